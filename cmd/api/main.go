@@ -10,10 +10,16 @@ import (
 	"time"
 
 	"agenda/internal/database"
+	"agenda/internal/notify"
+	"agenda/internal/scheduler"
 	"agenda/internal/server"
+	"agenda/internal/stream"
+	"agenda/internal/webhook"
 )
 
-func gracefulShutdown(apiServer *http.Server, done chan bool) {
+func gracefulShutdown(apiServer *http.Server, streamHub *stream.Hub, reminderScheduler *scheduler.ReminderScheduler,
+	webhookDispatcher *scheduler.WebhookDispatcher, trashPurger *scheduler.TrashPurger,
+	backupScheduler *scheduler.BackupScheduler, done chan bool) {
 	// Create context that listens for the interrupt signal from the OS.
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -24,6 +30,10 @@ func gracefulShutdown(apiServer *http.Server, done chan bool) {
 	log.Println("shutting down gracefully, press Ctrl+C again to force")
 	stop() // Allow Ctrl+C to force shutdown
 
+	// Change streams never finish on their own, so end them before waiting
+	// for the requests in flight; clients reconnect and resume them
+	streamHub.Close()
+
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -32,6 +42,15 @@ func gracefulShutdown(apiServer *http.Server, done chan bool) {
 		log.Printf("Server forced to shutdown with error: %v", err)
 	}
 
+	// Stop delivering reminders and webhooks once the in-flight deliveries
+	// have finished, and stop purging the trash and backing up the database
+	reminderScheduler.Stop()
+	webhookDispatcher.Stop()
+	trashPurger.Stop()
+	if backupScheduler != nil {
+		backupScheduler.Stop()
+	}
+
 	log.Println("Server exiting")
 
 	// Notify the main goroutine that the shutdown is complete
@@ -43,21 +62,68 @@ func main() {
 	dbService := database.New()
 	defer dbService.Close()
 
-	// Initialize database schema
-	if err := dbService.Initialize(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	// Initialize database schema, or make sure it is up to date when
+	// migrations are applied separately
+	if database.AutoMigrateFromEnv() {
+		if err := dbService.Initialize(); err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+	} else if err := dbService.CheckMigrations(); err != nil {
+		log.Fatalf("Database schema is not up to date, run cmd/migrate first: %v", err)
+	}
+	if count, err := database.CountUnownedRows(context.Background(), dbService.GetDB()); err == nil && count > 0 {
+		log.Printf("%d tasks and events predate user accounts and are visible to no user; "+
+			"run cmd/migrate assign-owner EMAIL to give them to a user", count)
+	}
+
+	// Task and event changes are published to the change streams through
+	// an in-process hub
+	streamHub := stream.NewHub(stream.ReplaySizeFromEnv())
+
+	// SQLite databases are backed up online, on demand through the admin
+	// routes and on a schedule
+	backups, err := database.NewBackupManager(dbService.GetDB(), database.BackupConfigFromEnv())
+	if err != nil {
+		log.Printf("Database backups disabled: %v", err)
 	}
+	server := server.NewServer(dbService.GetDB(), streamHub, backups)
 
-	server := server.NewServer(dbService.GetDB())
+	// Start delivering reminders in the background
+	notifier, err := notify.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure reminder notifiers: %v", err)
+	}
+	reminderScheduler := scheduler.NewReminderScheduler(dbService.GetDB(), notifier, scheduler.ReminderIntervalFromEnv())
+	if err := reminderScheduler.Start(context.Background()); err != nil {
+		log.Fatalf("Failed to start reminder scheduler: %v", err)
+	}
+
+	// Start sending webhook deliveries in the background
+	webhookDispatcher := scheduler.NewWebhookDispatcher(dbService.GetDB(), scheduler.WebhookIntervalFromEnv(),
+		webhook.AllowPrivateNetworksFromEnv())
+	if err := webhookDispatcher.Start(context.Background()); err != nil {
+		log.Fatalf("Failed to start webhook dispatcher: %v", err)
+	}
+
+	// Purge the trash of items deleted longer ago than the retention period
+	trashPurger := scheduler.NewTrashPurger(dbService.GetDB(), scheduler.TrashRetentionFromEnv())
+	trashPurger.Start(context.Background())
+
+	// Back up the database every BACKUP_INTERVAL, unless disabled
+	var backupScheduler *scheduler.BackupScheduler
+	if interval := scheduler.BackupIntervalFromEnv(); backups != nil && interval > 0 {
+		backupScheduler = scheduler.NewBackupScheduler(backups, interval)
+		backupScheduler.Start(context.Background())
+	}
 
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
 	// Run graceful shutdown in a separate goroutine
-	go gracefulShutdown(server, done)
+	go gracefulShutdown(server, streamHub, reminderScheduler, webhookDispatcher, trashPurger, backupScheduler, done)
 
 	log.Println("Starting server on port 8080...")
-	err := server.ListenAndServe()
+	err = server.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
 		panic(fmt.Sprintf("http server error: %s", err))
 	}
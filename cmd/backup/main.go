@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"agenda/internal/database"
+)
+
+const usage = `Usage: backup [flags] <command> [arguments]
+
+Backs up and restores the SQLite database of BLUEPRINT_DB_URL. Snapshots
+are written to BACKUP_DIR and rotated according to BACKUP_KEEP and
+BACKUP_RETENTION_DAYS.
+
+Commands:
+  create            Write a snapshot of the database
+  list              List the snapshots, most recent first
+  restore SNAPSHOT  Replace the database with a snapshot, given by name or
+                    path. Stop the server first.
+
+Flags:
+`
+
+func main() {
+	log.SetFlags(0)
+	skipBackup := flag.Bool("no-backup", false, "do not back up the database before restoring a snapshot")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dialect, err := database.DialectByName(os.Getenv("BLUEPRINT_DB_DRIVER"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if dialect != database.SQLite {
+		log.Fatal(database.ErrBackupUnsupported)
+	}
+	config := database.BackupConfigFromEnv()
+
+	switch flag.Arg(0) {
+	case "create":
+		err = create(config)
+	case "list":
+		err = list(config)
+	case "restore":
+		if flag.NArg() != 2 {
+			usageError("restore needs the snapshot to restore")
+		}
+		err = restore(config, flag.Arg(1), !*skipBackup)
+	case "":
+		usageError("missing command")
+	default:
+		usageError(fmt.Sprintf("unknown command %q", flag.Arg(0)))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// usageError reports a mistake in the command line and exits
+func usageError(message string) {
+	fmt.Fprintf(os.Stderr, "backup: %s\n\n", message)
+	flag.Usage()
+	os.Exit(2)
+}
+
+// backup writes a snapshot of the configured database
+func backup(config database.BackupConfig) (*database.Snapshot, error) {
+	dbService := database.New()
+	defer dbService.Close()
+
+	backups, err := database.NewBackupManager(dbService.GetDB(), config)
+	if err != nil {
+		return nil, err
+	}
+	return backups.Backup(context.Background())
+}
+
+func create(config database.BackupConfig) error {
+	snapshot, err := backup(config)
+	if snapshot != nil {
+		fmt.Printf("Wrote %s (%d bytes)\n", filepath.Join(config.Dir, snapshot.Name), snapshot.Size)
+	}
+	return err
+}
+
+func list(config database.BackupConfig) error {
+	// Listing only reads the backup directory
+	snapshots, err := database.ListSnapshots(config.Dir)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tCREATED AT")
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", snapshot.Name, snapshot.Size, snapshot.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func restore(config database.BackupConfig, snapshot string, backupFirst bool) error {
+	// Snapshots are looked up in the backup directory by name
+	if filepath.Base(snapshot) == snapshot {
+		snapshot = filepath.Join(config.Dir, snapshot)
+	}
+	if _, err := os.Stat(snapshot); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", snapshot, database.ErrSnapshotNotFound)
+	}
+	dbPath := database.SQLitePath(os.Getenv("BLUEPRINT_DB_URL"))
+
+	// Keep the database being replaced, so the restore can be undone
+	if _, err := os.Stat(dbPath); err == nil && backupFirst {
+		current, err := backup(config)
+		if err != nil {
+			return fmt.Errorf("failed to back up the database before restoring: %w", err)
+		}
+		fmt.Printf("Backed up the database to %s\n", filepath.Join(config.Dir, current.Name))
+	}
+
+	applied, err := database.RestoreSnapshot(context.Background(), snapshot, dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", snapshot, err)
+	}
+	for _, migration := range applied {
+		fmt.Println("Applied", migration.FileName())
+	}
+	fmt.Printf("Restored %s to %s\n", snapshot, dbPath)
+	return nil
+}
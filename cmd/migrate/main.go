@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"agenda/internal/database"
+)
+
+const usage = `Usage: migrate [flags] <command> [arguments]
+
+Manages the schema of the database configured by BLUEPRINT_DB_DRIVER and
+BLUEPRINT_DB_URL.
+
+Commands:
+  up           Apply the pending migrations
+  down [N]     Revert the last N applied migrations (1 by default)
+  status       List the migrations and whether they are applied
+  redo         Revert the last applied migration and apply it again
+  create NAME  Create the up and down files of a new migration
+  assign-owner EMAIL
+               Give the tasks and events created before user accounts
+               existed, which no user can see, to the user with EMAIL
+
+Flags:
+`
+
+func main() {
+	log.SetFlags(0)
+	dir := flag.String("dir", "internal/database/migrations", "directory holding the migrations of every dialect, for create")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command, args := flag.Arg(0), flag.Args()
+	if len(args) > 0 {
+		args = args[1:]
+	}
+
+	// Creating migrations only touches the migration files
+	if command == "create" {
+		if len(args) != 1 {
+			usageError("create needs the name of the migration")
+		}
+		paths, err := database.CreateMigration(*dir, args[0])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		for _, path := range paths {
+			fmt.Println("Created", path)
+		}
+		return
+	}
+
+	var run func(*database.MigrationService) error
+	switch command {
+	case "up":
+		run = up
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				usageError("down needs a positive number of migrations")
+			}
+			steps = n
+		}
+		run = func(ms *database.MigrationService) error { return down(ms, steps) }
+	case "status":
+		run = status
+	case "redo":
+		run = redo
+	case "assign-owner":
+		if len(args) != 1 {
+			usageError("assign-owner needs the email of the user")
+		}
+		dbService := database.New()
+		err := assignOwner(dbService.GetDB(), args[0])
+		dbService.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	case "":
+		usageError("missing command")
+	default:
+		usageError(fmt.Sprintf("unknown command %q", command))
+	}
+
+	dbService := database.New()
+	err := run(database.NewMigrationService(dbService.GetDB()))
+	dbService.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// usageError reports a mistake in the command line and exits
+func usageError(message string) {
+	fmt.Fprintf(os.Stderr, "migrate: %s\n\n", message)
+	flag.Usage()
+	os.Exit(2)
+}
+
+func up(ms *database.MigrationService) error {
+	applied, err := ms.Up()
+	for _, migration := range applied {
+		fmt.Println("Applied", migration.FileName())
+	}
+	if err == nil && len(applied) == 0 {
+		fmt.Println("No pending migrations")
+	}
+	return err
+}
+
+func down(ms *database.MigrationService, steps int) error {
+	reverted, err := ms.Down(steps)
+	for _, migration := range reverted {
+		fmt.Println("Reverted", migration.FileName())
+	}
+	return err
+}
+
+func redo(ms *database.MigrationService) error {
+	migration, err := ms.Redo()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Redid", migration.FileName())
+	return nil
+}
+
+func assignOwner(db *sql.DB, email string) error {
+	ctx := context.Background()
+	user, err := database.NewUserRepository(db).GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no user is registered with %s", email)
+	}
+	if err != nil {
+		return err
+	}
+
+	assigned, err := database.AssignOwner(ctx, db, user.ID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Assigned %d tasks and %d events to %s\n", assigned["tasks"], assigned["events"], user.Email)
+	return nil
+}
+
+func status(ms *database.MigrationService) error {
+	statuses, err := ms.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		switch {
+		case s.Missing:
+			state = "missing"
+		case s.Modified:
+			state = "modified"
+		case s.Applied:
+			state = "applied"
+		}
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,10 @@
+package api
+
+// Media types of the request bodies accepted by PATCH endpoints. Plain
+// application/json bodies are read as merge patches.
+const (
+	// MediaTypeMergePatch is a JSON merge patch (RFC 7396)
+	MediaTypeMergePatch = "application/merge-patch+json"
+	// MediaTypeJSONPatch is a JSON Patch document (RFC 6902)
+	MediaTypeJSONPatch = "application/json-patch+json"
+)
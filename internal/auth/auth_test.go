@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserIDContext(t *testing.T) {
+	_, ok := UserIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	userID, ok := UserIDFromContext(WithUserID(context.Background(), 42))
+	assert.True(t, ok)
+	assert.Equal(t, 42, userID)
+}
+
+func TestPasswordHashing(t *testing.T) {
+	hash, err := HashPassword("correct horse battery staple")
+	require.NoError(t, err)
+	assert.NotEqual(t, "correct horse battery staple", hash)
+
+	assert.NoError(t, CheckPassword(hash, "correct horse battery staple"))
+	assert.ErrorIs(t, CheckPassword(hash, "wrong"), ErrPasswordMismatch)
+}
+
+func TestTokens(t *testing.T) {
+	token, err := NewToken()
+	require.NoError(t, err)
+	other, err := NewToken()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, token, other)
+	assert.Len(t, token, 43)
+	assert.Equal(t, HashToken(token), HashToken(token))
+	assert.NotEqual(t, HashToken(token), HashToken(other))
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"Bearer abc123", "abc123"},
+		{"bearer abc123", "abc123"},
+		{"  Bearer   abc123 ", "abc123"},
+		{"Basic dXNlcjpwYXNz", ""},
+		{"Bearer", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, BearerToken(tt.header), tt.header)
+	}
+}
@@ -0,0 +1,36 @@
+// Package auth provides the primitives used to authenticate users: password
+// hashing, session tokens and the request context carrying the current user.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidSession is returned when a session token is missing, unknown or expired
+var ErrInvalidSession = errors.New("invalid or expired session")
+
+// contextKey is the key under which the authenticated user ID is stored
+type contextKey struct{}
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID
+func WithUserID(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+// UserIDFromContext returns the authenticated user ID carried by ctx
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(contextKey{}).(int)
+	return userID, ok
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>"
+// header value. It returns an empty string for any other scheme.
+func BearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
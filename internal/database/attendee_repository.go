@@ -0,0 +1,256 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"agenda/internal/models"
+)
+
+// AttendeeRepositoryInterface defines the contract for event attendee
+// repository operations
+type AttendeeRepositoryInterface interface {
+	BaseRepository
+
+	// Attendee methods, scoped to the events of the authenticated user.
+	// Every change to the attendees of an event bumps its version.
+	CreateAttendee(ctx context.Context, attendee *models.Attendee) (*models.Attendee, error)
+	GetAttendeeByID(ctx context.Context, eventID, id int) (*models.Attendee, error)
+	GetEventAttendees(ctx context.Context, eventID int) ([]*models.Attendee, error)
+	UpdateAttendee(ctx context.Context, attendee *models.Attendee) error
+	DeleteAttendee(ctx context.Context, eventID, id int) error
+}
+
+// attendeeColumns lists the selected attendee columns in models.Attendee field order
+const attendeeColumns = "id, event_id, name, email, user_id, role, status, created_at, updated_at"
+
+// attendeeEventCondition limits attendees to the live events of the
+// authenticated user; attendees have no owner of their own
+const attendeeEventCondition = "event_id IN (SELECT id FROM events WHERE " + ownerCondition + " AND " + notDeleted + ")"
+
+// AttendeeRepository implements AttendeeRepositoryInterface
+type AttendeeRepository struct {
+	*Repository
+}
+
+// NewAttendeeRepository creates a new attendee repository instance
+func NewAttendeeRepository(db *sql.DB) AttendeeRepositoryInterface {
+	return &AttendeeRepository{
+		Repository: NewRepository(db),
+	}
+}
+
+// CreateAttendee adds an attendee to an event. sql.ErrNoRows is returned
+// when the event does not exist.
+func (ar *AttendeeRepository) CreateAttendee(ctx context.Context, attendee *models.Attendee) (*models.Attendee, error) {
+	err := ar.inTransaction(ctx, func(tx executor) error {
+		if err := bumpEventVersion(ctx, tx, attendee.EventID); err != nil {
+			return err
+		}
+		return ar.insertAttendee(ctx, tx, attendee)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to create attendee: %w", err)
+	}
+
+	return attendee, nil
+}
+
+// GetAttendeeByID retrieves an attendee of an event by its ID
+func (ar *AttendeeRepository) GetAttendeeByID(ctx context.Context, eventID, id int) (*models.Attendee, error) {
+	query := `
+		SELECT ` + attendeeColumns + `
+		FROM event_attendees
+		WHERE id = ? AND event_id = ? AND ` + attendeeEventCondition + `
+	`
+
+	var attendee models.Attendee
+	err := ar.Get(ctx, &attendee, query, id, eventID, ownerArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get attendee: %w", err)
+	}
+
+	return &attendee, nil
+}
+
+// GetEventAttendees retrieves the attendees of an event in the order they
+// were added
+func (ar *AttendeeRepository) GetEventAttendees(ctx context.Context, eventID int) ([]*models.Attendee, error) {
+	query := `
+		SELECT ` + attendeeColumns + `
+		FROM event_attendees
+		WHERE event_id = ? AND ` + attendeeEventCondition + `
+		ORDER BY id ASC
+	`
+
+	var attendees []*models.Attendee
+	err := ar.List(ctx, &attendees, query, eventID, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event attendees: %w", err)
+	}
+
+	return attendees, nil
+}
+
+// UpdateAttendee updates the name, account, role and RSVP status of an
+// attendee. sql.ErrNoRows is returned when the attendee does not exist.
+func (ar *AttendeeRepository) UpdateAttendee(ctx context.Context, attendee *models.Attendee) error {
+	query := `
+		UPDATE event_attendees
+		SET name = ?, user_id = ?, role = ?, status = ?, updated_at = ?
+		WHERE id = ? AND event_id = ? AND ` + attendeeEventCondition + `
+	`
+
+	attendee.UpdatedAt = time.Now()
+
+	err := ar.inTransaction(ctx, func(tx executor) error {
+		result, err := tx.ExecContext(ctx, query, attendee.Name, attendee.UserID, attendee.Role, attendee.Status,
+			attendee.UpdatedAt, attendee.ID, attendee.EventID, ownerArg(ctx))
+		if err != nil {
+			return err
+		}
+		if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+			if err == nil {
+				err = sql.ErrNoRows
+			}
+			return err
+		}
+		return bumpEventVersion(ctx, tx, attendee.EventID)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return sql.ErrNoRows
+		}
+		return fmt.Errorf("failed to update attendee: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAttendee removes an attendee from an event. sql.ErrNoRows is
+// returned when the attendee does not exist.
+func (ar *AttendeeRepository) DeleteAttendee(ctx context.Context, eventID, id int) error {
+	query := `DELETE FROM event_attendees WHERE id = ? AND event_id = ? AND ` + attendeeEventCondition
+
+	err := ar.inTransaction(ctx, func(tx executor) error {
+		result, err := tx.ExecContext(ctx, query, id, eventID, ownerArg(ctx))
+		if err != nil {
+			return err
+		}
+		if deleted, err := result.RowsAffected(); err != nil || deleted == 0 {
+			if err == nil {
+				err = sql.ErrNoRows
+			}
+			return err
+		}
+		return bumpEventVersion(ctx, tx, eventID)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return sql.ErrNoRows
+		}
+		return fmt.Errorf("failed to delete attendee: %w", err)
+	}
+
+	return nil
+}
+
+// bumpEventVersion bumps the version of a live event of the authenticated
+// user after a change to its attendees, so that its ETag changes. It
+// returns sql.ErrNoRows when there is no such event.
+func bumpEventVersion(ctx context.Context, exec executor, eventID int) error {
+	result, err := exec.ExecContext(ctx, `UPDATE events SET version = version + 1 WHERE id = ? AND `+ownerCondition+` AND `+notDeleted,
+		eventID, ownerArg(ctx))
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// insertAttendee inserts an attendee of attendee.EventID
+func (r *Repository) insertAttendee(ctx context.Context, exec executor, attendee *models.Attendee) error {
+	query := `
+		INSERT INTO event_attendees (event_id, name, email, user_id, role, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	attendee.CreatedAt = now
+	attendee.UpdatedAt = now
+
+	if attendee.Role == "" {
+		attendee.Role = models.AttendeeRequired
+	}
+	if attendee.Status == "" {
+		attendee.Status = models.RSVPNeedsAction
+	}
+
+	id, err := r.dialect.insert(ctx, exec, query, attendee.EventID, attendee.Name, attendee.Email, attendee.UserID,
+		attendee.Role, attendee.Status, attendee.CreatedAt, attendee.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	attendee.ID = int(id)
+	return nil
+}
+
+// loadAttendees fills in the attendees of the given events. Overrides and
+// expanded occurrences get the attendees of their series.
+func loadAttendees(ctx context.Context, exec executor, events []*models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	bySeries := make(map[int][]*models.Event, len(events))
+	args := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		event.Attendees = []models.Attendee{}
+		seriesID := event.ID
+		if event.ParentID != nil {
+			seriesID = *event.ParentID
+		}
+		if _, ok := bySeries[seriesID]; !ok {
+			args = append(args, seriesID)
+		}
+		bySeries[seriesID] = append(bySeries[seriesID], event)
+	}
+
+	rows, err := exec.QueryContext(ctx, `
+		SELECT `+attendeeColumns+`
+		FROM event_attendees
+		WHERE event_id IN (`+placeholders(len(args))+`)
+		ORDER BY id ASC
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var attendees []*models.Attendee
+	if err := scanRows(rows, &attendees); err != nil {
+		return err
+	}
+
+	for _, attendee := range attendees {
+		for _, event := range bySeries[attendee.EventID] {
+			event.Attendees = append(event.Attendees, *attendee)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+)
+
+func TestAttendeeRepository(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewAttendeeRepository(db)
+	eventRepo := NewEventRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+	otherCtx := auth.WithUserID(context.Background(), 2)
+
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	series, err := eventRepo.CreateEvent(ctx, &models.Event{
+		Title:          "Standup",
+		StartTime:      start,
+		EndTime:        start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=DAILY",
+		Attendees: []models.Attendee{
+			{Name: "Ada", Email: "ada@example.com", Role: models.AttendeeChair},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	if len(series.Attendees) != 1 || series.Attendees[0].ID == 0 || series.Attendees[0].Status != models.RSVPNeedsAction {
+		t.Fatalf("Expected the attendee to be created with the event, got %+v", series.Attendees)
+	}
+
+	recurrenceID := start.AddDate(0, 0, 1)
+	override, err := eventRepo.CreateEvent(ctx, &models.Event{
+		Title:        "Standup (moved)",
+		StartTime:    recurrenceID.Add(time.Hour),
+		EndTime:      recurrenceID.Add(75 * time.Minute),
+		ParentID:     &series.ID,
+		RecurrenceID: &recurrenceID,
+		Attendees:    series.Attendees,
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent failed for override: %v", err)
+	}
+
+	grace, err := repo.CreateAttendee(ctx, &models.Attendee{EventID: series.ID, Name: "Grace", Email: "grace@example.com"})
+	if err != nil {
+		t.Fatalf("CreateAttendee failed: %v", err)
+	}
+	if grace.Role != models.AttendeeRequired || grace.Status != models.RSVPNeedsAction {
+		t.Errorf("Expected the default role and status, got %s and %s", grace.Role, grace.Status)
+	}
+
+	t.Run("attendees are loaded with events", func(t *testing.T) {
+		event, err := eventRepo.GetEventByID(ctx, series.ID)
+		if err != nil {
+			t.Fatalf("GetEventByID failed: %v", err)
+		}
+		if len(event.Attendees) != 2 || event.Attendees[0].Email != "ada@example.com" || event.Attendees[1].Email != "grace@example.com" {
+			t.Errorf("Expected both attendees in order, got %+v", event.Attendees)
+		}
+		if event.Version != series.Version+1 {
+			t.Errorf("Expected adding an attendee to bump the version to %d, got %d", series.Version+1, event.Version)
+		}
+
+		overrides, err := eventRepo.GetEventOverrides(ctx, series.ID)
+		if err != nil || len(overrides) != 1 {
+			t.Fatalf("Expected one override, got %d (%v)", len(overrides), err)
+		}
+		if len(overrides[0].Attendees) != 2 || overrides[0].Attendees[0].EventID != series.ID {
+			t.Errorf("Expected the override to share the attendees of its series, got %+v", overrides[0].Attendees)
+		}
+
+		attendees, err := repo.GetEventAttendees(ctx, override.ID)
+		if err != nil || len(attendees) != 0 {
+			t.Errorf("Expected no attendees stored for the override, got %d (%v)", len(attendees), err)
+		}
+	})
+
+	t.Run("record an RSVP", func(t *testing.T) {
+		grace.Status = models.RSVPAccepted
+		if err := repo.UpdateAttendee(ctx, grace); err != nil {
+			t.Fatalf("UpdateAttendee failed: %v", err)
+		}
+		attendee, err := repo.GetAttendeeByID(ctx, series.ID, grace.ID)
+		if err != nil || attendee.Status != models.RSVPAccepted {
+			t.Errorf("Expected the RSVP to be recorded, got %+v (%v)", attendee, err)
+		}
+		event, err := eventRepo.GetEventByID(ctx, series.ID)
+		if err != nil || event.Version != series.Version+2 {
+			t.Errorf("Expected the RSVP to bump the version, got %+v (%v)", event, err)
+		}
+	})
+
+	t.Run("scoped to the owner of the event", func(t *testing.T) {
+		if _, err := repo.GetAttendeeByID(otherCtx, series.ID, grace.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows for another user, got %v", err)
+		}
+		if attendees, err := repo.GetEventAttendees(otherCtx, series.ID); err != nil || len(attendees) != 0 {
+			t.Errorf("Expected no attendees for another user, got %d (%v)", len(attendees), err)
+		}
+		if err := repo.UpdateAttendee(otherCtx, grace); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows updating for another user, got %v", err)
+		}
+		if err := repo.DeleteAttendee(otherCtx, series.ID, grace.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows deleting for another user, got %v", err)
+		}
+		if _, err := repo.CreateAttendee(otherCtx, &models.Attendee{EventID: series.ID, Email: "alan@example.com"}); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows adding for another user, got %v", err)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		if err := repo.DeleteAttendee(ctx, series.ID, grace.ID); err != nil {
+			t.Fatalf("DeleteAttendee failed: %v", err)
+		}
+		if err := repo.DeleteAttendee(ctx, series.ID, grace.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows deleting twice, got %v", err)
+		}
+		attendees, err := repo.GetEventAttendees(ctx, series.ID)
+		if err != nil || len(attendees) != 1 {
+			t.Errorf("Expected one attendee left, got %d (%v)", len(attendees), err)
+		}
+	})
+
+	t.Run("purged with their event", func(t *testing.T) {
+		if err := eventRepo.DeleteEvent(ctx, series.ID); err != nil {
+			t.Fatalf("DeleteEvent failed: %v", err)
+		}
+		if _, err := eventRepo.PurgeDeletedEvents(context.Background(), time.Now().Add(time.Minute)); err != nil {
+			t.Fatalf("PurgeDeletedEvents failed: %v", err)
+		}
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM event_attendees`).Scan(&count); err != nil || count != 0 {
+			t.Errorf("Expected the attendees to be purged, got %d (%v)", count, err)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"agenda/internal/models"
+)
+
+// AuditRepositoryInterface defines the contract for audit log repository
+// operations. The audit log is append-only: entries cannot be changed or
+// deleted.
+type AuditRepositoryInterface interface {
+	BaseRepository
+
+	// Audit methods, scoped to the authenticated user
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) (*models.AuditEntry, error)
+	ListAuditEntries(ctx context.Context, filters AuditFilters) ([]*models.AuditEntry, error)
+}
+
+// AuditFilters represents filters for listing audit log entries
+type AuditFilters struct {
+	EntityType string
+	EntityID   int
+	Action     string
+	ActorID    *int
+	Since      *time.Time
+	Until      *time.Time
+
+	// BeforeID starts the results at the entry preceding the one with the
+	// given ID, entries being listed newest first
+	BeforeID int
+	Limit    int
+}
+
+// auditColumns lists the selected audit log columns in models.AuditEntry field order
+const auditColumns = "id, entity_type, entity_id, action, actor_id, changes, user_id, created_at"
+
+// AuditRepository implements AuditRepositoryInterface
+type AuditRepository struct {
+	*Repository
+}
+
+// NewAuditRepository creates a new audit repository instance
+func NewAuditRepository(db *sql.DB) AuditRepositoryInterface {
+	return &AuditRepository{
+		Repository: NewRepository(db),
+	}
+}
+
+// CreateAuditEntry appends an entry to the audit log. The entry belongs to
+// the owner of the changed item, set by the caller.
+func (ar *AuditRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) (*models.AuditEntry, error) {
+	query := `
+		INSERT INTO audit_log (entity_type, entity_id, action, actor_id, changes, user_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	// Store times in UTC so they compare correctly as text
+	entry.CreatedAt = entry.CreatedAt.UTC()
+
+	id, err := ar.Create(ctx, query, entry.EntityType, entry.EntityID, entry.Action, entry.ActorID, entry.Changes,
+		entry.UserID, entry.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	entry.ID = int(id)
+	return entry, nil
+}
+
+// ListAuditEntries retrieves the audit log entries matching the filters,
+// newest first
+func (ar *AuditRepository) ListAuditEntries(ctx context.Context, filters AuditFilters) ([]*models.AuditEntry, error) {
+	conditions := []string{ownerCondition}
+	args := []interface{}{ownerArg(ctx)}
+
+	if filters.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, filters.EntityType)
+	}
+	if filters.EntityID > 0 {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, filters.EntityID)
+	}
+	if filters.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filters.Action)
+	}
+	if filters.ActorID != nil {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, *filters.ActorID)
+	}
+	if filters.Since != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filters.Since.UTC())
+	}
+	if filters.Until != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filters.Until.UTC())
+	}
+	if filters.BeforeID > 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, filters.BeforeID)
+	}
+
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_log
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY id DESC
+	`
+	if filters.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filters.Limit)
+	}
+
+	var entries []*models.AuditEntry
+	err := ar.List(ctx, &entries, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+)
+
+func TestAuditRepository(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+	otherCtx := auth.WithUserID(context.Background(), 2)
+	owner, other := 1, 2
+
+	start := time.Date(2030, time.January, 1, 9, 0, 0, 0, time.UTC)
+	create := func(ctx context.Context, owner *int, entityType string, entityID int, action string, at time.Time) *models.AuditEntry {
+		entry, err := repo.CreateAuditEntry(ctx, &models.AuditEntry{
+			EntityType: entityType,
+			EntityID:   entityID,
+			Action:     action,
+			ActorID:    owner,
+			Changes: models.FieldChanges{
+				"title": {Before: json.RawMessage(`"Draft"`), After: json.RawMessage(`"Final"`)},
+			},
+			UserID:    owner,
+			CreatedAt: at,
+		})
+		if err != nil {
+			t.Fatalf("CreateAuditEntry failed: %v", err)
+		}
+		return entry
+	}
+
+	created := create(ctx, &owner, models.AuditEntityTask, 7, "created", start)
+	updated := create(ctx, &owner, models.AuditEntityTask, 7, "updated", start.Add(time.Hour))
+	event := create(ctx, &owner, models.AuditEntityEvent, 7, "created", start.Add(2*time.Hour))
+	create(otherCtx, &other, models.AuditEntityTask, 8, "created", start)
+
+	ids := func(entries []*models.AuditEntry) []int {
+		var ids []int
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+	since, until := start.Add(30*time.Minute), start.Add(90*time.Minute)
+
+	tests := []struct {
+		name    string
+		filters AuditFilters
+		want    []int
+	}{
+		{"newest first", AuditFilters{}, []int{event.ID, updated.ID, created.ID}},
+		{"entity", AuditFilters{EntityType: models.AuditEntityTask, EntityID: 7}, []int{updated.ID, created.ID}},
+		{"action", AuditFilters{Action: "created"}, []int{event.ID, created.ID}},
+		{"actor", AuditFilters{ActorID: &owner}, []int{event.ID, updated.ID, created.ID}},
+		{"time range", AuditFilters{Since: &since, Until: &until}, []int{updated.ID}},
+		{"before", AuditFilters{BeforeID: event.ID, Limit: 1}, []int{updated.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := repo.ListAuditEntries(ctx, tt.filters)
+			if err != nil {
+				t.Fatalf("ListAuditEntries failed: %v", err)
+			}
+			got := ids(entries)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	t.Run("stores the changes", func(t *testing.T) {
+		entries, err := repo.ListAuditEntries(ctx, AuditFilters{Limit: 1})
+		if err != nil {
+			t.Fatalf("ListAuditEntries failed: %v", err)
+		}
+		change, ok := entries[0].Changes["title"]
+		if !ok || string(change.Before) != `"Draft"` || string(change.After) != `"Final"` {
+			t.Errorf("Unexpected changes %+v", entries[0].Changes)
+		}
+		if entries[0].ActorID == nil || *entries[0].ActorID != owner {
+			t.Errorf("Expected actor %d, got %v", owner, entries[0].ActorID)
+		}
+	})
+
+	t.Run("append-only", func(t *testing.T) {
+		if _, err := db.Exec(rebind(db, `UPDATE audit_log SET action = 'deleted' WHERE id = ?`), created.ID); err == nil {
+			t.Error("Expected updating an audit entry to fail")
+		}
+		if _, err := db.Exec(rebind(db, `DELETE FROM audit_log WHERE id = ?`), created.ID); err == nil {
+			t.Error("Expected deleting an audit entry to fail")
+		}
+	})
+}
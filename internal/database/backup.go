@@ -0,0 +1,391 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backup settings
+const (
+	// DefaultBackupKeep is the number of snapshots kept by rotation
+	DefaultBackupKeep = 7
+	// DefaultBackupRetentionDays is how long snapshots are kept
+	DefaultBackupRetentionDays = 30
+
+	// snapshotPrefix and snapshotSuffix surround the creation time in the
+	// names of snapshot files
+	snapshotPrefix = "agenda-"
+	snapshotSuffix = ".db"
+	// snapshotTimeFormat is the layout of the creation time of snapshots,
+	// which sorts the snapshot files by age
+	snapshotTimeFormat = "20060102T150405.000Z"
+)
+
+// Backup errors
+var (
+	ErrBackupUnsupported = errors.New("online backups are only supported for SQLite databases")
+	ErrSnapshotNotFound  = errors.New("snapshot not found")
+	ErrInvalidSnapshot   = errors.New("snapshot is not a valid agenda database")
+	ErrSnapshotSchema    = errors.New("snapshot schema does not match the database")
+)
+
+// BackupConfig holds the location and the rotation of database backups
+type BackupConfig struct {
+	// Dir is the directory snapshots are written to
+	Dir string
+	// Keep is the number of most recent snapshots kept, 0 for no limit
+	Keep int
+	// Retention is how long snapshots are kept, 0 for no limit
+	Retention time.Duration
+}
+
+// BackupConfigFromEnv reads the backup settings from the BACKUP_DIR,
+// BACKUP_KEEP and BACKUP_RETENTION_DAYS environment variables. Snapshots
+// default to a backups directory next to the database file, and invalid
+// values fall back to DefaultBackupKeep and DefaultBackupRetentionDays.
+func BackupConfigFromEnv() BackupConfig {
+	config := BackupConfig{
+		Dir:       os.Getenv("BACKUP_DIR"),
+		Keep:      DefaultBackupKeep,
+		Retention: DefaultBackupRetentionDays * 24 * time.Hour,
+	}
+	if config.Dir == "" {
+		config.Dir = filepath.Join(filepath.Dir(SQLitePath(dburl)), "backups")
+	}
+	if keep, err := strconv.Atoi(os.Getenv("BACKUP_KEEP")); err == nil && keep >= 0 {
+		config.Keep = keep
+	}
+	if days, err := strconv.Atoi(os.Getenv("BACKUP_RETENTION_DAYS")); err == nil && days >= 0 {
+		config.Retention = time.Duration(days) * 24 * time.Hour
+	}
+	return config
+}
+
+// SQLitePath returns the file of a SQLite data source name, without the
+// file: scheme and the connection parameters
+func SQLitePath(dsn string) string {
+	path := strings.TrimPrefix(dsn, "file:")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
+
+// Snapshot is a backup of the database
+type Snapshot struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupManager takes consistent snapshots of a live SQLite database with
+// VACUUM INTO, and rotates them
+type BackupManager struct {
+	db     *sql.DB
+	config BackupConfig
+	// mu serializes backups, so rotation sees every snapshot
+	mu sync.Mutex
+}
+
+// NewBackupManager creates a manager backing up db to the directory of
+// config. It returns ErrBackupUnsupported for databases other than SQLite.
+func NewBackupManager(db *sql.DB, config BackupConfig) (*BackupManager, error) {
+	if DialectOf(db) != SQLite {
+		return nil, ErrBackupUnsupported
+	}
+	return &BackupManager{db: db, config: config}, nil
+}
+
+// Backup writes a snapshot of the database, then removes the snapshots
+// rotated out or past their retention. VACUUM INTO reads the database in a
+// single transaction, so the snapshot is consistent while requests keep
+// being served.
+func (bm *BackupManager) Backup(ctx context.Context) (*Snapshot, error) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	if err := os.MkdirAll(bm.config.Dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	name := snapshotPrefix + now.Format(snapshotTimeFormat) + snapshotSuffix
+	path := filepath.Join(bm.config.Dir, name)
+
+	// Snapshots are written under a temporary name, so an interrupted
+	// backup never looks like a complete one
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if _, err := bm.db.ExecContext(ctx, "VACUUM INTO ?", tmp); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{Name: name, Size: info.Size(), CreatedAt: now}
+
+	if _, err := bm.prune(now); err != nil {
+		return snapshot, fmt.Errorf("failed to rotate snapshots: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Snapshots returns the snapshots of the backup directory, most recent
+// first
+func (bm *BackupManager) Snapshots() ([]Snapshot, error) {
+	return ListSnapshots(bm.config.Dir)
+}
+
+// ListSnapshots returns the snapshots of dir, most recent first
+func ListSnapshots(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []Snapshot{}
+	for _, entry := range entries {
+		stamp, ok := strings.CutPrefix(entry.Name(), snapshotPrefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, snapshotSuffix)
+		if !ok {
+			continue
+		}
+		createdAt, err := time.Parse(snapshotTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, Snapshot{Name: entry.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// SnapshotPath returns the file of the snapshot called name, or
+// ErrSnapshotNotFound
+func (bm *BackupManager) SnapshotPath(name string) (string, error) {
+	if name != filepath.Base(name) || !strings.HasPrefix(name, snapshotPrefix) {
+		return "", ErrSnapshotNotFound
+	}
+	path := filepath.Join(bm.config.Dir, name)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrSnapshotNotFound
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// prune removes the snapshots beyond the number kept or older than the
+// retention period at now, and returns them. The most recent snapshot is
+// always kept.
+func (bm *BackupManager) prune(now time.Time) ([]Snapshot, error) {
+	snapshots, err := bm.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []Snapshot
+	for i, snapshot := range snapshots {
+		if i == 0 {
+			continue
+		}
+		rotated := bm.config.Keep > 0 && i >= bm.config.Keep
+		expired := bm.config.Retention > 0 && now.Sub(snapshot.CreatedAt) > bm.config.Retention
+		if !rotated && !expired {
+			continue
+		}
+		if err := os.Remove(filepath.Join(bm.config.Dir, snapshot.Name)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, snapshot)
+	}
+	return removed, nil
+}
+
+// RestoreSnapshot replaces the SQLite database at dbPath with the snapshot
+// at snapshotPath, and returns the migrations applied to the snapshot. The
+// application must be stopped while it runs.
+//
+// The snapshot is validated on a copy before the copy is swapped in: it
+// must pass an integrity check, its applied migrations must be known and
+// unchanged, and its schema version cannot be newer than the version
+// recorded in the schema_migrations table of the database. Snapshots taken
+// at an earlier version get the migrations they lack.
+func RestoreSnapshot(ctx context.Context, snapshotPath, dbPath string) ([]Migration, error) {
+	if _, err := os.Stat(snapshotPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+
+	target, err := sqliteSchemaVersion(ctx, dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version of %s: %w", dbPath, err)
+	}
+
+	tmp := dbPath + ".restore"
+	if err := copyFile(snapshotPath, tmp); err != nil {
+		return nil, fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+	applied, err := prepareSnapshot(ctx, tmp, target)
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+
+	// Journals left by the replaced database would be replayed into the
+	// snapshot
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			os.Remove(tmp)
+			return nil, err
+		}
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return applied, nil
+}
+
+// sqliteSchemaVersion returns the latest migration applied to the SQLite
+// database at path. Databases that do not exist yet, or have no migration
+// applied, can take any version known to the application.
+func sqliteSchemaVersion(ctx context.Context, path string) (int, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return math.MaxInt, nil
+	}
+
+	db, err := sql.Open(SQLite.Driver, "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&count); err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return math.MaxInt, nil
+	}
+
+	var version sql.NullInt64
+	if err := db.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, err
+	}
+	if !version.Valid {
+		return math.MaxInt, nil
+	}
+	return int(version.Int64), nil
+}
+
+// prepareSnapshot validates the copy of a snapshot at path, and applies
+// the migrations it lacks up to version target
+func prepareSnapshot(ctx context.Context, path string, target int) ([]Migration, error) {
+	db, err := sql.Open(SQLite.Driver, path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	var result string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if result != "ok" {
+		return nil, fmt.Errorf("%w: integrity check failed: %s", ErrInvalidSnapshot, result)
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&count); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%w: no schema_migrations table", ErrInvalidSnapshot)
+	}
+
+	ms := NewMigrationService(db)
+	statuses, err := ms.Status()
+	if err != nil {
+		return nil, err
+	}
+	version := 0
+	for _, status := range statuses {
+		switch {
+		case status.Missing:
+			return nil, fmt.Errorf("%w: migration %d of the snapshot is unknown", ErrSnapshotSchema, status.Version)
+		case status.Modified:
+			return nil, fmt.Errorf("%w: migration %03d_%s of the snapshot was modified", ErrSnapshotSchema, status.Version, status.Name)
+		case status.Applied:
+			version = status.Version
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%w: no migration applied", ErrInvalidSnapshot)
+	}
+	if version > target {
+		return nil, fmt.Errorf("%w: snapshot schema version %d is newer than the database version %d", ErrSnapshotSchema, version, target)
+	}
+
+	return ms.UpTo(target)
+}
+
+// copyFile copies the file at src to dst, replacing dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
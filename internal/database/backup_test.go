@@ -0,0 +1,302 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openBackupTestDB opens the SQLite database file at path with the
+// migrations up to version applied. Backups and restores work on files,
+// so these tests do not run on the shared test databases.
+func openBackupTestDB(t *testing.T, path string, version int) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := NewMigrationService(db).UpTo(version); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	return db
+}
+
+// taskTitles returns the titles of the tasks of the database file at path
+func taskTitles(t *testing.T, path string) []string {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT title FROM tasks ORDER BY id")
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %v", err)
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			t.Fatalf("Failed to scan task: %v", err)
+		}
+		titles = append(titles, title)
+	}
+	return titles
+}
+
+// writeSnapshotFile writes an empty snapshot file created at createdAt
+func writeSnapshotFile(t *testing.T, dir string, createdAt time.Time) string {
+	t.Helper()
+
+	name := snapshotPrefix + createdAt.UTC().Format(snapshotTimeFormat) + snapshotSuffix
+	if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+	return name
+}
+
+func TestBackupManager_Backup(t *testing.T) {
+	dir := t.TempDir()
+	db := openBackupTestDB(t, filepath.Join(dir, "app.db"), math.MaxInt)
+	if _, err := db.Exec("INSERT INTO tasks (title) VALUES ('Backed up')"); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	manager, err := NewBackupManager(db, BackupConfig{Dir: filepath.Join(dir, "backups")})
+	if err != nil {
+		t.Fatalf("Failed to create backup manager: %v", err)
+	}
+	snapshot, err := manager.Backup(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to back up database: %v", err)
+	}
+	if snapshot.Size == 0 {
+		t.Error("Expected a non-empty snapshot")
+	}
+
+	path, err := manager.SnapshotPath(snapshot.Name)
+	if err != nil {
+		t.Fatalf("Failed to find snapshot: %v", err)
+	}
+	if titles := taskTitles(t, path); len(titles) != 1 || titles[0] != "Backed up" {
+		t.Errorf("Expected the snapshot to hold the task, got %v", titles)
+	}
+
+	snapshots, err := manager.Snapshots()
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0] != *snapshot {
+		t.Errorf("Expected the snapshot to be listed, got %+v", snapshots)
+	}
+}
+
+func TestBackupManager_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	db := openBackupTestDB(t, filepath.Join(dir, "app.db"), math.MaxInt)
+	backups := filepath.Join(dir, "backups")
+	if err := os.Mkdir(backups, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	recent := writeSnapshotFile(t, backups, now.Add(-time.Hour))
+	writeSnapshotFile(t, backups, now.Add(-2*time.Hour))
+	writeSnapshotFile(t, backups, now.Add(-40*24*time.Hour))
+	// Files that are not snapshots are left alone
+	if err := os.WriteFile(filepath.Join(backups, "notes.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := NewBackupManager(db, BackupConfig{Dir: backups, Keep: 2, Retention: 30 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to create backup manager: %v", err)
+	}
+	snapshot, err := manager.Backup(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to back up database: %v", err)
+	}
+
+	snapshots, err := manager.Snapshots()
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != snapshot.Name || snapshots[1].Name != recent {
+		t.Errorf("Expected the two most recent snapshots to be kept, got %+v", snapshots)
+	}
+	if _, err := os.Stat(filepath.Join(backups, "notes.txt")); err != nil {
+		t.Errorf("Expected other files to be kept: %v", err)
+	}
+
+	// Retention alone never removes the most recent snapshot
+	manager.config = BackupConfig{Dir: backups, Retention: time.Nanosecond}
+	removed, err := manager.prune(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to prune snapshots: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Name != recent {
+		t.Errorf("Expected only the older snapshot to be removed, got %+v", removed)
+	}
+}
+
+func TestBackupManager_SnapshotPath(t *testing.T) {
+	dir := t.TempDir()
+	db := openBackupTestDB(t, filepath.Join(dir, "app.db"), 1)
+	manager, err := NewBackupManager(db, BackupConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("Failed to create backup manager: %v", err)
+	}
+
+	for _, name := range []string{"app.db", "../app.db", snapshotPrefix + "missing.db"} {
+		if _, err := manager.SnapshotPath(name); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Errorf("Expected ErrSnapshotNotFound for %q, got %v", name, err)
+		}
+	}
+}
+
+func TestNewBackupManager_Postgres(t *testing.T) {
+	db, err := sql.Open("postgres", "postgres://localhost/agenda")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := NewBackupManager(db, BackupConfig{Dir: t.TempDir()}); !errors.Is(err, ErrBackupUnsupported) {
+		t.Errorf("Expected ErrBackupUnsupported, got %v", err)
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "app.db")
+	db := openBackupTestDB(t, dbPath, math.MaxInt)
+	if _, err := db.Exec("INSERT INTO tasks (title) VALUES ('Kept')"); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	manager, err := NewBackupManager(db, BackupConfig{Dir: filepath.Join(dir, "backups")})
+	if err != nil {
+		t.Fatalf("Failed to create backup manager: %v", err)
+	}
+	snapshot, err := manager.Backup(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to back up database: %v", err)
+	}
+	snapshotPath, _ := manager.SnapshotPath(snapshot.Name)
+
+	if _, err := db.Exec("INSERT INTO tasks (title) VALUES ('Lost')"); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	db.Close()
+
+	applied, err := RestoreSnapshot(context.Background(), snapshotPath, dbPath)
+	if err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected no migration to be applied, got %+v", applied)
+	}
+	if titles := taskTitles(t, dbPath); len(titles) != 1 || titles[0] != "Kept" {
+		t.Errorf("Expected the tasks of the snapshot, got %v", titles)
+	}
+	if _, err := os.Stat(dbPath + ".restore"); !os.IsNotExist(err) {
+		t.Errorf("Expected the restore copy to be gone, got %v", err)
+	}
+}
+
+func TestRestoreSnapshot_SchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	migrations, err := NewMigrationService(openBackupTestDB(t, filepath.Join(dir, "scratch.db"), 0)).loadMigrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	latest := migrations[len(migrations)-1].Version
+
+	older := filepath.Join(dir, "older.db")
+	openBackupTestDB(t, older, latest-1).Close()
+	current := filepath.Join(dir, "current.db")
+	openBackupTestDB(t, current, latest).Close()
+
+	// Older snapshots get the migrations they lack
+	dbPath := filepath.Join(dir, "app.db")
+	openBackupTestDB(t, dbPath, latest).Close()
+	applied, err := RestoreSnapshot(context.Background(), older, dbPath)
+	if err != nil {
+		t.Fatalf("Failed to restore older snapshot: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != latest {
+		t.Errorf("Expected migration %d to be applied, got %+v", latest, applied)
+	}
+
+	// Newer snapshots are refused, and the database is left alone
+	dbPath = filepath.Join(dir, "behind.db")
+	openBackupTestDB(t, dbPath, latest-1).Close()
+	if _, err := RestoreSnapshot(context.Background(), current, dbPath); !errors.Is(err, ErrSnapshotSchema) {
+		t.Errorf("Expected ErrSnapshotSchema, got %v", err)
+	}
+	var version int
+	behind := openBackupTestDB(t, dbPath, 0)
+	if err := behind.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		t.Fatalf("Failed to read schema version: %v", err)
+	}
+	if version != latest-1 {
+		t.Errorf("Expected the database to stay at version %d, got %d", latest-1, version)
+	}
+
+	// Snapshots with a modified migration are refused
+	modified := filepath.Join(dir, "modified.db")
+	db := openBackupTestDB(t, modified, latest)
+	if _, err := db.Exec("UPDATE schema_migrations SET checksum = 'edited' WHERE version = 1"); err != nil {
+		t.Fatalf("Failed to edit checksum: %v", err)
+	}
+	db.Close()
+	if _, err := RestoreSnapshot(context.Background(), modified, filepath.Join(dir, "app.db")); !errors.Is(err, ErrSnapshotSchema) {
+		t.Errorf("Expected ErrSnapshotSchema, got %v", err)
+	}
+}
+
+func TestRestoreSnapshot_InvalidSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "app.db")
+	openBackupTestDB(t, dbPath, math.MaxInt).Close()
+
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("not a database, just some notes about the agenda"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	unrelated := filepath.Join(dir, "unrelated.db")
+	db, err := sql.Open("sqlite3", unrelated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE things (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	for _, path := range []string{notes, unrelated} {
+		if _, err := RestoreSnapshot(context.Background(), path, dbPath); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Expected ErrInvalidSnapshot for %s, got %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := RestoreSnapshot(context.Background(), filepath.Join(dir, "missing.db"), dbPath); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("Expected ErrSnapshotNotFound, got %v", err)
+	}
+
+	if titles := taskTitles(t, dbPath); len(titles) != 0 {
+		t.Errorf("Expected the database to be left alone, got %v", titles)
+	}
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"agenda/internal/models"
+)
+
+// CalendarRepositoryInterface defines the contract for calendar repository operations
+type CalendarRepositoryInterface interface {
+	BaseRepository
+
+	// Calendar methods, scoped to the authenticated user
+	CreateCalendar(ctx context.Context, calendar *models.Calendar) (*models.Calendar, error)
+	GetCalendarByID(ctx context.Context, id int) (*models.Calendar, error)
+	ListCalendars(ctx context.Context) ([]*models.Calendar, error)
+	UpdateCalendar(ctx context.Context, calendar *models.Calendar) error
+	DeleteCalendar(ctx context.Context, id int) error
+}
+
+// calendarColumns lists the selected calendar columns in models.Calendar field order
+const calendarColumns = "id, name, description, kind, conflict_policy, user_id, created_at, updated_at"
+
+// CalendarRepository implements CalendarRepositoryInterface
+type CalendarRepository struct {
+	*Repository
+}
+
+// NewCalendarRepository creates a new calendar repository instance
+func NewCalendarRepository(db *sql.DB) CalendarRepositoryInterface {
+	return &CalendarRepository{
+		Repository: NewRepository(db),
+	}
+}
+
+// CreateCalendar creates a new calendar in the database
+func (cr *CalendarRepository) CreateCalendar(ctx context.Context, calendar *models.Calendar) (*models.Calendar, error) {
+	query := `
+		INSERT INTO calendars (name, description, kind, conflict_policy, user_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	calendar.CreatedAt = now
+	calendar.UpdatedAt = now
+
+	if calendar.Kind == "" {
+		calendar.Kind = models.CalendarPersonal
+	}
+	if calendar.ConflictPolicy == "" {
+		calendar.ConflictPolicy = models.DefaultConflictPolicy
+	}
+
+	// New calendars belong to the authenticated user
+	calendar.UserID = ownerID(ctx, calendar.UserID)
+
+	id, err := cr.Create(ctx, query, calendar.Name, calendar.Description, calendar.Kind, calendar.ConflictPolicy,
+		calendar.UserID, calendar.CreatedAt, calendar.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calendar: %w", err)
+	}
+
+	calendar.ID = int(id)
+	return calendar, nil
+}
+
+// GetCalendarByID retrieves a calendar by its ID
+func (cr *CalendarRepository) GetCalendarByID(ctx context.Context, id int) (*models.Calendar, error) {
+	query := `
+		SELECT ` + calendarColumns + `
+		FROM calendars
+		WHERE id = ? AND ` + ownerCondition + `
+	`
+
+	var calendar models.Calendar
+	err := cr.Get(ctx, &calendar, query, id, ownerArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get calendar: %w", err)
+	}
+
+	return &calendar, nil
+}
+
+// ListCalendars retrieves every calendar, ordered by name
+func (cr *CalendarRepository) ListCalendars(ctx context.Context) ([]*models.Calendar, error) {
+	query := `
+		SELECT ` + calendarColumns + `
+		FROM calendars
+		WHERE ` + ownerCondition + `
+		ORDER BY name ASC, id ASC
+	`
+
+	var calendars []*models.Calendar
+	err := cr.List(ctx, &calendars, query, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendars: %w", err)
+	}
+
+	return calendars, nil
+}
+
+// UpdateCalendar updates an existing calendar
+func (cr *CalendarRepository) UpdateCalendar(ctx context.Context, calendar *models.Calendar) error {
+	query := `
+		UPDATE calendars
+		SET name = ?, description = ?, kind = ?, conflict_policy = ?, updated_at = ?
+		WHERE id = ? AND ` + ownerCondition + `
+	`
+
+	calendar.UpdatedAt = time.Now()
+
+	err := cr.Update(ctx, query, calendar.Name, calendar.Description, calendar.Kind, calendar.ConflictPolicy,
+		calendar.UpdatedAt, calendar.ID, ownerArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to update calendar: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCalendar removes a calendar. Events in the trash leave the calendar
+// and are restored without one. ErrNotEmpty is returned while the calendar
+// has events that are not deleted, and sql.ErrNoRows when it does not
+// exist.
+func (cr *CalendarRepository) DeleteCalendar(ctx context.Context, id int) error {
+	err := cr.inTransaction(ctx, func(tx executor) error {
+		var exists, used bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM calendars WHERE id = ? AND `+ownerCondition+`),
+				EXISTS (SELECT 1 FROM events WHERE calendar_id = ? AND `+notDeleted+`)
+		`, id, ownerArg(ctx), id).Scan(&exists, &used)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return sql.ErrNoRows
+		}
+		if used {
+			return ErrNotEmpty
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE events SET calendar_id = NULL WHERE calendar_id = ?`, id); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM calendars WHERE id = ? AND `+ownerCondition, id, ownerArg(ctx))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+)
+
+func TestCalendarRepository(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewCalendarRepository(db)
+	eventRepo := NewEventRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+	otherCtx := auth.WithUserID(context.Background(), 2)
+
+	team, err := repo.CreateCalendar(ctx, &models.Calendar{Name: "Team", Kind: models.CalendarTeam, ConflictPolicy: models.ConflictWarn})
+	if err != nil {
+		t.Fatalf("CreateCalendar failed: %v", err)
+	}
+	room, err := repo.CreateCalendar(ctx, &models.Calendar{Name: "Room 1"})
+	if err != nil {
+		t.Fatalf("CreateCalendar failed: %v", err)
+	}
+	if room.Kind != models.CalendarPersonal || room.ConflictPolicy != models.ConflictReject {
+		t.Errorf("Expected the default kind and policy, got %s and %s", room.Kind, room.ConflictPolicy)
+	}
+
+	t.Run("get and list", func(t *testing.T) {
+		calendar, err := repo.GetCalendarByID(ctx, team.ID)
+		if err != nil || calendar.Name != "Team" || calendar.ConflictPolicy != models.ConflictWarn {
+			t.Errorf("Expected the team calendar, got %+v (%v)", calendar, err)
+		}
+
+		calendars, err := repo.ListCalendars(ctx)
+		if err != nil || len(calendars) != 2 || calendars[0].Name != "Room 1" {
+			t.Errorf("Expected both calendars by name, got %+v (%v)", calendars, err)
+		}
+	})
+
+	t.Run("scoped to the owner", func(t *testing.T) {
+		if _, err := repo.GetCalendarByID(otherCtx, team.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows for another user, got %v", err)
+		}
+		if calendars, err := repo.ListCalendars(otherCtx); err != nil || len(calendars) != 0 {
+			t.Errorf("Expected no calendars for another user, got %d (%v)", len(calendars), err)
+		}
+		if err := repo.DeleteCalendar(otherCtx, team.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected sql.ErrNoRows deleting for another user, got %v", err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		room.Kind = models.CalendarRoom
+		room.ConflictPolicy = models.ConflictIgnore
+		if err := repo.UpdateCalendar(ctx, room); err != nil {
+			t.Fatalf("UpdateCalendar failed: %v", err)
+		}
+		calendar, err := repo.GetCalendarByID(ctx, room.ID)
+		if err != nil || calendar.Kind != models.CalendarRoom || calendar.ConflictPolicy != models.ConflictIgnore {
+			t.Errorf("Expected the calendar to be updated, got %+v (%v)", calendar, err)
+		}
+	})
+
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	series, err := eventRepo.CreateEvent(ctx, &models.Event{
+		Title:          "Standup",
+		StartTime:      start,
+		EndTime:        start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=DAILY",
+		CalendarID:     &team.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	recurrenceID := start.AddDate(0, 0, 1)
+	override, err := eventRepo.CreateEvent(ctx, &models.Event{
+		Title:        "Standup (moved)",
+		StartTime:    recurrenceID.Add(time.Hour),
+		EndTime:      recurrenceID.Add(75 * time.Minute),
+		ParentID:     &series.ID,
+		RecurrenceID: &recurrenceID,
+		CalendarID:   &team.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent failed for override: %v", err)
+	}
+
+	t.Run("events belong to a calendar", func(t *testing.T) {
+		events, err := eventRepo.ListEvents(ctx, EventFilters{CalendarID: &team.ID})
+		if err != nil || len(events) != 2 || *events[0].CalendarID != team.ID {
+			t.Errorf("Expected the events of the team calendar, got %d (%v)", len(events), err)
+		}
+		none := 0
+		if events, err := eventRepo.ListEvents(ctx, EventFilters{CalendarID: &none}); err != nil || len(events) != 0 {
+			t.Errorf("Expected no events without a calendar, got %d (%v)", len(events), err)
+		}
+	})
+
+	t.Run("overrides move with their series", func(t *testing.T) {
+		series.CalendarID = &room.ID
+		if err := eventRepo.UpdateEvent(ctx, series); err != nil {
+			t.Fatalf("UpdateEvent failed: %v", err)
+		}
+		moved, err := eventRepo.GetEventByID(ctx, override.ID)
+		if err != nil || moved.CalendarID == nil || *moved.CalendarID != room.ID {
+			t.Errorf("Expected the override to move to the room calendar, got %+v (%v)", moved, err)
+		}
+		if moved.Version != override.Version+1 {
+			t.Errorf("Expected the move to bump the override version to %d, got %d", override.Version+1, moved.Version)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := repo.DeleteCalendar(ctx, room.ID); !errors.Is(err, ErrNotEmpty) {
+			t.Fatalf("Expected ErrNotEmpty while the calendar has events, got %v", err)
+		}
+
+		if err := eventRepo.DeleteEvent(ctx, series.ID); err != nil {
+			t.Fatalf("DeleteEvent failed: %v", err)
+		}
+		if err := repo.DeleteCalendar(ctx, room.ID); err != nil {
+			t.Fatalf("DeleteCalendar failed: %v", err)
+		}
+		if _, err := repo.GetCalendarByID(ctx, room.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected the calendar to be deleted, got %v", err)
+		}
+
+		if err := eventRepo.RestoreEvent(ctx, series.ID); err != nil {
+			t.Fatalf("RestoreEvent failed: %v", err)
+		}
+		restored, err := eventRepo.GetEventByID(ctx, series.ID)
+		if err != nil || restored.CalendarID != nil {
+			t.Errorf("Expected the event to be restored without a calendar, got %+v (%v)", restored, err)
+		}
+	})
+}
@@ -27,6 +27,18 @@ func DefaultConnectionConfig() *ConnectionConfig {
 	}
 }
 
+// PostgresConnectionConfig returns default connection configuration for
+// PostgreSQL, which serves concurrent connections
+func PostgresConnectionConfig() *ConnectionConfig {
+	return &ConnectionConfig{
+		MaxOpenConns:    25,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: time.Hour,
+		ConnMaxIdleTime: time.Minute * 30,
+		PingTimeout:     time.Second * 5,
+	}
+}
+
 // ConfigureConnection applies connection configuration to a database instance
 func ConfigureConnection(db *sql.DB, config *ConnectionConfig) {
 	if config == nil {
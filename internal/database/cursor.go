@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCursor is returned for cursor tokens that cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a sorted list, used for keyset pagination. It
+// records the sort key and ID of the row next to which a page starts, so
+// pages do not shift when rows are inserted or deleted in between.
+type Cursor struct {
+	// Sort and Desc are the ordering the cursor was created for
+	Sort string `json:"s"`
+	Desc bool   `json:"d,omitempty"`
+
+	// Key is the sort key of the row, as stored, and ID its ID
+	Key interface{} `json:"k"`
+	ID  int         `json:"i"`
+
+	// Before selects the page ending right before the row instead of the
+	// page starting right after it
+	Before bool `json:"b,omitempty"`
+}
+
+// Encode returns the opaque token of the cursor
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token returned by Cursor.Encode
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort == "" || cursor.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// PageCursors holds the cursors of the pages around a page of a list, nil
+// when there is no such page
+type PageCursors struct {
+	Next *Cursor
+	Prev *Cursor
+}
+
+// keysetOrder describes the ordering of a list paginated with cursors.
+// Rows are ordered by a sort key, then by ID.
+type keysetOrder struct {
+	// sort names the ordering in cursors
+	sort string
+	// expr is the ORDER BY expression of the sort key, and key the
+	// expression selecting the stored sort key of a row
+	expr string
+	key  string
+	desc bool
+	// nullsLast sorts the rows without a sort key last in both directions
+	nullsLast bool
+}
+
+// orderBy returns the ORDER BY clause of the ordering, or of the reverse
+// ordering used to fetch the page before a cursor
+func (o keysetOrder) orderBy(reverse bool) string {
+	direction := " ASC"
+	if o.desc != reverse {
+		direction = " DESC"
+	}
+
+	orderBy := o.expr + direction + ", id" + direction
+	if o.nullsLast {
+		if reverse {
+			orderBy = o.expr + " IS NULL DESC, " + orderBy
+		} else {
+			orderBy = o.expr + " IS NULL, " + orderBy
+		}
+	}
+	return orderBy
+}
+
+// condition returns the WHERE condition selecting the rows after the
+// cursor, or before it for cursors with Before set
+func (o keysetOrder) condition(cursor *Cursor) (string, []interface{}) {
+	op := ">"
+	if o.desc != cursor.Before {
+		op = "<"
+	}
+
+	// Rows without a sort key come after every row with one
+	if cursor.Key == nil && o.nullsLast {
+		if cursor.Before {
+			return "(" + o.expr + " IS NOT NULL OR id " + op + " ?)", []interface{}{cursor.ID}
+		}
+		return "(" + o.expr + " IS NULL AND id " + op + " ?)", []interface{}{cursor.ID}
+	}
+
+	condition := "(" + o.expr + " " + op + " ? OR (" + o.expr + " = ? AND id " + op + " ?))"
+	args := []interface{}{cursor.Key, cursor.Key, cursor.ID}
+	if o.nullsLast {
+		if cursor.Before {
+			condition = "(" + o.expr + " IS NOT NULL AND " + condition + ")"
+		} else {
+			condition = "(" + o.expr + " IS NULL OR " + condition + ")"
+		}
+	}
+	return condition, args
+}
+
+// paginate finishes a page of rows fetched with one extra row to detect
+// whether more rows follow. Pages fetched backwards from a cursor are put
+// back in display order. It returns the page and the cursors of the pages
+// around it; offset is the number of rows skipped before the page when no
+// cursor was given.
+func paginate[T any](ctx context.Context, r *Repository, table string, order keysetOrder, rows []T, rowID func(T) int,
+	limit, offset int, cursor *Cursor) ([]T, PageCursors, error) {
+	var cursors PageCursors
+
+	more := limit > 0 && len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	if cursor != nil && cursor.Before {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, cursors, nil
+	}
+
+	// Rows precede the page when it was reached through a cursor or an
+	// offset, and follow it when the extra row was found
+	hasPrev, hasNext := offset > 0, more
+	if cursor != nil {
+		hasPrev, hasNext = !cursor.Before || more, cursor.Before || more
+	}
+	if !hasPrev && !hasNext {
+		return rows, cursors, nil
+	}
+
+	first, last := rowID(rows[0]), rowID(rows[len(rows)-1])
+	keys, err := sortKeys(ctx, r, table, order, first, last)
+	if err != nil {
+		return nil, cursors, err
+	}
+
+	if hasPrev {
+		cursors.Prev = &Cursor{Sort: order.sort, Desc: order.desc, Key: keys[first], ID: first, Before: true}
+	}
+	if hasNext {
+		cursors.Next = &Cursor{Sort: order.sort, Desc: order.desc, Key: keys[last], ID: last}
+	}
+	return rows, cursors, nil
+}
+
+// sortKeys returns the stored sort keys of rows of table by ID
+func sortKeys(ctx context.Context, r *Repository, table string, order keysetOrder, ids ...int) (map[int]interface{}, error) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	query := "SELECT id, " + order.key + " FROM " + table + " WHERE id IN (" + placeholders(len(ids)) + ")"
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sort keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[int]interface{}, len(ids))
+	for rows.Next() {
+		var id int
+		var key interface{}
+		if err := rows.Scan(&id, &key); err != nil {
+			return nil, fmt.Errorf("failed to get sort keys: %w", err)
+		}
+		if data, ok := key.([]byte); ok {
+			key = string(data)
+		}
+		keys[id] = key
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	cursor := &Cursor{Sort: TaskSortDueDate, Desc: true, Key: "2030-01-02 10:00:00+00:00", ID: 42, Before: true}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor failed: %v", err)
+	}
+	if !reflect.DeepEqual(cursor, decoded) {
+		t.Errorf("Expected %+v, got %+v", cursor, decoded)
+	}
+
+	for _, token := range []string{"", "not a cursor", "e30", (&Cursor{Sort: "title"}).Encode()} {
+		if _, err := DecodeCursor(token); err != ErrInvalidCursor {
+			t.Errorf("Expected ErrInvalidCursor for %q, got %v", token, err)
+		}
+	}
+}
+
+func TestTaskRepository_ListTasksPage(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+
+	// Ties on every sort key, and tasks without a due date
+	due := time.Date(2030, time.June, 1, 9, 0, 0, 0, time.UTC)
+	priorities := []string{models.TaskPriorityLow, models.TaskPriorityHigh, models.TaskPriorityMedium}
+	for i := 0; i < 9; i++ {
+		task := &models.Task{
+			Title:    fmt.Sprintf("Task %d", i%4),
+			Status:   models.TaskStatusPending,
+			Priority: priorities[i%3],
+		}
+		if i%3 != 0 {
+			dueDate := due.AddDate(0, 0, i%2)
+			task.DueDate = &dueDate
+		}
+		if _, err := repo.CreateTask(ctx, task); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+	}
+
+	ids := func(tasks []*models.Task) []int {
+		ids := []int{}
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	for _, sortBy := range []string{TaskSortCreatedAt, TaskSortDueDate, TaskSortPriority, TaskSortTitle} {
+		for _, desc := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s desc=%v", sortBy, desc), func(t *testing.T) {
+				filters := TaskFilters{SortBy: sortBy, SortDesc: desc}
+				all, err := repo.ListTasks(ctx, filters)
+				if err != nil {
+					t.Fatalf("ListTasks failed: %v", err)
+				}
+				want := ids(all)
+
+				// Forwards from the first page
+				filters.Limit = 2
+				var forwards, lastPage []int
+				var last PageCursors
+				for page := 0; page < 10; page++ {
+					tasks, cursors, err := repo.ListTasksPage(ctx, filters)
+					if err != nil {
+						t.Fatalf("ListTasksPage failed: %v", err)
+					}
+					if (page == 0) != (cursors.Prev == nil) {
+						t.Fatalf("Unexpected previous cursor on page %d: %+v", page, cursors.Prev)
+					}
+					forwards = append(forwards, ids(tasks)...)
+					lastPage, last = ids(tasks), cursors
+					if cursors.Next == nil {
+						break
+					}
+					filters.Cursor = cursors.Next
+				}
+				if !reflect.DeepEqual(want, forwards) {
+					t.Fatalf("Expected %v going forwards, got %v", want, forwards)
+				}
+
+				// Backwards from the last page
+				backwards := lastPage
+				filters.Cursor = last.Prev
+				for filters.Cursor != nil {
+					tasks, cursors, err := repo.ListTasksPage(ctx, filters)
+					if err != nil {
+						t.Fatalf("ListTasksPage failed: %v", err)
+					}
+					if cursors.Next == nil {
+						t.Fatal("Expected a next cursor going backwards")
+					}
+					backwards = append(ids(tasks), backwards...)
+					filters.Cursor = cursors.Prev
+				}
+				if !reflect.DeepEqual(want, backwards) {
+					t.Errorf("Expected %v going backwards, got %v", want, backwards)
+				}
+			})
+		}
+	}
+
+	t.Run("pages do not shift on inserts", func(t *testing.T) {
+		filters := TaskFilters{SortBy: TaskSortTitle, Limit: 3}
+		first, cursors, err := repo.ListTasksPage(ctx, filters)
+		if err != nil {
+			t.Fatalf("ListTasksPage failed: %v", err)
+		}
+		if _, err := repo.CreateTask(ctx, &models.Task{Title: "A task", Status: models.TaskStatusPending}); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+
+		filters.Cursor = cursors.Next
+		second, _, err := repo.ListTasksPage(ctx, filters)
+		if err != nil {
+			t.Fatalf("ListTasksPage failed: %v", err)
+		}
+		all, err := repo.ListTasks(ctx, TaskFilters{SortBy: TaskSortTitle})
+		if err != nil {
+			t.Fatalf("ListTasks failed: %v", err)
+		}
+		// The new task sorts first, before the cursor
+		if !reflect.DeepEqual(ids(append(first, second...)), ids(all[1:7])) {
+			t.Errorf("Expected the second page to follow the first one")
+		}
+	})
+}
+
+func TestEventRepository_ListEventsPage(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewEventRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+
+	start := time.Date(2030, time.March, 1, 9, 0, 0, 0, time.UTC)
+	var want []int
+	for i := 0; i < 5; i++ {
+		// Two events share each start time
+		eventStart := start.Add(time.Duration(i/2) * time.Hour)
+		event, err := repo.CreateEvent(ctx, &models.Event{
+			Title:     fmt.Sprintf("Event %d", i),
+			StartTime: eventStart,
+			EndTime:   eventStart.Add(30 * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("CreateEvent failed: %v", err)
+		}
+		want = append(want, event.ID)
+	}
+
+	filters := EventFilters{Limit: 2}
+	var got []int
+	for {
+		events, cursors, err := repo.ListEventsPage(ctx, filters)
+		if err != nil {
+			t.Fatalf("ListEventsPage failed: %v", err)
+		}
+		for _, event := range events {
+			got = append(got, event.ID)
+		}
+		if cursors.Next == nil {
+			break
+		}
+		filters.Cursor = cursors.Next
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Offset pages link to the page before them
+	_, cursors, err := repo.ListEventsPage(ctx, EventFilters{Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("ListEventsPage failed: %v", err)
+	}
+	if cursors.Prev == nil || cursors.Next == nil {
+		t.Fatalf("Expected both cursors, got %+v", cursors)
+	}
+	events, _, err := repo.ListEventsPage(ctx, EventFilters{Limit: 2, Cursor: cursors.Prev})
+	if err != nil {
+		t.Fatalf("ListEventsPage failed: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != want[0] || events[1].ID != want[1] {
+		t.Errorf("Expected the first page, got %+v", events)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	_ "github.com/lib/pq"
 	_ "github.com/joho/godotenv/autoload"
 )
 
@@ -29,6 +30,10 @@ type Service interface {
 	// Initialize sets up the database schema and runs migrations
 	Initialize() error
 
+	// CheckMigrations returns ErrPendingMigrations while migrations are
+	// pending, without applying them
+	CheckMigrations() error
+
 	// Ping checks if the database connection is alive
 	Ping(ctx context.Context) error
 
@@ -45,16 +50,34 @@ type service struct {
 
 var (
 	dburl = os.Getenv("BLUEPRINT_DB_URL")
+	// dbdriver selects the dialect of the database: sqlite (the default)
+	// or postgres
+	dbdriver = os.Getenv("BLUEPRINT_DB_DRIVER")
 	dbInstance *service
 )
 
+// AutoMigrateFromEnv reads from the BLUEPRINT_DB_AUTO_MIGRATE environment
+// variable whether the server applies pending migrations when it starts,
+// which it does unless the variable is false. Otherwise migrations are
+// applied with cmd/migrate, and the server refuses to start while some
+// are pending.
+func AutoMigrateFromEnv() bool {
+	autoMigrate, err := strconv.ParseBool(os.Getenv("BLUEPRINT_DB_AUTO_MIGRATE"))
+	return err != nil || autoMigrate
+}
+
 func New() Service {
 	// Reuse Connection
 	if dbInstance != nil {
 		return dbInstance
 	}
 
-	db, err := sql.Open("sqlite3", dburl)
+	dialect, err := DialectByName(dbdriver)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := sql.Open(dialect.Driver, dburl)
 	if err != nil {
 		// This will not be a connection error, but a DSN parse error or
 		// another initialization error.
@@ -62,7 +85,7 @@ func New() Service {
 	}
 
 	// Configure connection pool settings
-	configureConnectionPool(db)
+	ConfigureConnection(db, dialect.ConnectionConfig())
 
 	// Test the connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -105,6 +128,12 @@ func (s *service) Initialize() error {
 	return migrationService.RunMigrations()
 }
 
+// CheckMigrations returns ErrPendingMigrations while migrations are
+// pending, without applying them
+func (s *service) CheckMigrations() error {
+	return NewMigrationService(s.db).Check()
+}
+
 // Health checks the health of the database connection by pinging the database.
 // It returns a map with keys indicating various health statistics.
 func (s *service) Health() map[string]string {
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect describes the SQL flavor of a database driver. Repositories write
+// their queries once, with ? placeholders and portable SQL, and the dialect
+// of their database adapts them.
+type Dialect struct {
+	// Name selects the dialect in the configuration
+	Name string
+	// Driver is the database/sql driver of the dialect
+	Driver string
+
+	// numbered is set for drivers taking $1, $2... placeholders
+	numbered bool
+	// returning is set for drivers reporting generated IDs through
+	// RETURNING rather than LastInsertId
+	returning bool
+	// timestamp is the column type of timestamps
+	timestamp string
+	// connectionConfig is the connection pool suited to the database
+	connectionConfig func() *ConnectionConfig
+}
+
+// Supported dialects
+var (
+	SQLite = &Dialect{
+		Name:             "sqlite",
+		Driver:           "sqlite3",
+		timestamp:        "DATETIME",
+		connectionConfig: DefaultConnectionConfig,
+	}
+	Postgres = &Dialect{
+		Name:             "postgres",
+		Driver:           "postgres",
+		numbered:         true,
+		returning:        true,
+		timestamp:        "TIMESTAMPTZ",
+		connectionConfig: PostgresConnectionConfig,
+	}
+)
+
+// dialects lists the supported dialects
+var dialects = []*Dialect{SQLite, Postgres}
+
+// DialectByName returns the dialect configured by name, SQLite when name is
+// empty
+func DialectByName(name string) (*Dialect, error) {
+	switch strings.ToLower(name) {
+	case "", SQLite.Name, "sqlite3":
+		return SQLite, nil
+	case Postgres.Name, "postgresql":
+		return Postgres, nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", name)
+}
+
+// DialectOf returns the dialect of the driver behind db
+func DialectOf(db *sql.DB) *Dialect {
+	if _, ok := db.Driver().(*pq.Driver); ok {
+		return Postgres
+	}
+	return SQLite
+}
+
+// ConnectionConfig returns the connection pool settings of the dialect
+func (d *Dialect) ConnectionConfig() *ConnectionConfig {
+	return d.connectionConfig()
+}
+
+// Rebind rewrites the ? placeholders of query into the placeholders of the
+// dialect. Question marks within quoted strings and identifiers are left
+// alone.
+func (d *Dialect) Rebind(query string) string {
+	if !d.numbered || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// bind returns an executor running the queries of the repositories on exec
+// in the dialect
+func (d *Dialect) bind(exec executor) executor {
+	if !d.numbered {
+		return exec
+	}
+	return reboundExecutor{exec: exec, dialect: d}
+}
+
+// insert runs an INSERT statement on exec and returns the ID of the new row
+func (d *Dialect) insert(ctx context.Context, exec executor, query string, args ...interface{}) (int64, error) {
+	if d.returning {
+		var id int64
+		err := exec.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// reboundExecutor rebinds the placeholders of the queries it runs
+type reboundExecutor struct {
+	exec    executor
+	dialect *Dialect
+}
+
+func (re reboundExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return re.exec.ExecContext(ctx, re.dialect.Rebind(query), args...)
+}
+
+func (re reboundExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return re.exec.QueryContext(ctx, re.dialect.Rebind(query), args...)
+}
+
+func (re reboundExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return re.exec.QueryRowContext(ctx, re.dialect.Rebind(query), args...)
+}
+
+// isUniqueViolation reports whether err is the violation of a unique
+// constraint
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDialect_Rebind(t *testing.T) {
+	query := `SELECT id FROM tasks WHERE title = ? AND description != 'why?' AND "odd?" = ? LIMIT ?`
+
+	if got := SQLite.Rebind(query); got != query {
+		t.Errorf("Expected SQLite queries to be left alone, got %q", got)
+	}
+
+	want := `SELECT id FROM tasks WHERE title = $1 AND description != 'why?' AND "odd?" = $2 LIMIT $3`
+	if got := Postgres.Rebind(query); got != want {
+		t.Errorf("Rebind() = %q, want %q", got, want)
+	}
+}
+
+func TestDialectByName(t *testing.T) {
+	tests := map[string]*Dialect{
+		"":           SQLite,
+		"sqlite":     SQLite,
+		"sqlite3":    SQLite,
+		"postgres":   Postgres,
+		"PostgreSQL": Postgres,
+	}
+	for name, want := range tests {
+		got, err := DialectByName(name)
+		if err != nil || got != want {
+			t.Errorf("DialectByName(%q) = %v, %v, want %s", name, got, err, want.Name)
+		}
+	}
+
+	if _, err := DialectByName("mysql"); err == nil {
+		t.Error("Expected an error for an unsupported driver")
+	}
+}
+
+func TestDialectOf(t *testing.T) {
+	db := openTestDB(t)
+
+	want := SQLite
+	if os.Getenv(testPostgresURL) != "" {
+		want = Postgres
+	}
+	if got := DialectOf(db); got != want {
+		t.Errorf("DialectOf() = %s, want %s", got.Name, want.Name)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"agenda/internal/models"
+	"agenda/internal/timezone"
 )
 
 // EventRepositoryInterface defines the contract for event repository operations
@@ -20,6 +21,7 @@ type EventRepositoryInterface interface {
 	UpdateEvent(ctx context.Context, event *models.Event) error
 	DeleteEvent(ctx context.Context, id int) error
 	ListEvents(ctx context.Context, filters EventFilters) ([]*models.Event, error)
+	ListEventsPage(ctx context.Context, filters EventFilters) ([]*models.Event, PageCursors, error)
 	CountEvents(ctx context.Context, filters EventFilters) (int64, error)
 
 	// Calendar-specific queries
@@ -28,8 +30,25 @@ type EventRepositoryInterface interface {
 	GetEventsByDay(ctx context.Context, date time.Time) ([]*models.Event, error)
 	GetUpcomingEvents(ctx context.Context, limit int) ([]*models.Event, error)
 	GetEventsByTitle(ctx context.Context, title string) ([]*models.Event, error)
+
+	// Recurrence queries
+	GetRecurringEvents(ctx context.Context, startsBefore time.Time) ([]*models.Event, error)
+	GetEventOverrides(ctx context.Context, parentID int) ([]*models.Event, error)
+	MoveEventOverride(ctx context.Context, override *models.Event) error
+
+	// iCalendar queries
+	GetEventByUID(ctx context.Context, uid string) (*models.Event, error)
+
+	// Trash methods
+	ListDeletedEvents(ctx context.Context) ([]*models.Event, error)
+	RestoreEvent(ctx context.Context, id int) error
+	// PurgeDeletedEvents sees the events of every user
+	PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
+// eventColumns lists the selected event columns in models.Event field order
+const eventColumns = "id, title, description, start_time, end_time, time_zone, recurrence_rule, exdates, parent_id, recurrence_id, uid, calendar_id, user_id, version, created_at, updated_at, deleted_at"
+
 // EventFilters represents filtering options for event queries
 type EventFilters struct {
 	Title       string
@@ -39,8 +58,27 @@ type EventFilters struct {
 	EndAfter    *time.Time
 	EndBefore   *time.Time
 	Search      string
-	Limit       int
-	Offset      int
+	// CalendarID limits the events to a calendar; 0 selects the events
+	// without a calendar
+	CalendarID *int
+	// ExcludeRecurring leaves out recurring series masters, whose occurrences
+	// are expanded by the service layer instead
+	ExcludeRecurring bool
+	Limit            int
+	Offset           int
+	// Cursor starts the results next to a row instead of at Offset
+	Cursor *Cursor
+}
+
+// EventSortStartTime is the sort of event lists, recorded in their cursors
+const EventSortStartTime = "start_time"
+
+// eventOrder orders events by start time, then by ID. Start times are
+// compared as stored, not as parsed by the driver.
+var eventOrder = keysetOrder{
+	sort: EventSortStartTime,
+	expr: "start_time",
+	key:  "CAST(start_time AS TEXT)",
 }
 
 // EventRepository implements EventRepositoryInterface
@@ -55,41 +93,81 @@ func NewEventRepository(db *sql.DB) EventRepositoryInterface {
 	}
 }
 
-// CreateEvent creates a new event in the database
+// CreateEvent creates a new event in the database, together with its
+// attendees. Overrides keep the attendees of their series.
 func (er *EventRepository) CreateEvent(ctx context.Context, event *models.Event) (*models.Event, error) {
 	query := `
-		INSERT INTO events (title, description, start_time, end_time, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO events (title, description, start_time, end_time, time_zone, recurrence_rule, exdates, parent_id, recurrence_id, uid, calendar_id, user_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
 	event.CreatedAt = now
 	event.UpdatedAt = now
+	event.Version = 1
+
+	// Assign a UID to new events; overrides share the UID of their series
+	if event.UID == "" && event.ParentID == nil {
+		event.UID = newUID()
+	}
 
 	// Validate time range
 	if !event.IsValidTimeRange() {
 		return nil, fmt.Errorf("invalid time range: end time must be after start time")
 	}
 
-	id, err := er.Create(ctx, query, event.Title, event.Description, event.StartTime, event.EndTime, event.CreatedAt, event.UpdatedAt)
+	// New events belong to the authenticated user
+	event.UserID = ownerID(ctx, event.UserID)
+
+	// Times are stored in UTC; the zone of the event is kept in TimeZone
+	event.StartTime = event.StartTime.UTC()
+	event.EndTime = event.EndTime.UTC()
+	event.RecurrenceID = utcPtr(event.RecurrenceID)
+
+	if event.Attendees == nil {
+		event.Attendees = []models.Attendee{}
+	}
+
+	err := er.inTransaction(ctx, func(tx executor) error {
+		id, err := er.dialect.insert(ctx, tx, query, event.Title, event.Description, event.StartTime, event.EndTime, event.TimeZone,
+			event.RecurrenceRule, event.ExDates, event.ParentID, event.RecurrenceID, event.UID, event.CalendarID, event.UserID, event.CreatedAt, event.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		event.ID = int(id)
+
+		// Overrides share the attendees of their series, which are not
+		// copied
+		if event.ParentID != nil {
+			return nil
+		}
+		for i := range event.Attendees {
+			attendee := &event.Attendees[i]
+			attendee.ID = 0
+			attendee.EventID = event.ID
+			if err := er.insertAttendee(ctx, tx, attendee); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event: %w", err)
 	}
 
-	event.ID = int(id)
 	return event, nil
 }
 
 // GetEventByID retrieves an event by its ID
 func (er *EventRepository) GetEventByID(ctx context.Context, id int) (*models.Event, error) {
 	query := `
-		SELECT id, title, description, start_time, end_time, created_at, updated_at
+		SELECT ` + eventColumns + `
 		FROM events
-		WHERE id = ?
+		WHERE id = ? AND ` + ownerCondition + ` AND ` + notDeleted + `
 	`
 
 	var event models.Event
-	err := er.GetByID(ctx, &event, query, id)
+	err := er.Get(ctx, &event, query, id, ownerArg(ctx))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
@@ -97,15 +175,23 @@ func (er *EventRepository) GetEventByID(ctx context.Context, id int) (*models.Ev
 		return nil, fmt.Errorf("failed to get event: %w", err)
 	}
 
+	if err := loadAttendees(ctx, er.conn(ctx), []*models.Event{&event}); err != nil {
+		return nil, fmt.Errorf("failed to get event attendees: %w", err)
+	}
+
 	return &event, nil
 }
 
-// UpdateEvent updates an existing event
+// UpdateEvent updates an existing event. The update only applies when the
+// event is still at event.Version, and bumps its version; ErrVersionConflict
+// is returned when the event was updated in the meantime. Moving a series to
+// another calendar moves its overrides along.
 func (er *EventRepository) UpdateEvent(ctx context.Context, event *models.Event) error {
 	query := `
 		UPDATE events 
-		SET title = ?, description = ?, start_time = ?, end_time = ?, updated_at = ?
-		WHERE id = ?
+		SET title = ?, description = ?, start_time = ?, end_time = ?, time_zone = ?, recurrence_rule = ?, exdates = ?, calendar_id = ?,
+			updated_at = ?, version = version + 1
+		WHERE id = ? AND ` + ownerCondition + ` AND ` + notDeleted + ` AND version = ?
 	`
 
 	// Validate time range
@@ -114,8 +200,54 @@ func (er *EventRepository) UpdateEvent(ctx context.Context, event *models.Event)
 	}
 
 	event.UpdatedAt = time.Now()
+	event.StartTime = event.StartTime.UTC()
+	event.EndTime = event.EndTime.UTC()
 
-	err := er.Update(ctx, query, event.Title, event.Description, event.StartTime, event.EndTime, event.UpdatedAt, event.ID)
+	err := er.inTransaction(ctx, func(tx executor) error {
+		var previous models.Event
+		err := tx.QueryRowContext(ctx, `SELECT start_time, recurrence_rule, exdates, calendar_id, version FROM events WHERE id = ? AND `+ownerCondition+` AND `+notDeleted,
+			event.ID, ownerArg(ctx)).Scan(&previous.StartTime, &previous.RecurrenceRule, &previous.ExDates, &previous.CalendarID, &previous.Version)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if previous.Version != event.Version {
+			return ErrVersionConflict
+		}
+
+		result, err := tx.ExecContext(ctx, query, event.Title, event.Description, event.StartTime, event.EndTime, event.TimeZone,
+			event.RecurrenceRule, event.ExDates, event.CalendarID, event.UpdatedAt, event.ID, ownerArg(ctx), event.Version)
+		if err != nil {
+			return err
+		}
+		if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+			if err == nil {
+				err = ErrVersionConflict
+			}
+			return err
+		}
+		event.Version++
+
+		// Overrides belong to the calendar of their series
+		if !previous.SameCalendar(event) {
+			_, err := tx.ExecContext(ctx, `UPDATE events SET calendar_id = ?, version = version + 1 WHERE parent_id = ? AND `+notDeleted,
+				event.CalendarID, event.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Re-arm the reminders when the schedule moved. Reminders of a
+		// recurring series are rolled forward by the scheduler.
+		if previous.StartTime.Equal(event.StartTime) && previous.RecurrenceRule == event.RecurrenceRule &&
+			previous.ExDates.Equal(event.ExDates) {
+			return nil
+		}
+		start := event.StartTime
+		return rescheduleReminders(ctx, tx, "event_id", event.ID, &start)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update event: %w", err)
 	}
@@ -123,11 +255,17 @@ func (er *EventRepository) UpdateEvent(ctx context.Context, event *models.Event)
 	return nil
 }
 
-// DeleteEvent removes an event from the database
+// DeleteEvent moves an event to the trash, together with the occurrence
+// overrides of a recurring series. Its reminders are kept for when it is
+// restored; they are not delivered while it is in the trash.
 func (er *EventRepository) DeleteEvent(ctx context.Context, id int) error {
-	query := `DELETE FROM events WHERE id = ?`
+	query := `
+		UPDATE events
+		SET deleted_at = ?, version = version + 1
+		WHERE (id = ? OR parent_id = ?) AND ` + ownerCondition + ` AND ` + notDeleted + `
+	`
 
-	err := er.Delete(ctx, query, id)
+	err := er.Update(ctx, query, time.Now().UTC(), id, id, ownerArg(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete event: %w", err)
 	}
@@ -137,7 +275,7 @@ func (er *EventRepository) DeleteEvent(ctx context.Context, id int) error {
 
 // ListEvents retrieves events with optional filtering
 func (er *EventRepository) ListEvents(ctx context.Context, filters EventFilters) ([]*models.Event, error) {
-	query, args := er.buildEventQuery(filters, false)
+	query, args := er.buildEventQuery(ctx, filters, false)
 
 	var events []*models.Event
 	err := er.List(ctx, &events, query, args...)
@@ -145,12 +283,42 @@ func (er *EventRepository) ListEvents(ctx context.Context, filters EventFilters)
 		return nil, fmt.Errorf("failed to list events: %w", err)
 	}
 
+	if err := loadAttendees(ctx, er.conn(ctx), events); err != nil {
+		return nil, fmt.Errorf("failed to list event attendees: %w", err)
+	}
+
 	return events, nil
 }
 
+// ListEventsPage retrieves a page of at most filters.Limit events, starting
+// at filters.Cursor when set, along with the cursors of the adjacent pages
+func (er *EventRepository) ListEventsPage(ctx context.Context, filters EventFilters) ([]*models.Event, PageCursors, error) {
+	// One more event tells whether a next page exists
+	limit := filters.Limit
+	if limit > 0 {
+		filters.Limit++
+	}
+	if filters.Cursor != nil {
+		filters.Offset = 0
+	}
+
+	events, err := er.ListEvents(ctx, filters)
+	if err != nil {
+		return nil, PageCursors{}, err
+	}
+
+	events, cursors, err := paginate(ctx, er.Repository, "events", eventOrder, events, func(event *models.Event) int { return event.ID },
+		limit, filters.Offset, filters.Cursor)
+	if err != nil {
+		return nil, PageCursors{}, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	return events, cursors, nil
+}
+
 // CountEvents returns the total number of events matching the filters
 func (er *EventRepository) CountEvents(ctx context.Context, filters EventFilters) (int64, error) {
-	query, args := er.buildEventQuery(filters, true)
+	query, args := er.buildEventQuery(ctx, filters, true)
 
 	count, err := er.Count(ctx, query, args...)
 	if err != nil {
@@ -160,113 +328,345 @@ func (er *EventRepository) CountEvents(ctx context.Context, filters EventFilters
 	return count, nil
 }
 
-// GetEventsByDateRange retrieves events within a specific date range
+// GetEventsByDateRange retrieves non-recurring events within a specific date range
 func (er *EventRepository) GetEventsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Event, error) {
 	filters := EventFilters{
-		StartAfter:  &startDate,
-		StartBefore: &endDate,
+		StartAfter:       &startDate,
+		StartBefore:      &endDate,
+		ExcludeRecurring: true,
 	}
 	return er.ListEvents(ctx, filters)
 }
 
-// GetEventsByMonth retrieves all events for a specific month (calendar view)
+// GetEventsByMonth retrieves all non-recurring events for a specific month
+// (calendar view). The month starts and ends at midnight in the request time
+// zone.
 func (er *EventRepository) GetEventsByMonth(ctx context.Context, year int, month time.Month) ([]*models.Event, error) {
-	// Get the first day of the month
-	startOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
-	// Get the first day of the next month
-	endOfMonth := startOfMonth.AddDate(0, 1, 0)
+	startOfMonth, endOfMonth := timezone.MonthBounds(year, month, timezone.FromContext(ctx))
+	startOfMonth, endOfMonth = startOfMonth.UTC(), endOfMonth.UTC()
 
 	query := `
-		SELECT id, title, description, start_time, end_time, created_at, updated_at
+		SELECT ` + eventColumns + `
 		FROM events
-		WHERE (start_time >= ? AND start_time < ?) 
+		WHERE recurrence_rule = '' AND ` + ownerCondition + ` AND ` + notDeleted + `
+		  AND ((start_time >= ? AND start_time < ?)
 		   OR (end_time >= ? AND end_time < ?)
-		   OR (start_time < ? AND end_time >= ?)
+		   OR (start_time < ? AND end_time >= ?))
 		ORDER BY start_time ASC
 	`
 
 	var events []*models.Event
-	err := er.List(ctx, &events, query, startOfMonth, endOfMonth, startOfMonth, endOfMonth, startOfMonth, endOfMonth)
+	err := er.List(ctx, &events, query, ownerArg(ctx), startOfMonth, endOfMonth, startOfMonth, endOfMonth, startOfMonth, endOfMonth)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get events for month %d/%d: %w", month, year, err)
 	}
 
+	if err := loadAttendees(ctx, er.conn(ctx), events); err != nil {
+		return nil, fmt.Errorf("failed to get event attendees: %w", err)
+	}
+
 	return events, nil
 }
 
-// GetEventsByDay retrieves all events for a specific day
+// GetEventsByDay retrieves all non-recurring events for a specific day. The
+// day is the calendar day of date in the request time zone.
 func (er *EventRepository) GetEventsByDay(ctx context.Context, date time.Time) ([]*models.Event, error) {
-	// Get start and end of the day
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.AddDate(0, 0, 1)
+	startOfDay, endOfDay := timezone.DayBounds(date, timezone.FromContext(ctx))
+	startOfDay, endOfDay = startOfDay.UTC(), endOfDay.UTC()
 
 	query := `
-		SELECT id, title, description, start_time, end_time, created_at, updated_at
+		SELECT ` + eventColumns + `
 		FROM events
-		WHERE (start_time >= ? AND start_time < ?) 
+		WHERE recurrence_rule = '' AND ` + ownerCondition + ` AND ` + notDeleted + `
+		  AND ((start_time >= ? AND start_time < ?)
 		   OR (end_time >= ? AND end_time < ?)
-		   OR (start_time < ? AND end_time >= ?)
+		   OR (start_time < ? AND end_time >= ?))
 		ORDER BY start_time ASC
 	`
 
 	var events []*models.Event
-	err := er.List(ctx, &events, query, startOfDay, endOfDay, startOfDay, endOfDay, startOfDay, endOfDay)
+	err := er.List(ctx, &events, query, ownerArg(ctx), startOfDay, endOfDay, startOfDay, endOfDay, startOfDay, endOfDay)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get events for day %s: %w", date.Format("2006-01-02"), err)
 	}
 
+	if err := loadAttendees(ctx, er.conn(ctx), events); err != nil {
+		return nil, fmt.Errorf("failed to get event attendees: %w", err)
+	}
+
 	return events, nil
 }
 
-// GetUpcomingEvents retrieves upcoming events (starting from now)
+// GetUpcomingEvents retrieves upcoming non-recurring events (starting from now)
 func (er *EventRepository) GetUpcomingEvents(ctx context.Context, limit int) ([]*models.Event, error) {
 	query := `
-		SELECT id, title, description, start_time, end_time, created_at, updated_at
+		SELECT ` + eventColumns + `
 		FROM events
-		WHERE start_time >= ?
+		WHERE start_time >= ? AND recurrence_rule = '' AND ` + ownerCondition + ` AND ` + notDeleted + `
 		ORDER BY start_time ASC
 		LIMIT ?
 	`
 
-	now := time.Now()
+	now := time.Now().UTC()
 	var events []*models.Event
-	err := er.List(ctx, &events, query, now, limit)
+	err := er.List(ctx, &events, query, now, ownerArg(ctx), limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get upcoming events: %w", err)
 	}
 
+	if err := loadAttendees(ctx, er.conn(ctx), events); err != nil {
+		return nil, fmt.Errorf("failed to get upcoming event attendees: %w", err)
+	}
+
 	return events, nil
 }
 
 // GetEventsByTitle retrieves events by title (exact match)
 func (er *EventRepository) GetEventsByTitle(ctx context.Context, title string) ([]*models.Event, error) {
 	query := `
-		SELECT id, title, description, start_time, end_time, created_at, updated_at
+		SELECT ` + eventColumns + `
 		FROM events
-		WHERE title = ?
+		WHERE title = ? AND ` + ownerCondition + ` AND ` + notDeleted + `
 		ORDER BY start_time ASC
 	`
 
 	var events []*models.Event
-	err := er.List(ctx, &events, query, title)
+	err := er.List(ctx, &events, query, title, ownerArg(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get events by title: %w", err)
 	}
 
+	if err := loadAttendees(ctx, er.conn(ctx), events); err != nil {
+		return nil, fmt.Errorf("failed to get event attendees: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetRecurringEvents retrieves the masters of recurring series whose first
+// occurrence starts before the given time
+func (er *EventRepository) GetRecurringEvents(ctx context.Context, startsBefore time.Time) ([]*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE recurrence_rule != '' AND start_time < ? AND ` + ownerCondition + ` AND ` + notDeleted + `
+		ORDER BY start_time ASC
+	`
+
+	var events []*models.Event
+	err := er.List(ctx, &events, query, startsBefore.UTC(), ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring events: %w", err)
+	}
+
+	if err := loadAttendees(ctx, er.conn(ctx), events); err != nil {
+		return nil, fmt.Errorf("failed to get recurring event attendees: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetEventOverrides retrieves the occurrence overrides of a recurring series
+func (er *EventRepository) GetEventOverrides(ctx context.Context, parentID int) ([]*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE parent_id = ? AND ` + ownerCondition + ` AND ` + notDeleted + `
+		ORDER BY recurrence_id ASC
+	`
+
+	var events []*models.Event
+	err := er.List(ctx, &events, query, parentID, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event overrides: %w", err)
+	}
+
+	if err := loadAttendees(ctx, er.conn(ctx), events); err != nil {
+		return nil, fmt.Errorf("failed to get event override attendees: %w", err)
+	}
+
 	return events, nil
 }
 
+// MoveEventOverride moves an occurrence override to the series given by its
+// ParentID, and to the calendar of that series, keeping its RecurrenceID
+func (er *EventRepository) MoveEventOverride(ctx context.Context, override *models.Event) error {
+	query := `
+		UPDATE events
+		SET parent_id = ?, calendar_id = (SELECT calendar_id FROM events WHERE id = ?), updated_at = ?, version = version + 1
+		WHERE id = ? AND parent_id IS NOT NULL AND ` + ownerCondition + ` AND ` + notDeleted + ` AND version = ?
+	`
+
+	override.UpdatedAt = time.Now()
+	result, err := er.conn(ctx).ExecContext(ctx, query, override.ParentID, override.ParentID, override.UpdatedAt,
+		override.ID, ownerArg(ctx), override.Version)
+	if err != nil {
+		return fmt.Errorf("failed to move event override: %w", err)
+	}
+	if moved, err := result.RowsAffected(); err != nil || moved == 0 {
+		if err == nil {
+			err = ErrVersionConflict
+		}
+		return fmt.Errorf("failed to move event override: %w", err)
+	}
+	override.Version++
+
+	return er.conn(ctx).QueryRowContext(ctx, `SELECT calendar_id FROM events WHERE id = ?`, override.ID).Scan(&override.CalendarID)
+}
+
+// GetEventByUID retrieves an event by its iCalendar UID
+func (er *EventRepository) GetEventByUID(ctx context.Context, uid string) (*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE uid = ? AND ` + ownerCondition + ` AND ` + notDeleted + `
+	`
+
+	var event models.Event
+	err := er.Get(ctx, &event, query, uid, ownerArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get event by uid: %w", err)
+	}
+
+	if err := loadAttendees(ctx, er.conn(ctx), []*models.Event{&event}); err != nil {
+		return nil, fmt.Errorf("failed to get event attendees: %w", err)
+	}
+
+	return &event, nil
+}
+
+// ListDeletedEvents retrieves the events in the trash, most recently
+// deleted first. Overrides are left out, as they are restored with their
+// series.
+func (er *EventRepository) ListDeletedEvents(ctx context.Context) ([]*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE deleted_at IS NOT NULL AND parent_id IS NULL AND ` + ownerCondition + `
+		ORDER BY deleted_at DESC, id DESC
+	`
+
+	var events []*models.Event
+	err := er.List(ctx, &events, query, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted events: %w", err)
+	}
+
+	if err := loadAttendees(ctx, er.conn(ctx), events); err != nil {
+		return nil, fmt.Errorf("failed to list deleted event attendees: %w", err)
+	}
+
+	return events, nil
+}
+
+// RestoreEvent takes an event out of the trash and bumps its version. The
+// overrides deleted along with a recurring series are restored with it,
+// and the event gets a new UID when another event took its UID in the
+// meantime. An override can only be restored while its series is not
+// deleted. sql.ErrNoRows is returned when the event does not exist or is
+// an override of a deleted series, and ErrNotDeleted when it is not in
+// the trash.
+func (er *EventRepository) RestoreEvent(ctx context.Context, id int) error {
+	err := er.inTransaction(ctx, func(tx executor) error {
+		var event models.Event
+		err := tx.QueryRowContext(ctx, `SELECT parent_id, uid, start_time, deleted_at FROM events WHERE id = ? AND `+ownerCondition,
+			id, ownerArg(ctx)).Scan(&event.ParentID, &event.UID, &event.StartTime, &event.DeletedAt)
+		if err != nil {
+			return err
+		}
+		if event.DeletedAt == nil {
+			return ErrNotDeleted
+		}
+		if event.ParentID != nil {
+			var seriesLive bool
+			err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM events WHERE id = ? AND `+notDeleted+`)`,
+				*event.ParentID).Scan(&seriesLive)
+			if err != nil {
+				return err
+			}
+			if !seriesLive {
+				return sql.ErrNoRows
+			}
+		}
+
+		if event.UID, err = restoredUID(ctx, tx, "events", event.UID); err != nil {
+			return err
+		}
+
+		// Overrides deleted on their own before the series stay deleted
+		_, err = tx.ExecContext(ctx, `
+			UPDATE events
+			SET deleted_at = NULL, version = version + 1
+			WHERE parent_id = ? AND deleted_at = (SELECT deleted_at FROM events WHERE id = ?)
+		`, id, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE events SET deleted_at = NULL, uid = ?, version = version + 1 WHERE id = ?`,
+			event.UID, id)
+		if err != nil {
+			return err
+		}
+
+		// Re-arm the reminders cancelled while the event was in the trash
+		return rescheduleReminders(ctx, tx, "event_id", id, &event.StartTime)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore event: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeDeletedEvents permanently removes the events of any user deleted
+// before the given time, together with their reminders and attendees. It
+// returns the number of events removed, overrides included.
+func (er *EventRepository) PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := er.inTransaction(ctx, func(tx executor) error {
+		before := deletedBefore.UTC()
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM reminders WHERE event_id IN (SELECT id FROM events WHERE deleted_at < ?)`, before)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM event_attendees WHERE event_id IN (SELECT id FROM events WHERE deleted_at < ?)`, before)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM events WHERE deleted_at < ?`, before)
+		if err != nil {
+			return err
+		}
+		purged, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted events: %w", err)
+	}
+
+	return purged, nil
+}
+
 // buildEventQuery constructs a SQL query with WHERE conditions based on filters
-func (er *EventRepository) buildEventQuery(filters EventFilters, isCount bool) (string, []interface{}) {
+func (er *EventRepository) buildEventQuery(ctx context.Context, filters EventFilters, isCount bool) (string, []interface{}) {
 	var baseQuery string
 	if isCount {
 		baseQuery = "SELECT COUNT(*) FROM events"
 	} else {
-		baseQuery = "SELECT id, title, description, start_time, end_time, created_at, updated_at FROM events"
+		baseQuery = "SELECT " + eventColumns + " FROM events"
 	}
 
-	var conditions []string
-	var args []interface{}
+	// Only the rows of the authenticated user are visible, and deleted
+	// events stay in the trash
+	conditions := []string{ownerCondition, notDeleted}
+	args := []interface{}{ownerArg(ctx)}
 
 	// Title filter (exact match)
 	if filters.Title != "" {
@@ -283,41 +683,62 @@ func (er *EventRepository) buildEventQuery(filters EventFilters, isCount bool) (
 	// Start time range filters
 	if filters.StartAfter != nil {
 		conditions = append(conditions, "start_time >= ?")
-		args = append(args, *filters.StartAfter)
+		args = append(args, filters.StartAfter.UTC())
 	}
 
 	if filters.StartBefore != nil {
 		conditions = append(conditions, "start_time <= ?")
-		args = append(args, *filters.StartBefore)
+		args = append(args, filters.StartBefore.UTC())
 	}
 
 	// End time range filters
 	if filters.EndAfter != nil {
 		conditions = append(conditions, "end_time >= ?")
-		args = append(args, *filters.EndAfter)
+		args = append(args, filters.EndAfter.UTC())
 	}
 
 	if filters.EndBefore != nil {
 		conditions = append(conditions, "end_time <= ?")
-		args = append(args, *filters.EndBefore)
+		args = append(args, filters.EndBefore.UTC())
 	}
 
-	// Search filter (searches in title and description)
-	if filters.Search != "" {
-		conditions = append(conditions, "(title LIKE ? OR description LIKE ?)")
-		searchTerm := "%" + filters.Search + "%"
-		args = append(args, searchTerm, searchTerm)
+	// Search filter (words or word prefixes in title and description)
+	if match := searchMatch(filters.Search); match != "" {
+		condition, arg := er.searchCondition("events", match)
+		conditions = append(conditions, condition)
+		args = append(args, arg)
 	}
 
-	// Build WHERE clause
-	query := baseQuery
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	// Calendar filter
+	if filters.CalendarID != nil {
+		if *filters.CalendarID == 0 {
+			conditions = append(conditions, "calendar_id IS NULL")
+		} else {
+			conditions = append(conditions, "calendar_id = ?")
+			args = append(args, *filters.CalendarID)
+		}
+	}
+
+	// Recurring series filter
+	if filters.ExcludeRecurring {
+		conditions = append(conditions, "recurrence_rule = ''")
 	}
 
+	// Keyset pagination; pages before a cursor are fetched backwards
+	reverse := false
+	if filters.Cursor != nil && !isCount {
+		condition, cursorArgs := eventOrder.condition(filters.Cursor)
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+		reverse = filters.Cursor.Before
+	}
+
+	// Build WHERE clause
+	query := baseQuery + " WHERE " + strings.Join(conditions, " AND ")
+
 	// Add ordering and pagination for non-count queries
 	if !isCount {
-		query += " ORDER BY start_time ASC"
+		query += " ORDER BY " + eventOrder.orderBy(reverse)
 
 		if filters.Limit > 0 {
 			query += " LIMIT ?"
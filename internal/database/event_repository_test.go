@@ -3,42 +3,20 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"strings"
 	"testing"
 	"time"
 
+	"agenda/internal/auth"
 	"agenda/internal/models"
+	"agenda/internal/timezone"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 func setupEventTestDB(t *testing.T) *sql.DB {
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
-
-	// Create events table
-	schema := `
-		CREATE TABLE events (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			title TEXT NOT NULL,
-			description TEXT,
-			start_time DATETIME NOT NULL,
-			end_time DATETIME NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);
-
-		CREATE INDEX idx_events_start_time ON events(start_time);
-		CREATE INDEX idx_events_date_range ON events(start_time, end_time);
-	`
-
-	if _, err := db.Exec(schema); err != nil {
-		t.Fatalf("Failed to create test schema: %v", err)
-	}
-
-	return db
+	return migratedTestDB(t)
 }
 
 func createTestEvent(title, description string, startTime, endTime time.Time) *models.Event {
@@ -232,6 +210,45 @@ func TestEventRepository_UpdateEvent(t *testing.T) {
 	}
 }
 
+func TestEventRepository_UpdateEvent_VersionConflict(t *testing.T) {
+	db := setupEventTestDB(t)
+	defer db.Close()
+
+	repo := NewEventRepository(db)
+	ctx := context.Background()
+
+	startTime := time.Now().Add(time.Hour)
+	created, err := repo.CreateEvent(ctx, createTestEvent("Versioned Event", "", startTime, startTime.Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+	if created.Version != 1 {
+		t.Fatalf("Expected new events at version 1, got %d", created.Version)
+	}
+
+	first, second := *created, *created
+	first.Title = "First writer"
+	if err := repo.UpdateEvent(ctx, &first); err != nil {
+		t.Fatalf("Unexpected error updating event: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected the update to bump the version to 2, got %d", first.Version)
+	}
+
+	second.Title = "Second writer"
+	if err := repo.UpdateEvent(ctx, &second); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict, got %v", err)
+	}
+
+	stored, err := repo.GetEventByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Failed to get event: %v", err)
+	}
+	if stored.Title != "First writer" || stored.Version != 2 {
+		t.Errorf("Expected the first update at version 2, got %q at version %d", stored.Title, stored.Version)
+	}
+}
+
 func TestEventRepository_UpdateEvent_InvalidTimeRange(t *testing.T) {
 	db := setupEventTestDB(t)
 	defer db.Close()
@@ -586,6 +603,67 @@ func TestEventRepository_GetEventsByDay(t *testing.T) {
 	}
 }
 
+func TestEventRepository_GetEventsByDay_TimeZone(t *testing.T) {
+	db := setupEventTestDB(t)
+	defer db.Close()
+
+	repo := NewEventRepository(db)
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+
+	// 21:30 on January 14 in New York is already January 15 in UTC and
+	// Tokyo; the event is sent with its Tokyo offset
+	start := time.Date(2024, time.January, 14, 21, 30, 0, 0, newYork).In(tokyo)
+	event := createTestEvent("Late Event", "Description", start, start.Add(time.Hour))
+	if _, err := repo.CreateEvent(context.Background(), event); err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		loc      *time.Location
+		day      time.Time
+		expected int
+	}{
+		{"New York day", newYork, time.Date(2024, time.January, 14, 0, 0, 0, 0, newYork), 1},
+		{"New York next day", newYork, time.Date(2024, time.January, 15, 0, 0, 0, 0, newYork), 0},
+		{"UTC day", time.UTC, time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), 1},
+		{"Tokyo previous day", tokyo, time.Date(2024, time.January, 14, 0, 0, 0, 0, tokyo), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := timezone.WithLocation(context.Background(), tt.loc)
+			results, err := repo.GetEventsByDay(ctx, tt.day)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(results) != tt.expected {
+				t.Errorf("Expected %d events, got %d", tt.expected, len(results))
+			}
+		})
+	}
+
+	// Times are stored in UTC; PostgreSQL stores instants whatever their
+	// zone
+	if DialectOf(db) != SQLite {
+		return
+	}
+	var stored string
+	if err := db.QueryRow(rebind(db, "SELECT CAST(start_time AS TEXT) FROM events WHERE id = ?"), event.ID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read start time: %v", err)
+	}
+	if !strings.HasPrefix(stored, "2024-01-15 02:30:00") || !strings.HasSuffix(stored, "+00:00") {
+		t.Errorf("Expected start time stored in UTC, got %q", stored)
+	}
+}
+
 func TestEventRepository_GetUpcomingEvents(t *testing.T) {
 	db := setupEventTestDB(t)
 	defer db.Close()
@@ -683,3 +761,282 @@ func TestEventRepository_GetEventsByTitle(t *testing.T) {
 	}
 }
 
+
+func TestEventRepository_RecurringEvents(t *testing.T) {
+	db := setupEventTestDB(t)
+	defer db.Close()
+
+	repo := NewEventRepository(db)
+	ctx := context.Background()
+
+	start := time.Date(2024, time.January, 15, 9, 0, 0, 0, time.UTC)
+	exdate := start.AddDate(0, 0, 2)
+
+	// Create a daily series with an excluded date
+	master := createTestEvent("Standup", "Daily standup", start, start.Add(15*time.Minute))
+	master.RecurrenceRule = "FREQ=DAILY;COUNT=10"
+	master.ExDates = models.TimeList{exdate}
+	master, err := repo.CreateEvent(ctx, master)
+	if err != nil {
+		t.Fatalf("Failed to create recurring event: %v", err)
+	}
+
+	// Create an override for the second occurrence
+	recurrenceID := start.AddDate(0, 0, 1)
+	override := createTestEvent("Standup (moved)", "Daily standup", recurrenceID.Add(time.Hour), recurrenceID.Add(75*time.Minute))
+	override.ParentID = &master.ID
+	override.RecurrenceID = &recurrenceID
+	override, err = repo.CreateEvent(ctx, override)
+	if err != nil {
+		t.Fatalf("Failed to create override: %v", err)
+	}
+
+	// Round trip of the recurrence columns
+	retrieved, err := repo.GetEventByID(ctx, master.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if retrieved.RecurrenceRule != "FREQ=DAILY;COUNT=10" {
+		t.Errorf("Expected recurrence rule to round trip, got %q", retrieved.RecurrenceRule)
+	}
+	if len(retrieved.ExDates) != 1 || !retrieved.ExDates[0].Equal(exdate) {
+		t.Errorf("Expected exdates [%v], got %v", exdate, retrieved.ExDates)
+	}
+
+	// Masters are returned by GetRecurringEvents only when they start before the bound
+	masters, err := repo.GetRecurringEvents(ctx, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(masters) != 1 || masters[0].ID != master.ID {
+		t.Errorf("Expected the recurring master, got %d events", len(masters))
+	}
+	masters, err = repo.GetRecurringEvents(ctx, start)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(masters) != 0 {
+		t.Errorf("Expected no recurring events starting before %v, got %d", start, len(masters))
+	}
+
+	// Overrides are linked to their master
+	overrides, err := repo.GetEventOverrides(ctx, master.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(overrides) != 1 || overrides[0].ID != override.ID {
+		t.Fatalf("Expected the override, got %d events", len(overrides))
+	}
+	if overrides[0].RecurrenceID == nil || !overrides[0].RecurrenceID.Equal(recurrenceID) {
+		t.Errorf("Expected recurrence ID %v, got %v", recurrenceID, overrides[0].RecurrenceID)
+	}
+
+	// Calendar queries return the override but not the master, whose
+	// occurrences are expanded by the service layer
+	results, err := repo.GetEventsByMonth(ctx, 2024, time.January)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != override.ID {
+		t.Errorf("Expected only the override in the month view, got %d events", len(results))
+	}
+
+	// Deleting the master removes its overrides
+	if err := repo.DeleteEvent(ctx, master.ID); err != nil {
+		t.Fatalf("Unexpected error deleting recurring event: %v", err)
+	}
+	if _, err := repo.GetEventByID(ctx, override.ID); err == nil {
+		t.Error("Expected override to be deleted with its master")
+	}
+}
+
+func TestEventRepository_OwnerScoping(t *testing.T) {
+	db := setupEventTestDB(t)
+	defer db.Close()
+
+	repo := NewEventRepository(db)
+	alice := auth.WithUserID(context.Background(), 1)
+	bob := auth.WithUserID(context.Background(), 2)
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	event, err := repo.CreateEvent(alice, createTestEvent("Alice's event", "", start, start.Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	series := createTestEvent("Alice's series", "", start, start.Add(time.Hour))
+	series.RecurrenceRule = "FREQ=DAILY"
+	if _, err := repo.CreateEvent(alice, series); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	// Another user cannot read, update or delete the events
+	if _, err := repo.GetEventByID(bob, event.ID); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for another user's event, got %v", err)
+	}
+	if _, err := repo.GetEventByUID(bob, event.UID); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for another user's UID, got %v", err)
+	}
+	hijacked := *event
+	hijacked.Title = "Hijacked"
+	if err := repo.UpdateEvent(bob, &hijacked); err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+	if err := repo.DeleteEvent(bob, event.ID); err != nil {
+		t.Fatalf("DeleteEvent failed: %v", err)
+	}
+
+	stored, err := repo.GetEventByID(alice, event.ID)
+	if err != nil {
+		t.Fatalf("Expected owner to still see the event: %v", err)
+	}
+	if stored.Title != "Alice's event" {
+		t.Errorf("Expected title to be unchanged, got %s", stored.Title)
+	}
+
+	queries := map[string]func(ctx context.Context) ([]*models.Event, error){
+		"ListEvents": func(ctx context.Context) ([]*models.Event, error) {
+			return repo.ListEvents(ctx, EventFilters{})
+		},
+		"GetEventsByDay": func(ctx context.Context) ([]*models.Event, error) {
+			return repo.GetEventsByDay(ctx, start)
+		},
+		"GetUpcomingEvents": func(ctx context.Context) ([]*models.Event, error) {
+			return repo.GetUpcomingEvents(ctx, 10)
+		},
+		"GetEventsByTitle": func(ctx context.Context) ([]*models.Event, error) {
+			return repo.GetEventsByTitle(ctx, "Alice's event")
+		},
+		"GetRecurringEvents": func(ctx context.Context) ([]*models.Event, error) {
+			return repo.GetRecurringEvents(ctx, start.Add(time.Hour))
+		},
+	}
+	for name, query := range queries {
+		owned, err := query(alice)
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if len(owned) == 0 {
+			t.Errorf("%s: expected the owner to see events", name)
+		}
+
+		others, err := query(bob)
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if len(others) != 0 {
+			t.Errorf("%s: expected another user to see no events, got %d", name, len(others))
+		}
+	}
+}
+
+func TestEventRepository_Trash(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewEventRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+
+	start := time.Date(2030, time.January, 15, 9, 0, 0, 0, time.UTC)
+	master := createTestEvent("Standup", "Daily standup", start, start.Add(15*time.Minute))
+	master.RecurrenceRule = "FREQ=DAILY;COUNT=10"
+	master.UID = "standup@example.com"
+	master, err := repo.CreateEvent(ctx, master)
+	if err != nil {
+		t.Fatalf("Failed to create recurring event: %v", err)
+	}
+
+	createOverride := func(days int) *models.Event {
+		recurrenceID := start.AddDate(0, 0, days)
+		override := createTestEvent("Standup (moved)", "Daily standup", recurrenceID.Add(time.Hour), recurrenceID.Add(75*time.Minute))
+		override.ParentID = &master.ID
+		override.RecurrenceID = &recurrenceID
+		override, err := repo.CreateEvent(ctx, override)
+		if err != nil {
+			t.Fatalf("Failed to create override: %v", err)
+		}
+		return override
+	}
+	kept := createOverride(1)
+	dropped := createOverride(2)
+
+	// An override deleted on its own stays deleted when the series is restored
+	if err := repo.DeleteEvent(ctx, dropped.ID); err != nil {
+		t.Fatalf("DeleteEvent failed: %v", err)
+	}
+	if err := repo.DeleteEvent(ctx, master.ID); err != nil {
+		t.Fatalf("DeleteEvent failed: %v", err)
+	}
+
+	if _, err := repo.GetEventByID(ctx, master.ID); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+	if overrides, err := repo.GetEventOverrides(ctx, master.ID); err != nil || len(overrides) != 0 {
+		t.Errorf("Expected the overrides to be deleted, got %d (%v)", len(overrides), err)
+	}
+	if masters, err := repo.GetRecurringEvents(ctx, start.AddDate(1, 0, 0)); err != nil || len(masters) != 0 {
+		t.Errorf("Expected no recurring events, got %d (%v)", len(masters), err)
+	}
+
+	deleted, err := repo.ListDeletedEvents(ctx)
+	if err != nil {
+		t.Fatalf("ListDeletedEvents failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != master.ID {
+		t.Fatalf("Expected only the series in the trash, got %+v", deleted)
+	}
+
+	if err := repo.RestoreEvent(ctx, kept.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected overrides not to be restored while the series is deleted, got %v", err)
+	}
+
+	// The UID of the series was taken by an import in the meantime
+	imported := createTestEvent("Standup", "Imported", start, start.Add(15*time.Minute))
+	imported.UID = master.UID
+	if _, err := repo.CreateEvent(ctx, imported); err != nil {
+		t.Fatalf("Expected the UID of a deleted event to be free, got %v", err)
+	}
+
+	if err := repo.RestoreEvent(ctx, master.ID); err != nil {
+		t.Fatalf("RestoreEvent failed: %v", err)
+	}
+	restored, err := repo.GetEventByID(ctx, master.ID)
+	if err != nil {
+		t.Fatalf("GetEventByID failed: %v", err)
+	}
+	if restored.UID == master.UID || restored.Version != master.Version+2 {
+		t.Errorf("Expected a new UID and version, got %q at version %d", restored.UID, restored.Version)
+	}
+	overrides, err := repo.GetEventOverrides(ctx, master.ID)
+	if err != nil {
+		t.Fatalf("GetEventOverrides failed: %v", err)
+	}
+	if len(overrides) != 1 || overrides[0].ID != kept.ID {
+		t.Errorf("Expected only the override deleted with the series back, got %+v", overrides)
+	}
+
+	if err := repo.RestoreEvent(ctx, master.ID); !errors.Is(err, ErrNotDeleted) {
+		t.Errorf("Expected ErrNotDeleted, got %v", err)
+	}
+
+	purged, err := repo.PurgeDeletedEvents(ctx, time.Now().Add(time.Second))
+	if err != nil || purged != 1 {
+		t.Errorf("Expected the dropped override to be purged, got %d (%v)", purged, err)
+	}
+
+	// Once the series is back, an override deleted on its own can be
+	// restored
+	moved := createOverride(3)
+	if err := repo.DeleteEvent(ctx, moved.ID); err != nil {
+		t.Fatalf("DeleteEvent failed: %v", err)
+	}
+	if err := repo.RestoreEvent(ctx, moved.ID); err != nil {
+		t.Fatalf("RestoreEvent failed: %v", err)
+	}
+	overrides, err = repo.GetEventOverrides(ctx, master.ID)
+	if err != nil {
+		t.Fatalf("GetEventOverrides failed: %v", err)
+	}
+	if len(overrides) != 2 {
+		t.Errorf("Expected the override to be back, got %+v", overrides)
+	}
+}
@@ -1,116 +1,397 @@
 package database
 
 import (
+	"crypto/sha256"
 	"database/sql"
 	"embed"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
-//go:embed migrations/*.sql
+// migrationFiles holds a directory of migrations per dialect, named after
+// the dialect. The directories hold the same versions, each as a pair of
+// NNN_name.up.sql and NNN_name.down.sql files.
+//
+//go:embed migrations/sqlite/*.sql migrations/postgres/*.sql
 var migrationFiles embed.FS
 
+// Migration errors
+var (
+	ErrMigrationModified     = errors.New("applied migration was modified")
+	ErrMigrationMissing      = errors.New("applied migration has no migration file")
+	ErrIrreversibleMigration = errors.New("migration has no down migration")
+	ErrPendingMigrations     = errors.New("migrations are pending")
+	ErrNoAppliedMigrations   = errors.New("no migration is applied")
+	ErrInvalidMigrationName  = errors.New("migration name must contain letters or digits")
+)
+
 // Migration represents a database migration
 type Migration struct {
 	Version int
 	Name    string
-	SQL     string
+	// SQL applies the migration
+	SQL string
+	// Down reverts the migration, empty when it cannot be reverted
+	Down string
+	// Checksum is the SHA-256 digest of SQL, recorded when the migration is
+	// applied
+	Checksum string
+}
+
+// MigrationStatus is the state of a migration in the database
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+	// AppliedAt is zero for pending migrations
+	AppliedAt time.Time
+	// Modified is set for applied migrations whose up file changed since
+	// they were applied
+	Modified bool
+	// Missing is set for applied migrations without a migration file
+	Missing bool
+}
+
+// appliedMigration is a row of the schema_migrations table
+type appliedMigration struct {
+	version   int
+	checksum  string
+	appliedAt time.Time
 }
 
 // MigrationService handles database migrations
 type MigrationService struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect *Dialect
 }
 
-// NewMigrationService creates a new migration service
+// NewMigrationService creates a new migration service applying the
+// migrations of the dialect of db
 func NewMigrationService(db *sql.DB) *MigrationService {
-	return &MigrationService{db: db}
+	return &MigrationService{db: db, dialect: DialectOf(db)}
 }
 
 // RunMigrations executes all pending migrations
 func (ms *MigrationService) RunMigrations() error {
-	// Ensure migration tracking table exists
+	applied, err := ms.Up()
+	for _, migration := range applied {
+		log.Printf("Applied migration %d: %s", migration.Version, migration.Name)
+	}
+	return err
+}
+
+// Up applies the pending migrations in order and returns them. It refuses
+// to run when an applied migration was modified or is missing.
+func (ms *MigrationService) Up() ([]Migration, error) {
+	return ms.UpTo(math.MaxInt)
+}
+
+// UpTo applies the pending migrations up to version, included, like Up
+func (ms *MigrationService) UpTo(version int) ([]Migration, error) {
+	migrations, applied, err := ms.load()
+	if err != nil {
+		return nil, err
+	}
+	if err := ms.verify(migrations, applied, 0); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range pendingMigrations(migrations, applied) {
+		if migration.Version > version {
+			break
+		}
+		if err := ms.applyMigration(migration); err != nil {
+			return done, fmt.Errorf("failed to apply migration %d: %w", migration.Version, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, most recent first, and
+// returns them
+func (ms *MigrationService) Down(steps int) ([]Migration, error) {
+	migrations, applied, err := ms.load()
+	if err != nil {
+		return nil, err
+	}
+	if err := ms.verify(migrations, applied, 0); err != nil {
+		return nil, err
+	}
+	return ms.revert(migrations, applied, steps)
+}
+
+// Redo reverts the last applied migration and applies it again, which
+// picks up the changes made to it. The last migration is the only one
+// allowed to have been modified.
+func (ms *MigrationService) Redo() (*Migration, error) {
+	migrations, applied, err := ms.load()
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) == 0 {
+		return nil, ErrNoAppliedMigrations
+	}
+	last := applied[len(applied)-1].version
+	if err := ms.verify(migrations, applied, last); err != nil {
+		return nil, err
+	}
+
+	reverted, err := ms.revert(migrations, applied, 1)
+	if err != nil {
+		return nil, err
+	}
+	migration := reverted[0]
+	if err := ms.applyMigration(migration); err != nil {
+		return nil, fmt.Errorf("failed to apply migration %d: %w", migration.Version, err)
+	}
+	return &migration, nil
+}
+
+// Status returns the state of every migration, known or applied, in
+// version order
+func (ms *MigrationService) Status() ([]MigrationStatus, error) {
+	migrations, applied, err := ms.load()
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+	appliedVersions := make(map[int]bool, len(applied))
+
+	var statuses []MigrationStatus
+	for _, row := range applied {
+		appliedVersions[row.version] = true
+		migration, ok := byVersion[row.version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   row.version,
+			Name:      migration.Name,
+			Applied:   true,
+			AppliedAt: row.appliedAt,
+			Modified:  ok && row.checksum != "" && row.checksum != migration.Checksum,
+			Missing:   !ok,
+		})
+	}
+	for _, migration := range migrations {
+		if !appliedVersions[migration.Version] {
+			statuses = append(statuses, MigrationStatus{Version: migration.Version, Name: migration.Name})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Check returns ErrPendingMigrations when some migrations are not applied
+// yet, without applying them, and fails like Up when an applied migration
+// was modified or is missing
+func (ms *MigrationService) Check() error {
+	migrations, applied, err := ms.load()
+	if err != nil {
+		return err
+	}
+	if err := ms.verify(migrations, applied, 0); err != nil {
+		return err
+	}
+
+	pending := pendingMigrations(migrations, applied)
+	if len(pending) == 0 {
+		return nil
+	}
+	names := make([]string, len(pending))
+	for i, migration := range pending {
+		names[i] = migration.FileName()
+	}
+	return fmt.Errorf("%w: %s", ErrPendingMigrations, strings.Join(names, ", "))
+}
+
+// FileName returns the name of the migration files without their direction
+// and extension, such as 001_initial_schema
+func (m Migration) FileName() string {
+	return fmt.Sprintf("%03d_%s", m.Version, m.Name)
+}
+
+// load ensures the migration tracking table exists, and returns the
+// migrations of the dialect and the applied ones
+func (ms *MigrationService) load() ([]Migration, []appliedMigration, error) {
 	if err := ms.createMigrationTable(); err != nil {
-		return fmt.Errorf("failed to create migration table: %w", err)
+		return nil, nil, fmt.Errorf("failed to create migration table: %w", err)
 	}
 
-	// Get all migrations
 	migrations, err := ms.loadMigrations()
 	if err != nil {
-		return fmt.Errorf("failed to load migrations: %w", err)
+		return nil, nil, fmt.Errorf("failed to load migrations: %w", err)
 	}
 
-	// Get applied migrations
-	appliedVersions, err := ms.getAppliedMigrations()
+	applied, err := ms.getAppliedMigrations()
 	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+		return nil, nil, fmt.Errorf("failed to get applied migrations: %w", err)
 	}
+	return migrations, applied, nil
+}
 
-	// Apply pending migrations
+// verify checks that every applied migration still has its file, unchanged
+// since it was applied. The checksum of the migration at version skip is
+// not checked. Migrations applied before checksums were recorded get the
+// checksum of their current file.
+func (ms *MigrationService) verify(migrations []Migration, applied []appliedMigration, skip int) error {
+	byVersion := make(map[int]Migration, len(migrations))
 	for _, migration := range migrations {
-		if !contains(appliedVersions, migration.Version) {
-			if err := ms.applyMigration(migration); err != nil {
-				return fmt.Errorf("failed to apply migration %d: %w", migration.Version, err)
+		byVersion[migration.Version] = migration
+	}
+
+	for _, row := range applied {
+		migration, ok := byVersion[row.version]
+		switch {
+		case !ok:
+			return fmt.Errorf("%w: version %d", ErrMigrationMissing, row.version)
+		case row.checksum == "":
+			query := ms.dialect.Rebind("UPDATE schema_migrations SET checksum = ? WHERE version = ?")
+			if _, err := ms.db.Exec(query, migration.Checksum, row.version); err != nil {
+				return fmt.Errorf("failed to record checksum of migration %d: %w", row.version, err)
 			}
-			log.Printf("Applied migration %d: %s", migration.Version, migration.Name)
+		case row.checksum != migration.Checksum && row.version != skip:
+			return fmt.Errorf("%w: %s", ErrMigrationModified, migration.FileName())
 		}
 	}
-
 	return nil
 }
 
+// revert reverts the last steps applied migrations, most recent first, and
+// returns the reverted ones
+func (ms *MigrationService) revert(migrations []Migration, applied []appliedMigration, steps int) ([]Migration, error) {
+	if len(applied) == 0 {
+		return nil, ErrNoAppliedMigrations
+	}
+	if steps > len(applied) {
+		steps = len(applied)
+	}
+
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	for i := len(applied) - 1; i >= len(applied)-steps; i-- {
+		migration, ok := byVersion[applied[i].version]
+		if !ok {
+			return reverted, fmt.Errorf("%w: version %d", ErrMigrationMissing, applied[i].version)
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			return reverted, fmt.Errorf("%w: %s", ErrIrreversibleMigration, migration.FileName())
+		}
+		if err := ms.revertMigration(migration); err != nil {
+			return reverted, fmt.Errorf("failed to revert migration %d: %w", migration.Version, err)
+		}
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// pendingMigrations returns the migrations that are not applied
+func pendingMigrations(migrations []Migration, applied []appliedMigration) []Migration {
+	appliedVersions := make([]int, len(applied))
+	for i, row := range applied {
+		appliedVersions[i] = row.version
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if !contains(appliedVersions, migration.Version) {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
 // createMigrationTable creates the schema_migrations table if it doesn't exist
 func (ms *MigrationService) createMigrationTable() error {
 	query := `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version INTEGER PRIMARY KEY,
-			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			checksum TEXT NOT NULL DEFAULT '',
+			applied_at ` + ms.dialect.timestamp + ` DEFAULT CURRENT_TIMESTAMP
 		)
 	`
-	_, err := ms.db.Exec(query)
+	if _, err := ms.db.Exec(query); err != nil {
+		return err
+	}
+
+	// Tables created before checksums were recorded lack the column
+	if _, err := ms.db.Exec("SELECT checksum FROM schema_migrations WHERE 1 = 0"); err == nil {
+		return nil
+	}
+	_, err := ms.db.Exec("ALTER TABLE schema_migrations ADD COLUMN checksum TEXT NOT NULL DEFAULT ''")
 	return err
 }
 
-// loadMigrations loads all migration files from the embedded filesystem
+// loadMigrations loads the migration files of the dialect from the embedded
+// filesystem
 func (ms *MigrationService) loadMigrations() ([]Migration, error) {
-	var migrations []Migration
-
-	entries, err := migrationFiles.ReadDir("migrations")
+	dir := "migrations/" + ms.dialect.Name
+	entries, err := migrationFiles.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
 
+	byVersion := make(map[int]*Migration)
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
-			continue
-		}
-
-		// Parse version from filename (e.g., "001_initial_schema.sql")
-		parts := strings.SplitN(entry.Name(), "_", 2)
-		if len(parts) < 2 {
+		if entry.IsDir() {
 			continue
 		}
 
-		version, err := strconv.Atoi(parts[0])
-		if err != nil {
+		// Parse version, name and direction from filename (e.g.,
+		// "001_initial_schema.up.sql")
+		version, name, direction, ok := parseMigrationFileName(entry.Name())
+		if !ok {
 			continue
 		}
 
 		// Read migration content
-		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		content, err := migrationFiles.ReadFile(dir + "/" + entry.Name())
 		if err != nil {
 			return nil, err
 		}
 
-		name := strings.TrimSuffix(parts[1], ".sql")
-		migrations = append(migrations, Migration{
-			Version: version,
-			Name:    name,
-			SQL:     string(content),
-		})
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("migration %d has files named %q and %q", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.SQL = string(content)
+			migration.Checksum = migrationChecksum(migration.SQL)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	var migrations []Migration
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, fmt.Errorf("migration %s has no up migration", migration.FileName())
+		}
+		migrations = append(migrations, *migration)
 	}
 
 	// Sort migrations by version
@@ -121,25 +402,63 @@ func (ms *MigrationService) loadMigrations() ([]Migration, error) {
 	return migrations, nil
 }
 
-// getAppliedMigrations returns a list of applied migration versions
-func (ms *MigrationService) getAppliedMigrations() ([]int, error) {
-	query := "SELECT version FROM schema_migrations ORDER BY version"
+// parseMigrationFileName splits the name of a migration file into its
+// version, name and direction, "up" or "down"
+func parseMigrationFileName(fileName string) (int, string, string, bool) {
+	base, ok := strings.CutSuffix(fileName, ".sql")
+	if !ok {
+		return 0, "", "", false
+	}
+
+	var direction string
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", false
+	}
+	base = strings.TrimSuffix(base, "."+direction)
+
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) < 2 {
+		return 0, "", "", false
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", "", false
+	}
+	return version, parts[1], direction, true
+}
+
+// migrationChecksum returns the checksum of the SQL of a migration
+func migrationChecksum(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return hex.EncodeToString(sum[:])
+}
+
+// getAppliedMigrations returns the applied migrations in version order
+func (ms *MigrationService) getAppliedMigrations() ([]appliedMigration, error) {
+	query := "SELECT version, checksum, applied_at FROM schema_migrations ORDER BY version"
 	rows, err := ms.db.Query(query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var versions []int
+	var applied []appliedMigration
 	for rows.Next() {
-		var version int
-		if err := rows.Scan(&version); err != nil {
+		var row appliedMigration
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&row.version, &row.checksum, &appliedAt); err != nil {
 			return nil, err
 		}
-		versions = append(versions, version)
+		row.appliedAt = appliedAt.Time
+		applied = append(applied, row)
 	}
 
-	return versions, rows.Err()
+	return applied, rows.Err()
 }
 
 // applyMigration applies a single migration
@@ -152,17 +471,89 @@ func (ms *MigrationService) applyMigration(migration Migration) error {
 
 	// Execute migration SQL
 	if _, err := tx.Exec(migration.SQL); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return fmt.Errorf("%w: build with -tags sqlite_fts5 to enable SQLite full-text search", err)
+		}
 		return err
 	}
 
 	// Record migration as applied
-	if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", migration.Version); err != nil {
+	query := ms.dialect.Rebind("INSERT INTO schema_migrations (version, checksum) VALUES (?, ?)")
+	if _, err := tx.Exec(query, migration.Version, migration.Checksum); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// revertMigration reverts a single migration
+func (ms *MigrationService) revertMigration(migration Migration) error {
+	tx, err := ms.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.Down); err != nil {
+		return err
+	}
+
+	query := ms.dialect.Rebind("DELETE FROM schema_migrations WHERE version = ?")
+	if _, err := tx.Exec(query, migration.Version); err != nil {
 		return err
 	}
 
 	return tx.Commit()
 }
 
+// migrationNameCleaner matches the characters replaced by underscores in
+// the names of new migrations
+var migrationNameCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CreateMigration creates the empty up and down files of a new migration
+// in the directory of every dialect under dir, numbered after the latest
+// migration, and returns their paths
+func CreateMigration(dir, name string) ([]string, error) {
+	name = strings.Trim(migrationNameCleaner.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, ErrInvalidMigrationName
+	}
+
+	latest := 0
+	for _, dialect := range dialects {
+		entries, err := os.ReadDir(filepath.Join(dir, dialect.Name))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if version, _, _, ok := parseMigrationFileName(entry.Name()); ok && version > latest {
+				latest = version
+			}
+		}
+	}
+	migration := Migration{Version: latest + 1, Name: name}
+	description := strings.ReplaceAll(name, "_", " ")
+
+	var paths []string
+	for _, dialect := range dialects {
+		files := []struct {
+			direction string
+			content   string
+		}{
+			{"up", "-- " + strings.ToUpper(description[:1]) + description[1:] + "\n\n"},
+			{"down", "-- Reverts " + migration.FileName() + "\n\n"},
+		}
+		for _, file := range files {
+			path := filepath.Join(dir, dialect.Name, migration.FileName()+"."+file.direction+".sql")
+			if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+				return paths, err
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
 // contains checks if a slice contains a specific integer
 func contains(slice []int, item int) bool {
 	for _, s := range slice {
@@ -171,4 +562,4 @@ func contains(slice []int, item int) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
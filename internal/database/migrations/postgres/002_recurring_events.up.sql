@@ -0,0 +1,8 @@
+-- Recurring events: RFC 5545 recurrence rules, EXDATEs and per-occurrence overrides
+ALTER TABLE events ADD COLUMN IF NOT EXISTS recurrence_rule TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN IF NOT EXISTS exdates TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN IF NOT EXISTS parent_id INTEGER;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS recurrence_id TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_events_parent_id ON events(parent_id);
+CREATE INDEX IF NOT EXISTS idx_events_recurrence_rule ON events(recurrence_rule);
@@ -0,0 +1,10 @@
+-- iCalendar UIDs used to de-duplicate imported events and tasks
+ALTER TABLE events ADD COLUMN IF NOT EXISTS uid TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS uid TEXT NOT NULL DEFAULT '';
+
+-- Backfill existing rows; overrides share the UID of their series
+UPDATE events SET uid = md5(random()::text || id::text) || '@agenda' WHERE uid = '' AND parent_id IS NULL;
+UPDATE tasks SET uid = md5(random()::text || id::text) || '@agenda' WHERE uid = '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_uid ON events(uid) WHERE uid != '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_uid ON tasks(uid) WHERE uid != '';
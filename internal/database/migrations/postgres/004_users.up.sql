@@ -0,0 +1,33 @@
+-- User accounts, login sessions and per-user ownership of tasks and events
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Sessions store a digest of the bearer token, never the token itself
+CREATE TABLE IF NOT EXISTS sessions (
+    token_hash TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+
+-- Existing rows keep a NULL owner and are only visible without authentication
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS user_id INTEGER;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS user_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_user_id ON tasks(user_id);
+CREATE INDEX IF NOT EXISTS idx_events_user_id ON events(user_id);
+
+-- UIDs only need to be unique within a user's calendar
+DROP INDEX IF EXISTS idx_events_uid;
+DROP INDEX IF EXISTS idx_tasks_uid;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_user_uid ON events(user_id, uid) WHERE uid != '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_user_uid ON tasks(user_id, uid) WHERE uid != '';
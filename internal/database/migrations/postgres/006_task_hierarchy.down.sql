@@ -0,0 +1,9 @@
+-- Drops subtasks and task dependencies. Subtasks are kept as top-level
+-- tasks.
+
+DROP INDEX IF EXISTS idx_task_dependencies_blocked_by_id;
+DROP INDEX IF EXISTS idx_tasks_parent_id;
+
+DROP TABLE IF EXISTS task_dependencies;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,11 @@
+-- Subtasks and task dependencies
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS parent_id INTEGER;
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id INTEGER NOT NULL,
+    blocked_by_id INTEGER NOT NULL,
+    PRIMARY KEY (task_id, blocked_by_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id);
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocked_by_id ON task_dependencies(blocked_by_id);
@@ -0,0 +1,10 @@
+-- Recurring tasks and completion history
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence_rule TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recur_after_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS series_id INTEGER;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+-- Tasks completed before completion times were recorded
+UPDATE tasks SET completed_at = updated_at WHERE status = 'completed';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_series_id ON tasks(series_id);
@@ -0,0 +1,7 @@
+-- Drops task and event reminders
+
+DROP INDEX IF EXISTS idx_reminders_event_id;
+DROP INDEX IF EXISTS idx_reminders_task_id;
+DROP INDEX IF EXISTS idx_reminders_due;
+
+DROP TABLE IF EXISTS reminders;
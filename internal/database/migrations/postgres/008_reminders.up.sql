@@ -0,0 +1,21 @@
+-- Task and event reminders delivered by the background scheduler
+CREATE TABLE IF NOT EXISTS reminders (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER,
+    event_id INTEGER,
+    offset_minutes INTEGER NOT NULL DEFAULT 0,
+    occurrence_at TIMESTAMPTZ NOT NULL,
+    remind_at TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'cancelled')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMPTZ,
+    user_id INTEGER,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((task_id IS NULL) != (event_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_due ON reminders(status, remind_at);
+CREATE INDEX IF NOT EXISTS idx_reminders_task_id ON reminders(task_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_event_id ON reminders(event_id);
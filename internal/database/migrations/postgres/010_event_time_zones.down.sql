@@ -0,0 +1,4 @@
+-- Drops the time zones of events. Event and task times stay in UTC, which
+-- the earlier code reads as well.
+
+ALTER TABLE events DROP COLUMN IF EXISTS time_zone;
@@ -0,0 +1,3 @@
+-- IANA time zone of events. Unlike the SQLite text timestamps, TIMESTAMPTZ
+-- values are stored as instants, so no rewrite of existing times is needed.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS time_zone TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Drops the full-text search columns and their indexes. The unaccent
+-- extension is left installed, as other schemas of the database may use it.
+
+DROP INDEX IF EXISTS idx_events_search_vector;
+DROP INDEX IF EXISTS idx_tasks_search_vector;
+
+ALTER TABLE events DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE tasks DROP COLUMN IF EXISTS search_vector;
+
+DROP FUNCTION IF EXISTS agenda_unaccent(text);
@@ -0,0 +1,26 @@
+-- Full-text search over the titles and descriptions of tasks and events.
+-- Each row carries a generated tsvector, titles weighted A and descriptions
+-- B, indexed with GIN. Documents are parsed with the simple configuration,
+-- which like the SQLite index neither stems words nor drops stop words, and
+-- unaccented so diacritics are ignored.
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent is only STABLE, which generated columns and indexes do not
+-- accept; the dictionary is fixed here so the wrapper is immutable
+CREATE OR REPLACE FUNCTION agenda_unaccent(text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+    AS $$ SELECT unaccent('unaccent'::regdictionary, $1) $$;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', agenda_unaccent(title)), 'A') ||
+    setweight(to_tsvector('simple', agenda_unaccent(COALESCE(description, ''))), 'B')
+) STORED;
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', agenda_unaccent(title)), 'A') ||
+    setweight(to_tsvector('simple', agenda_unaccent(COALESCE(description, ''))), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING GIN (search_vector);
@@ -0,0 +1,4 @@
+-- Drops the row versions of tasks and events
+
+ALTER TABLE events DROP COLUMN IF EXISTS version;
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
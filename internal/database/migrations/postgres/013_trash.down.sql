@@ -0,0 +1,17 @@
+-- Drops soft deletion. Tasks and events in the trash are deleted for good,
+-- as they would otherwise come back, and UIDs go back to being unique
+-- among every task and event of a user.
+
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+DELETE FROM events WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_events_user_uid;
+DROP INDEX IF EXISTS idx_tasks_user_uid;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_user_uid ON events(user_id, uid) WHERE uid != '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_user_uid ON tasks(user_id, uid) WHERE uid != '';
+
+DROP INDEX IF EXISTS idx_events_deleted_at;
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+
+ALTER TABLE events DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,37 @@
+-- Append-only audit log of the changes made to tasks and events. Entries
+-- are written in the transaction making the change and record who made it
+-- and the value of every changed field before and after. Triggers reject
+-- updates and deletes, so entries outlive the tasks and events they
+-- describe, including purged ones.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id SERIAL PRIMARY KEY,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('task', 'event')),
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    actor_id INTEGER,
+    changes TEXT NOT NULL DEFAULT '{}',
+    user_id INTEGER,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log(user_id);
+
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        RAISE EXCEPTION 'audit log entries cannot be changed';
+    END IF;
+    RAISE EXCEPTION 'audit log entries cannot be deleted';
+END;
+$$;
+
+DROP TRIGGER IF EXISTS audit_log_no_update ON audit_log;
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+
+DROP TRIGGER IF EXISTS audit_log_no_delete ON audit_log;
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
@@ -0,0 +1,6 @@
+-- Drops the undo records
+
+DROP INDEX IF EXISTS idx_undo_records_expires_at;
+DROP INDEX IF EXISTS idx_undo_records_token;
+
+DROP TABLE IF EXISTS undo_records;
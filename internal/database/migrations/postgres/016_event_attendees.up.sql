@@ -0,0 +1,16 @@
+-- Attendees of events, with their RSVP. Roles and statuses are the ROLE and
+-- PARTSTAT values of iCalendar (RFC 5545). Overrides of a recurring series
+-- share the attendees of their series, which are the only ones stored.
+CREATE TABLE IF NOT EXISTS event_attendees (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL,
+    user_id INTEGER,
+    role TEXT NOT NULL DEFAULT 'REQ-PARTICIPANT' CHECK (role IN ('CHAIR', 'REQ-PARTICIPANT', 'OPT-PARTICIPANT', 'NON-PARTICIPANT')),
+    status TEXT NOT NULL DEFAULT 'NEEDS-ACTION' CHECK (status IN ('NEEDS-ACTION', 'ACCEPTED', 'DECLINED', 'TENTATIVE')),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_attendees_event_email ON event_attendees(event_id, email);
@@ -0,0 +1,21 @@
+-- Calendars group events, such as the personal calendar of a user, the
+-- calendar of a team or the bookings of a room. Events only conflict with
+-- the events of their own calendar, or with the events their attendees
+-- attend, and the conflict policy of the calendar decides whether a
+-- conflict rejects the event, is returned as a warning or is ignored.
+-- Events without a calendar keep conflicting with each other.
+CREATE TABLE IF NOT EXISTS calendars (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL DEFAULT 'personal' CHECK (kind IN ('personal', 'team', 'room')),
+    conflict_policy TEXT NOT NULL DEFAULT 'reject' CHECK (conflict_policy IN ('reject', 'warn', 'ignore')),
+    user_id INTEGER,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS calendar_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_calendars_user_id ON calendars(user_id);
+CREATE INDEX IF NOT EXISTS idx_events_calendar_id ON events(calendar_id);
@@ -0,0 +1,9 @@
+-- Drops the initial schema
+
+DROP INDEX IF EXISTS idx_events_date_range;
+DROP INDEX IF EXISTS idx_events_start_time;
+DROP INDEX IF EXISTS idx_tasks_status;
+DROP INDEX IF EXISTS idx_tasks_due_date;
+
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS tasks;
@@ -0,0 +1,13 @@
+-- Drops recurrence rules, exception dates and occurrence overrides. The
+-- overrides themselves are deleted, as they would otherwise turn into
+-- events of their own.
+
+DELETE FROM events WHERE parent_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_events_recurrence_rule;
+DROP INDEX IF EXISTS idx_events_parent_id;
+
+ALTER TABLE events DROP COLUMN recurrence_id;
+ALTER TABLE events DROP COLUMN parent_id;
+ALTER TABLE events DROP COLUMN exdates;
+ALTER TABLE events DROP COLUMN recurrence_rule;
@@ -0,0 +1,9 @@
+-- Recurring events: RFC 5545 recurrence rules, EXDATEs and per-occurrence overrides
+
+ALTER TABLE events ADD COLUMN recurrence_rule TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN exdates TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN parent_id INTEGER REFERENCES events(id) ON DELETE CASCADE;
+ALTER TABLE events ADD COLUMN recurrence_id DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_events_parent_id ON events(parent_id);
+CREATE INDEX IF NOT EXISTS idx_events_recurrence_rule ON events(recurrence_rule);
@@ -0,0 +1,7 @@
+-- Drops the iCalendar UIDs of events and tasks
+
+DROP INDEX IF EXISTS idx_tasks_uid;
+DROP INDEX IF EXISTS idx_events_uid;
+
+ALTER TABLE tasks DROP COLUMN uid;
+ALTER TABLE events DROP COLUMN uid;
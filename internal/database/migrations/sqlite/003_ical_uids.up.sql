@@ -0,0 +1,11 @@
+-- iCalendar UIDs used to de-duplicate imported events and tasks
+
+ALTER TABLE events ADD COLUMN uid TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN uid TEXT NOT NULL DEFAULT '';
+
+-- Backfill existing rows; overrides share the UID of their series
+UPDATE events SET uid = lower(hex(randomblob(16))) || '@agenda' WHERE uid = '' AND parent_id IS NULL;
+UPDATE tasks SET uid = lower(hex(randomblob(16))) || '@agenda' WHERE uid = '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_uid ON events(uid) WHERE uid != '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_uid ON tasks(uid) WHERE uid != '';
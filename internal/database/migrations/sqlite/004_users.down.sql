@@ -0,0 +1,20 @@
+-- Drops user accounts, sessions and the ownership of tasks and events.
+-- Every task and event becomes visible without authentication, so UIDs go
+-- back to being unique across users.
+
+DROP INDEX IF EXISTS idx_tasks_user_uid;
+DROP INDEX IF EXISTS idx_events_user_uid;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_uid ON events(uid) WHERE uid != '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_uid ON tasks(uid) WHERE uid != '';
+
+DROP INDEX IF EXISTS idx_events_user_id;
+DROP INDEX IF EXISTS idx_tasks_user_id;
+
+ALTER TABLE events DROP COLUMN user_id;
+ALTER TABLE tasks DROP COLUMN user_id;
+
+DROP INDEX IF EXISTS idx_sessions_expires_at;
+DROP INDEX IF EXISTS idx_sessions_user_id;
+
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,9 @@
+-- Drops task priorities and tags
+
+DROP INDEX IF EXISTS idx_task_tags_tag_id;
+DROP INDEX IF EXISTS idx_tasks_priority;
+
+DROP TABLE IF EXISTS task_tags;
+DROP TABLE IF EXISTS tags;
+
+ALTER TABLE tasks DROP COLUMN priority;
@@ -0,0 +1,9 @@
+-- Drops the recurrence settings, series links and completion times of
+-- tasks. Generated instances are kept as standalone tasks.
+
+DROP INDEX IF EXISTS idx_tasks_series_id;
+
+ALTER TABLE tasks DROP COLUMN completed_at;
+ALTER TABLE tasks DROP COLUMN series_id;
+ALTER TABLE tasks DROP COLUMN recur_after_days;
+ALTER TABLE tasks DROP COLUMN recurrence_rule;
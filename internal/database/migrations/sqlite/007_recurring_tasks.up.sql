@@ -0,0 +1,11 @@
+-- Recurring tasks and completion history
+
+ALTER TABLE tasks ADD COLUMN recurrence_rule TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN recur_after_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN series_id INTEGER REFERENCES tasks(id);
+ALTER TABLE tasks ADD COLUMN completed_at DATETIME;
+
+-- Tasks completed before completion times were recorded
+UPDATE tasks SET completed_at = updated_at WHERE status = 'completed';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_series_id ON tasks(series_id);
@@ -0,0 +1,10 @@
+-- Drops webhook subscriptions, the delivery outbox and the delivery log
+
+DROP INDEX IF EXISTS idx_webhook_delivery_attempts_delivery_id;
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_id;
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+DROP INDEX IF EXISTS idx_webhooks_user_id;
+
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,17 @@
+-- IANA time zone of events, and UTC storage of event and task times
+
+ALTER TABLE events ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
+
+-- Times used to be stored with the UTC offset they were sent with, which
+-- breaks comparing them as text. Rewrite the ones carrying a non-zero
+-- offset in UTC.
+UPDATE events SET start_time = strftime('%Y-%m-%d %H:%M:%S', start_time) || '+00:00'
+WHERE start_time GLOB '*[+-][0-9][0-9]:[0-9][0-9]' AND start_time NOT LIKE '%+00:00';
+UPDATE events SET end_time = strftime('%Y-%m-%d %H:%M:%S', end_time) || '+00:00'
+WHERE end_time GLOB '*[+-][0-9][0-9]:[0-9][0-9]' AND end_time NOT LIKE '%+00:00';
+UPDATE events SET recurrence_id = strftime('%Y-%m-%d %H:%M:%S', recurrence_id) || '+00:00'
+WHERE recurrence_id GLOB '*[+-][0-9][0-9]:[0-9][0-9]' AND recurrence_id NOT LIKE '%+00:00';
+UPDATE tasks SET due_date = strftime('%Y-%m-%d %H:%M:%S', due_date) || '+00:00'
+WHERE due_date GLOB '*[+-][0-9][0-9]:[0-9][0-9]' AND due_date NOT LIKE '%+00:00';
+UPDATE tasks SET completed_at = strftime('%Y-%m-%d %H:%M:%S', completed_at) || '+00:00'
+WHERE completed_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]' AND completed_at NOT LIKE '%+00:00';
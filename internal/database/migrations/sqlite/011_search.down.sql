@@ -0,0 +1,11 @@
+-- Drops the full-text search indexes and the triggers keeping them in sync
+
+DROP TRIGGER IF EXISTS events_fts_delete;
+DROP TRIGGER IF EXISTS events_fts_update;
+DROP TRIGGER IF EXISTS events_fts_insert;
+DROP TRIGGER IF EXISTS tasks_fts_delete;
+DROP TRIGGER IF EXISTS tasks_fts_update;
+DROP TRIGGER IF EXISTS tasks_fts_insert;
+
+DROP TABLE IF EXISTS events_fts;
+DROP TABLE IF EXISTS tasks_fts;
@@ -0,0 +1,34 @@
+-- Full-text search indexes over the titles and descriptions of tasks and
+-- events, kept in sync by triggers. The rowid of an index row is the ID of
+-- the task or event. FTS5 requires the SQLite driver to be built with the
+-- sqlite_fts5 tag.
+
+CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(title, description, tokenize = "unicode61 remove_diacritics 2");
+CREATE VIRTUAL TABLE IF NOT EXISTS events_fts USING fts5(title, description, tokenize = "unicode61 remove_diacritics 2");
+
+INSERT INTO tasks_fts (rowid, title, description) SELECT id, title, COALESCE(description, '') FROM tasks;
+INSERT INTO events_fts (rowid, title, description) SELECT id, title, COALESCE(description, '') FROM events;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (new.id, new.title, COALESCE(new.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF title, description ON tasks BEGIN
+    UPDATE tasks_fts SET title = new.title, description = COALESCE(new.description, '') WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM tasks_fts WHERE rowid = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS events_fts_insert AFTER INSERT ON events BEGIN
+    INSERT INTO events_fts (rowid, title, description) VALUES (new.id, new.title, COALESCE(new.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS events_fts_update AFTER UPDATE OF title, description ON events BEGIN
+    UPDATE events_fts SET title = new.title, description = COALESCE(new.description, '') WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS events_fts_delete AFTER DELETE ON events BEGIN
+    DELETE FROM events_fts WHERE rowid = old.id;
+END;
@@ -0,0 +1,6 @@
+-- Row versions of tasks and events for optimistic concurrency control.
+-- Every update increments the version and only applies when the row is
+-- still at the version it was read at.
+
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE events ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,16 @@
+-- Soft deletion of tasks and events. Deleting a task or event sets its
+-- deleted_at timestamp and leaves the row in the trash, from which it can
+-- be restored until it is purged. UIDs only have to be unique among the
+-- rows that are not deleted, so a calendar import can recreate an item
+-- that sits in the trash.
+
+ALTER TABLE tasks ADD COLUMN deleted_at DATETIME;
+ALTER TABLE events ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_events_deleted_at ON events(deleted_at);
+
+DROP INDEX IF EXISTS idx_events_user_uid;
+DROP INDEX IF EXISTS idx_tasks_user_uid;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_user_uid ON events(user_id, uid) WHERE uid != '' AND deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_user_uid ON tasks(user_id, uid) WHERE uid != '' AND deleted_at IS NULL;
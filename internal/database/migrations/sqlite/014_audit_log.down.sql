@@ -0,0 +1,9 @@
+-- Drops the audit log
+
+DROP TRIGGER IF EXISTS audit_log_no_delete;
+DROP TRIGGER IF EXISTS audit_log_no_update;
+
+DROP INDEX IF EXISTS idx_audit_log_user_id;
+DROP INDEX IF EXISTS idx_audit_log_entity;
+
+DROP TABLE IF EXISTS audit_log;
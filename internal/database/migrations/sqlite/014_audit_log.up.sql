@@ -0,0 +1,26 @@
+-- Append-only audit log of the changes made to tasks and events. Entries
+-- are written in the transaction making the change and record who made it
+-- and the value of every changed field before and after. Triggers reject
+-- updates and deletes, so entries outlive the tasks and events they
+-- describe, including purged ones.
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('task', 'event')),
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    actor_id INTEGER REFERENCES users(id),
+    changes TEXT NOT NULL DEFAULT '{}',
+    user_id INTEGER REFERENCES users(id),
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log(user_id);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log BEGIN
+    SELECT RAISE(ABORT, 'audit log entries cannot be changed');
+END;
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log BEGIN
+    SELECT RAISE(ABORT, 'audit log entries cannot be deleted');
+END;
@@ -0,0 +1,21 @@
+-- Changes that can be undone for a while with the token returned by the
+-- request making them. A request making several changes, such as
+-- completing a recurring task, records them all under one token, and they
+-- are undone together. Records are removed once they expire.
+
+CREATE TABLE IF NOT EXISTS undo_records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token TEXT NOT NULL,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('task', 'event')),
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    previous TEXT,
+    user_id INTEGER REFERENCES users(id),
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    undone_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_undo_records_token ON undo_records(token);
+CREATE INDEX IF NOT EXISTS idx_undo_records_expires_at ON undo_records(expires_at);
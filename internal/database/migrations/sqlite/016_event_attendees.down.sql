@@ -0,0 +1,5 @@
+-- Drops the attendees of events
+
+DROP INDEX IF EXISTS idx_event_attendees_event_email;
+
+DROP TABLE IF EXISTS event_attendees;
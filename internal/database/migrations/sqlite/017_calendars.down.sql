@@ -0,0 +1,8 @@
+-- Drops calendars. Their events are kept without a calendar.
+
+DROP INDEX IF EXISTS idx_events_calendar_id;
+DROP INDEX IF EXISTS idx_calendars_user_id;
+
+ALTER TABLE events DROP COLUMN calendar_id;
+
+DROP TABLE IF EXISTS calendars;
@@ -0,0 +1,281 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tableExists reports whether a table exists in the test database
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	if DialectOf(db) == Postgres {
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+	}
+	var count int
+	if err := db.QueryRow(rebind(db, query), table).Scan(&count); err != nil {
+		t.Fatalf("Failed to look up table %s: %v", table, err)
+	}
+	return count == 1
+}
+
+func TestMigrationFiles(t *testing.T) {
+	var versions map[int]string
+	for _, dialect := range dialects {
+		migrations, err := (&MigrationService{dialect: dialect}).loadMigrations()
+		if err != nil {
+			t.Fatalf("Failed to load %s migrations: %v", dialect.Name, err)
+		}
+
+		names := make(map[int]string)
+		for _, migration := range migrations {
+			names[migration.Version] = migration.Name
+			if migration.Down == "" {
+				t.Errorf("Migration %s of %s has no down migration", migration.FileName(), dialect.Name)
+			}
+		}
+		if versions == nil {
+			versions = names
+			continue
+		}
+		if len(names) != len(versions) {
+			t.Errorf("Expected %d %s migrations, got %d", len(versions), dialect.Name, len(names))
+		}
+		for version, name := range versions {
+			if names[version] != name {
+				t.Errorf("Expected %s migration %d to be named %q, got %q", dialect.Name, version, name, names[version])
+			}
+		}
+	}
+}
+
+func TestMigrationService_DownAndUp(t *testing.T) {
+	db := migratedTestDB(t)
+	ms := NewMigrationService(db)
+
+	statuses, err := ms.Status()
+	if err != nil {
+		t.Fatalf("Failed to get migration status: %v", err)
+	}
+	total := len(statuses)
+
+	reverted, err := ms.Down(2)
+	if err != nil {
+		t.Fatalf("Failed to revert migrations: %v", err)
+	}
+	if len(reverted) != 2 || reverted[0].Version != statuses[total-1].Version || reverted[1].Version != statuses[total-2].Version {
+		t.Fatalf("Expected the last two migrations to be reverted, most recent first, got %+v", reverted)
+	}
+	if tableExists(t, db, "calendars") {
+		t.Error("Expected the calendars table to be dropped")
+	}
+
+	// Every migration reverts cleanly down to an empty schema
+	if _, err := ms.Down(total); err != nil {
+		t.Fatalf("Failed to revert every migration: %v", err)
+	}
+	for _, table := range []string{"tasks", "events", "users"} {
+		if tableExists(t, db, table) {
+			t.Errorf("Expected the %s table to be dropped", table)
+		}
+	}
+	if _, err := ms.Down(1); !errors.Is(err, ErrNoAppliedMigrations) {
+		t.Errorf("Expected ErrNoAppliedMigrations, got %v", err)
+	}
+
+	applied, err := ms.Up()
+	if err != nil {
+		t.Fatalf("Failed to apply migrations again: %v", err)
+	}
+	if len(applied) != total {
+		t.Errorf("Expected %d migrations to be applied, got %d", total, len(applied))
+	}
+	if !tableExists(t, db, "calendars") {
+		t.Error("Expected the calendars table to be created again")
+	}
+}
+
+func TestMigrationService_Redo(t *testing.T) {
+	db := migratedTestDB(t)
+	ms := NewMigrationService(db)
+
+	// The last migration may have been edited since it was applied
+	if _, err := db.Exec("UPDATE schema_migrations SET checksum = 'edited' WHERE version = (SELECT MAX(version) FROM schema_migrations)"); err != nil {
+		t.Fatalf("Failed to edit checksum: %v", err)
+	}
+
+	migration, err := ms.Redo()
+	if err != nil {
+		t.Fatalf("Failed to redo migration: %v", err)
+	}
+
+	var checksum string
+	if err := db.QueryRow(rebind(db, "SELECT checksum FROM schema_migrations WHERE version = ?"), migration.Version).Scan(&checksum); err != nil {
+		t.Fatalf("Failed to read checksum: %v", err)
+	}
+	if checksum != migration.Checksum {
+		t.Errorf("Expected the checksum of the migration to be recorded again, got %q", checksum)
+	}
+	if err := ms.Check(); err != nil {
+		t.Errorf("Expected every migration to be applied, got %v", err)
+	}
+}
+
+func TestMigrationService_ModifiedMigration(t *testing.T) {
+	db := migratedTestDB(t)
+	ms := NewMigrationService(db)
+
+	if _, err := db.Exec("UPDATE schema_migrations SET checksum = 'edited' WHERE version = 1"); err != nil {
+		t.Fatalf("Failed to edit checksum: %v", err)
+	}
+
+	if _, err := ms.Up(); !errors.Is(err, ErrMigrationModified) {
+		t.Errorf("Expected Up to fail with ErrMigrationModified, got %v", err)
+	}
+	if _, err := ms.Down(1); !errors.Is(err, ErrMigrationModified) {
+		t.Errorf("Expected Down to fail with ErrMigrationModified, got %v", err)
+	}
+	if _, err := ms.Redo(); !errors.Is(err, ErrMigrationModified) {
+		t.Errorf("Expected Redo to fail with ErrMigrationModified, got %v", err)
+	}
+
+	statuses, err := ms.Status()
+	if err != nil {
+		t.Fatalf("Failed to get migration status: %v", err)
+	}
+	if !statuses[0].Modified || statuses[1].Modified {
+		t.Errorf("Expected only the first migration to be reported as modified, got %+v", statuses[:2])
+	}
+}
+
+func TestMigrationService_MissingMigration(t *testing.T) {
+	db := migratedTestDB(t)
+	ms := NewMigrationService(db)
+
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, checksum) VALUES (999, 'gone')"); err != nil {
+		t.Fatalf("Failed to record migration: %v", err)
+	}
+
+	if _, err := ms.Up(); !errors.Is(err, ErrMigrationMissing) {
+		t.Errorf("Expected ErrMigrationMissing, got %v", err)
+	}
+
+	statuses, err := ms.Status()
+	if err != nil {
+		t.Fatalf("Failed to get migration status: %v", err)
+	}
+	last := statuses[len(statuses)-1]
+	if last.Version != 999 || !last.Missing || !last.Applied {
+		t.Errorf("Expected migration 999 to be reported as missing, got %+v", last)
+	}
+}
+
+func TestMigrationService_ChecksumsOfEarlierMigrations(t *testing.T) {
+	db := openTestDB(t)
+
+	// Migrations applied before checksums were recorded
+	if _, err := db.Exec("CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at " +
+		DialectOf(db).timestamp + " DEFAULT CURRENT_TIMESTAMP)"); err != nil {
+		t.Fatalf("Failed to create migration table: %v", err)
+	}
+	first, err := NewMigrationService(db).loadMigrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := db.Exec(first[0].SQL); err != nil {
+		t.Fatalf("Failed to apply first migration: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO schema_migrations (version) VALUES (1)"); err != nil {
+		t.Fatalf("Failed to record migration: %v", err)
+	}
+
+	if err := NewMigrationService(db).RunMigrations(); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	var checksum string
+	if err := db.QueryRow("SELECT checksum FROM schema_migrations WHERE version = 1").Scan(&checksum); err != nil {
+		t.Fatalf("Failed to read checksum: %v", err)
+	}
+	if checksum != first[0].Checksum {
+		t.Errorf("Expected the checksum of migration 1 to be recorded, got %q", checksum)
+	}
+}
+
+func TestMigrationService_Check(t *testing.T) {
+	db := openTestDB(t)
+	ms := NewMigrationService(db)
+
+	if err := ms.Check(); !errors.Is(err, ErrPendingMigrations) {
+		t.Errorf("Expected ErrPendingMigrations, got %v", err)
+	}
+	if tableExists(t, db, "tasks") {
+		t.Error("Expected Check not to apply migrations")
+	}
+
+	if err := ms.RunMigrations(); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	if err := ms.Check(); err != nil {
+		t.Errorf("Expected no pending migrations, got %v", err)
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, dialect := range dialects {
+		if err := os.Mkdir(filepath.Join(dir, dialect.Name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sqlite", "007_earlier.up.sql"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := CreateMigration(dir, "Add Widgets!")
+	if err != nil {
+		t.Fatalf("Failed to create migration: %v", err)
+	}
+
+	var want []string
+	for _, dialect := range dialects {
+		want = append(want,
+			filepath.Join(dir, dialect.Name, "008_add_widgets.up.sql"),
+			filepath.Join(dir, dialect.Name, "008_add_widgets.down.sql"))
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, paths)
+	}
+	for i, path := range want {
+		if paths[i] != path {
+			t.Errorf("Expected %s, got %s", path, paths[i])
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist: %v", path, err)
+		}
+	}
+
+	if _, err := CreateMigration(dir, "--"); !errors.Is(err, ErrInvalidMigrationName) {
+		t.Errorf("Expected ErrInvalidMigrationName, got %v", err)
+	}
+}
+
+func TestAutoMigrateFromEnv(t *testing.T) {
+	tests := map[string]bool{
+		"":      true,
+		"true":  true,
+		"bogus": true,
+		"false": false,
+		"0":     false,
+	}
+	for value, want := range tests {
+		t.Setenv("BLUEPRINT_DB_AUTO_MIGRATE", value)
+		if got := AutoMigrateFromEnv(); got != want {
+			t.Errorf("AutoMigrateFromEnv() with %q = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"agenda/internal/auth"
+)
+
+// ownerCondition restricts a query to the rows of the user in the request
+// context. IS NOT DISTINCT FROM matches NULL as well, so requests without an
+// authenticated user only see rows that have no owner.
+const ownerCondition = "user_id IS NOT DISTINCT FROM ?"
+
+// ownerArg returns the argument bound to ownerCondition for ctx
+func ownerArg(ctx context.Context) interface{} {
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		return userID
+	}
+	return nil
+}
+
+// ownerID returns the owner assigned to records created with ctx, falling
+// back to the given owner when ctx carries no authenticated user
+func ownerID(ctx context.Context, fallback *int) *int {
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		return &userID
+	}
+	return fallback
+}
+
+// ownedTables lists the tables whose rows belong to the user in their
+// user_id column
+var ownedTables = []string{"tasks", "events", "reminders", "calendars", "webhooks", "webhook_deliveries", "undo_records"}
+
+// CountUnownedRows returns the number of tasks and events that have no
+// owner. Rows created before user accounts were introduced have none, and
+// stay out of reach of every user until AssignOwner gives them one.
+func CountUnownedRows(ctx context.Context, db *sql.DB) (int64, error) {
+	repo := NewRepository(db)
+	return repo.Count(ctx, `
+		SELECT (SELECT COUNT(*) FROM tasks WHERE user_id IS NULL) + (SELECT COUNT(*) FROM events WHERE user_id IS NULL)
+	`)
+}
+
+// AssignOwner gives every row that has no owner to the user, in one
+// transaction, and returns the number of rows assigned per table. It fails
+// when a task or event would take a UID the user already uses.
+func AssignOwner(ctx context.Context, db *sql.DB, userID int) (map[string]int64, error) {
+	assigned := make(map[string]int64, len(ownedTables))
+	err := NewRepository(db).inTransaction(ctx, func(tx executor) error {
+		for _, table := range ownedTables {
+			result, err := tx.ExecContext(ctx, `UPDATE `+table+` SET user_id = ? WHERE user_id IS NULL`, userID)
+			if err != nil {
+				return fmt.Errorf("failed to assign the %s without owner: %w", table, err)
+			}
+			if assigned[table], err = result.RowsAffected(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assigned, nil
+}
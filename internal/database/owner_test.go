@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+)
+
+func TestAssignOwner(t *testing.T) {
+	db := migratedTestDB(t)
+	tasks := NewTaskRepository(db)
+	events := NewEventRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+	otherCtx := auth.WithUserID(context.Background(), 2)
+
+	// Rows created without a user have no owner, like the rows created
+	// before user accounts existed
+	unowned, err := tasks.CreateTask(context.Background(), &models.Task{Title: "Old task", Status: models.TaskStatusPending})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	if _, err := events.CreateEvent(context.Background(), &models.Event{Title: "Old event", StartTime: start, EndTime: start.Add(time.Hour)}); err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	owned, err := tasks.CreateTask(otherCtx, &models.Task{Title: "Other task", Status: models.TaskStatusPending})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	if count, err := CountUnownedRows(context.Background(), db); err != nil || count != 2 {
+		t.Fatalf("Expected 2 rows without owner, got %d (%v)", count, err)
+	}
+	if _, err := tasks.GetTaskByID(ctx, unowned.ID); err != sql.ErrNoRows {
+		t.Fatalf("Expected the task without owner to be out of reach, got %v", err)
+	}
+
+	assigned, err := AssignOwner(context.Background(), db, 1)
+	if err != nil {
+		t.Fatalf("AssignOwner failed: %v", err)
+	}
+	if assigned["tasks"] != 1 || assigned["events"] != 1 {
+		t.Errorf("Expected a task and an event to be assigned, got %v", assigned)
+	}
+
+	if _, err := tasks.GetTaskByID(ctx, unowned.ID); err != nil {
+		t.Errorf("Expected the task to belong to the user, got %v", err)
+	}
+	if _, err := tasks.GetTaskByID(otherCtx, owned.ID); err != nil {
+		t.Errorf("Expected the task of another user to keep its owner, got %v", err)
+	}
+	if count, err := CountUnownedRows(context.Background(), db); err != nil || count != 0 {
+		t.Errorf("Expected no rows without owner, got %d (%v)", count, err)
+	}
+}
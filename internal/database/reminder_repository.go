@@ -0,0 +1,292 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"agenda/internal/models"
+)
+
+// ReminderRepositoryInterface defines the contract for reminder repository operations
+type ReminderRepositoryInterface interface {
+	BaseRepository
+
+	// Reminder methods, scoped to the authenticated user
+	CreateReminder(ctx context.Context, reminder *models.Reminder) (*models.Reminder, error)
+	GetReminderByID(ctx context.Context, id int) (*models.Reminder, error)
+	GetTaskReminders(ctx context.Context, taskID int) ([]*models.Reminder, error)
+	GetEventReminders(ctx context.Context, eventID int) ([]*models.Reminder, error)
+	DeleteReminder(ctx context.Context, id int) error
+
+	// Scheduler methods. These see the reminders of every user.
+	GetDueReminders(ctx context.Context, now time.Time, limit int) ([]*models.Reminder, error)
+	ClaimReminder(ctx context.Context, reminder *models.Reminder) (bool, error)
+	SaveReminderState(ctx context.Context, previous, reminder *models.Reminder) (bool, error)
+	ResetInterruptedReminders(ctx context.Context) (int64, error)
+}
+
+// reminderColumns lists the selected reminder columns in models.Reminder field order
+const reminderColumns = "id, task_id, event_id, offset_minutes, occurrence_at, remind_at, status, attempts, " +
+	"last_error, sent_at, user_id, created_at, updated_at"
+
+// ReminderRepository implements ReminderRepositoryInterface
+type ReminderRepository struct {
+	*Repository
+}
+
+// NewReminderRepository creates a new reminder repository instance
+func NewReminderRepository(db *sql.DB) ReminderRepositoryInterface {
+	return &ReminderRepository{
+		Repository: NewRepository(db),
+	}
+}
+
+// CreateReminder creates a new reminder in the database
+func (rr *ReminderRepository) CreateReminder(ctx context.Context, reminder *models.Reminder) (*models.Reminder, error) {
+	query := `
+		INSERT INTO reminders (task_id, event_id, offset_minutes, occurrence_at, remind_at, status, attempts,
+			last_error, sent_at, user_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	reminder.CreatedAt = now
+	reminder.UpdatedAt = now
+
+	if reminder.Status == "" {
+		reminder.Status = models.ReminderPending
+	}
+
+	// Store reminder times in UTC so they compare correctly as text
+	reminder.OccurrenceAt = reminder.OccurrenceAt.UTC()
+	reminder.RemindAt = reminder.RemindAt.UTC()
+
+	// New reminders belong to the authenticated user
+	reminder.UserID = ownerID(ctx, reminder.UserID)
+
+	id, err := rr.Create(ctx, query, reminder.TaskID, reminder.EventID, reminder.OffsetMinutes, reminder.OccurrenceAt,
+		reminder.RemindAt, reminder.Status, reminder.Attempts, reminder.LastError, reminder.SentAt, reminder.UserID,
+		reminder.CreatedAt, reminder.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reminder: %w", err)
+	}
+
+	reminder.ID = int(id)
+	return reminder, nil
+}
+
+// GetReminderByID retrieves a reminder by its ID
+func (rr *ReminderRepository) GetReminderByID(ctx context.Context, id int) (*models.Reminder, error) {
+	query := `
+		SELECT ` + reminderColumns + `
+		FROM reminders
+		WHERE id = ? AND ` + ownerCondition + `
+	`
+
+	var reminder models.Reminder
+	err := rr.Get(ctx, &reminder, query, id, ownerArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get reminder: %w", err)
+	}
+
+	return &reminder, nil
+}
+
+// GetTaskReminders retrieves the reminders of a task
+func (rr *ReminderRepository) GetTaskReminders(ctx context.Context, taskID int) ([]*models.Reminder, error) {
+	query := `
+		SELECT ` + reminderColumns + `
+		FROM reminders
+		WHERE task_id = ? AND ` + ownerCondition + `
+		ORDER BY offset_minutes DESC, id ASC
+	`
+
+	var reminders []*models.Reminder
+	err := rr.List(ctx, &reminders, query, taskID, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// GetEventReminders retrieves the reminders of an event
+func (rr *ReminderRepository) GetEventReminders(ctx context.Context, eventID int) ([]*models.Reminder, error) {
+	query := `
+		SELECT ` + reminderColumns + `
+		FROM reminders
+		WHERE event_id = ? AND ` + ownerCondition + `
+		ORDER BY offset_minutes DESC, id ASC
+	`
+
+	var reminders []*models.Reminder
+	err := rr.List(ctx, &reminders, query, eventID, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// DeleteReminder removes a reminder from the database
+func (rr *ReminderRepository) DeleteReminder(ctx context.Context, id int) error {
+	err := rr.Update(ctx, `DELETE FROM reminders WHERE id = ? AND `+ownerCondition, id, ownerArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete reminder: %w", err)
+	}
+
+	return nil
+}
+
+// GetDueReminders retrieves up to limit pending reminders of any user whose
+// delivery time is not after now, earliest first
+func (rr *ReminderRepository) GetDueReminders(ctx context.Context, now time.Time, limit int) ([]*models.Reminder, error) {
+	query := `
+		SELECT ` + reminderColumns + `
+		FROM reminders
+		WHERE status = ? AND remind_at <= ?
+		ORDER BY remind_at ASC, id ASC
+		LIMIT ?
+	`
+
+	var reminders []*models.Reminder
+	err := rr.List(ctx, &reminders, query, models.ReminderPending, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// ClaimReminder moves a pending reminder to the sending status and counts
+// the delivery attempt. It reports false when the reminder was claimed,
+// rescheduled or removed since it was read, in which case it must not be
+// delivered.
+func (rr *ReminderRepository) ClaimReminder(ctx context.Context, reminder *models.Reminder) (bool, error) {
+	query := `
+		UPDATE reminders
+		SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id = ? AND status = ? AND remind_at = ?
+	`
+
+	now := time.Now()
+	result, err := rr.conn(ctx).ExecContext(ctx, query, models.ReminderSending, now,
+		reminder.ID, models.ReminderPending, reminder.RemindAt.UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder: %w", err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder: %w", err)
+	}
+	if claimed == 0 {
+		return false, nil
+	}
+
+	reminder.Status = models.ReminderSending
+	reminder.Attempts++
+	reminder.UpdatedAt = now
+	return true, nil
+}
+
+// SaveReminderState stores the scheduling and delivery fields of a reminder
+// that was read as previous. It reports false, storing nothing, when the
+// status or remind_at of the reminder changed since it was read, such as
+// when editing its task or event rescheduled it.
+func (rr *ReminderRepository) SaveReminderState(ctx context.Context, previous, reminder *models.Reminder) (bool, error) {
+	query := `
+		UPDATE reminders
+		SET occurrence_at = ?, remind_at = ?, status = ?, attempts = ?, last_error = ?, sent_at = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND remind_at = ?
+	`
+
+	reminder.OccurrenceAt = reminder.OccurrenceAt.UTC()
+	reminder.RemindAt = reminder.RemindAt.UTC()
+	reminder.UpdatedAt = time.Now()
+
+	result, err := rr.conn(ctx).ExecContext(ctx, query, reminder.OccurrenceAt, reminder.RemindAt, reminder.Status,
+		reminder.Attempts, reminder.LastError, reminder.SentAt, reminder.UpdatedAt,
+		reminder.ID, previous.Status, previous.RemindAt.UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to save reminder state: %w", err)
+	}
+
+	saved, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to save reminder state: %w", err)
+	}
+	return saved > 0, nil
+}
+
+// ResetInterruptedReminders puts the reminders left in the sending status by
+// a scheduler that stopped mid-delivery back in the queue. Whether they
+// reached the user is unknown, so they may be delivered twice; notifiers
+// send the idempotency key of the notification for receivers to recognise
+// repeated deliveries.
+func (rr *ReminderRepository) ResetInterruptedReminders(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE reminders
+		SET status = ?, last_error = ?, updated_at = ?
+		WHERE status = ?
+	`
+
+	result, err := rr.conn(ctx).ExecContext(ctx, query, models.ReminderPending,
+		"delivery interrupted by a shutdown", time.Now(), models.ReminderSending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset interrupted reminders: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// rescheduleReminders re-arms the reminders of a task or event after its due
+// date or start time changed. column is the reminders column referencing
+// it. A nil occurrence cancels the pending reminders. Reminders that are
+// being delivered are left to the scheduler.
+func rescheduleReminders(ctx context.Context, exec executor, column string, id int, occurrence *time.Time) error {
+	rows, err := exec.QueryContext(ctx, `SELECT `+reminderColumns+` FROM reminders WHERE `+column+` = ? AND status != ?`,
+		id, models.ReminderSending)
+	if err != nil {
+		return err
+	}
+
+	var reminders []*models.Reminder
+	if err := scanRows(rows, &reminders); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	now := time.Now()
+	for _, reminder := range reminders {
+		switch {
+		case occurrence == nil:
+			if reminder.Status != models.ReminderPending {
+				continue
+			}
+			reminder.Status = models.ReminderCancelled
+		case reminder.Status == models.ReminderCancelled || !reminder.OccurrenceAt.Equal(*occurrence):
+			reminder.Schedule(*occurrence)
+		default:
+			continue
+		}
+
+		_, err := exec.ExecContext(ctx, `
+			UPDATE reminders
+			SET occurrence_at = ?, remind_at = ?, status = ?, attempts = ?, last_error = ?, updated_at = ?
+			WHERE id = ?
+		`, reminder.OccurrenceAt.UTC(), reminder.RemindAt.UTC(), reminder.Status, reminder.Attempts,
+			reminder.LastError, now, reminder.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,309 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupReminderTestDB creates a test database with the full schema
+func setupReminderTestDB(t *testing.T) *sql.DB {
+	return migratedTestDB(t)
+}
+
+func TestReminderRepository_CRUD(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewReminderRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+	otherCtx := auth.WithUserID(context.Background(), 2)
+
+	due := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	taskID := 7
+	reminder := &models.Reminder{TaskID: &taskID, OffsetMinutes: 60}
+	reminder.Schedule(due)
+
+	created, err := repo.CreateReminder(ctx, reminder)
+	if err != nil {
+		t.Fatalf("CreateReminder failed: %v", err)
+	}
+	if created.ID == 0 || created.Status != models.ReminderPending {
+		t.Fatalf("Unexpected created reminder: %+v", created)
+	}
+
+	fetched, err := repo.GetReminderByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetReminderByID failed: %v", err)
+	}
+	if !fetched.RemindAt.Equal(due.Add(-time.Hour)) {
+		t.Errorf("Expected remind_at %v, got %v", due.Add(-time.Hour), fetched.RemindAt)
+	}
+	if fetched.TaskID == nil || *fetched.TaskID != taskID || fetched.EventID != nil {
+		t.Errorf("Unexpected targets: task %v, event %v", fetched.TaskID, fetched.EventID)
+	}
+
+	if _, err := repo.GetReminderByID(otherCtx, created.ID); err != sql.ErrNoRows {
+		t.Errorf("Expected other users not to see the reminder, got %v", err)
+	}
+
+	reminders, err := repo.GetTaskReminders(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetTaskReminders failed: %v", err)
+	}
+	if len(reminders) != 1 {
+		t.Errorf("Expected 1 task reminder, got %d", len(reminders))
+	}
+
+	// Deleting as another user leaves the reminder alone
+	if err := repo.DeleteReminder(otherCtx, created.ID); err != nil {
+		t.Fatalf("DeleteReminder failed: %v", err)
+	}
+	if _, err := repo.GetReminderByID(ctx, created.ID); err != nil {
+		t.Errorf("Expected reminder to survive another user's delete, got %v", err)
+	}
+
+	if err := repo.DeleteReminder(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteReminder failed: %v", err)
+	}
+	if _, err := repo.GetReminderByID(ctx, created.ID); err != sql.ErrNoRows {
+		t.Errorf("Expected reminder to be deleted, got %v", err)
+	}
+}
+
+func TestReminderRepository_DeliveryState(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewReminderRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	eventID := 3
+	due := &models.Reminder{EventID: &eventID, OffsetMinutes: 15}
+	due.Schedule(now.Add(10 * time.Minute))
+	if _, err := repo.CreateReminder(ctx, due); err != nil {
+		t.Fatalf("CreateReminder failed: %v", err)
+	}
+
+	later := &models.Reminder{EventID: &eventID, OffsetMinutes: 15}
+	later.Schedule(now.Add(2 * time.Hour))
+	if _, err := repo.CreateReminder(ctx, later); err != nil {
+		t.Fatalf("CreateReminder failed: %v", err)
+	}
+
+	// Due reminders are found regardless of the owner
+	found, err := repo.GetDueReminders(auth.WithUserID(ctx, 9), now, 10)
+	if err != nil {
+		t.Fatalf("GetDueReminders failed: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != due.ID {
+		t.Fatalf("Expected only the due reminder, got %d reminders", len(found))
+	}
+
+	t.Run("a reminder is claimed once", func(t *testing.T) {
+		first := *found[0]
+		claimed, err := repo.ClaimReminder(ctx, &first)
+		if err != nil || !claimed {
+			t.Fatalf("Expected first claim to succeed, got %v, %v", claimed, err)
+		}
+		if first.Status != models.ReminderSending || first.Attempts != 1 {
+			t.Errorf("Unexpected claimed state: %s, %d attempts", first.Status, first.Attempts)
+		}
+
+		second := *found[0]
+		claimed, err = repo.ClaimReminder(ctx, &second)
+		if err != nil || claimed {
+			t.Errorf("Expected second claim to fail, got %v, %v", claimed, err)
+		}
+
+		remaining, err := repo.GetDueReminders(ctx, now, 10)
+		if err != nil {
+			t.Fatalf("GetDueReminders failed: %v", err)
+		}
+		if len(remaining) != 0 {
+			t.Errorf("Expected claimed reminder not to be due, got %d", len(remaining))
+		}
+	})
+
+	t.Run("interrupted deliveries are requeued", func(t *testing.T) {
+		reset, err := repo.ResetInterruptedReminders(ctx)
+		if err != nil {
+			t.Fatalf("ResetInterruptedReminders failed: %v", err)
+		}
+		if reset != 1 {
+			t.Errorf("Expected 1 interrupted reminder, got %d", reset)
+		}
+
+		reminder, err := repo.GetReminderByID(ctx, due.ID)
+		if err != nil {
+			t.Fatalf("GetReminderByID failed: %v", err)
+		}
+		if reminder.Status != models.ReminderPending || reminder.LastError == "" {
+			t.Errorf("Expected pending reminder with an error, got %s %q", reminder.Status, reminder.LastError)
+		}
+	})
+
+	t.Run("state is saved", func(t *testing.T) {
+		previous := *later
+		sentAt := now
+		later.Status = models.ReminderSent
+		later.SentAt = &sentAt
+		if saved, err := repo.SaveReminderState(ctx, &previous, later); err != nil || !saved {
+			t.Fatalf("SaveReminderState failed: %v", err)
+		}
+
+		reminder, err := repo.GetReminderByID(ctx, later.ID)
+		if err != nil {
+			t.Fatalf("GetReminderByID failed: %v", err)
+		}
+		if reminder.Status != models.ReminderSent || reminder.SentAt == nil {
+			t.Errorf("Expected sent reminder, got %s", reminder.Status)
+		}
+
+		// A state read before the reminder changed is not saved over it
+		later.Status = models.ReminderCancelled
+		if saved, err := repo.SaveReminderState(ctx, &previous, later); err != nil || saved {
+			t.Errorf("Expected a stale state not to be saved, got %v (%v)", saved, err)
+		}
+		reminder, err = repo.GetReminderByID(ctx, later.ID)
+		if err != nil || reminder.Status != models.ReminderSent {
+			t.Errorf("Expected the reminder to stay sent, got %+v (%v)", reminder, err)
+		}
+	})
+}
+
+func TestReminderRepository_FollowsTasksAndEvents(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewReminderRepository(db)
+	taskRepo := NewTaskRepository(db)
+	eventRepo := NewEventRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+
+	due := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	task, err := taskRepo.CreateTask(ctx, &models.Task{Title: "Report", Status: models.TaskStatusPending, DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	reminder := &models.Reminder{TaskID: &task.ID, OffsetMinutes: 30}
+	reminder.Schedule(due)
+	if _, err := repo.CreateReminder(ctx, reminder); err != nil {
+		t.Fatalf("CreateReminder failed: %v", err)
+	}
+
+	t.Run("moving the due date re-arms the reminder", func(t *testing.T) {
+		previous := *reminder
+		sentAt := time.Now()
+		reminder.Status = models.ReminderSent
+		reminder.SentAt = &sentAt
+		if _, err := repo.SaveReminderState(ctx, &previous, reminder); err != nil {
+			t.Fatalf("SaveReminderState failed: %v", err)
+		}
+
+		newDue := due.Add(24 * time.Hour)
+		task.DueDate = &newDue
+		if err := taskRepo.UpdateTask(ctx, task); err != nil {
+			t.Fatalf("UpdateTask failed: %v", err)
+		}
+
+		updated, err := repo.GetReminderByID(ctx, reminder.ID)
+		if err != nil {
+			t.Fatalf("GetReminderByID failed: %v", err)
+		}
+		if updated.Status != models.ReminderPending {
+			t.Errorf("Expected re-armed reminder, got %s", updated.Status)
+		}
+		if !updated.RemindAt.Equal(newDue.Add(-30 * time.Minute)) {
+			t.Errorf("Expected remind_at %v, got %v", newDue.Add(-30*time.Minute), updated.RemindAt)
+		}
+	})
+
+	t.Run("completing the task cancels the reminder", func(t *testing.T) {
+		task.Status = models.TaskStatusCompleted
+		if err := taskRepo.UpdateTask(ctx, task); err != nil {
+			t.Fatalf("UpdateTask failed: %v", err)
+		}
+
+		updated, err := repo.GetReminderByID(ctx, reminder.ID)
+		if err != nil {
+			t.Fatalf("GetReminderByID failed: %v", err)
+		}
+		if updated.Status != models.ReminderCancelled {
+			t.Errorf("Expected cancelled reminder, got %s", updated.Status)
+		}
+	})
+
+	t.Run("purging the task deletes its reminders", func(t *testing.T) {
+		if err := taskRepo.DeleteTask(ctx, task.ID); err != nil {
+			t.Fatalf("DeleteTask failed: %v", err)
+		}
+		if _, err := repo.GetReminderByID(ctx, reminder.ID); err != nil {
+			t.Fatalf("Expected the reminder to be kept in the trash, got %v", err)
+		}
+		if _, err := taskRepo.PurgeDeletedTasks(ctx, time.Now().Add(time.Second)); err != nil {
+			t.Fatalf("PurgeDeletedTasks failed: %v", err)
+		}
+		if _, err := repo.GetReminderByID(ctx, reminder.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected reminder to be deleted, got %v", err)
+		}
+	})
+
+	t.Run("moving an event re-arms its reminders", func(t *testing.T) {
+		start := time.Now().Add(4 * time.Hour).Truncate(time.Second)
+		event, err := eventRepo.CreateEvent(ctx, &models.Event{Title: "Review", StartTime: start, EndTime: start.Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("CreateEvent failed: %v", err)
+		}
+
+		eventReminder := &models.Reminder{EventID: &event.ID, OffsetMinutes: 15}
+		eventReminder.Schedule(start)
+		if _, err := repo.CreateReminder(ctx, eventReminder); err != nil {
+			t.Fatalf("CreateReminder failed: %v", err)
+		}
+
+		// Edits that keep the start time leave the reminder alone
+		event.Title = "Design review"
+		if err := eventRepo.UpdateEvent(ctx, event); err != nil {
+			t.Fatalf("UpdateEvent failed: %v", err)
+		}
+		unchanged, err := repo.GetReminderByID(ctx, eventReminder.ID)
+		if err != nil {
+			t.Fatalf("GetReminderByID failed: %v", err)
+		}
+		if !unchanged.UpdatedAt.Equal(eventReminder.UpdatedAt) {
+			t.Errorf("Expected reminder to be left alone")
+		}
+
+		event.StartTime = start.Add(time.Hour)
+		event.EndTime = start.Add(2 * time.Hour)
+		if err := eventRepo.UpdateEvent(ctx, event); err != nil {
+			t.Fatalf("UpdateEvent failed: %v", err)
+		}
+		moved, err := repo.GetReminderByID(ctx, eventReminder.ID)
+		if err != nil {
+			t.Fatalf("GetReminderByID failed: %v", err)
+		}
+		if !moved.OccurrenceAt.Equal(event.StartTime) {
+			t.Errorf("Expected occurrence %v, got %v", event.StartTime, moved.OccurrenceAt)
+		}
+
+		if err := eventRepo.DeleteEvent(ctx, event.ID); err != nil {
+			t.Fatalf("DeleteEvent failed: %v", err)
+		}
+		if _, err := eventRepo.PurgeDeletedEvents(ctx, time.Now().Add(time.Second)); err != nil {
+			t.Fatalf("PurgeDeletedEvents failed: %v", err)
+		}
+		if _, err := repo.GetReminderByID(ctx, eventReminder.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected reminder to be deleted, got %v", err)
+		}
+	})
+}
@@ -3,8 +3,20 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 )
 
+// ErrVersionConflict is returned when a row is updated from a version that
+// is no longer current, because someone else updated it in the meantime
+var ErrVersionConflict = errors.New("version conflict")
+
+// ErrNotDeleted is returned when restoring a task or event that is not in
+// the trash
+var ErrNotDeleted = errors.New("not deleted")
+
+// ErrNotEmpty is returned when deleting a calendar that still has events
+var ErrNotEmpty = errors.New("not empty")
+
 // BaseRepository defines common CRUD operations that all repositories should implement
 type BaseRepository interface {
 	// Create inserts a new record and returns the generated ID
@@ -40,54 +52,55 @@ type TransactionRepository interface {
 	BeginTx(ctx context.Context) (*sql.Tx, error)
 }
 
-// Repository provides the base implementation for database operations
+// Repository provides the base implementation for database operations.
+// Queries are written with ? placeholders and rebound to the dialect of the
+// database.
 type Repository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect *Dialect
 }
 
 // NewRepository creates a new repository instance
 func NewRepository(db *sql.DB) *Repository {
 	return &Repository{
-		db: db,
+		db:      db,
+		dialect: DialectOf(db),
 	}
 }
 
-// Create inserts a new record and returns the generated ID
+// Create inserts a new record and returns the generated ID. The table must
+// have an id column.
 func (r *Repository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
-	result, err := r.db.ExecContext(ctx, query, args...)
-	if err != nil {
-		return 0, err
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-
-	return id, nil
+	return r.dialect.insert(ctx, r.conn(ctx), query, args...)
 }
 
 // GetByID retrieves a single record by its ID
 func (r *Repository) GetByID(ctx context.Context, dest interface{}, query string, id interface{}) error {
-	row := r.db.QueryRowContext(ctx, query, id)
+	row := r.conn(ctx).QueryRowContext(ctx, query, id)
+	return scanRow(row, dest)
+}
+
+// Get retrieves a single record matching the query
+func (r *Repository) Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	row := r.conn(ctx).QueryRowContext(ctx, query, args...)
 	return scanRow(row, dest)
 }
 
 // Update modifies an existing record
 func (r *Repository) Update(ctx context.Context, query string, args ...interface{}) error {
-	_, err := r.db.ExecContext(ctx, query, args...)
+	_, err := r.conn(ctx).ExecContext(ctx, query, args...)
 	return err
 }
 
 // Delete removes a record by ID
 func (r *Repository) Delete(ctx context.Context, query string, id interface{}) error {
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := r.conn(ctx).ExecContext(ctx, query, id)
 	return err
 }
 
 // List retrieves multiple records with optional filtering
 func (r *Repository) List(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -99,7 +112,7 @@ func (r *Repository) List(ctx context.Context, dest interface{}, query string, a
 // Count returns the total number of records matching the criteria
 func (r *Repository) Count(ctx context.Context, query string, args ...interface{}) (int64, error) {
 	var count int64
-	row := r.db.QueryRowContext(ctx, query, args...)
+	row := r.conn(ctx).QueryRowContext(ctx, query, args...)
 	err := row.Scan(&count)
 	return count, err
 }
@@ -107,7 +120,7 @@ func (r *Repository) Count(ctx context.Context, query string, args ...interface{
 // Exists checks if a record exists
 func (r *Repository) Exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
 	var exists bool
-	row := r.db.QueryRowContext(ctx, query, args...)
+	row := r.conn(ctx).QueryRowContext(ctx, query, args...)
 	err := row.Scan(&exists)
 	if err == sql.ErrNoRows {
 		return false, nil
@@ -115,8 +128,31 @@ func (r *Repository) Exists(ctx context.Context, query string, args ...interface
 	return exists, err
 }
 
-// WithTransaction executes a function within a database transaction
-func (r *Repository) WithTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+// conn returns the transaction carried by ctx, or the database when there
+// is none
+func (r *Repository) conn(ctx context.Context) executor {
+	if tx, ok := TxFromContext(ctx); ok {
+		return r.dialect.bind(tx)
+	}
+	return r.dialect.bind(r.db)
+}
+
+// inTransaction runs fn within WithTransaction, handing it the transaction
+// as an executor in the dialect of the database
+func (r *Repository) inTransaction(ctx context.Context, fn func(tx executor) error) error {
+	return r.WithTransaction(ctx, func(tx *sql.Tx) error {
+		return fn(r.dialect.bind(tx))
+	})
+}
+
+// WithTransaction executes a function within a database transaction. When
+// ctx already carries a transaction, fn joins it instead of starting a new
+// one, and the outer transaction decides whether to commit.
+func (r *Repository) WithTransaction(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return fn(tx)
+	}
+
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
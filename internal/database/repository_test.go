@@ -17,20 +17,21 @@ type TestModel struct {
 	CreatedAt time.Time `db:"created_at"`
 }
 
-// setupTestDB creates an in-memory SQLite database for testing
+// setupTestDB creates a test database with a test_models table
 func setupTestDB(t *testing.T) *sql.DB {
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
+	db := openTestDB(t)
 	
 	// Create test table
-	_, err = db.Exec(`
+	id := "INTEGER PRIMARY KEY AUTOINCREMENT"
+	if DialectOf(db) == Postgres {
+		id = "SERIAL PRIMARY KEY"
+	}
+	_, err := db.Exec(`
 		CREATE TABLE test_models (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			id ` + id + `,
 			name TEXT NOT NULL,
 			email TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			created_at ` + DialectOf(db).timestamp + ` DEFAULT CURRENT_TIMESTAMP
 		)
 	`)
 	if err != nil {
@@ -67,7 +68,7 @@ func TestRepository_GetByID(t *testing.T) {
 	ctx := context.Background()
 	
 	// Insert test data
-	_, err := db.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "John Doe", "john@example.com")
+	_, err := db.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "John Doe", "john@example.com")
 	if err != nil {
 		t.Fatalf("Failed to insert test data: %v", err)
 	}
@@ -97,7 +98,7 @@ func TestRepository_Update(t *testing.T) {
 	ctx := context.Background()
 	
 	// Insert test data
-	_, err := db.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "John Doe", "john@example.com")
+	_, err := db.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "John Doe", "john@example.com")
 	if err != nil {
 		t.Fatalf("Failed to insert test data: %v", err)
 	}
@@ -133,7 +134,7 @@ func TestRepository_Delete(t *testing.T) {
 	ctx := context.Background()
 	
 	// Insert test data
-	_, err := db.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "John Doe", "john@example.com")
+	_, err := db.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "John Doe", "john@example.com")
 	if err != nil {
 		t.Fatalf("Failed to insert test data: %v", err)
 	}
@@ -165,7 +166,7 @@ func TestRepository_Count(t *testing.T) {
 	ctx := context.Background()
 	
 	// Insert test data
-	_, err := db.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "John Doe", "john@example.com")
+	_, err := db.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "John Doe", "john@example.com")
 	if err != nil {
 		t.Fatalf("Failed to insert test data: %v", err)
 	}
@@ -190,7 +191,7 @@ func TestRepository_Exists(t *testing.T) {
 	ctx := context.Background()
 	
 	// Insert test data
-	_, err := db.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "John Doe", "john@example.com")
+	_, err := db.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "John Doe", "john@example.com")
 	if err != nil {
 		t.Fatalf("Failed to insert test data: %v", err)
 	}
@@ -226,7 +227,7 @@ func TestRepository_WithTransaction(t *testing.T) {
 	
 	// Test successful transaction
 	err := repo.WithTransaction(ctx, func(tx *sql.Tx) error {
-		_, err := tx.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "John Doe", "john@example.com")
+		_, err := tx.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "John Doe", "john@example.com")
 		return err
 	})
 	
@@ -247,7 +248,7 @@ func TestRepository_WithTransaction(t *testing.T) {
 	
 	// Test failed transaction (should rollback)
 	err = repo.WithTransaction(ctx, func(tx *sql.Tx) error {
-		_, err := tx.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "Jane Doe", "jane@example.com")
+		_, err := tx.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "Jane Doe", "jane@example.com")
 		if err != nil {
 			return err
 		}
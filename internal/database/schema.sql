@@ -1,3 +1,21 @@
+-- Users table
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    password_hash TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Sessions table
+CREATE TABLE IF NOT EXISTS sessions (
+    token_hash TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Tasks table
 CREATE TABLE IF NOT EXISTS tasks (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -5,6 +23,49 @@ CREATE TABLE IF NOT EXISTS tasks (
     description TEXT,
     due_date DATETIME,
     status TEXT NOT NULL DEFAULT 'pending',
+    priority TEXT NOT NULL DEFAULT 'medium',
+    parent_id INTEGER REFERENCES tasks(id),
+    recurrence_rule TEXT NOT NULL DEFAULT '',
+    recur_after_days INTEGER NOT NULL DEFAULT 0,
+    series_id INTEGER REFERENCES tasks(id),
+    completed_at DATETIME,
+    uid TEXT NOT NULL DEFAULT '',
+    user_id INTEGER REFERENCES users(id),
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME
+);
+
+-- Tags table
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+
+-- Task tags join table
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, tag_id)
+);
+
+-- Task dependencies: task_id cannot be completed before blocked_by_id
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocked_by_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, blocked_by_id)
+);
+
+-- Calendars table: groups of events with the policy applied to their time
+-- conflicts
+CREATE TABLE IF NOT EXISTS calendars (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL DEFAULT 'personal' CHECK (kind IN ('personal', 'team', 'room')),
+    conflict_policy TEXT NOT NULL DEFAULT 'reject' CHECK (conflict_policy IN ('reject', 'warn', 'ignore')),
+    user_id INTEGER REFERENCES users(id),
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
@@ -16,15 +77,190 @@ CREATE TABLE IF NOT EXISTS events (
     description TEXT,
     start_time DATETIME NOT NULL,
     end_time DATETIME NOT NULL,
+    time_zone TEXT NOT NULL DEFAULT '',
+    recurrence_rule TEXT NOT NULL DEFAULT '',
+    exdates TEXT NOT NULL DEFAULT '',
+    parent_id INTEGER REFERENCES events(id) ON DELETE CASCADE,
+    recurrence_id DATETIME,
+    uid TEXT NOT NULL DEFAULT '',
+    user_id INTEGER REFERENCES users(id),
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME,
+    calendar_id INTEGER REFERENCES calendars(id)
+);
+
+-- Reminders table: notifications delivered ahead of a task due date or event start
+CREATE TABLE IF NOT EXISTS reminders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER REFERENCES tasks(id),
+    event_id INTEGER REFERENCES events(id),
+    offset_minutes INTEGER NOT NULL DEFAULT 0,
+    occurrence_at DATETIME NOT NULL,
+    remind_at DATETIME NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'cancelled')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    sent_at DATETIME,
+    user_id INTEGER REFERENCES users(id),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((task_id IS NULL) != (event_id IS NULL))
+);
+
+-- Event attendees table: participants of an event and their RSVP, using the
+-- iCalendar ROLE and PARTSTAT values; overrides share the attendees of their
+-- series
+CREATE TABLE IF NOT EXISTS event_attendees (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id INTEGER NOT NULL REFERENCES events(id),
+    name TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL,
+    user_id INTEGER REFERENCES users(id),
+    role TEXT NOT NULL DEFAULT 'REQ-PARTICIPANT' CHECK (role IN ('CHAIR', 'REQ-PARTICIPANT', 'OPT-PARTICIPANT', 'NON-PARTICIPANT')),
+    status TEXT NOT NULL DEFAULT 'NEEDS-ACTION' CHECK (status IN ('NEEDS-ACTION', 'ACCEPTED', 'DECLINED', 'TENTATIVE')),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Webhooks table
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT 1,
+    user_id INTEGER REFERENCES users(id),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Webhook deliveries table (outbox)
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id),
+    uid TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivering', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at DATETIME,
+    user_id INTEGER REFERENCES users(id),
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Webhook delivery attempts table (delivery log)
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    delivery_id INTEGER NOT NULL REFERENCES webhook_deliveries(id),
+    attempt INTEGER NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    response_body TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    attempted_at DATETIME NOT NULL
+);
+
+-- Audit log table: append-only history of task and event changes
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('task', 'event')),
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    actor_id INTEGER REFERENCES users(id),
+    changes TEXT NOT NULL DEFAULT '{}',
+    user_id INTEGER REFERENCES users(id),
+    created_at DATETIME NOT NULL
+);
+
+-- Triggers keeping the audit log append-only
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log BEGIN
+    SELECT RAISE(ABORT, 'audit log entries cannot be changed');
+END;
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log BEGIN
+    SELECT RAISE(ABORT, 'audit log entries cannot be deleted');
+END;
+
+-- Undo records table: recent changes that can be reverted with their token
+CREATE TABLE IF NOT EXISTS undo_records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token TEXT NOT NULL,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('task', 'event')),
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    previous TEXT,
+    user_id INTEGER REFERENCES users(id),
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    undone_at DATETIME
+);
+
+-- Full-text search indexes over task and event titles and descriptions;
+-- the rowid of an index row is the ID of the task or event
+CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(title, description, tokenize = "unicode61 remove_diacritics 2");
+CREATE VIRTUAL TABLE IF NOT EXISTS events_fts USING fts5(title, description, tokenize = "unicode61 remove_diacritics 2");
+
+-- Triggers keeping the search indexes in sync
+CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (new.id, new.title, COALESCE(new.description, ''));
+END;
+CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF title, description ON tasks BEGIN
+    UPDATE tasks_fts SET title = new.title, description = COALESCE(new.description, '') WHERE rowid = new.id;
+END;
+CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM tasks_fts WHERE rowid = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS events_fts_insert AFTER INSERT ON events BEGIN
+    INSERT INTO events_fts (rowid, title, description) VALUES (new.id, new.title, COALESCE(new.description, ''));
+END;
+CREATE TRIGGER IF NOT EXISTS events_fts_update AFTER UPDATE OF title, description ON events BEGIN
+    UPDATE events_fts SET title = new.title, description = COALESCE(new.description, '') WHERE rowid = new.id;
+END;
+CREATE TRIGGER IF NOT EXISTS events_fts_delete AFTER DELETE ON events BEGIN
+    DELETE FROM events_fts WHERE rowid = old.id;
+END;
+
 -- Indexes for performance
 CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks(due_date);
 CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_priority ON tasks(priority);
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag_id ON task_tags(tag_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_series_id ON tasks(series_id);
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocked_by_id ON task_dependencies(blocked_by_id);
 CREATE INDEX IF NOT EXISTS idx_events_start_time ON events(start_time);
 CREATE INDEX IF NOT EXISTS idx_events_date_range ON events(start_time, end_time);
+CREATE INDEX IF NOT EXISTS idx_events_parent_id ON events(parent_id);
+CREATE INDEX IF NOT EXISTS idx_events_recurrence_rule ON events(recurrence_rule);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_user_uid ON events(user_id, uid) WHERE uid != '' AND deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_user_uid ON tasks(user_id, uid) WHERE uid != '' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_user_id ON tasks(user_id);
+CREATE INDEX IF NOT EXISTS idx_events_user_id ON events(user_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_events_deleted_at ON events(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_reminders_due ON reminders(status, remind_at);
+CREATE INDEX IF NOT EXISTS idx_reminders_task_id ON reminders(task_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_event_id ON reminders(event_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks(user_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts(delivery_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log(user_id);
+CREATE INDEX IF NOT EXISTS idx_undo_records_token ON undo_records(token);
+CREATE INDEX IF NOT EXISTS idx_undo_records_expires_at ON undo_records(expires_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_attendees_event_email ON event_attendees(event_id, email);
+CREATE INDEX IF NOT EXISTS idx_calendars_user_id ON calendars(user_id);
+CREATE INDEX IF NOT EXISTS idx_events_calendar_id ON events(calendar_id);
 
 -- Migration tracking table
 CREATE TABLE IF NOT EXISTS schema_migrations (
@@ -0,0 +1,263 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"agenda/internal/models"
+)
+
+// ErrInvalidSearchQuery is returned for search queries that are not valid
+// full-text query expressions, such as unbalanced quotes or parentheses
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// SearchRepositoryInterface defines the contract for full-text search
+// operations. Queries use the SQLite FTS5 query syntax: terms, "quoted
+// phrases", prefix* terms and the AND, OR and NOT operators with
+// parentheses, translated to tsquery on PostgreSQL. Results are scoped to
+// the authenticated user.
+type SearchRepositoryInterface interface {
+	// SearchTasks returns the best matching tasks, best match first
+	SearchTasks(ctx context.Context, query string, limit int) ([]*models.SearchResult, error)
+	// SearchEvents returns the best matching events, best match first
+	SearchEvents(ctx context.Context, query string, limit int) ([]*models.SearchResult, error)
+}
+
+// Ranking settings. Matches in the title count twice as much as matches in
+// the description.
+const (
+	// searchColumnWeights are the FTS5 bm25 weights of the indexed
+	// columns, in index column order (title, description)
+	searchColumnWeights = "2.0, 1.0"
+	// searchVectorWeights are the ts_rank weights of the D, C, B and A
+	// labels of the PostgreSQL search vectors, where titles are labelled A
+	// and descriptions B, in the same proportion as searchColumnWeights
+	searchVectorWeights = "{0, 0, 0.5, 1}"
+)
+
+// Snippet settings
+const (
+	// highlightStart and highlightEnd delimit matched terms in the raw
+	// snippets; they are replaced by <mark> tags once escaped
+	highlightStart = "\x02"
+	highlightEnd   = "\x03"
+	// snippetTokens bounds the number of description tokens in a snippet
+	snippetTokens = 16
+)
+
+// SearchRepository implements SearchRepositoryInterface
+type SearchRepository struct {
+	*Repository
+}
+
+// NewSearchRepository creates a new search repository instance
+func NewSearchRepository(db *sql.DB) SearchRepositoryInterface {
+	return &SearchRepository{
+		Repository: NewRepository(db),
+	}
+}
+
+// SearchTasks returns the tasks matching query, best match first
+func (sr *SearchRepository) SearchTasks(ctx context.Context, query string, limit int) ([]*models.SearchResult, error) {
+	return sr.search(ctx, "tasks", "tasks_fts", models.SearchTypeTask, query, limit)
+}
+
+// SearchEvents returns the events matching query, best match first
+func (sr *SearchRepository) SearchEvents(ctx context.Context, query string, limit int) ([]*models.SearchResult, error) {
+	return sr.search(ctx, "events", "events_fts", models.SearchTypeEvent, query, limit)
+}
+
+// search returns the best limit rows of table matching query in its
+// ftsTable index, with highlighted snippets
+func (sr *SearchRepository) search(ctx context.Context, table, ftsTable, resultType, query string, limit int) ([]*models.SearchResult, error) {
+	var results []*models.SearchResult
+	var err error
+	if sr.dialect == Postgres {
+		if query, err = tsQuery(query); err != nil || query == "" {
+			return nil, err
+		}
+		results, err = sr.rankVectors(ctx, table, resultType, query, limit)
+	} else {
+		results, err = sr.rankFTS(ctx, table, ftsTable, resultType, query, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	// Snippets are only built for the results returned
+	if sr.dialect == Postgres {
+		err = sr.addHeadlines(ctx, table, query, results)
+	} else {
+		err = sr.addSnippets(ctx, ftsTable, query, results)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// rankFTS returns the best limit rows of table matching query in its
+// SQLite ftsTable index, ranked with BM25. FTS5 ranks better matches lower,
+// so the score is negated.
+func (sr *SearchRepository) rankFTS(ctx context.Context, table, ftsTable, resultType, query string, limit int) ([]*models.SearchResult, error) {
+	rankQuery := fmt.Sprintf(`
+		SELECT %[2]s.rowid, -bm25(%[2]s, %[5]s) AS score
+		FROM %[2]s JOIN %[1]s ON %[1]s.id = %[2]s.rowid
+		WHERE %[2]s MATCH ? AND %[1]s.%[3]s AND %[1]s.%[4]s
+		ORDER BY score DESC, %[2]s.rowid
+		LIMIT ?
+	`, table, ftsTable, ownerCondition, notDeleted, searchColumnWeights)
+
+	return sr.scanRanks(ctx, resultType, rankQuery, query, ownerArg(ctx), limit)
+}
+
+// rankVectors returns the best limit rows of table matching the tsquery
+// expression query on PostgreSQL, where the titles of the search vectors
+// are weighted A and the descriptions B
+func (sr *SearchRepository) rankVectors(ctx context.Context, table, resultType, query string, limit int) ([]*models.SearchResult, error) {
+	rankQuery := fmt.Sprintf(`
+		SELECT %[1]s.id, ts_rank('%[4]s', %[1]s.search_vector, q) AS score
+		FROM %[1]s, to_tsquery('simple', agenda_unaccent(?)) q
+		WHERE %[1]s.search_vector @@ q AND %[1]s.%[2]s AND %[1]s.%[3]s
+		ORDER BY score DESC, %[1]s.id
+		LIMIT ?
+	`, table, ownerCondition, notDeleted, searchVectorWeights)
+
+	return sr.scanRanks(ctx, resultType, rankQuery, query, ownerArg(ctx), limit)
+}
+
+// scanRanks runs a ranking query selecting the ID and score of the results,
+// best match first
+func (sr *SearchRepository) scanRanks(ctx context.Context, resultType, rankQuery string, args ...interface{}) ([]*models.SearchResult, error) {
+	rows, err := sr.conn(ctx).QueryContext(ctx, rankQuery, args...)
+	if err != nil {
+		return nil, searchError(err)
+	}
+	defer rows.Close()
+
+	var results []*models.SearchResult
+	for rows.Next() {
+		result := &models.SearchResult{Type: resultType}
+		if err := rows.Scan(&result.ID, &result.Score); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, searchError(err)
+	}
+	return results, nil
+}
+
+// addSnippets sets the highlighted title and description snippet of results
+func (sr *SearchRepository) addSnippets(ctx context.Context, ftsTable, query string, results []*models.SearchResult) error {
+	snippetQuery := fmt.Sprintf(`
+		SELECT rowid,
+			snippet(%[1]s, 0, char(2), char(3), '…', 64),
+			snippet(%[1]s, 1, char(2), char(3), '…', %[2]d)
+		FROM %[1]s
+		WHERE %[1]s MATCH ? AND rowid IN (%[3]s)
+	`, ftsTable, snippetTokens, placeholders(len(results)))
+
+	return sr.scanSnippets(ctx, snippetQuery, query, results)
+}
+
+// addHeadlines sets the highlighted title and description snippet of
+// results on PostgreSQL, for the tsquery expression query
+func (sr *SearchRepository) addHeadlines(ctx context.Context, table, query string, results []*models.SearchResult) error {
+	headlineQuery := fmt.Sprintf(`
+		SELECT id,
+			ts_headline('simple', title, q, 'StartSel="%[2]s", StopSel="%[3]s", HighlightAll=true'),
+			ts_headline('simple', COALESCE(description, ''), q, 'StartSel="%[2]s", StopSel="%[3]s", MaxWords=%[4]d, MinWords=%[5]d')
+		FROM %[1]s, to_tsquery('simple', agenda_unaccent(?)) q
+		WHERE id IN (%[6]s)
+	`, table, highlightStart, highlightEnd, snippetTokens, snippetTokens/2, placeholders(len(results)))
+
+	return sr.scanSnippets(ctx, headlineQuery, query, results)
+}
+
+// scanSnippets runs a snippet query selecting the ID, title and description
+// snippet of the results, and sets them on the results
+func (sr *SearchRepository) scanSnippets(ctx context.Context, snippetQuery, query string, results []*models.SearchResult) error {
+	args := make([]interface{}, 0, len(results)+1)
+	args = append(args, query)
+	byID := make(map[int]*models.SearchResult, len(results))
+	for _, result := range results {
+		args = append(args, result.ID)
+		byID[result.ID] = result
+	}
+
+	rows, err := sr.conn(ctx).QueryContext(ctx, snippetQuery, args...)
+	if err != nil {
+		return searchError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var title, snippet string
+		if err := rows.Scan(&id, &title, &snippet); err != nil {
+			return err
+		}
+		if result, ok := byID[id]; ok {
+			result.Title = highlight(title)
+			result.Snippet = highlight(snippet)
+		}
+	}
+	return searchError(rows.Err())
+}
+
+// highlight HTML-escapes a raw snippet and turns its match delimiters into
+// <mark> tags
+func highlight(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, highlightStart, "<mark>")
+	return strings.ReplaceAll(snippet, highlightEnd, "</mark>")
+}
+
+// searchError maps full-text query syntax errors to ErrInvalidSearchQuery.
+// FTS5 reads the words before a colon or after a minus sign as column
+// filters, which fail with an unknown column.
+func searchError(err error) error {
+	if err != nil && (strings.Contains(err.Error(), "fts5: syntax error") ||
+		strings.Contains(err.Error(), "unterminated string") ||
+		strings.Contains(err.Error(), "no such column") ||
+		strings.Contains(err.Error(), "syntax error in tsquery")) {
+		return ErrInvalidSearchQuery
+	}
+	return err
+}
+
+// searchCondition returns the condition restricting a list of the rows of
+// table to the ones matching the full-text query, and its argument
+func (r *Repository) searchCondition(table, query string) (string, interface{}) {
+	if r.dialect == Postgres {
+		// Queries built by searchMatch always translate
+		tsquery, _ := tsQuery(query)
+		return "search_vector @@ to_tsquery('simple', agenda_unaccent(?))", tsquery
+	}
+	return "id IN (SELECT rowid FROM " + table + "_fts WHERE " + table + "_fts MATCH ?)", query
+}
+
+// searchMatch turns free text into a full-text query matching the rows
+// containing every word of the text, as a whole word or a prefix. It
+// returns the empty string when the text has no words.
+func searchMatch(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		// Lowercase words are never taken for operators
+		words[i] = strings.ToLower(word) + "*"
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+)
+
+func TestSearchRepository_SearchTasks(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	taskRepo := NewTaskRepository(db)
+	repo := NewSearchRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+	otherCtx := auth.WithUserID(context.Background(), 2)
+
+	create := func(ctx context.Context, title, description string) *models.Task {
+		task, err := taskRepo.CreateTask(ctx, &models.Task{Title: title, Description: description, Status: models.TaskStatusPending})
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		return task
+	}
+
+	groceries := create(ctx, "Buy groceries", "Milk, eggs & <bread>")
+	budget := create(ctx, "Review budget", "Check the groceries spending for the quarter")
+	dentist := create(ctx, "Call the dentist", "")
+	create(otherCtx, "Groceries for the party", "")
+
+	ids := func(results []*models.SearchResult) []int {
+		var ids []int
+		for _, result := range results {
+			ids = append(ids, result.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"title matches rank first", "groceries", []int{groceries.ID, budget.ID}},
+		{"prefix", "grocer*", []int{groceries.ID, budget.ID}},
+		{"phrase", `"groceries spending"`, []int{budget.ID}},
+		{"phrase in the wrong order", `"spending groceries"`, nil},
+		{"and", "groceries AND quarter", []int{budget.ID}},
+		{"not", "groceries NOT quarter", []int{groceries.ID}},
+		{"or", "milk OR dentist", []int{dentist.ID, groceries.ID}},
+		{"diacritics are ignored", "éggs", []int{groceries.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := repo.SearchTasks(ctx, tt.query, 10)
+			if err != nil {
+				t.Fatalf("SearchTasks failed: %v", err)
+			}
+			got := ids(results)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	t.Run("highlights escaped snippets", func(t *testing.T) {
+		results, err := repo.SearchTasks(ctx, "groceries OR bread", 1)
+		if err != nil {
+			t.Fatalf("SearchTasks failed: %v", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected the limit to apply, got %d results", len(results))
+		}
+		result := results[0]
+		if result.Type != models.SearchTypeTask || result.ID != groceries.ID || result.Score <= 0 {
+			t.Errorf("Unexpected result %+v", result)
+		}
+		if result.Title != "Buy <mark>groceries</mark>" {
+			t.Errorf("Unexpected title %q", result.Title)
+		}
+		if result.Snippet != "Milk, eggs &amp; &lt;<mark>bread</mark>&gt;" {
+			t.Errorf("Unexpected snippet %q", result.Snippet)
+		}
+	})
+
+	t.Run("scoped to the owner", func(t *testing.T) {
+		results, err := repo.SearchTasks(otherCtx, "groceries", 10)
+		if err != nil {
+			t.Fatalf("SearchTasks failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Title != "<mark>Groceries</mark> for the party" {
+			t.Errorf("Expected only the other user's task, got %+v", results)
+		}
+	})
+
+	t.Run("follows updates and deletes", func(t *testing.T) {
+		groceries.Title = "Buy vegetables"
+		groceries.Description = ""
+		if err := taskRepo.UpdateTask(ctx, groceries); err != nil {
+			t.Fatalf("UpdateTask failed: %v", err)
+		}
+		if err := taskRepo.DeleteTask(ctx, budget.ID); err != nil {
+			t.Fatalf("DeleteTask failed: %v", err)
+		}
+
+		results, err := repo.SearchTasks(ctx, "groceries", 10)
+		if err != nil {
+			t.Fatalf("SearchTasks failed: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("Expected no results, got %+v", results)
+		}
+		results, err = repo.SearchTasks(ctx, "vegetables", 10)
+		if err != nil {
+			t.Fatalf("SearchTasks failed: %v", err)
+		}
+		if len(results) != 1 || results[0].ID != groceries.ID {
+			t.Errorf("Expected the updated task, got %+v", results)
+		}
+	})
+
+	t.Run("malformed query", func(t *testing.T) {
+		for _, query := range []string{`"groceries`, "(groceries", "NOT groceries", "milk:eggs"} {
+			if _, err := repo.SearchTasks(ctx, query, 10); err != ErrInvalidSearchQuery {
+				t.Errorf("Expected ErrInvalidSearchQuery for %q, got %v", query, err)
+			}
+		}
+	})
+}
+
+func TestSearchRepository_SearchEvents(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	eventRepo := NewEventRepository(db)
+	repo := NewSearchRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+
+	start := time.Date(2030, time.March, 1, 9, 0, 0, 0, time.UTC)
+	event, err := eventRepo.CreateEvent(ctx, &models.Event{
+		Title:       "Quarterly planning",
+		Description: "Plan the roadmap with the whole team",
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+
+	results, err := repo.SearchEvents(ctx, "roadmap", 10)
+	if err != nil {
+		t.Fatalf("SearchEvents failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != event.ID || results[0].Type != models.SearchTypeEvent {
+		t.Fatalf("Expected the event, got %+v", results)
+	}
+	if results[0].Title != "Quarterly planning" || results[0].Snippet != "Plan the <mark>roadmap</mark> with the whole team" {
+		t.Errorf("Unexpected highlights %+v", results[0])
+	}
+
+	if err := eventRepo.DeleteEvent(ctx, event.ID); err != nil {
+		t.Fatalf("DeleteEvent failed: %v", err)
+	}
+	results, err = repo.SearchEvents(ctx, "roadmap", 10)
+	if err != nil {
+		t.Fatalf("SearchEvents failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results after delete, got %+v", results)
+	}
+}
+
+func TestSearchMatch(t *testing.T) {
+	tests := map[string]string{
+		"":                  "",
+		"  ---  ":           "",
+		"Team":              "team*",
+		"team meeting":      "team* meeting*",
+		`"AND" OR (NOT) x*`: "and* or* not* x*",
+		"café-notes, 2030":  "café* notes* 2030*",
+	}
+	for input, want := range tests {
+		if got := searchMatch(input); got != want {
+			t.Errorf("searchMatch(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"":                      "",
+		"groceries":             "groceries",
+		"Grocer*":               "grocer:*",
+		`"groceries spending"`:  "(groceries <-> spending)",
+		"milk eggs":             "(milk & eggs)",
+		"groceries AND quarter": "(groceries & quarter)",
+		"a OR b c":              "(a | (b & c))",
+		"groceries NOT quarter": "(groceries & !quarter)",
+		"(a OR b) NOT c":        "((a | b) & !c)",
+		"café-notes*":           "(café <-> notes:*)",
+		`"AND" or not`:          "((and & or) & not)",
+	}
+	for input, want := range tests {
+		got, err := tsQuery(input)
+		if err != nil || got != want {
+			t.Errorf("tsQuery(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{`"groceries`, "(groceries", "groceries)", "NOT groceries", "a OR", `""`} {
+		if _, err := tsQuery(input); err != ErrInvalidSearchQuery {
+			t.Errorf("Expected ErrInvalidSearchQuery for %q, got %v", input, err)
+		}
+	}
+}
@@ -20,22 +20,82 @@ type TaskRepositoryInterface interface {
 	UpdateTask(ctx context.Context, task *models.Task) error
 	DeleteTask(ctx context.Context, id int) error
 	ListTasks(ctx context.Context, filters TaskFilters) ([]*models.Task, error)
+	ListTasksPage(ctx context.Context, filters TaskFilters) ([]*models.Task, PageCursors, error)
 	CountTasks(ctx context.Context, filters TaskFilters) (int64, error)
 
 	// Filtering methods
 	GetTasksByStatus(ctx context.Context, status string) ([]*models.Task, error)
 	GetTasksByDueDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Task, error)
 	GetOverdueTasks(ctx context.Context) ([]*models.Task, error)
+
+	// iCalendar queries
+	GetTaskByUID(ctx context.Context, uid string) (*models.Task, error)
+
+	// Hierarchy queries
+	GetSubtasks(ctx context.Context, parentID int) ([]*models.Task, error)
+
+	// Recurrence queries
+	GetSeriesHistory(ctx context.Context, seriesID int) ([]*models.Task, error)
+
+	// Trash methods
+	ListDeletedTasks(ctx context.Context) ([]*models.Task, error)
+	RestoreTask(ctx context.Context, id int) error
+	// PurgeDeletedTasks sees the tasks of every user
+	PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// taskColumns lists the selected task columns in models.Task field order
+const taskColumns = "id, title, description, due_date, status, priority, parent_id, " +
+	"recurrence_rule, recur_after_days, series_id, completed_at, uid, user_id, version, created_at, updated_at, deleted_at"
+
+// Task sort fields
+const (
+	TaskSortCreatedAt = "created_at"
+	TaskSortUpdatedAt = "updated_at"
+	TaskSortDueDate   = "due_date"
+	TaskSortPriority  = "priority"
+	TaskSortTitle     = "title"
+)
+
+// taskPriorityRank orders priorities from least to most important
+const taskPriorityRank = "CASE priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END"
+
+// taskSortExpressions maps sort fields to ORDER BY expressions
+var taskSortExpressions = map[string]string{
+	TaskSortCreatedAt: "created_at",
+	TaskSortUpdatedAt: "updated_at",
+	TaskSortDueDate:   "due_date",
+	TaskSortPriority:  taskPriorityRank,
+	TaskSortTitle:     "LOWER(title)",
+}
+
+// IsValidTaskSort checks if the provided sort field is supported
+func IsValidTaskSort(field string) bool {
+	_, ok := taskSortExpressions[field]
+	return ok
 }
 
 // TaskFilters represents filtering options for task queries
 type TaskFilters struct {
 	Status    string
+	Priority  string
 	DueAfter  *time.Time
 	DueBefore *time.Time
 	Search    string
-	Limit     int
-	Offset    int
+
+	// Tags restricts the results to tasks with any of the tags, or with
+	// all of them when MatchAllTags is set
+	Tags         []string
+	MatchAllTags bool
+
+	// SortBy is one of the TaskSort fields; newest first when empty
+	SortBy   string
+	SortDesc bool
+
+	Limit  int
+	Offset int
+	// Cursor starts the results next to a row instead of at Offset
+	Cursor *Cursor
 }
 
 // TaskRepository implements TaskRepositoryInterface
@@ -53,13 +113,15 @@ func NewTaskRepository(db *sql.DB) TaskRepositoryInterface {
 // CreateTask creates a new task in the database
 func (tr *TaskRepository) CreateTask(ctx context.Context, task *models.Task) (*models.Task, error) {
 	query := `
-		INSERT INTO tasks (title, description, due_date, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO tasks (title, description, due_date, status, priority, parent_id,
+			recurrence_rule, recur_after_days, series_id, completed_at, uid, user_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
 	task.CreatedAt = now
 	task.UpdatedAt = now
+	task.Version = 1
 
 	// Set default status if not provided
 	if task.Status == "" {
@@ -71,25 +133,61 @@ func (tr *TaskRepository) CreateTask(ctx context.Context, task *models.Task) (*m
 		return nil, fmt.Errorf("invalid task status: %s", task.Status)
 	}
 
-	id, err := tr.Create(ctx, query, task.Title, task.Description, task.DueDate, task.Status, task.CreatedAt, task.UpdatedAt)
+	// Set default priority if not provided
+	if task.Priority == "" {
+		task.Priority = models.TaskPriorityMedium
+	}
+
+	// Validate priority
+	if !task.IsValidPriority(task.Priority) {
+		return nil, fmt.Errorf("invalid task priority: %s", task.Priority)
+	}
+
+	// Assign a UID to new tasks
+	if task.UID == "" {
+		task.UID = newUID()
+	}
+
+	// New tasks belong to the authenticated user
+	task.UserID = ownerID(ctx, task.UserID)
+
+	err := tr.inTransaction(ctx, func(tx executor) error {
+		id, err := tr.dialect.insert(ctx, tx, query, task.Title, task.Description, utcPtr(task.DueDate), task.Status, task.Priority,
+			task.ParentID, task.RecurrenceRule, task.RecurAfterDays, task.SeriesID, utcPtr(task.CompletedAt),
+			task.UID, task.UserID, task.CreatedAt, task.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		task.ID = int(id)
+
+		if err := setTaskTags(ctx, tx, task.ID, task.Tags); err != nil {
+			return err
+		}
+		return setTaskDependencies(ctx, tx, task.ID, task.BlockedBy)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
-	task.ID = int(id)
+	if task.Tags == nil {
+		task.Tags = []string{}
+	}
+	if task.BlockedBy == nil {
+		task.BlockedBy = []int{}
+	}
 	return task, nil
 }
 
 // GetTaskByID retrieves a task by its ID
 func (tr *TaskRepository) GetTaskByID(ctx context.Context, id int) (*models.Task, error) {
 	query := `
-		SELECT id, title, description, due_date, status, created_at, updated_at
+		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE id = ?
+		WHERE id = ? AND ` + ownerCondition + ` AND ` + notDeleted + `
 	`
 
 	var task models.Task
-	err := tr.GetByID(ctx, &task, query, id)
+	err := tr.Get(ctx, &task, query, id, ownerArg(ctx))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
@@ -97,15 +195,22 @@ func (tr *TaskRepository) GetTaskByID(ctx context.Context, id int) (*models.Task
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
+	if err := tr.loadRelations(ctx, []*models.Task{&task}); err != nil {
+		return nil, fmt.Errorf("failed to get task relations: %w", err)
+	}
+
 	return &task, nil
 }
 
-// UpdateTask updates an existing task
+// UpdateTask updates an existing task. The update only applies when the
+// task is still at task.Version, and bumps its version; ErrVersionConflict
+// is returned when the task was updated in the meantime.
 func (tr *TaskRepository) UpdateTask(ctx context.Context, task *models.Task) error {
 	query := `
 		UPDATE tasks 
-		SET title = ?, description = ?, due_date = ?, status = ?, updated_at = ?
-		WHERE id = ?
+		SET title = ?, description = ?, due_date = ?, status = ?, priority = ?, parent_id = ?,
+			recurrence_rule = ?, recur_after_days = ?, completed_at = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND ` + ownerCondition + ` AND ` + notDeleted + ` AND version = ?
 	`
 
 	// Validate status
@@ -113,9 +218,62 @@ func (tr *TaskRepository) UpdateTask(ctx context.Context, task *models.Task) err
 		return fmt.Errorf("invalid task status: %s", task.Status)
 	}
 
+	// Tasks without a priority fall back to the default one
+	if task.Priority == "" {
+		task.Priority = models.TaskPriorityMedium
+	}
+
+	// Validate priority
+	if !task.IsValidPriority(task.Priority) {
+		return fmt.Errorf("invalid task priority: %s", task.Priority)
+	}
+
 	task.UpdatedAt = time.Now()
 
-	err := tr.Update(ctx, query, task.Title, task.Description, task.DueDate, task.Status, task.UpdatedAt, task.ID)
+	err := tr.inTransaction(ctx, func(tx executor) error {
+		parentID, err := storedParentID(ctx, tx, task.ID, task.ParentID)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, query, task.Title, task.Description, utcPtr(task.DueDate), task.Status, task.Priority,
+			parentID, task.RecurrenceRule, task.RecurAfterDays, utcPtr(task.CompletedAt), task.UpdatedAt,
+			task.ID, ownerArg(ctx), task.Version)
+		if err != nil {
+			return err
+		}
+
+		// Leave the tags and dependencies alone when the task belongs to
+		// someone else or moved on to another version
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			var exists bool
+			err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ? AND `+ownerCondition+` AND `+notDeleted+`)`,
+				task.ID, ownerArg(ctx)).Scan(&exists)
+			if err != nil || !exists {
+				return err
+			}
+			return ErrVersionConflict
+		}
+		task.Version++
+
+		if err := setTaskTags(ctx, tx, task.ID, task.Tags); err != nil {
+			return err
+		}
+		if err := setTaskDependencies(ctx, tx, task.ID, task.BlockedBy); err != nil {
+			return err
+		}
+
+		// Follow the due date with the reminders; completed tasks need none
+		occurrence := task.DueDate
+		if task.Status == models.TaskStatusCompleted {
+			occurrence = nil
+		}
+		return rescheduleReminders(ctx, tx, "task_id", task.ID, occurrence)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
 	}
@@ -123,11 +281,26 @@ func (tr *TaskRepository) UpdateTask(ctx context.Context, task *models.Task) err
 	return nil
 }
 
-// DeleteTask removes a task from the database
+// DeleteTask moves a task to the trash. Its subtasks, tags, blockers,
+// dependents and reminders are left alone for when it is restored: while it
+// is in the trash, its subtasks read as subtasks of its parent, the tasks it
+// blocks read as no longer blocked by it, and its reminders are not
+// delivered.
 func (tr *TaskRepository) DeleteTask(ctx context.Context, id int) error {
-	query := `DELETE FROM tasks WHERE id = ?`
+	err := tr.inTransaction(ctx, func(tx executor) error {
+		result, err := tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = ?, version = version + 1 WHERE id = ? AND `+ownerCondition+` AND `+notDeleted,
+			time.Now().UTC(), id, ownerArg(ctx))
+		if err != nil {
+			return err
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil || deleted == 0 {
+			return err
+		}
 
-	err := tr.Delete(ctx, query, id)
+		// The subtasks and dependents of the task read differently now
+		return bumpRelatedTasks(ctx, tx, id)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
@@ -135,9 +308,19 @@ func (tr *TaskRepository) DeleteTask(ctx context.Context, id int) error {
 	return nil
 }
 
+// bumpRelatedTasks bumps the version of the subtasks of a task and of the
+// tasks it blocks
+func bumpRelatedTasks(ctx context.Context, tx executor, id int) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE tasks SET version = version + 1
+		WHERE parent_id = ? OR id IN (SELECT task_id FROM task_dependencies WHERE blocked_by_id = ?)
+	`, id, id)
+	return err
+}
+
 // ListTasks retrieves tasks with optional filtering
 func (tr *TaskRepository) ListTasks(ctx context.Context, filters TaskFilters) ([]*models.Task, error) {
-	query, args := tr.buildTaskQuery(filters, false)
+	query, args := tr.buildTaskQuery(ctx, filters, false)
 
 	var tasks []*models.Task
 	err := tr.List(ctx, &tasks, query, args...)
@@ -145,12 +328,43 @@ func (tr *TaskRepository) ListTasks(ctx context.Context, filters TaskFilters) ([
 		return nil, fmt.Errorf("failed to list tasks: %w", err)
 	}
 
+	if err := tr.loadRelations(ctx, tasks); err != nil {
+		return nil, fmt.Errorf("failed to list task relations: %w", err)
+	}
+
 	return tasks, nil
 }
 
+// ListTasksPage retrieves a page of at most filters.Limit tasks, starting
+// at filters.Cursor when set, along with the cursors of the adjacent pages
+func (tr *TaskRepository) ListTasksPage(ctx context.Context, filters TaskFilters) ([]*models.Task, PageCursors, error) {
+	// One more task tells whether a next page exists
+	limit := filters.Limit
+	if limit > 0 {
+		filters.Limit++
+	}
+	if filters.Cursor != nil {
+		filters.Offset = 0
+	}
+
+	tasks, err := tr.ListTasks(ctx, filters)
+	if err != nil {
+		return nil, PageCursors{}, err
+	}
+
+	order := taskOrder(filters.SortBy, filters.SortDesc)
+	tasks, cursors, err := paginate(ctx, tr.Repository, "tasks", order, tasks, func(task *models.Task) int { return task.ID },
+		limit, filters.Offset, filters.Cursor)
+	if err != nil {
+		return nil, PageCursors{}, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	return tasks, cursors, nil
+}
+
 // CountTasks returns the total number of tasks matching the filters
 func (tr *TaskRepository) CountTasks(ctx context.Context, filters TaskFilters) (int64, error) {
-	query, args := tr.buildTaskQuery(filters, true)
+	query, args := tr.buildTaskQuery(ctx, filters, true)
 
 	count, err := tr.Count(ctx, query, args...)
 	if err != nil {
@@ -180,33 +394,294 @@ func (tr *TaskRepository) GetTasksByDueDateRange(ctx context.Context, startDate,
 // GetOverdueTasks retrieves tasks that are overdue (due date in the past and not completed)
 func (tr *TaskRepository) GetOverdueTasks(ctx context.Context) ([]*models.Task, error) {
 	query := `
-		SELECT id, title, description, due_date, status, created_at, updated_at
+		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE due_date < ? AND status = ?
+		WHERE due_date < ? AND status = ? AND ` + ownerCondition + ` AND ` + notDeleted + `
 		ORDER BY due_date ASC
 	`
 
-	now := time.Now()
+	now := time.Now().UTC()
 	var tasks []*models.Task
-	err := tr.List(ctx, &tasks, query, now, models.TaskStatusPending)
+	err := tr.List(ctx, &tasks, query, now, models.TaskStatusPending, ownerArg(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get overdue tasks: %w", err)
 	}
 
+	if err := tr.loadRelations(ctx, tasks); err != nil {
+		return nil, fmt.Errorf("failed to get overdue task relations: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// GetTaskByUID retrieves a task by its iCalendar UID
+func (tr *TaskRepository) GetTaskByUID(ctx context.Context, uid string) (*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE uid = ? AND ` + ownerCondition + ` AND ` + notDeleted + `
+	`
+
+	var task models.Task
+	err := tr.Get(ctx, &task, query, uid, ownerArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get task by uid: %w", err)
+	}
+
+	if err := tr.loadRelations(ctx, []*models.Task{&task}); err != nil {
+		return nil, fmt.Errorf("failed to get task relations: %w", err)
+	}
+
+	return &task, nil
+}
+
+// GetSubtasks retrieves the direct subtasks of a task, oldest first. The
+// subtasks of its subtasks in the trash count as its own.
+func (tr *TaskRepository) GetSubtasks(ctx context.Context, parentID int) ([]*models.Task, error) {
+	query := `
+		WITH RECURSIVE trashed(id) AS (
+			SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NOT NULL
+			UNION
+			SELECT tasks.id FROM tasks JOIN trashed ON tasks.parent_id = trashed.id
+			WHERE tasks.deleted_at IS NOT NULL
+		)
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE (parent_id = ? OR parent_id IN (SELECT id FROM trashed)) AND ` + ownerCondition + ` AND ` + notDeleted + `
+		ORDER BY created_at ASC, id ASC
+	`
+
+	var tasks []*models.Task
+	err := tr.List(ctx, &tasks, query, parentID, parentID, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+	}
+
+	if err := tr.loadRelations(ctx, tasks); err != nil {
+		return nil, fmt.Errorf("failed to get subtask relations: %w", err)
+	}
+
 	return tasks, nil
 }
 
+// GetSeriesHistory retrieves the completed instances of a recurring task,
+// most recently completed first
+func (tr *TaskRepository) GetSeriesHistory(ctx context.Context, seriesID int) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE (id = ? OR series_id = ?) AND status = ? AND ` + ownerCondition + ` AND ` + notDeleted + `
+		ORDER BY completed_at DESC, id DESC
+	`
+
+	var tasks []*models.Task
+	err := tr.List(ctx, &tasks, query, seriesID, seriesID, models.TaskStatusCompleted, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series history: %w", err)
+	}
+
+	if err := tr.loadRelations(ctx, tasks); err != nil {
+		return nil, fmt.Errorf("failed to get series history relations: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ListDeletedTasks retrieves the tasks in the trash, most recently deleted
+// first
+func (tr *TaskRepository) ListDeletedTasks(ctx context.Context) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE deleted_at IS NOT NULL AND ` + ownerCondition + `
+		ORDER BY deleted_at DESC, id DESC
+	`
+
+	var tasks []*models.Task
+	err := tr.List(ctx, &tasks, query, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted tasks: %w", err)
+	}
+
+	if err := tr.loadRelations(ctx, tasks); err != nil {
+		return nil, fmt.Errorf("failed to list deleted task relations: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask takes a task out of the trash and bumps its version, along
+// with the subtasks and dependencies it had. It reads as a subtask of its
+// nearest ancestor that is not in the trash, and gets a new UID when another
+// task took its UID in the meantime. sql.ErrNoRows is returned when the task
+// does not exist, and ErrNotDeleted when it is not in the trash.
+func (tr *TaskRepository) RestoreTask(ctx context.Context, id int) error {
+	err := tr.inTransaction(ctx, func(tx executor) error {
+		var task models.Task
+		err := tx.QueryRowContext(ctx, `SELECT uid, due_date, status, deleted_at FROM tasks WHERE id = ? AND `+ownerCondition,
+			id, ownerArg(ctx)).Scan(&task.UID, &task.DueDate, &task.Status, &task.DeletedAt)
+		if err != nil {
+			return err
+		}
+		if task.DeletedAt == nil {
+			return ErrNotDeleted
+		}
+
+		if task.UID, err = restoredUID(ctx, tx, "tasks", task.UID); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE tasks SET deleted_at = NULL, uid = ?, version = version + 1 WHERE id = ?`,
+			task.UID, id)
+		if err != nil {
+			return err
+		}
+		if err := bumpRelatedTasks(ctx, tx, id); err != nil {
+			return err
+		}
+
+		// Re-arm the reminders cancelled while the task was in the trash
+		occurrence := task.DueDate
+		if task.Status == models.TaskStatusCompleted {
+			occurrence = nil
+		}
+		return rescheduleReminders(ctx, tx, "task_id", id, occurrence)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore task: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeDeletedTasks permanently removes the tasks of any user deleted
+// before the given time, together with their tags, dependencies and
+// reminders. Their remaining subtasks move up to the nearest ancestor that
+// is not purged. It returns the number of tasks removed.
+func (tr *TaskRepository) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := tr.inTransaction(ctx, func(tx executor) error {
+		deleted := `SELECT id FROM tasks WHERE deleted_at < ?`
+		before := deletedBefore.UTC()
+
+		if err := reparentPurgedSubtasks(ctx, tx, before); err != nil {
+			return err
+		}
+
+		statements := []struct {
+			query string
+			args  []interface{}
+		}{
+			{`DELETE FROM task_tags WHERE task_id IN (` + deleted + `)`, []interface{}{before}},
+			{`DELETE FROM task_dependencies WHERE task_id IN (` + deleted + `) OR blocked_by_id IN (` + deleted + `)`, []interface{}{before, before}},
+			{`DELETE FROM reminders WHERE task_id IN (` + deleted + `)`, []interface{}{before}},
+		}
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE deleted_at < ?`, before)
+		if err != nil {
+			return err
+		}
+		purged, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted tasks: %w", err)
+	}
+
+	return purged, nil
+}
+
+// reparentPurgedSubtasks moves the subtasks of the tasks deleted before the
+// given time that are not purged with them up to their nearest ancestor
+// that is not purged either
+func reparentPurgedSubtasks(ctx context.Context, tx executor, before time.Time) error {
+	parents := make(map[int]*int)
+	rows, err := tx.QueryContext(ctx, `SELECT id, parent_id FROM tasks WHERE deleted_at < ?`, before)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int
+		var parentID *int
+		if err := rows.Scan(&id, &parentID); err != nil {
+			rows.Close()
+			return err
+		}
+		parents[id] = parentID
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(parents) == 0 {
+		return err
+	}
+
+	ids := make([]interface{}, 0, len(parents))
+	for id := range parents {
+		ids = append(ids, id)
+	}
+	query := `SELECT id, parent_id FROM tasks WHERE parent_id IN (` + placeholders(len(ids)) + `) AND (deleted_at IS NULL OR deleted_at >= ?)`
+	rows, err = tx.QueryContext(ctx, query, append(ids, before)...)
+	if err != nil {
+		return err
+	}
+	subtasks := make(map[int]*int)
+	for rows.Next() {
+		var id int
+		var parentID *int
+		if err := rows.Scan(&id, &parentID); err != nil {
+			rows.Close()
+			return err
+		}
+		subtasks[id] = parentID
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, parentID := range subtasks {
+		// The seen set stops at a corrupt, cyclic hierarchy
+		seen := make(map[int]bool)
+		for parentID != nil && !seen[*parentID] {
+			grandparentID, purged := parents[*parentID]
+			if !purged {
+				break
+			}
+			seen[*parentID] = true
+			parentID = grandparentID
+		}
+		if parentID != nil && seen[*parentID] {
+			parentID = nil
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET parent_id = ? WHERE id = ?`, parentID, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // buildTaskQuery constructs a SQL query with WHERE conditions based on filters
-func (tr *TaskRepository) buildTaskQuery(filters TaskFilters, isCount bool) (string, []interface{}) {
+func (tr *TaskRepository) buildTaskQuery(ctx context.Context, filters TaskFilters, isCount bool) (string, []interface{}) {
 	var baseQuery string
 	if isCount {
 		baseQuery = "SELECT COUNT(*) FROM tasks"
 	} else {
-		baseQuery = "SELECT id, title, description, due_date, status, created_at, updated_at FROM tasks"
+		baseQuery = "SELECT " + taskColumns + " FROM tasks"
 	}
 
-	var conditions []string
-	var args []interface{}
+	// Only the rows of the authenticated user are visible, and deleted
+	// tasks stay in the trash
+	conditions := []string{ownerCondition, notDeleted}
+	args := []interface{}{ownerArg(ctx)}
 
 	// Status filter
 	if filters.Status != "" {
@@ -214,33 +689,60 @@ func (tr *TaskRepository) buildTaskQuery(filters TaskFilters, isCount bool) (str
 		args = append(args, filters.Status)
 	}
 
+	// Priority filter
+	if filters.Priority != "" {
+		conditions = append(conditions, "priority = ?")
+		args = append(args, filters.Priority)
+	}
+
 	// Due date range filters
 	if filters.DueAfter != nil {
 		conditions = append(conditions, "due_date >= ?")
-		args = append(args, *filters.DueAfter)
+		args = append(args, filters.DueAfter.UTC())
 	}
 
 	if filters.DueBefore != nil {
 		conditions = append(conditions, "due_date <= ?")
-		args = append(args, *filters.DueBefore)
+		args = append(args, filters.DueBefore.UTC())
 	}
 
-	// Search filter (searches in title and description)
-	if filters.Search != "" {
-		conditions = append(conditions, "(title LIKE ? OR description LIKE ?)")
-		searchTerm := "%" + filters.Search + "%"
-		args = append(args, searchTerm, searchTerm)
+	// Search filter (words or word prefixes in title and description)
+	if match := searchMatch(filters.Search); match != "" {
+		condition, arg := tr.searchCondition("tasks", match)
+		conditions = append(conditions, condition)
+		args = append(args, arg)
 	}
 
-	// Build WHERE clause
-	query := baseQuery
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	// Tag filter (any or all of the tags)
+	if len(filters.Tags) > 0 {
+		tagQuery := "SELECT tt.task_id FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE t.name IN (" +
+			placeholders(len(filters.Tags)) + ")"
+		for _, tag := range filters.Tags {
+			args = append(args, tag)
+		}
+		if filters.MatchAllTags {
+			tagQuery += " GROUP BY tt.task_id HAVING COUNT(DISTINCT t.id) = ?"
+			args = append(args, len(filters.Tags))
+		}
+		conditions = append(conditions, "id IN ("+tagQuery+")")
 	}
 
+	// Keyset pagination; pages before a cursor are fetched backwards
+	order := taskOrder(filters.SortBy, filters.SortDesc)
+	reverse := false
+	if filters.Cursor != nil && !isCount {
+		condition, cursorArgs := order.condition(filters.Cursor)
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+		reverse = filters.Cursor.Before
+	}
+
+	// Build WHERE clause
+	query := baseQuery + " WHERE " + strings.Join(conditions, " AND ")
+
 	// Add ordering and pagination for non-count queries
 	if !isCount {
-		query += " ORDER BY created_at DESC"
+		query += " ORDER BY " + order.orderBy(reverse)
 
 		if filters.Limit > 0 {
 			query += " LIMIT ?"
@@ -255,3 +757,285 @@ func (tr *TaskRepository) buildTaskQuery(filters TaskFilters, isCount bool) (str
 
 	return query, args
 }
+
+// taskOrder returns the ordering of a sort field. Tasks without a due date
+// always sort last, and ties are broken by ID.
+func taskOrder(sortBy string, desc bool) keysetOrder {
+	expression, ok := taskSortExpressions[sortBy]
+	if !ok {
+		sortBy, desc = TaskSortCreatedAt, true
+		expression = taskSortExpressions[sortBy]
+	}
+
+	// Dates are compared as stored, not as parsed by the driver
+	key := expression
+	switch sortBy {
+	case TaskSortCreatedAt, TaskSortUpdatedAt, TaskSortDueDate:
+		key = "CAST(" + expression + " AS TEXT)"
+	}
+
+	return keysetOrder{
+		sort:      sortBy,
+		expr:      expression,
+		key:       key,
+		desc:      desc,
+		nullsLast: sortBy == TaskSortDueDate,
+	}
+}
+
+// placeholders returns n comma-separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// setTaskTags replaces the tags of a task
+func setTaskTags(ctx context.Context, tx executor, taskID int, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ?`, taskID); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tags (name) VALUES (?) ON CONFLICT DO NOTHING`, tag); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO task_tags (task_id, tag_id)
+			VALUES (?, (SELECT id FROM tags WHERE name = ?))
+			ON CONFLICT DO NOTHING
+		`, taskID, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setTaskDependencies replaces the tasks blocking a task
+func setTaskDependencies(ctx context.Context, tx executor, taskID int, blockedBy []int) error {
+	// Blockers in the trash are not listed, and stay for when they are
+	// restored
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM task_dependencies
+		WHERE task_id = ? AND blocked_by_id NOT IN (SELECT id FROM tasks WHERE deleted_at IS NOT NULL)
+	`, taskID)
+	if err != nil {
+		return err
+	}
+
+	for _, blockerID := range blockedBy {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO task_dependencies (task_id, blocked_by_id)
+			VALUES (?, ?)
+			ON CONFLICT DO NOTHING
+		`, taskID, blockerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadRelations fills in the parents, tags, blockers and subtask progress
+// of the given tasks
+func (tr *TaskRepository) loadRelations(ctx context.Context, tasks []*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Task, len(tasks))
+	args := make([]interface{}, 0, len(tasks))
+	for _, task := range tasks {
+		task.Tags = []string{}
+		task.BlockedBy = []int{}
+		task.Subtasks = models.SubtaskProgress{}
+		byID[task.ID] = task
+		args = append(args, task.ID)
+	}
+
+	if err := tr.loadParents(ctx, tasks); err != nil {
+		return err
+	}
+	if err := tr.loadTags(ctx, byID, args); err != nil {
+		return err
+	}
+	if err := tr.loadDependencies(ctx, byID, args); err != nil {
+		return err
+	}
+	return tr.loadSubtaskProgress(ctx, byID, args)
+}
+
+// liveParentsQuery selects, for each of the tasks with the given IDs, its
+// nearest ancestor that is not in the trash, or NULL when there is none.
+// UNION stops at a corrupt, cyclic hierarchy.
+const liveParentsQuery = `
+	WITH RECURSIVE ancestors(task_id, parent_id) AS (
+		SELECT id, parent_id FROM tasks WHERE id IN (%s)
+		UNION
+		SELECT ancestors.task_id, parent.parent_id
+		FROM ancestors JOIN tasks parent ON parent.id = ancestors.parent_id
+		WHERE parent.deleted_at IS NOT NULL
+	)
+	SELECT ancestors.task_id, ancestors.parent_id
+	FROM ancestors LEFT JOIN tasks parent ON parent.id = ancestors.parent_id
+	WHERE parent.id IS NULL OR parent.deleted_at IS NULL
+`
+
+// loadParents replaces the parents of the given tasks that are in the trash
+// with their nearest ancestor that is not
+func (tr *TaskRepository) loadParents(ctx context.Context, tasks []*models.Task) error {
+	byID := make(map[int]*models.Task)
+	var args []interface{}
+	for _, task := range tasks {
+		if task.ParentID != nil {
+			byID[task.ID] = task
+			args = append(args, task.ID)
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	rows, err := tr.conn(ctx).QueryContext(ctx, fmt.Sprintf(liveParentsQuery, placeholders(len(args))), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int
+		var parentID *int
+		if err := rows.Scan(&taskID, &parentID); err != nil {
+			return err
+		}
+		if task, ok := byID[taskID]; ok {
+			task.ParentID = parentID
+		}
+	}
+
+	return rows.Err()
+}
+
+// storedParentID returns the parent_id to store for the task with the given
+// ID to read as a subtask of parentID. Its stored parent is kept while in
+// the trash, unless the task moved elsewhere.
+func storedParentID(ctx context.Context, tx executor, id int, parentID *int) (*int, error) {
+	var stored *int
+	err := tx.QueryRowContext(ctx, `SELECT parent_id FROM tasks WHERE id = ?`, id).Scan(&stored)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if stored == nil {
+		return parentID, nil
+	}
+
+	var live *int
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(liveParentsQuery, "?"), id).Scan(new(int), &live)
+	if err != nil {
+		return nil, err
+	}
+	if (live == nil && parentID == nil) || (live != nil && parentID != nil && *live == *parentID) {
+		return stored, nil
+	}
+	return parentID, nil
+}
+
+// loadTags fills in the tags of the tasks with the given IDs
+func (tr *TaskRepository) loadTags(ctx context.Context, byID map[int]*models.Task, args []interface{}) error {
+	query := `
+		SELECT tt.task_id, t.name
+		FROM task_tags tt
+		JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.task_id IN (` + placeholders(len(args)) + `)
+		ORDER BY t.name ASC
+	`
+
+	rows, err := tr.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int
+		var name string
+		if err := rows.Scan(&taskID, &name); err != nil {
+			return err
+		}
+		if task, ok := byID[taskID]; ok {
+			task.Tags = append(task.Tags, name)
+		}
+	}
+
+	return rows.Err()
+}
+
+// loadDependencies fills in the blockers of the tasks with the given IDs
+func (tr *TaskRepository) loadDependencies(ctx context.Context, byID map[int]*models.Task, args []interface{}) error {
+	query := `
+		SELECT td.task_id, td.blocked_by_id
+		FROM task_dependencies td
+		JOIN tasks blocker ON blocker.id = td.blocked_by_id
+		WHERE td.task_id IN (` + placeholders(len(args)) + `) AND blocker.` + notDeleted + `
+		ORDER BY td.blocked_by_id ASC
+	`
+
+	rows, err := tr.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, blockerID int
+		if err := rows.Scan(&taskID, &blockerID); err != nil {
+			return err
+		}
+		if task, ok := byID[taskID]; ok {
+			task.BlockedBy = append(task.BlockedBy, blockerID)
+		}
+	}
+
+	return rows.Err()
+}
+
+// loadSubtaskProgress rolls up the completion of the subtasks of the tasks
+// with the given IDs, at any depth. Subtasks in the trash are not counted,
+// but their own subtasks are.
+func (tr *TaskRepository) loadSubtaskProgress(ctx context.Context, byID map[int]*models.Task, args []interface{}) error {
+	// UNION rather than UNION ALL so a corrupt, cyclic hierarchy cannot
+	// recurse forever
+	query := `
+		WITH RECURSIVE subtree(root_id, id, status, deleted_at) AS (
+			SELECT parent_id, id, status, deleted_at FROM tasks WHERE parent_id IN (` + placeholders(len(args)) + `)
+			UNION
+			SELECT subtree.root_id, tasks.id, tasks.status, tasks.deleted_at
+			FROM tasks JOIN subtree ON tasks.parent_id = subtree.id
+		)
+		SELECT root_id, COUNT(*), SUM(CASE WHEN status = ? THEN 1 ELSE 0 END)
+		FROM subtree
+		WHERE ` + notDeleted + `
+		GROUP BY root_id
+	`
+
+	queryArgs := append(append([]interface{}{}, args...), models.TaskStatusCompleted)
+	rows, err := tr.conn(ctx).QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rootID int
+		var progress models.SubtaskProgress
+		if err := rows.Scan(&rootID, &progress.Total, &progress.Completed); err != nil {
+			return err
+		}
+		if task, ok := byID[rootID]; ok {
+			task.Subtasks = progress
+		}
+	}
+
+	return rows.Err()
+}
@@ -3,42 +3,20 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"agenda/internal/auth"
 	"agenda/internal/models"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// setupTaskTestDB creates an in-memory SQLite database for testing tasks
+// setupTaskTestDB creates a test database with the full schema for testing tasks
 func setupTaskTestDB(t *testing.T) *sql.DB {
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
-
-	// Create tasks table
-	schema := `
-	CREATE TABLE tasks (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		title TEXT NOT NULL,
-		description TEXT,
-		due_date DATETIME,
-		status TEXT NOT NULL DEFAULT 'pending',
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-	
-	CREATE INDEX idx_tasks_due_date ON tasks(due_date);
-	CREATE INDEX idx_tasks_status ON tasks(status);
-	`
-
-	if _, err := db.Exec(schema); err != nil {
-		t.Fatalf("Failed to create test schema: %v", err)
-	}
-
-	return db
+	return migratedTestDB(t)
 }
 
 // createTestTask creates a test task with default values
@@ -207,6 +185,7 @@ func TestTaskRepository_UpdateTask(t *testing.T) {
 				Title:       "Updated Title",
 				Description: "Updated Description",
 				Status:      models.TaskStatusCompleted,
+				Version:     createdTask.Version,
 			},
 			wantErr: false,
 		},
@@ -256,6 +235,51 @@ func TestTaskRepository_UpdateTask(t *testing.T) {
 	}
 }
 
+func TestTaskRepository_UpdateTask_VersionConflict(t *testing.T) {
+	db := setupTaskTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+
+	created, err := repo.CreateTask(ctx, createTestTask("Versioned Task"))
+	if err != nil {
+		t.Fatalf("Failed to create test task: %v", err)
+	}
+	if created.Version != 1 {
+		t.Fatalf("Expected new tasks at version 1, got %d", created.Version)
+	}
+
+	// Two copies of the same version; the second update must not clobber the first
+	first, second := *created, *created
+	first.Title = "First writer"
+	if err := repo.UpdateTask(ctx, &first); err != nil {
+		t.Fatalf("UpdateTask() unexpected error: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected the update to bump the version to 2, got %d", first.Version)
+	}
+
+	second.Title = "Second writer"
+	if err := repo.UpdateTask(ctx, &second); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict, got %v", err)
+	}
+
+	stored, err := repo.GetTaskByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Failed to fetch task: %v", err)
+	}
+	if stored.Title != "First writer" || stored.Version != 2 {
+		t.Errorf("Expected the first update at version 2, got %q at version %d", stored.Title, stored.Version)
+	}
+
+	// Tasks that do not exist are still left alone silently
+	missing := models.Task{ID: created.ID + 100, Title: "Missing", Status: models.TaskStatusPending, Version: 1}
+	if err := repo.UpdateTask(ctx, &missing); err != nil {
+		t.Errorf("Expected no error updating a missing task, got %v", err)
+	}
+}
+
 func TestTaskRepository_DeleteTask(t *testing.T) {
 	db := setupTaskTestDB(t)
 	defer db.Close()
@@ -559,3 +583,455 @@ func TestTaskRepository_CountTasks(t *testing.T) {
 		t.Errorf("CountTasks() with filter expected 3 tasks, got %d", count)
 	}
 }
+
+func TestTaskRepository_OwnerScoping(t *testing.T) {
+	db := setupTaskTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+	alice := auth.WithUserID(context.Background(), 1)
+	bob := auth.WithUserID(context.Background(), 2)
+
+	task, err := repo.CreateTask(alice, createTestTask("Alice's task"))
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if task.UserID == nil || *task.UserID != 1 {
+		t.Fatalf("Expected task to be owned by user 1, got %v", task.UserID)
+	}
+	if _, err := repo.CreateTask(context.Background(), createTestTask("Unowned task")); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	// Another user cannot read, update or delete the task
+	if _, err := repo.GetTaskByID(bob, task.ID); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for another user's task, got %v", err)
+	}
+	if _, err := repo.GetTaskByUID(bob, task.UID); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for another user's UID, got %v", err)
+	}
+	hijacked := *task
+	hijacked.Title = "Hijacked"
+	if err := repo.UpdateTask(bob, &hijacked); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	if err := repo.DeleteTask(bob, task.ID); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+
+	stored, err := repo.GetTaskByID(alice, task.ID)
+	if err != nil {
+		t.Fatalf("Expected owner to still see the task: %v", err)
+	}
+	if stored.Title != "Alice's task" {
+		t.Errorf("Expected title to be unchanged, got %s", stored.Title)
+	}
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		want int64
+	}{
+		{"owner", alice, 1},
+		{"other user", bob, 0},
+		{"unauthenticated", context.Background(), 1},
+	} {
+		count, err := repo.CountTasks(tc.ctx, TaskFilters{})
+		if err != nil {
+			t.Fatalf("CountTasks failed: %v", err)
+		}
+		if count != tc.want {
+			t.Errorf("%s: expected %d tasks, got %d", tc.name, tc.want, count)
+		}
+	}
+}
+
+func TestTaskRepository_Tags(t *testing.T) {
+	db := setupTaskTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+
+	task := createTestTask("Tagged task")
+	task.Tags = []string{"work", "urgent-ish"}
+	created, err := repo.CreateTask(ctx, task)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	fetched, err := repo.GetTaskByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if len(fetched.Tags) != 2 || fetched.Tags[0] != "urgent-ish" || fetched.Tags[1] != "work" {
+		t.Errorf("Expected tags [urgent-ish work], got %v", fetched.Tags)
+	}
+
+	// Updating replaces the tag set
+	fetched.Tags = []string{"home"}
+	if err := repo.UpdateTask(ctx, fetched); err != nil {
+		t.Fatalf("Failed to update task: %v", err)
+	}
+	fetched, err = repo.GetTaskByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if len(fetched.Tags) != 1 || fetched.Tags[0] != "home" {
+		t.Errorf("Expected tags [home], got %v", fetched.Tags)
+	}
+
+	untagged, err := repo.CreateTask(ctx, createTestTask("Untagged task"))
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if untagged.Tags == nil || len(untagged.Tags) != 0 {
+		t.Errorf("Expected empty tags, got %v", untagged.Tags)
+	}
+
+	// Deleting a task keeps its tag links for a restore; purging it
+	// removes them
+	if err := repo.DeleteTask(ctx, created.ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	if _, err := repo.PurgeDeletedTasks(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("Failed to purge tasks: %v", err)
+	}
+	var links int
+	if err := db.QueryRow("SELECT COUNT(*) FROM task_tags").Scan(&links); err != nil {
+		t.Fatalf("Failed to count task tags: %v", err)
+	}
+	if links != 0 {
+		t.Errorf("Expected no task tags left, got %d", links)
+	}
+}
+
+func TestTaskRepository_PriorityTagFiltersAndSorting(t *testing.T) {
+	db := setupTaskTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+
+	day := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	fixtures := []struct {
+		title    string
+		priority string
+		tags     []string
+		due      *time.Time
+	}{
+		{"bravo", models.TaskPriorityLow, []string{"work"}, timePtr(day.AddDate(0, 0, 2))},
+		{"Alpha", models.TaskPriorityUrgent, []string{"work", "ops"}, nil},
+		{"charlie", models.TaskPriorityHigh, []string{"home"}, timePtr(day)},
+		{"delta", models.TaskPriorityHigh, nil, timePtr(day.AddDate(0, 0, 1))},
+	}
+	for _, f := range fixtures {
+		task := createTestTask(f.title)
+		task.Priority = f.priority
+		task.Tags = f.tags
+		task.DueDate = f.due
+		if _, err := repo.CreateTask(ctx, task); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filters TaskFilters
+		want    []string
+	}{
+		{"priority filter", TaskFilters{Priority: models.TaskPriorityHigh, SortBy: TaskSortTitle}, []string{"charlie", "delta"}},
+		{"any tag", TaskFilters{Tags: []string{"ops", "home"}, SortBy: TaskSortTitle}, []string{"Alpha", "charlie"}},
+		{"all tags", TaskFilters{Tags: []string{"work", "ops"}, MatchAllTags: true}, []string{"Alpha"}},
+		{"priority descending", TaskFilters{SortBy: TaskSortPriority, SortDesc: true}, []string{"Alpha", "delta", "charlie", "bravo"}},
+		{"due date ascending", TaskFilters{SortBy: TaskSortDueDate}, []string{"charlie", "delta", "bravo", "Alpha"}},
+		{"due date descending", TaskFilters{SortBy: TaskSortDueDate, SortDesc: true}, []string{"bravo", "delta", "charlie", "Alpha"}},
+		{"title ascending", TaskFilters{SortBy: TaskSortTitle}, []string{"Alpha", "bravo", "charlie", "delta"}},
+		{"default newest first", TaskFilters{}, []string{"delta", "charlie", "Alpha", "bravo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := repo.ListTasks(ctx, tt.filters)
+			if err != nil {
+				t.Fatalf("ListTasks failed: %v", err)
+			}
+
+			var titles []string
+			for _, task := range tasks {
+				titles = append(titles, task.Title)
+			}
+			if strings.Join(titles, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, titles)
+			}
+
+			count, err := repo.CountTasks(ctx, tt.filters)
+			if err != nil {
+				t.Fatalf("CountTasks failed: %v", err)
+			}
+			if count != int64(len(tt.want)) {
+				t.Errorf("Expected count %d, got %d", len(tt.want), count)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestTaskRepository_SubtasksAndDependencies(t *testing.T) {
+	db := setupTaskTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+
+	create := func(title string, parentID *int, blockedBy ...int) *models.Task {
+		task := createTestTask(title)
+		task.ParentID = parentID
+		task.BlockedBy = blockedBy
+		created, err := repo.CreateTask(ctx, task)
+		if err != nil {
+			t.Fatalf("Failed to create task %q: %v", title, err)
+		}
+		return created
+	}
+
+	root := create("Root", nil)
+	child := create("Child", &root.ID)
+	grandchild := create("Grandchild", &child.ID)
+	done := create("Done child", &root.ID)
+	blocked := create("Blocked", nil, child.ID, done.ID)
+
+	done.Status = models.TaskStatusCompleted
+	if err := repo.UpdateTask(ctx, done); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+
+	// Progress rolls up subtasks at any depth
+	fetched, err := repo.GetTaskByID(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if fetched.Subtasks != (models.SubtaskProgress{Total: 3, Completed: 1}) {
+		t.Errorf("Expected 1 of 3 subtasks completed, got %+v", fetched.Subtasks)
+	}
+
+	subtasks, err := repo.GetSubtasks(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("Failed to get subtasks: %v", err)
+	}
+	if len(subtasks) != 2 || subtasks[0].ID != child.ID || subtasks[1].ID != done.ID {
+		t.Errorf("Expected direct subtasks [%d %d], got %v", child.ID, done.ID, subtasks)
+	}
+	if subtasks[0].Subtasks.Total != 1 {
+		t.Errorf("Expected child to have 1 subtask, got %+v", subtasks[0].Subtasks)
+	}
+
+	fetched, err = repo.GetTaskByID(ctx, blocked.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if len(fetched.BlockedBy) != 2 || fetched.BlockedBy[0] != child.ID || fetched.BlockedBy[1] != done.ID {
+		t.Errorf("Expected blockers [%d %d], got %v", child.ID, done.ID, fetched.BlockedBy)
+	}
+
+	// Deleting a task moves its subtasks up and hides the dependencies on it
+	if err := repo.DeleteTask(ctx, child.ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+
+	fetched, err = repo.GetTaskByID(ctx, grandchild.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if fetched.ParentID == nil || *fetched.ParentID != root.ID {
+		t.Errorf("Expected grandchild to move under the root, got parent %v", fetched.ParentID)
+	}
+
+	fetched, err = repo.GetTaskByID(ctx, blocked.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if len(fetched.BlockedBy) != 1 || fetched.BlockedBy[0] != done.ID {
+		t.Errorf("Expected blockers [%d], got %v", done.ID, fetched.BlockedBy)
+	}
+
+	subtasks, err = repo.GetSubtasks(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("Failed to get subtasks: %v", err)
+	}
+	if len(subtasks) != 2 || subtasks[0].ID != grandchild.ID || subtasks[1].ID != done.ID {
+		t.Errorf("Expected direct subtasks [%d %d], got %v", grandchild.ID, done.ID, subtasks)
+	}
+	fetched, err = repo.GetTaskByID(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if fetched.Subtasks != (models.SubtaskProgress{Total: 2, Completed: 1}) {
+		t.Errorf("Expected 1 of 2 subtasks completed, got %+v", fetched.Subtasks)
+	}
+
+	// Updating a moved subtask keeps it below the deleted task
+	fetched, err = repo.GetTaskByID(ctx, grandchild.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	fetched.Title = "Renamed grandchild"
+	if err := repo.UpdateTask(ctx, fetched); err != nil {
+		t.Fatalf("Failed to update task: %v", err)
+	}
+
+	// Restoring the task brings its subtasks and dependencies back
+	if err := repo.RestoreTask(ctx, child.ID); err != nil {
+		t.Fatalf("Failed to restore task: %v", err)
+	}
+
+	fetched, err = repo.GetTaskByID(ctx, grandchild.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if fetched.ParentID == nil || *fetched.ParentID != child.ID {
+		t.Errorf("Expected grandchild to be back under the child, got parent %v", fetched.ParentID)
+	}
+
+	fetched, err = repo.GetTaskByID(ctx, blocked.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if len(fetched.BlockedBy) != 2 || fetched.BlockedBy[0] != child.ID || fetched.BlockedBy[1] != done.ID {
+		t.Errorf("Expected blockers [%d %d], got %v", child.ID, done.ID, fetched.BlockedBy)
+	}
+}
+
+func TestTaskRepository_Trash(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+
+	create := func(task *models.Task) *models.Task {
+		created, err := repo.CreateTask(ctx, task)
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		return created
+	}
+
+	parent := create(createTestTask("Parent"))
+	subtask := create(&models.Task{Title: "Subtask", ParentID: &parent.ID, Tags: []string{"home"}, UID: "subtask@example.com"})
+	blocked := create(&models.Task{Title: "Blocked", BlockedBy: []int{subtask.ID}})
+
+	if err := repo.DeleteTask(ctx, subtask.ID); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+
+	t.Run("deleted tasks are hidden", func(t *testing.T) {
+		if _, err := repo.GetTaskByID(ctx, subtask.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows, got %v", err)
+		}
+		tasks, err := repo.ListTasks(ctx, TaskFilters{})
+		if err != nil {
+			t.Fatalf("ListTasks failed: %v", err)
+		}
+		if len(tasks) != 2 {
+			t.Errorf("Expected 2 tasks, got %d", len(tasks))
+		}
+		fetchedParent, err := repo.GetTaskByID(ctx, parent.ID)
+		if err != nil {
+			t.Fatalf("GetTaskByID failed: %v", err)
+		}
+		if fetchedParent.Subtasks.Total != 0 {
+			t.Errorf("Expected no subtasks, got %+v", fetchedParent.Subtasks)
+		}
+		fetchedBlocked, err := repo.GetTaskByID(ctx, blocked.ID)
+		if err != nil {
+			t.Fatalf("GetTaskByID failed: %v", err)
+		}
+		if len(fetchedBlocked.BlockedBy) != 0 || fetchedBlocked.Version != blocked.Version+1 {
+			t.Errorf("Expected the dependency to be hidden, got %v at version %d", fetchedBlocked.BlockedBy, fetchedBlocked.Version)
+		}
+	})
+
+	t.Run("lists the trash", func(t *testing.T) {
+		tasks, err := repo.ListDeletedTasks(ctx)
+		if err != nil {
+			t.Fatalf("ListDeletedTasks failed: %v", err)
+		}
+		if len(tasks) != 1 || tasks[0].ID != subtask.ID || tasks[0].DeletedAt == nil {
+			t.Fatalf("Expected the deleted subtask, got %+v", tasks)
+		}
+		if len(tasks[0].Tags) != 1 {
+			t.Errorf("Expected the tags to be kept, got %v", tasks[0].Tags)
+		}
+
+		otherTasks, err := repo.ListDeletedTasks(auth.WithUserID(context.Background(), 2))
+		if err != nil {
+			t.Fatalf("ListDeletedTasks failed: %v", err)
+		}
+		if len(otherTasks) != 0 {
+			t.Errorf("Expected the trash to be scoped to the owner, got %d tasks", len(otherTasks))
+		}
+	})
+
+	t.Run("restores into a live hierarchy with a free UID", func(t *testing.T) {
+		if err := repo.DeleteTask(ctx, parent.ID); err != nil {
+			t.Fatalf("DeleteTask failed: %v", err)
+		}
+		create(&models.Task{Title: "Imported", UID: "subtask@example.com"})
+
+		if err := repo.RestoreTask(ctx, subtask.ID); err != nil {
+			t.Fatalf("RestoreTask failed: %v", err)
+		}
+		restored, err := repo.GetTaskByID(ctx, subtask.ID)
+		if err != nil {
+			t.Fatalf("GetTaskByID failed: %v", err)
+		}
+		if restored.DeletedAt != nil || restored.ParentID != nil {
+			t.Errorf("Expected a live top-level task, got deleted_at %v and parent %v", restored.DeletedAt, restored.ParentID)
+		}
+		if restored.UID == "" || restored.UID == "subtask@example.com" {
+			t.Errorf("Expected a new UID, got %q", restored.UID)
+		}
+		if len(restored.Tags) != 1 || restored.Tags[0] != "home" {
+			t.Errorf("Expected the tags to be restored, got %v", restored.Tags)
+		}
+	})
+
+	t.Run("restore errors", func(t *testing.T) {
+		if err := repo.RestoreTask(ctx, subtask.ID); !errors.Is(err, ErrNotDeleted) {
+			t.Errorf("Expected ErrNotDeleted, got %v", err)
+		}
+		if err := repo.RestoreTask(ctx, 9999); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected sql.ErrNoRows, got %v", err)
+		}
+		if err := repo.RestoreTask(auth.WithUserID(context.Background(), 2), parent.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected sql.ErrNoRows for another user, got %v", err)
+		}
+	})
+
+	t.Run("purges old deletions", func(t *testing.T) {
+		purged, err := repo.PurgeDeletedTasks(ctx, time.Now().Add(-time.Hour))
+		if err != nil || purged != 0 {
+			t.Errorf("Expected nothing to purge, got %d (%v)", purged, err)
+		}
+		purged, err = repo.PurgeDeletedTasks(ctx, time.Now().Add(time.Second))
+		if err != nil || purged != 1 {
+			t.Errorf("Expected the parent to be purged, got %d (%v)", purged, err)
+		}
+		if err := repo.RestoreTask(ctx, parent.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected purged task to be gone, got %v", err)
+		}
+
+		// The subtask of the purged parent no longer points to it
+		var parentID *int
+		if err := db.QueryRow("SELECT parent_id FROM tasks WHERE id = ?", subtask.ID).Scan(&parentID); err != nil {
+			t.Fatalf("Failed to read parent: %v", err)
+		}
+		if parentID != nil {
+			t.Errorf("Expected the subtask to become a top-level task, got parent %d", *parentID)
+		}
+	})
+}
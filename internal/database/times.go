@@ -0,0 +1,13 @@
+package database
+
+import "time"
+
+// utcPtr returns a copy of t in UTC, nil when t is nil. Times are stored in
+// UTC so that they compare correctly as text whatever zone they were sent in.
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -3,8 +3,11 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+
+	"github.com/lib/pq"
 )
 
 // TxOptions represents transaction options
@@ -29,6 +32,34 @@ func ReadOnlyTxOptions() *sql.TxOptions {
 	}
 }
 
+// executor is the subset of *sql.DB and *sql.Tx used to run queries
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txContextKey is the context key of the current transaction
+type txContextKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying tx. Repositories called with
+// the returned context run their queries inside tx.
+func ContextWithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx, if any
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// Transactor runs a function atomically. The context passed to fn carries
+// the transaction, so repository calls made with it take part in it.
+type Transactor interface {
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // TransactionManager provides advanced transaction management
 type TransactionManager struct {
 	db *sql.DB
@@ -40,7 +71,7 @@ func NewTransactionManager(db *sql.DB) *TransactionManager {
 }
 
 // ExecuteInTransaction executes a function within a transaction with custom options
-func (tm *TransactionManager) ExecuteInTransaction(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+func (tm *TransactionManager) ExecuteInTransaction(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
 	if opts == nil {
 		opts = DefaultTxOptions()
 	}
@@ -71,6 +102,48 @@ func (tm *TransactionManager) ExecuteInTransaction(ctx context.Context, opts *sq
 	return err
 }
 
+// RunInTransaction executes fn within a transaction carried by its context.
+// When ctx already carries a transaction, fn joins it. The functions given
+// to AfterCommit within fn run once the transaction commits.
+func (tm *TransactionManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	hooks := &commitHooks{}
+	err := tm.ExecuteInTransaction(ctx, DefaultTxOptions(), func(tx *sql.Tx) error {
+		return fn(context.WithValue(ContextWithTx(ctx, tx), commitHooksContextKey{}, hooks))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks.fns {
+		hook()
+	}
+	return nil
+}
+
+// commitHooksContextKey is the context key of the functions to run once
+// the current transaction commits
+type commitHooksContextKey struct{}
+
+// commitHooks collects the functions given to AfterCommit
+type commitHooks struct {
+	fns []func()
+}
+
+// AfterCommit runs fn once the transaction started by RunInTransaction and
+// carried by ctx commits, or right away when ctx carries none. fn never runs
+// when the transaction rolls back.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(commitHooksContextKey{}).(*commitHooks); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+	fn()
+}
+
 // ExecuteReadOnly executes a function within a read-only transaction
 func (tm *TransactionManager) ExecuteReadOnly(ctx context.Context, fn func(tx *sql.Tx) error) error {
 	return tm.ExecuteInTransaction(ctx, ReadOnlyTxOptions(), fn)
@@ -108,6 +181,12 @@ func isRetryableError(err error) bool {
 		return false
 	}
 	
+	// For PostgreSQL, serialization failures and deadlocks are
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+	
 	errStr := err.Error()
 	return errStr == "database is locked" || errStr == "database table is locked"
 }
@@ -3,6 +3,8 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -17,7 +19,7 @@ func TestTransactionManager_ExecuteInTransaction(t *testing.T) {
 	
 	// Test successful transaction
 	err := tm.ExecuteInTransaction(ctx, DefaultTxOptions(), func(tx *sql.Tx) error {
-		_, err := tx.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "John Doe", "john@example.com")
+		_, err := tx.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "John Doe", "john@example.com")
 		return err
 	})
 	
@@ -42,7 +44,7 @@ func TestTransactionManager_ExecuteReadOnly(t *testing.T) {
 	defer db.Close()
 	
 	// Insert test data
-	_, err := db.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "John Doe", "john@example.com")
+	_, err := db.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "John Doe", "john@example.com")
 	if err != nil {
 		t.Fatalf("Failed to insert test data: %v", err)
 	}
@@ -74,15 +76,15 @@ func TestBatchExecutor_ExecuteBatch(t *testing.T) {
 	// Create operations to insert multiple records
 	operations := []func(tx *sql.Tx) error{
 		func(tx *sql.Tx) error {
-			_, err := tx.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "User 1", "user1@example.com")
+			_, err := tx.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "User 1", "user1@example.com")
 			return err
 		},
 		func(tx *sql.Tx) error {
-			_, err := tx.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "User 2", "user2@example.com")
+			_, err := tx.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "User 2", "user2@example.com")
 			return err
 		},
 		func(tx *sql.Tx) error {
-			_, err := tx.Exec("INSERT INTO test_models (name, email) VALUES (?, ?)", "User 3", "user3@example.com")
+			_, err := tx.Exec(rebind(db, "INSERT INTO test_models (name, email) VALUES (?, ?)"), "User 3", "user3@example.com")
 			return err
 		},
 	}
@@ -104,6 +106,112 @@ func TestBatchExecutor_ExecuteBatch(t *testing.T) {
 	}
 }
 
+func TestTransactionManager_RunInTransaction(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	tm := NewTransactionManager(db)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	insert := "INSERT INTO test_models (name, email) VALUES (?, ?)"
+
+	count := func() int64 {
+		n, err := repo.Count(ctx, "SELECT COUNT(*) FROM test_models")
+		if err != nil {
+			t.Fatalf("Failed to count rows: %v", err)
+		}
+		return n
+	}
+
+	// Repository calls made with the transaction context are rolled back
+	// together
+	errRollback := errors.New("rollback")
+	err := tm.RunInTransaction(ctx, func(ctx context.Context) error {
+		if _, ok := TxFromContext(ctx); !ok {
+			t.Error("Expected the context to carry the transaction")
+		}
+		if _, err := repo.Create(ctx, insert, "John Doe", "john@example.com"); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if err != errRollback {
+		t.Fatalf("Expected the error of fn, got %v", err)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("Expected the insert to be rolled back, got %d rows", n)
+	}
+
+	// Nested transactions join the outer one
+	err = tm.RunInTransaction(ctx, func(ctx context.Context) error {
+		outer, _ := TxFromContext(ctx)
+		if _, err := repo.Create(ctx, insert, "John Doe", "john@example.com"); err != nil {
+			return err
+		}
+		return tm.RunInTransaction(ctx, func(ctx context.Context) error {
+			if inner, _ := TxFromContext(ctx); inner != outer {
+				t.Error("Expected the nested call to join the outer transaction")
+			}
+			return repo.WithTransaction(ctx, func(tx *sql.Tx) error {
+				if tx != outer {
+					t.Error("Expected WithTransaction to join the outer transaction")
+				}
+				_, err := tx.ExecContext(ctx, rebind(db, insert), "Jane Doe", "jane@example.com")
+				return err
+			})
+		})
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	if n := count(); n != 2 {
+		t.Errorf("Expected 2 committed rows, got %d", n)
+	}
+}
+
+func TestAfterCommit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	tm := NewTransactionManager(db)
+	ctx := context.Background()
+
+	var ran []string
+	record := func(name string) func() {
+		return func() { ran = append(ran, name) }
+	}
+
+	// Without a transaction the function runs right away
+	AfterCommit(ctx, record("now"))
+
+	// Functions given within a rolled back transaction never run
+	_ = tm.RunInTransaction(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, record("rolled back"))
+		return errors.New("rollback")
+	})
+
+	// Functions given within a committed transaction, including nested
+	// calls, run in order once it commits
+	err := tm.RunInTransaction(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, record("outer"))
+		return tm.RunInTransaction(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, record("inner"))
+			if len(ran) != 1 {
+				t.Error("Expected the functions to wait for the commit")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+
+	want := []string{"now", "outer", "inner"}
+	if fmt.Sprint(ran) != fmt.Sprint(want) {
+		t.Errorf("Expected %v to run, got %v", want, ran)
+	}
+}
+
 func TestIsRetryableError(t *testing.T) {
 	tests := []struct {
 		name     string
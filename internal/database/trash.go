@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+)
+
+// notDeleted restricts a query to the tasks or events that are not in the
+// trash. Every query applies it except the ones listing, restoring and
+// purging the trash.
+const notDeleted = "deleted_at IS NULL"
+
+// restoredUID returns the UID of a task or event of table leaving the
+// trash. It keeps its UID unless a row of the same owner that is not
+// deleted took it in the meantime, typically through a calendar import, in
+// which case it gets a new one.
+func restoredUID(ctx context.Context, tx executor, table, uid string) (string, error) {
+	if uid == "" {
+		return uid, nil
+	}
+
+	var taken bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE uid = ? AND `+ownerCondition+` AND `+notDeleted+`)`,
+		uid, ownerArg(ctx)).Scan(&taken)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return newUID(), nil
+	}
+	return uid, nil
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tsQuery translates a query in the SQLite full-text query syntax into a
+// PostgreSQL tsquery expression, so searches behave the same on both
+// databases. Terms and "quoted phrases" become lexemes and <-> phrases,
+// prefix* terms become prefix matches, and the AND, OR and NOT operators
+// keep their precedence: NOT binds tighter than AND, which binds tighter
+// than OR, and adjacent terms are ANDed. It returns ErrInvalidSearchQuery
+// for malformed queries, and the empty string for queries without terms.
+func tsQuery(query string) (string, error) {
+	tokens, err := searchTokens(query)
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "", nil
+	}
+
+	p := &tsQueryParser{tokens: tokens}
+	expr, err := p.or()
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(p.tokens) {
+		return "", ErrInvalidSearchQuery
+	}
+	return expr, nil
+}
+
+// searchToken is a token of a full-text query: an operator, a parenthesis,
+// or a term already translated into a tsquery expression
+type searchToken struct {
+	op   string
+	term string
+}
+
+// searchTokens splits a full-text query into tokens
+func searchTokens(query string) ([]searchToken, error) {
+	var tokens []searchToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, searchToken{op: string(c)})
+			i++
+		case c == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				return nil, ErrInvalidSearchQuery
+			}
+			term := tsPhrase(strings.Fields(query[i+1 : i+1+end]))
+			if term == "" {
+				return nil, ErrInvalidSearchQuery
+			}
+			tokens = append(tokens, searchToken{term: term})
+			i += end + 2
+		default:
+			end := strings.IndexAny(query[i:], " \t\n\r()\"")
+			if end < 0 {
+				end = len(query) - i
+			}
+			word := query[i : i+end]
+			i += end
+
+			switch word {
+			case "AND", "OR", "NOT":
+				tokens = append(tokens, searchToken{op: word})
+			default:
+				// Punctuation alone is not a term
+				if term := tsPhrase([]string{word}); term != "" {
+					tokens = append(tokens, searchToken{term: term})
+				}
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// tsPhrase translates the words of a term or phrase into a tsquery
+// expression. Words are split at punctuation the way the SQLite tokenizer
+// splits them, and a trailing * makes a word a prefix.
+func tsPhrase(words []string) string {
+	var lexemes []string
+	for _, word := range words {
+		prefix := strings.HasSuffix(word, "*")
+		parts := strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for i, part := range parts {
+			lexeme := strings.ToLower(part)
+			if prefix && i == len(parts)-1 {
+				lexeme += ":*"
+			}
+			lexemes = append(lexemes, lexeme)
+		}
+	}
+
+	switch len(lexemes) {
+	case 0:
+		return ""
+	case 1:
+		return lexemes[0]
+	}
+	return "(" + strings.Join(lexemes, " <-> ") + ")"
+}
+
+// tsQueryParser parses the tokens of a full-text query into a tsquery
+// expression
+type tsQueryParser struct {
+	tokens []searchToken
+	pos    int
+}
+
+// peek returns the operator of the next token, "term" for terms and the
+// empty string at the end of the query
+func (p *tsQueryParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	if p.tokens[p.pos].op == "" {
+		return "term"
+	}
+	return p.tokens[p.pos].op
+}
+
+func (p *tsQueryParser) or() (string, error) {
+	left, err := p.and()
+	if err != nil {
+		return "", err
+	}
+	for p.peek() == "OR" {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " | " + right + ")"
+	}
+	return left, nil
+}
+
+func (p *tsQueryParser) and() (string, error) {
+	left, err := p.not()
+	if err != nil {
+		return "", err
+	}
+	for {
+		switch p.peek() {
+		case "AND":
+			p.pos++
+		case "term", "(":
+		default:
+			return left, nil
+		}
+		right, err := p.not()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " & " + right + ")"
+	}
+}
+
+func (p *tsQueryParser) not() (string, error) {
+	left, err := p.primary()
+	if err != nil {
+		return "", err
+	}
+	for p.peek() == "NOT" {
+		p.pos++
+		right, err := p.primary()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " & !" + right + ")"
+	}
+	return left, nil
+}
+
+func (p *tsQueryParser) primary() (string, error) {
+	switch p.peek() {
+	case "term":
+		term := p.tokens[p.pos].term
+		p.pos++
+		return term, nil
+	case "(":
+		p.pos++
+		expr, err := p.or()
+		if err != nil {
+			return "", err
+		}
+		if p.peek() != ")" {
+			return "", ErrInvalidSearchQuery
+		}
+		p.pos++
+		return expr, nil
+	}
+	// Operators without an operand, such as a leading NOT
+	return "", ErrInvalidSearchQuery
+}
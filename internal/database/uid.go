@@ -0,0 +1,19 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// uidDomain is the domain part of the iCalendar UIDs generated for new records
+const uidDomain = "agenda"
+
+// newUID generates a globally unique iCalendar UID for a new record
+func newUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b) + "@" + uidDomain
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"agenda/internal/models"
+)
+
+// UndoRepositoryInterface defines the contract for undo record repository
+// operations
+type UndoRepositoryInterface interface {
+	BaseRepository
+
+	// Undo methods, scoped to the authenticated user
+	CreateUndoRecord(ctx context.Context, record *models.UndoRecord) (*models.UndoRecord, error)
+	GetUndoRecords(ctx context.Context, token string) ([]*models.UndoRecord, error)
+	MarkUndone(ctx context.Context, token string, undoneAt time.Time) error
+	GetItemVersion(ctx context.Context, entityType string, id int) (int, error)
+
+	// PurgeExpiredUndoRecords removes the records of any user
+	PurgeExpiredUndoRecords(ctx context.Context, expiredBefore time.Time) (int64, error)
+}
+
+// undoColumns lists the selected undo record columns in models.UndoRecord field order
+const undoColumns = "id, token, entity_type, entity_id, action, version, previous, user_id, created_at, expires_at, undone_at"
+
+// UndoRepository implements UndoRepositoryInterface
+type UndoRepository struct {
+	*Repository
+}
+
+// NewUndoRepository creates a new undo repository instance
+func NewUndoRepository(db *sql.DB) UndoRepositoryInterface {
+	return &UndoRepository{
+		Repository: NewRepository(db),
+	}
+}
+
+// CreateUndoRecord stores a change that can be undone. The record belongs
+// to the owner of the changed item, set by the caller.
+func (ur *UndoRepository) CreateUndoRecord(ctx context.Context, record *models.UndoRecord) (*models.UndoRecord, error) {
+	query := `
+		INSERT INTO undo_records (token, entity_type, entity_id, action, version, previous, user_id, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+
+	// Store times in UTC so they compare correctly as text
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.ExpiresAt = record.ExpiresAt.UTC()
+
+	id, err := ur.Create(ctx, query, record.Token, record.EntityType, record.EntityID, record.Action, record.Version,
+		record.Previous, record.UserID, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create undo record: %w", err)
+	}
+
+	record.ID = int(id)
+	return record, nil
+}
+
+// GetUndoRecords retrieves the changes recorded under a token, in the order
+// they were made
+func (ur *UndoRepository) GetUndoRecords(ctx context.Context, token string) ([]*models.UndoRecord, error) {
+	query := `
+		SELECT ` + undoColumns + `
+		FROM undo_records
+		WHERE token = ? AND ` + ownerCondition + `
+		ORDER BY id
+	`
+
+	var records []*models.UndoRecord
+	err := ur.List(ctx, &records, query, token, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get undo records: %w", err)
+	}
+
+	return records, nil
+}
+
+// MarkUndone marks the changes recorded under a token as undone.
+// sql.ErrNoRows is returned when they were already undone, so that a token
+// is only used once even by concurrent requests.
+func (ur *UndoRepository) MarkUndone(ctx context.Context, token string, undoneAt time.Time) error {
+	result, err := ur.conn(ctx).ExecContext(ctx, `
+		UPDATE undo_records
+		SET undone_at = ?
+		WHERE token = ? AND `+ownerCondition+` AND undone_at IS NULL
+	`, undoneAt.UTC(), token, ownerArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to mark undo records: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark undo records: %w", err)
+	}
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetItemVersion returns the current version of a task or event, including
+// deleted ones. sql.ErrNoRows is returned when the item does not exist.
+func (ur *UndoRepository) GetItemVersion(ctx context.Context, entityType string, id int) (int, error) {
+	var table string
+	switch entityType {
+	case models.AuditEntityTask:
+		table = "tasks"
+	case models.AuditEntityEvent:
+		table = "events"
+	default:
+		return 0, fmt.Errorf("unknown entity type %q", entityType)
+	}
+
+	var version int
+	err := ur.conn(ctx).QueryRowContext(ctx, `SELECT version FROM `+table+` WHERE id = ? AND `+ownerCondition,
+		id, ownerArg(ctx)).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// PurgeExpiredUndoRecords removes the undo records of any user that expired
+// before the given time. It returns the number of records removed.
+func (ur *UndoRepository) PurgeExpiredUndoRecords(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result, err := ur.conn(ctx).ExecContext(ctx, `DELETE FROM undo_records WHERE expires_at < ?`, expiredBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge undo records: %w", err)
+	}
+
+	return result.RowsAffected()
+}
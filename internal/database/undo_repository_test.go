@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+)
+
+func TestUndoRepository(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewUndoRepository(db)
+	taskRepo := NewTaskRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+	otherCtx := auth.WithUserID(context.Background(), 2)
+	userID := 1
+
+	task, err := taskRepo.CreateTask(ctx, &models.Task{Title: "Pay rent", Status: models.TaskStatusPending})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	now := time.Now()
+	previous := `{"title":"Pay the rent"}`
+	for _, record := range []*models.UndoRecord{
+		{Token: "abc", EntityType: models.AuditEntityTask, EntityID: task.ID, Action: "updated", Version: 2, Previous: &previous},
+		{Token: "abc", EntityType: models.AuditEntityTask, EntityID: task.ID + 1, Action: "created", Version: 1},
+		{Token: "old", EntityType: models.AuditEntityTask, EntityID: task.ID, Action: "created", Version: 1,
+			CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(-30 * time.Minute)},
+	} {
+		record.UserID = &userID
+		if record.ExpiresAt.IsZero() {
+			record.ExpiresAt = now.Add(time.Minute)
+		}
+		if _, err := repo.CreateUndoRecord(ctx, record); err != nil {
+			t.Fatalf("CreateUndoRecord failed: %v", err)
+		}
+	}
+
+	records, err := repo.GetUndoRecords(ctx, "abc")
+	if err != nil {
+		t.Fatalf("GetUndoRecords failed: %v", err)
+	}
+	if len(records) != 2 || records[0].Action != "updated" || records[1].Action != "created" {
+		t.Fatalf("Expected the records in order, got %+v", records)
+	}
+	if records[0].Previous == nil || *records[0].Previous != previous || records[1].Previous != nil {
+		t.Errorf("Unexpected previous snapshots %v and %v", records[0].Previous, records[1].Previous)
+	}
+	if records[0].UndoneAt != nil || !records[0].ExpiresAt.After(now) {
+		t.Errorf("Unexpected state %+v", records[0])
+	}
+
+	if records, err := repo.GetUndoRecords(otherCtx, "abc"); err != nil || len(records) != 0 {
+		t.Errorf("Expected the records to be scoped to their owner, got %d (%v)", len(records), err)
+	}
+	if err := repo.MarkUndone(otherCtx, "abc", now); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for another user, got %v", err)
+	}
+
+	if err := repo.MarkUndone(ctx, "abc", now); err != nil {
+		t.Fatalf("MarkUndone failed: %v", err)
+	}
+	if err := repo.MarkUndone(ctx, "abc", now); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows when already undone, got %v", err)
+	}
+	records, err = repo.GetUndoRecords(ctx, "abc")
+	if err != nil || records[0].UndoneAt == nil {
+		t.Errorf("Expected the records to be undone, got %+v (%v)", records, err)
+	}
+
+	t.Run("item versions include deleted items", func(t *testing.T) {
+		if err := taskRepo.DeleteTask(ctx, task.ID); err != nil {
+			t.Fatalf("DeleteTask failed: %v", err)
+		}
+		version, err := repo.GetItemVersion(ctx, models.AuditEntityTask, task.ID)
+		if err != nil || version != task.Version+1 {
+			t.Errorf("Expected version %d, got %d (%v)", task.Version+1, version, err)
+		}
+		if _, err := repo.GetItemVersion(otherCtx, models.AuditEntityTask, task.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows for another user, got %v", err)
+		}
+		if _, err := repo.GetItemVersion(ctx, models.AuditEntityEvent, task.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected sql.ErrNoRows for a missing event, got %v", err)
+		}
+	})
+
+	t.Run("purge expired records", func(t *testing.T) {
+		purged, err := repo.PurgeExpiredUndoRecords(context.Background(), now)
+		if err != nil || purged != 1 {
+			t.Errorf("Expected one record purged, got %d (%v)", purged, err)
+		}
+		if records, err := repo.GetUndoRecords(ctx, "old"); err != nil || len(records) != 0 {
+			t.Errorf("Expected the expired record to be gone, got %d (%v)", len(records), err)
+		}
+	})
+}
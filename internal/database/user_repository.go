@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"agenda/internal/models"
+)
+
+// ErrDuplicateEmail is returned when creating a user with an email that is already registered
+var ErrDuplicateEmail = errors.New("email already registered")
+
+// UserRepositoryInterface defines the contract for user and session repository operations
+type UserRepositoryInterface interface {
+	BaseRepository
+
+	// User methods
+	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+
+	// Session methods
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, tokenHash string) (*models.Session, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+	DeleteExpiredSessions(ctx context.Context, now time.Time) error
+}
+
+// userColumns lists the selected user columns in models.User field order
+const userColumns = "id, email, name, password_hash, created_at, updated_at"
+
+// sessionColumns lists the selected session columns in models.Session field order
+const sessionColumns = "token_hash, user_id, expires_at, created_at"
+
+// UserRepository implements UserRepositoryInterface
+type UserRepository struct {
+	*Repository
+}
+
+// NewUserRepository creates a new user repository instance
+func NewUserRepository(db *sql.DB) UserRepositoryInterface {
+	return &UserRepository{
+		Repository: NewRepository(db),
+	}
+}
+
+// CreateUser creates a new user in the database
+func (ur *UserRepository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	query := `
+		INSERT INTO users (email, name, password_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	id, err := ur.Create(ctx, query, user.Email, user.Name, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrDuplicateEmail
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	user.ID = int(id)
+	return user, nil
+}
+
+// GetUserByID retrieves a user by its ID
+func (ur *UserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = ?
+	`
+
+	var user models.User
+	err := ur.GetByID(ctx, &user, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// GetUserByEmail retrieves a user by its email address
+func (ur *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE email = ?
+	`
+
+	var user models.User
+	err := ur.Get(ctx, &user, query, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	return &user, nil
+}
+
+// CreateSession stores a new login session
+func (ur *UserRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (token_hash, user_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	// Store expiry times in UTC so they compare correctly as text
+	session.ExpiresAt = session.ExpiresAt.UTC()
+	session.CreatedAt = time.Now()
+
+	// Sessions are keyed by their token digest and have no generated ID
+	if _, err := ur.conn(ctx).ExecContext(ctx, query, session.TokenHash, session.UserID, session.ExpiresAt, session.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// GetSession retrieves a session by the digest of its token
+func (ur *UserRepository) GetSession(ctx context.Context, tokenHash string) (*models.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE token_hash = ?
+	`
+
+	var session models.Session
+	err := ur.Get(ctx, &session, query, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// DeleteSession removes a session, logging it out
+func (ur *UserRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	if err := ur.Delete(ctx, `DELETE FROM sessions WHERE token_hash = ?`, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredSessions removes the sessions that expired before now
+func (ur *UserRepository) DeleteExpiredSessions(ctx context.Context, now time.Time) error {
+	if err := ur.Delete(ctx, `DELETE FROM sessions WHERE expires_at <= ?`, now.UTC()); err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return nil
+}
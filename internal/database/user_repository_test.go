@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"agenda/internal/models"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupUserTestDB creates a test database with the full schema
+func setupUserTestDB(t *testing.T) *sql.DB {
+	return migratedTestDB(t)
+}
+
+func TestUserRepository_Users(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user, err := repo.CreateUser(ctx, &models.User{Email: "alice@example.com", Name: "Alice", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if user.ID == 0 {
+		t.Error("Expected user ID to be set")
+	}
+
+	if _, err := repo.CreateUser(ctx, &models.User{Email: "alice@example.com", PasswordHash: "hash"}); err != ErrDuplicateEmail {
+		t.Errorf("Expected ErrDuplicateEmail, got %v", err)
+	}
+
+	byEmail, err := repo.GetUserByEmail(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("GetUserByEmail failed: %v", err)
+	}
+	if byEmail.ID != user.ID || byEmail.PasswordHash != "hash" {
+		t.Errorf("Unexpected user: %+v", byEmail)
+	}
+
+	byID, err := repo.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID failed: %v", err)
+	}
+	if byID.Email != "alice@example.com" || byID.Name != "Alice" {
+		t.Errorf("Unexpected user: %+v", byID)
+	}
+
+	if _, err := repo.GetUserByEmail(ctx, "bob@example.com"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestUserRepository_Sessions(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user, err := repo.CreateUser(ctx, &models.User{Email: "alice@example.com", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	now := time.Now()
+	active := &models.Session{TokenHash: "active", UserID: user.ID, ExpiresAt: now.Add(time.Hour)}
+	expired := &models.Session{TokenHash: "expired", UserID: user.ID, ExpiresAt: now.Add(-time.Hour)}
+	for _, session := range []*models.Session{active, expired} {
+		if err := repo.CreateSession(ctx, session); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+	}
+
+	session, err := repo.GetSession(ctx, "active")
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if session.UserID != user.ID || session.IsExpired(now) {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+
+	if err := repo.DeleteExpiredSessions(ctx, now); err != nil {
+		t.Fatalf("DeleteExpiredSessions failed: %v", err)
+	}
+	if _, err := repo.GetSession(ctx, "expired"); err != sql.ErrNoRows {
+		t.Errorf("Expected expired session to be deleted, got %v", err)
+	}
+
+	if err := repo.DeleteSession(ctx, "active"); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if _, err := repo.GetSession(ctx, "active"); err != sql.ErrNoRows {
+		t.Errorf("Expected session to be deleted, got %v", err)
+	}
+}
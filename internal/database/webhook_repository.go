@@ -0,0 +1,384 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"agenda/internal/models"
+)
+
+// WebhookRepositoryInterface defines the contract for webhook repository operations
+type WebhookRepositoryInterface interface {
+	BaseRepository
+
+	// Webhook methods, scoped to the authenticated user
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error)
+	GetWebhookByID(ctx context.Context, id int) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	GetActiveWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id int) error
+
+	// Delivery methods, scoped to the authenticated user
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error)
+	GetDeliveryByID(ctx context.Context, webhookID, id int) (*models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, webhookID int, limit int) ([]*models.WebhookDelivery, error)
+	GetDeliveryAttempts(ctx context.Context, deliveryID int) ([]*models.WebhookDeliveryAttempt, error)
+
+	// Dispatcher methods. These see the deliveries of every user.
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error)
+	ClaimDelivery(ctx context.Context, delivery *models.WebhookDelivery) (bool, error)
+	SaveDeliveryState(ctx context.Context, delivery *models.WebhookDelivery) error
+	RecordDeliveryAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt) error
+	ResetInterruptedDeliveries(ctx context.Context) (int64, error)
+}
+
+// webhookColumns lists the selected webhook columns in models.Webhook field order
+const webhookColumns = "id, url, secret, event_types, description, active, user_id, created_at, updated_at"
+
+// deliveryColumns lists the selected delivery columns in models.WebhookDelivery field order
+const deliveryColumns = "id, webhook_id, uid, event_type, payload, status, attempts, next_attempt_at, " +
+	"last_status_code, last_error, delivered_at, user_id, created_at, updated_at"
+
+// WebhookRepository implements WebhookRepositoryInterface
+type WebhookRepository struct {
+	*Repository
+}
+
+// NewWebhookRepository creates a new webhook repository instance
+func NewWebhookRepository(db *sql.DB) WebhookRepositoryInterface {
+	return &WebhookRepository{
+		Repository: NewRepository(db),
+	}
+}
+
+// CreateWebhook creates a new webhook in the database
+func (wr *WebhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (url, secret, event_types, description, active, user_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	// New webhooks belong to the authenticated user
+	webhook.UserID = ownerID(ctx, webhook.UserID)
+
+	id, err := wr.Create(ctx, query, webhook.URL, webhook.Secret, webhook.EventTypes, webhook.Description,
+		webhook.Active, webhook.UserID, webhook.CreatedAt, webhook.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	webhook.ID = int(id)
+	return webhook, nil
+}
+
+// GetWebhookByID retrieves a webhook by its ID
+func (wr *WebhookRepository) GetWebhookByID(ctx context.Context, id int) (*models.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE id = ? AND ` + ownerCondition + `
+	`
+
+	var webhook models.Webhook
+	err := wr.Get(ctx, &webhook, query, id, ownerArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+// ListWebhooks retrieves every webhook, oldest first
+func (wr *WebhookRepository) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE ` + ownerCondition + `
+		ORDER BY id ASC
+	`
+
+	var webhooks []*models.Webhook
+	err := wr.List(ctx, &webhooks, query, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// GetActiveWebhooks retrieves the webhooks that receive deliveries
+func (wr *WebhookRepository) GetActiveWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE active AND ` + ownerCondition + `
+		ORDER BY id ASC
+	`
+
+	var webhooks []*models.Webhook
+	err := wr.List(ctx, &webhooks, query, ownerArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook updates an existing webhook
+func (wr *WebhookRepository) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = ?, secret = ?, event_types = ?, description = ?, active = ?, updated_at = ?
+		WHERE id = ? AND ` + ownerCondition + `
+	`
+
+	webhook.UpdatedAt = time.Now()
+
+	err := wr.Update(ctx, query, webhook.URL, webhook.Secret, webhook.EventTypes, webhook.Description,
+		webhook.Active, webhook.UpdatedAt, webhook.ID, ownerArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteWebhook removes a webhook along with its deliveries and their
+// attempts
+func (wr *WebhookRepository) DeleteWebhook(ctx context.Context, id int) error {
+	err := wr.inTransaction(ctx, func(tx executor) error {
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM webhook_delivery_attempts
+			WHERE delivery_id IN (SELECT id FROM webhook_deliveries WHERE webhook_id = ? AND `+ownerCondition+`)
+		`, id, ownerArg(ctx)); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ? AND `+ownerCondition,
+			id, ownerArg(ctx)); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ? AND `+ownerCondition, id, ownerArg(ctx))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// CreateDelivery queues a delivery in the outbox
+func (wr *WebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, uid, event_type, payload, status, attempts, next_attempt_at,
+			last_status_code, last_error, delivered_at, user_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+
+	if delivery.Status == "" {
+		delivery.Status = models.DeliveryPending
+	}
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+
+	// Store delivery times in UTC so they compare correctly as text
+	delivery.NextAttemptAt = delivery.NextAttemptAt.UTC()
+
+	// New deliveries belong to the authenticated user
+	delivery.UserID = ownerID(ctx, delivery.UserID)
+
+	id, err := wr.Create(ctx, query, delivery.WebhookID, delivery.UID, delivery.EventType, string(delivery.Payload),
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError,
+		delivery.DeliveredAt, delivery.UserID, delivery.CreatedAt, delivery.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	delivery.ID = int(id)
+	return delivery, nil
+}
+
+// GetDeliveryByID retrieves a delivery of a webhook by its ID
+func (wr *WebhookRepository) GetDeliveryByID(ctx context.Context, webhookID, id int) (*models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE id = ? AND webhook_id = ? AND ` + ownerCondition + `
+	`
+
+	var delivery models.WebhookDelivery
+	err := wr.Get(ctx, &delivery, query, id, webhookID, ownerArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+// ListDeliveries retrieves up to limit deliveries of a webhook, newest first
+func (wr *WebhookRepository) ListDeliveries(ctx context.Context, webhookID int, limit int) ([]*models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = ? AND ` + ownerCondition + `
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	var deliveries []*models.WebhookDelivery
+	err := wr.List(ctx, &deliveries, query, webhookID, ownerArg(ctx), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// GetDeliveryAttempts retrieves the attempts made at sending a delivery, in
+// order. The delivery is expected to have been loaded for its owner first.
+func (wr *WebhookRepository) GetDeliveryAttempts(ctx context.Context, deliveryID int) ([]*models.WebhookDeliveryAttempt, error) {
+	query := `
+		SELECT id, delivery_id, attempt, status_code, error, response_body, duration_ms, attempted_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = ?
+		ORDER BY attempt ASC, id ASC
+	`
+
+	var attempts []*models.WebhookDeliveryAttempt
+	err := wr.List(ctx, &attempts, query, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// GetDueDeliveries retrieves up to limit pending deliveries of any user
+// whose next attempt is not after now, oldest first
+func (wr *WebhookRepository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT ?
+	`
+
+	var deliveries []*models.WebhookDelivery
+	err := wr.List(ctx, &deliveries, query, models.DeliveryPending, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDelivery moves a pending delivery to the delivering status and
+// counts the attempt. It reports false when the delivery was claimed since
+// it was read, in which case it must not be sent.
+func (wr *WebhookRepository) ClaimDelivery(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id = ? AND status = ? AND attempts = ?
+	`
+
+	now := time.Now()
+	result, err := wr.conn(ctx).ExecContext(ctx, query, models.DeliveryDelivering, now,
+		delivery.ID, models.DeliveryPending, delivery.Attempts)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	if claimed == 0 {
+		return false, nil
+	}
+
+	delivery.Status = models.DeliveryDelivering
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+	return true, nil
+}
+
+// SaveDeliveryState stores the scheduling and outcome fields of a delivery
+func (wr *WebhookRepository) SaveDeliveryState(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, delivered_at = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	delivery.NextAttemptAt = delivery.NextAttemptAt.UTC()
+	delivery.UpdatedAt = time.Now()
+
+	err := wr.Update(ctx, query, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode,
+		delivery.LastError, delivery.DeliveredAt, delivery.UpdatedAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook delivery state: %w", err)
+	}
+
+	return nil
+}
+
+// RecordDeliveryAttempt adds an attempt to the delivery log
+func (wr *WebhookRepository) RecordDeliveryAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt) error {
+	query := `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, response_body, duration_ms,
+			attempted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := wr.Create(ctx, query, attempt.DeliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error,
+		attempt.ResponseBody, attempt.DurationMS, attempt.AttemptedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+
+	attempt.ID = int(id)
+	return nil
+}
+
+// ResetInterruptedDeliveries puts the deliveries left in the delivering
+// status by a dispatcher that stopped mid-delivery back in the queue.
+// Whether they reached the receiver is unknown, so they may be delivered
+// twice; receivers recognise repeated deliveries by their UID.
+func (wr *WebhookRepository) ResetInterruptedDeliveries(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, last_error = ?, updated_at = ?
+		WHERE status = ?
+	`
+
+	result, err := wr.conn(ctx).ExecContext(ctx, query, models.DeliveryPending,
+		"delivery interrupted by a shutdown", time.Now(), models.DeliveryDelivering)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset interrupted webhook deliveries: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+)
+
+func TestWebhookRepository_CRUD(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+	otherCtx := auth.WithUserID(context.Background(), 2)
+
+	webhook := &models.Webhook{
+		URL:        "https://hooks.example.com/agenda",
+		Secret:     "whsec_0123456789abcdef",
+		EventTypes: models.StringList{"task.created", "event.deleted"},
+		Active:     true,
+	}
+	created, err := repo.CreateWebhook(ctx, webhook)
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+
+	fetched, err := repo.GetWebhookByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetWebhookByID failed: %v", err)
+	}
+	if fetched.Secret != webhook.Secret || !fetched.Active || len(fetched.EventTypes) != 2 ||
+		!fetched.EventTypes.Contains("event.deleted") {
+		t.Errorf("Unexpected webhook %+v", fetched)
+	}
+
+	if _, err := repo.GetWebhookByID(otherCtx, created.ID); err != sql.ErrNoRows {
+		t.Errorf("Expected other users not to see the webhook, got %v", err)
+	}
+
+	fetched.Active = false
+	if err := repo.UpdateWebhook(ctx, fetched); err != nil {
+		t.Fatalf("UpdateWebhook failed: %v", err)
+	}
+	active, err := repo.GetActiveWebhooks(ctx)
+	if err != nil {
+		t.Fatalf("GetActiveWebhooks failed: %v", err)
+	}
+	if len(active) != 0 {
+		t.Errorf("Expected no active webhooks, got %d", len(active))
+	}
+
+	delivery, err := repo.CreateDelivery(ctx, &models.WebhookDelivery{
+		WebhookID: created.ID,
+		UID:       "dlv_1",
+		EventType: "task.created",
+		Payload:   `{"id":"dlv_1"}`,
+	})
+	if err != nil {
+		t.Fatalf("CreateDelivery failed: %v", err)
+	}
+	if err := repo.RecordDeliveryAttempt(ctx, &models.WebhookDeliveryAttempt{
+		DeliveryID: delivery.ID, Attempt: 1, StatusCode: 500, AttemptedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("RecordDeliveryAttempt failed: %v", err)
+	}
+
+	t.Run("deleting the webhook deletes its delivery log", func(t *testing.T) {
+		if err := repo.DeleteWebhook(ctx, created.ID); err != nil {
+			t.Fatalf("DeleteWebhook failed: %v", err)
+		}
+		if _, err := repo.GetWebhookByID(ctx, created.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected webhook to be deleted, got %v", err)
+		}
+		if _, err := repo.GetDeliveryByID(ctx, created.ID, delivery.ID); err != sql.ErrNoRows {
+			t.Errorf("Expected delivery to be deleted, got %v", err)
+		}
+		attempts, err := repo.GetDeliveryAttempts(ctx, delivery.ID)
+		if err != nil {
+			t.Fatalf("GetDeliveryAttempts failed: %v", err)
+		}
+		if len(attempts) != 0 {
+			t.Errorf("Expected attempts to be deleted, got %d", len(attempts))
+		}
+	})
+}
+
+func TestWebhookRepository_Deliveries(t *testing.T) {
+	db := setupReminderTestDB(t)
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	ctx := auth.WithUserID(context.Background(), 1)
+	now := time.Now()
+
+	webhook, err := repo.CreateWebhook(ctx, &models.Webhook{URL: "https://hooks.example.com", Secret: "s",
+		EventTypes: models.StringList{"task.created"}, Active: true})
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+
+	due, err := repo.CreateDelivery(ctx, &models.WebhookDelivery{WebhookID: webhook.ID, UID: "dlv_due",
+		EventType: "task.created", Payload: `{}`, NextAttemptAt: now.Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("CreateDelivery failed: %v", err)
+	}
+	if _, err := repo.CreateDelivery(ctx, &models.WebhookDelivery{WebhookID: webhook.ID, UID: "dlv_later",
+		EventType: "task.created", Payload: `{}`, NextAttemptAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("CreateDelivery failed: %v", err)
+	}
+
+	// Due deliveries are found regardless of the owner
+	found, err := repo.GetDueDeliveries(context.Background(), now, 10)
+	if err != nil {
+		t.Fatalf("GetDueDeliveries failed: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != due.ID {
+		t.Fatalf("Expected only the due delivery, got %d deliveries", len(found))
+	}
+
+	t.Run("a delivery is claimed once", func(t *testing.T) {
+		first := *found[0]
+		claimed, err := repo.ClaimDelivery(ctx, &first)
+		if err != nil || !claimed {
+			t.Fatalf("Expected first claim to succeed, got %v, %v", claimed, err)
+		}
+		if first.Status != models.DeliveryDelivering || first.Attempts != 1 {
+			t.Errorf("Unexpected claimed state: %s, %d attempts", first.Status, first.Attempts)
+		}
+
+		second := *found[0]
+		claimed, err = repo.ClaimDelivery(ctx, &second)
+		if err != nil || claimed {
+			t.Errorf("Expected second claim to fail, got %v, %v", claimed, err)
+		}
+	})
+
+	t.Run("interrupted deliveries are requeued", func(t *testing.T) {
+		reset, err := repo.ResetInterruptedDeliveries(ctx)
+		if err != nil {
+			t.Fatalf("ResetInterruptedDeliveries failed: %v", err)
+		}
+		if reset != 1 {
+			t.Errorf("Expected 1 interrupted delivery, got %d", reset)
+		}
+
+		delivery, err := repo.GetDeliveryByID(ctx, webhook.ID, due.ID)
+		if err != nil {
+			t.Fatalf("GetDeliveryByID failed: %v", err)
+		}
+		if delivery.Status != models.DeliveryPending || delivery.Attempts != 1 {
+			t.Errorf("Expected pending delivery with 1 attempt, got %s, %d", delivery.Status, delivery.Attempts)
+		}
+	})
+
+	t.Run("deliveries are listed newest first", func(t *testing.T) {
+		deliveries, err := repo.ListDeliveries(ctx, webhook.ID, 10)
+		if err != nil {
+			t.Fatalf("ListDeliveries failed: %v", err)
+		}
+		if len(deliveries) != 2 || deliveries[0].UID != "dlv_later" {
+			t.Errorf("Unexpected deliveries %+v", deliveries)
+		}
+		if string(deliveries[0].Payload) != `{}` {
+			t.Errorf("Unexpected payload %q", deliveries[0].Payload)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"agenda/internal/api"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttendeeHandler handles HTTP requests for event attendees
+type AttendeeHandler struct {
+	attendeeService services.AttendeeServiceInterface
+}
+
+// NewAttendeeHandler creates a new attendee handler instance
+func NewAttendeeHandler(attendeeService services.AttendeeServiceInterface) *AttendeeHandler {
+	return &AttendeeHandler{
+		attendeeService: attendeeService,
+	}
+}
+
+// CreateAttendeeRequest represents the HTTP request body for adding an
+// attendee to an event
+type CreateAttendeeRequest struct {
+	Name   string `json:"name"`
+	Email  string `json:"email" binding:"required"`
+	UserID *int   `json:"user_id"`
+	Role   string `json:"role"`
+	Status string `json:"status"`
+}
+
+// UpdateAttendeeRequest represents the HTTP request body for updating an
+// attendee or recording their RSVP
+type UpdateAttendeeRequest struct {
+	Name   *string `json:"name"`
+	UserID *int    `json:"user_id"`
+	Role   *string `json:"role"`
+	Status *string `json:"status"`
+}
+
+// GetAttendees handles GET /api/events/:id/attendees
+func (ah *AttendeeHandler) GetAttendees(c *gin.Context) {
+	eventID, err := ah.parseID(c, "id")
+	if err != nil {
+		ah.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid event ID", nil)
+		return
+	}
+
+	attendees, err := ah.attendeeService.GetEventAttendees(c.Request.Context(), eventID)
+	if err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	if attendees == nil {
+		attendees = []*models.Attendee{}
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"attendees": attendees,
+		"total":     len(attendees),
+	})
+}
+
+// AddAttendee handles POST /api/events/:id/attendees
+func (ah *AttendeeHandler) AddAttendee(c *gin.Context) {
+	eventID, err := ah.parseID(c, "id")
+	if err != nil {
+		ah.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid event ID", nil)
+		return
+	}
+
+	var req CreateAttendeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ah.handleValidationError(c, err)
+		return
+	}
+
+	attendee, err := ah.attendeeService.AddAttendee(c.Request.Context(), eventID, services.CreateAttendeeRequest{
+		Name:   req.Name,
+		Email:  req.Email,
+		UserID: req.UserID,
+		Role:   req.Role,
+		Status: req.Status,
+	})
+	if err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attendee)
+}
+
+// UpdateAttendee handles PUT /api/events/:id/attendees/:attendee_id
+func (ah *AttendeeHandler) UpdateAttendee(c *gin.Context) {
+	eventID, attendeeID, ok := ah.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateAttendeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ah.handleValidationError(c, err)
+		return
+	}
+
+	attendee, err := ah.attendeeService.UpdateAttendee(c.Request.Context(), eventID, attendeeID, services.UpdateAttendeeRequest{
+		Name:   req.Name,
+		UserID: req.UserID,
+		Role:   req.Role,
+		Status: req.Status,
+	})
+	if err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attendee)
+}
+
+// RemoveAttendee handles DELETE /api/events/:id/attendees/:attendee_id
+func (ah *AttendeeHandler) RemoveAttendee(c *gin.Context) {
+	eventID, attendeeID, ok := ah.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := ah.attendeeService.RemoveAttendee(c.Request.Context(), eventID, attendeeID); err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseIDs extracts the event and attendee IDs from the URL parameters. It
+// writes the error response and returns false when one is invalid.
+func (ah *AttendeeHandler) parseIDs(c *gin.Context) (int, int, bool) {
+	eventID, err := ah.parseID(c, "id")
+	if err != nil {
+		ah.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid event ID", nil)
+		return 0, 0, false
+	}
+
+	attendeeID, err := ah.parseID(c, "attendee_id")
+	if err != nil {
+		ah.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid attendee ID", nil)
+		return 0, 0, false
+	}
+
+	return eventID, attendeeID, true
+}
+
+// parseID extracts and validates an ID from a URL parameter
+func (ah *AttendeeHandler) parseID(c *gin.Context, param string) (int, error) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, errors.New("ID must be positive")
+	}
+	return id, nil
+}
+
+// handleValidationError handles validation errors from request binding
+func (ah *AttendeeHandler) handleValidationError(c *gin.Context, err error) {
+	ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+		"validation_error": err.Error(),
+	})
+}
+
+// handleServiceError handles errors from the service layer
+func (ah *AttendeeHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrEventNotFound:
+		ah.handleError(c, http.StatusNotFound, "EVENT_NOT_FOUND", "Event not found", nil)
+	case services.ErrAttendeeNotFound:
+		ah.handleError(c, http.StatusNotFound, "ATTENDEE_NOT_FOUND", "Attendee not found", nil)
+	case services.ErrInvalidAttendeeEmail:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid email address", map[string]any{
+			"email": "Email must be a valid address such as ada@example.com",
+		})
+	case services.ErrAttendeeNameTooLong:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Attendee name too long", map[string]any{
+			"name": "Name cannot exceed 255 characters",
+		})
+	case services.ErrInvalidAttendeeRole:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid role", map[string]any{
+			"role": "Role must be CHAIR, REQ-PARTICIPANT, OPT-PARTICIPANT or NON-PARTICIPANT",
+		})
+	case services.ErrInvalidRSVPStatus:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid RSVP status", map[string]any{
+			"status": "Status must be NEEDS-ACTION, ACCEPTED, DECLINED or TENTATIVE",
+		})
+	case services.ErrAttendeeUserNotFound:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "User not found", map[string]any{
+			"user_id": "User ID must refer to an existing user",
+		})
+	case services.ErrTooManyAttendees:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Too many attendees", map[string]any{
+			"attendees": "An event cannot have more than 100 attendees",
+		})
+	case services.ErrDuplicateAttendee:
+		ah.handleError(c, http.StatusConflict, "DUPLICATE_ATTENDEE", "Attendee already invited", map[string]any{
+			"email": "The event already has an attendee with this email",
+		})
+	default:
+		ah.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (ah *AttendeeHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
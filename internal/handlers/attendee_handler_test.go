@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAttendeeTestRouter serves the event and attendee routes on an
+// in-memory database with the full schema
+func setupAttendeeTestRouter(t *testing.T) *gin.Engine {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	eventRepo := database.NewEventRepository(db)
+	eventHandler := NewEventHandler(services.NewEventService(eventRepo, database.NewCalendarRepository(db), database.NewTransactionManager(db)))
+	attendeeHandler := NewAttendeeHandler(services.NewAttendeeService(database.NewAttendeeRepository(db), eventRepo,
+		database.NewUserRepository(db), database.NewTransactionManager(db)))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.POST("/events", eventHandler.CreateEvent)
+	api.GET("/events/conflicts", eventHandler.GetConflicts)
+	api.GET("/events/:id", eventHandler.GetEvent)
+	api.GET("/events/:id/attendees", attendeeHandler.GetAttendees)
+	api.POST("/events/:id/attendees", attendeeHandler.AddAttendee)
+	api.PUT("/events/:id/attendees/:attendee_id", attendeeHandler.UpdateAttendee)
+	api.DELETE("/events/:id/attendees/:attendee_id", attendeeHandler.RemoveAttendee)
+
+	return router
+}
+
+func TestAttendeeEndpoints(t *testing.T) {
+	router := setupAttendeeTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	createEvent := func(title, start, end string) models.Event {
+		w := send(http.MethodPost, "/api/events", fmt.Sprintf(`{"title": %q, "start_time": %q, "end_time": %q}`, title, start, end))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var event models.Event
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &event))
+		return event
+	}
+	addAttendee := func(eventID int, body string) *httptest.ResponseRecorder {
+		return send(http.MethodPost, fmt.Sprintf("/api/events/%d/attendees", eventID), body)
+	}
+
+	review := createEvent("Design review", "2030-05-01T10:00:00Z", "2030-05-01T11:00:00Z")
+	planning := createEvent("Sprint planning", "2030-05-01T11:00:00Z", "2030-05-01T12:00:00Z")
+	assert.Equal(t, []models.Attendee{}, review.Attendees)
+
+	var ada models.Attendee
+
+	t.Run("add attendees", func(t *testing.T) {
+		w := addAttendee(review.ID, `{"name": "Ada", "email": "ada@example.com", "role": "CHAIR"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ada))
+		assert.Equal(t, models.AttendeeChair, ada.Role)
+		assert.Equal(t, models.RSVPNeedsAction, ada.Status)
+
+		w = addAttendee(planning.ID, `{"name": "Grace", "email": "grace@example.com"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		w = addAttendee(review.ID, `{"email": "ADA@example.com"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "DUPLICATE_ATTENDEE")
+
+		w = addAttendee(review.ID, `{"name": "Nobody"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = addAttendee(999, `{"email": "alan@example.com"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("events list their attendees", func(t *testing.T) {
+		w := send(http.MethodGet, fmt.Sprintf("/api/events/%d", review.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var event models.Event
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &event))
+		require.Len(t, event.Attendees, 1)
+		assert.Equal(t, "ada@example.com", event.Attendees[0].Email)
+		assert.Equal(t, review.Version+1, event.Version, "changing the attendees changes the ETag")
+		assert.Equal(t, fmt.Sprintf(`"%d"`, event.Version), w.Header().Get("ETag"))
+
+		w = send(http.MethodGet, fmt.Sprintf("/api/events/%d/attendees", review.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total":1`)
+	})
+
+	t.Run("record an RSVP", func(t *testing.T) {
+		path := fmt.Sprintf("/api/events/%d/attendees/%d", review.ID, ada.ID)
+		w := send(http.MethodPut, path, `{"status": "declined"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var updated models.Attendee
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		assert.Equal(t, models.RSVPDeclined, updated.Status)
+		assert.Equal(t, models.AttendeeChair, updated.Role)
+
+		w = send(http.MethodPut, path, `{"status": "MAYBE"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = send(http.MethodPut, fmt.Sprintf("/api/events/%d/attendees/%d", planning.ID, ada.ID), `{"status": "ACCEPTED"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code, "attendees are addressed through their event")
+	})
+
+	t.Run("conflicts of an attendee", func(t *testing.T) {
+		conflicts := func(attendees ...string) []models.Event {
+			query := url.Values{"start_time": {"2030-05-01T10:30:00Z"}, "end_time": {"2030-05-01T11:30:00Z"}, "attendee": attendees}
+			w := send(http.MethodGet, "/api/events/conflicts?"+query.Encode(), "")
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var response struct {
+				Conflicts []models.Event `json:"conflicts"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			return response.Conflicts
+		}
+
+		assert.Len(t, conflicts(), 2)
+		assert.Empty(t, conflicts("ada@example.com"), "declined events do not conflict")
+
+		w := send(http.MethodPut, fmt.Sprintf("/api/events/%d/attendees/%d", review.ID, ada.ID), `{"status": "TENTATIVE"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		found := conflicts("ada@example.com")
+		require.Len(t, found, 1)
+		assert.Equal(t, review.ID, found[0].ID)
+
+		assert.Len(t, conflicts("ada@example.com", "grace@example.com"), 2)
+
+		w = send(http.MethodGet, "/api/events/conflicts?start_time=tomorrow", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		path := fmt.Sprintf("/api/events/%d/attendees/%d", review.ID, ada.ID)
+		w := send(http.MethodDelete, path, "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = send(http.MethodDelete, path, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
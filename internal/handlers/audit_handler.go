@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"agenda/internal/api"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	auditService services.AuditServiceInterface
+}
+
+// NewAuditHandler creates a new audit handler instance
+func NewAuditHandler(auditService services.AuditServiceInterface) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// AuditQuery represents query parameters for listing audit log entries.
+// The entity filters only apply to the global feed.
+type AuditQuery struct {
+	EntityType string `form:"entity_type"`
+	EntityID   int    `form:"entity_id"`
+	Action     string `form:"action"`
+	ActorID    *int   `form:"actor_id"`
+	Since      string `form:"since"`
+	Until      string `form:"until"`
+	Before     int    `form:"before"`
+	Limit      int    `form:"limit"`
+}
+
+// ListAuditEntries handles GET /api/audit
+func (ah *AuditHandler) ListAuditEntries(c *gin.Context) {
+	filters, ok := ah.parseFilters(c)
+	if !ok {
+		return
+	}
+
+	entries, err := ah.auditService.ListAuditEntries(c.Request.Context(), filters)
+	if err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	ah.respondEntries(c, entries)
+}
+
+// GetTaskHistory handles GET /api/tasks/:id/history
+func (ah *AuditHandler) GetTaskHistory(c *gin.Context) {
+	id, err := ah.parseID(c)
+	if err != nil {
+		ah.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
+		return
+	}
+	filters, ok := ah.parseFilters(c)
+	if !ok {
+		return
+	}
+
+	entries, err := ah.auditService.GetTaskHistory(c.Request.Context(), id, filters)
+	if err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	ah.respondEntries(c, entries)
+}
+
+// GetEventHistory handles GET /api/events/:id/history
+func (ah *AuditHandler) GetEventHistory(c *gin.Context) {
+	id, err := ah.parseID(c)
+	if err != nil {
+		ah.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid event ID", nil)
+		return
+	}
+	filters, ok := ah.parseFilters(c)
+	if !ok {
+		return
+	}
+
+	entries, err := ah.auditService.GetEventHistory(c.Request.Context(), id, filters)
+	if err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	ah.respondEntries(c, entries)
+}
+
+// parseFilters binds the audit query parameters, writing the error
+// response when they are invalid
+func (ah *AuditHandler) parseFilters(c *gin.Context) (services.AuditListFilters, bool) {
+	var query AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+			"validation_error": err.Error(),
+		})
+		return services.AuditListFilters{}, false
+	}
+	if query.Limit < 0 {
+		ah.handleError(c, http.StatusBadRequest, "INVALID_LIMIT", "Limit must be a positive number", nil)
+		return services.AuditListFilters{}, false
+	}
+
+	filters := services.AuditListFilters{
+		EntityType: query.EntityType,
+		EntityID:   query.EntityID,
+		Action:     query.Action,
+		ActorID:    query.ActorID,
+		Before:     query.Before,
+		Limit:      query.Limit,
+	}
+	for _, param := range []struct {
+		name  string
+		value string
+		dest  **time.Time
+	}{
+		{"since", query.Since, &filters.Since},
+		{"until", query.Until, &filters.Until},
+	} {
+		if param.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, param.value)
+		if err != nil {
+			ah.handleError(c, http.StatusBadRequest, "INVALID_DATE", "Invalid "+param.name+" date format", map[string]any{
+				param.name: "Date must be in RFC3339 format (e.g., 2023-01-01T00:00:00Z)",
+			})
+			return services.AuditListFilters{}, false
+		}
+		*param.dest = &t
+	}
+
+	return filters, true
+}
+
+// respondEntries writes a page of audit log entries. Clients fetch the
+// older entries by passing the ID of the last entry as before.
+func (ah *AuditHandler) respondEntries(c *gin.Context, entries []*models.AuditEntry) {
+	if entries == nil {
+		entries = []*models.AuditEntry{}
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"entries": entries,
+		"total":   len(entries),
+	})
+}
+
+// parseID extracts and validates the ID from the URL parameter
+func (ah *AuditHandler) parseID(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return id, nil
+}
+
+// handleServiceError handles errors from the service layer
+func (ah *AuditHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrTaskNotFound:
+		ah.handleError(c, http.StatusNotFound, "TASK_NOT_FOUND", "Task not found", nil)
+	case services.ErrEventNotFound:
+		ah.handleError(c, http.StatusNotFound, "EVENT_NOT_FOUND", "Event not found", nil)
+	case services.ErrInvalidAuditEntityType:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid entity type", map[string]any{
+			"entity_type": "Entity type must be task or event",
+		})
+	case services.ErrInvalidAuditAction:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid action", map[string]any{
+			"action": "Action must be created, updated, completed, reopened, deleted or restored",
+		})
+	case services.ErrInvalidAuditRange:
+		ah.handleError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", "Invalid date range", map[string]any{
+			"since": "Since must be before until",
+		})
+	default:
+		ah.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (ah *AuditHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAuditTestRouter serves the task, event and audit routes on an
+// in-memory database with the full schema, recording changes in the audit
+// log
+func setupAuditTestRouter(t *testing.T) *gin.Engine {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	taskRepo := database.NewTaskRepository(db)
+	eventRepo := database.NewEventRepository(db)
+	txManager := database.NewTransactionManager(db)
+	auditService := services.NewAuditService(database.NewAuditRepository(db), taskRepo, eventRepo)
+	taskHandler := NewTaskHandler(services.NewTaskService(taskRepo, txManager, auditService))
+	eventHandler := NewEventHandler(services.NewEventService(eventRepo, database.NewCalendarRepository(db), txManager, auditService))
+	auditHandler := NewAuditHandler(auditService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.POST("/tasks", taskHandler.CreateTask)
+	api.PUT("/tasks/:id", taskHandler.UpdateTask)
+	api.DELETE("/tasks/:id", taskHandler.DeleteTask)
+	api.GET("/tasks/:id/history", auditHandler.GetTaskHistory)
+	api.POST("/events", eventHandler.CreateEvent)
+	api.GET("/events/:id/history", auditHandler.GetEventHistory)
+	api.GET("/audit", auditHandler.ListAuditEntries)
+
+	return router
+}
+
+func TestAuditEndpoints(t *testing.T) {
+	router := setupAuditTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	created := func(w *httptest.ResponseRecorder) int {
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var item struct {
+			ID int `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &item))
+		return item.ID
+	}
+	type auditResponse struct {
+		Entries []models.AuditEntry `json:"entries"`
+		Total   int                 `json:"total"`
+	}
+	list := func(path string) auditResponse {
+		w := send(http.MethodGet, path, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response auditResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	taskID := created(send(http.MethodPost, "/api/tasks", `{"title": "Book venue"}`))
+	w := send(http.MethodPut, fmt.Sprintf("/api/tasks/%d", taskID), `{"title": "Book the venue", "priority": "high"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, http.StatusNoContent, send(http.MethodDelete, fmt.Sprintf("/api/tasks/%d", taskID), "").Code)
+	eventID := created(send(http.MethodPost, "/api/events",
+		`{"title": "Venue visit", "start_time": "2030-05-01T10:00:00Z", "end_time": "2030-05-01T11:00:00Z"}`))
+
+	t.Run("task history survives the deletion", func(t *testing.T) {
+		response := list(fmt.Sprintf("/api/tasks/%d/history", taskID))
+		require.Equal(t, 3, response.Total)
+
+		update := response.Entries[1]
+		assert.Equal(t, "updated", update.Action)
+		assert.Equal(t, models.FieldChanges{
+			"title":    {Before: json.RawMessage(`"Book venue"`), After: json.RawMessage(`"Book the venue"`)},
+			"priority": {Before: json.RawMessage(`"medium"`), After: json.RawMessage(`"high"`)},
+		}, update.Changes)
+		assert.Equal(t, "deleted", response.Entries[0].Action)
+		assert.Equal(t, "created", response.Entries[2].Action)
+	})
+
+	t.Run("event history", func(t *testing.T) {
+		response := list(fmt.Sprintf("/api/events/%d/history", eventID))
+		require.Equal(t, 1, response.Total)
+		assert.Equal(t, models.AuditEntityEvent, response.Entries[0].EntityType)
+		assert.Equal(t, `"Venue visit"`, string(response.Entries[0].Changes["title"].After))
+	})
+
+	t.Run("feed filters", func(t *testing.T) {
+		assert.Equal(t, 4, list("/api/audit").Total)
+		assert.Equal(t, 2, list("/api/audit?action=created").Total)
+		assert.Equal(t, 1, list("/api/audit?entity_type=event").Total)
+
+		page := list("/api/audit?limit=2")
+		require.Equal(t, 2, page.Total)
+		rest := list(fmt.Sprintf("/api/audit?before=%d", page.Entries[1].ID))
+		assert.Equal(t, 2, rest.Total)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			path   string
+			status int
+			code   string
+		}{
+			{"/api/tasks/999/history", http.StatusNotFound, "TASK_NOT_FOUND"},
+			{"/api/events/999/history", http.StatusNotFound, "EVENT_NOT_FOUND"},
+			{"/api/tasks/abc/history", http.StatusBadRequest, "INVALID_ID"},
+			{"/api/audit?entity_type=note", http.StatusBadRequest, "VALIDATION_ERROR"},
+			{"/api/audit?action=exploded", http.StatusBadRequest, "VALIDATION_ERROR"},
+			{"/api/audit?since=yesterday", http.StatusBadRequest, "INVALID_DATE"},
+			{"/api/audit?limit=-1", http.StatusBadRequest, "INVALID_LIMIT"},
+		}
+		for _, tt := range tests {
+			w := send(http.MethodGet, tt.path, "")
+			assert.Equal(t, tt.status, w.Code, tt.path)
+			assert.Contains(t, w.Body.String(), tt.code, tt.path)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+
+	"agenda/internal/api"
+	"agenda/internal/auth"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler handles HTTP requests for user accounts and login sessions
+type AuthHandler struct {
+	authService services.AuthServiceInterface
+}
+
+// NewAuthHandler creates a new auth handler instance
+func NewAuthHandler(authService services.AuthServiceInterface) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// RegisterRequest represents the HTTP request body for creating an account
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Name     string `json:"name"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginRequest represents the HTTP request body for logging in
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Register handles POST /api/auth/register
+func (ah *AuthHandler) Register(c *gin.Context) {
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+			"validation_error": err.Error(),
+		})
+		return
+	}
+
+	user, err := ah.authService.Register(c.Request.Context(), services.RegisterRequest{
+		Email:    req.Email,
+		Name:     req.Name,
+		Password: req.Password,
+	})
+	if err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// Login handles POST /api/auth/login
+func (ah *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+			"validation_error": err.Error(),
+		})
+		return
+	}
+
+	result, err := ah.authService.Login(c.Request.Context(), services.LoginRequest{
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Logout handles POST /api/auth/logout
+func (ah *AuthHandler) Logout(c *gin.Context) {
+	token := auth.BearerToken(c.GetHeader("Authorization"))
+	if err := ah.authService.Logout(c.Request.Context(), token); err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Me handles GET /api/auth/me
+func (ah *AuthHandler) Me(c *gin.Context) {
+	user, err := ah.authService.CurrentUser(c.Request.Context())
+	if err != nil {
+		ah.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// handleServiceError handles errors from the service layer
+func (ah *AuthHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrInvalidEmail:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid email address", map[string]any{
+			"email": "A valid email address is required",
+		})
+	case services.ErrPasswordTooShort:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Password too short", map[string]any{
+			"password": err.Error(),
+		})
+	case services.ErrPasswordTooLong:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Password too long", map[string]any{
+			"password": err.Error(),
+		})
+	case services.ErrUserNameTooLong:
+		ah.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "User name too long", map[string]any{
+			"name": "Name cannot exceed 255 characters",
+		})
+	case services.ErrEmailTaken:
+		ah.handleError(c, http.StatusConflict, "EMAIL_TAKEN", "Email is already registered", nil)
+	case services.ErrInvalidCredentials:
+		ah.handleError(c, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid email or password", nil)
+	case services.ErrInvalidSession:
+		c.Header("WWW-Authenticate", `Bearer realm="agenda"`)
+		ah.handleError(c, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid or expired session", nil)
+	default:
+		ah.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (ah *AuthHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
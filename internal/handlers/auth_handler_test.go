@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/middleware"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuthTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	schema := `
+	CREATE TABLE users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		email TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL DEFAULT '',
+		password_hash TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE sessions (
+		token_hash TEXT PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		expires_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+
+	return db
+}
+
+func setupAuthTestRouter(t *testing.T) (*gin.Engine, *sql.DB) {
+	db := setupAuthTestDB(t)
+	authService := services.NewAuthService(database.NewUserRepository(db))
+	handler := NewAuthHandler(authService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	authRoutes := router.Group("/api/auth")
+	{
+		authRoutes.POST("/register", handler.Register)
+		authRoutes.POST("/login", handler.Login)
+		authRoutes.POST("/logout", middleware.Auth(authService), handler.Logout)
+		authRoutes.GET("/me", middleware.Auth(authService), handler.Me)
+	}
+
+	return router, db
+}
+
+func postJSON(router *gin.Engine, path string, body any) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRegister(t *testing.T) {
+	router, db := setupAuthTestRouter(t)
+	defer db.Close()
+
+	tests := []struct {
+		name           string
+		body           map[string]any
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "valid registration",
+			body:           map[string]any{"email": "alice@example.com", "name": "Alice", "password": "correct horse"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "duplicate email",
+			body:           map[string]any{"email": "alice@example.com", "password": "correct horse"},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "EMAIL_TAKEN",
+		},
+		{
+			name:           "invalid email",
+			body:           map[string]any{"email": "alice", "password": "correct horse"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+		{
+			name:           "short password",
+			body:           map[string]any{"email": "bob@example.com", "password": "short"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+		{
+			name:           "long password",
+			body:           map[string]any{"email": "bob@example.com", "password": strings.Repeat("p", 73)},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+		{
+			name:           "missing password",
+			body:           map[string]any{"email": "bob@example.com"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSON(router, "/api/auth/register", tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				var errorResp ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+				assert.Equal(t, tt.expectedError, errorResp.Error.Code)
+				return
+			}
+
+			assert.NotContains(t, w.Body.String(), "password")
+		})
+	}
+}
+
+func TestLoginLogout(t *testing.T) {
+	router, db := setupAuthTestRouter(t)
+	defer db.Close()
+
+	credentials := map[string]any{"email": "alice@example.com", "password": "correct horse"}
+	require.Equal(t, http.StatusCreated, postJSON(router, "/api/auth/register", credentials).Code)
+
+	w := postJSON(router, "/api/auth/login", map[string]any{"email": "alice@example.com", "password": "wrong password"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	var errorResp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+	assert.Equal(t, "INVALID_CREDENTIALS", errorResp.Error.Code)
+
+	w = postJSON(router, "/api/auth/login", credentials)
+	require.Equal(t, http.StatusOK, w.Code)
+	var result services.LoginResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	require.NotEmpty(t, result.Token)
+	assert.Equal(t, "alice@example.com", result.User.Email)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer "+result.Token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "alice@example.com")
+
+	req = httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+result.Token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer "+result.Token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
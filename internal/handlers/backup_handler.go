@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"agenda/internal/api"
+	"agenda/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BackupHandler handles the admin requests backing up the database
+type BackupHandler struct {
+	backups *database.BackupManager
+}
+
+// NewBackupHandler creates a new backup handler instance. backups is nil
+// for databases without online backups.
+func NewBackupHandler(backups *database.BackupManager) *BackupHandler {
+	return &BackupHandler{
+		backups: backups,
+	}
+}
+
+// CreateBackup handles POST /api/admin/backups
+func (bh *BackupHandler) CreateBackup(c *gin.Context) {
+	if bh.backups == nil {
+		bh.handleUnsupported(c)
+		return
+	}
+
+	// The snapshot is returned even when rotating the older ones failed
+	snapshot, err := bh.backups.Backup(c.Request.Context())
+	if err != nil && snapshot == nil {
+		bh.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Backup failed", nil)
+		return
+	}
+
+	c.JSON(http.StatusCreated, snapshot)
+}
+
+// ListBackups handles GET /api/admin/backups
+func (bh *BackupHandler) ListBackups(c *gin.Context) {
+	if bh.backups == nil {
+		bh.handleUnsupported(c)
+		return
+	}
+
+	snapshots, err := bh.backups.Snapshots()
+	if err != nil {
+		bh.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"backups": snapshots,
+		"total":   len(snapshots),
+	})
+}
+
+// handleUnsupported rejects backup requests on databases without online
+// backups
+func (bh *BackupHandler) handleUnsupported(c *gin.Context) {
+	bh.handleError(c, http.StatusNotImplemented, "NOT_IMPLEMENTED", "Online backups are only supported for SQLite databases", nil)
+}
+
+// handleError creates a standardized error response
+func (bh *BackupHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
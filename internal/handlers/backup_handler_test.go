@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agenda/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupBackupTestRouter serves the backup routes of backups, nil for
+// databases without online backups
+func setupBackupTestRouter(backups *database.BackupManager) *gin.Engine {
+	backupHandler := NewBackupHandler(backups)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.GET("/admin/backups", backupHandler.ListBackups)
+	api.POST("/admin/backups", backupHandler.CreateBackup)
+
+	return router
+}
+
+func TestBackupEndpoints(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	backups, err := database.NewBackupManager(db, database.BackupConfig{Dir: t.TempDir()})
+	require.NoError(t, err)
+	router := setupBackupTestRouter(backups)
+
+	send := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/admin/backups", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(http.MethodPost)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var snapshot database.Snapshot
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &snapshot))
+	assert.NotEmpty(t, snapshot.Name)
+	assert.Positive(t, snapshot.Size)
+
+	w = send(http.MethodGet)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Backups []database.Snapshot `json:"backups"`
+		Total   int                 `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, 1, list.Total)
+	assert.Equal(t, snapshot.Name, list.Backups[0].Name)
+}
+
+func TestBackupEndpoints_Unsupported(t *testing.T) {
+	router := setupBackupTestRouter(nil)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req := httptest.NewRequest(method, "/api/admin/backups", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+		assert.Contains(t, w.Body.String(), "NOT_IMPLEMENTED")
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"agenda/internal/api"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// BulkOperationRequest is an operation of a bulk request. Data holds the
+// body of the matching single-item request for creations and updates.
+type BulkOperationRequest struct {
+	Op   string          `json:"op"`
+	ID   int             `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+// BulkItemResult is the outcome of an operation of a bulk request. Data is
+// the task or event after the operation, and Error follows the format of
+// the error responses of the single-item routes.
+type BulkItemResult struct {
+	Index  int              `json:"index"`
+	Op     string           `json:"op"`
+	ID     int              `json:"id,omitempty"`
+	Status int              `json:"status"`
+	Data   interface{}      `json:"data,omitempty"`
+	Error  *api.ErrorDetail `json:"error,omitempty"`
+}
+
+// BulkResponse is the response to a bulk request
+type BulkResponse struct {
+	Mode      string           `json:"mode"`
+	Results   []BulkItemResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	UndoToken string           `json:"undo_token,omitempty"`
+}
+
+// hasBulkData reports whether a bulk operation carries data
+func hasBulkData(data json.RawMessage) bool {
+	return len(data) > 0 && string(data) != "null"
+}
+
+// decodeBulkData decodes the data of a bulk operation into the request
+// body of the matching single-item route, and validates it the same way
+func decodeBulkData(data json.RawMessage, obj interface{}) error {
+	if err := json.Unmarshal(data, obj); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// writeBulkResponse writes the results of a bulk request. The response is
+// 200 OK when every operation was applied, and 207 Multi-Status when some
+// operations of a best effort request failed. Atomic requests that failed
+// get the status of the failed operation.
+func writeBulkResponse(c *gin.Context, mode string, results []*services.BulkResult, undoToken func() string,
+	serviceError func(error) (int, api.ErrorDetail)) {
+	if mode == "" {
+		mode = services.BulkAtomic
+	}
+	response := BulkResponse{Mode: mode, Results: make([]BulkItemResult, 0, len(results))}
+	status := http.StatusOK
+
+	for _, result := range results {
+		item := BulkItemResult{Index: result.Index, Op: result.Op, ID: result.ID, Data: result.Item}
+		switch {
+		case result.Err != nil:
+			itemStatus, detail := bulkServiceError(result.Err, serviceError)
+			item.Status, item.Error = itemStatus, &detail
+			response.Failed++
+			if result.Err != services.ErrBulkAborted {
+				status = itemStatus
+				if mode == services.BulkBestEffort {
+					status = http.StatusMultiStatus
+				}
+			}
+		case result.Op == services.BulkCreate:
+			item.Status = http.StatusCreated
+			response.Succeeded++
+		case result.Op == services.BulkDelete:
+			item.Status = http.StatusNoContent
+			response.Succeeded++
+		default:
+			item.Status = http.StatusOK
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, item)
+	}
+
+	if response.Succeeded > 0 {
+		response.UndoToken = setUndoToken(c, undoToken)
+	}
+
+	c.JSON(status, response)
+}
+
+// bulkServiceError maps the errors of bulk requests to the status and error
+// detail of their response, falling back to serviceError for the errors of
+// the operations themselves
+func bulkServiceError(err error, serviceError func(error) (int, api.ErrorDetail)) (int, api.ErrorDetail) {
+	switch err {
+	case services.ErrBulkAborted:
+		return http.StatusFailedDependency, api.ErrorDetail{Code: "BULK_ABORTED", Message: "Operation was not applied because another operation failed"}
+	case services.ErrInvalidBulkMode:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid bulk mode", Details: map[string]any{
+			"mode": "Mode must be 'atomic' or 'best_effort'",
+		}}
+	case services.ErrInvalidBulkRequest:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid bulk request", Details: map[string]any{
+			"operations": "Send either a list of operations or a selection with its op",
+		}}
+	case services.ErrEmptyBulkSelection:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Empty selection", Details: map[string]any{
+			"select": "Selection needs at least one filter",
+		}}
+	case services.ErrBulkTooLarge:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Too many items", Details: map[string]any{
+			"operations": "Bulk requests cannot exceed 500 items",
+		}}
+	case services.ErrInvalidBulkOperation:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid operation", Details: map[string]any{
+			"op": "Operation is not supported for this resource",
+		}}
+	case services.ErrBulkIDRequired:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Item ID is required", Details: map[string]any{
+			"id": "Operation needs the ID of the item",
+		}}
+	case services.ErrBulkDataRequired:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Item data is required", Details: map[string]any{
+			"data": "Create and update operations need data",
+		}}
+	}
+	return serviceError(err)
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"agenda/internal/api"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarHandler handles HTTP requests for calendars
+type CalendarHandler struct {
+	calendarService services.CalendarServiceInterface
+}
+
+// NewCalendarHandler creates a new calendar handler instance
+func NewCalendarHandler(calendarService services.CalendarServiceInterface) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// CreateCalendarRequest represents the HTTP request body for creating a calendar
+type CreateCalendarRequest struct {
+	Name           string `json:"name" binding:"required"`
+	Description    string `json:"description"`
+	Kind           string `json:"kind"`
+	ConflictPolicy string `json:"conflict_policy"`
+}
+
+// UpdateCalendarRequest represents the HTTP request body for updating a calendar
+type UpdateCalendarRequest struct {
+	Name           *string `json:"name"`
+	Description    *string `json:"description"`
+	Kind           *string `json:"kind"`
+	ConflictPolicy *string `json:"conflict_policy"`
+}
+
+// CreateCalendar handles POST /api/calendars
+func (ch *CalendarHandler) CreateCalendar(c *gin.Context) {
+	var req CreateCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ch.handleValidationError(c, err)
+		return
+	}
+
+	calendar, err := ch.calendarService.CreateCalendar(c.Request.Context(), services.CreateCalendarRequest{
+		Name:           req.Name,
+		Description:    req.Description,
+		Kind:           req.Kind,
+		ConflictPolicy: req.ConflictPolicy,
+	})
+	if err != nil {
+		ch.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, calendar)
+}
+
+// ListCalendars handles GET /api/calendars
+func (ch *CalendarHandler) ListCalendars(c *gin.Context) {
+	calendars, err := ch.calendarService.ListCalendars(c.Request.Context())
+	if err != nil {
+		ch.handleServiceError(c, err)
+		return
+	}
+	if calendars == nil {
+		calendars = []*models.Calendar{}
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"calendars": calendars,
+		"total":     len(calendars),
+	})
+}
+
+// GetCalendar handles GET /api/calendars/:id
+func (ch *CalendarHandler) GetCalendar(c *gin.Context) {
+	id, err := ch.parseID(c)
+	if err != nil {
+		ch.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid calendar ID", nil)
+		return
+	}
+
+	calendar, err := ch.calendarService.GetCalendarByID(c.Request.Context(), id)
+	if err != nil {
+		ch.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// UpdateCalendar handles PUT /api/calendars/:id
+func (ch *CalendarHandler) UpdateCalendar(c *gin.Context) {
+	id, err := ch.parseID(c)
+	if err != nil {
+		ch.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid calendar ID", nil)
+		return
+	}
+
+	var req UpdateCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ch.handleValidationError(c, err)
+		return
+	}
+
+	calendar, err := ch.calendarService.UpdateCalendar(c.Request.Context(), id, services.UpdateCalendarRequest{
+		Name:           req.Name,
+		Description:    req.Description,
+		Kind:           req.Kind,
+		ConflictPolicy: req.ConflictPolicy,
+	})
+	if err != nil {
+		ch.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// DeleteCalendar handles DELETE /api/calendars/:id
+func (ch *CalendarHandler) DeleteCalendar(c *gin.Context) {
+	id, err := ch.parseID(c)
+	if err != nil {
+		ch.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid calendar ID", nil)
+		return
+	}
+
+	if err := ch.calendarService.DeleteCalendar(c.Request.Context(), id); err != nil {
+		ch.handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseID extracts and validates the calendar ID from the URL
+func (ch *CalendarHandler) parseID(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, errors.New("ID must be positive")
+	}
+	return id, nil
+}
+
+// handleValidationError handles request binding errors
+func (ch *CalendarHandler) handleValidationError(c *gin.Context, err error) {
+	ch.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+		"validation_error": err.Error(),
+	})
+}
+
+// handleServiceError handles errors from the service layer
+func (ch *CalendarHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrCalendarNotFound:
+		ch.handleError(c, http.StatusNotFound, "CALENDAR_NOT_FOUND", "Calendar not found", nil)
+	case services.ErrCalendarNotEmpty:
+		ch.handleError(c, http.StatusConflict, "CALENDAR_NOT_EMPTY", "Calendar still has events", map[string]any{
+			"events": "Move or delete the events of the calendar first",
+		})
+	case services.ErrCalendarNameRequired:
+		ch.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Calendar name is required", map[string]any{
+			"name": "Name is required",
+		})
+	case services.ErrCalendarNameTooLong:
+		ch.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Calendar name too long", map[string]any{
+			"name": "Name cannot exceed 100 characters",
+		})
+	case services.ErrCalendarDescriptionTooLong:
+		ch.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Calendar description too long", map[string]any{
+			"description": "Description cannot exceed 1000 characters",
+		})
+	case services.ErrInvalidCalendarKind:
+		ch.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid calendar kind", map[string]any{
+			"kind": "Kind must be 'personal', 'team' or 'room'",
+		})
+	case services.ErrInvalidConflictPolicy:
+		ch.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid conflict policy", map[string]any{
+			"conflict_policy": "Conflict policy must be 'reject', 'warn' or 'ignore'",
+		})
+	case services.ErrTooManyCalendars:
+		ch.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Too many calendars", map[string]any{
+			"calendars": "Cannot have more than 50 calendars",
+		})
+	default:
+		ch.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (ch *CalendarHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCalendarTestRouter serves the calendar and event routes on an
+// in-memory database with the full schema
+func setupCalendarTestRouter(t *testing.T) *gin.Engine {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	calendarRepo := database.NewCalendarRepository(db)
+	eventHandler := NewEventHandler(services.NewEventService(database.NewEventRepository(db), calendarRepo,
+		database.NewTransactionManager(db)))
+	calendarHandler := NewCalendarHandler(services.NewCalendarService(calendarRepo))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.GET("/calendars", calendarHandler.ListCalendars)
+	api.POST("/calendars", calendarHandler.CreateCalendar)
+	api.GET("/calendars/:id", calendarHandler.GetCalendar)
+	api.PUT("/calendars/:id", calendarHandler.UpdateCalendar)
+	api.DELETE("/calendars/:id", calendarHandler.DeleteCalendar)
+	api.GET("/events", eventHandler.ListEvents)
+	api.POST("/events", eventHandler.CreateEvent)
+	api.PATCH("/events/:id", eventHandler.PatchEvent)
+	api.DELETE("/events/:id", eventHandler.DeleteEvent)
+
+	return router
+}
+
+func TestCalendarEndpoints(t *testing.T) {
+	router := setupCalendarTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	createCalendar := func(body string) models.Calendar {
+		w := send(http.MethodPost, "/api/calendars", body)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var calendar models.Calendar
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &calendar))
+		return calendar
+	}
+	createEvent := func(title string, calendarID int) *httptest.ResponseRecorder {
+		return send(http.MethodPost, "/api/events", fmt.Sprintf(
+			`{"title": %q, "start_time": "2030-05-01T10:00:00Z", "end_time": "2030-05-01T11:00:00Z", "calendar_id": %d}`, title, calendarID))
+	}
+
+	room := createCalendar(`{"name": "Room 1", "kind": "room"}`)
+	team := createCalendar(`{"name": "Team", "kind": "team", "conflict_policy": "warn"}`)
+	assert.Equal(t, models.ConflictReject, room.ConflictPolicy)
+
+	t.Run("validation", func(t *testing.T) {
+		w := send(http.MethodPost, "/api/calendars", `{"kind": "room"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = send(http.MethodPost, "/api/calendars", `{"name": "Garage", "conflict_policy": "merge"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "conflict_policy")
+
+		w = send(http.MethodGet, "/api/calendars/999", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	var booking models.Event
+	t.Run("conflicts follow the policy of the calendar", func(t *testing.T) {
+		w := createEvent("Booking", room.ID)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &booking))
+		assert.Equal(t, room.ID, *booking.CalendarID)
+
+		w = createEvent("Double booking", room.ID)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = createEvent("Design review", team.ID)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.NotContains(t, w.Body.String(), `"conflicts"`, "other calendars do not conflict")
+
+		w = createEvent("Planning", team.ID)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var planning models.Event
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &planning))
+		require.Len(t, planning.Conflicts, 1)
+		assert.Equal(t, "Design review", planning.Conflicts[0].Title)
+
+		w = createEvent("Nowhere", 999)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "calendar_id")
+	})
+
+	t.Run("list the events of a calendar", func(t *testing.T) {
+		w := send(http.MethodGet, fmt.Sprintf("/api/events?calendar_id=%d", team.ID), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"total":2`)
+	})
+
+	t.Run("move an event to another calendar", func(t *testing.T) {
+		w := send(http.MethodPatch, fmt.Sprintf("/api/events/%d", booking.ID), fmt.Sprintf(`{"calendar_id": %d}`, team.ID))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var moved models.Event
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+		assert.Equal(t, team.ID, *moved.CalendarID)
+		assert.Len(t, moved.Conflicts, 2)
+
+		w = send(http.MethodPatch, fmt.Sprintf("/api/events/%d", booking.ID), `{"calendar_id": null}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.NotContains(t, w.Body.String(), `"calendar_id"`)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		w := send(http.MethodPut, fmt.Sprintf("/api/calendars/%d", team.ID), `{"conflict_policy": "ignore"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"conflict_policy":"ignore"`)
+
+		w = send(http.MethodDelete, fmt.Sprintf("/api/calendars/%d", team.ID), "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = send(http.MethodDelete, fmt.Sprintf("/api/calendars/%d", room.ID), "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = send(http.MethodGet, "/api/calendars", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total":1`)
+	})
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag returns the entity tag of a version of a task or event
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header lists
+// tag, or is "*". Weak comparison, used by If-None-Match, ignores the W/
+// prefix of weak tags; strong comparison, used by If-Match, never matches
+// them.
+func etagMatches(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionFailed reports whether the request has an If-Match header
+// that does not match the current version. The response then gets the
+// current ETag.
+func preconditionFailed(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || etagMatches(header, etag(version), false) {
+		return false
+	}
+	c.Header("ETag", etag(version))
+	return true
+}
+
+// notModified sets the ETag of a GET response and answers it with 304 Not
+// Modified when its If-None-Match header matches the current version. It
+// reports whether the response was written.
+func notModified(c *gin.Context, version int) bool {
+	tag := etag(version)
+	c.Header("ETag", tag)
+
+	if header := c.GetHeader("If-None-Match"); header != "" && etagMatches(header, tag, true) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
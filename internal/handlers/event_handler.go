@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
-	"math"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"agenda/internal/api"
+	"agenda/internal/models"
 	"agenda/internal/services"
+	"agenda/internal/timezone"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,18 +31,86 @@ func NewEventHandler(eventService services.EventServiceInterface) *EventHandler
 
 // CreateEventRequest represents the HTTP request body for creating an event
 type CreateEventRequest struct {
-	Title       string    `json:"title" binding:"required"`
-	Description string    `json:"description"`
-	StartTime   time.Time `json:"start_time" binding:"required"`
-	EndTime     time.Time `json:"end_time" binding:"required"`
+	Title          string      `json:"title" binding:"required"`
+	Description    string      `json:"description"`
+	StartTime      time.Time   `json:"start_time" binding:"required"`
+	EndTime        time.Time   `json:"end_time" binding:"required"`
+	TimeZone       string      `json:"time_zone"`
+	RecurrenceRule string      `json:"recurrence_rule"`
+	ExDates        []time.Time `json:"exdates"`
+	CalendarID     *int        `json:"calendar_id"`
 }
 
 // UpdateEventRequest represents the HTTP request body for updating an event
 type UpdateEventRequest struct {
-	Title       *string    `json:"title"`
-	Description *string    `json:"description"`
-	StartTime   *time.Time `json:"start_time"`
-	EndTime     *time.Time `json:"end_time"`
+	Title          *string    `json:"title"`
+	Description    *string    `json:"description"`
+	StartTime      *time.Time `json:"start_time"`
+	EndTime        *time.Time `json:"end_time"`
+	TimeZone       *string    `json:"time_zone"`
+	RecurrenceRule *string    `json:"recurrence_rule"`
+	CalendarID     *int       `json:"calendar_id"`
+}
+
+// serviceRequest converts the request to a service request
+func (req CreateEventRequest) serviceRequest() services.CreateEventRequest {
+	return services.CreateEventRequest{
+		Title:          req.Title,
+		Description:    req.Description,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		TimeZone:       req.TimeZone,
+		RecurrenceRule: req.RecurrenceRule,
+		ExDates:        req.ExDates,
+		CalendarID:     req.CalendarID,
+	}
+}
+
+// eventPatchFields maps the event members a patch may change to the value
+// that clears them, empty for members that cannot be cleared
+var eventPatchFields = map[string]string{
+	"title":           `""`,
+	"description":     `""`,
+	"start_time":      "",
+	"end_time":        "",
+	"time_zone":       `""`,
+	"recurrence_rule": `""`,
+	"calendar_id":     `0`,
+}
+
+// serviceRequest converts the request to a service request
+func (req UpdateEventRequest) serviceRequest() services.UpdateEventRequest {
+	return services.UpdateEventRequest{
+		Title:          req.Title,
+		Description:    req.Description,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		TimeZone:       req.TimeZone,
+		RecurrenceRule: req.RecurrenceRule,
+		CalendarID:     req.CalendarID,
+	}
+}
+
+// BulkEventRequest represents the HTTP request body of a bulk event
+// request. It holds either a list of operations, or a selection of events
+// Op applies to, with Data for updates.
+type BulkEventRequest struct {
+	Mode       string                 `json:"mode"` // "atomic" (default) or "best_effort"
+	Operations []BulkOperationRequest `json:"operations"`
+
+	Select *EventSelectionRequest `json:"select"`
+	Op     string                 `json:"op"`
+	Data   json.RawMessage        `json:"data"`
+}
+
+// EventSelectionRequest selects the events of a bulk event request
+type EventSelectionRequest struct {
+	StartAfter  *time.Time `json:"start_after"`
+	StartBefore *time.Time `json:"start_before"`
+	EndAfter    *time.Time `json:"end_after"`
+	EndBefore   *time.Time `json:"end_before"`
+	Search      string     `json:"search"`
+	CalendarID  *int       `json:"calendar_id"` // 0 selects the events without a calendar
 }
 
 // EventListQuery represents query parameters for listing events
@@ -49,11 +121,15 @@ type EventListQuery struct {
 	EndAfter    string `form:"end_after"`
 	EndBefore   string `form:"end_before"`
 	Search      string `form:"search"`
+	CalendarID  *int   `form:"calendar_id"` // 0 lists the events without a calendar
 	Year        int    `form:"year"`
 	Month       int    `form:"month"`
 	Day         string `form:"day"`
 	Page        int    `form:"page"`
 	PageSize    int    `form:"page_size"`
+	Cursor      string `form:"cursor"`
+	// IncludeTotal defaults to true; false skips counting the events
+	IncludeTotal *bool `form:"include_total"`
 }
 
 // CreateEvent handles POST /api/events
@@ -65,20 +141,102 @@ func (eh *EventHandler) CreateEvent(c *gin.Context) {
 	}
 
 	// Convert to service request
-	serviceReq := services.CreateEventRequest{
-		Title:       req.Title,
-		Description: req.Description,
-		StartTime:   req.StartTime,
-		EndTime:     req.EndTime,
-	}
+	serviceReq := req.serviceRequest()
 
-	event, err := eh.eventService.CreateEvent(c.Request.Context(), serviceReq)
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	event, err := eh.eventService.CreateEvent(ctx, serviceReq)
 	if err != nil {
 		eh.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, event)
+	c.JSON(http.StatusCreated, undoableEvent{event, setUndoToken(c, undoToken)})
+}
+
+// BulkEvents handles POST /api/events/bulk, applying a list of create,
+// update and delete operations, or one operation to every event matching a
+// selection. Atomic requests apply every operation or none of them; best
+// effort requests apply the operations that succeed.
+func (eh *EventHandler) BulkEvents(c *gin.Context) {
+	var req BulkEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		eh.handleValidationError(c, err)
+		return
+	}
+
+	serviceReq := services.BulkEventRequest{
+		Mode: strings.ToLower(req.Mode),
+		Op:   strings.ToLower(req.Op),
+	}
+	for i, op := range req.Operations {
+		operation := services.BulkEventOperation{Op: strings.ToLower(op.Op), ID: op.ID}
+		var err error
+		operation.Create, operation.Update, err = decodeEventBulkData(operation.Op, op.Data)
+		if err != nil {
+			eh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+				fmt.Sprintf("operations[%d].data", i): err.Error(),
+			})
+			return
+		}
+		serviceReq.Operations = append(serviceReq.Operations, operation)
+	}
+	if req.Select != nil {
+		serviceReq.Select = &services.EventSelection{
+			StartAfter:  req.Select.StartAfter,
+			StartBefore: req.Select.StartBefore,
+			EndAfter:    req.Select.EndAfter,
+			EndBefore:   req.Select.EndBefore,
+			Search:      req.Select.Search,
+			CalendarID:  req.Select.CalendarID,
+		}
+		if serviceReq.Op == services.BulkUpdate && hasBulkData(req.Data) {
+			var update UpdateEventRequest
+			if err := decodeBulkData(req.Data, &update); err != nil {
+				eh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+					"data": err.Error(),
+				})
+				return
+			}
+			updateReq := update.serviceRequest()
+			serviceReq.Update = &updateReq
+		}
+	}
+
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	results, err := eh.eventService.BulkEvents(ctx, serviceReq)
+	if err != nil {
+		status, detail := bulkServiceError(err, eventServiceError)
+		eh.handleError(c, status, detail.Code, detail.Message, detail.Details)
+		return
+	}
+
+	writeBulkResponse(c, serviceReq.Mode, results, undoToken, eventServiceError)
+}
+
+// decodeEventBulkData decodes the data of a bulk event operation into the
+// request matching its operation
+func decodeEventBulkData(op string, data json.RawMessage) (*services.CreateEventRequest, *services.UpdateEventRequest, error) {
+	if !hasBulkData(data) {
+		return nil, nil, nil
+	}
+
+	switch op {
+	case services.BulkCreate:
+		var req CreateEventRequest
+		if err := decodeBulkData(data, &req); err != nil {
+			return nil, nil, err
+		}
+		serviceReq := req.serviceRequest()
+		return &serviceReq, nil, nil
+	case services.BulkUpdate:
+		var req UpdateEventRequest
+		if err := decodeBulkData(data, &req); err != nil {
+			return nil, nil, err
+		}
+		serviceReq := req.serviceRequest()
+		return nil, &serviceReq, nil
+	}
+	return nil, nil, nil
 }
 
 // GetEvent handles GET /api/events/:id
@@ -94,11 +252,15 @@ func (eh *EventHandler) GetEvent(c *gin.Context) {
 		eh.handleServiceError(c, err)
 		return
 	}
+	if notModified(c, event.Version) {
+		return
+	}
 
 	c.JSON(http.StatusOK, event)
 }
 
 // UpdateEvent handles PUT /api/events/:id
+// Recurring events accept ?occurrence=<RFC3339>&scope=this|following|all
 func (eh *EventHandler) UpdateEvent(c *gin.Context) {
 	id, err := eh.parseEventID(c)
 	if err != nil {
@@ -106,6 +268,11 @@ func (eh *EventHandler) UpdateEvent(c *gin.Context) {
 		return
 	}
 
+	occurrence, scope, ok := eh.parseOccurrence(c)
+	if !ok {
+		return
+	}
+
 	var req UpdateEventRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		eh.handleValidationError(c, err)
@@ -113,23 +280,95 @@ func (eh *EventHandler) UpdateEvent(c *gin.Context) {
 	}
 
 	// Convert to service request
-	serviceReq := services.UpdateEventRequest{
-		Title:       req.Title,
-		Description: req.Description,
-		StartTime:   req.StartTime,
-		EndTime:     req.EndTime,
+	serviceReq := req.serviceRequest()
+
+	// With If-Match, the update only applies to the version it names
+	if c.GetHeader("If-Match") != "" {
+		current, err := eh.eventService.GetEventByID(c.Request.Context(), id)
+		if err != nil {
+			eh.handleServiceError(c, err)
+			return
+		}
+		if preconditionFailed(c, current.Version) {
+			eh.handleServiceError(c, services.ErrVersionMismatch)
+			return
+		}
+		serviceReq.Version = &current.Version
+	}
+
+	eh.updateEvent(c, id, occurrence, scope, serviceReq)
+}
+
+// PatchEvent handles PATCH /api/events/:id with a JSON merge patch or a
+// JSON Patch document. Members set to null, or removed, are cleared.
+// Recurring events accept ?occurrence=<RFC3339>&scope=this|following|all
+func (eh *EventHandler) PatchEvent(c *gin.Context) {
+	id, err := eh.parseEventID(c)
+	if err != nil {
+		eh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid event ID", nil)
+		return
+	}
+
+	occurrence, scope, ok := eh.parseOccurrence(c)
+	if !ok {
+		return
 	}
 
-	event, err := eh.eventService.UpdateEvent(c.Request.Context(), id, serviceReq)
+	current, err := eh.eventService.GetEventByID(c.Request.Context(), id)
 	if err != nil {
 		eh.handleServiceError(c, err)
 		return
 	}
+	if preconditionFailed(c, current.Version) {
+		eh.handleServiceError(c, services.ErrVersionMismatch)
+		return
+	}
 
-	c.JSON(http.StatusOK, event)
+	var req UpdateEventRequest
+	changes, err := patchChanges(c, current)
+	if err == nil {
+		err = decodeChanges(changes, eventPatchFields, &req)
+	}
+	if err != nil {
+		status, code, message, details := patchError(err)
+		eh.handleError(c, status, code, message, details)
+		return
+	}
+
+	// The patch was computed from the current version, so it must not be
+	// applied over a concurrent update
+	serviceReq := req.serviceRequest()
+	serviceReq.Version = &current.Version
+
+	eh.updateEvent(c, id, occurrence, scope, serviceReq)
+}
+
+// updateEvent applies an update to an event, or to occurrences of it when
+// an occurrence is given, and writes the response
+func (eh *EventHandler) updateEvent(c *gin.Context, id int, occurrence *time.Time, scope services.RecurrenceScope, req services.UpdateEventRequest) {
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+
+	var event *models.Event
+	var err error
+	if occurrence != nil {
+		event, err = eh.eventService.UpdateEventOccurrence(ctx, id, *occurrence, scope, req)
+	} else {
+		event, err = eh.eventService.UpdateEvent(ctx, id, req)
+	}
+	if err != nil {
+		eh.handleServiceError(c, err)
+		return
+	}
+
+	// Occurrence updates may return another event than the one addressed
+	if event.ID == id {
+		c.Header("ETag", etag(event.Version))
+	}
+	c.JSON(http.StatusOK, undoableEvent{event, setUndoToken(c, undoToken)})
 }
 
 // DeleteEvent handles DELETE /api/events/:id
+// Recurring events accept ?occurrence=<RFC3339>&scope=this|following|all
 func (eh *EventHandler) DeleteEvent(c *gin.Context) {
 	id, err := eh.parseEventID(c)
 	if err != nil {
@@ -137,15 +376,45 @@ func (eh *EventHandler) DeleteEvent(c *gin.Context) {
 		return
 	}
 
-	err = eh.eventService.DeleteEvent(c.Request.Context(), id)
+	occurrence, scope, ok := eh.parseOccurrence(c)
+	if !ok {
+		return
+	}
+
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	if occurrence != nil {
+		err = eh.eventService.DeleteEventOccurrence(ctx, id, *occurrence, scope)
+	} else {
+		err = eh.eventService.DeleteEvent(ctx, id)
+	}
 	if err != nil {
 		eh.handleServiceError(c, err)
 		return
 	}
 
+	// The undo token is only sent in the header, as the response has no body
+	setUndoToken(c, undoToken)
 	c.Status(http.StatusNoContent)
 }
 
+// RestoreEvent handles POST /api/events/:id/restore
+func (eh *EventHandler) RestoreEvent(c *gin.Context) {
+	id, err := eh.parseEventID(c)
+	if err != nil {
+		eh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid event ID", nil)
+		return
+	}
+
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	event, err := eh.eventService.RestoreEvent(ctx, id)
+	if err != nil {
+		eh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, undoableEvent{event, setUndoToken(c, undoToken)})
+}
+
 // ListEvents handles GET /api/events with various filtering options
 func (eh *EventHandler) ListEvents(c *gin.Context) {
 	var query EventListQuery
@@ -168,10 +437,13 @@ func (eh *EventHandler) ListEvents(c *gin.Context) {
 
 	// Parse date filters for general listing
 	filters := services.EventListFilters{
-		Title:    query.Title,
-		Search:   query.Search,
-		Page:     query.Page,
-		PageSize: query.PageSize,
+		Title:      query.Title,
+		Search:     query.Search,
+		CalendarID: query.CalendarID,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		Cursor:     query.Cursor,
+		SkipTotal:  query.IncludeTotal != nil && !*query.IncludeTotal,
 	}
 
 	if query.StartAfter != "" {
@@ -218,31 +490,13 @@ func (eh *EventHandler) ListEvents(c *gin.Context) {
 		filters.EndBefore = &endBefore
 	}
 
-	events, total, err := eh.eventService.ListEvents(c.Request.Context(), filters)
+	events, info, err := eh.eventService.ListEvents(c.Request.Context(), filters)
 	if err != nil {
 		eh.handleServiceError(c, err)
 		return
 	}
 
-	// Calculate pagination info
-	page := filters.Page
-	page = int(math.Max(1.0, float64(page)))
-	pageSize := filters.PageSize
-	if pageSize <= 0 {
-		pageSize = 20
-	}
-
-	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
-
-	response := PaginatedResponse{
-		Data:       events,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-	}
-
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, newPaginatedResponse(events, filters.Page, filters.PageSize, filters.Cursor, !filters.SkipTotal, info))
 }
 
 // getEventsByMonth handles calendar month view queries
@@ -261,9 +515,10 @@ func (eh *EventHandler) getEventsByMonth(c *gin.Context, year int, month time.Mo
 	})
 }
 
-// getEventsByDay handles single day queries
+// getEventsByDay handles single day queries. The day is a calendar day in
+// the request time zone.
 func (eh *EventHandler) getEventsByDay(c *gin.Context, dayStr string) {
-	day, err := time.Parse("2006-01-02", dayStr)
+	day, err := time.ParseInLocation("2006-01-02", dayStr, timezone.FromContext(c.Request.Context()))
 	if err != nil {
 		eh.handleError(c, http.StatusBadRequest, "INVALID_DATE", "Invalid day format", map[string]any{
 			"day": "Date must be in YYYY-MM-DD format (e.g., 2023-01-01)",
@@ -308,6 +563,58 @@ func (eh *EventHandler) GetUpcomingEvents(c *gin.Context) {
 	})
 }
 
+// GetConflicts handles GET /api/events/conflicts. It lists the events
+// overlapping ?start_time and ?end_time, ignoring the series
+// ?exclude_event_id. Repeated ?attendee emails limit the conflicts to the
+// events these attendees have not declined.
+func (eh *EventHandler) GetConflicts(c *gin.Context) {
+	startTime, err := time.Parse(time.RFC3339, c.Query("start_time"))
+	if err != nil {
+		eh.handleError(c, http.StatusBadRequest, "INVALID_DATE", "Invalid start_time date format", map[string]any{
+			"start_time": "Date must be in RFC3339 format (e.g., 2023-01-01T00:00:00Z)",
+		})
+		return
+	}
+
+	endTime, err := time.Parse(time.RFC3339, c.Query("end_time"))
+	if err != nil {
+		eh.handleError(c, http.StatusBadRequest, "INVALID_DATE", "Invalid end_time date format", map[string]any{
+			"end_time": "Date must be in RFC3339 format (e.g., 2023-01-01T00:00:00Z)",
+		})
+		return
+	}
+	if !endTime.After(startTime) {
+		eh.handleServiceError(c, services.ErrInvalidTimeRange)
+		return
+	}
+
+	var excludeEventID *int
+	if excludeStr := c.Query("exclude_event_id"); excludeStr != "" {
+		id, err := strconv.Atoi(excludeStr)
+		if err != nil || id <= 0 {
+			eh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid exclude_event_id", nil)
+			return
+		}
+		excludeEventID = &id
+	}
+
+	attendees := c.QueryArray("attendee")
+	conflicts, err := eh.eventService.CheckTimeConflicts(c.Request.Context(), startTime, endTime, excludeEventID, attendees...)
+	if err != nil {
+		eh.handleServiceError(c, err)
+		return
+	}
+
+	if conflicts == nil {
+		conflicts = []*models.Event{}
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"conflicts": conflicts,
+		"total":     len(conflicts),
+	})
+}
+
 // parseEventID extracts and validates the event ID from the URL parameter
 func (eh *EventHandler) parseEventID(c *gin.Context) (int, error) {
 	idStr := c.Param("id")
@@ -321,6 +628,40 @@ func (eh *EventHandler) parseEventID(c *gin.Context) (int, error) {
 	return id, nil
 }
 
+// parseOccurrence extracts the optional occurrence and scope query parameters
+// used to target occurrences of recurring events. The scope defaults to the
+// single occurrence. It writes the error response and returns false when the
+// parameters are invalid.
+func (eh *EventHandler) parseOccurrence(c *gin.Context) (*time.Time, services.RecurrenceScope, bool) {
+	occurrenceStr := c.Query("occurrence")
+	scope := services.RecurrenceScope(c.DefaultQuery("scope", string(services.ScopeThisOccurrence)))
+
+	if occurrenceStr == "" {
+		if c.Query("scope") != "" {
+			eh.handleError(c, http.StatusBadRequest, "INVALID_OCCURRENCE", "Scope requires an occurrence", map[string]any{
+				"occurrence": "occurrence is required when scope is set",
+			})
+			return nil, "", false
+		}
+		return nil, "", true
+	}
+
+	occurrence, err := time.Parse(time.RFC3339, occurrenceStr)
+	if err != nil {
+		eh.handleError(c, http.StatusBadRequest, "INVALID_OCCURRENCE", "Invalid occurrence format", map[string]any{
+			"occurrence": "Use RFC3339 format (2006-01-02T15:04:05Z07:00)",
+		})
+		return nil, "", false
+	}
+
+	if !scope.IsValid() {
+		eh.handleServiceError(c, services.ErrInvalidRecurrenceScope)
+		return nil, "", false
+	}
+
+	return &occurrence, scope, true
+}
+
 // handleValidationError handles validation errors from request binding
 func (eh *EventHandler) handleValidationError(c *gin.Context, err error) {
 	eh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
@@ -330,37 +671,78 @@ func (eh *EventHandler) handleValidationError(c *gin.Context, err error) {
 
 // handleServiceError handles errors from the service layer
 func (eh *EventHandler) handleServiceError(c *gin.Context, err error) {
+	status, detail := eventServiceError(err)
+	eh.handleError(c, status, detail.Code, detail.Message, detail.Details)
+}
+
+// eventServiceError maps an error from the event service to the status and
+// error detail of its response
+func eventServiceError(err error) (int, api.ErrorDetail) {
 	switch err {
 	case services.ErrEventNotFound:
-		eh.handleError(c, http.StatusNotFound, "EVENT_NOT_FOUND", "Event not found", nil)
+		return http.StatusNotFound, api.ErrorDetail{Code: "EVENT_NOT_FOUND", Message: "Event not found"}
+	case services.ErrInvalidCursor:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "INVALID_CURSOR", Message: "Invalid cursor", Details: map[string]any{
+			"cursor": "Cursor must be a next_cursor or prev_cursor returned by the same list",
+		}}
 	case services.ErrEventTitleRequired:
-		eh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Event title is required", map[string]any{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Event title is required", Details: map[string]any{
 			"title": "Title is required",
-		})
+		}}
 	case services.ErrEventTitleTooLong:
-		eh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Event title too long", map[string]any{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Event title too long", Details: map[string]any{
 			"title": "Title cannot exceed 255 characters",
-		})
+		}}
 	case services.ErrEventDescriptionTooLong:
-		eh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Event description too long", map[string]any{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Event description too long", Details: map[string]any{
 			"description": "Description cannot exceed 1000 characters",
-		})
+		}}
 	case services.ErrInvalidTimeRange:
-		eh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid time range", map[string]any{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid time range", Details: map[string]any{
 			"time_range": "End time must be after start time",
-		})
+		}}
 	case services.ErrEventInPast:
-		eh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Event cannot be in the past", map[string]any{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Event cannot be in the past", Details: map[string]any{
 			"start_time": "Event start time cannot be in the past",
-		})
+		}}
 	case services.ErrEventTooLong:
-		eh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Event duration too long", map[string]any{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Event duration too long", Details: map[string]any{
 			"duration": "Event duration cannot exceed 24 hours",
-		})
+		}}
 	case services.ErrTimeConflict:
-		eh.handleError(c, http.StatusConflict, "TIME_CONFLICT", "Event conflicts with existing events", nil)
+		return http.StatusConflict, api.ErrorDetail{Code: "TIME_CONFLICT", Message: "Event conflicts with existing events"}
+	case services.ErrVersionMismatch:
+		return http.StatusPreconditionFailed, api.ErrorDetail{Code: "PRECONDITION_FAILED", Message: "Event was modified since it was read", Details: map[string]any{
+			"if_match": "Fetch the event again and retry with its current ETag",
+		}}
+	case services.ErrInvalidRecurrenceRule:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid recurrence rule", Details: map[string]any{
+			"recurrence_rule": "Recurrence rule must be a valid RFC 5545 RRULE and overrides cannot recur",
+		}}
+	case services.ErrInvalidRecurrenceScope:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid recurrence scope", Details: map[string]any{
+			"scope": "Scope must be 'this', 'following' or 'all'",
+		}}
+	case services.ErrEventNotRecurring:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "EVENT_NOT_RECURRING", Message: "Event is not recurring"}
+	case services.ErrOccurrenceNotFound:
+		return http.StatusNotFound, api.ErrorDetail{Code: "OCCURRENCE_NOT_FOUND", Message: "Occurrence not found"}
+	case services.ErrEventNotDeleted:
+		return http.StatusConflict, api.ErrorDetail{Code: "EVENT_NOT_DELETED", Message: "Event is not in the trash"}
+	case services.ErrCalendarNotFound:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Calendar not found", Details: map[string]any{
+			"calendar_id": "Calendar must be one of your calendars",
+		}}
+	case services.ErrOverrideCalendar:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid calendar", Details: map[string]any{
+			"calendar_id": "Occurrences belong to the calendar of their series",
+		}}
+	case services.ErrInvalidEventTimeZone:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid time zone", Details: map[string]any{
+			"time_zone": "Time zone must be an IANA time zone name such as Europe/Paris",
+		}}
 	default:
-		eh.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return http.StatusInternalServerError, api.ErrorDetail{Code: "INTERNAL_ERROR", Message: "Internal server error"}
 	}
 }
 
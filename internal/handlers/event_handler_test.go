@@ -8,10 +8,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"agenda/internal/database"
+	"agenda/internal/middleware"
 	"agenda/internal/models"
 	"agenda/internal/services"
 	"github.com/gin-gonic/gin"
@@ -23,6 +25,7 @@ import (
 func setupEventTestDB(t *testing.T) *sql.DB {
 	db, err := sql.Open("sqlite3", ":memory:")
 	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
 
 	// Create events table
 	schema := `
@@ -32,10 +35,59 @@ func setupEventTestDB(t *testing.T) *sql.DB {
 		description TEXT,
 		start_time DATETIME NOT NULL,
 		end_time DATETIME NOT NULL,
+		time_zone TEXT NOT NULL DEFAULT '',
+		recurrence_rule TEXT NOT NULL DEFAULT '',
+		exdates TEXT NOT NULL DEFAULT '',
+		parent_id INTEGER,
+		recurrence_id DATETIME,
+		uid TEXT NOT NULL DEFAULT '',
+		user_id INTEGER,
+		version INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		deleted_at DATETIME,
+		calendar_id INTEGER
+	);
+
+	CREATE TABLE reminders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER,
+		event_id INTEGER,
+		offset_minutes INTEGER NOT NULL DEFAULT 0,
+		occurrence_at DATETIME NOT NULL,
+		remind_at DATETIME NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		sent_at DATETIME,
+		user_id INTEGER,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE event_attendees (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		email TEXT NOT NULL,
+		user_id INTEGER,
+		role TEXT NOT NULL DEFAULT 'REQ-PARTICIPANT',
+		status TEXT NOT NULL DEFAULT 'NEEDS-ACTION',
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE VIRTUAL TABLE events_fts USING fts5(title, description, tokenize = "unicode61 remove_diacritics 2");
+	CREATE TRIGGER events_fts_insert AFTER INSERT ON events BEGIN
+		INSERT INTO events_fts (rowid, title, description) VALUES (new.id, new.title, COALESCE(new.description, ''));
+	END;
+	CREATE TRIGGER events_fts_update AFTER UPDATE OF title, description ON events BEGIN
+		UPDATE events_fts SET title = new.title, description = COALESCE(new.description, '') WHERE rowid = new.id;
+	END;
+	CREATE TRIGGER events_fts_delete AFTER DELETE ON events BEGIN
+		DELETE FROM events_fts WHERE rowid = old.id;
+	END;
+
 	CREATE INDEX idx_events_start_time ON events(start_time);
 	CREATE INDEX idx_events_date_range ON events(start_time, end_time);
 	`
@@ -48,7 +100,7 @@ func setupEventTestDB(t *testing.T) *sql.DB {
 func setupEventTestHandler(t *testing.T) (*EventHandler, *sql.DB) {
 	db := setupEventTestDB(t)
 	eventRepo := database.NewEventRepository(db)
-	eventService := services.NewEventService(eventRepo)
+	eventService := services.NewEventService(eventRepo, database.NewCalendarRepository(db), database.NewTransactionManager(db))
 	handler := NewEventHandler(eventService)
 	return handler, db
 }
@@ -62,10 +114,13 @@ func setupEventTestRouter(handler *EventHandler) *gin.Engine {
 	{
 		events.GET("", handler.ListEvents)
 		events.POST("", handler.CreateEvent)
+		events.POST("/bulk", handler.BulkEvents)
 		events.GET("/upcoming", handler.GetUpcomingEvents)
 		events.GET("/:id", handler.GetEvent)
 		events.PUT("/:id", handler.UpdateEvent)
+		events.PATCH("/:id", handler.PatchEvent)
 		events.DELETE("/:id", handler.DeleteEvent)
+		events.POST("/:id/restore", handler.RestoreEvent)
 	}
 	
 	return router
@@ -330,6 +385,101 @@ func TestUpdateEvent(t *testing.T) {
 	}
 }
 
+func TestEventETags(t *testing.T) {
+	handler, db := setupEventTestHandler(t)
+	defer db.Close()
+	router := setupEventTestRouter(handler)
+
+	event := createTestEvent(t, handler)
+	path := fmt.Sprintf("/api/events/%d", event.ID)
+
+	put := func(ifMatch, title string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(UpdateEventRequest{Title: stringPtr(title)})
+		req := httptest.NewRequest(http.MethodPut, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	original := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, original)
+
+	req = httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("If-None-Match", original)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = put(original, "First writer")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	w = put(original, "Second writer")
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var errorResp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+	assert.Equal(t, "PRECONDITION_FAILED", errorResp.Error.Code)
+
+	current, err := handler.eventService.GetEventByID(context.Background(), event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First writer", current.Title)
+	assert.Equal(t, 2, current.Version)
+}
+
+func TestPatchEvent(t *testing.T) {
+	handler, db := setupEventTestHandler(t)
+	defer db.Close()
+	router := setupEventTestRouter(handler)
+
+	event := createTestEvent(t, handler)
+	path := fmt.Sprintf("/api/events/%d", event.ID)
+
+	patch := func(contentType, body string) (*httptest.ResponseRecorder, models.Event) {
+		req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var patched models.Event
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+		}
+		return w, patched
+	}
+
+	w, patched := patch("application/merge-patch+json", `{"title": "Patched", "description": null}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "Patched", patched.Title)
+	assert.Empty(t, patched.Description)
+	assert.True(t, event.StartTime.Equal(patched.StartTime))
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	newEnd := event.EndTime.Add(time.Hour).UTC().Format(time.RFC3339Nano)
+	w, patched = patch("application/json-patch+json", `[{"op": "replace", "path": "/end_time", "value": "`+newEnd+`"}]`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, event.EndTime.Add(time.Hour).Equal(patched.EndTime))
+
+	// Events cannot lose their times, and patches are validated like updates
+	w, _ = patch("application/merge-patch+json", `{"start_time": null}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "start_time")
+
+	w, _ = patch("application/json-patch+json", `[{"op": "replace", "path": "/end_time", "value": "`+event.StartTime.Add(-time.Hour).UTC().Format(time.RFC3339Nano)+`"}]`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+
+	w, _ = patch("application/merge-patch+json", `{"uid": "other"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "uid")
+}
+
 func TestDeleteEvent(t *testing.T) {
 	handler, db := setupEventTestHandler(t)
 	defer db.Close()
@@ -390,6 +540,50 @@ func TestDeleteEvent(t *testing.T) {
 	}
 }
 
+func TestRestoreEvent(t *testing.T) {
+	handler, db := setupEventTestHandler(t)
+	defer db.Close()
+	router := setupEventTestRouter(handler)
+
+	event := createTestEvent(t, handler)
+	require.NoError(t, handler.eventService.DeleteEvent(context.Background(), event.ID))
+
+	restore := func(eventID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/events/"+eventID+"/restore", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := restore(fmt.Sprintf("%d", event.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var restoredEvent models.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restoredEvent))
+	assert.Equal(t, event.ID, restoredEvent.ID)
+	assert.Nil(t, restoredEvent.DeletedAt)
+
+	tests := []struct {
+		name           string
+		eventID        string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"event not in the trash", fmt.Sprintf("%d", event.ID), http.StatusConflict, "EVENT_NOT_DELETED"},
+		{"non-existent event", "999", http.StatusNotFound, "EVENT_NOT_FOUND"},
+		{"invalid event ID", "invalid", http.StatusBadRequest, "INVALID_ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := restore(tt.eventID)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResp ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+			assert.Equal(t, tt.expectedError, errorResp.Error.Code)
+		})
+	}
+}
+
 func TestListEvents(t *testing.T) {
 	handler, db := setupEventTestHandler(t)
 	defer db.Close()
@@ -460,12 +654,53 @@ func TestListEvents(t *testing.T) {
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				require.NoError(t, err)
 				assert.NotNil(t, response.Data)
-				assert.GreaterOrEqual(t, response.Total, int64(0))
+				require.NotNil(t, response.Total)
+				assert.GreaterOrEqual(t, *response.Total, int64(0))
 			}
 		})
 	}
 }
 
+func TestListEvents_Cursor(t *testing.T) {
+	handler, db := setupEventTestHandler(t)
+	defer db.Close()
+	router := setupEventTestRouter(handler)
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		start := now.Add(time.Duration(2*i+1) * time.Hour)
+		createTestEventWithTime(t, handler, start, start.Add(time.Hour))
+	}
+
+	list := func(query string) map[string]any {
+		req := httptest.NewRequest(http.MethodGet, "/api/events"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	first := list("?page_size=2&include_total=false")
+	assert.Len(t, first["data"], 2)
+	assert.NotContains(t, first, "total")
+	require.Contains(t, first, "next_cursor")
+
+	second := list("?page_size=2&cursor=" + first["next_cursor"].(string))
+	assert.Len(t, second["data"], 1)
+	assert.Equal(t, float64(3), second["total"])
+	assert.NotContains(t, second, "next_cursor")
+	assert.Contains(t, second, "prev_cursor")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events?cursor=garbage", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_CURSOR")
+}
+
 func TestListEventsByMonth(t *testing.T) {
 	handler, db := setupEventTestHandler(t)
 	defer db.Close()
@@ -530,8 +765,8 @@ func TestListEventsByDay(t *testing.T) {
 	defer db.Close()
 	router := setupEventTestRouter(handler)
 
-	// Create test event for today
-	now := time.Now()
+	// Create test event for tomorrow so it is never in the past
+	now := time.Now().AddDate(0, 0, 1)
 	today := time.Date(now.Year(), now.Month(), now.Day(), 10, 0, 0, 0, now.Location())
 	createTestEventWithTime(t, handler, today, today.Add(2*time.Hour))
 
@@ -585,6 +820,72 @@ func TestListEventsByDay(t *testing.T) {
 	}
 }
 
+func TestEventTimeZones(t *testing.T) {
+	handler, db := setupEventTestHandler(t)
+	defer db.Close()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	events := router.Group("/api/events", middleware.TimeZone())
+	events.GET("", handler.ListEvents)
+	events.POST("", handler.CreateEvent)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Weekly at 9:00 in New York from early March, across the DST change
+	year := time.Now().Year() + 1
+	start := time.Date(year, time.March, 4, 9, 0, 0, 0, newYork)
+	body, _ := json.Marshal(map[string]interface{}{
+		"title":           "Planning",
+		"start_time":      start.UTC(),
+		"end_time":        start.Add(time.Hour).UTC(),
+		"recurrence_rule": "FREQ=WEEKLY;COUNT=4",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/events", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.TimeZoneHeader, "America/New_York")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created models.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "America/New_York", created.TimeZone)
+
+	// The last occurrence, after the DST change, is still at 9:00
+	day := fmt.Sprintf("%d-03-25", year)
+	req = httptest.NewRequest(http.MethodGet, "/api/events?day="+day+"&tz=America/New_York", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Events []models.Event `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Events, 1)
+	assert.True(t, time.Date(year, time.March, 25, 9, 0, 0, 0, newYork).Equal(response.Events[0].StartTime))
+
+	// Invalid zones are rejected
+	body, _ = json.Marshal(map[string]interface{}{
+		"title":      "Elsewhere",
+		"start_time": start.AddDate(0, 1, 0),
+		"end_time":   start.AddDate(0, 1, 0).Add(time.Hour),
+		"time_zone":  "Nowhere/Special",
+	})
+	req = httptest.NewRequest(http.MethodPost, "/api/events", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/events?day="+day+"&tz=Nowhere/Special", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestGetUpcomingEvents(t *testing.T) {
 	handler, db := setupEventTestHandler(t)
 	defer db.Close()
@@ -729,6 +1030,89 @@ func TestEventTimeConflicts(t *testing.T) {
 
 // Helper functions
 
+func TestBulkEvents(t *testing.T) {
+	handler, db := setupEventTestHandler(t)
+	defer db.Close()
+	router := setupEventTestRouter(handler)
+
+	bulk := func(t *testing.T, body string) (int, BulkResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/events/bulk", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response BulkResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	title := func(t *testing.T, id int) string {
+		event, err := handler.eventService.GetEventByID(context.Background(), id)
+		require.NoError(t, err)
+		return event.Title
+	}
+
+	start := time.Date(2030, time.June, 3, 10, 0, 0, 0, time.UTC)
+	event := createTestEventWithTime(t, handler, start, start.Add(time.Hour))
+	operations := fmt.Sprintf(`"operations": [
+		{"op": "update", "id": %d, "data": {"title": "Renamed"}},
+		{"op": "create", "data": {"title": "Retro", "start_time": "2030-06-04T10:00:00Z", "end_time": "2030-06-04T11:00:00Z"}},
+		{"op": "delete", "id": 999}
+	]`, event.ID)
+
+	t.Run("atomic", func(t *testing.T) {
+		status, response := bulk(t, `{`+operations+`}`)
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Equal(t, 3, response.Failed)
+		require.Len(t, response.Results, 3)
+		assert.Equal(t, "BULK_ABORTED", response.Results[0].Error.Code)
+		assert.Equal(t, "BULK_ABORTED", response.Results[1].Error.Code)
+		assert.Equal(t, "EVENT_NOT_FOUND", response.Results[2].Error.Code)
+
+		assert.Equal(t, "Test Event", title(t, event.ID))
+	})
+
+	t.Run("best effort", func(t *testing.T) {
+		status, response := bulk(t, `{"mode": "best_effort", `+operations+`}`)
+		assert.Equal(t, http.StatusMultiStatus, status)
+		assert.Equal(t, 2, response.Succeeded)
+		require.Len(t, response.Results, 3)
+		assert.Equal(t, http.StatusOK, response.Results[0].Status)
+		assert.Equal(t, http.StatusCreated, response.Results[1].Status)
+		assert.Equal(t, http.StatusNotFound, response.Results[2].Status)
+
+		assert.Equal(t, "Renamed", title(t, event.ID))
+	})
+
+	t.Run("selection", func(t *testing.T) {
+		later := createTestEventWithTime(t, handler, start.AddDate(0, 1, 0), start.AddDate(0, 1, 0).Add(time.Hour))
+
+		status, response := bulk(t, `{
+			"select": {"start_after": "2030-06-01T00:00:00Z", "start_before": "2030-06-30T00:00:00Z"},
+			"op": "update",
+			"data": {"title": "Sprint"}
+		}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 2, response.Succeeded)
+
+		assert.Equal(t, "Sprint", title(t, event.ID))
+		assert.Equal(t, "Test Event", title(t, later.ID))
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		for _, body := range []string{
+			`{}`,
+			`{"operations": [{"op": "complete", "id": 1}]}`,
+			`{"operations": [{"op": "create", "data": {"title": "No times"}}]}`,
+			`{"select": {}, "op": "delete"}`,
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/api/events/bulk", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+}
+
 func createTestEvent(t *testing.T, handler *EventHandler) *models.Event {
 	now := time.Now()
 	startTime := now.Add(1 * time.Hour)
@@ -746,4 +1130,204 @@ func createTestEventWithTime(t *testing.T, handler *EventHandler, startTime, end
 	event, err := handler.eventService.CreateEvent(context.Background(), req)
 	require.NoError(t, err)
 	return event
-}
\ No newline at end of file
+}
+func TestRecurringEventSplitKeepsOverrides(t *testing.T) {
+	handler, db := setupEventTestHandler(t)
+	defer db.Close()
+	router := setupEventTestRouter(handler)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	start := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 10, 0, 0, 0, time.UTC)
+
+	send := func(method, target string, payload interface{}) *httptest.ResponseRecorder {
+		var body []byte
+		if payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		req := httptest.NewRequest(method, target, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	eventsOnDay := func(day time.Time) []models.Event {
+		w := send(http.MethodGet, "/api/events?day="+day.Format("2006-01-02"), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Events []models.Event `json:"events"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Events
+	}
+
+	w := send(http.MethodPost, "/api/events", CreateEventRequest{
+		Title:          "Standup",
+		StartTime:      start,
+		EndTime:        start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=DAILY;COUNT=5",
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var series models.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &series))
+	seriesURL := fmt.Sprintf("/api/events/%d", series.ID)
+	occurrenceQuery := func(occurrence time.Time) string {
+		return "?occurrence=" + url.QueryEscape(occurrence.Format(time.RFC3339))
+	}
+
+	// Edit the fourth occurrence, then the second and the ones following it
+	fourth := start.AddDate(0, 0, 3)
+	w = send(http.MethodPut, seriesURL+occurrenceQuery(fourth)+"&scope=this", UpdateEventRequest{Title: stringPtr("Demo day")})
+	require.Equal(t, http.StatusOK, w.Code)
+	var override models.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &override))
+
+	second := start.AddDate(0, 0, 1)
+	w = send(http.MethodPut, seriesURL+occurrenceQuery(second)+"&scope=following", UpdateEventRequest{Title: stringPtr("Sync")})
+	require.Equal(t, http.StatusOK, w.Code)
+	var following models.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &following))
+
+	// The override now belongs to the new series and still replaces its occurrence
+	events := eventsOnDay(fourth)
+	require.Len(t, events, 1)
+	assert.Equal(t, override.ID, events[0].ID)
+	assert.Equal(t, "Demo day", events[0].Title)
+	require.NotNil(t, events[0].ParentID)
+	assert.Equal(t, following.ID, *events[0].ParentID)
+
+	events = eventsOnDay(start.AddDate(0, 0, 2))
+	require.Len(t, events, 1)
+	assert.Equal(t, "Sync", events[0].Title)
+
+	// Deleting the following occurrences drops the override with them
+	w = send(http.MethodDelete, fmt.Sprintf("/api/events/%d", following.ID)+occurrenceQuery(start.AddDate(0, 0, 2))+"&scope=following", nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, eventsOnDay(fourth))
+}
+
+func TestRecurringEventOccurrences(t *testing.T) {
+	handler, db := setupEventTestHandler(t)
+	defer db.Close()
+	router := setupEventTestRouter(handler)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	start := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 10, 0, 0, 0, time.UTC)
+
+	// Create a daily series
+	body, _ := json.Marshal(CreateEventRequest{
+		Title:          "Standup",
+		StartTime:      start,
+		EndTime:        start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=DAILY;COUNT=5",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/events", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var series models.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &series))
+	assert.Equal(t, "FREQ=DAILY;COUNT=5", series.RecurrenceRule)
+
+	eventsOnDay := func(day time.Time) []models.Event {
+		req := httptest.NewRequest(http.MethodGet, "/api/events?day="+day.Format("2006-01-02"), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Events []models.Event `json:"events"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Events
+	}
+
+	// Occurrences are expanded in calendar views
+	third := start.AddDate(0, 0, 2)
+	events := eventsOnDay(third)
+	require.Len(t, events, 1)
+	assert.Equal(t, series.ID, events[0].ID)
+	assert.True(t, events[0].StartTime.Equal(third))
+	require.NotNil(t, events[0].RecurrenceID)
+
+	seriesURL := fmt.Sprintf("/api/events/%d", series.ID)
+	occurrenceQuery := "?occurrence=" + url.QueryEscape(third.Format(time.RFC3339))
+
+	// Errors for occurrence parameters
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "invalid occurrence format",
+			query:          "?occurrence=tomorrow",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_OCCURRENCE",
+		},
+		{
+			name:           "scope without occurrence",
+			query:          "?scope=following",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_OCCURRENCE",
+		},
+		{
+			name:           "invalid scope",
+			query:          occurrenceQuery + "&scope=some",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+		{
+			name:           "not an occurrence",
+			query:          "?occurrence=" + url.QueryEscape(third.Add(time.Hour).Format(time.RFC3339)),
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "OCCURRENCE_NOT_FOUND",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, seriesURL+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResp ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+			assert.Equal(t, tt.expectedError, errorResp.Error.Code)
+		})
+	}
+
+	// Editing a single occurrence detaches it from the series
+	body, _ = json.Marshal(UpdateEventRequest{Title: stringPtr("Moved standup")})
+	req = httptest.NewRequest(http.MethodPut, seriesURL+occurrenceQuery+"&scope=this", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	events = eventsOnDay(third)
+	require.Len(t, events, 1)
+	assert.NotEqual(t, series.ID, events[0].ID)
+	assert.Equal(t, "Moved standup", events[0].Title)
+
+	// Deleting a single occurrence removes its override too
+	req = httptest.NewRequest(http.MethodDelete, seriesURL+occurrenceQuery, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, eventsOnDay(third))
+
+	// Deleting this and following occurrences ends the series
+	fourth := start.AddDate(0, 0, 3)
+	req = httptest.NewRequest(http.MethodDelete, seriesURL+"?occurrence="+url.QueryEscape(fourth.Format(time.RFC3339))+"&scope=following", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, eventsOnDay(fourth))
+	assert.Len(t, eventsOnDay(start.AddDate(0, 0, 1)), 1)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"agenda/internal/api"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize bounds the size of uploaded iCalendar files
+const maxImportSize = 10 << 20
+
+// ICalHandler handles HTTP requests for iCalendar import and export
+type ICalHandler struct {
+	icalService services.ICalServiceInterface
+}
+
+// NewICalHandler creates a new iCalendar handler instance
+func NewICalHandler(icalService services.ICalServiceInterface) *ICalHandler {
+	return &ICalHandler{
+		icalService: icalService,
+	}
+}
+
+// ICalExportQuery represents query parameters for calendar export
+type ICalExportQuery struct {
+	StartDate string `form:"start_date"`
+	EndDate   string `form:"end_date"`
+}
+
+// ExportCalendar handles GET /api/calendar.ics
+func (ih *ICalHandler) ExportCalendar(c *gin.Context) {
+	var query ICalExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		ih.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+			"validation_error": err.Error(),
+		})
+		return
+	}
+
+	// Parse the optional range, using the same format as the dashboard date range
+	var startDate, endDate *time.Time
+	if query.StartDate != "" {
+		t, err := time.Parse(time.RFC3339, query.StartDate)
+		if err != nil {
+			ih.handleError(c, http.StatusBadRequest, "INVALID_DATE", "Invalid start_date format", map[string]any{
+				"start_date": "Date must be in RFC3339 format (e.g., 2023-01-01T00:00:00Z)",
+			})
+			return
+		}
+		startDate = &t
+	}
+	if query.EndDate != "" {
+		t, err := time.Parse(time.RFC3339, query.EndDate)
+		if err != nil {
+			ih.handleError(c, http.StatusBadRequest, "INVALID_DATE", "Invalid end_date format", map[string]any{
+				"end_date": "Date must be in RFC3339 format (e.g., 2023-01-01T00:00:00Z)",
+			})
+			return
+		}
+		endDate = &t
+	}
+
+	data, err := ih.icalService.ExportCalendar(c.Request.Context(), startDate, endDate)
+	if err != nil {
+		ih.handleServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="agenda.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
+
+// ImportCalendar handles POST /api/import/ics
+// The calendar is either the raw text/calendar body or the "file" field of a
+// multipart form upload.
+func (ih *ICalHandler) ImportCalendar(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			ih.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+				"file": "An .ics file must be uploaded in the 'file' field",
+			})
+			return
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			ih.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+				"file": "Uploaded file could not be read",
+			})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	result, err := ih.icalService.ImportCalendar(c.Request.Context(), body)
+	if err != nil {
+		ih.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleServiceError handles errors from the service layer
+func (ih *ICalHandler) handleServiceError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		ih.handleError(c, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "Calendar file too large", map[string]any{
+			"max_bytes": maxImportSize,
+		})
+	case errors.Is(err, services.ErrInvalidCalendar):
+		ih.handleError(c, http.StatusBadRequest, "INVALID_CALENDAR", "Invalid iCalendar data", map[string]any{
+			"calendar": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidDateRange):
+		ih.handleError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", "End date must be after start date", nil)
+	default:
+		ih.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (ih *ICalHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
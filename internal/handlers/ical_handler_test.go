@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupICalTestHandler serves iCalendar imports and exports on an in-memory
+// database with the full schema, publishing imports to publishers
+func setupICalTestHandler(t *testing.T, publishers ...services.ChangePublisher) (*ICalHandler, *sql.DB) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	icalService := services.NewICalService(database.NewTaskRepository(db), database.NewEventRepository(db),
+		database.NewCalendarRepository(db), database.NewTransactionManager(db), publishers...)
+	handler := NewICalHandler(icalService)
+	return handler, db
+}
+
+func setupICalTestRouter(handler *ICalHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	router.GET("/api/calendar.ics", handler.ExportCalendar)
+	router.POST("/api/import/ics", handler.ImportCalendar)
+
+	return router
+}
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//example//test//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"DTSTART;TZID=Europe/Madrid:20300107T093000\r\n" +
+	"DURATION:PT15M\r\n" +
+	"SUMMARY:Standup\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10\r\n" +
+	"EXDATE;TZID=Europe/Madrid:20300109T093000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Madrid:20300114T093000\r\n" +
+	"DTSTART;TZID=Europe/Madrid:20300114T100000\r\n" +
+	"DTEND;TZID=Europe/Madrid:20300114T101500\r\n" +
+	"SUMMARY:Standup (late)\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:offsite@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20300301\r\n" +
+	"SUMMARY:Offsite\r\n" +
+	"DESCRIPTION:Bring a laptop\\, a charger\\nand snacks\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:broken@example.com\r\n" +
+	"SUMMARY:No start\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:report@example.com\r\n" +
+	"SUMMARY:Write report\r\n" +
+	"DUE:20300110T170000Z\r\n" +
+	"STATUS:NEEDS-ACTION\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func importCalendar(t *testing.T, router *gin.Engine, data string) services.ImportResult {
+	req := httptest.NewRequest(http.MethodPost, "/api/import/ics", strings.NewReader(data))
+	req.Header.Set("Content-Type", "text/calendar")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result services.ImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func TestImportCalendar(t *testing.T) {
+	handler, db := setupICalTestHandler(t)
+	router := setupICalTestRouter(handler)
+
+	result := importCalendar(t, router, testCalendar)
+	assert.Equal(t, 3, result.EventsCreated)
+	assert.Equal(t, 0, result.EventsUpdated)
+	assert.Equal(t, 1, result.TasksCreated)
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, "broken@example.com", result.Skipped[0].UID)
+
+	// Re-importing the same file updates the records instead of duplicating them
+	updated := strings.Replace(testCalendar, "SUMMARY:Write report", "SUMMARY:Write the report", 1)
+	result = importCalendar(t, router, updated)
+	assert.Equal(t, 0, result.EventsCreated)
+	assert.Equal(t, 3, result.EventsUpdated)
+	assert.Equal(t, 0, result.TasksCreated)
+	assert.Equal(t, 1, result.TasksUpdated)
+
+	var events, tasks int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM events").Scan(&events))
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&tasks))
+	assert.Equal(t, 3, events)
+	assert.Equal(t, 1, tasks)
+
+	var title, description, rule, exdates string
+	require.NoError(t, db.QueryRow("SELECT title FROM tasks WHERE uid = 'report@example.com'").Scan(&title))
+	assert.Equal(t, "Write the report", title)
+	require.NoError(t, db.QueryRow("SELECT recurrence_rule, exdates FROM events WHERE uid = 'standup@example.com'").Scan(&rule, &exdates))
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=10;BYDAY=MO,WE", rule)
+	assert.Equal(t, "2030-01-09T08:30:00Z", exdates)
+	require.NoError(t, db.QueryRow("SELECT description FROM events WHERE uid = 'offsite@example.com'").Scan(&description))
+	assert.Equal(t, "Bring a laptop, a charger\nand snacks", description)
+}
+
+// failingPublisher fails to publish the changes of the given type
+type failingPublisher struct {
+	changeType string
+}
+
+func (p failingPublisher) Publish(ctx context.Context, change services.Change) error {
+	if change.Type == p.changeType {
+		return errors.New("publisher unavailable")
+	}
+	return nil
+}
+
+func TestImportCalendar_RollsBack(t *testing.T) {
+	// The task is imported after the events, which are undone with it
+	handler, db := setupICalTestHandler(t, failingPublisher{changeType: services.ChangeTaskCreated})
+	router := setupICalTestRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/import/ics", strings.NewReader(testCalendar))
+	req.Header.Set("Content-Type", "text/calendar")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var events, tasks int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM events").Scan(&events))
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&tasks))
+	assert.Equal(t, 0, events)
+	assert.Equal(t, 0, tasks)
+}
+
+func TestImportCalendar_MultipartUpload(t *testing.T) {
+	handler, _ := setupICalTestHandler(t)
+	router := setupICalTestRouter(handler)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "calendar.ics")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(testCalendar))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/import/ics", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var result services.ImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 3, result.EventsCreated)
+	assert.Equal(t, 1, result.TasksCreated)
+}
+
+func TestImportCalendar_Errors(t *testing.T) {
+	handler, _ := setupICalTestHandler(t)
+	router := setupICalTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "not a calendar",
+			contentType:    "text/calendar",
+			body:           "hello",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_CALENDAR",
+		},
+		{
+			name:           "unbalanced calendar",
+			contentType:    "text/calendar",
+			body:           "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_CALENDAR",
+		},
+		{
+			name:           "multipart without file",
+			contentType:    "multipart/form-data; boundary=x",
+			body:           "--x--\r\n",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/import/ics", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResp ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+			assert.Equal(t, tt.expectedError, errorResp.Error.Code)
+		})
+	}
+}
+
+func TestExportCalendar(t *testing.T) {
+	handler, _ := setupICalTestHandler(t)
+	router := setupICalTestRouter(handler)
+
+	importCalendar(t, router, testCalendar)
+
+	tests := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedContains []string
+		expectedMissing  []string
+	}{
+		{
+			name:           "full export",
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedContains: []string{
+				"BEGIN:VCALENDAR",
+				"BEGIN:VTIMEZONE\r\nTZID:Europe/Madrid\r\n",
+				"DTSTART;TZID=Europe/Madrid:20300107T093000",
+				"UID:standup@example.com",
+				"RRULE:FREQ=WEEKLY;COUNT=10;BYDAY=MO,WE",
+				"EXDATE:20300109T083000Z",
+				"RECURRENCE-ID:20300114T083000Z",
+				"UID:offsite@example.com",
+				"DESCRIPTION:Bring a laptop\\, a charger\\nand snacks",
+				"BEGIN:VTODO",
+				"DUE:20300110T170000Z",
+				"STATUS:NEEDS-ACTION",
+			},
+		},
+		{
+			name:             "range export",
+			query:            "?start_date=2030-02-10T00:00:00Z&end_date=2030-03-31T00:00:00Z",
+			expectedStatus:   http.StatusOK,
+			expectedContains: []string{"UID:offsite@example.com"},
+			expectedMissing:  []string{"UID:standup@example.com", "BEGIN:VTODO"},
+		},
+		{
+			name:           "invalid date",
+			query:          "?start_date=2030-02-01",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid range",
+			query:          "?start_date=2030-03-01T00:00:00Z&end_date=2030-02-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/calendar.ics"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+			for _, s := range tt.expectedContains {
+				assert.Contains(t, w.Body.String(), s)
+			}
+			for _, s := range tt.expectedMissing {
+				assert.NotContains(t, w.Body.String(), s)
+			}
+		})
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	handler, _ := setupICalTestHandler(t)
+	router := setupICalTestRouter(handler)
+
+	importCalendar(t, router, testCalendar)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/calendar.ics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Importing our own export matches every record by UID
+	result := importCalendar(t, router, w.Body.String())
+	assert.Equal(t, 0, result.EventsCreated)
+	assert.Equal(t, 3, result.EventsUpdated)
+	assert.Equal(t, 1, result.TasksUpdated)
+	assert.Empty(t, result.Skipped)
+}
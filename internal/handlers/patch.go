@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+
+	"agenda/internal/api"
+	"agenda/internal/jsonpatch"
+
+	"github.com/gin-gonic/gin"
+)
+
+// patchFieldError reports a member of a patched document that cannot take
+// its new value
+type patchFieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *patchFieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// patchChanges applies the patch in the request body to the JSON
+// representation of current. JSON Patch documents are recognized by their
+// content type; other bodies are JSON merge patches. It returns the members
+// of the patched document whose value changed, removed members as null.
+func patchChanges(c *gin.Context, current interface{}) (map[string]json.RawMessage, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", jsonpatch.ErrInvalidPatch, err)
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	var original map[string]interface{}
+	if err := json.Unmarshal(data, &original); err != nil {
+		return nil, err
+	}
+
+	var patched interface{}
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType == api.MediaTypeJSONPatch {
+		patch, err := jsonpatch.Parse(body)
+		if err != nil {
+			return nil, err
+		}
+		if patched, err = patch.Apply(original); err != nil {
+			return nil, err
+		}
+	} else {
+		var patch interface{}
+		if err := json.Unmarshal(body, &patch); err != nil {
+			return nil, fmt.Errorf("%w: the merge patch is not valid JSON", jsonpatch.ErrInvalidPatch)
+		}
+		patched = jsonpatch.Merge(original, patch)
+	}
+
+	patchedObject, ok := patched.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: the patched document must be an object", jsonpatch.ErrInvalidPatch)
+	}
+
+	changes := make(map[string]json.RawMessage)
+	for name, value := range patchedObject {
+		if previous, ok := original[name]; ok && reflect.DeepEqual(previous, value) {
+			continue
+		}
+		if changes[name], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	for name, previous := range original {
+		if _, ok := patchedObject[name]; !ok && previous != nil {
+			changes[name] = json.RawMessage("null")
+		}
+	}
+	return changes, nil
+}
+
+// decodeChanges decodes the changed members of a patched document into req,
+// a pointer to an update request. writable maps the members that may change
+// to the JSON value that null stands for, or to the empty string when the
+// member cannot be null.
+func decodeChanges(changes map[string]json.RawMessage, writable map[string]string, req interface{}) error {
+	fields := make(map[string]json.RawMessage, len(changes))
+	for name, value := range changes {
+		null, ok := writable[name]
+		if !ok {
+			return &patchFieldError{Field: name, Message: "Field cannot be changed"}
+		}
+		if string(value) == "null" {
+			if null == "" {
+				return &patchFieldError{Field: name, Message: "Field cannot be null"}
+			}
+			value = json.RawMessage(null)
+		}
+		fields[name] = value
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, req); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return &patchFieldError{Field: typeErr.Field, Message: "Field has the wrong type"}
+		}
+		return &patchFieldError{Field: "patch", Message: err.Error()}
+	}
+	return nil
+}
+
+// patchError returns the status, code, message and details of the response
+// to a patch that cannot be applied
+func patchError(err error) (int, string, string, map[string]interface{}) {
+	var fieldErr *patchFieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		return http.StatusBadRequest, "VALIDATION_ERROR", "Invalid patch", map[string]interface{}{
+			fieldErr.Field: fieldErr.Message,
+		}
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return http.StatusConflict, "PATCH_TEST_FAILED", "Patch test operation failed", map[string]interface{}{
+			"patch": err.Error(),
+		}
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		return http.StatusBadRequest, "INVALID_PATCH", "Invalid patch", map[string]interface{}{
+			"patch": err.Error(),
+		}
+	}
+	return http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"agenda/internal/api"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReminderHandler handles HTTP requests for task and event reminders
+type ReminderHandler struct {
+	reminderService services.ReminderServiceInterface
+}
+
+// NewReminderHandler creates a new reminder handler instance
+func NewReminderHandler(reminderService services.ReminderServiceInterface) *ReminderHandler {
+	return &ReminderHandler{
+		reminderService: reminderService,
+	}
+}
+
+// CreateReminderRequest represents the HTTP request body for adding a reminder
+type CreateReminderRequest struct {
+	OffsetMinutes *int `json:"offset_minutes" binding:"required"`
+}
+
+// CreateTaskReminder handles POST /api/tasks/:id/reminders
+func (rh *ReminderHandler) CreateTaskReminder(c *gin.Context) {
+	id, err := rh.parseID(c)
+	if err != nil {
+		rh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
+		return
+	}
+
+	req, ok := rh.bindCreateRequest(c)
+	if !ok {
+		return
+	}
+
+	reminder, err := rh.reminderService.CreateTaskReminder(c.Request.Context(), id, req)
+	if err != nil {
+		rh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reminder)
+}
+
+// GetTaskReminders handles GET /api/tasks/:id/reminders
+func (rh *ReminderHandler) GetTaskReminders(c *gin.Context) {
+	id, err := rh.parseID(c)
+	if err != nil {
+		rh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
+		return
+	}
+
+	reminders, err := rh.reminderService.GetTaskReminders(c.Request.Context(), id)
+	if err != nil {
+		rh.handleServiceError(c, err)
+		return
+	}
+
+	rh.respondWithReminders(c, reminders)
+}
+
+// CreateEventReminder handles POST /api/events/:id/reminders
+func (rh *ReminderHandler) CreateEventReminder(c *gin.Context) {
+	id, err := rh.parseID(c)
+	if err != nil {
+		rh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid event ID", nil)
+		return
+	}
+
+	req, ok := rh.bindCreateRequest(c)
+	if !ok {
+		return
+	}
+
+	reminder, err := rh.reminderService.CreateEventReminder(c.Request.Context(), id, req)
+	if err != nil {
+		rh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reminder)
+}
+
+// GetEventReminders handles GET /api/events/:id/reminders
+func (rh *ReminderHandler) GetEventReminders(c *gin.Context) {
+	id, err := rh.parseID(c)
+	if err != nil {
+		rh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid event ID", nil)
+		return
+	}
+
+	reminders, err := rh.reminderService.GetEventReminders(c.Request.Context(), id)
+	if err != nil {
+		rh.handleServiceError(c, err)
+		return
+	}
+
+	rh.respondWithReminders(c, reminders)
+}
+
+// DeleteReminder handles DELETE /api/reminders/:id
+func (rh *ReminderHandler) DeleteReminder(c *gin.Context) {
+	id, err := rh.parseID(c)
+	if err != nil {
+		rh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid reminder ID", nil)
+		return
+	}
+
+	if err := rh.reminderService.DeleteReminder(c.Request.Context(), id); err != nil {
+		rh.handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// bindCreateRequest binds the body of a create request. It writes the error
+// response and returns false when the body is invalid.
+func (rh *ReminderHandler) bindCreateRequest(c *gin.Context) (services.CreateReminderRequest, bool) {
+	var req CreateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+			"validation_error": err.Error(),
+		})
+		return services.CreateReminderRequest{}, false
+	}
+
+	return services.CreateReminderRequest{OffsetMinutes: *req.OffsetMinutes}, true
+}
+
+// respondWithReminders writes a list of reminders
+func (rh *ReminderHandler) respondWithReminders(c *gin.Context, reminders []*models.Reminder) {
+	if reminders == nil {
+		reminders = []*models.Reminder{}
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"reminders": reminders,
+		"total":     len(reminders),
+	})
+}
+
+// parseID extracts and validates the ID from the URL parameter
+func (rh *ReminderHandler) parseID(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, errors.New("ID must be positive")
+	}
+	return id, nil
+}
+
+// handleServiceError handles errors from the service layer
+func (rh *ReminderHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrTaskNotFound:
+		rh.handleError(c, http.StatusNotFound, "TASK_NOT_FOUND", "Task not found", nil)
+	case services.ErrEventNotFound:
+		rh.handleError(c, http.StatusNotFound, "EVENT_NOT_FOUND", "Event not found", nil)
+	case services.ErrReminderNotFound:
+		rh.handleError(c, http.StatusNotFound, "REMINDER_NOT_FOUND", "Reminder not found", nil)
+	case services.ErrInvalidReminderOffset:
+		rh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid reminder offset", map[string]any{
+			"offset_minutes": "Offset must be between 0 and 40320 minutes (4 weeks)",
+		})
+	case services.ErrTooManyReminders:
+		rh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Too many reminders", map[string]any{
+			"reminders": "A task or event cannot have more than 10 reminders",
+		})
+	case services.ErrReminderRequiresDueDate:
+		rh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Task has no due date", map[string]any{
+			"due_date": "Reminders can only be added to tasks with a due date",
+		})
+	case services.ErrReminderTaskCompleted:
+		rh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Task is completed", map[string]any{
+			"status": "Reminders cannot be added to completed tasks",
+		})
+	case services.ErrNoUpcomingOccurrence:
+		rh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Event has no upcoming occurrence", map[string]any{
+			"start_time": "Reminders can only be added to events that have not started yet",
+		})
+	default:
+		rh.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (rh *ReminderHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
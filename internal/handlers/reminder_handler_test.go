@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupReminderTestRouter serves the task, event and reminder routes on an
+// in-memory database with the full schema
+func setupReminderTestRouter(t *testing.T) *gin.Engine {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	taskRepo := database.NewTaskRepository(db)
+	eventRepo := database.NewEventRepository(db)
+	taskHandler := NewTaskHandler(services.NewTaskService(taskRepo, database.NewTransactionManager(db)))
+	eventHandler := NewEventHandler(services.NewEventService(eventRepo, database.NewCalendarRepository(db), database.NewTransactionManager(db)))
+	reminderHandler := NewReminderHandler(services.NewReminderService(database.NewReminderRepository(db), taskRepo, eventRepo,
+		database.NewTransactionManager(db)))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.POST("/tasks", taskHandler.CreateTask)
+	api.PUT("/tasks/:id", taskHandler.UpdateTask)
+	api.DELETE("/tasks/:id", taskHandler.DeleteTask)
+	api.GET("/tasks/:id/reminders", reminderHandler.GetTaskReminders)
+	api.POST("/tasks/:id/reminders", reminderHandler.CreateTaskReminder)
+	api.POST("/events", eventHandler.CreateEvent)
+	api.GET("/events/:id/reminders", reminderHandler.GetEventReminders)
+	api.POST("/events/:id/reminders", reminderHandler.CreateEventReminder)
+	api.DELETE("/reminders/:id", reminderHandler.DeleteReminder)
+
+	return router
+}
+
+func TestReminderEndpoints(t *testing.T) {
+	router := setupReminderTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	due := time.Now().AddDate(0, 0, 2).UTC().Truncate(time.Second)
+	w := send(http.MethodPost, "/api/tasks", fmt.Sprintf(`{"title": "Pay rent", "due_date": %q}`, due.Format(time.RFC3339)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var task models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+
+	start := time.Now().Add(3 * time.Hour).UTC().Truncate(time.Second)
+	w = send(http.MethodPost, "/api/events", fmt.Sprintf(`{"title": "Dentist", "start_time": %q, "end_time": %q}`,
+		start.Format(time.RFC3339), start.Add(time.Hour).Format(time.RFC3339)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var event models.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &event))
+
+	var taskReminder models.Reminder
+
+	t.Run("create task reminder", func(t *testing.T) {
+		w := send(http.MethodPost, fmt.Sprintf("/api/tasks/%d/reminders", task.ID), `{"offset_minutes": 1440}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &taskReminder))
+		assert.Equal(t, models.ReminderPending, taskReminder.Status)
+		assert.True(t, taskReminder.RemindAt.Equal(due.Add(-24*time.Hour)), "unexpected remind_at %v", taskReminder.RemindAt)
+	})
+
+	t.Run("create event reminder", func(t *testing.T) {
+		w := send(http.MethodPost, fmt.Sprintf("/api/events/%d/reminders", event.ID), `{"offset_minutes": 15}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		w = send(http.MethodGet, fmt.Sprintf("/api/events/%d/reminders", event.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var list struct {
+			Reminders []models.Reminder `json:"reminders"`
+			Total     int               `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		assert.Equal(t, 1, list.Total)
+		assert.True(t, list.Reminders[0].OccurrenceAt.Equal(start))
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			path   string
+			body   string
+			status int
+		}{
+			{"missing offset", fmt.Sprintf("/api/tasks/%d/reminders", task.ID), `{}`, http.StatusBadRequest},
+			{"negative offset", fmt.Sprintf("/api/tasks/%d/reminders", task.ID), `{"offset_minutes": -5}`, http.StatusBadRequest},
+			{"offset too large", fmt.Sprintf("/api/tasks/%d/reminders", task.ID), `{"offset_minutes": 50000}`, http.StatusBadRequest},
+			{"unknown task", "/api/tasks/999/reminders", `{"offset_minutes": 5}`, http.StatusNotFound},
+			{"unknown event", "/api/events/999/reminders", `{"offset_minutes": 5}`, http.StatusNotFound},
+			{"invalid ID", "/api/tasks/abc/reminders", `{"offset_minutes": 5}`, http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := send(http.MethodPost, tt.path, tt.body)
+				assert.Equal(t, tt.status, w.Code, w.Body.String())
+			})
+		}
+	})
+
+	t.Run("moving the due date moves the reminder", func(t *testing.T) {
+		newDue := due.AddDate(0, 0, 1)
+		w := send(http.MethodPut, fmt.Sprintf("/api/tasks/%d", task.ID), fmt.Sprintf(`{"due_date": %q}`, newDue.Format(time.RFC3339)))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = send(http.MethodGet, fmt.Sprintf("/api/tasks/%d/reminders", task.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var list struct {
+			Reminders []models.Reminder `json:"reminders"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		require.Len(t, list.Reminders, 1)
+		assert.True(t, list.Reminders[0].RemindAt.Equal(newDue.Add(-24*time.Hour)))
+	})
+
+	t.Run("delete reminder", func(t *testing.T) {
+		w := send(http.MethodDelete, fmt.Sprintf("/api/reminders/%d", taskReminder.ID), "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = send(http.MethodDelete, fmt.Sprintf("/api/reminders/%d", taskReminder.ID), "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"agenda/internal/api"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleHandler handles HTTP requests for free/busy queries and meeting
+// slot suggestions
+type ScheduleHandler struct {
+	scheduleService services.ScheduleServiceInterface
+}
+
+// NewScheduleHandler creates a new schedule handler instance
+func NewScheduleHandler(scheduleService services.ScheduleServiceInterface) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// FreeBusyRequest represents the HTTP request body for a free/busy query
+type FreeBusyRequest struct {
+	Start       time.Time `json:"start" binding:"required"`
+	End         time.Time `json:"end" binding:"required"`
+	Attendees   []string  `json:"attendees"`
+	CalendarIDs []int     `json:"calendar_ids"`
+}
+
+// SuggestSlotsRequest represents the HTTP request body for meeting slot
+// suggestions
+type SuggestSlotsRequest struct {
+	DurationMinutes   int                   `json:"duration_minutes" binding:"required"`
+	Start             time.Time             `json:"start" binding:"required"`
+	End               time.Time             `json:"end" binding:"required"`
+	WorkingHours      services.WorkingHours `json:"working_hours"`
+	TimeZone          string                `json:"time_zone"`
+	Attendees         []string              `json:"attendees"`
+	OptionalAttendees []string              `json:"optional_attendees"`
+	CalendarIDs       []int                 `json:"calendar_ids"`
+	Limit             int                   `json:"limit"`
+}
+
+// GetFreeBusy handles POST /api/schedule/free-busy
+func (sh *ScheduleHandler) GetFreeBusy(c *gin.Context) {
+	var req FreeBusyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sh.handleValidationError(c, err)
+		return
+	}
+
+	freeBusy, err := sh.scheduleService.GetFreeBusy(c.Request.Context(), services.FreeBusyRequest{
+		Start:       req.Start,
+		End:         req.End,
+		Attendees:   req.Attendees,
+		CalendarIDs: req.CalendarIDs,
+	})
+	if err != nil {
+		sh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, freeBusy)
+}
+
+// SuggestSlots handles POST /api/schedule/suggest
+func (sh *ScheduleHandler) SuggestSlots(c *gin.Context) {
+	var req SuggestSlotsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sh.handleValidationError(c, err)
+		return
+	}
+
+	slots, err := sh.scheduleService.SuggestSlots(c.Request.Context(), services.SuggestSlotsRequest{
+		Duration:          time.Duration(req.DurationMinutes) * time.Minute,
+		Start:             req.Start,
+		End:               req.End,
+		WorkingHours:      req.WorkingHours,
+		TimeZone:          req.TimeZone,
+		Attendees:         req.Attendees,
+		OptionalAttendees: req.OptionalAttendees,
+		CalendarIDs:       req.CalendarIDs,
+		Limit:             req.Limit,
+	})
+	if err != nil {
+		sh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"slots": slots,
+		"total": len(slots),
+	})
+}
+
+// handleValidationError handles request binding errors
+func (sh *ScheduleHandler) handleValidationError(c *gin.Context, err error) {
+	sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+		"validation_error": err.Error(),
+	})
+}
+
+// handleServiceError handles errors from the service layer
+func (sh *ScheduleHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrInvalidScheduleRange:
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid time range", map[string]any{
+			"end": "End must be after start and at most 31 days later",
+		})
+	case services.ErrInvalidSlotDuration:
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid duration", map[string]any{
+			"duration_minutes": "Duration must be positive and at most 24 hours",
+		})
+	case services.ErrInvalidWorkingHours:
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid working hours", map[string]any{
+			"working_hours": "Start and end must be HH:MM times with the start before the end",
+		})
+	case services.ErrInvalidWorkingDay:
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid working days", map[string]any{
+			"working_hours.days": "Days must be weekday codes such as MO, TU or WE",
+		})
+	case services.ErrInvalidScheduleTimeZone:
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid time zone", map[string]any{
+			"time_zone": "Time zone must be an IANA time zone name (e.g., Europe/Paris)",
+		})
+	case services.ErrCalendarNotFound:
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Calendar not found", map[string]any{
+			"calendar_ids": "Every calendar must exist",
+		})
+	default:
+		sh.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (sh *ScheduleHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
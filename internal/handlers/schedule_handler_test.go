@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agenda/internal/api"
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupScheduleTestRouter serves the schedule and event routes on an
+// in-memory database with the full schema
+func setupScheduleTestRouter(t *testing.T) *gin.Engine {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	calendarRepo := database.NewCalendarRepository(db)
+	eventService := services.NewEventService(database.NewEventRepository(db), calendarRepo,
+		database.NewTransactionManager(db))
+	eventHandler := NewEventHandler(eventService)
+	attendeeHandler := NewAttendeeHandler(services.NewAttendeeService(database.NewAttendeeRepository(db),
+		database.NewEventRepository(db), database.NewUserRepository(db), database.NewTransactionManager(db)))
+	scheduleHandler := NewScheduleHandler(services.NewScheduleService(eventService, calendarRepo))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.POST("/events", eventHandler.CreateEvent)
+	api.POST("/events/:id/attendees", attendeeHandler.AddAttendee)
+	api.POST("/schedule/free-busy", scheduleHandler.GetFreeBusy)
+	api.POST("/schedule/suggest", scheduleHandler.SuggestSlots)
+
+	return router
+}
+
+func TestScheduleEndpoints(t *testing.T) {
+	router := setupScheduleTestRouter(t)
+
+	send := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Monday 3 June 2030
+	w := send("/api/events", `{"title": "Standup", "start_time": "2030-06-03T09:00:00Z", "end_time": "2030-06-03T09:30:00Z"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = send("/api/events", `{"title": "Weekly", "start_time": "2030-06-03T10:00:00Z", "end_time": "2030-06-03T11:00:00Z",
+		"recurrence_rule": "FREQ=WEEKLY"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var weekly models.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &weekly))
+	w = send(fmt.Sprintf("/api/events/%d/attendees", weekly.ID), `{"email": "ana@example.com"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	t.Run("free-busy", func(t *testing.T) {
+		w := send("/api/schedule/free-busy", `{"start": "2030-06-03T00:00:00Z", "end": "2030-06-11T00:00:00Z"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var freeBusy services.FreeBusy
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &freeBusy))
+		require.Len(t, freeBusy.Busy, 3)
+		assert.Equal(t, "2030-06-03T09:00:00Z", freeBusy.Busy[0].Start.Format("2006-01-02T15:04:05Z07:00"))
+		// The next occurrence of the weekly series
+		assert.Equal(t, "2030-06-10T10:00:00Z", freeBusy.Busy[2].Start.Format("2006-01-02T15:04:05Z07:00"))
+
+		w = send("/api/schedule/free-busy", `{"start": "2030-06-03T00:00:00Z", "end": "2030-06-04T00:00:00Z", "attendees": ["ana@example.com"]}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &freeBusy))
+		require.Len(t, freeBusy.Busy, 1)
+		assert.Equal(t, 10, freeBusy.Busy[0].Start.Hour())
+	})
+
+	t.Run("suggest", func(t *testing.T) {
+		w := send("/api/schedule/suggest", `{"duration_minutes": 60, "start": "2030-06-03T00:00:00Z", "end": "2030-06-04T00:00:00Z",
+			"working_hours": {"start": "09:00", "end": "12:00"}, "time_zone": "UTC"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Slots []services.SlotSuggestion `json:"slots"`
+			Total int                       `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, 1, response.Total)
+		assert.Equal(t, 11, response.Slots[0].Start.Hour())
+		assert.Equal(t, 12, response.Slots[0].End.Hour())
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		w := send("/api/schedule/free-busy", `{"start": "2030-06-03T00:00:00Z"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = send("/api/schedule/free-busy", `{"start": "2030-06-03T00:00:00Z", "end": "2030-06-03T00:00:00Z"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = send("/api/schedule/free-busy", `{"start": "2030-06-03T00:00:00Z", "end": "2030-06-04T00:00:00Z", "calendar_ids": [42]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = send("/api/schedule/suggest", `{"duration_minutes": 60, "start": "2030-06-03T00:00:00Z", "end": "2030-06-04T00:00:00Z",
+			"working_hours": {"days": ["XX"]}}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response api.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Contains(t, response.Error.Details, "working_hours.days")
+	})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+
+	"agenda/internal/api"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler handles HTTP requests for full-text search
+type SearchHandler struct {
+	searchService services.SearchServiceInterface
+}
+
+// NewSearchHandler creates a new search handler instance
+func NewSearchHandler(searchService services.SearchServiceInterface) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// SearchQuery represents query parameters for searching
+type SearchQuery struct {
+	Query string `form:"q"`
+	Type  string `form:"type"`
+	Limit int    `form:"limit"`
+}
+
+// Search handles GET /api/search
+func (sh *SearchHandler) Search(c *gin.Context) {
+	var query SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+			"validation_error": err.Error(),
+		})
+		return
+	}
+	if query.Limit < 0 {
+		sh.handleError(c, http.StatusBadRequest, "INVALID_LIMIT", "Limit must be a positive number", nil)
+		return
+	}
+
+	results, err := sh.searchService.Search(c.Request.Context(), services.SearchRequest{
+		Query: query.Query,
+		Type:  query.Type,
+		Limit: query.Limit,
+	})
+	if err != nil {
+		sh.handleServiceError(c, err)
+		return
+	}
+	if results == nil {
+		results = []*models.SearchResult{}
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"results": results,
+		"total":   len(results),
+	})
+}
+
+// handleServiceError handles errors from the service layer
+func (sh *SearchHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrSearchQueryRequired:
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Search query is required", map[string]any{
+			"q": "Query parameter q is required",
+		})
+	case services.ErrSearchQueryTooLong:
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Search query too long", map[string]any{
+			"q": "Query cannot exceed 255 characters",
+		})
+	case services.ErrInvalidSearchQuery:
+		sh.handleError(c, http.StatusBadRequest, "INVALID_SEARCH_QUERY", "Invalid search query", map[string]any{
+			"q": `Query must be words, "quoted phrases" or prefix* terms, optionally combined with AND, OR, NOT and parentheses`,
+		})
+	case services.ErrInvalidSearchType:
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid search type", map[string]any{
+			"type": "Type must be task or event",
+		})
+	default:
+		sh.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (sh *SearchHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
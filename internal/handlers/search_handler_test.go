@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSearchTestRouter serves the task, event and search routes on an
+// in-memory database with the full schema
+func setupSearchTestRouter(t *testing.T) *gin.Engine {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	taskRepo := database.NewTaskRepository(db)
+	eventRepo := database.NewEventRepository(db)
+	txManager := database.NewTransactionManager(db)
+	taskHandler := NewTaskHandler(services.NewTaskService(taskRepo, txManager))
+	eventHandler := NewEventHandler(services.NewEventService(eventRepo, database.NewCalendarRepository(db), txManager))
+	searchHandler := NewSearchHandler(services.NewSearchService(database.NewSearchRepository(db), taskRepo, eventRepo))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.POST("/tasks", taskHandler.CreateTask)
+	api.POST("/events", eventHandler.CreateEvent)
+	api.GET("/search", searchHandler.Search)
+
+	return router
+}
+
+func TestSearchEndpoint(t *testing.T) {
+	router := setupSearchTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	search := func(params url.Values) *httptest.ResponseRecorder {
+		return send(http.MethodGet, "/api/search?"+params.Encode(), "")
+	}
+
+	w := send(http.MethodPost, "/api/tasks", `{"title": "Prepare launch checklist", "description": "Review the launch plan"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = send(http.MethodPost, "/api/events",
+		`{"title": "Launch party", "start_time": "2030-05-01T18:00:00Z", "end_time": "2030-05-01T21:00:00Z"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	t.Run("returns tasks and events", func(t *testing.T) {
+		w := search(url.Values{"q": {"launch"}})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Results []struct {
+				Type    string          `json:"type"`
+				Title   string          `json:"title"`
+				Snippet string          `json:"snippet"`
+				Task    json.RawMessage `json:"task"`
+				Event   json.RawMessage `json:"event"`
+			} `json:"results"`
+			Total int `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, 2, response.Total)
+
+		types := map[string]bool{}
+		for _, result := range response.Results {
+			types[result.Type] = true
+			assert.Contains(t, strings.ToLower(result.Title), "<mark>launch</mark>")
+			if result.Type == "task" {
+				assert.NotEmpty(t, result.Task)
+				assert.Equal(t, "Review the <mark>launch</mark> plan", result.Snippet)
+			} else {
+				assert.NotEmpty(t, result.Event)
+			}
+		}
+		assert.Equal(t, map[string]bool{"task": true, "event": true}, types)
+	})
+
+	t.Run("filters by type", func(t *testing.T) {
+		w := search(url.Values{"q": {`"launch party"`}, "type": {"event"}})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"total":1`)
+
+		w = search(url.Values{"q": {"party"}, "type": {"task"}})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"results": [], "total": 0}`, w.Body.String())
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		tests := []struct {
+			params url.Values
+			code   string
+		}{
+			{url.Values{}, "VALIDATION_ERROR"},
+			{url.Values{"q": {`"launch`}}, "INVALID_SEARCH_QUERY"},
+			{url.Values{"q": {"launch"}, "type": {"note"}}, "VALIDATION_ERROR"},
+			{url.Values{"q": {"launch"}, "limit": {"-1"}}, "INVALID_LIMIT"},
+		}
+		for _, tt := range tests {
+			w := search(tt.params)
+			assert.Equal(t, http.StatusBadRequest, w.Code, tt.params.Encode())
+			assert.Contains(t, w.Body.String(), tt.code, tt.params.Encode())
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"agenda/internal/api"
+	"agenda/internal/auth"
+	"agenda/internal/services"
+	"agenda/internal/stream"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamHeartbeat is how often a comment is sent on idle streams so
+// proxies do not close them
+const streamHeartbeat = 25 * time.Second
+
+// StreamHandler handles the Server-Sent Events stream of task and event
+// changes
+type StreamHandler struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+}
+
+// NewStreamHandler creates a new stream handler instance
+func NewStreamHandler(hub *stream.Hub) *StreamHandler {
+	return &StreamHandler{
+		hub:       hub,
+		heartbeat: streamHeartbeat,
+	}
+}
+
+// StreamQuery represents the query parameters of the change stream
+type StreamQuery struct {
+	Types       string `form:"types"`
+	Start       string `form:"start"`
+	End         string `form:"end"`
+	LastEventID string `form:"last_event_id"`
+}
+
+// streamPayload is the data of a change sent on the stream
+type streamPayload struct {
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Stream handles GET /api/stream, a Server-Sent Events stream of the
+// changes made to the tasks and events of the user. Each change is sent as
+// an event named after its change type, such as task.updated.
+//
+// Optional query parameters:
+//   - types: comma-separated entity types (task, event) or change types
+//   - start, end: RFC3339 times only letting through the tasks and events
+//     taking place in the range
+//
+// Clients resuming a stream send the ID of the last event they received in
+// the Last-Event-ID header, or the last_event_id parameter, and are first
+// sent the changes they missed. When some of them are no longer available
+// a reset event is sent instead, telling the client to reload its data.
+func (sh *StreamHandler) Stream(c *gin.Context) {
+	var query StreamQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid query parameters", map[string]any{
+			"validation_error": err.Error(),
+		})
+		return
+	}
+
+	filter, ok := sh.parseFilter(c, query)
+	if !ok {
+		return
+	}
+	if userID, ok := auth.UserIDFromContext(c.Request.Context()); ok {
+		filter.Owner = &userID
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.LastEventID
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid last event ID", map[string]any{
+				"last_event_id": "Last event ID must be the ID of an event of the stream",
+			})
+			return
+		}
+	}
+
+	sub, err := sh.hub.Subscribe(filter, lastID)
+	if err != nil {
+		sh.handleError(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Server is shutting down", nil)
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if sub.Gap {
+		fmt.Fprintf(c.Writer, "id: %d\nevent: reset\ndata: {}\n\n", sub.LastID)
+	} else {
+		for _, msg := range sub.Replay {
+			if err := writeStreamMessage(c.Writer, msg); err != nil {
+				return
+			}
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sh.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case msg, ok := <-sub.Messages():
+			// The hub is shutting down or the client fell behind; the
+			// client reconnects and resumes from its last event
+			if !ok {
+				return
+			}
+			if err := writeStreamMessage(c.Writer, msg); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// parseFilter builds the stream filter from the query parameters, writing
+// the error response when they are invalid
+func (sh *StreamHandler) parseFilter(c *gin.Context, query StreamQuery) (stream.Filter, bool) {
+	var filter stream.Filter
+
+	for _, t := range strings.Split(query.Types, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if t != "task" && t != "event" && !services.IsChangeType(t) {
+			sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid type", map[string]any{
+				"types": "Types must be 'task', 'event' or change types such as 'task.created'",
+			})
+			return filter, false
+		}
+		filter.Types = append(filter.Types, t)
+	}
+
+	if query.Start != "" {
+		start, err := time.Parse(time.RFC3339, query.Start)
+		if err != nil {
+			sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid date format", map[string]any{
+				"start": "Date must be in RFC3339 format (e.g., 2023-01-01T00:00:00Z)",
+			})
+			return filter, false
+		}
+		filter.Start = start
+	}
+	if query.End != "" {
+		end, err := time.Parse(time.RFC3339, query.End)
+		if err != nil {
+			sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid date format", map[string]any{
+				"end": "Date must be in RFC3339 format (e.g., 2023-01-01T00:00:00Z)",
+			})
+			return filter, false
+		}
+		filter.End = end
+	}
+	if !filter.Start.IsZero() && !filter.End.IsZero() && !filter.End.After(filter.Start) {
+		sh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid date range", map[string]any{
+			"end": "End must be after start",
+		})
+		return filter, false
+	}
+
+	return filter, true
+}
+
+// writeStreamMessage writes a change as a Server-Sent Event
+func writeStreamMessage(w io.Writer, msg *stream.Message) error {
+	data, err := json.Marshal(streamPayload{
+		Type:       msg.Type,
+		OccurredAt: msg.OccurredAt,
+		Data:       msg.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, data)
+	return err
+}
+
+// handleError creates a standardized error response
+func (sh *StreamHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agenda/internal/database"
+	"agenda/internal/services"
+	"agenda/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamEvent is a Server-Sent Event read from a stream
+type streamEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// setupStreamTestServer serves the stream, task and event routes on an
+// in-memory database, publishing the changes to the returned hub
+func setupStreamTestServer(t *testing.T) (*httptest.Server, *stream.Hub) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	hub := stream.NewHub(10)
+	publisher := services.NewStreamPublisher(hub)
+	txManager := database.NewTransactionManager(db)
+	taskHandler := NewTaskHandler(services.NewTaskService(database.NewTaskRepository(db), txManager, publisher))
+	eventHandler := NewEventHandler(services.NewEventService(database.NewEventRepository(db),
+		database.NewCalendarRepository(db), txManager, publisher))
+	streamHandler := NewStreamHandler(hub)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.POST("/tasks", taskHandler.CreateTask)
+	api.POST("/events", eventHandler.CreateEvent)
+	api.GET("/stream", streamHandler.Stream)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	t.Cleanup(hub.Close)
+	return server, hub
+}
+
+// openStream connects to the stream and returns a channel of its events,
+// closed when the stream ends
+func openStream(t *testing.T, url string, lastEventID string) <-chan streamEvent {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan streamEvent, 16)
+	go func() {
+		defer resp.Body.Close()
+		defer close(events)
+
+		var event streamEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				events <- event
+				event = streamEvent{}
+			case strings.HasPrefix(line, "id: "):
+				event.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				event.Event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return events
+}
+
+// nextStreamEvent waits for the next event of a stream
+func nextStreamEvent(t *testing.T, events <-chan streamEvent) streamEvent {
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "Expected the stream to stay open")
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a stream event")
+	}
+	return streamEvent{}
+}
+
+func TestStreamEndpoint(t *testing.T) {
+	server, hub := setupStreamTestServer(t)
+
+	post := func(path, body string) {
+		resp, err := http.Post(server.URL+path, "application/json", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	all := openStream(t, server.URL+"/api/stream", "")
+	events := openStream(t, server.URL+"/api/stream?types=event&start=2030-06-01T00:00:00Z&end=2030-07-01T00:00:00Z", "")
+
+	post("/api/tasks", `{"title": "Write report"}`)
+	post("/api/events", `{"title": "Old", "start_time": "2030-05-01T10:00:00Z", "end_time": "2030-05-01T11:00:00Z"}`)
+	post("/api/events", `{"title": "Review", "start_time": "2030-06-03T10:00:00Z", "end_time": "2030-06-03T11:00:00Z"}`)
+
+	var first streamEvent
+	t.Run("changes", func(t *testing.T) {
+		first = nextStreamEvent(t, all)
+		assert.Equal(t, services.ChangeTaskCreated, first.Event)
+		var payload struct {
+			Type string `json:"type"`
+			Data struct {
+				Title string `json:"title"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(first.Data), &payload))
+		assert.Equal(t, services.ChangeTaskCreated, payload.Type)
+		assert.Equal(t, "Write report", payload.Data.Title)
+
+		assert.Equal(t, services.ChangeEventCreated, nextStreamEvent(t, all).Event)
+		assert.Equal(t, services.ChangeEventCreated, nextStreamEvent(t, all).Event)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		event := nextStreamEvent(t, events)
+		assert.Equal(t, services.ChangeEventCreated, event.Event)
+		assert.Contains(t, event.Data, "Review")
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		resumed := openStream(t, server.URL+"/api/stream?types=event", first.ID)
+		assert.Contains(t, nextStreamEvent(t, resumed).Data, "Old")
+		assert.Contains(t, nextStreamEvent(t, resumed).Data, "Review")
+
+		// Resuming from an ID no longer kept asks the client to reload
+		reset := openStream(t, server.URL+"/api/stream", "1")
+		assert.Equal(t, "reset", nextStreamEvent(t, reset).Event)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		for _, query := range []string{"?types=note", "?start=tomorrow", "?start=2030-06-02T00:00:00Z&end=2030-06-01T00:00:00Z"} {
+			resp, err := http.Get(server.URL + "/api/stream" + query)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		hub.Close()
+		select {
+		case _, ok := <-all:
+			assert.False(t, ok, "Expected the stream to end")
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the stream to end")
+		}
+	})
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"agenda/internal/api"
 	"agenda/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -27,6 +31,13 @@ type CreateTaskRequest struct {
 	Title       string     `json:"title" binding:"required"`
 	Description string     `json:"description"`
 	DueDate     *time.Time `json:"due_date"`
+	Priority    string     `json:"priority"`
+	Tags        []string   `json:"tags"`
+	ParentID    *int       `json:"parent_id"`
+	BlockedBy   []int      `json:"blocked_by"`
+
+	RecurrenceRule string `json:"recurrence_rule"`
+	RecurAfterDays int    `json:"recur_after_days"`
 }
 
 // UpdateTaskRequest represents the HTTP request body for updating a task
@@ -35,16 +46,104 @@ type UpdateTaskRequest struct {
 	Description *string    `json:"description"`
 	DueDate     *time.Time `json:"due_date"`
 	Status      *string    `json:"status"`
+	Priority    *string    `json:"priority"`
+	Tags        *[]string  `json:"tags"`
+	ParentID    *int       `json:"parent_id"` // 0 turns a subtask into a top-level task
+	BlockedBy   *[]int     `json:"blocked_by"`
+
+	RecurrenceRule *string `json:"recurrence_rule"`
+	RecurAfterDays *int    `json:"recur_after_days"`
+}
+
+// serviceRequest converts the request to a service request
+func (req CreateTaskRequest) serviceRequest() services.CreateTaskRequest {
+	return services.CreateTaskRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+		Priority:    req.Priority,
+		Tags:        req.Tags,
+		ParentID:    req.ParentID,
+		BlockedBy:   req.BlockedBy,
+
+		RecurrenceRule: req.RecurrenceRule,
+		RecurAfterDays: req.RecurAfterDays,
+	}
+}
+
+// taskPatchFields maps the task members a patch may change to the value
+// that clears them, empty for members that cannot be cleared. A null due
+// date decodes as no change and is turned into ClearDueDate by PatchTask.
+var taskPatchFields = map[string]string{
+	"title":            `""`,
+	"description":      `""`,
+	"due_date":         `null`,
+	"status":           `""`,
+	"priority":         `""`,
+	"tags":             `[]`,
+	"parent_id":        `0`,
+	"blocked_by":       `[]`,
+	"recurrence_rule":  `""`,
+	"recur_after_days": `0`,
+}
+
+// serviceRequest converts the request to a service request
+func (req UpdateTaskRequest) serviceRequest() services.UpdateTaskRequest {
+	return services.UpdateTaskRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+		Status:      req.Status,
+		Priority:    req.Priority,
+		Tags:        req.Tags,
+		ParentID:    req.ParentID,
+		BlockedBy:   req.BlockedBy,
+
+		RecurrenceRule: req.RecurrenceRule,
+		RecurAfterDays: req.RecurAfterDays,
+	}
+}
+
+// BulkTaskRequest represents the HTTP request body of a bulk task request.
+// It holds either a list of operations, or a selection of tasks Op applies
+// to, with Data for updates.
+type BulkTaskRequest struct {
+	Mode       string                 `json:"mode"` // "atomic" (default) or "best_effort"
+	Operations []BulkOperationRequest `json:"operations"`
+
+	Select *TaskSelectionRequest `json:"select"`
+	Op     string                `json:"op"`
+	Data   json.RawMessage       `json:"data"`
+}
+
+// TaskSelectionRequest selects the tasks of a bulk task request
+type TaskSelectionRequest struct {
+	Status    string     `json:"status"`
+	Priority  string     `json:"priority"`
+	DueAfter  *time.Time `json:"due_after"`
+	DueBefore *time.Time `json:"due_before"`
+	Overdue   bool       `json:"overdue"`
+	Search    string     `json:"search"`
+	Tags      []string   `json:"tags"`
+	TagMatch  string     `json:"tag_match"` // "any" or "all"
 }
 
 // TaskListQuery represents query parameters for listing tasks
 type TaskListQuery struct {
 	Status    string `form:"status"`
+	Priority  string `form:"priority"`
 	DueAfter  string `form:"due_after"`
 	DueBefore string `form:"due_before"`
 	Search    string `form:"search"`
+	Tags      string `form:"tags"`      // Comma-separated
+	TagMatch  string `form:"tag_match"` // "any" or "all"
+	Sort      string `form:"sort"`
+	Order     string `form:"order"`
 	Page      int    `form:"page"`
 	PageSize  int    `form:"page_size"`
+	Cursor    string `form:"cursor"`
+	// IncludeTotal defaults to true; false skips counting the tasks
+	IncludeTotal *bool `form:"include_total"`
 }
 
 // ErrorResponse represents an error response
@@ -59,13 +158,44 @@ type ErrorDetail struct {
 	Details map[string]interface{} `json:"details,omitempty"`
 }
 
-// PaginatedResponse represents a paginated response
+// PaginatedResponse represents a paginated response. Page is only set for
+// pages selected by number, and Total and TotalPages are left out when the
+// client skipped the count with include_total=false.
 type PaginatedResponse struct {
 	Data       interface{} `json:"data"`
-	Total      int64       `json:"total"`
-	Page       int         `json:"page"`
+	Total      *int64      `json:"total,omitempty"`
+	Page       int         `json:"page,omitempty"`
 	PageSize   int         `json:"page_size"`
-	TotalPages int         `json:"total_pages"`
+	TotalPages *int        `json:"total_pages,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+}
+
+// newPaginatedResponse builds the response for a page of a list
+func newPaginatedResponse(data interface{}, page, pageSize int, cursor string, includeTotal bool, info services.PageInfo) PaginatedResponse {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	response := PaginatedResponse{
+		Data:       data,
+		PageSize:   pageSize,
+		NextCursor: info.NextCursor,
+		PrevCursor: info.PrevCursor,
+	}
+	if cursor == "" {
+		response.Page = page
+	}
+	if includeTotal {
+		total := info.Total
+		totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+		response.Total = &total
+		response.TotalPages = &totalPages
+	}
+	return response
 }
 
 // CreateTask handles POST /api/tasks
@@ -77,19 +207,104 @@ func (th *TaskHandler) CreateTask(c *gin.Context) {
 	}
 
 	// Convert to service request
-	serviceReq := services.CreateTaskRequest{
-		Title:       req.Title,
-		Description: req.Description,
-		DueDate:     req.DueDate,
-	}
+	serviceReq := req.serviceRequest()
 
-	task, err := th.taskService.CreateTask(c.Request.Context(), serviceReq)
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	task, err := th.taskService.CreateTask(ctx, serviceReq)
 	if err != nil {
 		th.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, task)
+	c.JSON(http.StatusCreated, undoableTask{task, setUndoToken(c, undoToken)})
+}
+
+// BulkTasks handles POST /api/tasks/bulk, applying a list of create,
+// update, delete and complete operations, or one operation to every task
+// matching a selection. Atomic requests apply every operation or none of
+// them; best effort requests apply the operations that succeed.
+func (th *TaskHandler) BulkTasks(c *gin.Context) {
+	var req BulkTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		th.handleValidationError(c, err)
+		return
+	}
+
+	serviceReq := services.BulkTaskRequest{
+		Mode: strings.ToLower(req.Mode),
+		Op:   strings.ToLower(req.Op),
+	}
+	for i, op := range req.Operations {
+		operation := services.BulkTaskOperation{Op: strings.ToLower(op.Op), ID: op.ID}
+		var err error
+		operation.Create, operation.Update, err = decodeTaskBulkData(operation.Op, op.Data)
+		if err != nil {
+			th.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]interface{}{
+				fmt.Sprintf("operations[%d].data", i): err.Error(),
+			})
+			return
+		}
+		serviceReq.Operations = append(serviceReq.Operations, operation)
+	}
+	if req.Select != nil {
+		serviceReq.Select = &services.TaskSelection{
+			Status:    req.Select.Status,
+			Priority:  req.Select.Priority,
+			DueAfter:  req.Select.DueAfter,
+			DueBefore: req.Select.DueBefore,
+			Overdue:   req.Select.Overdue,
+			Search:    req.Select.Search,
+			Tags:      req.Select.Tags,
+			TagMatch:  req.Select.TagMatch,
+		}
+		if serviceReq.Op == services.BulkUpdate && hasBulkData(req.Data) {
+			var update UpdateTaskRequest
+			if err := decodeBulkData(req.Data, &update); err != nil {
+				th.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]interface{}{
+					"data": err.Error(),
+				})
+				return
+			}
+			updateReq := update.serviceRequest()
+			serviceReq.Update = &updateReq
+		}
+	}
+
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	results, err := th.taskService.BulkTasks(ctx, serviceReq)
+	if err != nil {
+		status, detail := bulkServiceError(err, taskServiceError)
+		th.handleError(c, status, detail.Code, detail.Message, detail.Details)
+		return
+	}
+
+	writeBulkResponse(c, serviceReq.Mode, results, undoToken, taskServiceError)
+}
+
+// decodeTaskBulkData decodes the data of a bulk task operation into the
+// request matching its operation
+func decodeTaskBulkData(op string, data json.RawMessage) (*services.CreateTaskRequest, *services.UpdateTaskRequest, error) {
+	if !hasBulkData(data) {
+		return nil, nil, nil
+	}
+
+	switch op {
+	case services.BulkCreate:
+		var req CreateTaskRequest
+		if err := decodeBulkData(data, &req); err != nil {
+			return nil, nil, err
+		}
+		serviceReq := req.serviceRequest()
+		return &serviceReq, nil, nil
+	case services.BulkUpdate:
+		var req UpdateTaskRequest
+		if err := decodeBulkData(data, &req); err != nil {
+			return nil, nil, err
+		}
+		serviceReq := req.serviceRequest()
+		return nil, &serviceReq, nil
+	}
+	return nil, nil, nil
 }
 
 // GetTask handles GET /api/tasks/:id
@@ -105,6 +320,9 @@ func (th *TaskHandler) GetTask(c *gin.Context) {
 		th.handleServiceError(c, err)
 		return
 	}
+	if notModified(c, task.Version) {
+		return
+	}
 
 	c.JSON(http.StatusOK, task)
 }
@@ -124,20 +342,78 @@ func (th *TaskHandler) UpdateTask(c *gin.Context) {
 	}
 
 	// Convert to service request
-	serviceReq := services.UpdateTaskRequest{
-		Title:       req.Title,
-		Description: req.Description,
-		DueDate:     req.DueDate,
-		Status:      req.Status,
+	serviceReq := req.serviceRequest()
+
+	// With If-Match, the update only applies to the version it names
+	if c.GetHeader("If-Match") != "" {
+		current, err := th.taskService.GetTaskByID(c.Request.Context(), id)
+		if err != nil {
+			th.handleServiceError(c, err)
+			return
+		}
+		if preconditionFailed(c, current.Version) {
+			th.handleServiceError(c, services.ErrVersionMismatch)
+			return
+		}
+		serviceReq.Version = &current.Version
 	}
 
-	task, err := th.taskService.UpdateTask(c.Request.Context(), id, serviceReq)
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	task, err := th.taskService.UpdateTask(ctx, id, serviceReq)
 	if err != nil {
 		th.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, task)
+	c.Header("ETag", etag(task.Version))
+	c.JSON(http.StatusOK, undoableTask{task, setUndoToken(c, undoToken)})
+}
+
+// PatchTask handles PATCH /api/tasks/:id with a JSON merge patch or a JSON
+// Patch document. Members set to null, or removed, are cleared.
+func (th *TaskHandler) PatchTask(c *gin.Context) {
+	id, err := th.parseTaskID(c)
+	if err != nil {
+		th.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
+		return
+	}
+
+	current, err := th.taskService.GetTaskByID(c.Request.Context(), id)
+	if err != nil {
+		th.handleServiceError(c, err)
+		return
+	}
+	if preconditionFailed(c, current.Version) {
+		th.handleServiceError(c, services.ErrVersionMismatch)
+		return
+	}
+
+	var req UpdateTaskRequest
+	changes, err := patchChanges(c, current)
+	if err == nil {
+		err = decodeChanges(changes, taskPatchFields, &req)
+	}
+	if err != nil {
+		status, code, message, details := patchError(err)
+		th.handleError(c, status, code, message, details)
+		return
+	}
+
+	// The patch was computed from the current version, so it must not be
+	// applied over a concurrent update
+	serviceReq := req.serviceRequest()
+	serviceReq.ClearDueDate = string(changes["due_date"]) == "null"
+	serviceReq.Version = &current.Version
+
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	task, err := th.taskService.UpdateTask(ctx, id, serviceReq)
+	if err != nil {
+		th.handleServiceError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(task.Version))
+	c.JSON(http.StatusOK, undoableTask{task, setUndoToken(c, undoToken)})
 }
 
 // DeleteTask handles DELETE /api/tasks/:id
@@ -148,12 +424,15 @@ func (th *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	err = th.taskService.DeleteTask(c.Request.Context(), id)
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	err = th.taskService.DeleteTask(ctx, id)
 	if err != nil {
 		th.handleServiceError(c, err)
 		return
 	}
 
+	// The undo token is only sent in the header, as the response has no body
+	setUndoToken(c, undoToken)
 	c.Status(http.StatusNoContent)
 }
 
@@ -167,10 +446,19 @@ func (th *TaskHandler) ListTasks(c *gin.Context) {
 
 	// Parse date filters
 	filters := services.TaskListFilters{
-		Status:   query.Status,
-		Search:   query.Search,
-		Page:     query.Page,
-		PageSize: query.PageSize,
+		Status:    query.Status,
+		Priority:  query.Priority,
+		Search:    query.Search,
+		TagMatch:  query.TagMatch,
+		SortBy:    query.Sort,
+		SortOrder: query.Order,
+		Page:      query.Page,
+		PageSize:  query.PageSize,
+		Cursor:    query.Cursor,
+		SkipTotal: query.IncludeTotal != nil && !*query.IncludeTotal,
+	}
+	if query.Tags != "" {
+		filters.Tags = strings.Split(query.Tags, ",")
 	}
 
 	if query.DueAfter != "" {
@@ -195,67 +483,125 @@ func (th *TaskHandler) ListTasks(c *gin.Context) {
 		filters.DueBefore = &dueBefore
 	}
 
-	tasks, total, err := th.taskService.ListTasks(c.Request.Context(), filters)
+	tasks, info, err := th.taskService.ListTasks(c.Request.Context(), filters)
 	if err != nil {
 		th.handleServiceError(c, err)
 		return
 	}
 
-	// Calculate pagination info
-	page := filters.Page
-	if page < 1 {
-		page = 1
+	c.JSON(http.StatusOK, newPaginatedResponse(tasks, filters.Page, filters.PageSize, filters.Cursor, !filters.SkipTotal, info))
+}
+
+// CompleteTask handles POST /api/tasks/:id/complete
+func (th *TaskHandler) CompleteTask(c *gin.Context) {
+	id, err := th.parseTaskID(c)
+	if err != nil {
+		th.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
+		return
 	}
-	pageSize := filters.PageSize
-	if pageSize <= 0 {
-		pageSize = 20
+
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	task, err := th.taskService.CompleteTask(ctx, id)
+	if err != nil {
+		th.handleServiceError(c, err)
+		return
 	}
 
-	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	c.JSON(http.StatusOK, undoableTask{task, setUndoToken(c, undoToken)})
+}
 
-	response := PaginatedResponse{
-		Data:       tasks,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
+// ReopenTask handles POST /api/tasks/:id/reopen
+func (th *TaskHandler) ReopenTask(c *gin.Context) {
+	id, err := th.parseTaskID(c)
+	if err != nil {
+		th.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
+		return
+	}
+
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	task, err := th.taskService.ReopenTask(ctx, id)
+	if err != nil {
+		th.handleServiceError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, undoableTask{task, setUndoToken(c, undoToken)})
 }
 
-// CompleteTask handles POST /api/tasks/:id/complete
-func (th *TaskHandler) CompleteTask(c *gin.Context) {
+// RestoreTask handles POST /api/tasks/:id/restore
+func (th *TaskHandler) RestoreTask(c *gin.Context) {
 	id, err := th.parseTaskID(c)
 	if err != nil {
 		th.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
 		return
 	}
 
-	task, err := th.taskService.CompleteTask(c.Request.Context(), id)
+	ctx, undoToken := services.WithUndo(c.Request.Context())
+	task, err := th.taskService.RestoreTask(ctx, id)
 	if err != nil {
 		th.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, task)
+	c.JSON(http.StatusOK, undoableTask{task, setUndoToken(c, undoToken)})
 }
 
-// ReopenTask handles POST /api/tasks/:id/reopen
-func (th *TaskHandler) ReopenTask(c *gin.Context) {
+// GetSubtasks handles GET /api/tasks/:id/subtasks
+func (th *TaskHandler) GetSubtasks(c *gin.Context) {
 	id, err := th.parseTaskID(c)
 	if err != nil {
 		th.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
 		return
 	}
 
-	task, err := th.taskService.ReopenTask(c.Request.Context(), id)
+	tasks, err := th.taskService.GetSubtasks(c.Request.Context(), id)
 	if err != nil {
 		th.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, task)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"tasks":     tasks,
+		"parent_id": id,
+		"total":     len(tasks),
+	})
+}
+
+// GetDependencies handles GET /api/tasks/:id/dependencies
+func (th *TaskHandler) GetDependencies(c *gin.Context) {
+	id, err := th.parseTaskID(c)
+	if err != nil {
+		th.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
+		return
+	}
+
+	tree, err := th.taskService.GetDependencyTree(c.Request.Context(), id)
+	if err != nil {
+		th.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetCompletedInstances handles GET /api/tasks/:id/completions
+func (th *TaskHandler) GetCompletedInstances(c *gin.Context) {
+	id, err := th.parseTaskID(c)
+	if err != nil {
+		th.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid task ID", nil)
+		return
+	}
+
+	tasks, err := th.taskService.GetCompletedInstances(c.Request.Context(), id)
+	if err != nil {
+		th.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"tasks": tasks,
+		"total": len(tasks),
+	})
 }
 
 // parseTaskID extracts and validates the task ID from the URL parameter
@@ -280,35 +626,107 @@ func (th *TaskHandler) handleValidationError(c *gin.Context, err error) {
 
 // handleServiceError handles errors from the service layer
 func (th *TaskHandler) handleServiceError(c *gin.Context, err error) {
+	status, detail := taskServiceError(err)
+	th.handleError(c, status, detail.Code, detail.Message, detail.Details)
+}
+
+// taskServiceError maps an error from the task service to the status and
+// error detail of its response
+func taskServiceError(err error) (int, api.ErrorDetail) {
+	var blocked *services.TaskBlockedError
+	if errors.As(err, &blocked) {
+		return http.StatusConflict, api.ErrorDetail{Code: "TASK_BLOCKED", Message: "Task is blocked by pending tasks", Details: map[string]interface{}{
+			"blocked_by": blocked.BlockedBy,
+		}}
+	}
+
 	switch err {
 	case services.ErrTaskNotFound:
-		th.handleError(c, http.StatusNotFound, "TASK_NOT_FOUND", "Task not found", nil)
+		return http.StatusNotFound, api.ErrorDetail{Code: "TASK_NOT_FOUND", Message: "Task not found"}
+	case services.ErrVersionMismatch:
+		return http.StatusPreconditionFailed, api.ErrorDetail{Code: "PRECONDITION_FAILED", Message: "Task was modified since it was read", Details: map[string]interface{}{
+			"if_match": "Fetch the task again and retry with its current ETag",
+		}}
 	case services.ErrTaskTitleRequired:
-		th.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Task title is required", map[string]interface{}{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Task title is required", Details: map[string]interface{}{
 			"title": "Title is required",
-		})
+		}}
 	case services.ErrTaskTitleTooLong:
-		th.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Task title too long", map[string]interface{}{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Task title too long", Details: map[string]interface{}{
 			"title": "Title cannot exceed 255 characters",
-		})
+		}}
 	case services.ErrTaskDescriptionTooLong:
-		th.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Task description too long", map[string]interface{}{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Task description too long", Details: map[string]interface{}{
 			"description": "Description cannot exceed 1000 characters",
-		})
+		}}
 	case services.ErrInvalidTaskStatus:
-		th.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid task status", map[string]interface{}{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid task status", Details: map[string]interface{}{
 			"status": "Status must be 'pending' or 'completed'",
-		})
+		}}
 	case services.ErrDueDateInPast:
-		th.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Due date cannot be in the past", map[string]interface{}{
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Due date cannot be in the past", Details: map[string]interface{}{
 			"due_date": "Due date must be in the future",
-		})
+		}}
+	case services.ErrInvalidCursor:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "INVALID_CURSOR", Message: "Invalid cursor", Details: map[string]interface{}{
+			"cursor": "Cursor must be a next_cursor or prev_cursor returned by the same list and sort",
+		}}
 	case services.ErrTaskAlreadyCompleted:
-		th.handleError(c, http.StatusConflict, "TASK_ALREADY_COMPLETED", "Task is already completed", nil)
+		return http.StatusConflict, api.ErrorDetail{Code: "TASK_ALREADY_COMPLETED", Message: "Task is already completed"}
 	case services.ErrTaskAlreadyPending:
-		th.handleError(c, http.StatusConflict, "TASK_ALREADY_PENDING", "Task is already pending", nil)
+		return http.StatusConflict, api.ErrorDetail{Code: "TASK_ALREADY_PENDING", Message: "Task is already pending"}
+	case services.ErrTaskNotDeleted:
+		return http.StatusConflict, api.ErrorDetail{Code: "TASK_NOT_DELETED", Message: "Task is not in the trash"}
+	case services.ErrInvalidTaskPriority:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid task priority", Details: map[string]interface{}{
+			"priority": "Priority must be 'low', 'medium', 'high' or 'urgent'",
+		}}
+	case services.ErrInvalidTaskTag, services.ErrTooManyTaskTags:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid task tags", Details: map[string]interface{}{
+			"tags": err.Error(),
+		}}
+	case services.ErrInvalidTagMatch:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid tag match", Details: map[string]interface{}{
+			"tag_match": "Tag match must be 'any' or 'all'",
+		}}
+	case services.ErrInvalidTaskSort:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid sort field", Details: map[string]interface{}{
+			"sort": "Sort must be 'created_at', 'updated_at', 'due_date', 'priority' or 'title'",
+		}}
+	case services.ErrInvalidSortOrder:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid sort order", Details: map[string]interface{}{
+			"order": "Order must be 'asc' or 'desc'",
+		}}
+	case services.ErrParentTaskNotFound:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Parent task not found", Details: map[string]interface{}{
+			"parent_id": "Parent must be an existing task",
+		}}
+	case services.ErrBlockingTaskNotFound:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Blocking task not found", Details: map[string]interface{}{
+			"blocked_by": "Blockers must be existing tasks",
+		}}
+	case services.ErrTaskHierarchyCycle:
+		return http.StatusConflict, api.ErrorDetail{Code: "HIERARCHY_CYCLE", Message: "Task cannot be a subtask of itself or of its own subtasks"}
+	case services.ErrDependencyCycle:
+		return http.StatusConflict, api.ErrorDetail{Code: "DEPENDENCY_CYCLE", Message: "Task dependencies cannot form a cycle"}
+	case services.ErrInvalidRecurrenceRule:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid recurrence rule", Details: map[string]interface{}{
+			"recurrence_rule": "Recurrence rule must be a valid RFC 5545 RRULE",
+		}}
+	case services.ErrInvalidRecurAfterDays:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Invalid recurrence delay", Details: map[string]interface{}{
+			"recur_after_days": "Days after completion must be between 0 and 3650",
+		}}
+	case services.ErrConflictingRecurrence:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Conflicting recurrence", Details: map[string]interface{}{
+			"recurrence_rule": "Set either recurrence_rule or recur_after_days, not both",
+		}}
+	case services.ErrRecurrenceRequiresDueDate:
+		return http.StatusBadRequest, api.ErrorDetail{Code: "VALIDATION_ERROR", Message: "Recurring task needs a due date", Details: map[string]interface{}{
+			"due_date": "Tasks repeating on a schedule need a due date",
+		}}
 	default:
-		th.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return http.StatusInternalServerError, api.ErrorDetail{Code: "INTERNAL_ERROR", Message: "Internal server error"}
 	}
 }
 
@@ -32,9 +32,63 @@ func setupTestDB(t *testing.T) *sql.DB {
 		description TEXT,
 		due_date DATETIME,
 		status TEXT NOT NULL DEFAULT 'pending',
+		priority TEXT NOT NULL DEFAULT 'medium',
+		parent_id INTEGER,
+		recurrence_rule TEXT NOT NULL DEFAULT '',
+		recur_after_days INTEGER NOT NULL DEFAULT 0,
+		series_id INTEGER,
+		completed_at DATETIME,
+		uid TEXT NOT NULL DEFAULT '',
+		user_id INTEGER,
+		version INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		deleted_at DATETIME
+	);
+
+	CREATE TABLE tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE
+	);
+
+	CREATE TABLE task_tags (
+		task_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		PRIMARY KEY (task_id, tag_id)
+	);
+
+	CREATE TABLE task_dependencies (
+		task_id INTEGER NOT NULL,
+		blocked_by_id INTEGER NOT NULL,
+		PRIMARY KEY (task_id, blocked_by_id)
+	);
+
+	CREATE TABLE reminders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER,
+		event_id INTEGER,
+		offset_minutes INTEGER NOT NULL DEFAULT 0,
+		occurrence_at DATETIME NOT NULL,
+		remind_at DATETIME NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		sent_at DATETIME,
+		user_id INTEGER,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
+
+	CREATE VIRTUAL TABLE tasks_fts USING fts5(title, description, tokenize = "unicode61 remove_diacritics 2");
+	CREATE TRIGGER tasks_fts_insert AFTER INSERT ON tasks BEGIN
+		INSERT INTO tasks_fts (rowid, title, description) VALUES (new.id, new.title, COALESCE(new.description, ''));
+	END;
+	CREATE TRIGGER tasks_fts_update AFTER UPDATE OF title, description ON tasks BEGIN
+		UPDATE tasks_fts SET title = new.title, description = COALESCE(new.description, '') WHERE rowid = new.id;
+	END;
+	CREATE TRIGGER tasks_fts_delete AFTER DELETE ON tasks BEGIN
+		DELETE FROM tasks_fts WHERE rowid = old.id;
+	END;
 	`
 	_, err = db.Exec(schema)
 	require.NoError(t, err)
@@ -45,7 +99,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 func setupTestHandler(t *testing.T) (*TaskHandler, *sql.DB) {
 	db := setupTestDB(t)
 	taskRepo := database.NewTaskRepository(db)
-	taskService := services.NewTaskService(taskRepo)
+	taskService := services.NewTaskService(taskRepo, database.NewTransactionManager(db))
 	handler := NewTaskHandler(taskService)
 	return handler, db
 }
@@ -59,11 +113,17 @@ func setupTestRouter(handler *TaskHandler) *gin.Engine {
 	{
 		tasks.GET("", handler.ListTasks)
 		tasks.POST("", handler.CreateTask)
+		tasks.POST("/bulk", handler.BulkTasks)
 		tasks.GET("/:id", handler.GetTask)
 		tasks.PUT("/:id", handler.UpdateTask)
+		tasks.PATCH("/:id", handler.PatchTask)
 		tasks.DELETE("/:id", handler.DeleteTask)
 		tasks.POST("/:id/complete", handler.CompleteTask)
 		tasks.POST("/:id/reopen", handler.ReopenTask)
+		tasks.POST("/:id/restore", handler.RestoreTask)
+		tasks.GET("/:id/subtasks", handler.GetSubtasks)
+		tasks.GET("/:id/dependencies", handler.GetDependencies)
+		tasks.GET("/:id/completions", handler.GetCompletedInstances)
 	}
 	
 	return router
@@ -273,6 +333,254 @@ func TestUpdateTask(t *testing.T) {
 	}
 }
 
+func TestTaskETags(t *testing.T) {
+	handler, db := setupTestHandler(t)
+	defer db.Close()
+	router := setupTestRouter(handler)
+
+	task := createTestTask(t, handler)
+	path := fmt.Sprintf("/api/tasks/%d", task.ID)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	put := func(ifMatch, title string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(UpdateTaskRequest{Title: stringPtr(title)})
+		req := httptest.NewRequest(http.MethodPut, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	original := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, original)
+
+	t.Run("conditional get", func(t *testing.T) {
+		w := get(original)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, original, w.Header().Get("ETag"))
+
+		assert.Equal(t, http.StatusNotModified, get(`"7", W/`+original).Code)
+		assert.Equal(t, http.StatusOK, get(`"7"`).Code)
+	})
+
+	w = put(original, "First writer")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	updated := w.Header().Get("ETag")
+	assert.Equal(t, `"2"`, updated)
+
+	t.Run("stale if-match", func(t *testing.T) {
+		w := put(original, "Second writer")
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, updated, w.Header().Get("ETag"))
+
+		var errorResp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+		assert.Equal(t, "PRECONDITION_FAILED", errorResp.Error.Code)
+
+		// Weak tags never match If-Match
+		assert.Equal(t, http.StatusPreconditionFailed, put("W/"+updated, "Second writer").Code)
+
+		current, err := handler.taskService.GetTaskByID(context.Background(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "First writer", current.Title)
+	})
+
+	t.Run("changed resource", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get(original).Code)
+	})
+
+	t.Run("wildcard and unconditional updates", func(t *testing.T) {
+		w := put("*", "Any version")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		w = put("", "No precondition")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("missing task", func(t *testing.T) {
+		body, _ := json.Marshal(UpdateTaskRequest{Title: stringPtr("Missing")})
+		req := httptest.NewRequest(http.MethodPut, "/api/tasks/999", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", original)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestPatchTask(t *testing.T) {
+	handler, db := setupTestHandler(t)
+	defer db.Close()
+	router := setupTestRouter(handler)
+
+	due := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	task, err := handler.taskService.CreateTask(context.Background(), services.CreateTaskRequest{
+		Title:       "Patch me",
+		Description: "Original description",
+		DueDate:     &due,
+		Priority:    models.TaskPriorityHigh,
+		Tags:        []string{"work", "urgent"},
+	})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/api/tasks/%d", task.ID)
+
+	patch := func(contentType, body string) (*httptest.ResponseRecorder, models.Task) {
+		req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var patched models.Task
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+		}
+		return w, patched
+	}
+	errorCode := func(w *httptest.ResponseRecorder) string {
+		var errorResp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+		return errorResp.Error.Code
+	}
+
+	t.Run("merge patch leaves other fields alone", func(t *testing.T) {
+		w, patched := patch("application/merge-patch+json", `{"title": "Patched"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "Patched", patched.Title)
+		assert.Equal(t, "Original description", patched.Description)
+		require.NotNil(t, patched.DueDate)
+		assert.True(t, due.Equal(*patched.DueDate))
+		assert.Equal(t, models.TaskPriorityHigh, patched.Priority)
+		assert.Equal(t, fmt.Sprintf(`"%d"`, patched.Version), w.Header().Get("ETag"))
+	})
+
+	t.Run("merge patch null clears fields", func(t *testing.T) {
+		w, patched := patch("application/merge-patch+json", `{"due_date": null, "description": null, "tags": null}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Nil(t, patched.DueDate)
+		assert.Empty(t, patched.Description)
+		assert.Empty(t, patched.Tags)
+		assert.Equal(t, "Patched", patched.Title)
+
+		stored, err := handler.taskService.GetTaskByID(context.Background(), task.ID)
+		require.NoError(t, err)
+		assert.Nil(t, stored.DueDate)
+	})
+
+	t.Run("plain JSON is a merge patch", func(t *testing.T) {
+		w, patched := patch("application/json", `{"priority": "low"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, models.TaskPriorityLow, patched.Priority)
+	})
+
+	t.Run("json patch", func(t *testing.T) {
+		w, patched := patch("application/json-patch+json", `[
+			{"op": "test", "path": "/title", "value": "Patched"},
+			{"op": "replace", "path": "/title", "value": "From JSON Patch"},
+			{"op": "add", "path": "/tags/-", "value": "home"},
+			{"op": "add", "path": "/due_date", "value": "`+due.Format(time.RFC3339)+`"}
+		]`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "From JSON Patch", patched.Title)
+		assert.Equal(t, []string{"home"}, patched.Tags)
+		require.NotNil(t, patched.DueDate)
+		assert.True(t, due.Equal(*patched.DueDate))
+
+		w, patched = patch("application/json-patch+json", `[{"op": "remove", "path": "/due_date"}]`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Nil(t, patched.DueDate)
+	})
+
+	t.Run("failed test operation", func(t *testing.T) {
+		w, _ := patch("application/json-patch+json", `[
+			{"op": "test", "path": "/title", "value": "Something else"},
+			{"op": "replace", "path": "/title", "value": "Never applied"}
+		]`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "PATCH_TEST_FAILED", errorCode(w))
+	})
+
+	t.Run("same validation as updates", func(t *testing.T) {
+		w, _ := patch("application/merge-patch+json", `{"title": null}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "VALIDATION_ERROR", errorCode(w))
+		assert.Contains(t, w.Body.String(), "Title is required")
+
+		w, _ = patch("application/merge-patch+json", `{"priority": "extreme"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "VALIDATION_ERROR", errorCode(w))
+
+		w, _ = patch("application/merge-patch+json", `{"due_date": "2001-01-01T00:00:00Z"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "VALIDATION_ERROR", errorCode(w))
+	})
+
+	t.Run("read-only fields", func(t *testing.T) {
+		for _, body := range []string{`{"id": 42}`, `{"version": 99}`, `{"created_at": null}`, `{"color": "red"}`} {
+			w, _ := patch("application/merge-patch+json", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+			assert.Equal(t, "VALIDATION_ERROR", errorCode(w), body)
+		}
+
+		// Unchanged members may be sent back as they are
+		current, err := handler.taskService.GetTaskByID(context.Background(), task.ID)
+		require.NoError(t, err)
+		w, _ := patch("application/merge-patch+json", fmt.Sprintf(`{"id": %d, "title": "Echoed"}`, current.ID))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("invalid patches", func(t *testing.T) {
+		for contentType, body := range map[string]string{
+			"application/merge-patch+json": `{"title": `,
+			"application/json-patch+json":  `{"op": "replace", "path": "/title", "value": "x"}`,
+		} {
+			w, _ := patch(contentType, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+			assert.Equal(t, "INVALID_PATCH", errorCode(w), body)
+		}
+
+		w, _ := patch("application/json-patch+json", `[{"op": "remove", "path": "/missing"}]`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "INVALID_PATCH", errorCode(w))
+
+		w, _ = patch("application/merge-patch+json", `["not", "an", "object"]`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "INVALID_PATCH", errorCode(w))
+	})
+
+	t.Run("if-match", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(`{"title": "Stale"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", `"1"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("missing task", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPatch, "/api/tasks/999", bytes.NewBufferString(`{"title": "Missing"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestDeleteTask(t *testing.T) {
 	handler, db := setupTestHandler(t)
 	defer db.Close()
@@ -389,12 +697,188 @@ func TestListTasks(t *testing.T) {
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				require.NoError(t, err)
 				assert.NotNil(t, response.Data)
-				assert.GreaterOrEqual(t, response.Total, int64(0))
+				require.NotNil(t, response.Total)
+				assert.GreaterOrEqual(t, *response.Total, int64(0))
 			}
 		})
 	}
 }
 
+func TestListTasks_Cursor(t *testing.T) {
+	handler, db := setupTestHandler(t)
+	defer db.Close()
+	router := setupTestRouter(handler)
+
+	for _, title := range []string{"Alpha", "Bravo", "Charlie", "Delta", "Echo"} {
+		_, err := handler.taskService.CreateTask(context.Background(), services.CreateTaskRequest{Title: title})
+		require.NoError(t, err)
+	}
+
+	list := func(query string) (*httptest.ResponseRecorder, map[string]any) {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+	titles := func(response map[string]any) []string {
+		var titles []string
+		for _, task := range response["data"].([]any) {
+			titles = append(titles, task.(map[string]any)["title"].(string))
+		}
+		return titles
+	}
+
+	w, first := list("?sort=title&page_size=2&include_total=false")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"Alpha", "Bravo"}, titles(first))
+	assert.NotContains(t, first, "total")
+	assert.NotContains(t, first, "total_pages")
+	assert.NotContains(t, first, "prev_cursor")
+	require.Contains(t, first, "next_cursor")
+
+	w, second := list("?sort=title&page_size=2&cursor=" + first["next_cursor"].(string))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"Charlie", "Delta"}, titles(second))
+	assert.Equal(t, float64(5), second["total"])
+	assert.NotContains(t, second, "page")
+
+	w, previous := list("?sort=title&page_size=2&cursor=" + second["prev_cursor"].(string))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"Alpha", "Bravo"}, titles(previous))
+
+	// Cursors only apply to the ordering they were created for
+	w, _ = list("?sort=due_date&page_size=2&cursor=" + first["next_cursor"].(string))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_CURSOR")
+
+	w, _ = list("?cursor=garbage")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_CURSOR")
+}
+
+func TestListTasks_PriorityTagsAndSort(t *testing.T) {
+	handler, db := setupTestHandler(t)
+	defer db.Close()
+	router := setupTestRouter(handler)
+
+	for _, req := range []services.CreateTaskRequest{
+		{Title: "Fix login", Priority: "urgent", Tags: []string{"backend", "bug"}},
+		{Title: "Write docs", Priority: "low", Tags: []string{"docs"}},
+		{Title: "Refactor", Priority: "high", Tags: []string{"backend"}},
+	} {
+		_, err := handler.taskService.CreateTask(context.Background(), req)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		expectedStatus int
+		expectedError  string
+		expectedTitles []string
+	}{
+		{
+			name:           "sort by priority",
+			queryParams:    "?sort=priority",
+			expectedStatus: http.StatusOK,
+			expectedTitles: []string{"Fix login", "Refactor", "Write docs"},
+		},
+		{
+			name:           "sort by title descending",
+			queryParams:    "?sort=title&order=desc",
+			expectedStatus: http.StatusOK,
+			expectedTitles: []string{"Write docs", "Refactor", "Fix login"},
+		},
+		{
+			name:           "filter by priority",
+			queryParams:    "?priority=low",
+			expectedStatus: http.StatusOK,
+			expectedTitles: []string{"Write docs"},
+		},
+		{
+			name:           "filter by any tag",
+			queryParams:    "?tags=bug,docs&sort=title",
+			expectedStatus: http.StatusOK,
+			expectedTitles: []string{"Fix login", "Write docs"},
+		},
+		{
+			name:           "filter by all tags",
+			queryParams:    "?tags=backend,bug&tag_match=all",
+			expectedStatus: http.StatusOK,
+			expectedTitles: []string{"Fix login"},
+		},
+		{
+			name:           "invalid sort field",
+			queryParams:    "?sort=id",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+		{
+			name:           "invalid tag match",
+			queryParams:    "?tags=bug&tag_match=some",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/tasks"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResp ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+				assert.Equal(t, tt.expectedError, errorResp.Error.Code)
+				return
+			}
+
+			var response struct {
+				Data []models.Task `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			var titles []string
+			for _, task := range response.Data {
+				titles = append(titles, task.Title)
+			}
+			assert.Equal(t, tt.expectedTitles, titles)
+		})
+	}
+}
+
+func TestCreateTask_PriorityAndTags(t *testing.T) {
+	handler, db := setupTestHandler(t)
+	defer db.Close()
+	router := setupTestRouter(handler)
+
+	body := `{"title": "Tagged", "priority": "high", "tags": ["Work", "work", "q3"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var task models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+	assert.Equal(t, "high", task.Priority)
+	assert.Equal(t, []string{"work", "q3"}, task.Tags)
+
+	body = `{"title": "Bad", "priority": "critical"}`
+	req = httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestCompleteTask(t *testing.T) {
 	handler, db := setupTestHandler(t)
 	defer db.Close()
@@ -511,6 +995,153 @@ func TestReopenTask(t *testing.T) {
 	}
 }
 
+func TestRestoreTask(t *testing.T) {
+	handler, db := setupTestHandler(t)
+	defer db.Close()
+	router := setupTestRouter(handler)
+
+	task := createTestTask(t, handler)
+	require.NoError(t, handler.taskService.DeleteTask(context.Background(), task.ID))
+
+	restore := func(taskID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+taskID+"/restore", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := restore(fmt.Sprintf("%d", task.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var restoredTask models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restoredTask))
+	assert.Equal(t, task.ID, restoredTask.ID)
+	assert.Nil(t, restoredTask.DeletedAt)
+
+	_, err := handler.taskService.GetTaskByID(context.Background(), task.ID)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		taskID         string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"task not in the trash", fmt.Sprintf("%d", task.ID), http.StatusConflict, "TASK_NOT_DELETED"},
+		{"non-existent task", "999", http.StatusNotFound, "TASK_NOT_FOUND"},
+		{"invalid task ID", "invalid", http.StatusBadRequest, "INVALID_ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := restore(tt.taskID)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResp ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+			assert.Equal(t, tt.expectedError, errorResp.Error.Code)
+		})
+	}
+}
+
+func TestBulkTasks(t *testing.T) {
+	handler, db := setupTestHandler(t)
+	defer db.Close()
+	router := setupTestRouter(handler)
+
+	bulk := func(t *testing.T, body string) (int, BulkResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/bulk", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response BulkResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	countTasks := func(t *testing.T) int {
+		var count int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tasks WHERE deleted_at IS NULL").Scan(&count))
+		return count
+	}
+
+	task := createTestTask(t, handler)
+	operations := fmt.Sprintf(`"operations": [
+		{"op": "create", "data": {"title": "Sprint review", "tags": ["sprint"]}},
+		{"op": "complete", "id": %d},
+		{"op": "complete", "id": 999}
+	]`, task.ID)
+
+	t.Run("atomic", func(t *testing.T) {
+		status, response := bulk(t, `{`+operations+`}`)
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Equal(t, services.BulkAtomic, response.Mode)
+		assert.Equal(t, 0, response.Succeeded)
+		assert.Equal(t, 3, response.Failed)
+		require.Len(t, response.Results, 3)
+		for _, result := range response.Results[:2] {
+			assert.Equal(t, http.StatusFailedDependency, result.Status)
+			assert.Equal(t, "BULK_ABORTED", result.Error.Code)
+			assert.Nil(t, result.Data)
+		}
+		assert.Equal(t, http.StatusNotFound, response.Results[2].Status)
+		assert.Equal(t, "TASK_NOT_FOUND", response.Results[2].Error.Code)
+
+		// Nothing was applied
+		assert.Equal(t, 1, countTasks(t))
+		current, err := handler.taskService.GetTaskByID(context.Background(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatusPending, current.Status)
+	})
+
+	t.Run("best effort", func(t *testing.T) {
+		status, response := bulk(t, `{"mode": "best_effort", `+operations+`}`)
+		assert.Equal(t, http.StatusMultiStatus, status)
+		assert.Equal(t, 2, response.Succeeded)
+		assert.Equal(t, 1, response.Failed)
+		require.Len(t, response.Results, 3)
+		assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+		assert.NotZero(t, response.Results[0].ID)
+		assert.Equal(t, http.StatusOK, response.Results[1].Status)
+		assert.Equal(t, task.ID, response.Results[1].ID)
+		assert.Equal(t, "TASK_NOT_FOUND", response.Results[2].Error.Code)
+
+		assert.Equal(t, 2, countTasks(t))
+	})
+
+	t.Run("selection", func(t *testing.T) {
+		late, err := handler.taskService.CreateTask(context.Background(), services.CreateTaskRequest{Title: "Late", Tags: []string{"sprint"}})
+		require.NoError(t, err)
+		other, err := handler.taskService.CreateTask(context.Background(), services.CreateTaskRequest{Title: "Late elsewhere", Tags: []string{"ops"}})
+		require.NoError(t, err)
+		yesterday := time.Now().AddDate(0, 0, -1)
+		_, err = db.Exec("UPDATE tasks SET due_date = ? WHERE id IN (?, ?)", yesterday, late.ID, other.ID)
+		require.NoError(t, err)
+
+		status, response := bulk(t, `{"select": {"overdue": true, "tags": ["sprint"]}, "op": "complete"}`)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, response.Results, 1)
+		assert.Equal(t, late.ID, response.Results[0].ID)
+
+		current, err := handler.taskService.GetTaskByID(context.Background(), other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatusPending, current.Status)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		for _, body := range []string{
+			`{}`,
+			`{"mode": "sometimes", "operations": [{"op": "delete", "id": 1}]}`,
+			`{"operations": [{"op": "create", "data": {"description": "No title"}}]}`,
+			`{"select": {}, "op": "complete"}`,
+			`{"select": {"status": "pending"}, "op": "create"}`,
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/api/tasks/bulk", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+}
+
 // Helper functions
 
 func createTestTask(t *testing.T, handler *TaskHandler) *models.Task {
@@ -529,4 +1160,138 @@ func timePtr(t time.Time) *time.Time {
 
 func stringPtr(s string) *string {
 	return &s
-}
\ No newline at end of file
+}
+
+func TestTaskDependencies(t *testing.T) {
+	handler, db := setupTestHandler(t)
+	defer db.Close()
+	router := setupTestRouter(handler)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	blocker := createTestTask(t, handler)
+
+	w := send(http.MethodPost, "/api/tasks", fmt.Sprintf(`{"title": "Blocked", "blocked_by": [%d]}`, blocker.ID))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var blocked models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &blocked))
+	assert.Equal(t, []int{blocker.ID}, blocked.BlockedBy)
+
+	w = send(http.MethodPost, "/api/tasks", fmt.Sprintf(`{"title": "Subtask", "parent_id": %d}`, blocked.ID))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	t.Run("completing a blocked task conflicts", func(t *testing.T) {
+		w := send(http.MethodPost, fmt.Sprintf("/api/tasks/%d/complete", blocked.ID), "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		var errorResp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+		assert.Equal(t, "TASK_BLOCKED", errorResp.Error.Code)
+		assert.Equal(t, []interface{}{float64(blocker.ID)}, errorResp.Error.Details["blocked_by"])
+	})
+
+	t.Run("cycles are rejected", func(t *testing.T) {
+		w := send(http.MethodPut, fmt.Sprintf("/api/tasks/%d", blocker.ID), fmt.Sprintf(`{"blocked_by": [%d]}`, blocked.ID))
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "DEPENDENCY_CYCLE")
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		w := send(http.MethodPost, "/api/tasks", `{"title": "Orphan", "parent_id": 999}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("subtasks", func(t *testing.T) {
+		w := send(http.MethodGet, fmt.Sprintf("/api/tasks/%d/subtasks", blocked.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Tasks []models.Task `json:"tasks"`
+			Total int           `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, 1, response.Total)
+		assert.Equal(t, "Subtask", response.Tasks[0].Title)
+	})
+
+	t.Run("dependency tree", func(t *testing.T) {
+		w := send(http.MethodGet, fmt.Sprintf("/api/tasks/%d/dependencies", blocked.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var tree services.TaskDependencyNode
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tree))
+		assert.Equal(t, blocked.ID, tree.Task.ID)
+		assert.Equal(t, models.SubtaskProgress{Total: 1, Completed: 0}, tree.Task.Subtasks)
+		require.Len(t, tree.BlockedBy, 1)
+		assert.Equal(t, blocker.ID, tree.BlockedBy[0].Task.ID)
+		assert.Empty(t, tree.BlockedBy[0].BlockedBy)
+	})
+
+	t.Run("completing the blocker unblocks the task", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, send(http.MethodPost, fmt.Sprintf("/api/tasks/%d/complete", blocker.ID), "").Code)
+		assert.Equal(t, http.StatusOK, send(http.MethodPost, fmt.Sprintf("/api/tasks/%d/complete", blocked.ID), "").Code)
+	})
+}
+
+func TestCompleteRecurringTask(t *testing.T) {
+	handler, db := setupTestHandler(t)
+	defer db.Close()
+	router := setupTestRouter(handler)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	due := time.Now().AddDate(0, 0, 1).UTC().Truncate(time.Second)
+	body := fmt.Sprintf(`{"title": "Submit timesheet", "due_date": %q, "recurrence_rule": "FREQ=WEEKLY"}`, due.Format(time.RFC3339))
+	w := send(http.MethodPost, "/api/tasks", body)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var task models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+	assert.Equal(t, "FREQ=WEEKLY", task.RecurrenceRule)
+
+	w = send(http.MethodPost, fmt.Sprintf("/api/tasks/%d/complete", task.ID), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var completed models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &completed))
+	assert.NotNil(t, completed.CompletedAt)
+
+	// The next instance is pending and due a week later
+	w = send(http.MethodGet, "/api/tasks?status=pending", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []models.Task `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	next := list.Data[0]
+	assert.NotEqual(t, task.ID, next.ID)
+	require.NotNil(t, next.SeriesID)
+	assert.Equal(t, task.ID, *next.SeriesID)
+	require.NotNil(t, next.DueDate)
+	assert.True(t, next.DueDate.Equal(due.AddDate(0, 0, 7)), "unexpected due date %v", next.DueDate)
+
+	// The completed instance stays in the history of the series
+	w = send(http.MethodGet, fmt.Sprintf("/api/tasks/%d/completions", next.ID), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var history struct {
+		Tasks []models.Task `json:"tasks"`
+		Total int           `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Equal(t, 1, history.Total)
+	assert.Equal(t, task.ID, history.Tasks[0].ID)
+
+	w = send(http.MethodPost, "/api/tasks", `{"title": "Bad", "recurrence_rule": "FREQ=DAILY"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
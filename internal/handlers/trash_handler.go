@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"agenda/internal/api"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrashHandler handles HTTP requests for the deleted tasks and events
+type TrashHandler struct {
+	trashService services.TrashServiceInterface
+}
+
+// NewTrashHandler creates a new trash handler instance
+func NewTrashHandler(trashService services.TrashServiceInterface) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// TrashQuery represents query parameters for listing the trash
+type TrashQuery struct {
+	Type string `form:"type"`
+}
+
+// ListTrash handles GET /api/trash
+func (th *TrashHandler) ListTrash(c *gin.Context) {
+	var query TrashQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		th.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+			"validation_error": err.Error(),
+		})
+		return
+	}
+
+	items, err := th.trashService.ListTrash(c.Request.Context(), query.Type)
+	if err != nil {
+		th.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// handleServiceError handles errors from the service layer
+func (th *TrashHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrInvalidTrashType:
+		th.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid trash type", map[string]any{
+			"type": "Type must be task or event",
+		})
+	default:
+		th.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (th *TrashHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
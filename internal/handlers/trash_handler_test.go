@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTrashTestRouter serves the task, event and trash routes on an
+// in-memory database with the full schema
+func setupTrashTestRouter(t *testing.T) *gin.Engine {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	taskRepo := database.NewTaskRepository(db)
+	eventRepo := database.NewEventRepository(db)
+	txManager := database.NewTransactionManager(db)
+	taskHandler := NewTaskHandler(services.NewTaskService(taskRepo, txManager))
+	eventHandler := NewEventHandler(services.NewEventService(eventRepo, database.NewCalendarRepository(db), txManager))
+	trashHandler := NewTrashHandler(services.NewTrashService(taskRepo, eventRepo))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.POST("/tasks", taskHandler.CreateTask)
+	api.DELETE("/tasks/:id", taskHandler.DeleteTask)
+	api.POST("/tasks/:id/restore", taskHandler.RestoreTask)
+	api.POST("/events", eventHandler.CreateEvent)
+	api.DELETE("/events/:id", eventHandler.DeleteEvent)
+	api.GET("/trash", trashHandler.ListTrash)
+
+	return router
+}
+
+func TestTrashEndpoint(t *testing.T) {
+	router := setupTrashTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	created := func(w *httptest.ResponseRecorder) int {
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var item struct {
+			ID int `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &item))
+		return item.ID
+	}
+	type trashResponse struct {
+		Items []struct {
+			Type  string          `json:"type"`
+			ID    int             `json:"id"`
+			Title string          `json:"title"`
+			Task  json.RawMessage `json:"task"`
+			Event json.RawMessage `json:"event"`
+		} `json:"items"`
+		Total int `json:"total"`
+	}
+	list := func(query string) trashResponse {
+		w := send(http.MethodGet, "/api/trash"+query, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response trashResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	taskID := created(send(http.MethodPost, "/api/tasks", `{"title": "Old chore"}`))
+	eventID := created(send(http.MethodPost, "/api/events",
+		`{"title": "Cancelled meetup", "start_time": "2030-05-01T18:00:00Z", "end_time": "2030-05-01T21:00:00Z"}`))
+
+	assert.Equal(t, 0, list("").Total)
+
+	require.Equal(t, http.StatusNoContent, send(http.MethodDelete, fmt.Sprintf("/api/tasks/%d", taskID), "").Code)
+	require.Equal(t, http.StatusNoContent, send(http.MethodDelete, fmt.Sprintf("/api/events/%d", eventID), "").Code)
+
+	t.Run("lists deleted items", func(t *testing.T) {
+		response := list("")
+		require.Equal(t, 2, response.Total)
+		for _, item := range response.Items {
+			switch item.Type {
+			case "task":
+				assert.Equal(t, taskID, item.ID)
+				assert.Equal(t, "Old chore", item.Title)
+				assert.NotEmpty(t, item.Task)
+			case "event":
+				assert.Equal(t, eventID, item.ID)
+				assert.NotEmpty(t, item.Event)
+			default:
+				t.Errorf("unexpected item type %q", item.Type)
+			}
+		}
+	})
+
+	t.Run("filters by type", func(t *testing.T) {
+		response := list("?type=event")
+		require.Equal(t, 1, response.Total)
+		assert.Equal(t, eventID, response.Items[0].ID)
+	})
+
+	t.Run("restored items leave the trash", func(t *testing.T) {
+		w := send(http.MethodPost, fmt.Sprintf("/api/tasks/%d/restore", taskID), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.Equal(t, 0, list("?type=task").Total)
+	})
+
+	t.Run("rejects invalid types", func(t *testing.T) {
+		w := send(http.MethodGet, "/api/trash?type=note", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+	})
+}
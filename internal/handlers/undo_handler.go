@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"agenda/internal/api"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UndoTokenHeader is the response header carrying the token that undoes
+// the changes made by a request
+const UndoTokenHeader = "Undo-Token"
+
+// undoableTask is the response to a request changing a task, with the
+// token undoing the change
+type undoableTask struct {
+	*models.Task
+	UndoToken string `json:"undo_token,omitempty"`
+}
+
+// undoableEvent is the response to a request changing an event, with the
+// token undoing the change
+type undoableEvent struct {
+	*models.Event
+	UndoToken string `json:"undo_token,omitempty"`
+}
+
+// setUndoToken sets the Undo-Token header of the response to a request
+// whose changes were recorded through services.WithUndo, and returns the
+// token. Nothing is set when no change was recorded.
+func setUndoToken(c *gin.Context, undoToken func() string) string {
+	token := undoToken()
+	if token != "" {
+		c.Header(UndoTokenHeader, token)
+	}
+	return token
+}
+
+// UndoHandler handles HTTP requests undoing changes to tasks and events
+type UndoHandler struct {
+	undoService services.UndoServiceInterface
+}
+
+// NewUndoHandler creates a new undo handler instance
+func NewUndoHandler(undoService services.UndoServiceInterface) *UndoHandler {
+	return &UndoHandler{
+		undoService: undoService,
+	}
+}
+
+// Undo handles POST /api/undo/:token
+func (uh *UndoHandler) Undo(c *gin.Context) {
+	changes, err := uh.undoService.Undo(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		uh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"undone": changes,
+		"total":  len(changes),
+	})
+}
+
+// handleServiceError handles errors from the service layer
+func (uh *UndoHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrUndoTokenNotFound:
+		uh.handleError(c, http.StatusNotFound, "UNDO_TOKEN_NOT_FOUND", "Undo token not found", nil)
+	case services.ErrUndoExpired:
+		uh.handleError(c, http.StatusGone, "UNDO_EXPIRED", "Undo token has expired", nil)
+	case services.ErrAlreadyUndone:
+		uh.handleError(c, http.StatusConflict, "ALREADY_UNDONE", "Changes were already undone", nil)
+	case services.ErrUndoConflict:
+		uh.handleError(c, http.StatusConflict, "UNDO_CONFLICT", "Item was modified since the change", map[string]any{
+			"undo_token": "The change can no longer be undone",
+		})
+	default:
+		uh.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (uh *UndoHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
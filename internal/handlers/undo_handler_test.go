@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupUndoTestRouter serves the task, event and undo routes on an
+// in-memory database with the full schema, changes being undoable for
+// window
+func setupUndoTestRouter(t *testing.T, window time.Duration) *gin.Engine {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	taskRepo := database.NewTaskRepository(db)
+	eventRepo := database.NewEventRepository(db)
+	txManager := database.NewTransactionManager(db)
+	undoService := services.NewUndoService(database.NewUndoRepository(db), taskRepo, eventRepo, txManager, window)
+	taskHandler := NewTaskHandler(services.NewTaskService(taskRepo, txManager, undoService))
+	eventHandler := NewEventHandler(services.NewEventService(eventRepo, database.NewCalendarRepository(db), txManager, undoService))
+	undoHandler := NewUndoHandler(undoService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.POST("/tasks", taskHandler.CreateTask)
+	api.GET("/tasks/:id", taskHandler.GetTask)
+	api.PUT("/tasks/:id", taskHandler.UpdateTask)
+	api.DELETE("/tasks/:id", taskHandler.DeleteTask)
+	api.POST("/tasks/:id/complete", taskHandler.CompleteTask)
+	api.POST("/events", eventHandler.CreateEvent)
+	api.GET("/events/:id", eventHandler.GetEvent)
+	api.PUT("/events/:id", eventHandler.UpdateEvent)
+	api.POST("/undo/:token", undoHandler.Undo)
+
+	return router
+}
+
+func TestUndoEndpoint(t *testing.T) {
+	router := setupUndoTestRouter(t, time.Minute)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	// undoToken returns the undo token of a response, checking that the
+	// body and the header agree
+	undoToken := func(w *httptest.ResponseRecorder) string {
+		token := w.Header().Get(UndoTokenHeader)
+		require.NotEmpty(t, token, "missing undo token")
+		if w.Body.Len() > 0 {
+			var body struct {
+				UndoToken string `json:"undo_token"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, token, body.UndoToken)
+		}
+		return token
+	}
+	getTask := func(id int) *httptest.ResponseRecorder {
+		return send(http.MethodGet, fmt.Sprintf("/api/tasks/%d", id), "")
+	}
+	createTask := func(title string) models.Task {
+		w := send(http.MethodPost, "/api/tasks", `{"title": "`+title+`"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var task models.Task
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+		return task
+	}
+
+	t.Run("reopen a completed task", func(t *testing.T) {
+		task := createTask("Send invoices")
+		w := send(http.MethodPost, fmt.Sprintf("/api/tasks/%d/complete", task.ID), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = send(http.MethodPost, "/api/undo/"+undoToken(w), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Undone []models.UndoneChange `json:"undone"`
+			Total  int                   `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, 1, response.Total)
+		assert.Equal(t, "completed", response.Undone[0].Action)
+		require.NotNil(t, response.Undone[0].Task)
+		assert.Equal(t, models.TaskStatusPending, response.Undone[0].Task.Status)
+		assert.Nil(t, response.Undone[0].Task.CompletedAt)
+	})
+
+	t.Run("restore a deleted task", func(t *testing.T) {
+		task := createTask("Renew passport")
+		w := send(http.MethodDelete, fmt.Sprintf("/api/tasks/%d", task.ID), "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+		token := undoToken(w)
+		require.Equal(t, http.StatusNotFound, getTask(task.ID).Code)
+
+		w = send(http.MethodPost, "/api/undo/"+token, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, http.StatusOK, getTask(task.ID).Code)
+
+		w = send(http.MethodPost, "/api/undo/"+token, "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "ALREADY_UNDONE")
+	})
+
+	t.Run("revert an event reschedule", func(t *testing.T) {
+		w := send(http.MethodPost, "/api/events",
+			`{"title": "Dentist", "start_time": "2030-05-01T10:00:00Z", "end_time": "2030-05-01T11:00:00Z"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var event models.Event
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &event))
+
+		w = send(http.MethodPut, fmt.Sprintf("/api/events/%d", event.ID),
+			`{"start_time": "2030-05-02T15:00:00Z", "end_time": "2030-05-02T16:00:00Z"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = send(http.MethodPost, "/api/undo/"+undoToken(w), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = send(http.MethodGet, fmt.Sprintf("/api/events/%d", event.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var reverted models.Event
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reverted))
+		assert.True(t, reverted.StartTime.Equal(event.StartTime), "start time not reverted: %v", reverted.StartTime)
+		assert.True(t, reverted.EndTime.Equal(event.EndTime), "end time not reverted: %v", reverted.EndTime)
+	})
+
+	t.Run("refuses when modified since", func(t *testing.T) {
+		task := createTask("Plan offsite")
+		w := send(http.MethodPut, fmt.Sprintf("/api/tasks/%d", task.ID), `{"title": "Plan the offsite"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		token := undoToken(w)
+		w = send(http.MethodPut, fmt.Sprintf("/api/tasks/%d", task.ID), `{"priority": "high"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = send(http.MethodPost, "/api/undo/"+token, "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "UNDO_CONFLICT")
+
+		var current models.Task
+		require.NoError(t, json.Unmarshal(getTask(task.ID).Body.Bytes(), &current))
+		assert.Equal(t, "Plan the offsite", current.Title)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		w := send(http.MethodPost, "/api/undo/0123456789abcdef", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestUndoEndpoint_Expired(t *testing.T) {
+	router := setupUndoTestRouter(t, time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewBufferString(`{"title": "Water plants"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	token := w.Header().Get(UndoTokenHeader)
+	require.NotEmpty(t, token)
+
+	time.Sleep(5 * time.Millisecond)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/undo/"+token, nil))
+	assert.Equal(t, http.StatusGone, w.Code, w.Body.String())
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"agenda/internal/api"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions and their
+// delivery log
+type WebhookHandler struct {
+	webhookService services.WebhookServiceInterface
+}
+
+// NewWebhookHandler creates a new webhook handler instance
+func NewWebhookHandler(webhookService services.WebhookServiceInterface) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhookRequest represents the HTTP request body for creating a webhook
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	EventTypes  []string `json:"event_types" binding:"required"`
+	Description string   `json:"description"`
+	Secret      string   `json:"secret"`
+	Active      *bool    `json:"active"`
+}
+
+// UpdateWebhookRequest represents the HTTP request body for updating a webhook
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url"`
+	EventTypes  *[]string `json:"event_types"`
+	Description *string   `json:"description"`
+	Active      *bool     `json:"active"`
+}
+
+// CreatedWebhookResponse is the response to creating a webhook, the only
+// one carrying its secret
+type CreatedWebhookResponse struct {
+	*models.Webhook
+	Secret string `json:"secret"`
+}
+
+// CreateWebhook handles POST /api/webhooks
+func (wh *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		wh.handleValidationError(c, err)
+		return
+	}
+
+	webhook, err := wh.webhookService.CreateWebhook(c.Request.Context(), services.CreateWebhookRequest{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Secret:      req.Secret,
+		Active:      req.Active,
+	})
+	if err != nil {
+		wh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatedWebhookResponse{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// ListWebhooks handles GET /api/webhooks
+func (wh *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := wh.webhookService.ListWebhooks(c.Request.Context())
+	if err != nil {
+		wh.handleServiceError(c, err)
+		return
+	}
+	if webhooks == nil {
+		webhooks = []*models.Webhook{}
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"webhooks": webhooks,
+		"total":    len(webhooks),
+	})
+}
+
+// GetWebhook handles GET /api/webhooks/:id
+func (wh *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, err := wh.parseID(c, "id")
+	if err != nil {
+		wh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid webhook ID", nil)
+		return
+	}
+
+	webhook, err := wh.webhookService.GetWebhookByID(c.Request.Context(), id)
+	if err != nil {
+		wh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook handles PUT /api/webhooks/:id
+func (wh *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, err := wh.parseID(c, "id")
+	if err != nil {
+		wh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid webhook ID", nil)
+		return
+	}
+
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		wh.handleValidationError(c, err)
+		return
+	}
+
+	webhook, err := wh.webhookService.UpdateWebhook(c.Request.Context(), id, services.UpdateWebhookRequest{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Active:      req.Active,
+	})
+	if err != nil {
+		wh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles DELETE /api/webhooks/:id
+func (wh *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := wh.parseID(c, "id")
+	if err != nil {
+		wh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid webhook ID", nil)
+		return
+	}
+
+	if err := wh.webhookService.DeleteWebhook(c.Request.Context(), id); err != nil {
+		wh.handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /api/webhooks/:id/deliveries
+func (wh *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := wh.parseID(c, "id")
+	if err != nil {
+		wh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid webhook ID", nil)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		wh.handleError(c, http.StatusBadRequest, "INVALID_LIMIT", "Limit must be a positive number", nil)
+		return
+	}
+
+	deliveries, err := wh.webhookService.ListDeliveries(c.Request.Context(), id, limit)
+	if err != nil {
+		wh.handleServiceError(c, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*models.WebhookDelivery{}
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"deliveries": deliveries,
+		"total":      len(deliveries),
+	})
+}
+
+// GetDelivery handles GET /api/webhooks/:id/deliveries/:deliveryId
+func (wh *WebhookHandler) GetDelivery(c *gin.Context) {
+	id, err := wh.parseID(c, "id")
+	if err != nil {
+		wh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid webhook ID", nil)
+		return
+	}
+	deliveryID, err := wh.parseID(c, "deliveryId")
+	if err != nil {
+		wh.handleError(c, http.StatusBadRequest, "INVALID_ID", "Invalid delivery ID", nil)
+		return
+	}
+
+	delivery, err := wh.webhookService.GetDelivery(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		wh.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// parseID extracts and validates an ID from a URL parameter
+func (wh *WebhookHandler) parseID(c *gin.Context, param string) (int, error) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, errors.New("ID must be positive")
+	}
+	return id, nil
+}
+
+// handleValidationError handles request binding errors
+func (wh *WebhookHandler) handleValidationError(c *gin.Context, err error) {
+	wh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", map[string]any{
+		"validation_error": err.Error(),
+	})
+}
+
+// handleServiceError handles errors from the service layer
+func (wh *WebhookHandler) handleServiceError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrWebhookNotFound:
+		wh.handleError(c, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook not found", nil)
+	case services.ErrWebhookDeliveryNotFound:
+		wh.handleError(c, http.StatusNotFound, "DELIVERY_NOT_FOUND", "Webhook delivery not found", nil)
+	case services.ErrInvalidWebhookURL:
+		wh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid webhook URL", map[string]any{
+			"url": "URL must be an absolute http or https URL of at most 2048 characters",
+		})
+	case services.ErrWebhookURLNotPublic:
+		wh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid webhook URL", map[string]any{
+			"url": "URL cannot point to a loopback, link-local or private network address",
+		})
+	case services.ErrWebhookEventTypesRequired, services.ErrInvalidWebhookEventType:
+		wh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid event types", map[string]any{
+			"event_types": "Event types must be one or more of " + strings.Join(services.ChangeTypes, ", "),
+		})
+	case services.ErrWebhookDescriptionTooLong:
+		wh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Description too long", map[string]any{
+			"description": "Description cannot exceed 255 characters",
+		})
+	case services.ErrWebhookSecretTooShort:
+		wh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Secret too short", map[string]any{
+			"secret": "Secret must be at least 16 characters",
+		})
+	case services.ErrTooManyWebhooks:
+		wh.handleError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Too many webhooks", map[string]any{
+			"webhooks": "Cannot have more than 20 webhooks",
+		})
+	default:
+		wh.handleError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+	}
+}
+
+// handleError creates a standardized error response
+func (wh *WebhookHandler) handleError(c *gin.Context, statusCode int, code, message string, details map[string]any) {
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+	c.JSON(statusCode, response)
+}
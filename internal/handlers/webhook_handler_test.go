@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupWebhookTestRouter serves the task and webhook routes on an in-memory
+// database with the full schema, publishing task changes to the webhooks
+func setupWebhookTestRouter(t *testing.T) *gin.Engine {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.NewMigrationService(db).RunMigrations())
+
+	webhookService := services.NewWebhookService(database.NewWebhookRepository(db), false)
+	taskService := services.NewTaskService(database.NewTaskRepository(db), database.NewTransactionManager(db), webhookService)
+	taskHandler := NewTaskHandler(taskService)
+	webhookHandler := NewWebhookHandler(webhookService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api")
+	api.POST("/tasks", taskHandler.CreateTask)
+	api.GET("/webhooks", webhookHandler.ListWebhooks)
+	api.POST("/webhooks", webhookHandler.CreateWebhook)
+	api.GET("/webhooks/:id", webhookHandler.GetWebhook)
+	api.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+	api.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+	api.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+	api.GET("/webhooks/:id/deliveries/:deliveryId", webhookHandler.GetDelivery)
+
+	return router
+}
+
+func TestWebhookEndpoints(t *testing.T) {
+	router := setupWebhookTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var webhook models.Webhook
+
+	t.Run("create returns the secret once", func(t *testing.T) {
+		w := send(http.MethodPost, "/api/webhooks",
+			`{"url": "https://hooks.example.com/agenda", "event_types": ["task.created"], "description": "CRM sync"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var created map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		assert.NotEmpty(t, created["secret"])
+		assert.Equal(t, "CRM sync", created["description"])
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &webhook))
+
+		w = send(http.MethodGet, fmt.Sprintf("/api/webhooks/%d", webhook.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var fetched map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+		assert.NotContains(t, fetched, "secret")
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			body   string
+			status int
+		}{
+			{"missing URL", http.MethodPost, "/api/webhooks", `{"event_types": ["task.created"]}`, http.StatusBadRequest},
+			{"invalid URL", http.MethodPost, "/api/webhooks", `{"url": "hooks", "event_types": ["task.created"]}`, http.StatusBadRequest},
+			{"internal URL", http.MethodPost, "/api/webhooks", `{"url": "http://169.254.169.254/latest", "event_types": ["task.created"]}`, http.StatusBadRequest},
+			{"unknown event type", http.MethodPost, "/api/webhooks", `{"url": "https://example.com", "event_types": ["task.moved"]}`, http.StatusBadRequest},
+			{"empty event types", http.MethodPut, fmt.Sprintf("/api/webhooks/%d", webhook.ID), `{"event_types": []}`, http.StatusBadRequest},
+			{"unknown webhook", http.MethodPut, "/api/webhooks/999", `{"active": false}`, http.StatusNotFound},
+			{"invalid ID", http.MethodGet, "/api/webhooks/abc", ``, http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := send(tt.method, tt.path, tt.body)
+				assert.Equal(t, tt.status, w.Code, w.Body.String())
+			})
+		}
+	})
+
+	t.Run("task changes are queued for delivery", func(t *testing.T) {
+		w := send(http.MethodPost, "/api/tasks", `{"title": "Call the bank"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		w = send(http.MethodGet, fmt.Sprintf("/api/webhooks/%d/deliveries", webhook.ID), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list struct {
+			Deliveries []struct {
+				ID        int            `json:"id"`
+				EventType string         `json:"event_type"`
+				Status    string         `json:"status"`
+				Payload   map[string]any `json:"payload"`
+			} `json:"deliveries"`
+			Total int `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		require.Equal(t, 1, list.Total)
+		delivery := list.Deliveries[0]
+		assert.Equal(t, services.ChangeTaskCreated, delivery.EventType)
+		assert.Equal(t, models.DeliveryPending, delivery.Status)
+		assert.Equal(t, "Call the bank", delivery.Payload["data"].(map[string]any)["title"])
+
+		w = send(http.MethodGet, fmt.Sprintf("/api/webhooks/%d/deliveries/%d", webhook.ID, delivery.ID), "")
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = send(http.MethodGet, fmt.Sprintf("/api/webhooks/%d/deliveries/999", webhook.ID), "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("delete webhook", func(t *testing.T) {
+		w := send(http.MethodDelete, fmt.Sprintf("/api/webhooks/%d", webhook.ID), "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = send(http.MethodDelete, fmt.Sprintf("/api/webhooks/%d", webhook.ID), "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = send(http.MethodGet, "/api/webhooks", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"webhooks": [], "total": 0}`, w.Body.String())
+	})
+}
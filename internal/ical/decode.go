@@ -0,0 +1,165 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Decoding errors
+var (
+	ErrMalformedLine   = errors.New("malformed content line")
+	ErrUnbalancedBlock = errors.New("unbalanced BEGIN/END")
+	ErrNoCalendar      = errors.New("no VCALENDAR component")
+	ErrLineTooLong     = errors.New("content line too long")
+)
+
+// maxUnfoldedLine bounds the size of a single unfolded content line
+const maxUnfoldedLine = 1 << 20
+
+// Decode parses iCalendar data and returns its first top-level component,
+// which must be a VCALENDAR
+func Decode(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		root  *Component
+		stack []*Component
+	)
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			component := NewComponent(prop.Value)
+			if len(stack) > 0 {
+				stack[len(stack)-1].AddComponent(component)
+			} else if root == nil {
+				root = component
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("line %d: %w", i+1, ErrUnbalancedBlock)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property outside of a component: %w", i+1, ErrMalformedLine)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, ErrUnbalancedBlock
+	}
+	if root == nil || root.Name != ComponentCalendar {
+		return nil, ErrNoCalendar
+	}
+
+	return root, nil
+}
+
+// unfold reads the content lines, joining folded continuation lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxUnfoldedLine)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			last := len(lines) - 1
+			if len(lines[last])+len(line) > maxUnfoldedLine {
+				return nil, ErrLineTooLong
+			}
+			lines[last] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, ErrLineTooLong
+		}
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// parseLine parses an unfolded content line: name *(";" param) ":" value
+func parseLine(line string) (*Property, error) {
+	prop := &Property{}
+
+	// Name
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return nil, ErrMalformedLine
+	}
+	prop.Name = strings.ToUpper(line[:i])
+	rest := line[i:]
+
+	// Parameters
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, ErrMalformedLine
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var values []string
+		for {
+			var value string
+			if strings.HasPrefix(rest, `"`) {
+				end := strings.IndexByte(rest[1:], '"')
+				if end < 0 {
+					return nil, ErrMalformedLine
+				}
+				value = rest[1 : end+1]
+				rest = rest[end+2:]
+			} else {
+				end := strings.IndexAny(rest, ";:,")
+				if end < 0 {
+					return nil, ErrMalformedLine
+				}
+				value = rest[:end]
+				rest = rest[end:]
+			}
+			values = append(values, value)
+
+			if !strings.HasPrefix(rest, ",") {
+				break
+			}
+			rest = rest[1:]
+		}
+
+		if prop.Params == nil {
+			prop.Params = make(map[string]string)
+		}
+		prop.Params[name] = strings.Join(values, ",")
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return nil, ErrMalformedLine
+	}
+	prop.Value = rest[1:]
+
+	return prop, nil
+}
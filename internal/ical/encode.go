@@ -0,0 +1,90 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the maximum length of a content line before folding
+const maxLineOctets = 75
+
+// Encode writes the component, including its sub-components, as iCalendar
+// data with CRLF line endings and folded long lines
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	if err := encodeComponent(bw, c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func encodeComponent(w *bufio.Writer, c *Component) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, prop := range c.Properties {
+		if err := writeLine(w, formatProperty(prop)); err != nil {
+			return err
+		}
+	}
+	for _, sub := range c.Components {
+		if err := encodeComponent(w, sub); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+// formatProperty formats a property as an unfolded content line
+func formatProperty(p *Property) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	// Sort parameters for a stable output
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := p.Params[name]
+		b.WriteByte(';')
+		b.WriteString(name)
+		b.WriteByte('=')
+		if strings.ContainsAny(value, ";:,") {
+			b.WriteByte('"')
+			b.WriteString(strings.ReplaceAll(value, `"`, ""))
+			b.WriteByte('"')
+		} else {
+			b.WriteString(value)
+		}
+	}
+
+	b.WriteByte(':')
+	b.WriteString(p.Value)
+	return b.String()
+}
+
+// writeLine writes a content line, folding it at maxLineOctets without
+// splitting UTF-8 sequences
+func writeLine(w *bufio.Writer, line string) error {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		// Continuation lines start with a space that counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	_, err := w.WriteString(line + "\r\n")
+	return err
+}
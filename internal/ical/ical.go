@@ -0,0 +1,176 @@
+// Package ical reads and writes iCalendar (RFC 5545) data.
+//
+// It models a calendar as a tree of components made of content lines and
+// leaves the mapping to application models to its callers.
+package ical
+
+import (
+	"strings"
+)
+
+// Component names
+const (
+	ComponentCalendar = "VCALENDAR"
+	ComponentEvent    = "VEVENT"
+	ComponentTodo     = "VTODO"
+	ComponentTimeZone = "VTIMEZONE"
+	ComponentStandard = "STANDARD"
+	ComponentDaylight = "DAYLIGHT"
+)
+
+// Property is a single content line of a component. Value holds the raw,
+// still escaped value as it appears on the wire; use Text for TEXT values.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Param returns the value of the named parameter, or an empty string
+func (p *Property) Param(name string) string {
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Text returns the property value unescaped as a TEXT value
+func (p *Property) Text() string {
+	return UnescapeText(p.Value)
+}
+
+// Component is an iCalendar component such as VCALENDAR, VEVENT or VTODO
+type Component struct {
+	Name       string
+	Properties []*Property
+	Components []*Component
+}
+
+// NewCalendar creates an empty VCALENDAR with the required properties
+func NewCalendar(prodID string) *Component {
+	cal := NewComponent(ComponentCalendar)
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", prodID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	return cal
+}
+
+// NewComponent creates an empty component with the given name
+func NewComponent(name string) *Component {
+	return &Component{Name: strings.ToUpper(name)}
+}
+
+// Add appends a property with a raw value and optional parameters given as
+// name/value pairs
+func (c *Component) Add(name, value string, params ...string) *Property {
+	prop := &Property{Name: strings.ToUpper(name), Value: value}
+	if len(params) > 0 {
+		prop.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			prop.Params[strings.ToUpper(params[i])] = params[i+1]
+		}
+	}
+	c.Properties = append(c.Properties, prop)
+	return prop
+}
+
+// AddText appends a property with a TEXT value, escaping it
+func (c *Component) AddText(name, value string) *Property {
+	return c.Add(name, EscapeText(value))
+}
+
+// Get returns the first property with the given name, or nil
+func (c *Component) Get(name string) *Property {
+	name = strings.ToUpper(name)
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			return prop
+		}
+	}
+	return nil
+}
+
+// GetAll returns every property with the given name
+func (c *Component) GetAll(name string) []*Property {
+	name = strings.ToUpper(name)
+	var props []*Property
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			props = append(props, prop)
+		}
+	}
+	return props
+}
+
+// Text returns the unescaped TEXT value of the first property with the
+// given name, or an empty string
+func (c *Component) Text(name string) string {
+	if prop := c.Get(name); prop != nil {
+		return prop.Text()
+	}
+	return ""
+}
+
+// AddComponent appends a sub-component
+func (c *Component) AddComponent(sub *Component) {
+	c.Components = append(c.Components, sub)
+}
+
+// Children returns the direct sub-components with the given name
+func (c *Component) Children(name string) []*Component {
+	name = strings.ToUpper(name)
+	var children []*Component
+	for _, sub := range c.Components {
+		if sub.Name == name {
+			children = append(children, sub)
+		}
+	}
+	return children
+}
+
+// EscapeText escapes a TEXT value as defined in RFC 5545 section 3.3.11
+func EscapeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case ';':
+			b.WriteString(`\;`)
+		case ',':
+			b.WriteString(`\,`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			// Dropped; line breaks are written as \n
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// UnescapeText reverses EscapeText
+func UnescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if escaped {
+			switch r {
+			case 'n', 'N':
+				b.WriteRune('\n')
+			default:
+				b.WriteRune(r)
+			}
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
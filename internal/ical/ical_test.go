@@ -0,0 +1,207 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	cal := NewCalendar("-//agenda//test//EN")
+	event := NewComponent(ComponentEvent)
+	event.Add("UID", "1@agenda")
+	event.AddText("SUMMARY", "Review; planning, and notes\\ with a newline\nhere")
+	event.AddText("DESCRIPTION", strings.Repeat("ñ", 100))
+	event.Add("DTSTART", "20240115T090000", "TZID", "Europe/Madrid")
+	cal.AddComponent(event)
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, cal))
+
+	// Every physical line is CRLF terminated and at most 75 octets long
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets)
+	}
+
+	decoded, err := Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0", decoded.Get("VERSION").Value)
+
+	events := decoded.Children(ComponentEvent)
+	require.Len(t, events, 1)
+	assert.Equal(t, "1@agenda", events[0].Text("UID"))
+	assert.Equal(t, "Review; planning, and notes\\ with a newline\nhere", events[0].Text("SUMMARY"))
+	assert.Equal(t, strings.Repeat("ñ", 100), events[0].Text("DESCRIPTION"))
+	assert.Equal(t, "Europe/Madrid", events[0].Get("DTSTART").Param("tzid"))
+}
+
+func TestDecode(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:todo-1\r\n" +
+		"SUMMARY:Folded\r\n" +
+		"  summary\r\n" +
+		"ATTENDEE;ROLE=REQ-PARTICIPANT;CN=\"Doe, Jane\":mailto:jane@example.com\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	cal, err := Decode(strings.NewReader(data))
+	require.NoError(t, err)
+
+	todos := cal.Children(ComponentTodo)
+	require.Len(t, todos, 1)
+	assert.Equal(t, "Folded summary", todos[0].Text("SUMMARY"))
+
+	attendee := todos[0].Get("ATTENDEE")
+	require.NotNil(t, attendee)
+	assert.Equal(t, "Doe, Jane", attendee.Param("CN"))
+	assert.Equal(t, "REQ-PARTICIPANT", attendee.Param("ROLE"))
+	assert.Equal(t, "mailto:jane@example.com", attendee.Value)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr error
+	}{
+		{"empty", "", ErrNoCalendar},
+		{"not a calendar", "BEGIN:VEVENT\nEND:VEVENT\n", ErrNoCalendar},
+		{"unbalanced", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nEND:VCALENDAR\n", ErrUnbalancedBlock},
+		{"unterminated", "BEGIN:VCALENDAR\nBEGIN:VEVENT\n", ErrUnbalancedBlock},
+		{"malformed line", "BEGIN:VCALENDAR\nno colon here\nEND:VCALENDAR\n", ErrMalformedLine},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.data))
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		prop           *Property
+		expected       time.Time
+		expectedAllDay bool
+	}{
+		{
+			name:     "utc",
+			prop:     &Property{Value: "20240115T090000Z"},
+			expected: time.Date(2024, time.January, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "floating",
+			prop:     &Property{Value: "20240115T090000"},
+			expected: time.Date(2024, time.January, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "with time zone",
+			prop:     &Property{Value: "20240115T090000", Params: map[string]string{"TZID": "Europe/Madrid"}},
+			expected: time.Date(2024, time.January, 15, 9, 0, 0, 0, madrid),
+		},
+		{
+			name:     "unknown time zone",
+			prop:     &Property{Value: "20240115T090000", Params: map[string]string{"TZID": "Custom Zone"}},
+			expected: time.Date(2024, time.January, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "date",
+			prop:           &Property{Value: "20240115", Params: map[string]string{"VALUE": "DATE"}},
+			expected:       time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+			expectedAllDay: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, allDay, err := ParseDateTime(tt.prop)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(result), "expected %v, got %v", tt.expected, result)
+			assert.Equal(t, tt.expectedAllDay, allDay)
+		})
+	}
+
+	_, _, err = ParseDateTime(&Property{Value: "tomorrow"})
+	assert.ErrorIs(t, err, ErrInvalidValue)
+
+	times, _, err := ParseDateTimes(&Property{Value: "20240115T090000Z,20240116T090000Z"})
+	require.NoError(t, err)
+	assert.Len(t, times, 2)
+}
+
+func TestNewTimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+
+	from := time.Date(2024, time.January, 15, 9, 0, 0, 0, loc)
+	to := time.Date(2024, time.December, 31, 0, 0, 0, 0, loc)
+	vtimezone := NewTimeZone(loc, from, to)
+
+	assert.Equal(t, ComponentTimeZone, vtimezone.Name)
+	assert.Equal(t, "Europe/Madrid", vtimezone.Text("TZID"))
+
+	// The offset at the start of the range, then both transitions of 2024
+	require.Len(t, vtimezone.Components, 3)
+	for i, expected := range []struct {
+		name, start, from, to, tzname string
+	}{
+		{ComponentStandard, "20240115T090000", "+0100", "+0100", "CET"},
+		{ComponentDaylight, "20240331T020000", "+0100", "+0200", "CEST"},
+		{ComponentStandard, "20241027T030000", "+0200", "+0100", "CET"},
+	} {
+		observance := vtimezone.Components[i]
+		assert.Equal(t, expected.name, observance.Name)
+		assert.Equal(t, expected.start, observance.Get("DTSTART").Value)
+		assert.Equal(t, expected.from, observance.Get("TZOFFSETFROM").Value)
+		assert.Equal(t, expected.to, observance.Get("TZOFFSETTO").Value)
+		assert.Equal(t, expected.tzname, observance.Text("TZNAME"))
+	}
+
+	// Zones without transitions have a single observance
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	vtimezone = NewTimeZone(tokyo, from, to)
+	require.Len(t, vtimezone.Components, 1)
+	assert.Equal(t, "+0900", vtimezone.Components[0].Get("TZOFFSETTO").Value)
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		valid    bool
+	}{
+		{"PT1H30M", 90 * time.Minute, true},
+		{"P1D", 24 * time.Hour, true},
+		{"P2W", 14 * 24 * time.Hour, true},
+		{"P1DT2H3M4S", 26*time.Hour + 3*time.Minute + 4*time.Second, true},
+		{"-PT15M", -15 * time.Minute, true},
+		{"PT", 0, false},
+		{"P1H", 0, false},
+		{"1H", 0, false},
+		{"PT5", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			result, err := ParseDuration(tt.value)
+			if !tt.valid {
+				assert.ErrorIs(t, err, ErrInvalidValue)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
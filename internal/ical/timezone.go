@@ -0,0 +1,65 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewTimeZone creates the VTIMEZONE defining loc between from and to, for
+// the DATE-TIME values qualified by a TZID parameter naming loc. It lists
+// the offset in effect at from and every transition until to as separate
+// STANDARD and DAYLIGHT observances.
+func NewTimeZone(loc *time.Location, from, to time.Time) *Component {
+	vtimezone := NewComponent(ComponentTimeZone)
+	vtimezone.Add("TZID", loc.String())
+
+	t := from.In(loc)
+	name, offset := t.Zone()
+	vtimezone.AddComponent(observance(t, name, offset, offset))
+
+	for {
+		_, end := t.ZoneBounds()
+		if end.IsZero() || end.After(to) {
+			break
+		}
+		t = end.In(loc)
+		previous := offset
+		name, offset = t.Zone()
+		vtimezone.AddComponent(observance(t, name, previous, offset))
+	}
+	return vtimezone
+}
+
+// observance creates the STANDARD or DAYLIGHT component of the offset that
+// comes into effect at t, replacing offsetFrom
+func observance(t time.Time, name string, offsetFrom, offsetTo int) *Component {
+	component := NewComponent(ComponentStandard)
+	if t.IsDST() {
+		component = NewComponent(ComponentDaylight)
+	}
+
+	// The start of an observance is local to the offset it replaces
+	component.Add("DTSTART", FormatLocalDateTime(t.In(time.FixedZone("", offsetFrom))))
+	component.Add("TZOFFSETFROM", formatUTCOffset(offsetFrom))
+	component.Add("TZOFFSETTO", formatUTCOffset(offsetTo))
+	if name != "" {
+		component.AddText("TZNAME", name)
+	}
+	return component
+}
+
+// formatUTCOffset formats an offset in seconds east of UTC as a UTC-OFFSET
+// value
+func formatUTCOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+
+	value := fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset%3600/60)
+	if seconds := offset % 60; seconds != 0 {
+		value += fmt.Sprintf("%02d", seconds)
+	}
+	return value
+}
@@ -0,0 +1,157 @@
+package ical
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Value formats
+const (
+	dateFormat        = "20060102"
+	dateTimeFormat    = "20060102T150405"
+	utcDateTimeFormat = "20060102T150405Z"
+)
+
+// ErrInvalidValue is returned for values that cannot be parsed
+var ErrInvalidValue = errors.New("invalid value")
+
+// FormatDateTime formats a time as a UTC DATE-TIME value
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(utcDateTimeFormat)
+}
+
+// FormatLocalDateTime formats a time as a DATE-TIME value local to its
+// location, to be qualified by a TZID parameter
+func FormatLocalDateTime(t time.Time) string {
+	return t.Format(dateTimeFormat)
+}
+
+// FormatDateTimes formats times as a comma-separated list of UTC DATE-TIME
+// values, as used by EXDATE
+func FormatDateTimes(times []time.Time) string {
+	values := make([]string, len(times))
+	for i, t := range times {
+		values[i] = FormatDateTime(t)
+	}
+	return strings.Join(values, ",")
+}
+
+// ParseDateTime parses the DATE or DATE-TIME value of a property such as
+// DTSTART, DUE or RECURRENCE-ID. It honours the VALUE and TZID parameters;
+// floating times and unknown time zones are interpreted as UTC. allDay
+// reports whether the value was a DATE.
+func ParseDateTime(p *Property) (t time.Time, allDay bool, err error) {
+	times, allDay, err := ParseDateTimes(p)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(times) != 1 {
+		return time.Time{}, false, ErrInvalidValue
+	}
+	return times[0], allDay, nil
+}
+
+// ParseDateTimes parses a comma-separated list of DATE or DATE-TIME values,
+// as used by EXDATE
+func ParseDateTimes(p *Property) ([]time.Time, bool, error) {
+	loc := time.UTC
+	if tzid := p.Param("TZID"); tzid != "" {
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+
+	allDay := strings.EqualFold(p.Param("VALUE"), "DATE")
+	var times []time.Time
+	for _, value := range strings.Split(p.Value, ",") {
+		value = strings.TrimSpace(value)
+
+		var (
+			t   time.Time
+			err error
+		)
+		switch {
+		case allDay || len(value) == len(dateFormat):
+			allDay = true
+			t, err = time.ParseInLocation(dateFormat, value, time.UTC)
+		case strings.HasSuffix(value, "Z"):
+			t, err = time.Parse(utcDateTimeFormat, value)
+		default:
+			t, err = time.ParseInLocation(dateTimeFormat, value, loc)
+		}
+		if err != nil {
+			return nil, false, ErrInvalidValue
+		}
+		times = append(times, t)
+	}
+
+	return times, allDay, nil
+}
+
+// ParseDuration parses a DURATION value such as P1D, PT1H30M or -P2W
+func ParseDuration(value string) (time.Duration, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, ErrInvalidValue
+	}
+	s = s[1:]
+
+	var (
+		d      time.Duration
+		inTime bool
+		digits string
+	)
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits += string(r)
+			continue
+		case r == 'T' && !inTime && digits == "":
+			inTime = true
+			continue
+		}
+
+		if digits == "" {
+			return 0, ErrInvalidValue
+		}
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return 0, ErrInvalidValue
+		}
+		digits = ""
+
+		var unit time.Duration
+		switch {
+		case r == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case r == 'H' && inTime:
+			unit = time.Hour
+		case r == 'M' && inTime:
+			unit = time.Minute
+		case r == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, ErrInvalidValue
+		}
+		d += time.Duration(n) * unit
+	}
+	if digits != "" {
+		return 0, ErrInvalidValue
+	}
+
+	return sign * d, nil
+}
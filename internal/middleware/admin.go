@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"agenda/internal/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminEmailsFromEnv reads the emails of the administrators from the
+// comma-separated ADMIN_EMAILS environment variable
+func AdminEmailsFromEnv() []string {
+	var emails []string
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// Admin middleware only lets administrators through, the users whose email
+// is listed in emails. It runs after Auth; with no administrator listed,
+// every request is rejected.
+func Admin(emails []string) gin.HandlerFunc {
+	admins := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		admins[strings.ToLower(email)] = true
+	}
+
+	return func(c *gin.Context) {
+		if !admins[strings.ToLower(c.GetString(UserEmailKey))] {
+			c.JSON(http.StatusForbidden, api.ErrorResponse{
+				Error: api.ErrorDetail{
+					Code:    "FORBIDDEN",
+					Message: "Administrator access required",
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"agenda/internal/api"
+	"agenda/internal/auth"
+	"agenda/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gin context keys of the authenticated user
+const (
+	// UserIDKey holds the ID of the authenticated user
+	UserIDKey = "user_id"
+	// UserEmailKey holds the email of the authenticated user
+	UserEmailKey = "user_email"
+)
+
+// Authenticator resolves a bearer token to the user owning it
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*models.User, error)
+}
+
+// Auth middleware requires a valid bearer token. The authenticated user is
+// attached to the request context so repositories only return their rows.
+func Auth(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := auth.BearerToken(c.GetHeader("Authorization"))
+		if token == "" {
+			abortUnauthorized(c, "Authentication required")
+			return
+		}
+
+		user, err := authenticator.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidSession) {
+				abortUnauthorized(c, "Invalid or expired session")
+				return
+			}
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{
+				Error: api.ErrorDetail{
+					Code:    "INTERNAL_ERROR",
+					Message: "Internal server error",
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithUserID(c.Request.Context(), user.ID))
+		c.Set(UserIDKey, user.ID)
+		c.Set(UserEmailKey, user.Email)
+
+		c.Next()
+	}
+}
+
+// abortUnauthorized rejects the request with a 401 response
+func abortUnauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="agenda"`)
+	response := api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    "UNAUTHORIZED",
+			Message: message,
+		},
+	}
+	c.JSON(http.StatusUnauthorized, response)
+	c.Abort()
+}
@@ -1,11 +1,18 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/models"
+	"agenda/internal/timezone"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -154,6 +161,72 @@ func TestContentTypeValidation(t *testing.T) {
 	}
 }
 
+func TestContentTypeValidation_AllowedTypes(t *testing.T) {
+	router := gin.New()
+	router.Use(ContentTypeValidation("text/calendar", "multipart/form-data"))
+	router.POST("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "test"})
+	})
+
+	tests := []struct {
+		name           string
+		contentType    string
+		expectedStatus int
+	}{
+		{"Allowed calendar content-type", "text/calendar; charset=utf-8", http.StatusOK},
+		{"Allowed multipart content-type", "multipart/form-data; boundary=x", http.StatusOK},
+		{"JSON not allowed", "application/json", http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/test", strings.NewReader("data"))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestContentTypeValidation_Patch(t *testing.T) {
+	router := gin.New()
+	router.Use(ContentTypeValidation())
+	router.PATCH("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "test"})
+	})
+	router.POST("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "test"})
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		contentType    string
+		expectedStatus int
+	}{
+		{"Merge patch", "PATCH", "application/merge-patch+json", http.StatusOK},
+		{"JSON Patch", "PATCH", "application/json-patch+json; charset=utf-8", http.StatusOK},
+		{"Plain JSON patch", "PATCH", "application/json", http.StatusOK},
+		{"Invalid patch content-type", "PATCH", "text/plain", http.StatusUnsupportedMediaType},
+		{"Patch types only apply to PATCH", "POST", "application/json-patch+json", http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/test", strings.NewReader("{}"))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
 func TestAPIVersioning(t *testing.T) {
 	router := gin.New()
 	router.Use(APIVersioning())
@@ -199,9 +272,50 @@ func TestAPIVersioning(t *testing.T) {
 	}
 }
 
-func TestRateLimitInfo(t *testing.T) {
+func TestTimeZone(t *testing.T) {
+	router := gin.New()
+	router.Use(TimeZone())
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, timezone.FromContext(c.Request.Context()).String())
+	})
+
+	tests := []struct {
+		name           string
+		query          string
+		header         string
+		expectedStatus int
+		expectedZone   string
+	}{
+		{"Defaults to UTC", "", "", http.StatusOK, "UTC"},
+		{"Header", "", "Europe/Paris", http.StatusOK, "Europe/Paris"},
+		{"Query parameter wins over header", "?tz=Asia/Tokyo", "Europe/Paris", http.StatusOK, "Asia/Tokyo"},
+		{"Unknown zone", "?tz=Nowhere/Special", "", http.StatusBadRequest, ""},
+		{"Server zone", "", "Local", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set(TimeZoneHeader, tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedZone, w.Body.String())
+			} else {
+				assert.Contains(t, w.Body.String(), "INVALID_TIME_ZONE")
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
 	router := gin.New()
-	router.Use(RateLimitInfo())
+	router.Use(RateLimit(RateLimitPolicy{Limit: 1000, Window: time.Hour}))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "test"})
 	})
@@ -214,4 +328,226 @@ func TestRateLimitInfo(t *testing.T) {
 	assert.Equal(t, "1000", w.Header().Get("X-RateLimit-Limit"))
 	assert.Equal(t, "999", w.Header().Get("X-RateLimit-Remaining"))
 	assert.Equal(t, "3600", w.Header().Get("X-RateLimit-Reset"))
+
+	req = httptest.NewRequest("GET", "/test", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "998", w.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestRateLimit_Exceeded(t *testing.T) {
+	router := gin.New()
+	router.Use(RateLimit(RateLimitPolicy{Limit: 2, Window: time.Minute}))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "test"})
+	})
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send("192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusOK, send("192.0.2.1:1234").Code)
+
+	w := send("192.0.2.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "RATE_LIMIT_EXCEEDED")
+
+	// Other clients have their own budget
+	w = send("192.0.2.2:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestRateLimit_PerUserAndMethod(t *testing.T) {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(UserIDKey, c.GetHeader("X-Test-User"))
+		c.Next()
+	})
+	router.Use(RateLimit(RateLimitPolicy{Limit: 1, Window: time.Minute, Methods: WriteMethods}))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.POST("/test", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	send := func(method, user string) int {
+		req := httptest.NewRequest(method, "/test", nil)
+		req.Header.Set("X-Test-User", user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusCreated, send("POST", "alice"))
+	assert.Equal(t, http.StatusTooManyRequests, send("POST", "alice"))
+	assert.Equal(t, http.StatusOK, send("GET", "alice"))
+	assert.Equal(t, http.StatusCreated, send("POST", "bob"))
+}
+
+func TestRateLimiter_SlidingWindow(t *testing.T) {
+	limiter := NewRateLimiter(2, time.Minute)
+	start := time.Date(2030, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	assert.True(t, limiter.Allow("client", start).Allowed)
+	assert.True(t, limiter.Allow("client", start.Add(30*time.Second)).Allowed)
+
+	result := limiter.Allow("client", start.Add(45*time.Second))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 15*time.Second, result.RetryAfter)
+
+	// The first request leaves the window after a minute
+	result = limiter.Allow("client", start.Add(61*time.Second))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 29*time.Second, result.Reset)
+
+	// Idle clients are forgotten
+	limiter.Allow("other", start.Add(10*time.Minute))
+	assert.Len(t, limiter.clients, 1)
+}
+
+func TestRateLimitPolicyFromEnv(t *testing.T) {
+	defaults := RateLimitPolicy{Limit: 10, Window: time.Minute}
+
+	t.Setenv("TEST_LIMIT_REQUESTS", "25")
+	t.Setenv("TEST_LIMIT_WINDOW", "30s")
+	policy := RateLimitPolicyFromEnv("TEST_LIMIT", defaults)
+	assert.Equal(t, 25, policy.Limit)
+	assert.Equal(t, 30*time.Second, policy.Window)
+
+	t.Setenv("TEST_LIMIT_REQUESTS", "many")
+	t.Setenv("TEST_LIMIT_WINDOW", "")
+	assert.Equal(t, defaults, RateLimitPolicyFromEnv("TEST_LIMIT", defaults))
+}
+
+// stubAuthenticator accepts a single token
+type stubAuthenticator struct {
+	token string
+	err   error
+}
+
+func (s stubAuthenticator) Authenticate(ctx context.Context, token string) (*models.User, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if token != s.token {
+		return nil, auth.ErrInvalidSession
+	}
+	return &models.User{ID: 7, Email: "alice@example.com"}, nil
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		name           string
+		authenticator  stubAuthenticator
+		header         string
+		expectedStatus int
+	}{
+		{
+			name:           "Valid token",
+			authenticator:  stubAuthenticator{token: "secret"},
+			header:         "Bearer secret",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing header",
+			authenticator:  stubAuthenticator{token: "secret"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Wrong scheme",
+			authenticator:  stubAuthenticator{token: "secret"},
+			header:         "Basic secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Unknown token",
+			authenticator:  stubAuthenticator{token: "secret"},
+			header:         "Bearer other",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Authenticator failure",
+			authenticator:  stubAuthenticator{err: errors.New("database is down")},
+			header:         "Bearer secret",
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Auth(tt.authenticator))
+			router.GET("/test", func(c *gin.Context) {
+				userID, ok := auth.UserIDFromContext(c.Request.Context())
+				assert.True(t, ok)
+				assert.Equal(t, 7, userID)
+				assert.Equal(t, 7, c.GetInt(UserIDKey))
+				c.JSON(http.StatusOK, gin.H{"message": "test"})
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+				assert.Contains(t, w.Body.String(), "UNAUTHORIZED")
+			}
+		})
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	tests := []struct {
+		name           string
+		admins         []string
+		expectedStatus int
+	}{
+		{name: "Listed administrator", admins: []string{"bob@example.com", "Alice@Example.com"}, expectedStatus: http.StatusOK},
+		{name: "Other user", admins: []string{"bob@example.com"}, expectedStatus: http.StatusForbidden},
+		{name: "No administrators", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Auth(stubAuthenticator{token: "secret"}), Admin(tt.admins))
+			router.GET("/test", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "test"})
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), "FORBIDDEN")
+			}
+		})
+	}
+}
+
+func TestAdminEmailsFromEnv(t *testing.T) {
+	t.Setenv("ADMIN_EMAILS", " alice@example.com, ,bob@example.com ")
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, AdminEmailsFromEnv())
+
+	t.Setenv("ADMIN_EMAILS", "")
+	assert.Empty(t, AdminEmailsFromEnv())
 }
@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"agenda/internal/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy describes how many requests a client may make in a
+// sliding window of time
+type RateLimitPolicy struct {
+	Limit  int
+	Window time.Duration
+
+	// Methods restricts the policy to the given HTTP methods; requests
+	// with other methods are not counted. Empty means every method.
+	Methods []string
+}
+
+// WriteMethods are the HTTP methods that modify data
+var WriteMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// RateLimitPolicyFromEnv reads a policy from the <prefix>_REQUESTS and
+// <prefix>_WINDOW environment variables, keeping the defaults for unset or
+// invalid values
+func RateLimitPolicyFromEnv(prefix string, defaults RateLimitPolicy) RateLimitPolicy {
+	policy := defaults
+
+	if limit, err := strconv.Atoi(os.Getenv(prefix + "_REQUESTS")); err == nil && limit > 0 {
+		policy.Limit = limit
+	}
+	if window, err := time.ParseDuration(os.Getenv(prefix + "_WINDOW")); err == nil && window > 0 {
+		policy.Window = window
+	}
+
+	return policy
+}
+
+// RateLimit middleware enforces a sliding-window rate limit per client.
+// Clients are identified by the authenticated user when the Auth middleware
+// ran before it, and by IP address otherwise. Every call creates an
+// independent limiter, so route groups can be given their own policies.
+func RateLimit(policy RateLimitPolicy) gin.HandlerFunc {
+	limiter := NewRateLimiter(policy.Limit, policy.Window)
+
+	methods := make(map[string]struct{}, len(policy.Methods))
+	for _, method := range policy.Methods {
+		methods[method] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if len(methods) > 0 {
+			if _, ok := methods[c.Request.Method]; !ok {
+				c.Next()
+				return
+			}
+		}
+
+		result := limiter.Allow(rateLimitKey(c), time.Now())
+		setRateLimitHeaders(c, result)
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			response := api.ErrorResponse{
+				Error: api.ErrorDetail{
+					Code:    "RATE_LIMIT_EXCEEDED",
+					Message: "Too many requests",
+					Details: map[string]interface{}{
+						"limit":          result.Limit,
+						"window_seconds": int(policy.Window / time.Second),
+						"retry_after":    retryAfter,
+					},
+				},
+			}
+			c.JSON(http.StatusTooManyRequests, response)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey identifies the client a request is counted against
+func rateLimitKey(c *gin.Context) string {
+	if userID, ok := c.Get(UserIDKey); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// setRateLimitHeaders reports the state of the most restrictive limit that
+// applies to the request, since several limiters may run in sequence
+func setRateLimitHeaders(c *gin.Context, result RateLimitResult) {
+	if previous, err := strconv.Atoi(c.Writer.Header().Get("X-RateLimit-Remaining")); err == nil && previous < result.Remaining {
+		return
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// RateLimitResult is the outcome of counting a request against a limiter
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+
+	// Reset is the time until the oldest counted request leaves the window
+	// and frees a slot
+	Reset time.Duration
+
+	// RetryAfter is how long a rejected client must wait before retrying
+	RetryAfter time.Duration
+}
+
+// RateLimiter is a sliding-window log limiter. It remembers the time of the
+// requests made by each client during the last window.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu        sync.Mutex
+	clients   map[string][]time.Time
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a limiter allowing limit requests per window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string][]time.Time),
+	}
+}
+
+// Allow counts a request made by the client identified by key at now
+func (rl *RateLimiter) Allow(key string, now time.Time) RateLimitResult {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.sweep(now)
+
+	hits := rl.prune(rl.clients[key], now)
+	result := RateLimitResult{Limit: rl.limit}
+
+	if len(hits) >= rl.limit {
+		result.RetryAfter = hits[0].Add(rl.window).Sub(now)
+		result.Reset = result.RetryAfter
+		rl.clients[key] = hits
+		return result
+	}
+
+	hits = append(hits, now)
+	rl.clients[key] = hits
+
+	result.Allowed = true
+	result.Remaining = rl.limit - len(hits)
+	result.Reset = hits[0].Add(rl.window).Sub(now)
+	return result
+}
+
+// prune drops the requests that fell out of the window ending at now
+func (rl *RateLimiter) prune(hits []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-rl.window)
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	return hits[i:]
+}
+
+// sweep forgets idle clients once per window so the map does not grow
+// without bound
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rl.window {
+		return
+	}
+	rl.lastSweep = now
+
+	for key, hits := range rl.clients {
+		if len(rl.prune(hits, now)) == 0 {
+			delete(rl.clients, key)
+		}
+	}
+}
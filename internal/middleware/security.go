@@ -21,15 +21,3 @@ func Security() gin.HandlerFunc {
 		c.Next()
 	}
 }
-
-// RateLimitInfo middleware adds rate limiting information headers
-func RateLimitInfo() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Add rate limiting headers (placeholder for future implementation)
-		c.Header("X-RateLimit-Limit", "1000")
-		c.Header("X-RateLimit-Remaining", "999")
-		c.Header("X-RateLimit-Reset", "3600")
-
-		c.Next()
-	}
-}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+
+	"agenda/internal/api"
+	"agenda/internal/timezone"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeZoneHeader is the request header naming the client time zone
+const TimeZoneHeader = "X-Time-Zone"
+
+// TimeZone middleware resolves the client time zone from the tz query
+// parameter or the X-Time-Zone header, in that order, and attaches it to the
+// request context. Days and months are computed in that zone, which
+// defaults to UTC. Unknown zones are rejected.
+func TimeZone() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Query("tz")
+		if name == "" {
+			name = c.GetHeader(TimeZoneHeader)
+		}
+
+		loc, err := timezone.Load(name)
+		if err != nil {
+			response := api.ErrorResponse{
+				Error: api.ErrorDetail{
+					Code:    "INVALID_TIME_ZONE",
+					Message: "Time zone must be an IANA time zone name such as Europe/Paris",
+					Details: map[string]interface{}{
+						"received": name,
+					},
+				},
+			}
+			c.JSON(http.StatusBadRequest, response)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(timezone.WithLocation(c.Request.Context(), loc))
+		c.Next()
+	}
+}
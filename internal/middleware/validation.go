@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"mime"
 	"net/http"
 	"strings"
 
@@ -9,12 +10,23 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// ContentTypeValidation middleware validates content-type for POST/PUT requests
-func ContentTypeValidation() gin.HandlerFunc {
+// ContentTypeValidation middleware validates content-type for POST/PUT/PATCH requests.
+// Requests must be JSON unless other allowed content types are given. PATCH
+// requests may also be JSON merge patches or JSON Patch documents.
+func ContentTypeValidation(allowed ...string) gin.HandlerFunc {
+	if len(allowed) == 0 {
+		allowed = []string{"application/json"}
+	}
+	patchAllowed := append([]string{api.MediaTypeMergePatch, api.MediaTypeJSONPatch}, allowed...)
+
 	return func(c *gin.Context) {
 		// Only validate content-type for requests with body
-		if c.Request.Method == http.MethodPost || c.Request.Method == http.MethodPut {
+		if c.Request.Method == http.MethodPost || c.Request.Method == http.MethodPut || c.Request.Method == http.MethodPatch {
 			contentType := c.GetHeader("Content-Type")
+			types := allowed
+			if c.Request.Method == http.MethodPatch {
+				types = patchAllowed
+			}
 
 			// Allow empty content-type for requests without body
 			if c.Request.ContentLength == 0 {
@@ -22,15 +34,15 @@ func ContentTypeValidation() gin.HandlerFunc {
 				return
 			}
 
-			// Check if content-type is JSON
-			if !strings.HasPrefix(contentType, "application/json") {
+			// Check if content-type is one of the allowed types
+			if !hasContentType(contentType, types) {
 				response := api.ErrorResponse{
 					Error: api.ErrorDetail{
 						Code:    "INVALID_CONTENT_TYPE",
-						Message: "Content-Type must be application/json",
+						Message: "Content-Type must be " + strings.Join(types, " or "),
 						Details: map[string]interface{}{
 							"received": contentType,
-							"expected": "application/json",
+							"expected": strings.Join(types, ", "),
 						},
 					},
 				}
@@ -44,6 +56,20 @@ func ContentTypeValidation() gin.HandlerFunc {
 	}
 }
 
+// hasContentType checks if the content-type header matches one of the allowed types
+func hasContentType(contentType string, allowed []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range allowed {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
 // APIVersioning middleware adds API version information
 func APIVersioning() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+// Attendee roles, the iCalendar ROLE values (RFC 5545 section 3.2.16)
+const (
+	// AttendeeChair attendees lead the event
+	AttendeeChair = "CHAIR"
+	// AttendeeRequired attendees are expected to take part
+	AttendeeRequired = "REQ-PARTICIPANT"
+	// AttendeeOptional attendees may take part
+	AttendeeOptional = "OPT-PARTICIPANT"
+	// AttendeeNonParticipant attendees are informed of the event only
+	AttendeeNonParticipant = "NON-PARTICIPANT"
+)
+
+// RSVP statuses, the iCalendar PARTSTAT values of events (RFC 5545 section
+// 3.2.12)
+const (
+	// RSVPNeedsAction attendees have not answered yet
+	RSVPNeedsAction = "NEEDS-ACTION"
+	RSVPAccepted    = "ACCEPTED"
+	RSVPDeclined    = "DECLINED"
+	RSVPTentative   = "TENTATIVE"
+)
+
+// Attendee is a participant of an event and their RSVP. Role and Status
+// use the iCalendar values, so attendees map directly to ATTENDEE
+// properties.
+type Attendee struct {
+	ID      int    `json:"id" db:"id"`
+	EventID int    `json:"event_id" db:"event_id"`
+	Name    string `json:"name" db:"name"`
+	Email   string `json:"email" db:"email"`
+
+	// UserID links the attendee to a user account, nil for people without
+	// one
+	UserID *int `json:"user_id" db:"user_id"`
+
+	Role      string    `json:"role" db:"role"`
+	Status    string    `json:"status" db:"status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CalAddress returns the iCalendar calendar address of the attendee
+func (a *Attendee) CalAddress() string {
+	return "mailto:" + a.Email
+}
+
+// IsValidAttendeeRole checks if a role is one of the supported values
+func IsValidAttendeeRole(role string) bool {
+	switch role {
+	case AttendeeChair, AttendeeRequired, AttendeeOptional, AttendeeNonParticipant:
+		return true
+	}
+	return false
+}
+
+// IsValidRSVPStatus checks if an RSVP status is one of the supported values
+func IsValidRSVPStatus(status string) bool {
+	switch status {
+	case RSVPNeedsAction, RSVPAccepted, RSVPDeclined, RSVPTentative:
+		return true
+	}
+	return false
+}
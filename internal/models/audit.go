@@ -0,0 +1,87 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Audit entity types
+const (
+	AuditEntityTask  = "task"
+	AuditEntityEvent = "event"
+)
+
+// AuditEntry records a change made to a task or event. Entries are never
+// changed or deleted once written.
+type AuditEntry struct {
+	ID         int    `json:"id" db:"id"`
+	EntityType string `json:"entity_type" db:"entity_type"` // "task" or "event"
+	EntityID   int    `json:"entity_id" db:"entity_id"`
+
+	// Action is what happened to the item: "created", "updated",
+	// "completed", "reopened", "deleted" or "restored"
+	Action string `json:"action" db:"action"`
+
+	// ActorID is the user who made the change, nil for changes made without
+	// authentication
+	ActorID *int `json:"actor_id" db:"actor_id"`
+
+	// Changes holds the value of every changed field before and after the
+	// change
+	Changes FieldChanges `json:"changes" db:"changes"`
+
+	// UserID is the owner of the changed item, nil for items created
+	// without authentication
+	UserID *int `json:"-" db:"user_id"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// FieldChange is the JSON value of a field before and after a change. A
+// nil value stands for null, including fields the item did not have yet or
+// no longer has.
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// FieldChanges maps the JSON names of changed fields to their change. It is
+// stored as a JSON object.
+type FieldChanges map[string]FieldChange
+
+// Value implements driver.Valuer
+func (fc FieldChanges) Value() (driver.Value, error) {
+	if fc == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(fc)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (fc *FieldChanges) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*fc = FieldChanges{}
+		return nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("cannot scan %T into FieldChanges", src)
+	}
+
+	changes := FieldChanges{}
+	if err := json.Unmarshal(raw, &changes); err != nil {
+		return fmt.Errorf("cannot scan FieldChanges: %w", err)
+	}
+	*fc = changes
+	return nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+// Calendar kinds
+const (
+	CalendarPersonal = "personal"
+	CalendarTeam     = "team"
+	// CalendarRoom calendars hold the bookings of a room or other resource
+	CalendarRoom = "room"
+)
+
+// Conflict policies, applied when an event saved in a calendar overlaps
+// the events of the calendar or of its attendees
+const (
+	// ConflictReject refuses to save the event
+	ConflictReject = "reject"
+	// ConflictWarn saves the event and returns the conflicts with it
+	ConflictWarn = "warn"
+	// ConflictIgnore saves the event without checking for conflicts
+	ConflictIgnore = "ignore"
+)
+
+// DefaultConflictPolicy applies to the events without a calendar
+const DefaultConflictPolicy = ConflictReject
+
+// Calendar groups events, such as the events of a person, a team or a room.
+// Time conflicts are checked within a calendar.
+type Calendar struct {
+	ID             int    `json:"id" db:"id"`
+	Name           string `json:"name" db:"name"`
+	Description    string `json:"description" db:"description"`
+	Kind           string `json:"kind" db:"kind"`
+	ConflictPolicy string `json:"conflict_policy" db:"conflict_policy"`
+
+	// UserID is the owner of the calendar, nil for rows created without
+	// authentication
+	UserID *int `json:"-" db:"user_id"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsValidCalendarKind checks if a calendar kind is one of the supported
+// values
+func IsValidCalendarKind(kind string) bool {
+	switch kind {
+	case CalendarPersonal, CalendarTeam, CalendarRoom:
+		return true
+	}
+	return false
+}
+
+// IsValidConflictPolicy checks if a conflict policy is one of the supported
+// values
+func IsValidConflictPolicy(policy string) bool {
+	switch policy {
+	case ConflictReject, ConflictWarn, ConflictIgnore:
+		return true
+	}
+	return false
+}
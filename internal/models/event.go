@@ -11,11 +11,107 @@ type Event struct {
 	Description string    `json:"description" db:"description"`
 	StartTime   time.Time `json:"start_time" db:"start_time"`
 	EndTime     time.Time `json:"end_time" db:"end_time"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// TimeZone is the IANA time zone the event was scheduled in. Recurring
+	// events repeat at the same wall-clock time in that zone across DST
+	// changes. Empty means UTC.
+	TimeZone string `json:"time_zone,omitempty" db:"time_zone"`
+
+	// Recurrence fields. A series master carries an RFC 5545 RRULE and the
+	// EXDATEs removed from it; an override is a concrete row that replaces
+	// the occurrence of ParentID originally starting at RecurrenceID.
+	// Expanded occurrences share the master's ID and set RecurrenceID.
+	RecurrenceRule string     `json:"recurrence_rule,omitempty" db:"recurrence_rule"`
+	ExDates        TimeList   `json:"exdates,omitempty" db:"exdates"`
+	ParentID       *int       `json:"parent_id,omitempty" db:"parent_id"`
+	RecurrenceID   *time.Time `json:"recurrence_id,omitempty" db:"recurrence_id"`
+
+	// UID is the iCalendar unique identifier of the event. Overrides share
+	// the UID of their series and leave it empty.
+	UID string `json:"uid,omitempty" db:"uid"`
+
+	// CalendarID is the calendar the event belongs to, nil for events
+	// without a calendar. Overrides belong to the calendar of their series.
+	CalendarID *int `json:"calendar_id,omitempty" db:"calendar_id"`
+
+	// Attendees are loaded from the event_attendees table. Overrides and
+	// occurrences have the attendees of their series.
+	Attendees []Attendee `json:"attendees" db:"-"`
+
+	// Reminders are only loaded for the changes made to the reminders of
+	// the event
+	Reminders []Reminder `json:"reminders,omitempty" db:"-"`
+
+	// UserID is the owner of the event, nil for rows created without
+	// authentication
+	UserID *int `json:"-" db:"user_id"`
+
+	// Version is incremented by every update of the event and serves as
+	// its ETag
+	Version int `json:"version" db:"version"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// DeletedAt is set while the event is in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Conflicts are the events the event was saved in conflict with, set
+	// when its calendar warns about conflicts instead of rejecting them
+	Conflicts []*Event `json:"conflicts,omitempty" db:"-"`
 }
 
 // IsValidTimeRange checks if the event has a valid time range
 func (e *Event) IsValidTimeRange() bool {
 	return e.EndTime.After(e.StartTime)
-}
\ No newline at end of file
+}
+
+// IsRecurring reports whether the event is the master of a recurring series
+func (e *Event) IsRecurring() bool {
+	return e.RecurrenceRule != ""
+}
+
+// Location returns the time zone of the event, UTC when it has none or an
+// unknown one
+func (e *Event) Location() *time.Location {
+	if e.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(e.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// LocalStart returns the start of the event in its own time zone, the
+// reference time recurrence rules are expanded from
+func (e *Event) LocalStart() time.Time {
+	return e.StartTime.In(e.Location())
+}
+
+// SameCalendar reports whether two events belong to the same calendar, or
+// both to none
+func (e *Event) SameCalendar(other *Event) bool {
+	if e.CalendarID == nil || other.CalendarID == nil {
+		return e.CalendarID == nil && other.CalendarID == nil
+	}
+	return *e.CalendarID == *other.CalendarID
+}
+
+// Duration returns the length of the event
+func (e *Event) Duration() time.Duration {
+	return e.EndTime.Sub(e.StartTime)
+}
+
+// Occurrence returns a copy of a recurring event moved to the occurrence
+// starting at start, with RecurrenceID identifying the occurrence
+func (e *Event) Occurrence(start time.Time) *Event {
+	occurrence := *e
+	occurrence.StartTime = start
+	occurrence.EndTime = start.Add(e.Duration())
+	recurrenceID := start
+	occurrence.RecurrenceID = &recurrenceID
+	occurrence.ExDates = nil
+	return &occurrence
+}
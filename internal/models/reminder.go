@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// Reminder delivery statuses
+const (
+	// ReminderPending reminders wait for RemindAt to be delivered
+	ReminderPending = "pending"
+	// ReminderSending reminders have been claimed by the scheduler and are
+	// being delivered
+	ReminderSending = "sending"
+	// ReminderSent reminders have been delivered
+	ReminderSent = "sent"
+	// ReminderFailed reminders gave up after their last delivery attempt
+	ReminderFailed = "failed"
+	// ReminderCancelled reminders no longer apply, for example because the
+	// task was completed or lost its due date
+	ReminderCancelled = "cancelled"
+)
+
+// Reminder represents a notification delivered a number of minutes before a
+// task is due or an event starts
+type Reminder struct {
+	ID      int  `json:"id" db:"id"`
+	TaskID  *int `json:"task_id,omitempty" db:"task_id"`
+	EventID *int `json:"event_id,omitempty" db:"event_id"`
+
+	// OffsetMinutes is how long before the due date or start time the
+	// reminder fires
+	OffsetMinutes int `json:"offset_minutes" db:"offset_minutes"`
+
+	// OccurrenceAt is the due date or occurrence start time the reminder is
+	// for. Reminders of recurring events move on to the next occurrence
+	// once delivered.
+	OccurrenceAt time.Time `json:"occurrence_at" db:"occurrence_at"`
+
+	// RemindAt is the time of the next delivery attempt
+	RemindAt time.Time `json:"remind_at" db:"remind_at"`
+
+	Status    string     `json:"status" db:"status"`
+	Attempts  int        `json:"attempts" db:"attempts"`
+	LastError string     `json:"last_error,omitempty" db:"last_error"`
+	SentAt    *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+
+	// UserID is the owner of the reminder, nil for rows created without
+	// authentication
+	UserID *int `json:"-" db:"user_id"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Schedule points the reminder at a new occurrence and re-arms it
+func (r *Reminder) Schedule(occurrence time.Time) {
+	r.OccurrenceAt = occurrence
+	r.RemindAt = occurrence.Add(-time.Duration(r.OffsetMinutes) * time.Minute)
+	r.Status = ReminderPending
+	r.Attempts = 0
+	r.LastError = ""
+}
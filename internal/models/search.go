@@ -0,0 +1,21 @@
+package models
+
+// Search result types
+const (
+	SearchTypeTask  = "task"
+	SearchTypeEvent = "event"
+)
+
+// SearchResult is a task or event matching a full-text search. Title and
+// Snippet are HTML-escaped, with the matched terms wrapped in <mark> tags.
+type SearchResult struct {
+	Type    string  `json:"type"`
+	ID      int     `json:"id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+
+	// Task or Event holds the matching item, depending on Type
+	Task  *Task  `json:"task,omitempty"`
+	Event *Event `json:"event,omitempty"`
+}
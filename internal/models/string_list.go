@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// StringList is a list of strings stored as a comma-separated text column.
+// Items cannot contain commas.
+type StringList []string
+
+// Contains reports whether the list contains s
+func (sl StringList) Contains(s string) bool {
+	for _, item := range sl {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Value implements driver.Valuer
+func (sl StringList) Value() (driver.Value, error) {
+	return strings.Join(sl, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (sl *StringList) Scan(src interface{}) error {
+	var raw string
+	switch v := src.(type) {
+	case nil:
+		*sl = nil
+		return nil
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", src)
+	}
+
+	var list StringList
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*sl = list
+	return nil
+}
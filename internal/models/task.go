@@ -6,13 +6,34 @@ import (
 
 // Task represents a task in the system
 type Task struct {
-	ID          int        `json:"id" db:"id"`
-	Title       string     `json:"title" db:"title"`
-	Description string     `json:"description" db:"description"`
-	DueDate     *time.Time `json:"due_date" db:"due_date"`
-	Status      string     `json:"status" db:"status"` // "pending", "completed"
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID             int        `json:"id" db:"id"`
+	Title          string     `json:"title" db:"title"`
+	Description    string     `json:"description" db:"description"`
+	DueDate        *time.Time `json:"due_date" db:"due_date"`
+	Status         string     `json:"status" db:"status"`     // "pending", "completed"
+	Priority       string     `json:"priority" db:"priority"` // "low", "medium", "high", "urgent"
+	ParentID       *int       `json:"parent_id" db:"parent_id"`
+	RecurrenceRule string     `json:"recurrence_rule" db:"recurrence_rule"`   // RRULE anchored at the due date
+	RecurAfterDays int        `json:"recur_after_days" db:"recur_after_days"` // Next instance due N days after completion
+	SeriesID       *int       `json:"series_id" db:"series_id"`               // First instance of a recurring task
+	CompletedAt    *time.Time `json:"completed_at" db:"completed_at"`
+	UID            string     `json:"uid" db:"uid"`         // iCalendar unique identifier
+	UserID         *int       `json:"-" db:"user_id"`       // Owner; nil for rows created without authentication
+	Version        int        `json:"version" db:"version"` // Incremented by every update; serves as the ETag
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // Set while the task is in the trash
+
+	Tags      []string        `json:"tags" db:"-"`                // Loaded from the task_tags table
+	BlockedBy []int           `json:"blocked_by" db:"-"`          // IDs of the tasks that must be completed first
+	Subtasks  SubtaskProgress `json:"subtasks" db:"-"`            // Completion of the subtasks, at any depth
+	Reminders []Reminder      `json:"reminders,omitempty" db:"-"` // Loaded only for the changes made to the reminders
+}
+
+// SubtaskProgress rolls up the completion of the subtasks of a task
+type SubtaskProgress struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
 }
 
 // TaskStatus constants
@@ -21,7 +42,42 @@ const (
 	TaskStatusCompleted = "completed"
 )
 
+// TaskPriority constants, from least to most important
+const (
+	TaskPriorityLow    = "low"
+	TaskPriorityMedium = "medium"
+	TaskPriorityHigh   = "high"
+	TaskPriorityUrgent = "urgent"
+)
+
+// IsValidTaskPriority checks if the provided priority is valid
+func IsValidTaskPriority(priority string) bool {
+	switch priority {
+	case TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh, TaskPriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// IsValidPriority checks if the provided priority is valid
+func (t *Task) IsValidPriority(priority string) bool {
+	return IsValidTaskPriority(priority)
+}
+
+// IsRecurring reports whether completing the task creates a next instance
+func (t *Task) IsRecurring() bool {
+	return t.RecurrenceRule != "" || t.RecurAfterDays > 0
+}
+
+// SeriesRootID returns the ID of the first instance of the task's series
+func (t *Task) SeriesRootID() int {
+	if t.SeriesID != nil {
+		return *t.SeriesID
+	}
+	return t.ID
+}
+
 // IsValidStatus checks if the provided status is valid
 func (t *Task) IsValidStatus(status string) bool {
 	return status == TaskStatusPending || status == TaskStatusCompleted
-}
\ No newline at end of file
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimeList is a list of instants stored as a comma-separated text column
+type TimeList []time.Time
+
+// Contains reports whether the list contains an instant equal to t
+func (tl TimeList) Contains(t time.Time) bool {
+	for _, item := range tl {
+		if item.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal reports whether both lists contain the same instants, in any order
+func (tl TimeList) Equal(other TimeList) bool {
+	if len(tl) != len(other) {
+		return false
+	}
+	for _, item := range tl {
+		if !other.Contains(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Value implements driver.Valuer
+func (tl TimeList) Value() (driver.Value, error) {
+	items := make([]string, 0, len(tl))
+	for _, t := range tl {
+		items = append(items, t.UTC().Format(time.RFC3339Nano))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (tl *TimeList) Scan(src interface{}) error {
+	var raw string
+	switch v := src.(type) {
+	case nil:
+		*tl = nil
+		return nil
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into TimeList", src)
+	}
+
+	var list TimeList
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, item)
+		if err != nil {
+			return fmt.Errorf("invalid time %q in TimeList: %w", item, err)
+		}
+		list = append(list, t)
+	}
+	*tl = list
+	return nil
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Trash item types
+const (
+	TrashTypeTask  = "task"
+	TrashTypeEvent = "event"
+)
+
+// TrashItem is a deleted task or event waiting in the trash until it is
+// restored or purged
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deleted_at"`
+
+	// Task or Event holds the deleted item, depending on Type
+	Task  *Task  `json:"task,omitempty"`
+	Event *Event `json:"event,omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// UndoRecord records a change made to a task or event, so that it can be
+// undone with the token returned by the request that made it
+type UndoRecord struct {
+	ID         int    `json:"id" db:"id"`
+	Token      string `json:"-" db:"token"`
+	EntityType string `json:"entity_type" db:"entity_type"` // "task" or "event"
+	EntityID   int    `json:"entity_id" db:"entity_id"`
+
+	// Action is what happened to the item, as in AuditEntry
+	Action string `json:"action" db:"action"`
+
+	// Version is the version of the item right after the change. The
+	// change is only undone while the item is still at this version.
+	Version int `json:"version" db:"version"`
+
+	// Previous is the JSON representation of the item before an update,
+	// nil for changes that create, delete or restore it
+	Previous *string `json:"-" db:"previous"`
+
+	// UserID is the owner of the changed item, nil for items created
+	// without authentication
+	UserID *int `json:"-" db:"user_id"`
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UndoneAt  *time.Time `json:"undone_at,omitempty" db:"undone_at"`
+}
+
+// UndoneChange is a change reverted by an undo
+type UndoneChange struct {
+	EntityType string `json:"entity_type"`
+	EntityID   int    `json:"entity_id"`
+	// Action is the action that was undone
+	Action string `json:"action"`
+
+	// Task or Event holds the item as it is after the undo, depending on
+	// EntityType. Both are nil when the undo moved the item to the trash.
+	Task  *Task  `json:"task,omitempty"`
+	Event *Event `json:"event,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// User represents an account that owns tasks and events
+type User struct {
+	ID           int       `json:"id" db:"id"`
+	Email        string    `json:"email" db:"email"`
+	Name         string    `json:"name" db:"name"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Session represents a login session identified by a bearer token. Only the
+// digest of the token is stored.
+type Session struct {
+	TokenHash string    `json:"-" db:"token_hash"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsExpired reports whether the session is no longer valid at the given time
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
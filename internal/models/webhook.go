@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	// DeliveryPending deliveries wait for NextAttemptAt to be sent
+	DeliveryPending = "pending"
+	// DeliveryDelivering deliveries have been claimed by the dispatcher and
+	// are being sent
+	DeliveryDelivering = "delivering"
+	// DeliverySucceeded deliveries were accepted by the receiver
+	DeliverySucceeded = "succeeded"
+	// DeliveryFailed deliveries gave up after their last attempt
+	DeliveryFailed = "failed"
+)
+
+// Webhook is a subscription delivering task and event changes to a URL
+type Webhook struct {
+	ID  int    `json:"id" db:"id"`
+	URL string `json:"url" db:"url"`
+
+	// Secret signs the deliveries. It is only returned when the webhook is
+	// created.
+	Secret string `json:"-" db:"secret"`
+
+	// EventTypes are the change types delivered to the webhook
+	EventTypes  StringList `json:"event_types" db:"event_types"`
+	Description string     `json:"description" db:"description"`
+	Active      bool       `json:"active" db:"active"`
+
+	// UserID is the owner of the webhook, nil for rows created without
+	// authentication
+	UserID *int `json:"-" db:"user_id"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Subscribes reports whether changes of the given type are delivered to the
+// webhook
+func (w *Webhook) Subscribes(eventType string) bool {
+	return w.Active && w.EventTypes.Contains(eventType)
+}
+
+// RawJSON is a JSON document stored as text and embedded as is when
+// marshalled
+type RawJSON string
+
+// MarshalJSON implements json.Marshaler
+func (r RawJSON) MarshalJSON() ([]byte, error) {
+	if r == "" {
+		return []byte("null"), nil
+	}
+	return []byte(r), nil
+}
+
+// WebhookDelivery is a change waiting to be delivered to, or delivered to,
+// a webhook. Deliveries are written in the transaction making the change,
+// so they form an outbox of the changes to deliver.
+type WebhookDelivery struct {
+	ID        int `json:"id" db:"id"`
+	WebhookID int `json:"webhook_id" db:"webhook_id"`
+
+	// UID identifies the delivery to receivers, which can use it to ignore
+	// repeated deliveries
+	UID       string  `json:"uid" db:"uid"`
+	EventType string  `json:"event_type" db:"event_type"`
+	Payload   RawJSON `json:"payload" db:"payload"`
+
+	Status         string     `json:"status" db:"status"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      string     `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+
+	// UserID is the owner of the delivery, nil for rows created without
+	// authentication
+	UserID *int `json:"-" db:"user_id"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Attempts made so far, only loaded for a single delivery
+	AttemptLog []*WebhookDeliveryAttempt `json:"attempt_log,omitempty" db:"-"`
+}
+
+// WebhookDeliveryAttempt records one attempt at sending a delivery
+type WebhookDeliveryAttempt struct {
+	ID         int `json:"id" db:"id"`
+	DeliveryID int `json:"delivery_id" db:"delivery_id"`
+	Attempt    int `json:"attempt" db:"attempt"`
+
+	// StatusCode is the HTTP status of the response, 0 when no response
+	// was received
+	StatusCode   int       `json:"status_code" db:"status_code"`
+	Error        string    `json:"error,omitempty" db:"error"`
+	ResponseBody string    `json:"response_body,omitempty" db:"response_body"`
+	DurationMS   int       `json:"duration_ms" db:"duration_ms"`
+	AttemptedAt  time.Time `json:"attempted_at" db:"attempted_at"`
+}
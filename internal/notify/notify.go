@@ -0,0 +1,187 @@
+// Package notify delivers reminder notifications through pluggable
+// backends: an HTTP webhook, SMTP email and a local log used in development
+// and tests.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notification kinds
+const (
+	KindTask  = "task"
+	KindEvent = "event"
+)
+
+// Notification describes a reminder being delivered
+type Notification struct {
+	ReminderID int    `json:"reminder_id"`
+	Kind       string `json:"kind"`
+	ItemID     int    `json:"item_id"`
+	Title      string `json:"title"`
+
+	// At is the due date of the task or the start time of the event
+	// occurrence
+	At            time.Time `json:"at"`
+	OffsetMinutes int       `json:"offset_minutes"`
+
+	// Recipient is the email address of the owner, empty when the item has
+	// no owner
+	Recipient string `json:"recipient,omitempty"`
+}
+
+// Subject returns a one-line summary of the notification
+func (n Notification) Subject() string {
+	if n.Kind == KindEvent {
+		return fmt.Sprintf("Reminder: %s starts at %s", n.Title, n.At.Format(time.RFC1123))
+	}
+	return fmt.Sprintf("Reminder: %s is due at %s", n.Title, n.At.Format(time.RFC1123))
+}
+
+// IdempotencyKey identifies the delivery of a reminder for one due date or
+// occurrence. Retries of a delivery share its key, so receivers can drop
+// the notifications they already got.
+func (n Notification) IdempotencyKey() string {
+	return fmt.Sprintf("reminder-%d-%d", n.ReminderID, n.At.Unix())
+}
+
+// Notifier delivers notifications. Notify returns an error when the
+// notification could not be delivered and may be retried.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to a logger and keeps them in memory,
+// which makes it suitable for development and tests
+type LogNotifier struct {
+	logger *log.Logger
+
+	mu   sync.Mutex
+	sent []Notification
+}
+
+// NewLogNotifier creates a notifier writing to logger, or to the standard
+// logger when logger is nil
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogNotifier{logger: logger}
+}
+
+// Notify implements Notifier
+func (ln *LogNotifier) Notify(ctx context.Context, n Notification) error {
+	ln.logger.Printf("reminder %d: %s", n.ReminderID, n.Subject())
+
+	ln.mu.Lock()
+	defer ln.mu.Unlock()
+	ln.sent = append(ln.sent, n)
+	return nil
+}
+
+// Sent returns the notifications delivered so far
+func (ln *LogNotifier) Sent() []Notification {
+	ln.mu.Lock()
+	defer ln.mu.Unlock()
+	return append([]Notification(nil), ln.sent...)
+}
+
+// multiNotifier fans notifications out to several notifiers
+type multiNotifier []Notifier
+
+// Multi returns a notifier delivering every notification to all of
+// notifiers. Delivery succeeds when at least one of them succeeds, so a
+// backend that is down does not make the others deliver duplicates on
+// retry; the failures of the other backends are logged.
+func Multi(notifiers ...Notifier) Notifier {
+	if len(notifiers) == 1 {
+		return notifiers[0]
+	}
+	return multiNotifier(notifiers)
+}
+
+// Notify implements Notifier
+func (mn multiNotifier) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range mn {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == len(mn) {
+		return errors.Join(errs...)
+	}
+	for _, err := range errs {
+		log.Printf("reminder %d: partial delivery failure: %v", n.ReminderID, err)
+	}
+	return nil
+}
+
+// FromEnv builds the notifier configured by the environment.
+// REMINDER_NOTIFIERS is a comma-separated list of backends ("log",
+// "webhook", "smtp") and defaults to "log". The webhook backend posts to
+// REMINDER_WEBHOOK_URL; the smtp backend sends through SMTP_HOST and
+// SMTP_PORT (default 587), authenticating with SMTP_USERNAME and
+// SMTP_PASSWORD when set, from SMTP_FROM.
+func FromEnv() (Notifier, error) {
+	names := os.Getenv("REMINDER_NOTIFIERS")
+	if strings.TrimSpace(names) == "" {
+		names = "log"
+	}
+
+	var notifiers []Notifier
+	for _, name := range strings.Split(names, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+			continue
+		case "log":
+			notifiers = append(notifiers, NewLogNotifier(nil))
+		case "webhook":
+			url := os.Getenv("REMINDER_WEBHOOK_URL")
+			if url == "" {
+				return nil, errors.New("REMINDER_WEBHOOK_URL is required by the webhook notifier")
+			}
+			notifiers = append(notifiers, NewWebhookNotifier(url))
+		case "smtp":
+			host := os.Getenv("SMTP_HOST")
+			if host == "" {
+				return nil, errors.New("SMTP_HOST is required by the smtp notifier")
+			}
+			port := 587
+			if value := os.Getenv("SMTP_PORT"); value != "" {
+				parsed, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid SMTP_PORT %q", value)
+				}
+				port = parsed
+			}
+			from := os.Getenv("SMTP_FROM")
+			if from == "" {
+				return nil, errors.New("SMTP_FROM is required by the smtp notifier")
+			}
+			notifiers = append(notifiers, NewSMTPNotifier(SMTPConfig{
+				Host:     host,
+				Port:     port,
+				Username: os.Getenv("SMTP_USERNAME"),
+				Password: os.Getenv("SMTP_PASSWORD"),
+				From:     from,
+			}))
+		default:
+			return nil, fmt.Errorf("unknown reminder notifier %q", name)
+		}
+	}
+
+	if len(notifiers) == 0 {
+		return nil, errors.New("no reminder notifiers configured")
+	}
+	return Multi(notifiers...), nil
+}
@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testNotification() Notification {
+	return Notification{
+		ReminderID:    1,
+		Kind:          KindEvent,
+		ItemID:        7,
+		Title:         "Design review",
+		At:            time.Date(2030, time.May, 6, 14, 0, 0, 0, time.UTC),
+		OffsetMinutes: 15,
+		Recipient:     "alice@example.com",
+	}
+}
+
+// failingNotifier always fails to deliver
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(ctx context.Context, n Notification) error {
+	return errors.New("backend down")
+}
+
+func TestLogNotifier(t *testing.T) {
+	var out strings.Builder
+	notifier := NewLogNotifier(log.New(&out, "", 0))
+
+	if err := notifier.Notify(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(notifier.Sent()) != 1 {
+		t.Errorf("Expected 1 recorded notification, got %d", len(notifier.Sent()))
+	}
+	if !strings.Contains(out.String(), "Design review starts at") {
+		t.Errorf("Unexpected log output %q", out.String())
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Notification
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		if r.Header.Get("Idempotency-Key") != testNotification().IdempotencyKey() {
+			t.Errorf("Unexpected idempotency key %q", r.Header.Get("Idempotency-Key"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("Invalid payload: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL)
+	if err := notifier.Notify(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if received.ReminderID != 1 || received.Title != "Design review" || received.Kind != KindEvent {
+		t.Errorf("Unexpected payload %+v", received)
+	}
+
+	status = http.StatusBadGateway
+	if err := notifier.Notify(context.Background(), testNotification()); err == nil {
+		t.Error("Expected an error for a non-2xx response")
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	notifier := NewSMTPNotifier(SMTPConfig{Host: "mail.example.com", Port: 2525, From: "agenda@example.com"})
+
+	var addr string
+	var to []string
+	var msg string
+	notifier.sendMail = func(ctx context.Context, a string, auth smtp.Auth, from string, recipients []string, body []byte) error {
+		addr, to, msg = a, recipients, string(body)
+		return nil
+	}
+
+	if err := notifier.Notify(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if addr != "mail.example.com:2525" {
+		t.Errorf("Unexpected server address %q", addr)
+	}
+	if len(to) != 1 || to[0] != "alice@example.com" {
+		t.Errorf("Unexpected recipients %v", to)
+	}
+	if !strings.Contains(msg, "Subject: Reminder: Design review starts at") {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	if !strings.Contains(msg, "Message-ID: <"+testNotification().IdempotencyKey()+"@example.com>") {
+		t.Errorf("Expected the idempotency key as the message ID, got %q", msg)
+	}
+
+	n := testNotification()
+	n.Recipient = ""
+	if err := notifier.Notify(context.Background(), n); err == nil {
+		t.Error("Expected an error without a recipient")
+	}
+}
+
+func TestSMTPNotifier_Timeout(t *testing.T) {
+	// A server accepting connections without ever greeting the client
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	notifier := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: port, From: "agenda@example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := notifier.Notify(ctx, testNotification()); err == nil {
+		t.Fatal("Expected an error from a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the delivery to give up with its context, took %v", elapsed)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	ctx := context.Background()
+	logged := NewLogNotifier(log.New(io.Discard, "", 0))
+
+	if err := Multi(failingNotifier{}, logged).Notify(ctx, testNotification()); err != nil {
+		t.Errorf("Expected delivery through the working backend, got %v", err)
+	}
+	if len(logged.Sent()) != 1 {
+		t.Errorf("Expected the working backend to deliver, got %d", len(logged.Sent()))
+	}
+
+	if err := Multi(failingNotifier{}, failingNotifier{}).Notify(ctx, testNotification()); err == nil {
+		t.Error("Expected an error when every backend fails")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("REMINDER_NOTIFIERS", "")
+	notifier, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv failed: %v", err)
+	}
+	if _, ok := notifier.(*LogNotifier); !ok {
+		t.Errorf("Expected the log notifier by default, got %T", notifier)
+	}
+
+	t.Setenv("REMINDER_NOTIFIERS", "log, webhook")
+	t.Setenv("REMINDER_WEBHOOK_URL", "")
+	if _, err := FromEnv(); err == nil {
+		t.Error("Expected an error without a webhook URL")
+	}
+
+	t.Setenv("REMINDER_WEBHOOK_URL", "https://hooks.example.com/reminders")
+	notifier, err = FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv failed: %v", err)
+	}
+	if _, ok := notifier.(multiNotifier); !ok {
+		t.Errorf("Expected a fan-out notifier, got %T", notifier)
+	}
+
+	t.Setenv("REMINDER_NOTIFIERS", "pager")
+	if _, err := FromEnv(); err == nil {
+		t.Error("Expected an error for an unknown notifier")
+	}
+}
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig configures the SMTP notifier
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// smtpTimeout bounds a single email delivery, from dialing the server to
+// the end of the session
+const smtpTimeout = 30 * time.Second
+
+// sendMailFunc matches sendMail
+type sendMailFunc func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// SMTPNotifier emails notifications to the owner of the task or event
+type SMTPNotifier struct {
+	config   SMTPConfig
+	sendMail sendMailFunc
+}
+
+// NewSMTPNotifier creates a notifier sending through the given server
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{
+		config:   config,
+		sendMail: sendMail,
+	}
+}
+
+// Notify implements Notifier
+func (sn *SMTPNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.Recipient == "" {
+		return errors.New("smtp delivery failed: reminder has no recipient")
+	}
+
+	var auth smtp.Auth
+	if sn.config.Username != "" {
+		auth = smtp.PlainAuth("", sn.config.Username, sn.config.Password, sn.config.Host)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(sn.config.Host, strconv.Itoa(sn.config.Port))
+	if err := sn.sendMail(ctx, addr, auth, sn.config.From, []string{n.Recipient}, sn.message(n)); err != nil {
+		return fmt.Errorf("smtp delivery failed: %w", err)
+	}
+	return nil
+}
+
+// sendMail works like smtp.SendMail, but gives up when ctx is done: the
+// connection is dialed with ctx and expires at its deadline
+func sendMail(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Closing the connection interrupts the session when ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	host, _, _ := net.SplitHostPort(addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if a != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("server doesn't support AUTH")
+		}
+		if err := c.Auth(a); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := c.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message formats the email carrying n
+func (sn *SMTPNotifier) message(n Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", sn.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", n.Recipient)
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(n.Subject()))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@%s>\r\n", n.IdempotencyKey(), sn.messageDomain())
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(n.Subject())
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// messageDomain returns the domain of the Message-ID header: the domain of
+// the sender, or the SMTP host when the sender has none
+func (sn *SMTPNotifier) messageDomain() string {
+	if at := strings.LastIndex(sn.config.From, "@"); at >= 0 {
+		return strings.Trim(sn.config.From[at+1:], "<> ")
+	}
+	return sn.config.Host
+}
+
+// headerValue strips line breaks that would inject extra headers
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
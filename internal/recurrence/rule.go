@@ -0,0 +1,585 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules
+// (RRULE) used by recurring events and tasks.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ part of a recurrence rule
+type Frequency string
+
+// Supported frequencies
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// untilLayout is the UTC DATE-TIME form used when formatting UNTIL
+const untilLayout = "20060102T150405Z"
+
+// maxIterations bounds the number of periods walked while expanding a rule,
+// so that rules which never match (e.g. BYMONTHDAY=31;BYMONTH=2) terminate
+const maxIterations = 100000
+
+// Parse errors
+var (
+	ErrEmptyRule           = errors.New("recurrence rule is empty")
+	ErrMissingFrequency    = errors.New("recurrence rule must specify FREQ")
+	ErrUnsupportedRulePart = errors.New("unsupported recurrence rule part")
+	ErrCountAndUntil       = errors.New("recurrence rule cannot specify both COUNT and UNTIL")
+)
+
+// WeekdayNum is a BYDAY entry: a weekday with an optional ordinal such as
+// 1MO (first Monday) or -1FR (last Friday). N is zero when no ordinal is set.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// A leading "RRULE:" property name is accepted and ignored.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "RRULE:")
+	if value == "" {
+		return nil, ErrEmptyRule
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+		key, val := strings.ToUpper(strings.TrimSpace(kv[0])), strings.TrimSpace(kv[1])
+
+		switch key {
+		case "FREQ":
+			freq := Frequency(strings.ToUpper(val))
+			switch freq {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = freq
+			default:
+				return nil, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedRulePart, val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				wd, err := parseWeekdayNum(item)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(item))
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", item)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, item := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(item))
+				if err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("invalid BYMONTH %q", item)
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			wd, ok := weekdayCodes[strings.ToUpper(val)]
+			if !ok {
+				return nil, fmt.Errorf("invalid WKST %q", val)
+			}
+			rule.WeekStart = wd
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRulePart, key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, ErrMissingFrequency
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, ErrCountAndUntil
+	}
+	for _, wd := range rule.ByDay {
+		if wd.N != 0 && rule.Freq != Monthly && rule.Freq != Yearly {
+			return nil, fmt.Errorf("BYDAY ordinals are only valid with MONTHLY or YEARLY frequency")
+		}
+	}
+
+	return rule, nil
+}
+
+// parseUntil parses an UNTIL value in DATE or DATE-TIME form
+func parseUntil(val string) (time.Time, error) {
+	layouts := []string{untilLayout, "20060102T150405", "20060102"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, val); err == nil {
+			if layout == "20060102" {
+				// A DATE value includes the whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", val)
+}
+
+// ParseWeekday parses a two-letter iCalendar weekday code such as "MO",
+// in any case
+func ParseWeekday(code string) (time.Weekday, bool) {
+	wd, ok := weekdayCodes[strings.ToUpper(strings.TrimSpace(code))]
+	return wd, ok
+}
+
+// parseWeekdayNum parses a BYDAY item such as "MO", "2TU" or "-1FR"
+func parseWeekdayNum(item string) (WeekdayNum, error) {
+	item = strings.ToUpper(strings.TrimSpace(item))
+	if len(item) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", item)
+	}
+
+	code := item[len(item)-2:]
+	wd, ok := weekdayCodes[code]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", item)
+	}
+
+	var n int
+	if prefix := item[:len(item)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", item)
+		}
+	}
+
+	return WeekdayNum{Weekday: wd, N: n}, nil
+}
+
+// String formats the rule back into its RRULE value form
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			days = append(days, wd.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, 0, len(r.ByMonth))
+		for _, m := range r.ByMonth {
+			months = append(months, strconv.Itoa(int(m)))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+// String formats the weekday as a BYDAY item
+func (w WeekdayNum) String() string {
+	if w.N != 0 {
+		return strconv.Itoa(w.N) + weekdayCode(w.Weekday)
+	}
+	return weekdayCode(w.Weekday)
+}
+
+// weekdayCode returns the two-letter RFC 5545 code for a weekday
+func weekdayCode(wd time.Weekday) string {
+	for code, day := range weekdayCodes {
+		if day == wd {
+			return code
+		}
+	}
+	return ""
+}
+
+// Between returns the occurrence start times of the rule anchored at dtstart
+// that fall within [after, before). COUNT is always counted from dtstart, so
+// the result does not depend on the window. Occurrences keep the location
+// and wall-clock time of dtstart, which keeps them stable across DST changes.
+func (r *Rule) Between(dtstart, after, before time.Time) []time.Time {
+	var result []time.Time
+	r.iterate(dtstart, before, func(t time.Time) bool {
+		if !t.Before(before) {
+			return false
+		}
+		if !t.Before(after) {
+			result = append(result, t)
+		}
+		return true
+	})
+	return result
+}
+
+// First returns up to limit occurrence start times of the rule anchored at
+// dtstart, starting with the earliest one
+func (r *Rule) First(dtstart time.Time, limit int) []time.Time {
+	var result []time.Time
+	if limit <= 0 {
+		return result
+	}
+	r.iterate(dtstart, time.Time{}, func(t time.Time) bool {
+		result = append(result, t)
+		return len(result) < limit
+	})
+	return result
+}
+
+// After returns the first occurrence strictly after t, if any
+func (r *Rule) After(dtstart, t time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	r.iterate(dtstart, time.Time{}, func(occ time.Time) bool {
+		if occ.After(t) {
+			next = occ
+			found = true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+// IsOccurrence reports whether t is one of the occurrences of the rule
+func (r *Rule) IsOccurrence(dtstart, t time.Time) bool {
+	for _, occ := range r.Between(dtstart, t, t.Add(time.Nanosecond)) {
+		if occ.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// CountBefore returns the number of occurrences that start before t
+func (r *Rule) CountBefore(dtstart, t time.Time) int {
+	return len(r.Between(dtstart, dtstart, t))
+}
+
+// iterate walks the occurrences in chronological order, calling yield for
+// each one until yield returns false or the rule is exhausted. A non-zero
+// horizon stops the walk once a period starts after it.
+func (r *Rule) iterate(dtstart, horizon time.Time, yield func(time.Time) bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	emitted := 0
+	for i := 0; i < maxIterations; i++ {
+		periodStart := r.periodStart(dtstart, i*interval)
+		if r.Until != nil && periodStart.After(*r.Until) {
+			return
+		}
+		if !horizon.IsZero() && periodStart.After(horizon) {
+			return
+		}
+
+		for _, c := range r.expandPeriod(dtstart, i*interval) {
+			if c.Before(dtstart) {
+				continue
+			}
+			if r.Until != nil && c.After(*r.Until) {
+				return
+			}
+			if !yield(c) {
+				return
+			}
+			emitted++
+			if r.Count > 0 && emitted >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// periodStart returns the first instant of the n-th period after dtstart
+func (r *Rule) periodStart(dtstart time.Time, n int) time.Time {
+	loc := dtstart.Location()
+	switch r.Freq {
+	case Daily:
+		return time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day()+n, 0, 0, 0, 0, loc)
+	case Weekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		return time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset+7*n, 0, 0, 0, 0, loc)
+	case Monthly:
+		return time.Date(dtstart.Year(), dtstart.Month()+time.Month(n), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(dtstart.Year()+n, 1, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// expandPeriod returns the sorted candidate occurrences within the n-th period
+func (r *Rule) expandPeriod(dtstart time.Time, n int) []time.Time {
+	start := r.periodStart(dtstart, n)
+	var days []time.Time
+
+	switch r.Freq {
+	case Daily:
+		if r.matchesMonth(start.Month()) && r.matchesMonthDay(start) && r.matchesWeekday(start) {
+			days = append(days, start)
+		}
+	case Weekly:
+		for d := 0; d < 7; d++ {
+			day := start.AddDate(0, 0, d)
+			if !r.matchesMonth(day.Month()) {
+				continue
+			}
+			if len(r.ByDay) == 0 {
+				if day.Weekday() == dtstart.Weekday() {
+					days = append(days, day)
+				}
+			} else if r.matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+	case Monthly:
+		if r.matchesMonth(start.Month()) {
+			days = r.expandMonth(dtstart, start.Year(), start.Month())
+		}
+	case Yearly:
+		if len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) > 0 {
+			days = r.expandYearByDay(start.Year(), start.Location())
+		} else {
+			months := r.ByMonth
+			if len(months) == 0 {
+				months = []time.Month{dtstart.Month()}
+			}
+			for _, month := range months {
+				days = append(days, r.expandMonth(dtstart, start.Year(), month)...)
+			}
+		}
+	}
+
+	occurrences := make([]time.Time, 0, len(days))
+	for _, day := range days {
+		occurrences = append(occurrences, time.Date(day.Year(), day.Month(), day.Day(),
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location()))
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+	return dedupe(occurrences)
+}
+
+// expandMonth returns the matching days of a single month
+func (r *Rule) expandMonth(dtstart time.Time, year int, month time.Month) []time.Time {
+	loc := dtstart.Location()
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	daysInMonth := first.AddDate(0, 1, -1).Day()
+
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) == 0 && len(r.ByDay) == 0:
+		// Months without the start day (e.g. the 31st) are skipped, per RFC 5545
+		if dtstart.Day() <= daysInMonth {
+			days = append(days, time.Date(year, month, dtstart.Day(), 0, 0, 0, 0, loc))
+		}
+	case len(r.ByMonthDay) > 0:
+		for _, md := range r.ByMonthDay {
+			day := md
+			if md < 0 {
+				day = daysInMonth + md + 1
+			}
+			if day < 1 || day > daysInMonth {
+				continue
+			}
+			candidate := time.Date(year, month, day, 0, 0, 0, 0, loc)
+			if len(r.ByDay) == 0 || r.matchesWeekdayInRange(candidate, first, daysInMonth) {
+				days = append(days, candidate)
+			}
+		}
+	default:
+		for d := 1; d <= daysInMonth; d++ {
+			candidate := time.Date(year, month, d, 0, 0, 0, 0, loc)
+			if r.matchesWeekdayInRange(candidate, first, daysInMonth) {
+				days = append(days, candidate)
+			}
+		}
+	}
+	return days
+}
+
+// expandYearByDay returns the days of a year matching BYDAY, where ordinals
+// count weekdays within the whole year
+func (r *Rule) expandYearByDay(year int, loc *time.Location) []time.Time {
+	first := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	daysInYear := first.AddDate(1, 0, 0).Sub(first).Hours() / 24
+
+	var days []time.Time
+	for d := 0; d < int(daysInYear+0.5); d++ {
+		candidate := first.AddDate(0, 0, d)
+		if r.matchesWeekdayInRange(candidate, first, int(daysInYear+0.5)) {
+			days = append(days, candidate)
+		}
+	}
+	return days
+}
+
+// matchesMonth reports whether the month satisfies BYMONTH
+func (r *Rule) matchesMonth(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay reports whether the day satisfies BYMONTHDAY
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	for _, md := range r.ByMonthDay {
+		if md == day.Day() || (md < 0 && daysInMonth+md+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday reports whether the day satisfies BYDAY, ignoring ordinals
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekdayInRange reports whether the day satisfies BYDAY, honoring
+// ordinals relative to a range of the given length starting at first
+func (r *Rule) matchesWeekdayInRange(day, first time.Time, length int) bool {
+	index := int(day.Sub(first).Hours()/24+0.5) + 1
+	for _, wd := range r.ByDay {
+		if wd.Weekday != day.Weekday() {
+			continue
+		}
+		if wd.N == 0 {
+			return true
+		}
+		nth := (index-1)/7 + 1
+		nthFromEnd := -((length-index)/7 + 1)
+		if wd.N == nth || wd.N == nthFromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupe removes consecutive duplicates from a sorted slice
+func dedupe(times []time.Time) []time.Time {
+	if len(times) < 2 {
+		return times
+	}
+	result := times[:1]
+	for _, t := range times[1:] {
+		if !t.Equal(result[len(result)-1]) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// Clone returns a deep copy of the rule
+func (r *Rule) Clone() *Rule {
+	clone := *r
+	if r.Until != nil {
+		until := *r.Until
+		clone.Until = &until
+	}
+	clone.ByDay = append([]WeekdayNum(nil), r.ByDay...)
+	clone.ByMonthDay = append([]int(nil), r.ByMonthDay...)
+	clone.ByMonth = append([]time.Month(nil), r.ByMonth...)
+	return &clone
+}
+
+// TruncateBefore returns a copy of the rule that ends right before t, keeping
+// the occurrences anchored at dtstart that start earlier than t
+func (r *Rule) TruncateBefore(t time.Time) *Rule {
+	truncated := r.Clone()
+	truncated.Count = 0
+	until := t.Add(-time.Second).UTC()
+	truncated.Until = &until
+	return truncated
+}
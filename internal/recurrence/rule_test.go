@@ -0,0 +1,194 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(y int, m time.Month, d, h, min int) time.Time {
+	return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "daily", value: "FREQ=DAILY"},
+		{name: "weekly with days", value: "FREQ=WEEKLY;BYDAY=MO,WE,FR;INTERVAL=2"},
+		{name: "monthly ordinal", value: "RRULE:FREQ=MONTHLY;BYDAY=-1FR;COUNT=5"},
+		{name: "yearly until", value: "FREQ=YEARLY;UNTIL=20301231T000000Z"},
+		{name: "empty", value: "", wantErr: true},
+		{name: "missing freq", value: "COUNT=3", wantErr: true},
+		{name: "unsupported part", value: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+		{name: "count and until", value: "FREQ=DAILY;COUNT=3;UNTIL=20301231", wantErr: true},
+		{name: "ordinal on weekly", value: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{name: "invalid interval", value: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRule_String(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY;INTERVAL=2;BYDAY=2TU;COUNT=4")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;COUNT=4;BYDAY=2TU", rule.String())
+
+	reparsed, err := Parse(rule.String())
+	require.NoError(t, err)
+	assert.Equal(t, rule, reparsed)
+}
+
+func TestRule_Between(t *testing.T) {
+	dtstart := date(2025, time.January, 6, 9, 30) // Monday
+
+	tests := []struct {
+		name   string
+		rule   string
+		start  time.Time
+		after  time.Time
+		before time.Time
+		want   []time.Time
+	}{
+		{
+			name:   "daily with count",
+			rule:   "FREQ=DAILY;COUNT=3",
+			after:  dtstart,
+			before: dtstart.AddDate(0, 1, 0),
+			want:   []time.Time{dtstart, dtstart.AddDate(0, 0, 1), dtstart.AddDate(0, 0, 2)},
+		},
+		{
+			name:   "weekly on monday and wednesday",
+			rule:   "FREQ=WEEKLY;BYDAY=MO,WE",
+			after:  dtstart,
+			before: dtstart.AddDate(0, 0, 14),
+			want: []time.Time{
+				dtstart, date(2025, time.January, 8, 9, 30),
+				date(2025, time.January, 13, 9, 30), date(2025, time.January, 15, 9, 30),
+			},
+		},
+		{
+			name:   "biweekly window in the middle of the series",
+			rule:   "FREQ=WEEKLY;INTERVAL=2",
+			after:  date(2025, time.February, 1, 0, 0),
+			before: date(2025, time.March, 1, 0, 0),
+			want:   []time.Time{date(2025, time.February, 3, 9, 30), date(2025, time.February, 17, 9, 30)},
+		},
+		{
+			name:   "monthly last friday",
+			rule:   "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			after:  dtstart,
+			before: dtstart.AddDate(1, 0, 0),
+			want: []time.Time{
+				date(2025, time.January, 31, 9, 30), date(2025, time.February, 28, 9, 30),
+				date(2025, time.March, 28, 9, 30),
+			},
+		},
+		{
+			name:   "monthly skips short months",
+			rule:   "FREQ=MONTHLY;COUNT=3",
+			start:  date(2025, time.January, 31, 9, 30),
+			after:  date(2025, time.January, 1, 0, 0),
+			before: date(2026, time.January, 1, 0, 0),
+			want: []time.Time{
+				date(2025, time.January, 31, 9, 30), date(2025, time.March, 31, 9, 30),
+				date(2025, time.May, 31, 9, 30),
+			},
+		},
+		{
+			name:   "until is inclusive",
+			rule:   "FREQ=DAILY;UNTIL=20250108T093000Z",
+			after:  dtstart,
+			before: dtstart.AddDate(0, 1, 0),
+			want:   []time.Time{dtstart, dtstart.AddDate(0, 0, 1), dtstart.AddDate(0, 0, 2)},
+		},
+		{
+			name:   "yearly by month",
+			rule:   "FREQ=YEARLY;BYMONTH=1,7;COUNT=3",
+			after:  dtstart,
+			before: dtstart.AddDate(3, 0, 0),
+			want: []time.Time{
+				dtstart, date(2025, time.July, 6, 9, 30), date(2026, time.January, 6, 9, 30),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			require.NoError(t, err)
+
+			start := tt.start
+			if start.IsZero() {
+				start = dtstart
+			}
+
+			assert.Equal(t, tt.want, rule.Between(start, tt.after, tt.before))
+		})
+	}
+}
+
+func TestRule_BetweenKeepsWallClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	rule, err := Parse("FREQ=WEEKLY;COUNT=3")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2025, time.March, 24, 9, 0, 0, 0, loc)
+	occurrences := rule.First(dtstart, 3)
+	require.Len(t, occurrences, 3)
+	for _, occ := range occurrences {
+		assert.Equal(t, 9, occ.In(loc).Hour())
+	}
+	assert.Equal(t, 7*24*time.Hour-time.Hour, occurrences[1].Sub(occurrences[0]))
+}
+
+func TestRule_IsOccurrenceAndCountBefore(t *testing.T) {
+	dtstart := date(2025, time.January, 6, 9, 30)
+	rule, err := Parse("FREQ=WEEKLY;COUNT=4")
+	require.NoError(t, err)
+
+	assert.True(t, rule.IsOccurrence(dtstart, date(2025, time.January, 20, 9, 30)))
+	assert.False(t, rule.IsOccurrence(dtstart, date(2025, time.January, 21, 9, 30)))
+	assert.False(t, rule.IsOccurrence(dtstart, date(2025, time.February, 3, 9, 30)))
+	assert.Equal(t, 2, rule.CountBefore(dtstart, date(2025, time.January, 20, 9, 30)))
+
+	next, ok := rule.After(dtstart, dtstart)
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, time.January, 13, 9, 30), next)
+}
+
+func TestRule_TruncateBefore(t *testing.T) {
+	dtstart := date(2025, time.January, 6, 9, 30)
+	rule, err := Parse("FREQ=DAILY;COUNT=10")
+	require.NoError(t, err)
+
+	truncated := rule.TruncateBefore(date(2025, time.January, 9, 9, 30))
+	assert.Equal(t, 0, truncated.Count)
+	assert.Len(t, truncated.Between(dtstart, dtstart, dtstart.AddDate(1, 0, 0)), 3)
+	assert.Equal(t, 10, rule.Count, "original rule must not be modified")
+}
+
+func TestRule_ImpossibleRuleTerminates(t *testing.T) {
+	rule, err := Parse("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30")
+	require.NoError(t, err)
+
+	dtstart := date(2025, time.January, 1, 0, 0)
+	assert.Empty(t, rule.First(dtstart, 1))
+}
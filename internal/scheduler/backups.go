@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"agenda/internal/database"
+)
+
+// DefaultBackupInterval is how often the database is backed up
+const DefaultBackupInterval = 24 * time.Hour
+
+// BackupIntervalFromEnv reads how often the database is backed up from the
+// BACKUP_INTERVAL environment variable, falling back to
+// DefaultBackupInterval for unset or invalid values. An interval of 0
+// disables scheduled backups.
+func BackupIntervalFromEnv() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("BACKUP_INTERVAL")); err == nil && interval >= 0 {
+		return interval
+	}
+	return DefaultBackupInterval
+}
+
+// BackupScheduler backs up the database in the background at a fixed
+// interval. Snapshots are rotated by the backup manager.
+type BackupScheduler struct {
+	backups  *database.BackupManager
+	interval time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewBackupScheduler creates a scheduler taking a snapshot with backups
+// every interval
+func NewBackupScheduler(backups *database.BackupManager, interval time.Duration) *BackupScheduler {
+	if interval <= 0 {
+		interval = DefaultBackupInterval
+	}
+
+	return &BackupScheduler{
+		backups:  backups,
+		interval: interval,
+	}
+}
+
+// Start starts backing up the database in a background goroutine until
+// Stop is called or ctx is cancelled. The first backup is taken after an
+// interval, so restarts do not pile up snapshots.
+func (s *BackupScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx)
+}
+
+// Stop stops the scheduler and waits for the backup in progress, if any,
+// to finish
+func (s *BackupScheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// run backs up the database every interval until ctx is cancelled
+func (s *BackupScheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("backup scheduler: %v", err)
+		}
+	}
+}
+
+// Tick takes a snapshot of the database
+func (s *BackupScheduler) Tick(ctx context.Context) error {
+	snapshot, err := s.backups.Backup(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("backup scheduler: wrote %s (%d bytes)", snapshot.Name, snapshot.Size)
+	return nil
+}
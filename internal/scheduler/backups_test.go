@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+)
+
+func TestBackupScheduler(t *testing.T) {
+	env := setupSchedulerTest(t)
+	if _, err := env.tasks.CreateTask(env.ctx, &models.Task{Title: "Backed up"}); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	backups, err := database.NewBackupManager(env.db, database.BackupConfig{Dir: t.TempDir(), Keep: 2})
+	if err != nil {
+		t.Fatalf("NewBackupManager failed: %v", err)
+	}
+	scheduler := NewBackupScheduler(backups, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if err := scheduler.Tick(env.ctx); err != nil {
+			t.Fatalf("Tick failed: %v", err)
+		}
+		// Snapshot names have a millisecond resolution
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	snapshots, err := backups.Snapshots()
+	if err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Errorf("Expected the snapshots to be rotated down to 2, got %d", len(snapshots))
+	}
+}
+
+func TestBackupIntervalFromEnv(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    DefaultBackupInterval,
+		"6h":  6 * time.Hour,
+		"0":   0,
+		"-1h": DefaultBackupInterval,
+		"abc": DefaultBackupInterval,
+	}
+	for value, want := range tests {
+		t.Setenv("BACKUP_INTERVAL", value)
+		if got := BackupIntervalFromEnv(); got != want {
+			t.Errorf("BACKUP_INTERVAL=%q: got %v, want %v", value, got, want)
+		}
+	}
+}
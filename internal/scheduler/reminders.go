@@ -0,0 +1,316 @@
+// Package scheduler runs the background jobs of the API server
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/notify"
+	"agenda/internal/services"
+)
+
+// Reminder scheduling settings
+const (
+	// DefaultReminderInterval is how often due reminders are looked for
+	DefaultReminderInterval = 30 * time.Second
+	// reminderBatchSize bounds the number of reminders loaded at once
+	reminderBatchSize = 100
+	// maxReminderAttempts is the number of delivery attempts made before a
+	// reminder is marked as failed
+	maxReminderAttempts = 5
+	// reminderRetryDelay is the delay before the first retry; it doubles
+	// with every further attempt
+	reminderRetryDelay = time.Minute
+)
+
+// ReminderIntervalFromEnv reads the scheduler interval from the
+// REMINDER_INTERVAL environment variable, falling back to
+// DefaultReminderInterval for unset or invalid values
+func ReminderIntervalFromEnv() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("REMINDER_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultReminderInterval
+}
+
+// ReminderScheduler delivers due reminders in the background. A reminder is
+// claimed in the database before it is delivered, so that concurrent
+// schedulers never deliver it twice, and it is retried with a growing delay
+// until the notifier accepts it. A delivery interrupted by a shutdown is
+// retried after a restart, with the same idempotency key.
+type ReminderScheduler struct {
+	reminderRepo database.ReminderRepositoryInterface
+	taskRepo     database.TaskRepositoryInterface
+	eventRepo    database.EventRepositoryInterface
+	userRepo     database.UserRepositoryInterface
+	notifier     notify.Notifier
+	interval     time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReminderScheduler creates a scheduler delivering the reminders stored
+// in db through notifier, checking for due reminders every interval
+func NewReminderScheduler(db *sql.DB, notifier notify.Notifier, interval time.Duration) *ReminderScheduler {
+	if interval <= 0 {
+		interval = DefaultReminderInterval
+	}
+
+	return &ReminderScheduler{
+		reminderRepo: database.NewReminderRepository(db),
+		taskRepo:     database.NewTaskRepository(db),
+		eventRepo:    database.NewEventRepository(db),
+		userRepo:     database.NewUserRepository(db),
+		notifier:     notifier,
+		interval:     interval,
+	}
+}
+
+// Start recovers the reminders interrupted by a previous shutdown and
+// starts delivering reminders in a background goroutine until Stop is
+// called or ctx is cancelled
+func (s *ReminderScheduler) Start(ctx context.Context) error {
+	interrupted, err := s.reminderRepo.ResetInterruptedReminders(ctx)
+	if err != nil {
+		return err
+	}
+	if interrupted > 0 {
+		log.Printf("reminder scheduler: requeued %d interrupted reminders", interrupted)
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the scheduler and waits for the delivery in progress, if any,
+// to finish
+func (s *ReminderScheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// run delivers due reminders every interval until ctx is cancelled
+func (s *ReminderScheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Tick(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("reminder scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick delivers the reminders due at now, up to a batch per call. It stops
+// early when ctx is cancelled, but always finishes the delivery in progress.
+// Deliveries are made one after the other, so the clock of the tick starts
+// at now and advances with the time they take: each delivery is timed when
+// it is actually made.
+func (s *ReminderScheduler) Tick(ctx context.Context, now time.Time) error {
+	started := time.Now()
+	clock := func() time.Time { return now.Add(time.Since(started)) }
+
+	due, err := s.reminderRepo.GetDueReminders(ctx, now, reminderBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// Deliveries run to completion so that their outcome is recorded
+	deliveryCtx := context.WithoutCancel(ctx)
+	for _, reminder := range due {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := s.process(deliveryCtx, reminder, clock); err != nil {
+			log.Printf("reminder scheduler: reminder %d: %v", reminder.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// process delivers a due reminder, or moves it to the current due date or
+// occurrence of its task or event when that changed, reading the time from
+// clock
+func (s *ReminderScheduler) process(ctx context.Context, reminder *models.Reminder, clock func() time.Time) error {
+	now := clock()
+
+	// The state the reminder was read in, which it is only saved over
+	stored := *reminder
+
+	// Load the task or event as its owner
+	ownerCtx := ctx
+	if reminder.UserID != nil {
+		ownerCtx = auth.WithUserID(ctx, *reminder.UserID)
+	}
+
+	notification, event, overrides, ok, err := s.target(ownerCtx, reminder, now)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		reminder.Status = models.ReminderCancelled
+		return s.save(ctx, &stored, reminder)
+	}
+
+	offset := time.Duration(reminder.OffsetMinutes) * time.Minute
+	if !notification.At.Equal(reminder.OccurrenceAt) {
+		if notification.At.Add(-offset).After(now) {
+			reminder.Schedule(notification.At)
+			return s.save(ctx, &stored, reminder)
+		}
+		reminder.OccurrenceAt = notification.At
+	}
+
+	claimed, err := s.reminderRepo.ClaimReminder(ctx, reminder)
+	if err != nil || !claimed {
+		return err
+	}
+	stored = *reminder
+
+	notification.Recipient = s.recipient(ctx, reminder)
+	err = s.notifier.Notify(ctx, notification)
+	// Retries and the next reminder are scheduled from when the delivery
+	// ended
+	sentAt := clock()
+	if err != nil {
+		reminder.LastError = err.Error()
+		if reminder.Attempts >= maxReminderAttempts {
+			reminder.Status = models.ReminderFailed
+		} else {
+			reminder.Status = models.ReminderPending
+			reminder.RemindAt = sentAt.Add(reminderRetryDelay << (reminder.Attempts - 1))
+		}
+		return s.save(ctx, &stored, reminder)
+	}
+
+	reminder.SentAt = &sentAt
+	reminder.Status = models.ReminderSent
+	reminder.LastError = ""
+
+	// Reminders of a recurring event move on to the first occurrence whose
+	// reminder time is still ahead
+	if event != nil && event.IsRecurring() {
+		after := sentAt.Add(offset)
+		if !notification.At.Before(after) {
+			after = notification.At.Add(time.Nanosecond)
+		}
+		if next, ok := services.NextEventOccurrence(event, overrides, after); ok {
+			reminder.Schedule(next)
+		}
+	}
+
+	return s.save(ctx, &stored, reminder)
+}
+
+// save stores the new state of a reminder read as stored. A reminder that
+// changed in the meantime, typically rescheduled after its task or event
+// was edited, keeps its new state and is left for a later tick.
+func (s *ReminderScheduler) save(ctx context.Context, stored, reminder *models.Reminder) error {
+	_, err := s.reminderRepo.SaveReminderState(ctx, stored, reminder)
+	return err
+}
+
+// target loads the task or event of a reminder and returns the notification
+// for its current due date or next occurrence. It reports false when the
+// reminder no longer applies: the task or event was deleted, the task was
+// completed or lost its due date, or the event has no occurrence left.
+func (s *ReminderScheduler) target(ctx context.Context, reminder *models.Reminder, now time.Time) (notify.Notification, *models.Event, []*models.Event, bool, error) {
+	notification := notify.Notification{
+		ReminderID:    reminder.ID,
+		OffsetMinutes: reminder.OffsetMinutes,
+	}
+
+	if reminder.TaskID != nil {
+		task, err := s.taskRepo.GetTaskByID(ctx, *reminder.TaskID)
+		if err == sql.ErrNoRows {
+			return notification, nil, nil, false, nil
+		}
+		if err != nil {
+			return notification, nil, nil, false, err
+		}
+		if task.Status == models.TaskStatusCompleted || task.DueDate == nil {
+			return notification, nil, nil, false, nil
+		}
+
+		notification.Kind = notify.KindTask
+		notification.ItemID = task.ID
+		notification.Title = task.Title
+		notification.At = *task.DueDate
+		return notification, nil, nil, true, nil
+	}
+
+	if reminder.EventID == nil {
+		return notification, nil, nil, false, nil
+	}
+
+	event, err := s.eventRepo.GetEventByID(ctx, *reminder.EventID)
+	if err == sql.ErrNoRows {
+		return notification, nil, nil, false, nil
+	}
+	if err != nil {
+		return notification, nil, nil, false, err
+	}
+
+	var overrides []*models.Event
+	if event.IsRecurring() {
+		overrides, err = s.eventRepo.GetEventOverrides(ctx, event.ID)
+		if err != nil {
+			return notification, nil, nil, false, err
+		}
+	}
+
+	// Occurrences that already started are not reminded about
+	after := now
+	if reminder.OccurrenceAt.After(after) {
+		after = reminder.OccurrenceAt
+	}
+	occurrence, ok := services.NextEventOccurrence(event, overrides, after)
+	if !ok {
+		return notification, nil, nil, false, nil
+	}
+
+	notification.Kind = notify.KindEvent
+	notification.ItemID = event.ID
+	notification.Title = event.Title
+	notification.At = occurrence
+	return notification, event, overrides, true, nil
+}
+
+// recipient returns the email address of the owner of a reminder, or an
+// empty string when it has none
+func (s *ReminderScheduler) recipient(ctx context.Context, reminder *models.Reminder) string {
+	if reminder.UserID == nil {
+		return ""
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, *reminder.UserID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("reminder scheduler: failed to load owner of reminder %d: %v", reminder.ID, err)
+		}
+		return ""
+	}
+	return user.Email
+}
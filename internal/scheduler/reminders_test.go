@@ -0,0 +1,366 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/notify"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// flakyNotifier fails the first failures deliveries, then records the rest
+type flakyNotifier struct {
+	mu       sync.Mutex
+	failures int
+	delay    time.Duration
+	sent     []notify.Notification
+}
+
+func (f *flakyNotifier) Notify(ctx context.Context, n notify.Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	time.Sleep(f.delay)
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("backend down")
+	}
+	f.sent = append(f.sent, n)
+	return nil
+}
+
+func (f *flakyNotifier) Sent() []notify.Notification {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]notify.Notification(nil), f.sent...)
+}
+
+type testEnv struct {
+	db        *sql.DB
+	ctx       context.Context
+	reminders database.ReminderRepositoryInterface
+	tasks     database.TaskRepositoryInterface
+	events    database.EventRepositoryInterface
+}
+
+func setupSchedulerTest(t *testing.T) *testEnv {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.NewMigrationService(db).RunMigrations(); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	user, err := database.NewUserRepository(db).CreateUser(context.Background(),
+		&models.User{Email: "alice@example.com", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	return &testEnv{
+		db:        db,
+		ctx:       auth.WithUserID(context.Background(), user.ID),
+		reminders: database.NewReminderRepository(db),
+		tasks:     database.NewTaskRepository(db),
+		events:    database.NewEventRepository(db),
+	}
+}
+
+func (env *testEnv) addReminder(t *testing.T, reminder *models.Reminder, occurrence time.Time) *models.Reminder {
+	reminder.Schedule(occurrence)
+	created, err := env.reminders.CreateReminder(env.ctx, reminder)
+	if err != nil {
+		t.Fatalf("CreateReminder failed: %v", err)
+	}
+	return created
+}
+
+func (env *testEnv) reminder(t *testing.T, id int) *models.Reminder {
+	reminder, err := env.reminders.GetReminderByID(env.ctx, id)
+	if err != nil {
+		t.Fatalf("GetReminderByID failed: %v", err)
+	}
+	return reminder
+}
+
+func TestReminderScheduler_DeliversTaskReminderOnce(t *testing.T) {
+	env := setupSchedulerTest(t)
+	logged := notify.NewLogNotifier(log.New(io.Discard, "", 0))
+	scheduler := NewReminderScheduler(env.db, logged, time.Minute)
+
+	now := time.Now()
+	due := now.Add(30 * time.Minute).Truncate(time.Second)
+	task, err := env.tasks.CreateTask(env.ctx, &models.Task{Title: "Send invoice", Status: models.TaskStatusPending, DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	reminder := env.addReminder(t, &models.Reminder{TaskID: &task.ID, OffsetMinutes: 60}, due)
+
+	for i := 0; i < 3; i++ {
+		if err := scheduler.Tick(context.Background(), now); err != nil {
+			t.Fatalf("Tick failed: %v", err)
+		}
+	}
+
+	sent := logged.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected exactly 1 notification, got %d", len(sent))
+	}
+	if sent[0].Kind != notify.KindTask || sent[0].ItemID != task.ID || sent[0].Recipient != "alice@example.com" {
+		t.Errorf("Unexpected notification %+v", sent[0])
+	}
+
+	delivered := env.reminder(t, reminder.ID)
+	if delivered.Status != models.ReminderSent || delivered.SentAt == nil || delivered.Attempts != 1 {
+		t.Errorf("Unexpected delivered state: %s, %d attempts", delivered.Status, delivered.Attempts)
+	}
+}
+
+func TestReminderScheduler_SkipsRemindersThatNoLongerApply(t *testing.T) {
+	env := setupSchedulerTest(t)
+	logged := notify.NewLogNotifier(log.New(io.Discard, "", 0))
+	scheduler := NewReminderScheduler(env.db, logged, time.Minute)
+	now := time.Now()
+
+	due := now.Add(10 * time.Minute).Truncate(time.Second)
+	task, err := env.tasks.CreateTask(env.ctx, &models.Task{Title: "Done already", Status: models.TaskStatusPending, DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	completed := env.addReminder(t, &models.Reminder{TaskID: &task.ID, OffsetMinutes: 30}, due)
+
+	// Complete the task behind the repository's back, as a concurrent
+	// request racing the scheduler would
+	if _, err := env.db.Exec(`UPDATE tasks SET status = 'completed' WHERE id = ?`, task.ID); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+
+	missingID := 999
+	orphan := env.addReminder(t, &models.Reminder{EventID: &missingID, OffsetMinutes: 30}, due)
+
+	if err := scheduler.Tick(context.Background(), now); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+
+	if len(logged.Sent()) != 0 {
+		t.Errorf("Expected no notifications, got %d", len(logged.Sent()))
+	}
+	for _, id := range []int{completed.ID, orphan.ID} {
+		if status := env.reminder(t, id).Status; status != models.ReminderCancelled {
+			t.Errorf("Reminder %d: expected cancelled, got %s", id, status)
+		}
+	}
+}
+
+func TestReminderScheduler_RetriesFailedDeliveries(t *testing.T) {
+	env := setupSchedulerTest(t)
+	notifier := &flakyNotifier{failures: 1}
+	scheduler := NewReminderScheduler(env.db, notifier, time.Minute)
+	now := time.Now()
+
+	start := now.Add(10 * time.Minute).Truncate(time.Second)
+	event, err := env.events.CreateEvent(env.ctx, &models.Event{Title: "Call", StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	reminder := env.addReminder(t, &models.Reminder{EventID: &event.ID, OffsetMinutes: 15}, start)
+
+	if err := scheduler.Tick(context.Background(), now); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	failed := env.reminder(t, reminder.ID)
+	if failed.Status != models.ReminderPending || failed.LastError == "" || !failed.RemindAt.After(now) {
+		t.Fatalf("Expected a scheduled retry, got %s at %v (%q)", failed.Status, failed.RemindAt, failed.LastError)
+	}
+
+	// Not retried before the delay has passed
+	if err := scheduler.Tick(context.Background(), now); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.Sent()) != 0 {
+		t.Fatalf("Expected no delivery before the retry delay")
+	}
+
+	if err := scheduler.Tick(context.Background(), failed.RemindAt); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(notifier.Sent()) != 1 {
+		t.Fatalf("Expected the retry to deliver, got %d notifications", len(notifier.Sent()))
+	}
+	delivered := env.reminder(t, reminder.ID)
+	if delivered.Status != models.ReminderSent || delivered.Attempts != 2 || delivered.LastError != "" {
+		t.Errorf("Unexpected delivered state: %s, %d attempts (%q)", delivered.Status, delivered.Attempts, delivered.LastError)
+	}
+}
+
+func TestReminderScheduler_TimesDeliveriesWhenMade(t *testing.T) {
+	env := setupSchedulerTest(t)
+	notifier := &flakyNotifier{failures: 1, delay: 100 * time.Millisecond}
+	scheduler := NewReminderScheduler(env.db, notifier, time.Minute)
+	now := time.Now()
+
+	due := now.Add(30 * time.Minute).Truncate(time.Second)
+	task, err := env.tasks.CreateTask(env.ctx, &models.Task{Title: "Send invoice", Status: models.TaskStatusPending, DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	reminder := env.addReminder(t, &models.Reminder{TaskID: &task.ID, OffsetMinutes: 60}, due)
+
+	// The retry delay counts from the failed delivery, not from the tick
+	if err := scheduler.Tick(context.Background(), now); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	failed := env.reminder(t, reminder.ID)
+	if retryAt := now.Add(reminderRetryDelay + notifier.delay); failed.RemindAt.Before(retryAt) {
+		t.Errorf("Expected a retry at %v or later, got %v", retryAt, failed.RemindAt)
+	}
+
+	if err := scheduler.Tick(context.Background(), failed.RemindAt); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	delivered := env.reminder(t, reminder.ID)
+	if delivered.SentAt == nil || delivered.SentAt.Before(failed.RemindAt.Add(notifier.delay)) {
+		t.Errorf("Expected the reminder to be sent after the delivery, got %v", delivered.SentAt)
+	}
+}
+
+func TestReminderScheduler_GivesUpAfterMaxAttempts(t *testing.T) {
+	env := setupSchedulerTest(t)
+	notifier := &flakyNotifier{failures: maxReminderAttempts}
+	scheduler := NewReminderScheduler(env.db, notifier, time.Minute)
+	now := time.Now()
+
+	due := now.Add(time.Hour).Truncate(time.Second)
+	task, err := env.tasks.CreateTask(env.ctx, &models.Task{Title: "Renew passport", Status: models.TaskStatusPending, DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	reminder := env.addReminder(t, &models.Reminder{TaskID: &task.ID, OffsetMinutes: 120}, due)
+
+	at := now
+	for i := 0; i < maxReminderAttempts; i++ {
+		if err := scheduler.Tick(context.Background(), at); err != nil {
+			t.Fatalf("Tick failed: %v", err)
+		}
+		at = env.reminder(t, reminder.ID).RemindAt
+	}
+
+	failed := env.reminder(t, reminder.ID)
+	if failed.Status != models.ReminderFailed || failed.Attempts != maxReminderAttempts {
+		t.Errorf("Expected failed reminder after %d attempts, got %s after %d", maxReminderAttempts, failed.Status, failed.Attempts)
+	}
+}
+
+func TestReminderScheduler_RecurringEventRollsForward(t *testing.T) {
+	env := setupSchedulerTest(t)
+	logged := notify.NewLogNotifier(log.New(io.Discard, "", 0))
+	scheduler := NewReminderScheduler(env.db, logged, time.Minute)
+
+	now := time.Now().Truncate(time.Second)
+	start := now.Add(10 * time.Minute)
+	event, err := env.events.CreateEvent(env.ctx, &models.Event{Title: "Standup", StartTime: start,
+		EndTime: start.Add(15 * time.Minute), RecurrenceRule: "FREQ=DAILY;COUNT=2"})
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	reminder := env.addReminder(t, &models.Reminder{EventID: &event.ID, OffsetMinutes: 15}, start)
+
+	if err := scheduler.Tick(context.Background(), now); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	rolled := env.reminder(t, reminder.ID)
+	if rolled.Status != models.ReminderPending || !rolled.OccurrenceAt.Equal(start.AddDate(0, 0, 1)) {
+		t.Fatalf("Expected reminder for the next occurrence, got %s for %v", rolled.Status, rolled.OccurrenceAt)
+	}
+
+	if err := scheduler.Tick(context.Background(), rolled.RemindAt); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(logged.Sent()) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(logged.Sent()))
+	}
+	if status := env.reminder(t, reminder.ID).Status; status != models.ReminderSent {
+		t.Errorf("Expected the series to end with a sent reminder, got %s", status)
+	}
+}
+
+func TestReminderScheduler_MissedEventOccurrence(t *testing.T) {
+	env := setupSchedulerTest(t)
+	logged := notify.NewLogNotifier(log.New(io.Discard, "", 0))
+	scheduler := NewReminderScheduler(env.db, logged, time.Minute)
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	event, err := env.events.CreateEvent(env.ctx, &models.Event{Title: "Weekly sync", StartTime: start,
+		EndTime: start.Add(time.Hour), RecurrenceRule: "FREQ=WEEKLY"})
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	reminder := env.addReminder(t, &models.Reminder{EventID: &event.ID, OffsetMinutes: 10}, start)
+
+	// The scheduler was down while the occurrence started; it moves on to
+	// the next one instead of reminding late
+	if err := scheduler.Tick(context.Background(), time.Now()); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(logged.Sent()) != 0 {
+		t.Errorf("Expected no notification for a started occurrence, got %d", len(logged.Sent()))
+	}
+	moved := env.reminder(t, reminder.ID)
+	if !moved.OccurrenceAt.Equal(start.AddDate(0, 0, 7)) || moved.Attempts != 0 {
+		t.Errorf("Expected reminder for the next week, got %v", moved.OccurrenceAt)
+	}
+}
+
+func TestReminderScheduler_StartAndStop(t *testing.T) {
+	env := setupSchedulerTest(t)
+	logged := notify.NewLogNotifier(log.New(io.Discard, "", 0))
+	scheduler := NewReminderScheduler(env.db, logged, 10*time.Millisecond)
+
+	due := time.Now().Add(time.Minute).Truncate(time.Second)
+	task, err := env.tasks.CreateTask(env.ctx, &models.Task{Title: "Water plants", Status: models.TaskStatusPending, DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	// A reminder left mid-delivery by a previous run
+	interrupted := env.addReminder(t, &models.Reminder{TaskID: &task.ID, OffsetMinutes: 5}, due)
+	if _, err := env.db.Exec(`UPDATE reminders SET status = 'sending' WHERE id = ?`, interrupted.ID); err != nil {
+		t.Fatalf("Failed to mark reminder as sending: %v", err)
+	}
+	pending := env.addReminder(t, &models.Reminder{TaskID: &task.ID, OffsetMinutes: 10}, due)
+
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(logged.Sent()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	scheduler.Stop()
+
+	if len(logged.Sent()) != 2 {
+		t.Fatalf("Expected the interrupted and pending reminders to be delivered, got %+v", logged.Sent())
+	}
+	for _, reminder := range []*models.Reminder{interrupted, pending} {
+		if status := env.reminder(t, reminder.ID).Status; status != models.ReminderSent {
+			t.Errorf("Expected reminder %d to be sent, got %s", reminder.ID, status)
+		}
+	}
+
+	// Stopping twice is harmless
+	scheduler.Stop()
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"agenda/internal/database"
+)
+
+// Trash purge settings
+const (
+	// DefaultTrashRetentionDays is how long deleted tasks and events stay
+	// in the trash before they are purged
+	DefaultTrashRetentionDays = 30
+	// trashPurgeInterval is how often expired items are looked for
+	trashPurgeInterval = time.Hour
+)
+
+// TrashRetentionFromEnv reads the number of days deleted items are kept
+// from the TRASH_RETENTION_DAYS environment variable, falling back to
+// DefaultTrashRetentionDays for unset or invalid values
+func TrashRetentionFromEnv() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = DefaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// TrashPurger permanently removes the tasks and events of every user that
+// have been in the trash for longer than the retention period
+type TrashPurger struct {
+	taskRepo  database.TaskRepositoryInterface
+	eventRepo database.EventRepositoryInterface
+	retention time.Duration
+	interval  time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTrashPurger creates a purger removing the items deleted from db more
+// than retention ago
+func NewTrashPurger(db *sql.DB, retention time.Duration) *TrashPurger {
+	if retention <= 0 {
+		retention = DefaultTrashRetentionDays * 24 * time.Hour
+	}
+
+	return &TrashPurger{
+		taskRepo:  database.NewTaskRepository(db),
+		eventRepo: database.NewEventRepository(db),
+		retention: retention,
+		interval:  trashPurgeInterval,
+	}
+}
+
+// Start starts purging the trash in a background goroutine until Stop is
+// called or ctx is cancelled
+func (p *TrashPurger) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go p.run(ctx)
+}
+
+// Stop stops the purger and waits for the purge in progress, if any, to
+// finish
+func (p *TrashPurger) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+}
+
+// run purges the trash every interval until ctx is cancelled
+func (p *TrashPurger) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Tick(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("trash purger: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick purges the tasks and events deleted more than the retention period
+// before now
+func (p *TrashPurger) Tick(ctx context.Context, now time.Time) error {
+	deletedBefore := now.Add(-p.retention)
+
+	tasks, err := p.taskRepo.PurgeDeletedTasks(ctx, deletedBefore)
+	if err != nil {
+		return err
+	}
+	events, err := p.eventRepo.PurgeDeletedEvents(ctx, deletedBefore)
+	if err != nil {
+		return err
+	}
+
+	if tasks > 0 || events > 0 {
+		log.Printf("trash purger: purged %d tasks and %d events", tasks, events)
+	}
+	return nil
+}
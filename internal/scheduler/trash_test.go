@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"agenda/internal/models"
+)
+
+func TestTrashPurger(t *testing.T) {
+	env := setupSchedulerTest(t)
+	purger := NewTrashPurger(env.db, 7*24*time.Hour)
+
+	task, err := env.tasks.CreateTask(env.ctx, &models.Task{Title: "Old task"})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	event, err := env.events.CreateEvent(env.ctx, &models.Event{Title: "Old event", StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	reminder := env.addReminder(t, &models.Reminder{EventID: &event.ID, OffsetMinutes: 10}, start)
+
+	if err := env.tasks.DeleteTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	if err := env.events.DeleteEvent(env.ctx, event.ID); err != nil {
+		t.Fatalf("DeleteEvent failed: %v", err)
+	}
+
+	// Items deleted within the retention period are kept
+	if err := purger.Tick(env.ctx, time.Now().Add(6*24*time.Hour)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if tasks, err := env.tasks.ListDeletedTasks(env.ctx); err != nil || len(tasks) != 1 {
+		t.Fatalf("Expected the task to stay in the trash, got %d (%v)", len(tasks), err)
+	}
+
+	if err := purger.Tick(env.ctx, time.Now().Add(8*24*time.Hour)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if tasks, err := env.tasks.ListDeletedTasks(env.ctx); err != nil || len(tasks) != 0 {
+		t.Errorf("Expected the task to be purged, got %d (%v)", len(tasks), err)
+	}
+	if events, err := env.events.ListDeletedEvents(env.ctx); err != nil || len(events) != 0 {
+		t.Errorf("Expected the event to be purged, got %d (%v)", len(events), err)
+	}
+	if _, err := env.reminders.GetReminderByID(env.ctx, reminder.ID); err == nil {
+		t.Errorf("Expected the reminder of the event to be purged")
+	}
+}
+
+func TestTrashRetentionFromEnv(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    DefaultTrashRetentionDays * 24 * time.Hour,
+		"7":   7 * 24 * time.Hour,
+		"0":   DefaultTrashRetentionDays * 24 * time.Hour,
+		"abc": DefaultTrashRetentionDays * 24 * time.Hour,
+	}
+	for value, want := range tests {
+		t.Setenv("TRASH_RETENTION_DAYS", value)
+		if got := TrashRetentionFromEnv(); got != want {
+			t.Errorf("TRASH_RETENTION_DAYS=%q: got %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/webhook"
+)
+
+// Webhook dispatch settings
+const (
+	// DefaultWebhookInterval is how often due deliveries are looked for
+	DefaultWebhookInterval = 10 * time.Second
+	// webhookBatchSize bounds the number of deliveries loaded at once
+	webhookBatchSize = 50
+	// webhookTimeout bounds how long a receiver has to respond
+	webhookTimeout = 10 * time.Second
+	// maxWebhookAttempts is the number of attempts made before a delivery
+	// is marked as failed
+	maxWebhookAttempts = 8
+	// webhookRetryDelay is the delay before the first retry; it doubles
+	// with every further attempt up to maxWebhookRetryDelay
+	webhookRetryDelay    = 30 * time.Second
+	maxWebhookRetryDelay = time.Hour
+)
+
+// WebhookIntervalFromEnv reads the dispatcher interval from the
+// WEBHOOK_INTERVAL environment variable, falling back to
+// DefaultWebhookInterval for unset or invalid values
+func WebhookIntervalFromEnv() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("WEBHOOK_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultWebhookInterval
+}
+
+// WebhookDispatcher sends the deliveries queued in the webhook outbox in
+// the background. Every attempt is recorded in the delivery log, and failed
+// deliveries are retried with exponential backoff. Deliveries interrupted
+// by a shutdown are sent again, so receivers may see a delivery twice.
+type WebhookDispatcher struct {
+	webhookRepo database.WebhookRepositoryInterface
+	sender      *webhook.Sender
+	interval    time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWebhookDispatcher creates a dispatcher sending the deliveries stored
+// in db, checking for due deliveries every interval. Unless
+// allowPrivateNetworks is set, deliveries are only sent to public addresses.
+func NewWebhookDispatcher(db *sql.DB, interval time.Duration, allowPrivateNetworks bool) *WebhookDispatcher {
+	if interval <= 0 {
+		interval = DefaultWebhookInterval
+	}
+
+	return &WebhookDispatcher{
+		webhookRepo: database.NewWebhookRepository(db),
+		sender:      webhook.NewSender(webhookTimeout, allowPrivateNetworks),
+		interval:    interval,
+	}
+}
+
+// Start requeues the deliveries interrupted by a previous shutdown and
+// starts sending deliveries in a background goroutine until Stop is called
+// or ctx is cancelled
+func (d *WebhookDispatcher) Start(ctx context.Context) error {
+	interrupted, err := d.webhookRepo.ResetInterruptedDeliveries(ctx)
+	if err != nil {
+		return err
+	}
+	if interrupted > 0 {
+		log.Printf("webhook dispatcher: requeued %d interrupted deliveries", interrupted)
+	}
+
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = make(chan struct{})
+	go d.run(ctx)
+
+	return nil
+}
+
+// Stop stops the dispatcher and waits for the delivery in progress, if
+// any, to finish
+func (d *WebhookDispatcher) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	<-d.done
+}
+
+// run sends due deliveries every interval until ctx is cancelled
+func (d *WebhookDispatcher) run(ctx context.Context) {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Tick(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("webhook dispatcher: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick sends the deliveries due at now, up to a batch per call. It stops
+// early when ctx is cancelled, but always finishes the delivery in progress.
+// Deliveries are sent one after the other, so the clock of the tick starts
+// at now and advances with the time they take: each delivery is signed and
+// timed when it is actually sent.
+func (d *WebhookDispatcher) Tick(ctx context.Context, now time.Time) error {
+	started := time.Now()
+	clock := func() time.Time { return now.Add(time.Since(started)) }
+
+	due, err := d.webhookRepo.GetDueDeliveries(ctx, now, webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// Deliveries run to completion so that their outcome is recorded
+	deliveryCtx := context.WithoutCancel(ctx)
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := d.process(deliveryCtx, delivery, clock); err != nil {
+			log.Printf("webhook dispatcher: delivery %d: %v", delivery.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// process sends a due delivery and records the outcome, reading the time
+// from clock
+func (d *WebhookDispatcher) process(ctx context.Context, delivery *models.WebhookDelivery, clock func() time.Time) error {
+	// Load the webhook as its owner
+	ownerCtx := ctx
+	if delivery.UserID != nil {
+		ownerCtx = auth.WithUserID(ctx, *delivery.UserID)
+	}
+
+	hook, err := d.webhookRepo.GetWebhookByID(ownerCtx, delivery.WebhookID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if hook == nil || !hook.Active {
+		delivery.Status = models.DeliveryFailed
+		delivery.LastError = "webhook was disabled or deleted"
+		return d.webhookRepo.SaveDeliveryState(ctx, delivery)
+	}
+
+	claimed, err := d.webhookRepo.ClaimDelivery(ctx, delivery)
+	if err != nil || !claimed {
+		return err
+	}
+
+	sentAt := clock()
+	result := d.sender.Send(ctx, webhook.Request{
+		URL:    hook.URL,
+		Secret: hook.Secret,
+		ID:     delivery.UID,
+		Event:  delivery.EventType,
+		Body:   []byte(delivery.Payload),
+	}, sentAt)
+
+	attempt := &models.WebhookDeliveryAttempt{
+		DeliveryID:   delivery.ID,
+		Attempt:      delivery.Attempts,
+		StatusCode:   result.StatusCode,
+		ResponseBody: result.Body,
+		DurationMS:   int(result.Duration.Milliseconds()),
+		AttemptedAt:  sentAt,
+	}
+	if result.Err != nil {
+		attempt.Error = result.Err.Error()
+	}
+	if err := d.webhookRepo.RecordDeliveryAttempt(ctx, attempt); err != nil {
+		log.Printf("webhook dispatcher: delivery %d: %v", delivery.ID, err)
+	}
+
+	delivery.LastStatusCode = result.StatusCode
+	delivery.LastError = attempt.Error
+	switch {
+	case result.Err == nil:
+		deliveredAt := clock()
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &deliveredAt
+	case delivery.Attempts >= maxWebhookAttempts:
+		delivery.Status = models.DeliveryFailed
+	default:
+		delivery.Status = models.DeliveryPending
+		delivery.NextAttemptAt = clock().Add(webhookBackoff(delivery.Attempts))
+	}
+
+	return d.webhookRepo.SaveDeliveryState(ctx, delivery)
+}
+
+// webhookBackoff returns the delay before retrying a delivery that failed
+// its given attempt
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryDelay
+	for i := 1; i < attempts && delay < maxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxWebhookRetryDelay {
+		delay = maxWebhookRetryDelay
+	}
+	return delay
+}
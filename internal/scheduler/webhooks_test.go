@@ -0,0 +1,294 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/services"
+	"agenda/internal/webhook"
+)
+
+// webhookReceiver records verified deliveries, failing the first failures
+// of them
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	failures int
+	received []webhook.Payload
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	if err := webhook.Verify(wr.secret, r.Header.Get(webhook.HeaderTimestamp), r.Header.Get(webhook.HeaderSignature),
+		body, time.Now(), time.Hour); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if wr.failures > 0 {
+		wr.failures--
+		http.Error(w, "try again later", http.StatusServiceUnavailable)
+		return
+	}
+
+	var payload webhook.Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	wr.received = append(wr.received, payload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (wr *webhookReceiver) Received() []webhook.Payload {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return append([]webhook.Payload(nil), wr.received...)
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	env := setupSchedulerTest(t)
+	receiver := &webhookReceiver{secret: "whsec_0123456789abcdef", failures: 1}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhookService := services.NewWebhookService(database.NewWebhookRepository(env.db), true)
+	hook, err := webhookService.CreateWebhook(env.ctx, services.CreateWebhookRequest{
+		URL:        server.URL,
+		EventTypes: []string{services.ChangeTaskCreated, services.ChangeTaskCompleted},
+		Secret:     receiver.secret,
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+
+	taskService := services.NewTaskService(env.tasks, database.NewTransactionManager(env.db), webhookService)
+	task, err := taskService.CreateTask(env.ctx, services.CreateTaskRequest{Title: "Water plants"})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	dispatcher := NewWebhookDispatcher(env.db, time.Minute, true)
+	now := time.Now()
+
+	deliveries, err := webhookService.ListDeliveries(env.ctx, hook.ID, 0)
+	if err != nil {
+		t.Fatalf("ListDeliveries failed: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].EventType != services.ChangeTaskCreated {
+		t.Fatalf("Expected one task.created delivery, got %+v", deliveries)
+	}
+	deliveryID := deliveries[0].ID
+
+	t.Run("failed attempts are retried with a delay", func(t *testing.T) {
+		if err := dispatcher.Tick(env.ctx, now); err != nil {
+			t.Fatalf("Tick failed: %v", err)
+		}
+
+		delivery, err := webhookService.GetDelivery(env.ctx, hook.ID, deliveryID)
+		if err != nil {
+			t.Fatalf("GetDelivery failed: %v", err)
+		}
+		if delivery.Status != models.DeliveryPending || delivery.Attempts != 1 ||
+			delivery.LastStatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Expected a pending retry, got %s after %d attempts (%d)", delivery.Status, delivery.Attempts,
+				delivery.LastStatusCode)
+		}
+		// The delay starts once the attempt failed, a moment after the tick
+		if retryAt := now.Add(webhookRetryDelay); delivery.NextAttemptAt.Before(retryAt) ||
+			delivery.NextAttemptAt.After(retryAt.Add(time.Second)) {
+			t.Errorf("Expected next attempt at %v, got %v", retryAt, delivery.NextAttemptAt)
+		}
+		if len(delivery.AttemptLog) != 1 || delivery.AttemptLog[0].ResponseBody == "" {
+			t.Errorf("Expected the attempt to be logged, got %+v", delivery.AttemptLog)
+		}
+
+		// Not retried before the delay has passed
+		if err := dispatcher.Tick(env.ctx, now.Add(time.Second)); err != nil {
+			t.Fatalf("Tick failed: %v", err)
+		}
+		if len(receiver.Received()) != 0 {
+			t.Errorf("Expected no delivery before the retry delay")
+		}
+	})
+
+	t.Run("successful deliveries carry the change", func(t *testing.T) {
+		if err := dispatcher.Tick(env.ctx, now.Add(webhookRetryDelay+time.Second)); err != nil {
+			t.Fatalf("Tick failed: %v", err)
+		}
+
+		received := receiver.Received()
+		if len(received) != 1 {
+			t.Fatalf("Expected 1 delivery, got %d", len(received))
+		}
+		if received[0].Type != services.ChangeTaskCreated {
+			t.Errorf("Unexpected payload type %q", received[0].Type)
+		}
+		data, _ := received[0].Data.(map[string]interface{})
+		if data["title"] != "Water plants" || int(data["id"].(float64)) != task.ID {
+			t.Errorf("Unexpected payload data %v", received[0].Data)
+		}
+
+		delivery, err := webhookService.GetDelivery(env.ctx, hook.ID, deliveryID)
+		if err != nil {
+			t.Fatalf("GetDelivery failed: %v", err)
+		}
+		if delivery.Status != models.DeliverySucceeded || delivery.DeliveredAt == nil || len(delivery.AttemptLog) != 2 {
+			t.Errorf("Expected a succeeded delivery after 2 attempts, got %s with %d attempts", delivery.Status,
+				len(delivery.AttemptLog))
+		}
+		if received[0].ID != delivery.UID {
+			t.Errorf("Expected payload ID %q, got %q", delivery.UID, received[0].ID)
+		}
+	})
+
+	t.Run("unsubscribed changes are not delivered", func(t *testing.T) {
+		if _, err := taskService.UpdateTask(env.ctx, task.ID, services.UpdateTaskRequest{Title: stringPtr("Water the plants")}); err != nil {
+			t.Fatalf("UpdateTask failed: %v", err)
+		}
+		deliveries, err := webhookService.ListDeliveries(env.ctx, hook.ID, 0)
+		if err != nil {
+			t.Fatalf("ListDeliveries failed: %v", err)
+		}
+		if len(deliveries) != 1 {
+			t.Errorf("Expected no task.updated delivery, got %d deliveries", len(deliveries))
+		}
+	})
+
+	t.Run("deliveries give up after the last attempt", func(t *testing.T) {
+		receiver.mu.Lock()
+		receiver.failures = maxWebhookAttempts
+		receiver.mu.Unlock()
+
+		if _, err := taskService.CompleteTask(env.ctx, task.ID); err != nil {
+			t.Fatalf("CompleteTask failed: %v", err)
+		}
+
+		at := now
+		for i := 0; i < maxWebhookAttempts; i++ {
+			at = at.Add(maxWebhookRetryDelay)
+			if err := dispatcher.Tick(env.ctx, at); err != nil {
+				t.Fatalf("Tick failed: %v", err)
+			}
+		}
+
+		deliveries, err := webhookService.ListDeliveries(env.ctx, hook.ID, 0)
+		if err != nil {
+			t.Fatalf("ListDeliveries failed: %v", err)
+		}
+		if deliveries[0].EventType != services.ChangeTaskCompleted || deliveries[0].Status != models.DeliveryFailed ||
+			deliveries[0].Attempts != maxWebhookAttempts {
+			t.Errorf("Expected a failed task.completed delivery, got %s %s after %d attempts",
+				deliveries[0].EventType, deliveries[0].Status, deliveries[0].Attempts)
+		}
+	})
+
+	t.Run("deliveries of disabled webhooks are dropped", func(t *testing.T) {
+		if _, err := taskService.CreateTask(env.ctx, services.CreateTaskRequest{Title: "Feed the cat"}); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		inactive := false
+		if _, err := webhookService.UpdateWebhook(env.ctx, hook.ID, services.UpdateWebhookRequest{Active: &inactive}); err != nil {
+			t.Fatalf("UpdateWebhook failed: %v", err)
+		}
+
+		if err := dispatcher.Tick(env.ctx, time.Now()); err != nil {
+			t.Fatalf("Tick failed: %v", err)
+		}
+
+		deliveries, err := webhookService.ListDeliveries(env.ctx, hook.ID, 1)
+		if err != nil {
+			t.Fatalf("ListDeliveries failed: %v", err)
+		}
+		if deliveries[0].Status != models.DeliveryFailed || deliveries[0].Attempts != 0 {
+			t.Errorf("Expected the delivery to be dropped, got %s after %d attempts", deliveries[0].Status,
+				deliveries[0].Attempts)
+		}
+	})
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{50, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestWebhookDispatcher_TimesEachDelivery(t *testing.T) {
+	env := setupSchedulerTest(t)
+	const delay = 100 * time.Millisecond
+	var mu sync.Mutex
+	var timestamps []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		timestamps = append(timestamps, r.Header.Get(webhook.HeaderTimestamp))
+		mu.Unlock()
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhookService := services.NewWebhookService(database.NewWebhookRepository(env.db), true)
+	hook, err := webhookService.CreateWebhook(env.ctx, services.CreateWebhookRequest{
+		URL:        server.URL,
+		EventTypes: []string{services.ChangeTaskCreated},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+	taskService := services.NewTaskService(env.tasks, database.NewTransactionManager(env.db), webhookService)
+	for _, title := range []string{"Water plants", "Feed the cat"} {
+		if _, err := taskService.CreateTask(env.ctx, services.CreateTaskRequest{Title: title}); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+	}
+
+	dispatcher := NewWebhookDispatcher(env.db, time.Minute, true)
+	if err := dispatcher.Tick(env.ctx, time.Now()); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+
+	deliveries, err := webhookService.ListDeliveries(env.ctx, hook.ID, 0)
+	if err != nil || len(deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d (%v)", len(deliveries), err)
+	}
+	var attemptedAt []time.Time
+	for _, listed := range deliveries {
+		delivery, err := webhookService.GetDelivery(env.ctx, hook.ID, listed.ID)
+		if err != nil || len(delivery.AttemptLog) != 1 {
+			t.Fatalf("Expected an attempt, got %+v (%v)", delivery, err)
+		}
+		attemptedAt = append(attemptedAt, delivery.AttemptLog[0].AttemptedAt)
+	}
+
+	// Deliveries are listed newest first
+	if gap := attemptedAt[0].Sub(attemptedAt[1]); gap < delay {
+		t.Errorf("Expected the second delivery to be sent after the first, %v apart", gap)
+	}
+}
@@ -2,77 +2,204 @@ package server
 
 import (
 	"database/sql"
+	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"agenda/internal/database"
 	"agenda/internal/handlers"
 	"agenda/internal/middleware"
 	"agenda/internal/services"
+	"agenda/internal/stream"
+	"agenda/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewServer(db *sql.DB) *http.Server {
+// NewServer creates the API server. Task and event changes are published to
+// the streams of streamHub, and the admin routes back up the database with
+// backups, nil for databases without online backups.
+func NewServer(db *sql.DB, streamHub *stream.Hub, backups *database.BackupManager) *http.Server {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	// Rate limit policies, overridable with RATE_LIMIT*_REQUESTS and
+	// RATE_LIMIT*_WINDOW environment variables
+	globalRateLimit := middleware.RateLimitPolicyFromEnv("RATE_LIMIT",
+		middleware.RateLimitPolicy{Limit: 1000, Window: time.Hour})
+	authRateLimit := middleware.RateLimitPolicyFromEnv("RATE_LIMIT_AUTH",
+		middleware.RateLimitPolicy{Limit: 20, Window: time.Minute})
+	writeRateLimit := middleware.RateLimitPolicyFromEnv("RATE_LIMIT_WRITE",
+		middleware.RateLimitPolicy{Limit: 120, Window: time.Minute, Methods: middleware.WriteMethods})
+
 	// Create router without default middleware to have full control
 	router := gin.New()
 
+	// Only honour X-Forwarded-For from known proxies so clients cannot pick
+	// the IP address they are rate limited by
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Printf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Add middleware in order of execution
 	router.Use(middleware.RequestLogger()) // Log requests first
 	router.Use(middleware.ErrorHandler())  // Handle panics and errors
 	router.Use(middleware.Security())      // Add security headers
 	router.Use(middleware.CORS())          // Handle CORS for frontend integration
-	router.Use(middleware.RateLimitInfo()) // Add rate limiting info headers
+	router.Use(middleware.RateLimit(globalRateLimit)) // Limit requests per client IP
 
 	// Initialize repositories
+	userRepo := database.NewUserRepository(db)
 	taskRepo := database.NewTaskRepository(db)
 	eventRepo := database.NewEventRepository(db)
+	reminderRepo := database.NewReminderRepository(db)
+	webhookRepo := database.NewWebhookRepository(db)
+	searchRepo := database.NewSearchRepository(db)
+	auditRepo := database.NewAuditRepository(db)
+	undoRepo := database.NewUndoRepository(db)
+	attendeeRepo := database.NewAttendeeRepository(db)
+	calendarRepo := database.NewCalendarRepository(db)
+	txManager := database.NewTransactionManager(db)
 
 	// Initialize services
-	taskService := services.NewTaskService(taskRepo)
-	eventService := services.NewEventService(eventRepo)
+	authService := services.NewAuthService(userRepo)
+	webhookService := services.NewWebhookService(webhookRepo, webhook.AllowPrivateNetworksFromEnv())
+	auditService := services.NewAuditService(auditRepo, taskRepo, eventRepo)
+	streamPublisher := services.NewStreamPublisher(streamHub)
+	undoService := services.NewUndoService(undoRepo, taskRepo, eventRepo, txManager, services.UndoWindowFromEnv(),
+		auditService, webhookService, streamPublisher)
+	taskService := services.NewTaskService(taskRepo, txManager, auditService, webhookService, streamPublisher, undoService)
+	eventService := services.NewEventService(eventRepo, calendarRepo, txManager, auditService, webhookService,
+		streamPublisher, undoService)
 	dashboardService := services.NewDashboardService(taskService, eventService)
+	icalService := services.NewICalService(taskRepo, eventRepo, calendarRepo, txManager, auditService, webhookService, streamPublisher)
+	reminderService := services.NewReminderService(reminderRepo, taskRepo, eventRepo, txManager, auditService,
+		webhookService, streamPublisher)
+	searchService := services.NewSearchService(searchRepo, taskRepo, eventRepo)
+	trashService := services.NewTrashService(taskRepo, eventRepo)
+	attendeeService := services.NewAttendeeService(attendeeRepo, eventRepo, userRepo, txManager, auditService,
+		webhookService, streamPublisher)
+	calendarService := services.NewCalendarService(calendarRepo)
+	scheduleService := services.NewScheduleService(eventService, calendarRepo)
 
 	// Initialize handlers
+	authHandler := handlers.NewAuthHandler(authService)
 	taskHandler := handlers.NewTaskHandler(taskService)
 	eventHandler := handlers.NewEventHandler(eventService)
 	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
+	icalHandler := handlers.NewICalHandler(icalService)
+	reminderHandler := handlers.NewReminderHandler(reminderService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	undoHandler := handlers.NewUndoHandler(undoService)
+	attendeeHandler := handlers.NewAttendeeHandler(attendeeService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService)
+	streamHandler := handlers.NewStreamHandler(streamHub)
+	backupHandler := handlers.NewBackupHandler(backups)
+
+	// Every API route except registration and login requires a session
+	requireAuth := middleware.Auth(authService)
+
+	// Stricter limits for credential checks (per IP) and writes (per user)
+	authLimit := middleware.RateLimit(authRateLimit)
+	writeLimit := middleware.RateLimit(writeRateLimit)
+	protected := []gin.HandlerFunc{requireAuth, writeLimit}
 
 	// API routes with additional middleware
 	api := router.Group("/api")
 	api.Use(middleware.APIVersioning())         // Add API versioning
-	api.Use(middleware.ContentTypeValidation()) // Validate content-type for POST/PUT
+	api.Use(middleware.ContentTypeValidation()) // Validate content-type for POST/PUT/PATCH
+	api.Use(middleware.TimeZone())              // Resolve the client time zone
 	{
+		// Auth routes
+		authRoutes := api.Group("/auth")
+		{
+			authRoutes.POST("/register", authLimit, authHandler.Register)
+			authRoutes.POST("/login", authLimit, authHandler.Login)
+			authRoutes.POST("/logout", requireAuth, authHandler.Logout)
+			authRoutes.GET("/me", requireAuth, authHandler.Me)
+		}
+
 		// Task routes
-		tasks := api.Group("/tasks")
+		tasks := api.Group("/tasks", protected...)
 		{
 			tasks.GET("", taskHandler.ListTasks)
 			tasks.POST("", taskHandler.CreateTask)
+			tasks.POST("/bulk", taskHandler.BulkTasks)
 			tasks.GET("/:id", taskHandler.GetTask)
 			tasks.PUT("/:id", taskHandler.UpdateTask)
+			tasks.PATCH("/:id", taskHandler.PatchTask)
 			tasks.DELETE("/:id", taskHandler.DeleteTask)
 			tasks.POST("/:id/complete", taskHandler.CompleteTask)
 			tasks.POST("/:id/reopen", taskHandler.ReopenTask)
+			tasks.POST("/:id/restore", taskHandler.RestoreTask)
+			tasks.GET("/:id/subtasks", taskHandler.GetSubtasks)
+			tasks.GET("/:id/dependencies", taskHandler.GetDependencies)
+			tasks.GET("/:id/completions", taskHandler.GetCompletedInstances)
+			tasks.GET("/:id/history", auditHandler.GetTaskHistory)
+			tasks.GET("/:id/reminders", reminderHandler.GetTaskReminders)
+			tasks.POST("/:id/reminders", reminderHandler.CreateTaskReminder)
 		}
 
 		// Event routes
-		events := api.Group("/events")
+		events := api.Group("/events", protected...)
 		{
 			events.GET("", eventHandler.ListEvents)
 			events.POST("", eventHandler.CreateEvent)
+			events.POST("/bulk", eventHandler.BulkEvents)
 			events.GET("/upcoming", eventHandler.GetUpcomingEvents)
+			events.GET("/conflicts", eventHandler.GetConflicts)
 			events.GET("/:id", eventHandler.GetEvent)
 			events.PUT("/:id", eventHandler.UpdateEvent)
+			events.PATCH("/:id", eventHandler.PatchEvent)
 			events.DELETE("/:id", eventHandler.DeleteEvent)
+			events.POST("/:id/restore", eventHandler.RestoreEvent)
+			events.GET("/:id/history", auditHandler.GetEventHistory)
+			events.GET("/:id/reminders", reminderHandler.GetEventReminders)
+			events.POST("/:id/reminders", reminderHandler.CreateEventReminder)
+			events.GET("/:id/attendees", attendeeHandler.GetAttendees)
+			events.POST("/:id/attendees", attendeeHandler.AddAttendee)
+			events.PUT("/:id/attendees/:attendee_id", attendeeHandler.UpdateAttendee)
+			events.DELETE("/:id/attendees/:attendee_id", attendeeHandler.RemoveAttendee)
+		}
+
+		// Calendar routes
+		calendars := api.Group("/calendars", protected...)
+		{
+			calendars.GET("", calendarHandler.ListCalendars)
+			calendars.POST("", calendarHandler.CreateCalendar)
+			calendars.GET("/:id", calendarHandler.GetCalendar)
+			calendars.PUT("/:id", calendarHandler.UpdateCalendar)
+			calendars.DELETE("/:id", calendarHandler.DeleteCalendar)
+		}
+
+		// Reminder routes
+		reminders := api.Group("/reminders", protected...)
+		{
+			reminders.DELETE("/:id", reminderHandler.DeleteReminder)
+		}
+
+		// Webhook routes
+		webhooks := api.Group("/webhooks", protected...)
+		{
+			webhooks.GET("", webhookHandler.ListWebhooks)
+			webhooks.POST("", webhookHandler.CreateWebhook)
+			webhooks.GET("/:id", webhookHandler.GetWebhook)
+			webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+			webhooks.GET("/:id/deliveries/:deliveryId", webhookHandler.GetDelivery)
 		}
 
 		// Dashboard routes
-		dashboard := api.Group("/dashboard")
+		dashboard := api.Group("/dashboard", protected...)
 		{
 			dashboard.GET("", dashboardHandler.GetDashboard)
 			dashboard.GET("/stats", dashboardHandler.GetDashboardStats)
@@ -80,6 +207,61 @@ func NewServer(db *sql.DB) *http.Server {
 			dashboard.GET("/calendar", dashboardHandler.GetCalendarView)
 			dashboard.GET("/daterange", dashboardHandler.GetDateRange)
 		}
+
+		// Free/busy queries and meeting slot suggestions
+		schedule := api.Group("/schedule", protected...)
+		{
+			schedule.POST("/free-busy", scheduleHandler.GetFreeBusy)
+			schedule.POST("/suggest", scheduleHandler.SuggestSlots)
+		}
+
+		// Full-text search across tasks and events
+		search := api.Group("/search", protected...)
+		{
+			search.GET("", searchHandler.Search)
+		}
+
+		// Deleted tasks and events, restored through their own routes
+		trash := api.Group("/trash", protected...)
+		{
+			trash.GET("", trashHandler.ListTrash)
+		}
+
+		// Audit log of the changes made to tasks and events
+		audit := api.Group("/audit", protected...)
+		{
+			audit.GET("", auditHandler.ListAuditEntries)
+		}
+
+		// Undo of the changes made by a task or event request
+		undo := api.Group("/undo", protected...)
+		{
+			undo.POST("/:token", undoHandler.Undo)
+		}
+
+		// Database administration, restricted to the users listed in
+		// ADMIN_EMAILS
+		admin := api.Group("/admin", requireAuth, writeLimit, middleware.Admin(middleware.AdminEmailsFromEnv()))
+		{
+			admin.GET("/backups", backupHandler.ListBackups)
+			admin.POST("/backups", backupHandler.CreateBackup)
+		}
+
+		// Live stream of task and event changes
+		api.GET("/stream", requireAuth, streamHandler.Stream)
+
+		// iCalendar export
+		api.GET("/calendar.ics", requireAuth, writeLimit, icalHandler.ExportCalendar)
+	}
+
+	// iCalendar import accepts .ics bodies and file uploads instead of JSON
+	imports := router.Group("/api/import")
+	imports.Use(middleware.APIVersioning())
+	imports.Use(middleware.ContentTypeValidation("text/calendar", "multipart/form-data"))
+	imports.Use(middleware.TimeZone())
+	imports.Use(protected...)
+	{
+		imports.POST("/ics", icalHandler.ImportCalendar)
 	}
 
 	// Basic health check endpoint
@@ -94,3 +276,20 @@ func NewServer(db *sql.DB) *http.Server {
 
 	return server
 }
+
+// trustedProxies returns the proxies allowed to set the client IP, read from
+// the comma-separated TRUSTED_PROXIES environment variable
+func trustedProxies() []string {
+	value := os.Getenv("TRUSTED_PROXIES")
+	if value == "" {
+		return nil
+	}
+
+	var proxies []string
+	for _, proxy := range strings.Split(value, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
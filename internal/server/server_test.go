@@ -2,6 +2,8 @@ package server
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -9,6 +11,7 @@ import (
 	"testing"
 
 	"agenda/internal/database"
+	"agenda/internal/stream"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -34,11 +37,36 @@ func setupTestDB(t *testing.T) *sql.DB {
 	return db
 }
 
+// login registers a user with the given email and returns a session token
+func login(t *testing.T, handler http.Handler, email string) string {
+	credentials := fmt.Sprintf(`{"email": %q, "password": "correct horse"}`, email)
+
+	req := httptest.NewRequest("POST", "/api/auth/register", strings.NewReader(credentials))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	req = httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(credentials))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	require.NotEmpty(t, result.Token)
+	return result.Token
+}
+
 func TestServerRouting(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 
-	server := NewServer(db)
+	server := NewServer(db, stream.NewHub(0), nil)
+	token := login(t, server.Handler, "routing@example.com")
 
 	tests := []struct {
 		name           string
@@ -48,6 +76,7 @@ func TestServerRouting(t *testing.T) {
 		contentType    string
 		expectedStatus int
 		checkHeaders   map[string]string
+		anonymous      bool
 	}{
 		{
 			name:           "Health check endpoint",
@@ -59,6 +88,38 @@ func TestServerRouting(t *testing.T) {
 				"X-Frame-Options":        "DENY",
 			},
 		},
+		{
+			name:           "List tasks without session",
+			method:         "GET",
+			path:           "/api/tasks",
+			expectedStatus: http.StatusUnauthorized,
+			anonymous:      true,
+			checkHeaders: map[string]string{
+				"WWW-Authenticate": "Bearer",
+			},
+		},
+		{
+			name:           "Change stream without session",
+			method:         "GET",
+			path:           "/api/stream",
+			expectedStatus: http.StatusUnauthorized,
+			anonymous:      true,
+		},
+		{
+			name:           "Current user endpoint",
+			method:         "GET",
+			path:           "/api/auth/me",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Login with wrong password",
+			method:         "POST",
+			path:           "/api/auth/login",
+			body:           `{"email": "routing@example.com", "password": "wrong password"}`,
+			contentType:    "application/json",
+			expectedStatus: http.StatusUnauthorized,
+			anonymous:      true,
+		},
 		{
 			name:           "List tasks endpoint",
 			method:         "GET",
@@ -102,13 +163,39 @@ func TestServerRouting(t *testing.T) {
 			path:           "/api/dashboard/stats",
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "iCalendar export endpoint",
+			method:         "GET",
+			path:           "/api/calendar.ics",
+			expectedStatus: http.StatusOK,
+			checkHeaders: map[string]string{
+				"Content-Type":  "text/calendar",
+				"X-API-Version": "v1",
+			},
+		},
+		{
+			name:           "iCalendar import endpoint",
+			method:         "POST",
+			path:           "/api/import/ics",
+			body:           "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nEND:VCALENDAR\r\n",
+			contentType:    "text/calendar",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "iCalendar import with JSON content-type",
+			method:         "POST",
+			path:           "/api/import/ics",
+			body:           `{"calendar": ""}`,
+			contentType:    "application/json",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
 		{
 			name:           "OPTIONS request for CORS",
 			method:         "OPTIONS",
 			path:           "/api/tasks",
 			expectedStatus: http.StatusNoContent,
 			checkHeaders: map[string]string{
-				"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE, OPTIONS",
+				"Access-Control-Allow-Methods": "GET, POST, PUT, PATCH, DELETE, OPTIONS",
 			},
 		},
 	}
@@ -126,6 +213,10 @@ func TestServerRouting(t *testing.T) {
 				req.Header.Set("Content-Type", tt.contentType)
 			}
 
+			if !tt.anonymous {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
 			// Add origin header for CORS testing
 			req.Header.Set("Origin", "http://localhost:3000")
 
@@ -147,10 +238,12 @@ func TestServerMiddlewareOrder(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 
-	server := NewServer(db)
+	server := NewServer(db, stream.NewHub(0), nil)
+	token := login(t, server.Handler, "middleware@example.com")
 
 	// Test that middleware is applied in correct order
 	req := httptest.NewRequest("GET", "/api/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Origin", "http://localhost:3000")
 
 	w := httptest.NewRecorder()
@@ -168,7 +261,8 @@ func TestAPIVersioningMiddleware(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 
-	server := NewServer(db)
+	server := NewServer(db, stream.NewHub(0), nil)
+	token := login(t, server.Handler, "versioning@example.com")
 
 	tests := []struct {
 		name           string
@@ -195,6 +289,7 @@ func TestAPIVersioningMiddleware(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest("GET", "/api/tasks", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
 			if tt.clientVersion != "" {
 				req.Header.Set("X-API-Version", tt.clientVersion)
 			}
@@ -207,3 +302,132 @@ func TestAPIVersioningMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestUserIsolation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	server := NewServer(db, stream.NewHub(0), nil)
+	alice := login(t, server.Handler, "alice@example.com")
+	bob := login(t, server.Handler, "bob@example.com")
+
+	do := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		server.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(alice, "POST", "/api/tasks", `{"title": "Alice's task"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var task struct {
+		ID int `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+	taskPath := fmt.Sprintf("/api/tasks/%d", task.ID)
+
+	w = do(alice, "POST", "/api/events", `{"title": "Alice's event", "start_time": "2099-01-01T09:00:00Z", "end_time": "2099-01-01T10:00:00Z"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var event struct {
+		ID int `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &event))
+	eventPath := fmt.Sprintf("/api/events/%d", event.ID)
+
+	// Bob can neither see nor modify Alice's rows
+	assert.Equal(t, http.StatusNotFound, do(bob, "GET", taskPath, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(bob, "PUT", taskPath, `{"title": "Hijacked"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(bob, "PATCH", taskPath, `{"title": "Hijacked"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(bob, "DELETE", taskPath, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(bob, "GET", eventPath, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(bob, "PUT", eventPath, `{"title": "Hijacked"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(bob, "PATCH", eventPath, `{"title": "Hijacked"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(bob, "DELETE", eventPath, "").Code)
+
+	w = do(bob, "GET", "/api/tasks", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "Alice's task")
+	w = do(bob, "GET", "/api/events", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "Alice's event")
+	w = do(bob, "GET", "/api/calendar.ics", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "Alice")
+
+	// Alice still sees her rows untouched
+	w = do(alice, "GET", taskPath, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Alice's task")
+	w = do(alice, "GET", eventPath, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Alice's event")
+
+	// Logging out invalidates the session
+	assert.Equal(t, http.StatusNoContent, do(alice, "POST", "/api/auth/logout", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(alice, "GET", "/api/tasks", "").Code)
+}
+
+func TestRateLimits(t *testing.T) {
+	t.Setenv("RATE_LIMIT_AUTH_REQUESTS", "3")
+	t.Setenv("RATE_LIMIT_WRITE_REQUESTS", "1")
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	server := NewServer(db, stream.NewHub(0), nil)
+	token := login(t, server.Handler, "limits@example.com")
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		server.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Writes are limited per user
+	assert.Equal(t, http.StatusCreated, send("POST", "/api/tasks", `{"title": "First"}`).Code)
+	w := send("POST", "/api/tasks", `{"title": "Second"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "RATE_LIMIT_EXCEEDED")
+
+	// Reads only count against the global limit
+	w = send("GET", "/api/tasks", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1000", w.Header().Get("X-RateLimit-Limit"))
+
+	// Login attempts are limited per IP
+	credentials := `{"email": "limits@example.com", "password": "wrong password"}`
+	assert.Equal(t, http.StatusUnauthorized, send("POST", "/api/auth/login", credentials).Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("POST", "/api/auth/login", credentials).Code)
+}
+
+func TestAdminRoutes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	t.Setenv("ADMIN_EMAILS", "admin@example.com")
+
+	backups, err := database.NewBackupManager(db, database.BackupConfig{Dir: t.TempDir()})
+	require.NoError(t, err)
+	server := NewServer(db, stream.NewHub(0), backups)
+	admin := login(t, server.Handler, "admin@example.com")
+	user := login(t, server.Handler, "user@example.com")
+
+	do := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/admin/backups", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		server.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, do(user).Code)
+	assert.Equal(t, http.StatusCreated, do(admin).Code)
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+)
+
+// AttendeeServiceInterface defines the contract for event attendee business
+// logic operations. The attendees of an override are those of its series,
+// so managing them through an override changes the whole series.
+type AttendeeServiceInterface interface {
+	AddAttendee(ctx context.Context, eventID int, req CreateAttendeeRequest) (*models.Attendee, error)
+	GetEventAttendees(ctx context.Context, eventID int) ([]*models.Attendee, error)
+	UpdateAttendee(ctx context.Context, eventID, id int, req UpdateAttendeeRequest) (*models.Attendee, error)
+	RemoveAttendee(ctx context.Context, eventID, id int) error
+}
+
+// AttendeeService implements AttendeeServiceInterface
+type AttendeeService struct {
+	attendeeRepo database.AttendeeRepositoryInterface
+	eventRepo    database.EventRepositoryInterface
+	userRepo     database.UserRepositoryInterface
+	transactor   database.Transactor
+	publishers   changePublishers
+}
+
+// NewAttendeeService creates a new attendee service instance. Changes to
+// the attendees are published to publishers as updates of their series, in
+// the transaction making them.
+func NewAttendeeService(attendeeRepo database.AttendeeRepositoryInterface, eventRepo database.EventRepositoryInterface,
+	userRepo database.UserRepositoryInterface, transactor database.Transactor, publishers ...ChangePublisher) AttendeeServiceInterface {
+	return &AttendeeService{
+		attendeeRepo: attendeeRepo,
+		eventRepo:    eventRepo,
+		userRepo:     userRepo,
+		transactor:   transactor,
+		publishers:   publishers,
+	}
+}
+
+// CreateAttendeeRequest represents the request to add an attendee to an
+// event. Role defaults to models.AttendeeRequired and Status to
+// models.RSVPNeedsAction.
+type CreateAttendeeRequest struct {
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	UserID *int   `json:"user_id"`
+	Role   string `json:"role"`
+	Status string `json:"status"`
+}
+
+// UpdateAttendeeRequest represents the request to update an attendee, such
+// as recording their RSVP. The email identifies the attendee and cannot be
+// changed.
+type UpdateAttendeeRequest struct {
+	Name   *string `json:"name"`
+	UserID *int    `json:"user_id"`
+	Role   *string `json:"role"`
+	Status *string `json:"status"`
+}
+
+// maxAttendeesPerEvent bounds the number of attendees of an event
+const maxAttendeesPerEvent = 100
+
+// Attendee errors
+var (
+	ErrAttendeeNotFound     = errors.New("attendee not found")
+	ErrInvalidAttendeeEmail = errors.New("a valid attendee email address is required")
+	ErrAttendeeNameTooLong  = errors.New("attendee name cannot exceed 255 characters")
+	ErrInvalidAttendeeRole  = errors.New("role must be CHAIR, REQ-PARTICIPANT, OPT-PARTICIPANT or NON-PARTICIPANT")
+	ErrInvalidRSVPStatus    = errors.New("status must be NEEDS-ACTION, ACCEPTED, DECLINED or TENTATIVE")
+	ErrDuplicateAttendee    = errors.New("attendee already invited to the event")
+	ErrTooManyAttendees     = errors.New("an event cannot have more than 100 attendees")
+	ErrAttendeeUserNotFound = errors.New("attendee user not found")
+)
+
+// AddAttendee adds an attendee to an event. Emails are compared case
+// insensitively, and an event lists each email once.
+func (as *AttendeeService) AddAttendee(ctx context.Context, eventID int, req CreateAttendeeRequest) (*models.Attendee, error) {
+	attendee := &models.Attendee{
+		Name:   strings.TrimSpace(req.Name),
+		Email:  strings.ToLower(strings.TrimSpace(req.Email)),
+		UserID: req.UserID,
+		Role:   strings.ToUpper(strings.TrimSpace(req.Role)),
+		Status: strings.ToUpper(strings.TrimSpace(req.Status)),
+	}
+	if attendee.Role == "" {
+		attendee.Role = models.AttendeeRequired
+	}
+	if attendee.Status == "" {
+		attendee.Status = models.RSVPNeedsAction
+	}
+	if addr, err := mail.ParseAddress(attendee.Email); err != nil || addr.Address != attendee.Email {
+		return nil, ErrInvalidAttendeeEmail
+	}
+	if err := as.validateAttendee(ctx, attendee); err != nil {
+		return nil, err
+	}
+
+	var created *models.Attendee
+	err := as.changeAttendees(ctx, eventID, func(ctx context.Context, seriesID int) error {
+		attendee.EventID = seriesID
+
+		existing, err := as.attendeeRepo.GetEventAttendees(ctx, seriesID)
+		if err != nil {
+			return err
+		}
+		if len(existing) >= maxAttendeesPerEvent {
+			return ErrTooManyAttendees
+		}
+		for _, other := range existing {
+			if other.Email == attendee.Email {
+				return ErrDuplicateAttendee
+			}
+		}
+
+		created, err = as.attendeeRepo.CreateAttendee(ctx, attendee)
+		if err == sql.ErrNoRows {
+			return ErrEventNotFound
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetEventAttendees retrieves the attendees of an event
+func (as *AttendeeService) GetEventAttendees(ctx context.Context, eventID int) ([]*models.Attendee, error) {
+	series, err := as.series(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return as.attendeeRepo.GetEventAttendees(ctx, series.ID)
+}
+
+// UpdateAttendee updates the name, user account, role or RSVP status of an
+// attendee
+func (as *AttendeeService) UpdateAttendee(ctx context.Context, eventID, id int, req UpdateAttendeeRequest) (*models.Attendee, error) {
+	var attendee *models.Attendee
+	err := as.changeAttendees(ctx, eventID, func(ctx context.Context, seriesID int) error {
+		var err error
+		attendee, err = as.attendeeRepo.GetAttendeeByID(ctx, seriesID, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrAttendeeNotFound
+			}
+			return err
+		}
+
+		if req.Name != nil {
+			attendee.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.UserID != nil {
+			attendee.UserID = req.UserID
+		}
+		if req.Role != nil {
+			attendee.Role = strings.ToUpper(strings.TrimSpace(*req.Role))
+		}
+		if req.Status != nil {
+			attendee.Status = strings.ToUpper(strings.TrimSpace(*req.Status))
+		}
+		if err := as.validateAttendee(ctx, attendee); err != nil {
+			return err
+		}
+
+		err = as.attendeeRepo.UpdateAttendee(ctx, attendee)
+		if err == sql.ErrNoRows {
+			return ErrAttendeeNotFound
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attendee, nil
+}
+
+// RemoveAttendee removes an attendee from an event
+func (as *AttendeeService) RemoveAttendee(ctx context.Context, eventID, id int) error {
+	return as.changeAttendees(ctx, eventID, func(ctx context.Context, seriesID int) error {
+		err := as.attendeeRepo.DeleteAttendee(ctx, seriesID, id)
+		if err == sql.ErrNoRows {
+			return ErrAttendeeNotFound
+		}
+		return err
+	})
+}
+
+// changeAttendees runs change on the attendees of the series of an event in
+// a transaction, and publishes the change as an update of the series
+func (as *AttendeeService) changeAttendees(ctx context.Context, eventID int, change func(ctx context.Context, seriesID int) error) error {
+	return as.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		previous, err := as.series(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		if err := change(ctx, previous.ID); err != nil {
+			return err
+		}
+
+		event, err := as.eventRepo.GetEventByID(ctx, previous.ID)
+		if err != nil {
+			return err
+		}
+		return as.publishers.publishUpdate(ctx, ChangeEventUpdated, previous, event)
+	})
+}
+
+// series returns the event holding the attendees of an event: its series
+// for an override, the event itself otherwise
+func (as *AttendeeService) series(ctx context.Context, eventID int) (*models.Event, error) {
+	event, err := as.eventRepo.GetEventByID(ctx, eventID)
+	if err == nil && event.ParentID != nil {
+		event, err = as.eventRepo.GetEventByID(ctx, *event.ParentID)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+// validateAttendee validates the fields of an attendee that can be changed
+func (as *AttendeeService) validateAttendee(ctx context.Context, attendee *models.Attendee) error {
+	if len(attendee.Name) > 255 {
+		return ErrAttendeeNameTooLong
+	}
+	if !models.IsValidAttendeeRole(attendee.Role) {
+		return ErrInvalidAttendeeRole
+	}
+	if !models.IsValidRSVPStatus(attendee.Status) {
+		return ErrInvalidRSVPStatus
+	}
+
+	if attendee.UserID != nil {
+		if _, err := as.userRepo.GetUserByID(ctx, *attendee.UserID); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrAttendeeUserNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
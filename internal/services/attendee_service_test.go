@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAttendeeRepository implements AttendeeRepositoryInterface for testing
+type MockAttendeeRepository struct {
+	attendees map[int]*models.Attendee
+	nextID    int
+}
+
+func NewMockAttendeeRepository() *MockAttendeeRepository {
+	return &MockAttendeeRepository{
+		attendees: make(map[int]*models.Attendee),
+		nextID:    1,
+	}
+}
+
+func (m *MockAttendeeRepository) CreateAttendee(ctx context.Context, attendee *models.Attendee) (*models.Attendee, error) {
+	attendee.ID = m.nextID
+	m.nextID++
+	m.attendees[attendee.ID] = attendee
+	return attendee, nil
+}
+
+func (m *MockAttendeeRepository) GetAttendeeByID(ctx context.Context, eventID, id int) (*models.Attendee, error) {
+	attendee, exists := m.attendees[id]
+	if !exists || attendee.EventID != eventID {
+		return nil, sql.ErrNoRows
+	}
+	copied := *attendee
+	return &copied, nil
+}
+
+func (m *MockAttendeeRepository) GetEventAttendees(ctx context.Context, eventID int) ([]*models.Attendee, error) {
+	var attendees []*models.Attendee
+	for id := 1; id < m.nextID; id++ {
+		if attendee, exists := m.attendees[id]; exists && attendee.EventID == eventID {
+			attendees = append(attendees, attendee)
+		}
+	}
+	return attendees, nil
+}
+
+func (m *MockAttendeeRepository) UpdateAttendee(ctx context.Context, attendee *models.Attendee) error {
+	if _, err := m.GetAttendeeByID(ctx, attendee.EventID, attendee.ID); err != nil {
+		return err
+	}
+	m.attendees[attendee.ID] = attendee
+	return nil
+}
+
+func (m *MockAttendeeRepository) DeleteAttendee(ctx context.Context, eventID, id int) error {
+	if _, err := m.GetAttendeeByID(ctx, eventID, id); err != nil {
+		return err
+	}
+	delete(m.attendees, id)
+	return nil
+}
+
+// BaseRepository methods (not used in tests but required for interface)
+func (m *MockAttendeeRepository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockAttendeeRepository) GetByID(ctx context.Context, dest interface{}, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockAttendeeRepository) Update(ctx context.Context, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockAttendeeRepository) Delete(ctx context.Context, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockAttendeeRepository) List(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockAttendeeRepository) Count(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockAttendeeRepository) Exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	return false, nil
+}
+
+func TestAttendeeService(t *testing.T) {
+	eventRepo := new(MockEventRepository)
+	attendeeRepo := NewMockAttendeeRepository()
+	userRepo := NewMockUserRepository()
+	publisher := &recordingPublisher{}
+	service := NewAttendeeService(attendeeRepo, eventRepo, userRepo, MockTransactor{}, publisher)
+	ctx := context.Background()
+
+	user, err := userRepo.CreateUser(ctx, &models.User{Email: "ada@example.com"})
+	require.NoError(t, err)
+
+	seriesID := 1
+	eventRepo.On("GetEventByID", ctx, 1).Return(&models.Event{ID: 1, Title: "Standup", RecurrenceRule: "FREQ=DAILY"}, nil)
+	eventRepo.On("GetEventByID", ctx, 2).Return(&models.Event{ID: 2, Title: "Standup", ParentID: &seriesID}, nil)
+	eventRepo.On("GetEventByID", ctx, 3).Return(nil, sql.ErrNoRows)
+
+	var ada *models.Attendee
+	t.Run("add with defaults", func(t *testing.T) {
+		ada, err = service.AddAttendee(ctx, 1, CreateAttendeeRequest{Name: " Ada ", Email: "Ada@Example.com", UserID: &user.ID})
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", ada.Name)
+		assert.Equal(t, "ada@example.com", ada.Email)
+		assert.Equal(t, models.AttendeeRequired, ada.Role)
+		assert.Equal(t, models.RSVPNeedsAction, ada.Status)
+		assert.Equal(t, 1, ada.EventID)
+		assert.Equal(t, []string{ChangeEventUpdated}, publisher.types())
+	})
+
+	t.Run("overrides share the attendees of their series", func(t *testing.T) {
+		grace, err := service.AddAttendee(ctx, 2, CreateAttendeeRequest{Email: "grace@example.com", Role: "opt-participant"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, grace.EventID)
+		assert.Equal(t, models.AttendeeOptional, grace.Role)
+		require.Len(t, publisher.changes, 2)
+		assert.Equal(t, 1, publisher.changes[1].Data.(*models.Event).ID, "the series is updated")
+
+		attendees, err := service.GetEventAttendees(ctx, 2)
+		require.NoError(t, err)
+		assert.Len(t, attendees, 2)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		missing := 999
+		for _, tc := range []struct {
+			req  CreateAttendeeRequest
+			want error
+		}{
+			{CreateAttendeeRequest{Email: "not an email"}, ErrInvalidAttendeeEmail},
+			{CreateAttendeeRequest{Email: "Ada <ada@example.com>"}, ErrInvalidAttendeeEmail},
+			{CreateAttendeeRequest{Email: "ADA@example.com"}, ErrDuplicateAttendee},
+			{CreateAttendeeRequest{Email: "alan@example.com", Role: "GUEST"}, ErrInvalidAttendeeRole},
+			{CreateAttendeeRequest{Email: "alan@example.com", Status: "MAYBE"}, ErrInvalidRSVPStatus},
+			{CreateAttendeeRequest{Email: "alan@example.com", UserID: &missing}, ErrAttendeeUserNotFound},
+		} {
+			_, err := service.AddAttendee(ctx, 1, tc.req)
+			assert.Equal(t, tc.want, err, "%+v", tc.req)
+		}
+
+		_, err := service.AddAttendee(ctx, 3, CreateAttendeeRequest{Email: "alan@example.com"})
+		assert.Equal(t, ErrEventNotFound, err)
+		assert.Len(t, publisher.changes, 2, "rejected attendees publish nothing")
+	})
+
+	t.Run("record an RSVP", func(t *testing.T) {
+		status := "accepted"
+		updated, err := service.UpdateAttendee(ctx, 2, ada.ID, UpdateAttendeeRequest{Status: &status})
+		require.NoError(t, err)
+		assert.Equal(t, models.RSVPAccepted, updated.Status)
+		assert.Equal(t, "Ada", updated.Name)
+
+		status = "MAYBE"
+		_, err = service.UpdateAttendee(ctx, 1, ada.ID, UpdateAttendeeRequest{Status: &status})
+		assert.Equal(t, ErrInvalidRSVPStatus, err)
+		assert.Equal(t, models.RSVPAccepted, attendeeRepo.attendees[ada.ID].Status)
+
+		_, err = service.UpdateAttendee(ctx, 1, 999, UpdateAttendeeRequest{})
+		assert.Equal(t, ErrAttendeeNotFound, err)
+	})
+
+	t.Run("limits the number of attendees", func(t *testing.T) {
+		for i := len(attendeeRepo.attendees); i < maxAttendeesPerEvent; i++ {
+			attendeeRepo.attendees[attendeeRepo.nextID] = &models.Attendee{ID: attendeeRepo.nextID, EventID: 1}
+			attendeeRepo.nextID++
+		}
+
+		_, err := service.AddAttendee(ctx, 1, CreateAttendeeRequest{Email: "alan@example.com"})
+		assert.Equal(t, ErrTooManyAttendees, err)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		count := len(publisher.changes)
+		assert.NoError(t, service.RemoveAttendee(ctx, 1, ada.ID))
+		assert.Len(t, publisher.changes, count+1)
+		assert.Equal(t, ErrAttendeeNotFound, service.RemoveAttendee(ctx, 1, ada.ID))
+		assert.Len(t, publisher.changes, count+1)
+	})
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/database"
+	"agenda/internal/models"
+)
+
+// AuditServiceInterface defines the contract for audit log operations.
+// Audit services record the changes published by the task and event
+// services in the audit log.
+type AuditServiceInterface interface {
+	ChangePublisher
+
+	ListAuditEntries(ctx context.Context, filters AuditListFilters) ([]*models.AuditEntry, error)
+	GetTaskHistory(ctx context.Context, id int, filters AuditListFilters) ([]*models.AuditEntry, error)
+	GetEventHistory(ctx context.Context, id int, filters AuditListFilters) ([]*models.AuditEntry, error)
+}
+
+// AuditService implements AuditServiceInterface
+type AuditService struct {
+	auditRepo database.AuditRepositoryInterface
+	taskRepo  database.TaskRepositoryInterface
+	eventRepo database.EventRepositoryInterface
+}
+
+// NewAuditService creates a new audit service instance
+func NewAuditService(auditRepo database.AuditRepositoryInterface, taskRepo database.TaskRepositoryInterface,
+	eventRepo database.EventRepositoryInterface) AuditServiceInterface {
+	return &AuditService{
+		auditRepo: auditRepo,
+		taskRepo:  taskRepo,
+		eventRepo: eventRepo,
+	}
+}
+
+// AuditListFilters represents filters for listing audit log entries
+type AuditListFilters struct {
+	EntityType string
+	EntityID   int
+	Action     string
+	ActorID    *int
+	Since      *time.Time
+	Until      *time.Time
+
+	// Before lists the entries older than the entry with this ID, to page
+	// through the log
+	Before int
+	Limit  int
+}
+
+// Audit log limits
+const (
+	defaultAuditPage = 50
+	maxAuditPage     = 200
+)
+
+// Audit errors
+var (
+	ErrInvalidAuditEntityType = errors.New("audit entity type must be task or event")
+	ErrInvalidAuditAction     = errors.New("invalid audit action")
+	ErrInvalidAuditRange      = errors.New("since must be before until")
+)
+
+// unauditedFields are the fields left out of the recorded changes: they
+// change with every write or are derived from other items
+var unauditedFields = map[string]bool{
+	"id":         true,
+	"version":    true,
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+	"subtasks":   true,
+}
+
+// Publish records a change in the audit log, in the transaction making it
+func (as *AuditService) Publish(ctx context.Context, change Change) error {
+	entityType, action, _ := strings.Cut(change.Type, ".")
+
+	entry := &models.AuditEntry{
+		EntityType: entityType,
+		Action:     action,
+		CreatedAt:  change.OccurredAt,
+	}
+	switch item := change.Data.(type) {
+	case *models.Task:
+		entry.EntityID, entry.UserID = item.ID, item.UserID
+	case *models.Event:
+		entry.EntityID, entry.UserID = item.ID, item.UserID
+	default:
+		return fmt.Errorf("cannot audit changes to %T", change.Data)
+	}
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		entry.ActorID = &userID
+	}
+
+	// Creations and restorations record the fields the item has, deletions
+	// the fields it had
+	before, after := change.Previous, change.Data
+	switch change.Type {
+	case ChangeTaskCreated, ChangeTaskRestored, ChangeEventCreated, ChangeEventRestored:
+		before = nil
+	case ChangeTaskDeleted, ChangeEventDeleted:
+		before, after = change.Data, nil
+	}
+
+	changes, err := fieldChanges(before, after)
+	if err != nil {
+		return fmt.Errorf("failed to compute audited changes: %w", err)
+	}
+	entry.Changes = changes
+
+	if _, err := as.auditRepo.CreateAuditEntry(ctx, entry); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListAuditEntries retrieves the audit log entries matching the filters,
+// newest first
+func (as *AuditService) ListAuditEntries(ctx context.Context, filters AuditListFilters) ([]*models.AuditEntry, error) {
+	if filters.EntityType != "" && filters.EntityType != models.AuditEntityTask && filters.EntityType != models.AuditEntityEvent {
+		return nil, ErrInvalidAuditEntityType
+	}
+	if filters.Action != "" && !isAuditAction(filters.Action) {
+		return nil, ErrInvalidAuditAction
+	}
+	if filters.Since != nil && filters.Until != nil && !filters.Since.Before(*filters.Until) {
+		return nil, ErrInvalidAuditRange
+	}
+
+	limit := filters.Limit
+	if limit <= 0 {
+		limit = defaultAuditPage
+	}
+	if limit > maxAuditPage {
+		limit = maxAuditPage
+	}
+
+	entries, err := as.auditRepo.ListAuditEntries(ctx, database.AuditFilters{
+		EntityType: filters.EntityType,
+		EntityID:   filters.EntityID,
+		Action:     filters.Action,
+		ActorID:    filters.ActorID,
+		Since:      filters.Since,
+		Until:      filters.Until,
+		BeforeID:   filters.Before,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetTaskHistory retrieves the audit log entries of a task, newest first.
+// The history stays available after the task is deleted.
+func (as *AuditService) GetTaskHistory(ctx context.Context, id int, filters AuditListFilters) ([]*models.AuditEntry, error) {
+	filters.EntityType, filters.EntityID = models.AuditEntityTask, id
+	entries, err := as.ListAuditEntries(ctx, filters)
+	if err != nil || len(entries) > 0 {
+		return entries, err
+	}
+
+	// Tasks created before the audit log existed have no entries yet
+	if _, err := as.taskRepo.GetTaskByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	return entries, nil
+}
+
+// GetEventHistory retrieves the audit log entries of an event, newest
+// first. The history stays available after the event is deleted.
+func (as *AuditService) GetEventHistory(ctx context.Context, id int, filters AuditListFilters) ([]*models.AuditEntry, error) {
+	filters.EntityType, filters.EntityID = models.AuditEntityEvent, id
+	entries, err := as.ListAuditEntries(ctx, filters)
+	if err != nil || len(entries) > 0 {
+		return entries, err
+	}
+
+	// Events created before the audit log existed have no entries yet
+	if _, err := as.eventRepo.GetEventByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEventNotFound
+		}
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	return entries, nil
+}
+
+// isAuditAction reports whether action is the action of a change type
+func isAuditAction(action string) bool {
+	for _, changeType := range ChangeTypes {
+		if _, changeAction, _ := strings.Cut(changeType, "."); changeAction == action {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldChanges compares the JSON representations of an item before and
+// after a change, either of which may be nil. Fields missing on one side
+// and empty on the other are not changes.
+func fieldChanges(before, after interface{}) (models.FieldChanges, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := models.FieldChanges{}
+	for name, value := range afterFields {
+		previous, ok := beforeFields[name]
+		if unauditedFields[name] || (ok && bytes.Equal(previous, value)) || (!ok && isEmptyJSON(value)) {
+			continue
+		}
+		changes[name] = models.FieldChange{Before: previous, After: value}
+	}
+	for name, previous := range beforeFields {
+		if _, ok := afterFields[name]; ok || unauditedFields[name] || isEmptyJSON(previous) {
+			continue
+		}
+		changes[name] = models.FieldChange{Before: previous}
+	}
+	return changes, nil
+}
+
+// jsonFields returns the members of the JSON object representing item
+func jsonFields(item interface{}) (map[string]json.RawMessage, error) {
+	if item == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// isEmptyJSON reports whether a JSON value is null or an empty string,
+// array or object
+func isEmptyJSON(value json.RawMessage) bool {
+	switch string(value) {
+	case "null", `""`, "[]", "{}":
+		return true
+	}
+	return false
+}
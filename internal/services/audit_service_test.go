@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/database"
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAuditRepository implements AuditRepositoryInterface for testing
+type MockAuditRepository struct {
+	entries []*models.AuditEntry
+	filters []database.AuditFilters
+}
+
+func (m *MockAuditRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) (*models.AuditEntry, error) {
+	entry.ID = len(m.entries) + 1
+	m.entries = append(m.entries, entry)
+	return entry, nil
+}
+
+func (m *MockAuditRepository) ListAuditEntries(ctx context.Context, filters database.AuditFilters) ([]*models.AuditEntry, error) {
+	m.filters = append(m.filters, filters)
+
+	var entries []*models.AuditEntry
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		entry := m.entries[i]
+		if (filters.EntityType == "" || entry.EntityType == filters.EntityType) &&
+			(filters.EntityID == 0 || entry.EntityID == filters.EntityID) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// BaseRepository methods (not used in tests but required for interface)
+func (m *MockAuditRepository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockAuditRepository) GetByID(ctx context.Context, dest interface{}, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockAuditRepository) Update(ctx context.Context, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockAuditRepository) Delete(ctx context.Context, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockAuditRepository) Count(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockAuditRepository) Exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	return false, nil
+}
+
+func TestAuditService_RecordsTaskChanges(t *testing.T) {
+	auditRepo := &MockAuditRepository{}
+	taskRepo := NewMockTaskRepository()
+	auditService := NewAuditService(auditRepo, taskRepo, new(MockEventRepository))
+	taskService := NewTaskService(taskRepo, MockTransactor{}, auditService)
+	ctx := auth.WithUserID(context.Background(), 3)
+
+	task, err := taskService.CreateTask(ctx, CreateTaskRequest{Title: "Write report", Priority: models.TaskPriorityHigh})
+	require.NoError(t, err)
+	title := "Write final report"
+	_, err = taskService.UpdateTask(ctx, task.ID, UpdateTaskRequest{Title: &title})
+	require.NoError(t, err)
+	_, err = taskService.CompleteTask(ctx, task.ID)
+	require.NoError(t, err)
+	_, err = taskService.ReopenTask(ctx, task.ID)
+	require.NoError(t, err)
+	require.NoError(t, taskService.DeleteTask(ctx, task.ID))
+
+	entries := auditRepo.entries
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+		assert.Equal(t, models.AuditEntityTask, entry.EntityType)
+		assert.Equal(t, task.ID, entry.EntityID)
+		require.NotNil(t, entry.ActorID)
+		assert.Equal(t, 3, *entry.ActorID)
+	}
+	require.Equal(t, []string{"created", "updated", "completed", "reopened", "deleted"}, actions)
+
+	t.Run("creation records the set fields", func(t *testing.T) {
+		changes := entries[0].Changes
+		assert.Equal(t, `"Write report"`, string(changes["title"].After))
+		assert.Nil(t, changes["title"].Before)
+		assert.Equal(t, `"high"`, string(changes["priority"].After))
+		assert.NotContains(t, changes, "description", "empty fields are not recorded")
+		assert.NotContains(t, changes, "version")
+	})
+
+	t.Run("updates record the changed fields", func(t *testing.T) {
+		assert.Equal(t, models.FieldChanges{
+			"title": {Before: []byte(`"Write report"`), After: []byte(`"Write final report"`)},
+		}, entries[1].Changes)
+	})
+
+	t.Run("completion and reopening record the status", func(t *testing.T) {
+		completed := entries[2].Changes
+		assert.Equal(t, `"pending"`, string(completed["status"].Before))
+		assert.Equal(t, `"completed"`, string(completed["status"].After))
+		assert.Contains(t, completed, "completed_at")
+
+		reopened := entries[3].Changes
+		assert.Equal(t, `"completed"`, string(reopened["status"].Before))
+		assert.Equal(t, `"pending"`, string(reopened["status"].After))
+		assert.Equal(t, "null", string(reopened["completed_at"].After))
+	})
+
+	t.Run("deletion records the removed fields", func(t *testing.T) {
+		changes := entries[4].Changes
+		assert.Equal(t, `"Write final report"`, string(changes["title"].Before))
+		assert.Nil(t, changes["title"].After)
+	})
+}
+
+func TestAuditService_ListAuditEntries(t *testing.T) {
+	auditRepo := &MockAuditRepository{}
+	service := NewAuditService(auditRepo, NewMockTaskRepository(), new(MockEventRepository))
+	ctx := context.Background()
+
+	since := time.Date(2030, time.January, 2, 0, 0, 0, 0, time.UTC)
+	until := since.Add(-time.Hour)
+	tests := []struct {
+		name    string
+		filters AuditListFilters
+		want    error
+	}{
+		{"unknown entity type", AuditListFilters{EntityType: "note"}, ErrInvalidAuditEntityType},
+		{"unknown action", AuditListFilters{Action: "exploded"}, ErrInvalidAuditAction},
+		{"empty range", AuditListFilters{Since: &since, Until: &until}, ErrInvalidAuditRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ListAuditEntries(ctx, tt.filters)
+			assert.Equal(t, tt.want, err)
+		})
+	}
+
+	t.Run("limits the page size", func(t *testing.T) {
+		auditRepo.filters = nil
+		_, err := service.ListAuditEntries(ctx, AuditListFilters{Action: "restored"})
+		require.NoError(t, err)
+		_, err = service.ListAuditEntries(ctx, AuditListFilters{Limit: 1000})
+		require.NoError(t, err)
+
+		require.Len(t, auditRepo.filters, 2)
+		assert.Equal(t, defaultAuditPage, auditRepo.filters[0].Limit)
+		assert.Equal(t, maxAuditPage, auditRepo.filters[1].Limit)
+	})
+
+	t.Run("history of a missing task", func(t *testing.T) {
+		_, err := service.GetTaskHistory(ctx, 999, AuditListFilters{})
+		assert.Equal(t, ErrTaskNotFound, err)
+	})
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/database"
+	"agenda/internal/models"
+)
+
+// sessionTTL is how long a login session stays valid
+const sessionTTL = 7 * 24 * time.Hour
+
+// Password length limits
+const (
+	// minPasswordLength is the minimum number of characters in a password
+	minPasswordLength = 8
+	// maxPasswordLength is the maximum number of bytes in a password, the
+	// most bcrypt can hash
+	maxPasswordLength = 72
+)
+
+// AuthServiceInterface defines the contract for user accounts and login sessions
+type AuthServiceInterface interface {
+	Register(ctx context.Context, req RegisterRequest) (*models.User, error)
+	Login(ctx context.Context, req LoginRequest) (*LoginResult, error)
+	Logout(ctx context.Context, token string) error
+	Authenticate(ctx context.Context, token string) (*models.User, error)
+	CurrentUser(ctx context.Context) (*models.User, error)
+}
+
+// AuthService implements AuthServiceInterface
+type AuthService struct {
+	userRepo database.UserRepositoryInterface
+}
+
+// NewAuthService creates a new auth service instance
+func NewAuthService(userRepo database.UserRepositoryInterface) AuthServiceInterface {
+	return &AuthService{
+		userRepo: userRepo,
+	}
+}
+
+// RegisterRequest represents the request to create a new user account
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// LoginRequest represents the request to open a new session
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginResult is returned after a successful login. The token must be sent
+// as a bearer token on subsequent requests.
+type LoginResult struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      *models.User `json:"user"`
+}
+
+// Auth errors
+var (
+	ErrInvalidEmail       = errors.New("a valid email address is required")
+	ErrPasswordTooShort   = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	ErrPasswordTooLong    = fmt.Errorf("password cannot exceed %d bytes", maxPasswordLength)
+	ErrUserNameTooLong    = errors.New("user name cannot exceed 255 characters")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidSession     = auth.ErrInvalidSession
+)
+
+// Register creates a new user account
+func (as *AuthService) Register(ctx context.Context, req RegisterRequest) (*models.User, error) {
+	email := normalizeEmail(req.Email)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, ErrInvalidEmail
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, ErrPasswordTooShort
+	}
+	if len(req.Password) > maxPasswordLength {
+		return nil, ErrPasswordTooLong
+	}
+	name := strings.TrimSpace(req.Name)
+	if len(name) > 255 {
+		return nil, ErrUserNameTooLong
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := as.userRepo.CreateUser(ctx, &models.User{
+		Email:        email,
+		Name:         name,
+		PasswordHash: hash,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrDuplicateEmail) {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// Login checks the credentials of a user and opens a new session
+func (as *AuthService) Login(ctx context.Context, req LoginRequest) (*LoginResult, error) {
+	user, err := as.userRepo.GetUserByEmail(ctx, normalizeEmail(req.Email))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			auth.CheckNoPassword(req.Password)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := auth.CheckPassword(user.PasswordHash, req.Password); err != nil {
+		if errors.Is(err, auth.ErrPasswordMismatch) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to check password: %w", err)
+	}
+
+	token, err := auth.NewToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	session := &models.Session{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(sessionTTL),
+	}
+	if err := as.userRepo.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	// Opportunistically clean up sessions that can no longer be used
+	if err := as.userRepo.DeleteExpiredSessions(ctx, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return &LoginResult{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		User:      user,
+	}, nil
+}
+
+// Logout ends the session identified by token
+func (as *AuthService) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return ErrInvalidSession
+	}
+
+	if err := as.userRepo.DeleteSession(ctx, auth.HashToken(token)); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate resolves a session token to the user that owns it
+func (as *AuthService) Authenticate(ctx context.Context, token string) (*models.User, error) {
+	if token == "" {
+		return nil, ErrInvalidSession
+	}
+
+	session, err := as.userRepo.GetSession(ctx, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidSession
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if session.IsExpired(time.Now()) {
+		return nil, ErrInvalidSession
+	}
+
+	user, err := as.userRepo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidSession
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// CurrentUser returns the authenticated user carried by ctx
+func (as *AuthService) CurrentUser(ctx context.Context) (*models.User, error) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrInvalidSession
+	}
+
+	user, err := as.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidSession
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// normalizeEmail returns the canonical form of an email address
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
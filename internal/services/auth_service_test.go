@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/database"
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockUserRepository implements UserRepositoryInterface for testing
+type MockUserRepository struct {
+	users    map[int]*models.User
+	sessions map[string]*models.Session
+	nextID   int
+}
+
+func NewMockUserRepository() *MockUserRepository {
+	return &MockUserRepository{
+		users:    make(map[int]*models.User),
+		sessions: make(map[string]*models.Session),
+		nextID:   1,
+	}
+}
+
+func (m *MockUserRepository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	for _, existing := range m.users {
+		if existing.Email == user.Email {
+			return nil, database.ErrDuplicateEmail
+		}
+	}
+
+	user.ID = m.nextID
+	m.nextID++
+	m.users[user.ID] = user
+	return user, nil
+}
+
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	user, exists := m.users[id]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockUserRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	m.sessions[session.TokenHash] = session
+	return nil
+}
+
+func (m *MockUserRepository) GetSession(ctx context.Context, tokenHash string) (*models.Session, error) {
+	session, exists := m.sessions[tokenHash]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return session, nil
+}
+
+func (m *MockUserRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+func (m *MockUserRepository) DeleteExpiredSessions(ctx context.Context, now time.Time) error {
+	for hash, session := range m.sessions {
+		if session.IsExpired(now) {
+			delete(m.sessions, hash)
+		}
+	}
+	return nil
+}
+
+// BaseRepository methods (not used in tests)
+func (m *MockUserRepository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+func (m *MockUserRepository) GetByID(ctx context.Context, dest interface{}, query string, id interface{}) error {
+	return nil
+}
+func (m *MockUserRepository) Update(ctx context.Context, query string, args ...interface{}) error {
+	return nil
+}
+func (m *MockUserRepository) Delete(ctx context.Context, query string, id interface{}) error {
+	return nil
+}
+func (m *MockUserRepository) List(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return nil
+}
+func (m *MockUserRepository) Count(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+func (m *MockUserRepository) Exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	return false, nil
+}
+
+func TestAuthService_Register(t *testing.T) {
+	service := NewAuthService(NewMockUserRepository())
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, RegisterRequest{Email: " Alice@Example.com ", Name: "Alice", Password: "correct horse"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.NotEqual(t, "correct horse", user.PasswordHash)
+
+	tests := []struct {
+		name    string
+		req     RegisterRequest
+		wantErr error
+	}{
+		{"duplicate email", RegisterRequest{Email: "ALICE@example.com", Password: "correct horse"}, ErrEmailTaken},
+		{"missing email", RegisterRequest{Password: "correct horse"}, ErrInvalidEmail},
+		{"malformed email", RegisterRequest{Email: "alice", Password: "correct horse"}, ErrInvalidEmail},
+		{"display name form", RegisterRequest{Email: "Alice <bob@example.com>", Password: "correct horse"}, ErrInvalidEmail},
+		{"short password", RegisterRequest{Email: "bob@example.com", Password: "short"}, ErrPasswordTooShort},
+		{"long password", RegisterRequest{Email: "bob@example.com", Password: strings.Repeat("p", maxPasswordLength+1)}, ErrPasswordTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Register(ctx, tt.req)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestAuthService_LoginAndAuthenticate(t *testing.T) {
+	repo := NewMockUserRepository()
+	service := NewAuthService(repo)
+	ctx := context.Background()
+
+	registered, err := service.Register(ctx, RegisterRequest{Email: "alice@example.com", Password: "correct horse"})
+	require.NoError(t, err)
+
+	_, err = service.Login(ctx, LoginRequest{Email: "alice@example.com", Password: "wrong password"})
+	assert.Equal(t, ErrInvalidCredentials, err)
+	_, err = service.Login(ctx, LoginRequest{Email: "nobody@example.com", Password: "correct horse"})
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	result, err := service.Login(ctx, LoginRequest{Email: "Alice@example.com", Password: "correct horse"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Token)
+	assert.WithinDuration(t, time.Now().Add(sessionTTL), result.ExpiresAt, time.Minute)
+
+	// Only the digest of the token is stored
+	_, stored := repo.sessions[result.Token]
+	assert.False(t, stored)
+
+	user, err := service.Authenticate(ctx, result.Token)
+	require.NoError(t, err)
+	assert.Equal(t, registered.ID, user.ID)
+
+	current, err := service.CurrentUser(auth.WithUserID(ctx, user.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", current.Email)
+	_, err = service.CurrentUser(ctx)
+	assert.Equal(t, ErrInvalidSession, err)
+
+	_, err = service.Authenticate(ctx, "unknown")
+	assert.Equal(t, ErrInvalidSession, err)
+
+	require.NoError(t, service.Logout(ctx, result.Token))
+	_, err = service.Authenticate(ctx, result.Token)
+	assert.Equal(t, ErrInvalidSession, err)
+}
+
+func TestAuthService_ExpiredSession(t *testing.T) {
+	repo := NewMockUserRepository()
+	service := NewAuthService(repo)
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, RegisterRequest{Email: "alice@example.com", Password: "correct horse"})
+	require.NoError(t, err)
+	result, err := service.Login(ctx, LoginRequest{Email: "alice@example.com", Password: "correct horse"})
+	require.NoError(t, err)
+
+	repo.sessions[auth.HashToken(result.Token)].ExpiresAt = time.Now().Add(-time.Minute)
+
+	_, err = service.Authenticate(ctx, result.Token)
+	assert.Equal(t, ErrInvalidSession, err)
+
+	// The next login removes the expired session
+	_, err = service.Login(ctx, LoginRequest{Email: "alice@example.com", Password: "correct horse"})
+	require.NoError(t, err)
+	assert.Len(t, repo.sessions, 1)
+}
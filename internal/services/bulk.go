@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"agenda/internal/database"
+)
+
+// Bulk modes
+const (
+	// BulkAtomic applies every operation of a bulk request or none of them
+	BulkAtomic = "atomic"
+	// BulkBestEffort applies the operations that succeed and reports the
+	// others
+	BulkBestEffort = "best_effort"
+)
+
+// Bulk operations
+const (
+	BulkCreate   = "create"
+	BulkUpdate   = "update"
+	BulkDelete   = "delete"
+	BulkComplete = "complete"
+)
+
+// maxBulkItems bounds the number of operations of a bulk request, and the
+// number of items its selection may match
+const maxBulkItems = 500
+
+// Bulk errors
+var (
+	ErrInvalidBulkMode      = errors.New("bulk mode must be 'atomic' or 'best_effort'")
+	ErrInvalidBulkRequest   = errors.New("bulk request needs either operations or a selection")
+	ErrEmptyBulkSelection   = errors.New("bulk selection needs at least one filter")
+	ErrBulkTooLarge         = errors.New("bulk requests cannot exceed 500 items")
+	ErrInvalidBulkOperation = errors.New("unsupported bulk operation")
+	ErrBulkIDRequired       = errors.New("bulk operation needs the ID of the item")
+	ErrBulkDataRequired     = errors.New("bulk create and update operations need data")
+	ErrBulkAborted          = errors.New("operation was not applied because another operation failed")
+)
+
+// errBulkRollback rolls back the transaction of an atomic bulk request
+var errBulkRollback = errors.New("bulk operation failed")
+
+// BulkResult is the outcome of an operation of a bulk request
+type BulkResult struct {
+	// Index is the position of the operation in the request, or of the
+	// item in the selection
+	Index int
+	Op    string
+	// ID is the ID of the item, 0 when a creation was not applied
+	ID int
+	// Item is the task or event after the operation, nil for deletions and
+	// operations that were not applied
+	Item interface{}
+	Err  error
+}
+
+// validateBulkMode defaults the mode of a bulk request to atomic
+func validateBulkMode(mode *string) error {
+	switch *mode {
+	case "":
+		*mode = BulkAtomic
+	case BulkAtomic, BulkBestEffort:
+	default:
+		return ErrInvalidBulkMode
+	}
+	return nil
+}
+
+// runBulk applies the operation of every result in turn. In atomic mode
+// they all run in a single transaction, rolled back as soon as one fails;
+// the others are then reported as ErrBulkAborted. In best effort mode each
+// operation commits on its own.
+func runBulk(ctx context.Context, transactor database.Transactor, mode string, results []*BulkResult,
+	apply func(ctx context.Context, result *BulkResult) error) error {
+	if mode == BulkBestEffort {
+		for _, result := range results {
+			result.Err = apply(ctx, result)
+		}
+		return nil
+	}
+
+	err := transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, result := range results {
+			if err := apply(ctx, result); err != nil {
+				result.Err = err
+				return errBulkRollback
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, errBulkRollback) {
+		return err
+	}
+
+	for _, result := range results {
+		result.Item = nil
+		if result.Op == BulkCreate {
+			result.ID = 0
+		}
+		if result.Err == nil {
+			result.Err = ErrBulkAborted
+		}
+	}
+	return nil
+}
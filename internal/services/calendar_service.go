@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+)
+
+// CalendarServiceInterface defines the contract for calendar business logic operations
+type CalendarServiceInterface interface {
+	CreateCalendar(ctx context.Context, req CreateCalendarRequest) (*models.Calendar, error)
+	GetCalendarByID(ctx context.Context, id int) (*models.Calendar, error)
+	ListCalendars(ctx context.Context) ([]*models.Calendar, error)
+	UpdateCalendar(ctx context.Context, id int, req UpdateCalendarRequest) (*models.Calendar, error)
+	DeleteCalendar(ctx context.Context, id int) error
+}
+
+// CalendarService implements CalendarServiceInterface
+type CalendarService struct {
+	calendarRepo database.CalendarRepositoryInterface
+}
+
+// NewCalendarService creates a new calendar service instance
+func NewCalendarService(calendarRepo database.CalendarRepositoryInterface) CalendarServiceInterface {
+	return &CalendarService{
+		calendarRepo: calendarRepo,
+	}
+}
+
+// CreateCalendarRequest represents the request to create a new calendar.
+// Kind defaults to personal and ConflictPolicy to reject.
+type CreateCalendarRequest struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Kind           string `json:"kind"`
+	ConflictPolicy string `json:"conflict_policy"`
+}
+
+// UpdateCalendarRequest represents the request to update an existing calendar
+type UpdateCalendarRequest struct {
+	Name           *string `json:"name"`
+	Description    *string `json:"description"`
+	Kind           *string `json:"kind"`
+	ConflictPolicy *string `json:"conflict_policy"`
+}
+
+// Calendar limits
+const (
+	maxCalendars                 = 50
+	maxCalendarNameLength        = 100
+	maxCalendarDescriptionLength = 1000
+)
+
+// Calendar errors
+var (
+	ErrCalendarNotFound           = errors.New("calendar not found")
+	ErrCalendarNameRequired       = errors.New("calendar name is required")
+	ErrCalendarNameTooLong        = errors.New("calendar name cannot exceed 100 characters")
+	ErrCalendarDescriptionTooLong = errors.New("calendar description cannot exceed 1000 characters")
+	ErrInvalidCalendarKind        = errors.New("calendar kind must be 'personal', 'team' or 'room'")
+	ErrInvalidConflictPolicy      = errors.New("conflict policy must be 'reject', 'warn' or 'ignore'")
+	ErrCalendarNotEmpty           = errors.New("calendar still has events")
+	ErrTooManyCalendars           = errors.New("cannot have more than 50 calendars")
+)
+
+// CreateCalendar creates a new calendar with validation
+func (cs *CalendarService) CreateCalendar(ctx context.Context, req CreateCalendarRequest) (*models.Calendar, error) {
+	calendar := &models.Calendar{
+		Name:           strings.TrimSpace(req.Name),
+		Description:    strings.TrimSpace(req.Description),
+		Kind:           strings.ToLower(strings.TrimSpace(req.Kind)),
+		ConflictPolicy: strings.ToLower(strings.TrimSpace(req.ConflictPolicy)),
+	}
+	if calendar.Kind == "" {
+		calendar.Kind = models.CalendarPersonal
+	}
+	if calendar.ConflictPolicy == "" {
+		calendar.ConflictPolicy = models.DefaultConflictPolicy
+	}
+
+	if err := validateCalendar(calendar); err != nil {
+		return nil, err
+	}
+
+	existing, err := cs.calendarRepo.ListCalendars(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendars: %w", err)
+	}
+	if len(existing) >= maxCalendars {
+		return nil, ErrTooManyCalendars
+	}
+
+	createdCalendar, err := cs.calendarRepo.CreateCalendar(ctx, calendar)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calendar: %w", err)
+	}
+
+	return createdCalendar, nil
+}
+
+// GetCalendarByID retrieves a calendar by its ID
+func (cs *CalendarService) GetCalendarByID(ctx context.Context, id int) (*models.Calendar, error) {
+	calendar, err := cs.calendarRepo.GetCalendarByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCalendarNotFound
+		}
+		return nil, fmt.Errorf("failed to get calendar: %w", err)
+	}
+
+	return calendar, nil
+}
+
+// ListCalendars retrieves every calendar
+func (cs *CalendarService) ListCalendars(ctx context.Context) ([]*models.Calendar, error) {
+	calendars, err := cs.calendarRepo.ListCalendars(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendars: %w", err)
+	}
+
+	return calendars, nil
+}
+
+// UpdateCalendar updates an existing calendar with validation. A new
+// conflict policy applies to the events saved from then on; existing
+// conflicts are left alone.
+func (cs *CalendarService) UpdateCalendar(ctx context.Context, id int, req UpdateCalendarRequest) (*models.Calendar, error) {
+	existingCalendar, err := cs.GetCalendarByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedCalendar := *existingCalendar
+	if req.Name != nil {
+		updatedCalendar.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		updatedCalendar.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Kind != nil {
+		updatedCalendar.Kind = strings.ToLower(strings.TrimSpace(*req.Kind))
+	}
+	if req.ConflictPolicy != nil {
+		updatedCalendar.ConflictPolicy = strings.ToLower(strings.TrimSpace(*req.ConflictPolicy))
+	}
+
+	if err := validateCalendar(&updatedCalendar); err != nil {
+		return nil, err
+	}
+
+	if err := cs.calendarRepo.UpdateCalendar(ctx, &updatedCalendar); err != nil {
+		return nil, fmt.Errorf("failed to update calendar: %w", err)
+	}
+
+	return &updatedCalendar, nil
+}
+
+// DeleteCalendar removes a calendar that has no events left. Events in the
+// trash leave the calendar.
+func (cs *CalendarService) DeleteCalendar(ctx context.Context, id int) error {
+	if err := cs.calendarRepo.DeleteCalendar(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrCalendarNotFound
+		case errors.Is(err, database.ErrNotEmpty):
+			return ErrCalendarNotEmpty
+		}
+		return fmt.Errorf("failed to delete calendar: %w", err)
+	}
+
+	return nil
+}
+
+// validateCalendar validates the fields of a calendar
+func validateCalendar(calendar *models.Calendar) error {
+	if calendar.Name == "" {
+		return ErrCalendarNameRequired
+	}
+	if len(calendar.Name) > maxCalendarNameLength {
+		return ErrCalendarNameTooLong
+	}
+	if len(calendar.Description) > maxCalendarDescriptionLength {
+		return ErrCalendarDescriptionTooLong
+	}
+	if !models.IsValidCalendarKind(calendar.Kind) {
+		return ErrInvalidCalendarKind
+	}
+	if !models.IsValidConflictPolicy(calendar.ConflictPolicy) {
+		return ErrInvalidConflictPolicy
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCalendarRepository implements CalendarRepositoryInterface for testing
+type MockCalendarRepository struct {
+	calendars map[int]*models.Calendar
+	nextID    int
+	// used holds the calendars that still have events
+	used map[int]bool
+}
+
+func NewMockCalendarRepository() *MockCalendarRepository {
+	return &MockCalendarRepository{
+		calendars: make(map[int]*models.Calendar),
+		nextID:    1,
+		used:      make(map[int]bool),
+	}
+}
+
+func (m *MockCalendarRepository) CreateCalendar(ctx context.Context, calendar *models.Calendar) (*models.Calendar, error) {
+	calendar.ID = m.nextID
+	m.nextID++
+	m.calendars[calendar.ID] = calendar
+	return calendar, nil
+}
+
+func (m *MockCalendarRepository) GetCalendarByID(ctx context.Context, id int) (*models.Calendar, error) {
+	calendar, exists := m.calendars[id]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	copied := *calendar
+	return &copied, nil
+}
+
+func (m *MockCalendarRepository) ListCalendars(ctx context.Context) ([]*models.Calendar, error) {
+	var calendars []*models.Calendar
+	for id := 1; id < m.nextID; id++ {
+		if calendar, exists := m.calendars[id]; exists {
+			calendars = append(calendars, calendar)
+		}
+	}
+	return calendars, nil
+}
+
+func (m *MockCalendarRepository) UpdateCalendar(ctx context.Context, calendar *models.Calendar) error {
+	if _, exists := m.calendars[calendar.ID]; !exists {
+		return sql.ErrNoRows
+	}
+	m.calendars[calendar.ID] = calendar
+	return nil
+}
+
+func (m *MockCalendarRepository) DeleteCalendar(ctx context.Context, id int) error {
+	if _, exists := m.calendars[id]; !exists {
+		return sql.ErrNoRows
+	}
+	if m.used[id] {
+		return database.ErrNotEmpty
+	}
+	delete(m.calendars, id)
+	return nil
+}
+
+// BaseRepository methods (not used in tests but required for interface)
+func (m *MockCalendarRepository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockCalendarRepository) GetByID(ctx context.Context, dest interface{}, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockCalendarRepository) Update(ctx context.Context, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockCalendarRepository) Delete(ctx context.Context, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockCalendarRepository) List(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockCalendarRepository) Count(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockCalendarRepository) Exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	return false, nil
+}
+
+func TestCalendarService(t *testing.T) {
+	calendarRepo := NewMockCalendarRepository()
+	service := NewCalendarService(calendarRepo)
+	ctx := context.Background()
+
+	var team *models.Calendar
+	t.Run("create with defaults", func(t *testing.T) {
+		personal, err := service.CreateCalendar(ctx, CreateCalendarRequest{Name: " Personal "})
+		require.NoError(t, err)
+		assert.Equal(t, "Personal", personal.Name)
+		assert.Equal(t, models.CalendarPersonal, personal.Kind)
+		assert.Equal(t, models.ConflictReject, personal.ConflictPolicy)
+
+		team, err = service.CreateCalendar(ctx, CreateCalendarRequest{Name: "Team", Kind: "Team", ConflictPolicy: "WARN"})
+		require.NoError(t, err)
+		assert.Equal(t, models.CalendarTeam, team.Kind)
+		assert.Equal(t, models.ConflictWarn, team.ConflictPolicy)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		for _, tc := range []struct {
+			req  CreateCalendarRequest
+			want error
+		}{
+			{CreateCalendarRequest{Name: "  "}, ErrCalendarNameRequired},
+			{CreateCalendarRequest{Name: strings.Repeat("a", 101)}, ErrCalendarNameTooLong},
+			{CreateCalendarRequest{Name: "Room", Description: strings.Repeat("a", 1001)}, ErrCalendarDescriptionTooLong},
+			{CreateCalendarRequest{Name: "Room", Kind: "building"}, ErrInvalidCalendarKind},
+			{CreateCalendarRequest{Name: "Room", ConflictPolicy: "merge"}, ErrInvalidConflictPolicy},
+		} {
+			_, err := service.CreateCalendar(ctx, tc.req)
+			assert.Equal(t, tc.want, err, "%+v", tc.req)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		policy := "ignore"
+		updated, err := service.UpdateCalendar(ctx, team.ID, UpdateCalendarRequest{ConflictPolicy: &policy})
+		require.NoError(t, err)
+		assert.Equal(t, models.ConflictIgnore, updated.ConflictPolicy)
+		assert.Equal(t, "Team", updated.Name)
+
+		kind := "garage"
+		_, err = service.UpdateCalendar(ctx, team.ID, UpdateCalendarRequest{Kind: &kind})
+		assert.Equal(t, ErrInvalidCalendarKind, err)
+		assert.Equal(t, models.CalendarTeam, calendarRepo.calendars[team.ID].Kind)
+
+		_, err = service.UpdateCalendar(ctx, 99, UpdateCalendarRequest{})
+		assert.Equal(t, ErrCalendarNotFound, err)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		calendarRepo.used[team.ID] = true
+		assert.Equal(t, ErrCalendarNotEmpty, service.DeleteCalendar(ctx, team.ID))
+
+		calendarRepo.used[team.ID] = false
+		assert.NoError(t, service.DeleteCalendar(ctx, team.ID))
+		assert.Equal(t, ErrCalendarNotFound, service.DeleteCalendar(ctx, team.ID))
+	})
+
+	t.Run("limits the number of calendars", func(t *testing.T) {
+		for len(calendarRepo.calendars) < maxCalendars {
+			_, err := calendarRepo.CreateCalendar(ctx, &models.Calendar{Name: "Room"})
+			require.NoError(t, err)
+		}
+
+		_, err := service.CreateCalendar(ctx, CreateCalendarRequest{Name: "One too many"})
+		assert.Equal(t, ErrTooManyCalendars, err)
+	})
+}
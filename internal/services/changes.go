@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// Change types published when tasks and events change
+const (
+	ChangeTaskCreated   = "task.created"
+	ChangeTaskUpdated   = "task.updated"
+	ChangeTaskCompleted = "task.completed"
+	ChangeTaskReopened  = "task.reopened"
+	ChangeTaskDeleted   = "task.deleted"
+	ChangeTaskRestored  = "task.restored"
+	ChangeEventCreated  = "event.created"
+	ChangeEventUpdated  = "event.updated"
+	ChangeEventDeleted  = "event.deleted"
+	ChangeEventRestored = "event.restored"
+)
+
+// ChangeTypes lists every change type that can be published
+var ChangeTypes = []string{
+	ChangeTaskCreated,
+	ChangeTaskUpdated,
+	ChangeTaskCompleted,
+	ChangeTaskReopened,
+	ChangeTaskDeleted,
+	ChangeTaskRestored,
+	ChangeEventCreated,
+	ChangeEventUpdated,
+	ChangeEventDeleted,
+	ChangeEventRestored,
+}
+
+// IsChangeType reports whether t is a known change type
+func IsChangeType(t string) bool {
+	for _, changeType := range ChangeTypes {
+		if changeType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Change describes a task or event that was created, updated, deleted or
+// restored
+type Change struct {
+	Type string
+	// Data is the task or event after the change, or before it for
+	// deletions
+	Data interface{}
+	// Previous is the task or event before an update, nil for changes that
+	// create, delete or restore it
+	Previous   interface{}
+	OccurredAt time.Time
+}
+
+// ChangePublisher receives the changes made through the task and event
+// services. Publish is called inside the transaction making the change, so
+// a publisher writing to the database through ctx commits or rolls back
+// with it, and an error aborts the change.
+type ChangePublisher interface {
+	Publish(ctx context.Context, change Change) error
+}
+
+// changePublishers publishes changes to every publisher in turn
+type changePublishers []ChangePublisher
+
+// publish publishes a change of the given type happening now
+func (cp changePublishers) publish(ctx context.Context, changeType string, data interface{}) error {
+	return cp.publishUpdate(ctx, changeType, nil, data)
+}
+
+// publishUpdate publishes a change of the given type happening now to a task
+// or event that was previous before the change
+func (cp changePublishers) publishUpdate(ctx context.Context, changeType string, previous, data interface{}) error {
+	change := Change{Type: changeType, Data: data, Previous: previous, OccurredAt: time.Now()}
+	for _, publisher := range cp {
+		if err := publisher.Publish(ctx, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"errors"
+
+	"agenda/internal/database"
+)
+
+// ErrVersionMismatch is returned when an update is made from a version of
+// a task or event that is no longer current
+var ErrVersionMismatch = errors.New("version does not match the current version")
+
+// checkVersion checks the version an update was made from, when given,
+// against the current version
+func checkVersion(expected *int, current int) error {
+	if expected != nil && *expected != current {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// isVersionConflict reports whether err comes from a concurrent update of
+// the row being written
+func isVersionConflict(err error) bool {
+	return errors.Is(err, database.ErrVersionConflict)
+}
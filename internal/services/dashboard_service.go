@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"agenda/internal/models"
+	"agenda/internal/timezone"
 )
 
 // DashboardServiceInterface defines the contract for dashboard business logic operations
@@ -115,8 +116,7 @@ func (ds *DashboardService) GetDashboardData(ctx context.Context, filters Dashbo
 
 	// Set default filters if not provided
 	if filters.StartDate == nil {
-		now := time.Now()
-		startDate := now.Truncate(24 * time.Hour)
+		startDate := timezone.StartOfDay(time.Now(), timezone.FromContext(ctx))
 		filters.StartDate = &startDate
 	}
 	if filters.EndDate == nil {
@@ -157,10 +157,8 @@ func (ds *DashboardService) GetDashboardData(ctx context.Context, filters Dashbo
 		}
 		dashboardData.UpcomingEvents = upcomingEvents
 
-		// Get today's events
-		nowLocal := time.Now()
-		today := time.Date(nowLocal.Year(), nowLocal.Month(), nowLocal.Day(), 0, 0, 0, 0, nowLocal.Location())
-		todayEvents, err := ds.eventService.GetEventsByDay(ctx, today)
+		// Get today's events in the request time zone
+		todayEvents, err := ds.eventService.GetEventsByDay(ctx, timezone.Now(ctx))
 		if err != nil {
 			return nil, fmt.Errorf("failed to get today's events: %w", err)
 		}
@@ -237,20 +235,20 @@ func (ds *DashboardService) GetDashboardStats(ctx context.Context) (*DashboardSt
 	stats := &DashboardStats{}
 
 	// Get task statistics (user totals; avoid sampling)
-	_, totalTasks, err := ds.taskService.ListTasks(ctx, TaskListFilters{PageSize: 1})
+	_, taskPage, err := ds.taskService.ListTasks(ctx, TaskListFilters{PageSize: 1})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task statistics: %w", err)
 	}
-	stats.TotalTasks = totalTasks
+	stats.TotalTasks = taskPage.Total
 
 	// Total per status
- 	if _, completedTotal, err := ds.taskService.ListTasks(ctx, TaskListFilters{Status: models.TaskStatusCompleted, PageSize: 1}); err == nil {
-	    stats.CompletedTasks = completedTotal
+ 	if _, completedPage, err := ds.taskService.ListTasks(ctx, TaskListFilters{Status: models.TaskStatusCompleted, PageSize: 1}); err == nil {
+	    stats.CompletedTasks = completedPage.Total
 	} else {
 	    return nil, fmt.Errorf("failed to count completed tasks: %w", err)
 	}
-	if _, pendingTotal, err := ds.taskService.ListTasks(ctx, TaskListFilters{Status: models.TaskStatusPending, PageSize: 1}); err == nil {
-    	stats.PendingTasks = pendingTotal
+	if _, pendingPage, err := ds.taskService.ListTasks(ctx, TaskListFilters{Status: models.TaskStatusPending, PageSize: 1}); err == nil {
+    	stats.PendingTasks = pendingPage.Total
 	} else {
     	return nil, fmt.Errorf("failed to count pending tasks: %w", err)
 	}
@@ -279,21 +277,20 @@ func (ds *DashboardService) GetDashboardStats(ctx context.Context) (*DashboardSt
 	}
 	stats.OverdueTasks = int64(len(overdueTasks))
 
-	// Get event statistics
-	now := time.Now()
+	// Get event statistics; days and years are those of the request time zone
+	now := timezone.Now(ctx)
 	endOfYear := time.Date(now.Year(), 12, 31, 23, 59, 59, 0, now.Location())
-	allEvents, totalEvents, err := ds.eventService.ListEvents(ctx, EventListFilters{
+	allEvents, eventPage, err := ds.eventService.ListEvents(ctx, EventListFilters{
 		StartBefore: &endOfYear,
 		PageSize:    1000,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get event statistics: %w", err)
 	}
-	stats.TotalEvents = totalEvents
+	stats.TotalEvents = eventPage.Total
 
 	// Count today's events
-	today := now.Truncate(24 * time.Hour)
-	tomorrow := today.AddDate(0, 0, 1)
+	today, tomorrow := timezone.DayBounds(now, now.Location())
 	var todayCount, upcomingCount int64
 	for _, event := range allEvents {
 		if !event.StartTime.Before(today) && event.StartTime.Before(tomorrow) {
@@ -331,8 +328,8 @@ func (ds *DashboardService) GetCombinedCalendarView(ctx context.Context, year in
 	calendarData.Events = events
 
 	// Get tasks with due dates in the month (Requirement 3.2)
-	startOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
-	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	startOfMonth, endOfMonth := timezone.MonthBounds(year, month, timezone.FromContext(ctx))
+	endOfMonth = endOfMonth.Add(-time.Nanosecond)
 
 	taskFilters := TaskListFilters{
 		DueAfter:  &startOfMonth,
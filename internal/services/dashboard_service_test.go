@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"agenda/internal/models"
+	"agenda/internal/timezone"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -46,9 +47,9 @@ func (m *MockTaskService) ReopenTask(ctx context.Context, id int) (*models.Task,
 	return args.Get(0).(*models.Task), args.Error(1)
 }
 
-func (m *MockTaskService) ListTasks(ctx context.Context, filters TaskListFilters) ([]*models.Task, int64, error) {
+func (m *MockTaskService) ListTasks(ctx context.Context, filters TaskListFilters) ([]*models.Task, PageInfo, error) {
 	args := m.Called(ctx, filters)
-	return args.Get(0).([]*models.Task), args.Get(1).(int64), args.Error(2)
+	return args.Get(0).([]*models.Task), PageInfo{Total: args.Get(1).(int64)}, args.Error(2)
 }
 
 func (m *MockTaskService) GetOverdueTasks(ctx context.Context) ([]*models.Task, error) {
@@ -66,6 +67,31 @@ func (m *MockTaskService) GetUpcomingTasks(ctx context.Context, days int) ([]*mo
 	return args.Get(0).([]*models.Task), args.Error(1)
 }
 
+func (m *MockTaskService) GetSubtasks(ctx context.Context, id int) ([]*models.Task, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetDependencyTree(ctx context.Context, id int) (*TaskDependencyNode, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*TaskDependencyNode), args.Error(1)
+}
+
+func (m *MockTaskService) GetCompletedInstances(ctx context.Context, id int) ([]*models.Task, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) RestoreTask(ctx context.Context, id int) (*models.Task, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) BulkTasks(ctx context.Context, req BulkTaskRequest) ([]*BulkResult, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]*BulkResult), args.Error(1)
+}
+
 // MockEventService is a mock implementation of EventServiceInterface
 type MockEventService struct {
 	mock.Mock
@@ -91,6 +117,16 @@ func (m *MockEventService) DeleteEvent(ctx context.Context, id int) error {
 	return args.Error(0)
 }
 
+func (m *MockEventService) UpdateEventOccurrence(ctx context.Context, id int, occurrence time.Time, scope RecurrenceScope, req UpdateEventRequest) (*models.Event, error) {
+	args := m.Called(ctx, id, occurrence, scope, req)
+	return args.Get(0).(*models.Event), args.Error(1)
+}
+
+func (m *MockEventService) DeleteEventOccurrence(ctx context.Context, id int, occurrence time.Time, scope RecurrenceScope) error {
+	args := m.Called(ctx, id, occurrence, scope)
+	return args.Error(0)
+}
+
 func (m *MockEventService) GetEventsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Event, error) {
 	args := m.Called(ctx, startDate, endDate)
 	return args.Get(0).([]*models.Event), args.Error(1)
@@ -111,7 +147,7 @@ func (m *MockEventService) GetUpcomingEvents(ctx context.Context, limit int) ([]
 	return args.Get(0).([]*models.Event), args.Error(1)
 }
 
-func (m *MockEventService) CheckTimeConflicts(ctx context.Context, startTime, endTime time.Time, excludeEventID *int) ([]*models.Event, error) {
+func (m *MockEventService) CheckTimeConflicts(ctx context.Context, startTime, endTime time.Time, excludeEventID *int, attendees ...string) ([]*models.Event, error) {
 	args := m.Called(ctx, startTime, endTime, excludeEventID)
 	return args.Get(0).([]*models.Event), args.Error(1)
 }
@@ -121,9 +157,19 @@ func (m *MockEventService) ValidateEventTimes(startTime, endTime time.Time) erro
 	return args.Error(0)
 }
 
-func (m *MockEventService) ListEvents(ctx context.Context, filters EventListFilters) ([]*models.Event, int64, error) {
+func (m *MockEventService) ListEvents(ctx context.Context, filters EventListFilters) ([]*models.Event, PageInfo, error) {
 	args := m.Called(ctx, filters)
-	return args.Get(0).([]*models.Event), args.Get(1).(int64), args.Error(2)
+	return args.Get(0).([]*models.Event), PageInfo{Total: args.Get(1).(int64)}, args.Error(2)
+}
+
+func (m *MockEventService) RestoreEvent(ctx context.Context, id int) (*models.Event, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Event), args.Error(1)
+}
+
+func (m *MockEventService) BulkEvents(ctx context.Context, req BulkEventRequest) ([]*BulkResult, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]*BulkResult), args.Error(1)
 }
 
 func TestNewDashboardService(t *testing.T) {
@@ -317,6 +363,42 @@ func TestDashboardService_GetDashboardStats(t *testing.T) {
 	mockEventService.AssertExpectations(t)
 }
 
+func TestDashboardService_TimeZone(t *testing.T) {
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+	ctx := timezone.WithLocation(context.Background(), kiritimati)
+
+	mockTaskService := &MockTaskService{}
+	mockEventService := &MockEventService{}
+	service := NewDashboardService(mockTaskService, mockEventService)
+
+	// Midnight in a UTC+14 zone, a day earlier in UTC than the local date
+	startOfToday := timezone.StartOfDay(time.Now(), kiritimati)
+	allEvents := []*models.Event{
+		{ID: 1, StartTime: startOfToday.UTC()},
+		{ID: 2, StartTime: startOfToday.Add(-time.Minute).UTC()},
+	}
+
+	mockTaskService.On("GetUpcomingTasks", ctx, 7).Return([]*models.Task{}, nil)
+	mockTaskService.On("GetOverdueTasks", ctx).Return([]*models.Task{}, nil)
+	mockTaskService.On("ListTasks", ctx, mock.AnythingOfType("TaskListFilters")).Return([]*models.Task{}, int64(0), nil)
+	mockEventService.On("GetUpcomingEvents", ctx, 10).Return([]*models.Event{}, nil)
+	mockEventService.On("GetEventsByDay", ctx, mock.MatchedBy(func(day time.Time) bool {
+		return day.Location() == kiritimati && day.Day() == startOfToday.Day()
+	})).Return(allEvents[:1], nil)
+	mockEventService.On("ListEvents", ctx, mock.AnythingOfType("EventListFilters")).Return(allEvents, int64(2), nil)
+
+	result, err := service.GetDashboardData(ctx, DashboardFilters{})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.TodayEvents, 1)
+	assert.Equal(t, int64(1), result.Stats.TodayEvents)
+	mockTaskService.AssertExpectations(t)
+	mockEventService.AssertExpectations(t)
+}
+
 func TestDashboardService_GetDashboardStats_LargeDataset(t *testing.T) {
 	mockTaskService := &MockTaskService{}
 	mockEventService := &MockEventService{}
@@ -5,11 +5,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"agenda/internal/database"
 	"agenda/internal/models"
+	"agenda/internal/recurrence"
+	"agenda/internal/timezone"
 )
 
 // EventServiceInterface defines the contract for event business logic operations
@@ -20,6 +23,10 @@ type EventServiceInterface interface {
 	UpdateEvent(ctx context.Context, id int, req UpdateEventRequest) (*models.Event, error)
 	DeleteEvent(ctx context.Context, id int) error
 
+	// Recurring series operations
+	UpdateEventOccurrence(ctx context.Context, id int, occurrence time.Time, scope RecurrenceScope, req UpdateEventRequest) (*models.Event, error)
+	DeleteEventOccurrence(ctx context.Context, id int, occurrence time.Time, scope RecurrenceScope) error
+
 	// Calendar-specific operations
 	GetEventsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Event, error)
 	GetEventsByMonth(ctx context.Context, year int, month time.Month) ([]*models.Event, error)
@@ -27,37 +34,121 @@ type EventServiceInterface interface {
 	GetUpcomingEvents(ctx context.Context, limit int) ([]*models.Event, error)
 
 	// Business logic operations
-	CheckTimeConflicts(ctx context.Context, startTime, endTime time.Time, excludeEventID *int) ([]*models.Event, error)
+	CheckTimeConflicts(ctx context.Context, startTime, endTime time.Time, excludeEventID *int, attendees ...string) ([]*models.Event, error)
 	ValidateEventTimes(startTime, endTime time.Time) error
-	ListEvents(ctx context.Context, filters EventListFilters) ([]*models.Event, int64, error)
+	ListEvents(ctx context.Context, filters EventListFilters) ([]*models.Event, PageInfo, error)
+
+	// Trash operations
+	RestoreEvent(ctx context.Context, id int) (*models.Event, error)
+
+	// Bulk operations
+	BulkEvents(ctx context.Context, req BulkEventRequest) ([]*BulkResult, error)
 }
 
 // EventService implements EventServiceInterface
 type EventService struct {
-	eventRepo database.EventRepositoryInterface
+	eventRepo    database.EventRepositoryInterface
+	calendarRepo database.CalendarRepositoryInterface
+	transactor   database.Transactor
+	publishers   changePublishers
 }
 
-// NewEventService creates a new event service instance
-func NewEventService(eventRepo database.EventRepositoryInterface) EventServiceInterface {
+// NewEventService creates a new event service instance. Changes to events
+// are published to publishers in the transaction making them.
+func NewEventService(eventRepo database.EventRepositoryInterface, calendarRepo database.CalendarRepositoryInterface, transactor database.Transactor,
+	publishers ...ChangePublisher) EventServiceInterface {
 	return &EventService{
-		eventRepo: eventRepo,
+		eventRepo:    eventRepo,
+		calendarRepo: calendarRepo,
+		transactor:   transactor,
+		publishers:   publishers,
 	}
 }
 
-// CreateEventRequest represents the request to create a new event
+// CreateEventRequest represents the request to create a new event. The
+// event is scheduled in TimeZone, or in the request time zone when empty.
 type CreateEventRequest struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	StartTime   time.Time `json:"start_time"`
-	EndTime     time.Time `json:"end_time"`
+	Title          string      `json:"title"`
+	Description    string      `json:"description"`
+	StartTime      time.Time   `json:"start_time"`
+	EndTime        time.Time   `json:"end_time"`
+	TimeZone       string      `json:"time_zone"`
+	RecurrenceRule string      `json:"recurrence_rule"`
+	ExDates        []time.Time `json:"exdates"`
+	CalendarID     *int        `json:"calendar_id"`
 }
 
 // UpdateEventRequest represents the request to update an existing event
 type UpdateEventRequest struct {
-	Title       *string    `json:"title"`
-	Description *string    `json:"description"`
-	StartTime   *time.Time `json:"start_time"`
-	EndTime     *time.Time `json:"end_time"`
+	Title          *string    `json:"title"`
+	Description    *string    `json:"description"`
+	StartTime      *time.Time `json:"start_time"`
+	EndTime        *time.Time `json:"end_time"`
+	TimeZone       *string    `json:"time_zone"`
+	RecurrenceRule *string    `json:"recurrence_rule"`
+	CalendarID     *int       `json:"calendar_id"` // 0 takes the event out of its calendar
+
+	// Version, when set, is the version of the event the update was made
+	// from; the update fails with ErrVersionMismatch when it is stale
+	Version *int `json:"-"`
+}
+
+// RecurrenceScope selects which occurrences of a recurring series an edit
+// or delete applies to
+type RecurrenceScope string
+
+// Recurrence scopes
+const (
+	ScopeThisOccurrence   RecurrenceScope = "this"
+	ScopeThisAndFollowing RecurrenceScope = "following"
+	ScopeAllOccurrences   RecurrenceScope = "all"
+)
+
+// IsValid checks if the scope is one of the supported values
+func (s RecurrenceScope) IsValid() bool {
+	return s == ScopeThisOccurrence || s == ScopeThisAndFollowing || s == ScopeAllOccurrences
+}
+
+// Recurrence expansion windows
+const (
+	// upcomingHorizon bounds how far ahead recurring series are expanded for upcoming events
+	upcomingHorizon = 366 * 24 * time.Hour
+	// conflictHorizon bounds how far ahead a new recurring series is checked for conflicts
+	conflictHorizon = 366 * 24 * time.Hour
+	// maxConflictOccurrences bounds the number of occurrences checked for conflicts
+	maxConflictOccurrences = 366
+)
+
+// BulkEventOperation is an operation of a bulk event request: create,
+// update or delete. Create takes Create, update takes ID and Update, and
+// delete takes ID. Updates and deletions apply to whole series.
+type BulkEventOperation struct {
+	Op     string
+	ID     int
+	Create *CreateEventRequest
+	Update *UpdateEventRequest
+}
+
+// EventSelection selects the events a bulk request applies to
+type EventSelection struct {
+	StartAfter  *time.Time
+	StartBefore *time.Time
+	EndAfter    *time.Time
+	EndBefore   *time.Time
+	Search      string
+	CalendarID  *int // 0 selects the events without a calendar
+}
+
+// BulkEventRequest represents a bulk request, made of either a list of
+// operations or a selection of events the operation Op, with Update for
+// updates, applies to. Mode defaults to BulkAtomic.
+type BulkEventRequest struct {
+	Mode       string
+	Operations []BulkEventOperation
+
+	Select *EventSelection
+	Op     string
+	Update *UpdateEventRequest
 }
 
 // EventListFilters represents filtering options for listing events
@@ -68,8 +159,11 @@ type EventListFilters struct {
 	EndAfter    *time.Time
 	EndBefore   *time.Time
 	Search      string
+	CalendarID  *int // 0 selects the events without a calendar
 	Page        int
 	PageSize    int
+	Cursor      string // cursor of a previous page; replaces Page
+	SkipTotal   bool   // skips counting the matching events
 }
 
 // Validation errors
@@ -82,6 +176,13 @@ var (
 	ErrEventInPast             = errors.New("event cannot be scheduled in the past")
 	ErrEventTooLong            = errors.New("event duration cannot exceed 24 hours")
 	ErrTimeConflict            = errors.New("event conflicts with existing events")
+	ErrInvalidRecurrenceRule   = errors.New("invalid recurrence rule")
+	ErrInvalidRecurrenceScope  = errors.New("recurrence scope must be 'this', 'following' or 'all'")
+	ErrEventNotRecurring       = errors.New("event is not recurring")
+	ErrOccurrenceNotFound      = errors.New("occurrence not found in recurring event")
+	ErrInvalidEventTimeZone    = errors.New("event time zone must be an IANA time zone name")
+	ErrEventNotDeleted         = errors.New("event is not in the trash")
+	ErrOverrideCalendar        = errors.New("occurrences belong to the calendar of their series")
 )
 
 // CreateEvent creates a new event with validation and conflict checking
@@ -96,29 +197,39 @@ func (es *EventService) CreateEvent(ctx context.Context, req CreateEventRequest)
 		return nil, err
 	}
 
-	// Check for time conflicts
-	conflicts, err := es.CheckTimeConflicts(ctx, req.StartTime, req.EndTime, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check time conflicts: %w", err)
-	}
-	if len(conflicts) > 0 {
-		return nil, ErrTimeConflict
+	// Events are scheduled in the request time zone unless told otherwise
+	timeZone := timezone.FromContext(ctx).String()
+	if strings.TrimSpace(req.TimeZone) != "" {
+		timeZone = strings.TrimSpace(req.TimeZone)
 	}
 
 	// Create event model
 	event := &models.Event{
-		Title:       strings.TrimSpace(req.Title),
-		Description: strings.TrimSpace(req.Description),
-		StartTime:   req.StartTime,
-		EndTime:     req.EndTime,
+		Title:          strings.TrimSpace(req.Title),
+		Description:    strings.TrimSpace(req.Description),
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		TimeZone:       timeZone,
+		RecurrenceRule: strings.TrimSpace(req.RecurrenceRule),
+		ExDates:        models.TimeList(req.ExDates),
+	}
+	if req.CalendarID != nil && *req.CalendarID != 0 {
+		event.CalendarID = req.CalendarID
+	}
+
+	// Check for time conflicts, including every occurrence of a new series
+	conflicts, err := es.checkConflictPolicy(ctx, event, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create event in repository
-	createdEvent, err := es.eventRepo.CreateEvent(ctx, event)
+	createdEvent, err := es.createEvent(ctx, event)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event: %w", err)
 	}
 
+	createdEvent.Conflicts = conflicts
 	return createdEvent, nil
 }
 
@@ -153,6 +264,9 @@ func (es *EventService) UpdateEvent(ctx context.Context, id int, req UpdateEvent
 		}
 		return nil, fmt.Errorf("failed to get existing event: %w", err)
 	}
+	if err := checkVersion(req.Version, existingEvent.Version); err != nil {
+		return nil, err
+	}
 
 	// Validate update request
 	if err := es.validateUpdateEventRequest(req); err != nil {
@@ -161,38 +275,25 @@ func (es *EventService) UpdateEvent(ctx context.Context, id int, req UpdateEvent
 
 	// Apply updates
 	updatedEvent := *existingEvent
-	if req.Title != nil {
-		updatedEvent.Title = strings.TrimSpace(*req.Title)
-	}
-	if req.Description != nil {
-		updatedEvent.Description = strings.TrimSpace(*req.Description)
-	}
-	if req.StartTime != nil {
-		updatedEvent.StartTime = *req.StartTime
-	}
-	if req.EndTime != nil {
-		updatedEvent.EndTime = *req.EndTime
-	}
-
-	// Validate updated times
-	if err := es.ValidateEventTimes(updatedEvent.StartTime, updatedEvent.EndTime); err != nil {
+	if err := es.applyEventUpdate(&updatedEvent, req); err != nil {
 		return nil, err
 	}
 
 	// Check for time conflicts (excluding current event)
-	conflicts, err := es.CheckTimeConflicts(ctx, updatedEvent.StartTime, updatedEvent.EndTime, &id)
+	conflicts, err := es.checkConflictPolicy(ctx, &updatedEvent, &id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check time conflicts: %w", err)
-	}
-	if len(conflicts) > 0 {
-		return nil, ErrTimeConflict
+		return nil, err
 	}
 
 	// Update in repository
-	if err := es.eventRepo.UpdateEvent(ctx, &updatedEvent); err != nil {
+	if err := es.updateEvent(ctx, existingEvent, &updatedEvent); err != nil {
+		if isVersionConflict(err) {
+			return nil, ErrVersionMismatch
+		}
 		return nil, fmt.Errorf("failed to update event: %w", err)
 	}
 
+	updatedEvent.Conflicts = conflicts
 	return &updatedEvent, nil
 }
 
@@ -203,7 +304,7 @@ func (es *EventService) DeleteEvent(ctx context.Context, id int) error {
 	}
 
 	// Check if event exists
-	_, err := es.eventRepo.GetEventByID(ctx, id)
+	event, err := es.eventRepo.GetEventByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrEventNotFound
@@ -212,13 +313,462 @@ func (es *EventService) DeleteEvent(ctx context.Context, id int) error {
 	}
 
 	// Delete event
-	if err := es.eventRepo.DeleteEvent(ctx, id); err != nil {
+	if err := es.deleteEvent(ctx, event); err != nil {
 		return fmt.Errorf("failed to delete event: %w", err)
 	}
 
 	return nil
 }
 
+// RestoreEvent takes a deleted event out of the trash, together with the
+// overrides deleted along with a recurring series. An override can only be
+// restored while its series is not deleted. The restored event is not
+// checked for conflicts.
+func (es *EventService) RestoreEvent(ctx context.Context, id int) (*models.Event, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid event ID")
+	}
+
+	var event *models.Event
+	err := es.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := es.eventRepo.RestoreEvent(ctx, id); err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrEventNotFound
+			case errors.Is(err, database.ErrNotDeleted):
+				return ErrEventNotDeleted
+			}
+			return fmt.Errorf("failed to restore event: %w", err)
+		}
+
+		var err error
+		event, err = es.eventRepo.GetEventByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get restored event: %w", err)
+		}
+		return es.publishers.publish(ctx, ChangeEventRestored, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// BulkEvents applies the operations of a bulk request, or its operation to
+// every selected event, and returns the outcome of each
+func (es *EventService) BulkEvents(ctx context.Context, req BulkEventRequest) ([]*BulkResult, error) {
+	if err := validateBulkMode(&req.Mode); err != nil {
+		return nil, err
+	}
+
+	operations := req.Operations
+	if req.Select != nil {
+		if len(operations) > 0 {
+			return nil, ErrInvalidBulkRequest
+		}
+		if req.Op == BulkCreate || !isEventBulkOp(req.Op) {
+			return nil, ErrInvalidBulkOperation
+		}
+
+		events, err := es.selectEvents(ctx, *req.Select)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			operations = append(operations, BulkEventOperation{Op: req.Op, ID: event.ID, Update: req.Update})
+		}
+	} else if len(operations) == 0 {
+		return nil, ErrInvalidBulkRequest
+	}
+	if len(operations) > maxBulkItems {
+		return nil, ErrBulkTooLarge
+	}
+
+	results := make([]*BulkResult, len(operations))
+	for i, op := range operations {
+		results[i] = &BulkResult{Index: i, Op: op.Op, ID: op.ID}
+	}
+
+	err := runBulk(ctx, es.transactor, req.Mode, results, func(ctx context.Context, result *BulkResult) error {
+		op := operations[result.Index]
+		if !isEventBulkOp(op.Op) {
+			return ErrInvalidBulkOperation
+		}
+		if op.Op != BulkCreate && op.ID <= 0 {
+			return ErrBulkIDRequired
+		}
+
+		var event *models.Event
+		var err error
+		switch op.Op {
+		case BulkCreate:
+			if op.Create == nil {
+				return ErrBulkDataRequired
+			}
+			event, err = es.CreateEvent(ctx, *op.Create)
+		case BulkUpdate:
+			if op.Update == nil {
+				return ErrBulkDataRequired
+			}
+			event, err = es.UpdateEvent(ctx, op.ID, *op.Update)
+		case BulkDelete:
+			err = es.DeleteEvent(ctx, op.ID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if event != nil {
+			result.ID, result.Item = event.ID, event
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// selectEvents returns the events matching a bulk selection, earliest
+// first. Recurring series are selected by their first occurrence.
+func (es *EventService) selectEvents(ctx context.Context, selection EventSelection) ([]*models.Event, error) {
+	if selection.StartAfter == nil && selection.StartBefore == nil && selection.EndAfter == nil &&
+		selection.EndBefore == nil && strings.TrimSpace(selection.Search) == "" && selection.CalendarID == nil {
+		return nil, ErrEmptyBulkSelection
+	}
+
+	// One more than the limit tells selections that are too large apart
+	events, err := es.eventRepo.ListEvents(ctx, database.EventFilters{
+		StartAfter:  selection.StartAfter,
+		StartBefore: selection.StartBefore,
+		EndAfter:    selection.EndAfter,
+		EndBefore:   selection.EndBefore,
+		Search:      selection.Search,
+		CalendarID:  selection.CalendarID,
+		Limit:       maxBulkItems + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select events: %w", err)
+	}
+	if len(events) > maxBulkItems {
+		return nil, ErrBulkTooLarge
+	}
+
+	return events, nil
+}
+
+// isEventBulkOp reports whether op is an operation of bulk event requests
+func isEventBulkOp(op string) bool {
+	switch op {
+	case BulkCreate, BulkUpdate, BulkDelete:
+		return true
+	}
+	return false
+}
+
+// UpdateEventOccurrence updates a single occurrence of a recurring event,
+// that occurrence and all following ones, or the whole series depending on
+// the scope. The id may refer to the series or to one of its overrides.
+func (es *EventService) UpdateEventOccurrence(ctx context.Context, id int, occurrence time.Time, scope RecurrenceScope, req UpdateEventRequest) (*models.Event, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid event ID")
+	}
+	if !scope.IsValid() {
+		return nil, ErrInvalidRecurrenceScope
+	}
+
+	// Validate update request
+	if err := es.validateUpdateEventRequest(req); err != nil {
+		return nil, err
+	}
+
+	// The version guards the event addressed by id; the update itself may
+	// apply to its series or to another override
+	if req.Version != nil {
+		event, err := es.GetEventByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkVersion(req.Version, event.Version); err != nil {
+			return nil, err
+		}
+		req.Version = nil
+	}
+
+	master, rule, err := es.getSeriesOccurrence(ctx, id, occurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	if scope == ScopeAllOccurrences || (scope == ScopeThisAndFollowing && occurrence.Equal(master.StartTime)) {
+		return es.UpdateEvent(ctx, master.ID, req)
+	}
+
+	overrides, err := es.eventRepo.GetEventOverrides(ctx, master.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event overrides: %w", err)
+	}
+
+	if scope == ScopeThisOccurrence {
+		if req.RecurrenceRule != nil && strings.TrimSpace(*req.RecurrenceRule) != "" {
+			return nil, ErrInvalidRecurrenceRule
+		}
+
+		// Update the existing override or detach a new one from the series
+		for _, override := range overrides {
+			if override.RecurrenceID != nil && override.RecurrenceID.Equal(occurrence) {
+				return es.UpdateEvent(ctx, override.ID, req)
+			}
+		}
+
+		detached := master.Occurrence(occurrence)
+		detached.ID = 0
+		detached.ParentID = &master.ID
+		detached.RecurrenceRule = ""
+		if err := es.applyEventUpdate(detached, req); err != nil {
+			return nil, err
+		}
+
+		conflicts, err := es.checkConflictPolicy(ctx, detached, &master.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		createdEvent, err := es.createEvent(ctx, detached)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create event override: %w", err)
+		}
+		createdEvent.Conflicts = conflicts
+		return createdEvent, nil
+	}
+
+	// Split the series: the original ends before the occurrence and a new
+	// series carries the remaining occurrences with the requested changes
+	following := rule.Clone()
+	if following.Count > 0 {
+		following.Count -= rule.CountBefore(master.LocalStart(), occurrence)
+	}
+
+	series := master.Occurrence(occurrence)
+	series.ID = 0
+	series.ParentID = nil
+	series.RecurrenceID = nil
+	series.RecurrenceRule = following.String()
+	for _, exdate := range master.ExDates {
+		if !exdate.Before(occurrence) {
+			series.ExDates = append(series.ExDates, exdate)
+		}
+	}
+	if err := es.applyEventUpdate(series, req); err != nil {
+		return nil, err
+	}
+
+	conflicts, err := es.checkConflictPolicy(ctx, series, &master.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdEvent *models.Event
+	err = es.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		createdEvent, err = es.createEvent(ctx, series)
+		if err != nil {
+			return fmt.Errorf("failed to create event: %w", err)
+		}
+		return es.truncateSeries(ctx, master, rule, occurrence, overrides, createdEvent)
+	})
+	if err != nil {
+		if isVersionConflict(err) {
+			return nil, ErrVersionMismatch
+		}
+		return nil, err
+	}
+
+	createdEvent.Conflicts = conflicts
+	return createdEvent, nil
+}
+
+// DeleteEventOccurrence removes a single occurrence of a recurring event,
+// that occurrence and all following ones, or the whole series depending on
+// the scope. The id may refer to the series or to one of its overrides.
+func (es *EventService) DeleteEventOccurrence(ctx context.Context, id int, occurrence time.Time, scope RecurrenceScope) error {
+	if id <= 0 {
+		return errors.New("invalid event ID")
+	}
+	if !scope.IsValid() {
+		return ErrInvalidRecurrenceScope
+	}
+
+	master, rule, err := es.getSeriesOccurrence(ctx, id, occurrence)
+	if err != nil {
+		return err
+	}
+
+	if scope == ScopeAllOccurrences || (scope == ScopeThisAndFollowing && occurrence.Equal(master.StartTime)) {
+		return es.DeleteEvent(ctx, master.ID)
+	}
+
+	overrides, err := es.eventRepo.GetEventOverrides(ctx, master.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get event overrides: %w", err)
+	}
+
+	if scope == ScopeThisAndFollowing {
+		return es.truncateSeries(ctx, master, rule, occurrence, overrides, nil)
+	}
+
+	// Remove the override, if any, and exclude the occurrence from the series
+	return es.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, override := range overrides {
+			if override.RecurrenceID != nil && override.RecurrenceID.Equal(occurrence) {
+				if err := es.deleteEvent(ctx, override); err != nil {
+					return fmt.Errorf("failed to delete event override: %w", err)
+				}
+			}
+		}
+
+		previous := *master
+		master.ExDates = append(master.ExDates, occurrence)
+		if err := es.updateEvent(ctx, &previous, master); err != nil {
+			return fmt.Errorf("failed to update event: %w", err)
+		}
+		return nil
+	})
+}
+
+// getSeriesOccurrence resolves the recurring series an event belongs to and
+// verifies that occurrence is one of its occurrences
+func (es *EventService) getSeriesOccurrence(ctx context.Context, id int, occurrence time.Time) (*models.Event, *recurrence.Rule, error) {
+	master, err := es.eventRepo.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrEventNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get existing event: %w", err)
+	}
+
+	// Overrides resolve to the series they belong to
+	if master.ParentID != nil {
+		master, err = es.eventRepo.GetEventByID(ctx, *master.ParentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil, ErrEventNotFound
+			}
+			return nil, nil, fmt.Errorf("failed to get recurring event: %w", err)
+		}
+	}
+
+	if !master.IsRecurring() {
+		return nil, nil, ErrEventNotRecurring
+	}
+
+	rule, err := recurrence.Parse(master.RecurrenceRule)
+	if err != nil {
+		return nil, nil, ErrInvalidRecurrenceRule
+	}
+
+	if !rule.IsOccurrence(master.LocalStart(), occurrence) || master.ExDates.Contains(occurrence) {
+		return nil, nil, ErrOccurrenceNotFound
+	}
+
+	return master, rule, nil
+}
+
+// truncateSeries ends a recurring series before the given occurrence,
+// dropping the exception dates that no longer apply. The overrides of the
+// occurrences that follow move to successor, the series carrying them on
+// after a split, when it still has their occurrence; the others are
+// deleted.
+func (es *EventService) truncateSeries(ctx context.Context, master *models.Event, rule *recurrence.Rule, occurrence time.Time,
+	overrides []*models.Event, successor *models.Event) error {
+	var successorRule *recurrence.Rule
+	if successor != nil && successor.IsRecurring() {
+		successorRule, _ = recurrence.Parse(successor.RecurrenceRule)
+	}
+
+	return es.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, override := range overrides {
+			if override.RecurrenceID == nil || override.RecurrenceID.Before(occurrence) {
+				continue
+			}
+
+			if successorRule != nil && successorRule.IsOccurrence(successor.LocalStart(), *override.RecurrenceID) &&
+				!successor.ExDates.Contains(*override.RecurrenceID) {
+				previous := *override
+				override.ParentID = &successor.ID
+				if err := es.eventRepo.MoveEventOverride(ctx, override); err != nil {
+					return err
+				}
+				if err := es.publishers.publishUpdate(ctx, ChangeEventUpdated, &previous, override); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := es.deleteEvent(ctx, override); err != nil {
+				return fmt.Errorf("failed to delete event override: %w", err)
+			}
+		}
+
+		var exdates models.TimeList
+		for _, exdate := range master.ExDates {
+			if exdate.Before(occurrence) {
+				exdates = append(exdates, exdate)
+			}
+		}
+
+		previous := *master
+		master.RecurrenceRule = rule.TruncateBefore(occurrence).String()
+		master.ExDates = exdates
+		if err := es.updateEvent(ctx, &previous, master); err != nil {
+			return fmt.Errorf("failed to update event: %w", err)
+		}
+		return nil
+	})
+}
+
+// createEvent stores a new event and publishes its creation in the same
+// transaction
+func (es *EventService) createEvent(ctx context.Context, event *models.Event) (*models.Event, error) {
+	var createdEvent *models.Event
+	err := es.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		createdEvent, err = es.eventRepo.CreateEvent(ctx, event)
+		if err != nil {
+			return err
+		}
+		return es.publishers.publish(ctx, ChangeEventCreated, createdEvent)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createdEvent, nil
+}
+
+// updateEvent stores an updated event and publishes the update from
+// previous in the same transaction
+func (es *EventService) updateEvent(ctx context.Context, previous, event *models.Event) error {
+	return es.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := es.eventRepo.UpdateEvent(ctx, event); err != nil {
+			return err
+		}
+		return es.publishers.publishUpdate(ctx, ChangeEventUpdated, previous, event)
+	})
+}
+
+// deleteEvent removes an event and publishes its deletion in the same
+// transaction
+func (es *EventService) deleteEvent(ctx context.Context, event *models.Event) error {
+	return es.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := es.eventRepo.DeleteEvent(ctx, event.ID); err != nil {
+			return err
+		}
+		return es.publishers.publish(ctx, ChangeEventDeleted, event)
+	})
+}
+
 // GetEventsByDateRange retrieves events within a specific date range
 func (es *EventService) GetEventsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Event, error) {
 	if endDate.Before(startDate) {
@@ -230,10 +780,18 @@ func (es *EventService) GetEventsByDateRange(ctx context.Context, startDate, end
 		return nil, fmt.Errorf("failed to get events by date range: %w", err)
 	}
 
-	return events, nil
+	occurrences, err := es.expandRecurringEvents(ctx, startDate, endDate.Add(time.Nanosecond), func(start, end time.Time) bool {
+		return !start.Before(startDate) && !start.After(endDate)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand recurring events: %w", err)
+	}
+
+	return mergeEvents(events, occurrences), nil
 }
 
 // GetEventsByMonth retrieves all events for a specific month (calendar view)
+// in the request time zone
 func (es *EventService) GetEventsByMonth(ctx context.Context, year int, month time.Month) ([]*models.Event, error) {
 	if year < 1900 || year > 2100 {
 		return nil, errors.New("invalid year")
@@ -247,17 +805,34 @@ func (es *EventService) GetEventsByMonth(ctx context.Context, year int, month ti
 		return nil, fmt.Errorf("failed to get events by month: %w", err)
 	}
 
-	return events, nil
+	startOfMonth, endOfMonth := timezone.MonthBounds(year, month, timezone.FromContext(ctx))
+	occurrences, err := es.expandRecurringEvents(ctx, startOfMonth, endOfMonth, func(start, end time.Time) bool {
+		return es.eventsOverlap(start, end, startOfMonth, endOfMonth)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand recurring events: %w", err)
+	}
+
+	return mergeEvents(events, occurrences), nil
 }
 
-// GetEventsByDay retrieves all events for a specific day
+// GetEventsByDay retrieves all events for the calendar day of date in the
+// request time zone
 func (es *EventService) GetEventsByDay(ctx context.Context, date time.Time) ([]*models.Event, error) {
 	events, err := es.eventRepo.GetEventsByDay(ctx, date)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get events by day: %w", err)
 	}
 
-	return events, nil
+	startOfDay, endOfDay := timezone.DayBounds(date, timezone.FromContext(ctx))
+	occurrences, err := es.expandRecurringEvents(ctx, startOfDay, endOfDay, func(start, end time.Time) bool {
+		return es.eventsOverlap(start, end, startOfDay, endOfDay)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand recurring events: %w", err)
+	}
+
+	return mergeEvents(events, occurrences), nil
 }
 
 // GetUpcomingEvents retrieves upcoming events
@@ -274,15 +849,129 @@ func (es *EventService) GetUpcomingEvents(ctx context.Context, limit int) ([]*mo
 		return nil, fmt.Errorf("failed to get upcoming events: %w", err)
 	}
 
+	now := time.Now()
+	occurrences, err := es.expandRecurringEvents(ctx, now, now.Add(upcomingHorizon), func(start, end time.Time) bool {
+		return !start.Before(now)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand recurring events: %w", err)
+	}
+
+	events = mergeEvents(events, occurrences)
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
 	return events, nil
 }
 
-// CheckTimeConflicts checks if the given time range conflicts with existing events
-func (es *EventService) CheckTimeConflicts(ctx context.Context, startTime, endTime time.Time, excludeEventID *int) ([]*models.Event, error) {
+// CheckTimeConflicts checks if the given time range conflicts with existing
+// events of any calendar, including the expanded occurrences of recurring
+// series. Events belonging to the series excludeEventID (the event itself,
+// its overrides and its occurrences) are ignored. Given attendee emails,
+// only the events one of them attends without having declined are
+// conflicts; otherwise every event is.
+func (es *EventService) CheckTimeConflicts(ctx context.Context, startTime, endTime time.Time, excludeEventID *int, attendees ...string) ([]*models.Event, error) {
+	var inScope func(*models.Event) bool
+	if len(attendees) > 0 {
+		inScope = func(other *models.Event) bool {
+			return attendedByAny(other, attendees)
+		}
+	}
+	return es.findConflicts(ctx, [][2]time.Time{{startTime, endTime}}, excludeEventID, inScope)
+}
+
+// checkConflictPolicy checks an event about to be saved for time conflicts
+// under the conflict policy of its calendar, or the default policy when it
+// has none. It returns ErrTimeConflict when the policy rejects conflicts,
+// and the conflicts to warn about when it warns.
+func (es *EventService) checkConflictPolicy(ctx context.Context, event *models.Event, excludeEventID *int) ([]*models.Event, error) {
+	policy := models.DefaultConflictPolicy
+	if event.CalendarID != nil {
+		calendar, err := es.calendarRepo.GetCalendarByID(ctx, *event.CalendarID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrCalendarNotFound
+			}
+			return nil, fmt.Errorf("failed to get calendar: %w", err)
+		}
+		policy = calendar.ConflictPolicy
+	}
+	if policy == models.ConflictIgnore {
+		return nil, nil
+	}
+
+	conflicts, err := es.checkEventConflicts(ctx, event, excludeEventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check time conflicts: %w", err)
+	}
+	if len(conflicts) > 0 && policy == models.ConflictReject {
+		return nil, ErrTimeConflict
+	}
+
+	return conflicts, nil
+}
+
+// checkEventConflicts checks the event, or every occurrence of it within the
+// conflict horizon when it is a recurring series, for time conflicts. Only
+// the events of its calendar, or those its attendees attend, conflict with
+// it.
+func (es *EventService) checkEventConflicts(ctx context.Context, event *models.Event, excludeEventID *int) ([]*models.Event, error) {
+	var attendees []string
+	for _, attendee := range event.Attendees {
+		if attendee.Status != models.RSVPDeclined {
+			attendees = append(attendees, attendee.Email)
+		}
+	}
+	inScope := func(other *models.Event) bool {
+		return event.SameCalendar(other) || attendedByAny(other, attendees)
+	}
+
+	if !event.IsRecurring() {
+		return es.findConflicts(ctx, [][2]time.Time{{event.StartTime, event.EndTime}}, excludeEventID, inScope)
+	}
+
+	rule, err := recurrence.Parse(event.RecurrenceRule)
+	if err != nil {
+		return nil, ErrInvalidRecurrenceRule
+	}
+
+	var intervals [][2]time.Time
+	for _, start := range rule.Between(event.LocalStart(), event.StartTime, event.StartTime.Add(conflictHorizon)) {
+		if event.ExDates.Contains(start) {
+			continue
+		}
+		intervals = append(intervals, [2]time.Time{start, start.Add(event.Duration())})
+		if len(intervals) >= maxConflictOccurrences {
+			break
+		}
+	}
+
+	return es.findConflicts(ctx, intervals, excludeEventID, inScope)
+}
+
+// findConflicts returns the events overlapping any of the given intervals,
+// limited to the events accepted by inScope when it is not nil
+func (es *EventService) findConflicts(ctx context.Context, intervals [][2]time.Time, excludeEventID *int, inScope func(*models.Event) bool) ([]*models.Event, error) {
+	if len(intervals) == 0 {
+		return nil, nil
+	}
+
+	windowStart, windowEnd := intervals[0][0], intervals[0][1]
+	for _, interval := range intervals[1:] {
+		if interval[0].Before(windowStart) {
+			windowStart = interval[0]
+		}
+		if interval[1].After(windowEnd) {
+			windowEnd = interval[1]
+		}
+	}
+
 	// Get events that might conflict (events that overlap with the given time range)
 	filters := database.EventFilters{
-		StartBefore: &endTime,
-		EndAfter:    &startTime,
+		StartBefore:      &windowEnd,
+		EndAfter:         &windowStart,
+		ExcludeRecurring: true,
 	}
 
 	events, err := es.eventRepo.ListEvents(ctx, filters)
@@ -290,31 +979,129 @@ func (es *EventService) CheckTimeConflicts(ctx context.Context, startTime, endTi
 		return nil, fmt.Errorf("failed to get events for conflict check: %w", err)
 	}
 
+	occurrences, err := es.expandRecurringEvents(ctx, windowStart, windowEnd, func(start, end time.Time) bool {
+		return es.eventsOverlap(start, end, windowStart, windowEnd)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand recurring events for conflict check: %w", err)
+	}
+
 	var conflicts []*models.Event
-	for _, event := range events {
+	for _, event := range append(events, occurrences...) {
 		// Skip the event being updated
-		if excludeEventID != nil && event.ID == *excludeEventID {
+		if excludeEventID != nil && (event.ID == *excludeEventID || (event.ParentID != nil && *event.ParentID == *excludeEventID)) {
+			continue
+		}
+
+		// Skip the events of other calendars and attendees
+		if inScope != nil && !inScope(event) {
 			continue
 		}
 
 		// Check if events overlap
-		if es.eventsOverlap(startTime, endTime, event.StartTime, event.EndTime) {
-			conflicts = append(conflicts, event)
+		for _, interval := range intervals {
+			if es.eventsOverlap(interval[0], interval[1], event.StartTime, event.EndTime) {
+				conflicts = append(conflicts, event)
+				break
+			}
 		}
 	}
 
 	return conflicts, nil
 }
 
+// attendedByAny reports whether one of the given emails attends the event
+// without having declined it
+func attendedByAny(event *models.Event, emails []string) bool {
+	for _, attendee := range event.Attendees {
+		if attendee.Status == models.RSVPDeclined {
+			continue
+		}
+		for _, email := range emails {
+			if strings.EqualFold(attendee.Email, strings.TrimSpace(email)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandRecurringEvents expands the recurring series that may have
+// occurrences within [from, to) and returns the occurrences accepted by
+// match. Occurrences removed by an EXDATE or replaced by an override are
+// skipped; overrides are concrete rows returned by the regular queries.
+func (es *EventService) expandRecurringEvents(ctx context.Context, from, to time.Time, match func(start, end time.Time) bool) ([]*models.Event, error) {
+	masters, err := es.eventRepo.GetRecurringEvents(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var occurrences []*models.Event
+	for _, master := range masters {
+		rule, err := recurrence.Parse(master.RecurrenceRule)
+		if err != nil {
+			// Skip series with rules that can no longer be parsed rather than
+			// failing every calendar query
+			continue
+		}
+
+		overrides, err := es.eventRepo.GetEventOverrides(ctx, master.ID)
+		if err != nil {
+			return nil, err
+		}
+		overridden := make(models.TimeList, 0, len(overrides))
+		for _, override := range overrides {
+			if override.RecurrenceID != nil {
+				overridden = append(overridden, *override.RecurrenceID)
+			}
+		}
+
+		// Occurrences keep their wall-clock time in the zone of the series
+		for _, start := range rule.Between(master.LocalStart(), from.Add(-master.Duration()), to) {
+			if master.ExDates.Contains(start) || overridden.Contains(start) {
+				continue
+			}
+			occurrence := master.Occurrence(start)
+			if match(occurrence.StartTime, occurrence.EndTime) {
+				occurrences = append(occurrences, occurrence)
+			}
+		}
+	}
+
+	return occurrences, nil
+}
+
+// mergeEvents merges expanded occurrences into a list of events, keeping
+// chronological order
+func mergeEvents(events, occurrences []*models.Event) []*models.Event {
+	if len(occurrences) == 0 {
+		return events
+	}
+
+	merged := make([]*models.Event, 0, len(events)+len(occurrences))
+	merged = append(merged, events...)
+	merged = append(merged, occurrences...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].StartTime.Before(merged[j].StartTime)
+	})
+	return merged
+}
+
 // ValidateEventTimes validates event start and end times according to business rules
 func (es *EventService) ValidateEventTimes(startTime, endTime time.Time) error {
+	return validateEventTimes(startTime, endTime, true)
+}
+
+// validateEventTimes validates event times, refusing start times in the past
+// when checkPast is set
+func validateEventTimes(startTime, endTime time.Time, checkPast bool) error {
 	// Check if end time is after start time
 	if !endTime.After(startTime) {
 		return ErrInvalidTimeRange
 	}
 
 	// Check if event is not in the past (allow events starting within the last hour for flexibility)
-	if startTime.Before(time.Now().Add(-1 * time.Hour)) {
+	if checkPast && startTime.Before(time.Now().Add(-1*time.Hour)) {
 		return ErrEventInPast
 	}
 
@@ -327,8 +1114,9 @@ func (es *EventService) ValidateEventTimes(startTime, endTime time.Time) error {
 	return nil
 }
 
-// ListEvents retrieves events with filtering and pagination
-func (es *EventService) ListEvents(ctx context.Context, filters EventListFilters) ([]*models.Event, int64, error) {
+// ListEvents retrieves events with filtering and pagination. Pages are
+// selected by Cursor when set, by Page otherwise.
+func (es *EventService) ListEvents(ctx context.Context, filters EventListFilters) ([]*models.Event, PageInfo, error) {
 	// Set default pagination
 	if filters.PageSize <= 0 {
 		filters.PageSize = 20
@@ -340,6 +1128,11 @@ func (es *EventService) ListEvents(ctx context.Context, filters EventListFilters
 		filters.Page = 1
 	}
 
+	cursor, err := decodeCursor(filters.Cursor, database.EventSortStartTime, false)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
 	// Convert to repository filters
 	repoFilters := database.EventFilters{
 		Title:       filters.Title,
@@ -348,27 +1141,84 @@ func (es *EventService) ListEvents(ctx context.Context, filters EventListFilters
 		EndAfter:    filters.EndAfter,
 		EndBefore:   filters.EndBefore,
 		Search:      filters.Search,
+		CalendarID:  filters.CalendarID,
 		Limit:       filters.PageSize,
 		Offset:      (filters.Page - 1) * filters.PageSize,
+		Cursor:      cursor,
 	}
 
-	// Get events and total count
-	events, err := es.eventRepo.ListEvents(ctx, repoFilters)
+	// Get the page of events and the total count
+	events, cursors, err := es.eventRepo.ListEventsPage(ctx, repoFilters)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list events: %w", err)
+		return nil, PageInfo{}, fmt.Errorf("failed to list events: %w", err)
 	}
 
-	total, err := es.eventRepo.CountEvents(ctx, repoFilters)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count events: %w", err)
+	var total int64
+	if !filters.SkipTotal {
+		total, err = es.eventRepo.CountEvents(ctx, repoFilters)
+		if err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to count events: %w", err)
+		}
+	}
+
+	return events, newPageInfo(total, cursors), nil
+}
+
+// applyEventUpdate applies the fields set in the update request to the event
+// and validates the resulting times. Events that already started, such as a
+// recurring series, can be edited as long as their start time stays put.
+func (es *EventService) applyEventUpdate(event *models.Event, req UpdateEventRequest) error {
+	startTime := event.StartTime
+	if req.Title != nil {
+		event.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		event.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.StartTime != nil {
+		event.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		event.EndTime = *req.EndTime
+	}
+	if req.TimeZone != nil {
+		event.TimeZone = strings.TrimSpace(*req.TimeZone)
+	}
+	if req.CalendarID != nil {
+		var calendarID *int
+		if *req.CalendarID != 0 {
+			calendarID = req.CalendarID
+		}
+		// Overrides stay in the calendar of their series
+		moved := &models.Event{CalendarID: calendarID}
+		if !event.SameCalendar(moved) {
+			if event.ParentID != nil {
+				return ErrOverrideCalendar
+			}
+			event.CalendarID = calendarID
+		}
+	}
+	if req.RecurrenceRule != nil {
+		// Overrides replace a single occurrence and cannot recur themselves
+		if event.ParentID != nil && strings.TrimSpace(*req.RecurrenceRule) != "" {
+			return ErrInvalidRecurrenceRule
+		}
+		event.RecurrenceRule = strings.TrimSpace(*req.RecurrenceRule)
 	}
 
-	return events, total, nil
+	// Validate updated times
+	return validateEventTimes(event.StartTime, event.EndTime, !event.StartTime.Equal(startTime))
 }
 
 // eventsOverlap checks if two time ranges overlap
 func (es *EventService) eventsOverlap(start1, end1, start2, end2 time.Time) bool {
-	// Events overlap if one starts before the other ends and vice versa
+	return rangesOverlap(start1, end1, start2, end2)
+}
+
+// rangesOverlap checks if two time ranges overlap. Ranges overlap if one
+// starts before the other ends and vice versa, so back-to-back ranges do
+// not.
+func rangesOverlap(start1, end1, start2, end2 time.Time) bool {
 	return start1.Before(end2) && start2.Before(end1)
 }
 
@@ -387,6 +1237,18 @@ func (es *EventService) validateCreateEventRequest(req CreateEventRequest) error
 		return ErrEventDescriptionTooLong
 	}
 
+	// Recurrence rule validation
+	if strings.TrimSpace(req.RecurrenceRule) != "" {
+		if _, err := recurrence.Parse(req.RecurrenceRule); err != nil {
+			return ErrInvalidRecurrenceRule
+		}
+	}
+
+	// Time zone validation
+	if _, err := timezone.Load(req.TimeZone); err != nil {
+		return ErrInvalidEventTimeZone
+	}
+
 	return nil
 }
 
@@ -407,5 +1269,19 @@ func (es *EventService) validateUpdateEventRequest(req UpdateEventRequest) error
 		return ErrEventDescriptionTooLong
 	}
 
+	// Recurrence rule validation
+	if req.RecurrenceRule != nil && strings.TrimSpace(*req.RecurrenceRule) != "" {
+		if _, err := recurrence.Parse(*req.RecurrenceRule); err != nil {
+			return ErrInvalidRecurrenceRule
+		}
+	}
+
+	// Time zone validation
+	if req.TimeZone != nil {
+		if _, err := timezone.Load(*req.TimeZone); err != nil {
+			return ErrInvalidEventTimeZone
+		}
+	}
+
 	return nil
 }
\ No newline at end of file
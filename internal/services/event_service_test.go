@@ -3,14 +3,19 @@ package services
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"agenda/internal/database"
 	"agenda/internal/models"
+	"agenda/internal/recurrence"
+	"agenda/internal/timezone"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockEventRepository is a mock implementation of EventRepositoryInterface
@@ -52,6 +57,11 @@ func (m *MockEventRepository) ListEvents(ctx context.Context, filters database.E
 	return args.Get(0).([]*models.Event), args.Error(1)
 }
 
+func (m *MockEventRepository) ListEventsPage(ctx context.Context, filters database.EventFilters) ([]*models.Event, database.PageCursors, error) {
+	events, err := m.ListEvents(ctx, filters)
+	return events, database.PageCursors{}, err
+}
+
 func (m *MockEventRepository) CountEvents(ctx context.Context, filters database.EventFilters) (int64, error) {
 	args := m.Called(ctx, filters)
 	return args.Get(0).(int64), args.Error(1)
@@ -97,6 +107,53 @@ func (m *MockEventRepository) GetEventsByTitle(ctx context.Context, title string
 	return args.Get(0).([]*models.Event), args.Error(1)
 }
 
+func (m *MockEventRepository) GetRecurringEvents(ctx context.Context, startsBefore time.Time) ([]*models.Event, error) {
+	args := m.Called(ctx, startsBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Event), args.Error(1)
+}
+
+func (m *MockEventRepository) GetEventOverrides(ctx context.Context, parentID int) ([]*models.Event, error) {
+	args := m.Called(ctx, parentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Event), args.Error(1)
+}
+
+func (m *MockEventRepository) MoveEventOverride(ctx context.Context, override *models.Event) error {
+	args := m.Called(ctx, override)
+	return args.Error(0)
+}
+
+func (m *MockEventRepository) GetEventByUID(ctx context.Context, uid string) (*models.Event, error) {
+	args := m.Called(ctx, uid)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Event), args.Error(1)
+}
+
+func (m *MockEventRepository) ListDeletedEvents(ctx context.Context) ([]*models.Event, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Event), args.Error(1)
+}
+
+func (m *MockEventRepository) RestoreEvent(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockEventRepository) PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // BaseRepository methods (not used in tests but required for interface)
 func (m *MockEventRepository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
 	return 0, nil
@@ -137,7 +194,9 @@ func (m *MockEventRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
 // Test helper functions
 func createTestEventService() (*EventService, *MockEventRepository) {
 	mockRepo := &MockEventRepository{}
-	service := NewEventService(mockRepo).(*EventService)
+	// Most tests don't involve recurring series
+	mockRepo.On("GetRecurringEvents", mock.Anything, mock.Anything).Return([]*models.Event{}, nil).Maybe()
+	service := NewEventService(mockRepo, NewMockCalendarRepository(), MockTransactor{}).(*EventService)
 	return service, mockRepo
 }
 
@@ -381,6 +440,38 @@ func TestEventService_UpdateEvent_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestEventService_UpdateEvent_VersionMismatch(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	existingEvent := createTestEvent()
+	existingEvent.Version = 3
+	mockRepo.On("GetEventByID", ctx, 1).Return(existingEvent, nil)
+
+	stale := 2
+	_, err := service.UpdateEvent(ctx, 1, UpdateEventRequest{Title: stringPtr("Stale"), Version: &stale})
+
+	assert.Equal(t, ErrVersionMismatch, err)
+	mockRepo.AssertNotCalled(t, "UpdateEvent", mock.Anything, mock.Anything)
+}
+
+func TestEventService_UpdateEvent_ConcurrentUpdate(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	existingEvent := createTestEvent()
+	existingEvent.Version = 3
+	mockRepo.On("GetEventByID", ctx, 1).Return(existingEvent, nil)
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{}, nil)
+	mockRepo.On("UpdateEvent", ctx, mock.AnythingOfType("*models.Event")).
+		Return(fmt.Errorf("failed to update event: %w", database.ErrVersionConflict))
+
+	current := 3
+	_, err := service.UpdateEvent(ctx, 1, UpdateEventRequest{Title: stringPtr("Racing"), Version: &current})
+
+	assert.Equal(t, ErrVersionMismatch, err)
+}
+
 func TestEventService_UpdateEvent_TimeConflict(t *testing.T) {
 	service, mockRepo := createTestEventService()
 	ctx := context.Background()
@@ -438,6 +529,37 @@ func TestEventService_DeleteEvent_NotFound(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// Test RestoreEvent
+func TestEventService_RestoreEvent_Success(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	restoredEvent := createTestEvent()
+	mockRepo.On("RestoreEvent", ctx, 1).Return(nil)
+	mockRepo.On("GetEventByID", ctx, 1).Return(restoredEvent, nil)
+
+	event, err := service.RestoreEvent(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, restoredEvent, event)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_RestoreEvent_Errors(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	mockRepo.On("RestoreEvent", ctx, 1).Return(database.ErrNotDeleted)
+	mockRepo.On("RestoreEvent", ctx, 999).Return(sql.ErrNoRows)
+
+	_, err := service.RestoreEvent(ctx, 1)
+	assert.Equal(t, ErrEventNotDeleted, err)
+
+	_, err = service.RestoreEvent(ctx, 999)
+	assert.Equal(t, ErrEventNotFound, err)
+	mockRepo.AssertExpectations(t)
+}
+
 // Test GetEventsByDateRange
 func TestEventService_GetEventsByDateRange_Success(t *testing.T) {
 	service, mockRepo := createTestEventService()
@@ -633,6 +755,144 @@ func TestEventService_CheckTimeConflicts_ExcludeEvent(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestEventService_CheckTimeConflicts_ForAttendee(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	now := time.Now()
+	startTime := now.Add(1 * time.Hour)
+	endTime := now.Add(2 * time.Hour)
+
+	attended := &models.Event{
+		ID:        1,
+		StartTime: now.Add(30 * time.Minute),
+		EndTime:   now.Add(90 * time.Minute),
+		Attendees: []models.Attendee{{Email: "ada@example.com", Status: models.RSVPAccepted}},
+	}
+	declined := &models.Event{
+		ID:        2,
+		StartTime: now.Add(90 * time.Minute),
+		EndTime:   now.Add(150 * time.Minute),
+		Attendees: []models.Attendee{{Email: "ada@example.com", Status: models.RSVPDeclined}},
+	}
+	unrelated := &models.Event{
+		ID:        3,
+		StartTime: now.Add(45 * time.Minute),
+		EndTime:   now.Add(75 * time.Minute),
+		Attendees: []models.Attendee{{Email: "grace@example.com", Status: models.RSVPNeedsAction}},
+	}
+
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).
+		Return([]*models.Event{attended, declined, unrelated}, nil)
+
+	conflicts, err := service.CheckTimeConflicts(ctx, startTime, endTime, nil, "Ada@Example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Event{attended}, conflicts)
+
+	conflicts, err = service.CheckTimeConflicts(ctx, startTime, endTime, nil)
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 3, "without attendees every event conflicts")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_CreateEvent_CalendarConflictPolicies(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	calendarRepo := service.calendarRepo.(*MockCalendarRepository)
+	ctx := context.Background()
+
+	newCalendar := func(name, policy string) *int {
+		calendar, err := calendarRepo.CreateCalendar(ctx, &models.Calendar{Name: name, ConflictPolicy: policy})
+		require.NoError(t, err)
+		return &calendar.ID
+	}
+	room := newCalendar("Room 1", models.ConflictReject)
+	team := newCalendar("Team", models.ConflictWarn)
+	holidays := newCalendar("Holidays", models.ConflictIgnore)
+	other := newCalendar("Other team", models.ConflictReject)
+
+	now := time.Now()
+	booking := &models.Event{ID: 1, Title: "Booking", CalendarID: room, StartTime: now.Add(30 * time.Minute), EndTime: now.Add(90 * time.Minute)}
+	standup := &models.Event{ID: 2, Title: "Standup", CalendarID: team, StartTime: now.Add(time.Hour), EndTime: now.Add(75 * time.Minute)}
+	personal := &models.Event{ID: 3, Title: "Dentist", StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)}
+
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{booking, standup, personal}, nil)
+	mockRepo.On("CreateEvent", ctx, mock.AnythingOfType("*models.Event")).Return(&models.Event{ID: 10}, nil)
+
+	create := func(calendarID *int) (*models.Event, error) {
+		return service.CreateEvent(ctx, CreateEventRequest{
+			Title:      "Meeting",
+			StartTime:  now.Add(time.Hour),
+			EndTime:    now.Add(2 * time.Hour),
+			CalendarID: calendarID,
+		})
+	}
+
+	_, err := create(room)
+	assert.Equal(t, ErrTimeConflict, err, "reject calendars refuse conflicts")
+
+	_, err = create(nil)
+	assert.Equal(t, ErrTimeConflict, err, "events without a calendar conflict with each other")
+
+	created, err := create(team)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Event{standup}, created.Conflicts, "warn calendars return the conflicts of their own calendar")
+
+	created, err = create(holidays)
+	require.NoError(t, err)
+	assert.Empty(t, created.Conflicts)
+
+	created, err = create(other)
+	require.NoError(t, err, "events of other calendars do not conflict")
+	assert.Empty(t, created.Conflicts)
+
+	missing := 99
+	_, err = create(&missing)
+	assert.Equal(t, ErrCalendarNotFound, err)
+}
+
+func TestEventService_UpdateEvent_CalendarScopedConflicts(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	calendarRepo := service.calendarRepo.(*MockCalendarRepository)
+	ctx := context.Background()
+
+	personal, err := calendarRepo.CreateCalendar(ctx, &models.Calendar{Name: "Personal", ConflictPolicy: models.ConflictReject})
+	require.NoError(t, err)
+	team, err := calendarRepo.CreateCalendar(ctx, &models.Calendar{Name: "Team", ConflictPolicy: models.ConflictReject})
+	require.NoError(t, err)
+
+	now := time.Now()
+	event := &models.Event{
+		ID:         1,
+		Title:      "Review",
+		CalendarID: &personal.ID,
+		StartTime:  now.Add(3 * time.Hour),
+		EndTime:    now.Add(4 * time.Hour),
+		Attendees:  []models.Attendee{{Email: "ada@example.com", Status: models.RSVPAccepted}},
+	}
+	planning := &models.Event{
+		ID:         2,
+		Title:      "Planning",
+		CalendarID: &team.ID,
+		StartTime:  now.Add(time.Hour),
+		EndTime:    now.Add(2 * time.Hour),
+		Attendees:  []models.Attendee{{Email: "ada@example.com", Status: models.RSVPTentative}},
+	}
+	seriesID := 5
+	override := &models.Event{ID: 6, Title: "Standup", ParentID: &seriesID, CalendarID: &team.ID,
+		StartTime: now.Add(5 * time.Hour), EndTime: now.Add(6 * time.Hour)}
+
+	mockRepo.On("GetEventByID", ctx, 1).Return(event, nil)
+	mockRepo.On("GetEventByID", ctx, 6).Return(override, nil)
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{planning}, nil)
+
+	start, end := now.Add(time.Hour), now.Add(2*time.Hour)
+	_, err = service.UpdateEvent(ctx, 1, UpdateEventRequest{StartTime: &start, EndTime: &end})
+	assert.Equal(t, ErrTimeConflict, err, "events of other calendars conflict through their attendees")
+
+	_, err = service.UpdateEvent(ctx, 6, UpdateEventRequest{CalendarID: &personal.ID})
+	assert.Equal(t, ErrOverrideCalendar, err)
+}
+
 // Test ValidateEventTimes
 func TestEventService_ValidateEventTimes_Success(t *testing.T) {
 	service, _ := createTestEventService()
@@ -701,11 +961,11 @@ func TestEventService_ListEvents_Success(t *testing.T) {
 	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return(expectedEvents, nil)
 	mockRepo.On("CountEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return(expectedTotal, nil)
 
-	events, total, err := service.ListEvents(ctx, filters)
+	events, page, err := service.ListEvents(ctx, filters)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedEvents, events)
-	assert.Equal(t, expectedTotal, total)
+	assert.Equal(t, expectedTotal, page.Total)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -723,15 +983,377 @@ func TestEventService_ListEvents_DefaultPagination(t *testing.T) {
 	})).Return(expectedEvents, nil)
 	mockRepo.On("CountEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return(expectedTotal, nil)
 
-	events, total, err := service.ListEvents(ctx, filters)
+	events, page, err := service.ListEvents(ctx, filters)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedEvents, events)
-	assert.Equal(t, expectedTotal, total)
+	assert.Equal(t, expectedTotal, page.Total)
 	mockRepo.AssertExpectations(t)
 }
 
 // Helper function
 func stringPtr(s string) *string {
 	return &s
-}
\ No newline at end of file
+}
+// Test recurring events
+func createTestRecurringEvent(start time.Time) *models.Event {
+	return &models.Event{
+		ID:             1,
+		Title:          "Standup",
+		StartTime:      start,
+		EndTime:        start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=DAILY;COUNT=5",
+	}
+}
+
+func TestEventService_GetEventsByMonth_ExpandsRecurring(t *testing.T) {
+	mockRepo := &MockEventRepository{}
+	service := NewEventService(mockRepo, NewMockCalendarRepository(), MockTransactor{}).(*EventService)
+	ctx := context.Background()
+
+	start := time.Date(2030, time.March, 4, 9, 0, 0, 0, time.UTC)
+	master := createTestRecurringEvent(start)
+	master.ExDates = models.TimeList{start.AddDate(0, 0, 2)}
+
+	overrideStart := start.AddDate(0, 0, 1)
+	override := &models.Event{
+		ID:           2,
+		Title:        "Standup (moved)",
+		StartTime:    overrideStart.Add(time.Hour),
+		EndTime:      overrideStart.Add(75 * time.Minute),
+		ParentID:     &master.ID,
+		RecurrenceID: &overrideStart,
+	}
+
+	mockRepo.On("GetEventsByMonth", ctx, 2030, time.March).Return([]*models.Event{override}, nil)
+	mockRepo.On("GetRecurringEvents", ctx, mock.AnythingOfType("time.Time")).Return([]*models.Event{master}, nil)
+	mockRepo.On("GetEventOverrides", ctx, 1).Return([]*models.Event{override}, nil)
+
+	result, err := service.GetEventsByMonth(ctx, 2030, time.March)
+
+	require.NoError(t, err)
+	// Five occurrences, minus the excluded one, with the override replacing the second
+	require.Len(t, result, 4)
+	assert.Equal(t, start, result[0].StartTime)
+	assert.Equal(t, 2, result[1].ID)
+	assert.Equal(t, start.AddDate(0, 0, 3), result[2].StartTime)
+	assert.Equal(t, start.AddDate(0, 0, 4), result[3].StartTime)
+	assert.Equal(t, 1, result[3].ID)
+	require.NotNil(t, result[3].RecurrenceID)
+	assert.Equal(t, start.AddDate(0, 0, 4), *result[3].RecurrenceID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_GetEventsByDay_TimeZone(t *testing.T) {
+	mockRepo := &MockEventRepository{}
+	service := NewEventService(mockRepo, NewMockCalendarRepository(), MockTransactor{}).(*EventService)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	ctx := timezone.WithLocation(context.Background(), newYork)
+
+	// A weekly 9:00 meeting scheduled in New York before clocks move
+	// forward on March 10, stored in UTC
+	start := time.Date(2030, time.March, 4, 9, 0, 0, 0, newYork)
+	master := &models.Event{
+		ID:             1,
+		Title:          "Planning",
+		StartTime:      start.UTC(),
+		EndTime:        start.Add(time.Hour).UTC(),
+		TimeZone:       "America/New_York",
+		RecurrenceRule: "FREQ=WEEKLY;COUNT=4",
+	}
+
+	day := time.Date(2030, time.March, 11, 0, 0, 0, 0, newYork)
+	mockRepo.On("GetEventsByDay", ctx, day).Return([]*models.Event{}, nil)
+	mockRepo.On("GetRecurringEvents", ctx, mock.AnythingOfType("time.Time")).Return([]*models.Event{master}, nil)
+	mockRepo.On("GetEventOverrides", ctx, 1).Return([]*models.Event{}, nil)
+
+	result, err := service.GetEventsByDay(ctx, day)
+
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	// Still 9:00 in New York, an hour earlier in UTC
+	assert.True(t, time.Date(2030, time.March, 11, 9, 0, 0, 0, newYork).Equal(result[0].StartTime))
+	assert.True(t, time.Date(2030, time.March, 11, 13, 0, 0, 0, time.UTC).Equal(result[0].StartTime))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_CreateEvent_TimeZone(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	ctx := timezone.WithLocation(context.Background(), paris)
+
+	start := time.Now().Add(time.Hour)
+	tests := []struct {
+		name     string
+		timeZone string
+		expected string
+	}{
+		{"defaults to the request time zone", "", "Europe/Paris"},
+		{"explicit time zone", "Asia/Tokyo", "Asia/Tokyo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo := createTestEventService()
+			mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{}, nil)
+			mockRepo.On("CreateEvent", ctx, mock.MatchedBy(func(event *models.Event) bool {
+				return event.TimeZone == tt.expected
+			})).Return(&models.Event{ID: 1}, nil)
+
+			_, err := service.CreateEvent(ctx, CreateEventRequest{
+				Title:     "Meeting",
+				StartTime: start,
+				EndTime:   start.Add(time.Hour),
+				TimeZone:  tt.timeZone,
+			})
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("invalid time zone", func(t *testing.T) {
+		service, _ := createTestEventService()
+
+		_, err := service.CreateEvent(ctx, CreateEventRequest{
+			Title:     "Meeting",
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+			TimeZone:  "Europe/Atlantis",
+		})
+
+		assert.Equal(t, ErrInvalidEventTimeZone, err)
+	})
+}
+
+func TestEventService_CreateEvent_InvalidRecurrenceRule(t *testing.T) {
+	service, _ := createTestEventService()
+	ctx := context.Background()
+
+	start := time.Now().Add(time.Hour)
+	req := CreateEventRequest{
+		Title:          "Standup",
+		StartTime:      start,
+		EndTime:        start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=HOURLY",
+	}
+
+	_, err := service.CreateEvent(ctx, req)
+
+	assert.Equal(t, ErrInvalidRecurrenceRule, err)
+}
+
+func TestEventService_CreateEvent_RecurringTimeConflict(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	start := time.Now().Add(time.Hour).Truncate(time.Minute)
+	req := CreateEventRequest{
+		Title:          "Standup",
+		StartTime:      start,
+		EndTime:        start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=WEEKLY;COUNT=4",
+	}
+
+	// Conflicts with the third occurrence only
+	conflictingEvent := &models.Event{
+		ID:        2,
+		Title:     "Conflicting Event",
+		StartTime: start.AddDate(0, 0, 14).Add(5 * time.Minute),
+		EndTime:   start.AddDate(0, 0, 14).Add(time.Hour),
+	}
+
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{conflictingEvent}, nil)
+
+	_, err := service.CreateEvent(ctx, req)
+
+	assert.Equal(t, ErrTimeConflict, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_UpdateEventOccurrence_This(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	start := time.Now().Add(time.Hour).Truncate(time.Minute)
+	master := createTestRecurringEvent(start)
+	occurrence := start.AddDate(0, 0, 2)
+
+	mockRepo.On("GetEventByID", ctx, 1).Return(master, nil)
+	mockRepo.On("GetEventOverrides", ctx, 1).Return([]*models.Event{}, nil)
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{}, nil)
+	mockRepo.On("CreateEvent", ctx, mock.MatchedBy(func(event *models.Event) bool {
+		return event.ID == 0 && event.Title == "Moved standup" && event.RecurrenceRule == "" &&
+			event.ParentID != nil && *event.ParentID == 1 &&
+			event.RecurrenceID != nil && event.RecurrenceID.Equal(occurrence)
+	})).Return(&models.Event{ID: 2, Title: "Moved standup"}, nil)
+
+	result, err := service.UpdateEventOccurrence(ctx, 1, occurrence, ScopeThisOccurrence, UpdateEventRequest{
+		Title: stringPtr("Moved standup"),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_UpdateEventOccurrence_Following(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	start := time.Now().Add(time.Hour).Truncate(time.Minute)
+	master := createTestRecurringEvent(start)
+	occurrence := start.AddDate(0, 0, 2)
+	master.ExDates = models.TimeList{start.AddDate(0, 0, 1), start.AddDate(0, 0, 3)}
+
+	mockRepo.On("GetEventByID", ctx, 1).Return(master, nil)
+	mockRepo.On("GetEventOverrides", ctx, 1).Return([]*models.Event{}, nil)
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{}, nil)
+	mockRepo.On("UpdateEvent", ctx, mock.MatchedBy(func(event *models.Event) bool {
+		return event.ID == 1 && strings.Contains(event.RecurrenceRule, "UNTIL=") &&
+			len(event.ExDates) == 1 && event.ExDates[0].Equal(start.AddDate(0, 0, 1))
+	})).Return(nil)
+	mockRepo.On("CreateEvent", ctx, mock.MatchedBy(func(event *models.Event) bool {
+		return event.ID == 0 && event.ParentID == nil && event.RecurrenceID == nil &&
+			event.StartTime.Equal(occurrence) && event.RecurrenceRule == "FREQ=DAILY;COUNT=3" &&
+			len(event.ExDates) == 1 && event.ExDates[0].Equal(start.AddDate(0, 0, 3))
+	})).Return(&models.Event{ID: 2, Title: "Renamed standup"}, nil)
+
+	result, err := service.UpdateEventOccurrence(ctx, 1, occurrence, ScopeThisAndFollowing, UpdateEventRequest{
+		Title: stringPtr("Renamed standup"),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_UpdateEventOccurrence_StartedSeries(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	// The first occurrence is long gone, the series still runs
+	start := time.Now().AddDate(0, 0, -2).Truncate(time.Minute)
+	master := createTestRecurringEvent(start)
+
+	mockRepo.On("GetEventByID", ctx, 1).Return(master, nil)
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{}, nil)
+	mockRepo.On("UpdateEvent", ctx, mock.MatchedBy(func(event *models.Event) bool {
+		return event.ID == 1 && event.Title == "Renamed standup" && event.StartTime.Equal(start)
+	})).Return(nil)
+
+	result, err := service.UpdateEventOccurrence(ctx, 1, start.AddDate(0, 0, 3), ScopeAllOccurrences, UpdateEventRequest{
+		Title:     stringPtr("Renamed standup"),
+		StartTime: &start,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed standup", result.Title)
+
+	// Moving the series to another past start is still refused
+	earlier := start.Add(-time.Hour)
+	_, err = service.UpdateEvent(ctx, 1, UpdateEventRequest{StartTime: &earlier})
+	assert.Equal(t, ErrEventInPast, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_DeleteEventOccurrence_This(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	start := time.Now().Add(time.Hour).Truncate(time.Minute)
+	master := createTestRecurringEvent(start)
+	occurrence := start.AddDate(0, 0, 1)
+	override := &models.Event{ID: 2, ParentID: &master.ID, RecurrenceID: &occurrence}
+
+	mockRepo.On("GetEventByID", ctx, 1).Return(master, nil)
+	mockRepo.On("GetEventOverrides", ctx, 1).Return([]*models.Event{override}, nil)
+	mockRepo.On("DeleteEvent", ctx, 2).Return(nil)
+	mockRepo.On("UpdateEvent", ctx, mock.MatchedBy(func(event *models.Event) bool {
+		return event.ID == 1 && event.ExDates.Contains(occurrence)
+	})).Return(nil)
+
+	err := service.DeleteEventOccurrence(ctx, 1, occurrence, ScopeThisOccurrence)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_DeleteEventOccurrence_Following(t *testing.T) {
+	service, mockRepo := createTestEventService()
+	ctx := context.Background()
+
+	start := time.Now().Add(time.Hour).Truncate(time.Minute)
+	master := createTestRecurringEvent(start)
+	occurrence := start.AddDate(0, 0, 3)
+
+	mockRepo.On("GetEventByID", ctx, 1).Return(master, nil)
+	mockRepo.On("GetEventOverrides", ctx, 1).Return([]*models.Event{}, nil)
+	mockRepo.On("UpdateEvent", ctx, mock.AnythingOfType("*models.Event")).Return(nil)
+
+	err := service.DeleteEventOccurrence(ctx, 1, occurrence, ScopeThisAndFollowing)
+
+	require.NoError(t, err)
+	rule, err := recurrence.Parse(master.RecurrenceRule)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2)}, rule.First(master.StartTime, 10))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventService_EventOccurrence_Errors(t *testing.T) {
+	start := time.Now().Add(time.Hour).Truncate(time.Minute)
+
+	tests := []struct {
+		name        string
+		event       *models.Event
+		occurrence  time.Time
+		scope       RecurrenceScope
+		expectedErr error
+	}{
+		{
+			name:        "invalid scope",
+			event:       createTestRecurringEvent(start),
+			occurrence:  start,
+			scope:       RecurrenceScope("some"),
+			expectedErr: ErrInvalidRecurrenceScope,
+		},
+		{
+			name:        "event not recurring",
+			event:       &models.Event{ID: 1, StartTime: start, EndTime: start.Add(time.Hour)},
+			occurrence:  start,
+			scope:       ScopeThisOccurrence,
+			expectedErr: ErrEventNotRecurring,
+		},
+		{
+			name:        "time is not an occurrence",
+			event:       createTestRecurringEvent(start),
+			occurrence:  start.Add(time.Hour),
+			scope:       ScopeThisOccurrence,
+			expectedErr: ErrOccurrenceNotFound,
+		},
+		{
+			name:        "occurrence after count",
+			event:       createTestRecurringEvent(start),
+			occurrence:  start.AddDate(0, 0, 5),
+			scope:       ScopeAllOccurrences,
+			expectedErr: ErrOccurrenceNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo := createTestEventService()
+			ctx := context.Background()
+
+			mockRepo.On("GetEventByID", ctx, 1).Return(tt.event, nil).Maybe()
+
+			err := service.DeleteEventOccurrence(ctx, 1, tt.occurrence, tt.scope)
+			assert.Equal(t, tt.expectedErr, err)
+
+			_, err = service.UpdateEventOccurrence(ctx, 1, tt.occurrence, tt.scope, UpdateEventRequest{})
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,734 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"agenda/internal/database"
+	"agenda/internal/ical"
+	"agenda/internal/models"
+	"agenda/internal/recurrence"
+)
+
+// ICalServiceInterface defines the contract for iCalendar import and export
+type ICalServiceInterface interface {
+	ExportCalendar(ctx context.Context, startDate, endDate *time.Time) ([]byte, error)
+	ImportCalendar(ctx context.Context, r io.Reader) (*ImportResult, error)
+}
+
+// ICalService implements ICalServiceInterface
+type ICalService struct {
+	taskRepo   database.TaskRepositoryInterface
+	eventRepo  database.EventRepositoryInterface
+	transactor database.Transactor
+	// Imports are saved through the task and event services, so they are
+	// checked and published like any other change
+	tasks  *TaskService
+	events *EventService
+}
+
+// NewICalService creates a new iCalendar service instance. Imported tasks
+// and events are published to publishers in the transaction of the import.
+func NewICalService(taskRepo database.TaskRepositoryInterface, eventRepo database.EventRepositoryInterface,
+	calendarRepo database.CalendarRepositoryInterface, transactor database.Transactor, publishers ...ChangePublisher) ICalServiceInterface {
+	return &ICalService{
+		taskRepo:   taskRepo,
+		eventRepo:  eventRepo,
+		transactor: transactor,
+		tasks:      NewTaskService(taskRepo, transactor, publishers...).(*TaskService),
+		events:     NewEventService(eventRepo, calendarRepo, transactor, publishers...).(*EventService),
+	}
+}
+
+// ImportResult summarizes the outcome of an iCalendar import
+type ImportResult struct {
+	EventsCreated int          `json:"events_created"`
+	EventsUpdated int          `json:"events_updated"`
+	TasksCreated  int          `json:"tasks_created"`
+	TasksUpdated  int          `json:"tasks_updated"`
+	Skipped       []ImportSkip `json:"skipped"`
+}
+
+// ImportSkip describes a component that could not be imported
+type ImportSkip struct {
+	Component string `json:"component"`
+	UID       string `json:"uid,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// ICalProdID identifies agenda as the producer of exported calendars
+const ICalProdID = "-//agenda//agenda//EN"
+
+// iCalendar errors
+var (
+	ErrInvalidCalendar = errors.New("invalid iCalendar data")
+)
+
+// Reasons for skipping imported components
+var (
+	errMissingSummary      = errors.New("missing SUMMARY")
+	errMissingStart        = errors.New("missing or invalid DTSTART")
+	errInvalidEnd          = errors.New("missing or invalid DTEND or DURATION")
+	errUnsupportedRule     = errors.New("unsupported RRULE")
+	errOrphanOverride      = errors.New("RECURRENCE-ID does not match an imported recurring event")
+	errCancelled           = errors.New("cancelled")
+	errInvalidExDate       = errors.New("invalid EXDATE")
+	errInvalidDue          = errors.New("invalid DUE")
+	errInvalidRecurrenceID = errors.New("invalid RECURRENCE-ID")
+)
+
+// ExportCalendar renders events as VEVENTs and tasks as VTODOs. When a range
+// is given, only events overlapping it, recurring events with occurrences in
+// it and tasks due within it are exported.
+func (is *ICalService) ExportCalendar(ctx context.Context, startDate, endDate *time.Time) ([]byte, error) {
+	if startDate != nil && endDate != nil && endDate.Before(*startDate) {
+		return nil, ErrInvalidDateRange
+	}
+
+	events, err := is.exportedEvents(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := is.taskRepo.ListTasks(ctx, database.TaskFilters{
+		DueAfter:  startDate,
+		DueBefore: endDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks for export: %w", err)
+	}
+
+	now := time.Now()
+	cal := ical.NewCalendar(ICalProdID)
+
+	// Overrides are exported with the UID of their series
+	seriesUIDs := make(map[int]string)
+	for _, event := range events {
+		if event.IsRecurring() {
+			seriesUIDs[event.ID] = eventUID(event)
+		}
+	}
+
+	// Every time zone referenced by a TZID parameter is defined, before
+	// the events using it
+	for _, vtimezone := range timeZoneComponents(events, now) {
+		cal.AddComponent(vtimezone)
+	}
+
+	for _, event := range events {
+		uid := eventUID(event)
+		if event.ParentID != nil {
+			parentUID, ok := seriesUIDs[*event.ParentID]
+			if !ok {
+				parent, err := is.eventRepo.GetEventByID(ctx, *event.ParentID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get recurring event for export: %w", err)
+				}
+				parentUID = eventUID(parent)
+				seriesUIDs[parent.ID] = parentUID
+			}
+			uid = parentUID
+		}
+		cal.AddComponent(eventComponent(event, uid, now))
+	}
+
+	for _, task := range tasks {
+		cal.AddComponent(taskComponent(task, now))
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		return nil, fmt.Errorf("failed to encode calendar: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// exportedEvents returns the event rows to export for the optional range
+func (is *ICalService) exportedEvents(ctx context.Context, startDate, endDate *time.Time) ([]*models.Event, error) {
+	if startDate == nil && endDate == nil {
+		events, err := is.eventRepo.ListEvents(ctx, database.EventFilters{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get events for export: %w", err)
+		}
+		return events, nil
+	}
+
+	// Single events and overrides overlapping the range
+	events, err := is.eventRepo.ListEvents(ctx, database.EventFilters{
+		StartBefore:      endDate,
+		EndAfter:         startDate,
+		ExcludeRecurring: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events for export: %w", err)
+	}
+
+	// Recurring series with occurrences in the range
+	startsBefore := time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if endDate != nil {
+		startsBefore = endDate.Add(time.Nanosecond)
+	}
+	masters, err := is.eventRepo.GetRecurringEvents(ctx, startsBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring events for export: %w", err)
+	}
+	for _, master := range masters {
+		if startDate != nil {
+			rule, err := recurrence.Parse(master.RecurrenceRule)
+			if err != nil {
+				continue
+			}
+			if _, ok := rule.After(master.LocalStart(), startDate.Add(-master.Duration())); !ok {
+				continue
+			}
+		}
+		events = append(events, master)
+	}
+
+	return events, nil
+}
+
+// eventUID returns the UID of an event, falling back to one derived from its
+// ID for rows created before UIDs were assigned
+func eventUID(event *models.Event) string {
+	if event.UID != "" {
+		return event.UID
+	}
+	return fmt.Sprintf("event-%d@agenda", event.ID)
+}
+
+// recurringTimeZoneYears is how many years past the export the time zone
+// transitions of recurring events are defined, as their occurrences go on
+const recurringTimeZoneYears = 10
+
+// timeZoneComponents returns the VTIMEZONEs of the time zones of events,
+// sorted by TZID, each covering the events in its time zone
+func timeZoneComponents(events []*models.Event, now time.Time) []*ical.Component {
+	type zoneRange struct {
+		loc      *time.Location
+		from, to time.Time
+	}
+
+	zones := make(map[string]*zoneRange)
+	for _, event := range events {
+		loc := event.Location()
+		if loc == time.UTC {
+			continue
+		}
+
+		to := event.EndTime
+		if event.IsRecurring() {
+			if to.Before(now) {
+				to = now
+			}
+			to = to.AddDate(recurringTimeZoneYears, 0, 0)
+		}
+
+		zone, ok := zones[loc.String()]
+		if !ok {
+			zones[loc.String()] = &zoneRange{loc: loc, from: event.StartTime, to: to}
+			continue
+		}
+		if event.StartTime.Before(zone.from) {
+			zone.from = event.StartTime
+		}
+		if to.After(zone.to) {
+			zone.to = to
+		}
+	}
+
+	tzids := make([]string, 0, len(zones))
+	for tzid := range zones {
+		tzids = append(tzids, tzid)
+	}
+	sort.Strings(tzids)
+
+	components := make([]*ical.Component, 0, len(zones))
+	for _, tzid := range tzids {
+		zone := zones[tzid]
+		components = append(components, ical.NewTimeZone(zone.loc, zone.from, zone.to))
+	}
+	return components
+}
+
+// eventComponent converts an event into a VEVENT
+func eventComponent(event *models.Event, uid string, now time.Time) *ical.Component {
+	vevent := ical.NewComponent(ical.ComponentEvent)
+	vevent.AddText("UID", uid)
+	vevent.Add("DTSTAMP", ical.FormatDateTime(now))
+	if loc := event.Location(); loc != time.UTC {
+		// Zoned times keep recurring events at the same wall-clock time
+		// across DST changes in the importing client
+		vevent.Add("DTSTART", ical.FormatLocalDateTime(event.StartTime.In(loc)), "TZID", loc.String())
+		vevent.Add("DTEND", ical.FormatLocalDateTime(event.EndTime.In(loc)), "TZID", loc.String())
+	} else {
+		vevent.Add("DTSTART", ical.FormatDateTime(event.StartTime))
+		vevent.Add("DTEND", ical.FormatDateTime(event.EndTime))
+	}
+	vevent.AddText("SUMMARY", event.Title)
+	if event.Description != "" {
+		vevent.AddText("DESCRIPTION", event.Description)
+	}
+	if event.RecurrenceRule != "" {
+		vevent.Add("RRULE", event.RecurrenceRule)
+	}
+	if len(event.ExDates) > 0 {
+		vevent.Add("EXDATE", ical.FormatDateTimes(event.ExDates))
+	}
+	if event.RecurrenceID != nil {
+		vevent.Add("RECURRENCE-ID", ical.FormatDateTime(*event.RecurrenceID))
+	}
+	for _, attendee := range event.Attendees {
+		params := []string{"ROLE", attendee.Role, "PARTSTAT", attendee.Status}
+		if attendee.Name != "" {
+			params = append(params, "CN", attendee.Name)
+		}
+		vevent.Add("ATTENDEE", attendee.CalAddress(), params...)
+	}
+	if !event.CreatedAt.IsZero() {
+		vevent.Add("CREATED", ical.FormatDateTime(event.CreatedAt))
+	}
+	if !event.UpdatedAt.IsZero() {
+		vevent.Add("LAST-MODIFIED", ical.FormatDateTime(event.UpdatedAt))
+	}
+	return vevent
+}
+
+// taskComponent converts a task into a VTODO
+func taskComponent(task *models.Task, now time.Time) *ical.Component {
+	uid := task.UID
+	if uid == "" {
+		uid = fmt.Sprintf("task-%d@agenda", task.ID)
+	}
+
+	vtodo := ical.NewComponent(ical.ComponentTodo)
+	vtodo.AddText("UID", uid)
+	vtodo.Add("DTSTAMP", ical.FormatDateTime(now))
+	vtodo.AddText("SUMMARY", task.Title)
+	if task.Description != "" {
+		vtodo.AddText("DESCRIPTION", task.Description)
+	}
+	if task.DueDate != nil {
+		vtodo.Add("DUE", ical.FormatDateTime(*task.DueDate))
+	}
+	if task.Status == models.TaskStatusCompleted {
+		vtodo.Add("STATUS", "COMPLETED")
+	} else {
+		vtodo.Add("STATUS", "NEEDS-ACTION")
+	}
+	if !task.CreatedAt.IsZero() {
+		vtodo.Add("CREATED", ical.FormatDateTime(task.CreatedAt))
+	}
+	if !task.UpdatedAt.IsZero() {
+		vtodo.Add("LAST-MODIFIED", ical.FormatDateTime(task.UpdatedAt))
+	}
+	return vtodo
+}
+
+// ImportCalendar imports the VEVENTs and VTODOs of an iCalendar file in a
+// single transaction. Components are matched to existing records by UID, so
+// importing the same file again updates the records instead of duplicating
+// them. Components that cannot be imported, including events rejected by
+// the conflict policy, are skipped and reported in the result; any other
+// error leaves the records as they were.
+func (is *ICalService) ImportCalendar(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	cal, err := ical.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCalendar, err)
+	}
+
+	result := &ImportResult{Skipped: []ImportSkip{}}
+	err = is.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		// Import series before the overrides that refer to them
+		var overrides []*ical.Component
+		for _, vevent := range cal.Children(ical.ComponentEvent) {
+			if vevent.Get("RECURRENCE-ID") != nil {
+				overrides = append(overrides, vevent)
+				continue
+			}
+			if err := is.importEvent(ctx, vevent, result); err != nil {
+				return err
+			}
+		}
+		for _, vevent := range overrides {
+			if err := is.importOverride(ctx, vevent, result); err != nil {
+				return err
+			}
+		}
+
+		for _, vtodo := range cal.Children(ical.ComponentTodo) {
+			if err := is.importTask(ctx, vtodo, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// checkImportedEvent checks an imported event about to be saved like the
+// event service checks the events it saves, except that imported events may
+// lie in the past. Events failing the checks are skipped, and ok is false;
+// err aborts the import.
+func (is *ICalService) checkImportedEvent(ctx context.Context, event *models.Event, excludeEventID *int,
+	skip func(reason error) error) (ok bool, err error) {
+	if err := validateEventTimes(event.StartTime, event.EndTime, false); err != nil {
+		return false, skip(err)
+	}
+	if _, err := is.events.checkConflictPolicy(ctx, event, excludeEventID); err != nil {
+		if errors.Is(err, ErrTimeConflict) {
+			return false, skip(err)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// importEvent creates or updates the event for a VEVENT without
+// RECURRENCE-ID. Attendees are imported with new events only; those of
+// existing events are managed through their own endpoints.
+func (is *ICalService) importEvent(ctx context.Context, vevent *ical.Component, result *ImportResult) error {
+	uid := vevent.Text("UID")
+	skip := func(reason error) error {
+		result.Skipped = append(result.Skipped, ImportSkip{Component: ical.ComponentEvent, UID: uid, Reason: reason.Error()})
+		return nil
+	}
+
+	if strings.EqualFold(vevent.Text("STATUS"), "CANCELLED") {
+		return skip(errCancelled)
+	}
+
+	event, err := parseEventComponent(vevent)
+	if err != nil {
+		return skip(err)
+	}
+
+	if uid != "" {
+		existing, err := is.eventRepo.GetEventByUID(ctx, uid)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to look up event: %w", err)
+		}
+		if existing != nil {
+			previous := *existing
+			existing.Title = event.Title
+			existing.Description = event.Description
+			existing.StartTime = event.StartTime
+			existing.EndTime = event.EndTime
+			existing.TimeZone = event.TimeZone
+			existing.RecurrenceRule = event.RecurrenceRule
+			existing.ExDates = event.ExDates
+			if ok, err := is.checkImportedEvent(ctx, existing, &existing.ID, skip); !ok {
+				return err
+			}
+			if err := is.events.updateEvent(ctx, &previous, existing); err != nil {
+				return fmt.Errorf("failed to update event: %w", err)
+			}
+			result.EventsUpdated++
+			return nil
+		}
+	}
+
+	event.UID = uid
+	if ok, err := is.checkImportedEvent(ctx, event, nil, skip); !ok {
+		return err
+	}
+	if _, err := is.events.createEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+	result.EventsCreated++
+	return nil
+}
+
+// importOverride creates or updates the override for a VEVENT with a
+// RECURRENCE-ID. Cancelled occurrences become EXDATEs of their series.
+func (is *ICalService) importOverride(ctx context.Context, vevent *ical.Component, result *ImportResult) error {
+	uid := vevent.Text("UID")
+	skip := func(reason error) error {
+		result.Skipped = append(result.Skipped, ImportSkip{Component: ical.ComponentEvent, UID: uid, Reason: reason.Error()})
+		return nil
+	}
+
+	recurrenceID, _, err := ical.ParseDateTime(vevent.Get("RECURRENCE-ID"))
+	if err != nil {
+		return skip(errInvalidRecurrenceID)
+	}
+
+	if uid == "" {
+		return skip(errOrphanOverride)
+	}
+	master, err := is.eventRepo.GetEventByUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return skip(errOrphanOverride)
+		}
+		return fmt.Errorf("failed to look up recurring event: %w", err)
+	}
+	if !master.IsRecurring() {
+		return skip(errOrphanOverride)
+	}
+
+	overrides, err := is.eventRepo.GetEventOverrides(ctx, master.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get event overrides: %w", err)
+	}
+	var existing *models.Event
+	for _, override := range overrides {
+		if override.RecurrenceID != nil && override.RecurrenceID.Equal(recurrenceID) {
+			existing = override
+			break
+		}
+	}
+
+	if strings.EqualFold(vevent.Text("STATUS"), "CANCELLED") {
+		if existing != nil {
+			if err := is.events.deleteEvent(ctx, existing); err != nil {
+				return fmt.Errorf("failed to delete event override: %w", err)
+			}
+		}
+		if !master.ExDates.Contains(recurrenceID) {
+			previous := *master
+			master.ExDates = append(append(models.TimeList{}, master.ExDates...), recurrenceID)
+			if err := is.events.updateEvent(ctx, &previous, master); err != nil {
+				return fmt.Errorf("failed to update event: %w", err)
+			}
+		}
+		result.EventsUpdated++
+		return nil
+	}
+
+	event, err := parseEventComponent(vevent)
+	if err != nil {
+		return skip(err)
+	}
+	// Overrides replace a single occurrence and cannot recur themselves
+	event.RecurrenceRule = ""
+	event.ExDates = nil
+
+	if existing != nil {
+		previous := *existing
+		existing.Title = event.Title
+		existing.Description = event.Description
+		existing.StartTime = event.StartTime
+		existing.EndTime = event.EndTime
+		if ok, err := is.checkImportedEvent(ctx, existing, &master.ID, skip); !ok {
+			return err
+		}
+		if err := is.events.updateEvent(ctx, &previous, existing); err != nil {
+			return fmt.Errorf("failed to update event override: %w", err)
+		}
+		result.EventsUpdated++
+		return nil
+	}
+
+	event.ParentID = &master.ID
+	event.RecurrenceID = &recurrenceID
+	event.CalendarID = master.CalendarID
+	if ok, err := is.checkImportedEvent(ctx, event, &master.ID, skip); !ok {
+		return err
+	}
+	if _, err := is.events.createEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to create event override: %w", err)
+	}
+	result.EventsCreated++
+	return nil
+}
+
+// importTask creates or updates the task for a VTODO
+func (is *ICalService) importTask(ctx context.Context, vtodo *ical.Component, result *ImportResult) error {
+	uid := vtodo.Text("UID")
+	skip := func(reason error) error {
+		result.Skipped = append(result.Skipped, ImportSkip{Component: ical.ComponentTodo, UID: uid, Reason: reason.Error()})
+		return nil
+	}
+
+	title := strings.TrimSpace(vtodo.Text("SUMMARY"))
+	if title == "" {
+		return skip(errMissingSummary)
+	}
+	if len(title) > 255 {
+		return skip(ErrTaskTitleTooLong)
+	}
+	description := strings.TrimSpace(vtodo.Text("DESCRIPTION"))
+	if len(description) > 1000 {
+		return skip(ErrTaskDescriptionTooLong)
+	}
+
+	var dueDate *time.Time
+	if prop := vtodo.Get("DUE"); prop != nil {
+		due, _, err := ical.ParseDateTime(prop)
+		if err != nil {
+			return skip(errInvalidDue)
+		}
+		dueDate = &due
+	}
+
+	status := models.TaskStatusPending
+	if strings.EqualFold(vtodo.Text("STATUS"), "COMPLETED") {
+		status = models.TaskStatusCompleted
+	}
+
+	if uid != "" {
+		existing, err := is.taskRepo.GetTaskByUID(ctx, uid)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to look up task: %w", err)
+		}
+		if existing != nil {
+			previous := *existing
+			existing.Title = title
+			existing.Description = description
+			existing.DueDate = dueDate
+			existing.Status = status
+
+			// Completions and reopenings are saved like those made through
+			// the task service
+			switch {
+			case previous.Status != models.TaskStatusCompleted && status == models.TaskStatusCompleted:
+				err = is.tasks.saveCompletion(ctx, &previous, existing)
+			case previous.Status == models.TaskStatusCompleted && status == models.TaskStatusPending:
+				existing.CompletedAt = nil
+				err = is.tasks.updateTask(ctx, ChangeTaskReopened, &previous, existing)
+			default:
+				err = is.tasks.updateTask(ctx, ChangeTaskUpdated, &previous, existing)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update task: %w", err)
+			}
+			result.TasksUpdated++
+			return nil
+		}
+	}
+
+	task := &models.Task{
+		Title:       title,
+		Description: description,
+		DueDate:     dueDate,
+		Status:      status,
+		UID:         uid,
+	}
+	if _, err := is.tasks.createTask(ctx, task); err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+	result.TasksCreated++
+	return nil
+}
+
+// parseEventComponent converts a VEVENT into an unsaved event
+func parseEventComponent(vevent *ical.Component) (*models.Event, error) {
+	event := &models.Event{
+		Title:       strings.TrimSpace(vevent.Text("SUMMARY")),
+		Description: strings.TrimSpace(vevent.Text("DESCRIPTION")),
+	}
+	if event.Title == "" {
+		return nil, errMissingSummary
+	}
+	if len(event.Title) > 255 {
+		return nil, ErrEventTitleTooLong
+	}
+	if len(event.Description) > 1000 {
+		return nil, ErrEventDescriptionTooLong
+	}
+
+	dtstart := vevent.Get("DTSTART")
+	if dtstart == nil {
+		return nil, errMissingStart
+	}
+	start, allDay, err := ical.ParseDateTime(dtstart)
+	if err != nil {
+		return nil, errMissingStart
+	}
+	event.StartTime = start
+	if loc := start.Location(); loc != time.UTC {
+		event.TimeZone = loc.String()
+	}
+
+	switch {
+	case vevent.Get("DTEND") != nil:
+		end, _, err := ical.ParseDateTime(vevent.Get("DTEND"))
+		if err != nil {
+			return nil, errInvalidEnd
+		}
+		event.EndTime = end
+	case vevent.Get("DURATION") != nil:
+		duration, err := ical.ParseDuration(vevent.Get("DURATION").Value)
+		if err != nil {
+			return nil, errInvalidEnd
+		}
+		event.EndTime = start.Add(duration)
+	case allDay:
+		// All-day events without an end last one day
+		event.EndTime = start.AddDate(0, 0, 1)
+	}
+	if !event.IsValidTimeRange() {
+		return nil, errInvalidEnd
+	}
+
+	event.Attendees = parseAttendees(vevent)
+
+	if prop := vevent.Get("RRULE"); prop != nil {
+		rule, err := recurrence.Parse(prop.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errUnsupportedRule, err)
+		}
+		event.RecurrenceRule = rule.String()
+
+		for _, exdate := range vevent.GetAll("EXDATE") {
+			times, _, err := ical.ParseDateTimes(exdate)
+			if err != nil {
+				return nil, errInvalidExDate
+			}
+			event.ExDates = append(event.ExDates, times...)
+		}
+	}
+
+	return event, nil
+}
+
+// parseAttendees converts the ATTENDEE properties of a VEVENT into unsaved
+// attendees. Attendees without a valid email address are left out, and
+// roles and statuses without an equivalent fall back to the defaults.
+func parseAttendees(vevent *ical.Component) []models.Attendee {
+	var attendees []models.Attendee
+	seen := make(map[string]bool)
+	for _, prop := range vevent.GetAll("ATTENDEE") {
+		email := strings.TrimSpace(prop.Value)
+		if len(email) > len("mailto:") && strings.EqualFold(email[:len("mailto:")], "mailto:") {
+			email = email[len("mailto:"):]
+		}
+		email = strings.ToLower(email)
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email || seen[email] {
+			continue
+		}
+		seen[email] = true
+
+		attendee := models.Attendee{
+			Name:   strings.TrimSpace(prop.Param("CN")),
+			Email:  email,
+			Role:   strings.ToUpper(prop.Param("ROLE")),
+			Status: strings.ToUpper(prop.Param("PARTSTAT")),
+		}
+		if !models.IsValidAttendeeRole(attendee.Role) {
+			attendee.Role = models.AttendeeRequired
+		}
+		if !models.IsValidRSVPStatus(attendee.Status) {
+			attendee.Status = models.RSVPNeedsAction
+		}
+		if len(attendee.Name) > 255 {
+			attendee.Name = ""
+		}
+		attendees = append(attendees, attendee)
+	}
+	return attendees
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestICalService() (*ICalService, *MockTaskRepository, *MockEventRepository) {
+	taskRepo := NewMockTaskRepository()
+	eventRepo := &MockEventRepository{}
+	eventRepo.On("GetRecurringEvents", mock.Anything, mock.Anything).Return([]*models.Event{}, nil).Maybe()
+	service := NewICalService(taskRepo, eventRepo, NewMockCalendarRepository(), MockTransactor{}).(*ICalService)
+	return service, taskRepo, eventRepo
+}
+
+func TestICalService_ExportCalendar(t *testing.T) {
+	service, taskRepo, eventRepo := createTestICalService()
+	ctx := context.Background()
+
+	start := time.Date(2030, time.January, 7, 9, 0, 0, 0, time.UTC)
+	master := &models.Event{
+		ID:             1,
+		Title:          "Standup",
+		StartTime:      start,
+		EndTime:        start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=DAILY;COUNT=5",
+		ExDates:        models.TimeList{start.AddDate(0, 0, 1)},
+		UID:            "standup@example.com",
+		Attendees: []models.Attendee{
+			{Name: "Ada", Email: "ada@example.com", Role: models.AttendeeChair, Status: models.RSVPAccepted},
+		},
+	}
+	recurrenceID := start.AddDate(0, 0, 2)
+	override := &models.Event{
+		ID:           2,
+		Title:        "Standup; moved",
+		StartTime:    recurrenceID.Add(time.Hour),
+		EndTime:      recurrenceID.Add(75 * time.Minute),
+		ParentID:     &master.ID,
+		RecurrenceID: &recurrenceID,
+	}
+	due := start.Add(8 * time.Hour)
+	_, err := taskRepo.CreateTask(ctx, &models.Task{Title: "Report", DueDate: &due, Status: models.TaskStatusCompleted})
+	require.NoError(t, err)
+
+	eventRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{master, override}, nil)
+
+	data, err := service.ExportCalendar(ctx, nil, nil)
+
+	require.NoError(t, err)
+	calendar := string(data)
+	assert.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.Equal(t, 2, strings.Count(calendar, "UID:standup@example.com\r\n"))
+	assert.Contains(t, calendar, "RRULE:FREQ=DAILY;COUNT=5\r\n")
+	assert.Contains(t, calendar, "EXDATE:20300108T090000Z\r\n")
+	assert.Contains(t, calendar, "RECURRENCE-ID:20300109T090000Z\r\n")
+	assert.Contains(t, calendar, "SUMMARY:Standup\\; moved\r\n")
+	assert.Contains(t, calendar, "ATTENDEE;CN=Ada;PARTSTAT=ACCEPTED;ROLE=CHAIR:mailto:ada@example.com\r\n")
+	assert.Contains(t, calendar, "BEGIN:VTODO\r\nUID:task-1@agenda\r\n")
+	assert.Contains(t, calendar, "STATUS:COMPLETED\r\n")
+	eventRepo.AssertExpectations(t)
+}
+
+func TestICalService_ExportCalendar_InvalidRange(t *testing.T) {
+	service, _, _ := createTestICalService()
+
+	start := time.Now()
+	end := start.Add(-time.Hour)
+	_, err := service.ExportCalendar(context.Background(), &start, &end)
+
+	assert.Equal(t, ErrInvalidDateRange, err)
+}
+
+func TestICalService_ImportCalendar_SkipsInvalidComponents(t *testing.T) {
+	service, taskRepo, eventRepo := createTestICalService()
+	ctx := context.Background()
+
+	data := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:no-summary\r\nDTSTART:20300101T090000Z\r\nDTEND:20300101T100000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:hourly\r\nSUMMARY:Ping\r\nDTSTART:20300101T090000Z\r\nDTEND:20300101T100000Z\r\nRRULE:FREQ=HOURLY\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:backwards\r\nSUMMARY:Backwards\r\nDTSTART:20300101T090000Z\r\nDTEND:20300101T080000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:orphan\r\nSUMMARY:Orphan\r\nRECURRENCE-ID:20300101T090000Z\r\nDTSTART:20300101T090000Z\r\nDTEND:20300101T100000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VTODO\r\nUID:bad-due\r\nSUMMARY:Task\r\nDUE:soon\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	eventRepo.On("GetEventByUID", ctx, "orphan").Return(nil, sql.ErrNoRows)
+
+	result, err := service.ImportCalendar(ctx, strings.NewReader(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.EventsCreated)
+	assert.Equal(t, 0, result.TasksCreated)
+	require.Len(t, result.Skipped, 5)
+	reasons := make(map[string]string)
+	for _, skip := range result.Skipped {
+		reasons[skip.UID] = skip.Reason
+	}
+	assert.Equal(t, errMissingSummary.Error(), reasons["no-summary"])
+	assert.True(t, strings.HasPrefix(reasons["hourly"], errUnsupportedRule.Error()))
+	assert.Equal(t, errInvalidEnd.Error(), reasons["backwards"])
+	assert.Equal(t, errOrphanOverride.Error(), reasons["orphan"])
+	assert.Equal(t, errInvalidDue.Error(), reasons["bad-due"])
+	assert.Empty(t, taskRepo.tasks)
+	eventRepo.AssertExpectations(t)
+}
+
+func TestICalService_ImportCalendar_CancelledOccurrence(t *testing.T) {
+	service, _, eventRepo := createTestICalService()
+	ctx := context.Background()
+
+	start := time.Date(2030, time.January, 7, 9, 0, 0, 0, time.UTC)
+	master := &models.Event{
+		ID:             1,
+		Title:          "Standup",
+		StartTime:      start,
+		EndTime:        start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=DAILY;COUNT=5",
+		UID:            "standup@example.com",
+	}
+	cancelled := start.AddDate(0, 0, 1)
+
+	data := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:standup@example.com\r\nRECURRENCE-ID:20300108T090000Z\r\nSTATUS:CANCELLED\r\n" +
+		"SUMMARY:Standup\r\nDTSTART:20300108T090000Z\r\nDTEND:20300108T091500Z\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	eventRepo.On("GetEventByUID", ctx, "standup@example.com").Return(master, nil)
+	eventRepo.On("GetEventOverrides", ctx, 1).Return([]*models.Event{}, nil)
+	eventRepo.On("UpdateEvent", ctx, mock.MatchedBy(func(event *models.Event) bool {
+		return event.ID == 1 && event.ExDates.Contains(cancelled)
+	})).Return(nil)
+
+	result, err := service.ImportCalendar(ctx, strings.NewReader(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.EventsUpdated)
+	assert.Empty(t, result.Skipped)
+	eventRepo.AssertExpectations(t)
+}
+
+func TestICalService_ImportCalendar_Attendees(t *testing.T) {
+	service, _, eventRepo := createTestICalService()
+	ctx := context.Background()
+
+	data := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:review@example.com\r\nSUMMARY:Review\r\nDTSTART:20300101T090000Z\r\nDTEND:20300101T100000Z\r\n" +
+		"ATTENDEE;CN=\"Lovelace, Ada\";ROLE=CHAIR;PARTSTAT=ACCEPTED:MAILTO:Ada@Example.com\r\n" +
+		"ATTENDEE;PARTSTAT=DELEGATED:mailto:grace@example.com\r\n" +
+		"ATTENDEE:mailto:ada@example.com\r\n" +
+		"ATTENDEE:urn:uuid:not-an-email\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	eventRepo.On("GetEventByUID", ctx, "review@example.com").Return(nil, sql.ErrNoRows)
+	eventRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{}, nil)
+	eventRepo.On("CreateEvent", ctx, mock.MatchedBy(func(event *models.Event) bool {
+		return len(event.Attendees) == 2 &&
+			event.Attendees[0] == models.Attendee{Name: "Lovelace, Ada", Email: "ada@example.com", Role: models.AttendeeChair, Status: models.RSVPAccepted} &&
+			event.Attendees[1] == models.Attendee{Email: "grace@example.com", Role: models.AttendeeRequired, Status: models.RSVPNeedsAction}
+	})).Return(&models.Event{ID: 1}, nil)
+
+	result, err := service.ImportCalendar(ctx, strings.NewReader(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.EventsCreated)
+	eventRepo.AssertExpectations(t)
+}
+
+func TestICalService_ImportCalendar_ChecksAndPublishes(t *testing.T) {
+	taskRepo := NewMockTaskRepository()
+	eventRepo := &MockEventRepository{}
+	publisher := &recordingPublisher{}
+	service := NewICalService(taskRepo, eventRepo, NewMockCalendarRepository(), MockTransactor{}, publisher)
+	ctx := context.Background()
+
+	start := time.Date(2030, time.January, 1, 9, 0, 0, 0, time.UTC)
+	busy := &models.Event{ID: 1, Title: "Busy", StartTime: start, EndTime: start.Add(time.Hour)}
+
+	data := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:overlap\r\nSUMMARY:Overlap\r\nDTSTART:20300101T093000Z\r\nDTEND:20300101T103000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:long\r\nSUMMARY:Long\r\nDTSTART:20300102T090000Z\r\nDTEND:20300104T090000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VTODO\r\nUID:report\r\nSUMMARY:Report\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	eventRepo.On("GetEventByUID", ctx, mock.Anything).Return(nil, sql.ErrNoRows)
+	eventRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{busy}, nil)
+	eventRepo.On("GetRecurringEvents", ctx, mock.AnythingOfType("time.Time")).Return([]*models.Event{}, nil)
+
+	result, err := service.ImportCalendar(ctx, strings.NewReader(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.EventsCreated)
+	assert.Equal(t, 1, result.TasksCreated)
+	require.Len(t, result.Skipped, 2)
+	assert.Equal(t, ErrTimeConflict.Error(), result.Skipped[0].Reason)
+	assert.Equal(t, ErrEventTooLong.Error(), result.Skipped[1].Reason)
+	assert.Equal(t, []string{ChangeTaskCreated}, publisher.types())
+	eventRepo.AssertNotCalled(t, "CreateEvent", mock.Anything, mock.Anything)
+}
+
+func TestICalService_ImportCalendar_InvalidCalendar(t *testing.T) {
+	service, _, _ := createTestICalService()
+
+	_, err := service.ImportCalendar(context.Background(), strings.NewReader("not a calendar"))
+
+	assert.ErrorIs(t, err, ErrInvalidCalendar)
+}
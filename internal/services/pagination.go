@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+
+	"agenda/internal/database"
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed or were
+// created for another ordering
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageInfo describes a page of a list
+type PageInfo struct {
+	// Total is the number of items matching the filters; zero when the
+	// count was skipped
+	Total int64
+	// NextCursor and PrevCursor fetch the adjacent pages; empty when there
+	// is no such page
+	NextCursor string
+	PrevCursor string
+}
+
+// decodeCursor parses a cursor token, checking that it was created for the
+// given ordering. The empty token decodes to nil.
+func decodeCursor(token, sort string, desc bool) (*database.Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	cursor, err := database.DecodeCursor(token)
+	if err != nil || cursor.Sort != sort || cursor.Desc != desc {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// newPageInfo returns the page info of a page with the given cursors
+func newPageInfo(total int64, cursors database.PageCursors) PageInfo {
+	info := PageInfo{Total: total}
+	if cursors.Next != nil {
+		info.NextCursor = cursors.Next.Encode()
+	}
+	if cursors.Prev != nil {
+		info.PrevCursor = cursors.Prev.Encode()
+	}
+	return info
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/recurrence"
+)
+
+// ReminderServiceInterface defines the contract for reminder business logic operations
+type ReminderServiceInterface interface {
+	CreateTaskReminder(ctx context.Context, taskID int, req CreateReminderRequest) (*models.Reminder, error)
+	CreateEventReminder(ctx context.Context, eventID int, req CreateReminderRequest) (*models.Reminder, error)
+	GetTaskReminders(ctx context.Context, taskID int) ([]*models.Reminder, error)
+	GetEventReminders(ctx context.Context, eventID int) ([]*models.Reminder, error)
+	DeleteReminder(ctx context.Context, id int) error
+}
+
+// ReminderService implements ReminderServiceInterface
+type ReminderService struct {
+	reminderRepo database.ReminderRepositoryInterface
+	taskRepo     database.TaskRepositoryInterface
+	eventRepo    database.EventRepositoryInterface
+	transactor   database.Transactor
+	publishers   changePublishers
+}
+
+// NewReminderService creates a new reminder service instance. Adding or
+// removing a reminder is published to publishers as an update of its task or
+// event, in the transaction making it.
+func NewReminderService(reminderRepo database.ReminderRepositoryInterface, taskRepo database.TaskRepositoryInterface,
+	eventRepo database.EventRepositoryInterface, transactor database.Transactor, publishers ...ChangePublisher) ReminderServiceInterface {
+	return &ReminderService{
+		reminderRepo: reminderRepo,
+		taskRepo:     taskRepo,
+		eventRepo:    eventRepo,
+		transactor:   transactor,
+		publishers:   publishers,
+	}
+}
+
+// CreateReminderRequest represents the request to add a reminder to a task or event
+type CreateReminderRequest struct {
+	// OffsetMinutes is how long before the due date or start time the
+	// reminder fires; 0 fires at that time
+	OffsetMinutes int `json:"offset_minutes"`
+}
+
+// Reminder limits
+const (
+	// maxReminderOffsetMinutes bounds how early a reminder can fire (4 weeks)
+	maxReminderOffsetMinutes = 4 * 7 * 24 * 60
+	// maxRemindersPerItem bounds the number of reminders of a task or event
+	maxRemindersPerItem = 10
+)
+
+// Reminder errors
+var (
+	ErrReminderNotFound        = errors.New("reminder not found")
+	ErrInvalidReminderOffset   = errors.New("offset_minutes must be between 0 and 40320")
+	ErrTooManyReminders        = errors.New("a task or event cannot have more than 10 reminders")
+	ErrReminderRequiresDueDate = errors.New("reminders can only be added to tasks with a due date")
+	ErrReminderTaskCompleted   = errors.New("reminders cannot be added to completed tasks")
+	ErrNoUpcomingOccurrence    = errors.New("event has no upcoming occurrence to remind about")
+)
+
+// CreateTaskReminder adds a reminder firing the given number of minutes
+// before the task is due
+func (rs *ReminderService) CreateTaskReminder(ctx context.Context, taskID int, req CreateReminderRequest) (*models.Reminder, error) {
+	if err := validateReminderRequest(req); err != nil {
+		return nil, err
+	}
+
+	var created *models.Reminder
+	err := rs.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		task, err := rs.taskRepo.GetTaskByID(ctx, taskID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+		if task.Status == models.TaskStatusCompleted {
+			return ErrReminderTaskCompleted
+		}
+		if task.DueDate == nil {
+			return ErrReminderRequiresDueDate
+		}
+
+		existing, err := rs.reminderRepo.GetTaskReminders(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if len(existing) >= maxRemindersPerItem {
+			return ErrTooManyReminders
+		}
+
+		reminder := &models.Reminder{
+			TaskID:        &task.ID,
+			OffsetMinutes: req.OffsetMinutes,
+		}
+		reminder.Schedule(*task.DueDate)
+
+		created, err = rs.reminderRepo.CreateReminder(ctx, reminder)
+		if err != nil {
+			return err
+		}
+		return rs.publishTaskReminders(ctx, task, existing)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// CreateEventReminder adds a reminder firing the given number of minutes
+// before the event starts. Reminders of a recurring event fire for every
+// occurrence, starting with the next one.
+func (rs *ReminderService) CreateEventReminder(ctx context.Context, eventID int, req CreateReminderRequest) (*models.Reminder, error) {
+	if err := validateReminderRequest(req); err != nil {
+		return nil, err
+	}
+
+	var created *models.Reminder
+	err := rs.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		event, err := rs.eventRepo.GetEventByID(ctx, eventID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrEventNotFound
+			}
+			return err
+		}
+
+		var overrides []*models.Event
+		if event.IsRecurring() {
+			overrides, err = rs.eventRepo.GetEventOverrides(ctx, event.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		occurrence, ok := NextEventOccurrence(event, overrides, time.Now())
+		if !ok {
+			return ErrNoUpcomingOccurrence
+		}
+
+		existing, err := rs.reminderRepo.GetEventReminders(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		if len(existing) >= maxRemindersPerItem {
+			return ErrTooManyReminders
+		}
+
+		reminder := &models.Reminder{
+			EventID:       &event.ID,
+			OffsetMinutes: req.OffsetMinutes,
+		}
+		reminder.Schedule(occurrence)
+
+		created, err = rs.reminderRepo.CreateReminder(ctx, reminder)
+		if err != nil {
+			return err
+		}
+		return rs.publishEventReminders(ctx, event, existing)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetTaskReminders retrieves the reminders of a task
+func (rs *ReminderService) GetTaskReminders(ctx context.Context, taskID int) ([]*models.Reminder, error) {
+	if _, err := rs.taskRepo.GetTaskByID(ctx, taskID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	return rs.reminderRepo.GetTaskReminders(ctx, taskID)
+}
+
+// GetEventReminders retrieves the reminders of an event
+func (rs *ReminderService) GetEventReminders(ctx context.Context, eventID int) ([]*models.Reminder, error) {
+	if _, err := rs.eventRepo.GetEventByID(ctx, eventID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+
+	return rs.reminderRepo.GetEventReminders(ctx, eventID)
+}
+
+// DeleteReminder removes a reminder. Reminders of tasks and events in the
+// trash are not found.
+func (rs *ReminderService) DeleteReminder(ctx context.Context, id int) error {
+	return rs.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		reminder, err := rs.reminderRepo.GetReminderByID(ctx, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrReminderNotFound
+			}
+			return err
+		}
+
+		var task *models.Task
+		var event *models.Event
+		var existing []*models.Reminder
+		if reminder.TaskID != nil {
+			task, err = rs.taskRepo.GetTaskByID(ctx, *reminder.TaskID)
+			if err == nil {
+				existing, err = rs.reminderRepo.GetTaskReminders(ctx, task.ID)
+			}
+		} else {
+			event, err = rs.eventRepo.GetEventByID(ctx, *reminder.EventID)
+			if err == nil {
+				existing, err = rs.reminderRepo.GetEventReminders(ctx, event.ID)
+			}
+		}
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrReminderNotFound
+			}
+			return err
+		}
+
+		if err := rs.reminderRepo.DeleteReminder(ctx, id); err != nil {
+			return err
+		}
+		if task != nil {
+			return rs.publishTaskReminders(ctx, task, existing)
+		}
+		return rs.publishEventReminders(ctx, event, existing)
+	})
+}
+
+// publishTaskReminders publishes a change to the reminders of a task, which
+// had the previous reminders before it, as an update of the task
+func (rs *ReminderService) publishTaskReminders(ctx context.Context, task *models.Task, previous []*models.Reminder) error {
+	reminders, err := rs.reminderRepo.GetTaskReminders(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	before, after := *task, *task
+	before.Reminders = reminderValues(previous)
+	after.Reminders = reminderValues(reminders)
+	return rs.publishers.publishUpdate(ctx, ChangeTaskUpdated, &before, &after)
+}
+
+// publishEventReminders publishes a change to the reminders of an event,
+// which had the previous reminders before it, as an update of the event
+func (rs *ReminderService) publishEventReminders(ctx context.Context, event *models.Event, previous []*models.Reminder) error {
+	reminders, err := rs.reminderRepo.GetEventReminders(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+
+	before, after := *event, *event
+	before.Reminders = reminderValues(previous)
+	after.Reminders = reminderValues(reminders)
+	return rs.publishers.publishUpdate(ctx, ChangeEventUpdated, &before, &after)
+}
+
+// reminderValues copies reminders into the list held by a task or event
+func reminderValues(reminders []*models.Reminder) []models.Reminder {
+	values := make([]models.Reminder, 0, len(reminders))
+	for _, reminder := range reminders {
+		values = append(values, *reminder)
+	}
+	return values
+}
+
+// validateReminderRequest validates the fields of a new reminder
+func validateReminderRequest(req CreateReminderRequest) error {
+	if req.OffsetMinutes < 0 || req.OffsetMinutes > maxReminderOffsetMinutes {
+		return ErrInvalidReminderOffset
+	}
+	return nil
+}
+
+// NextEventOccurrence returns the start time of the first occurrence of
+// event starting at or after t. For a recurring series, occurrences removed
+// by an EXDATE are skipped and occurrences replaced by one of overrides
+// start at the time of the override.
+func NextEventOccurrence(event *models.Event, overrides []*models.Event, t time.Time) (time.Time, bool) {
+	if !event.IsRecurring() {
+		return event.StartTime, !event.StartTime.Before(t)
+	}
+
+	rule, err := recurrence.Parse(event.RecurrenceRule)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	overridden := make(models.TimeList, 0, len(overrides))
+	var next time.Time
+	found := false
+	for _, override := range overrides {
+		if override.RecurrenceID != nil {
+			overridden = append(overridden, *override.RecurrenceID)
+		}
+		if !override.StartTime.Before(t) && (!found || override.StartTime.Before(next)) {
+			next = override.StartTime
+			found = true
+		}
+	}
+
+	start, ok := rule.After(event.LocalStart(), t.Add(-time.Nanosecond))
+	for ok && (event.ExDates.Contains(start) || overridden.Contains(start)) {
+		start, ok = rule.After(event.LocalStart(), start)
+	}
+	if ok && (!found || start.Before(next)) {
+		next = start
+		found = true
+	}
+
+	return next, found
+}
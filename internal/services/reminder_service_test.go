@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReminderRepository implements ReminderRepositoryInterface for testing
+type MockReminderRepository struct {
+	reminders map[int]*models.Reminder
+	nextID    int
+}
+
+func NewMockReminderRepository() *MockReminderRepository {
+	return &MockReminderRepository{
+		reminders: make(map[int]*models.Reminder),
+		nextID:    1,
+	}
+}
+
+func (m *MockReminderRepository) CreateReminder(ctx context.Context, reminder *models.Reminder) (*models.Reminder, error) {
+	reminder.ID = m.nextID
+	m.nextID++
+	if reminder.Status == "" {
+		reminder.Status = models.ReminderPending
+	}
+	m.reminders[reminder.ID] = reminder
+	return reminder, nil
+}
+
+func (m *MockReminderRepository) GetReminderByID(ctx context.Context, id int) (*models.Reminder, error) {
+	reminder, exists := m.reminders[id]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return reminder, nil
+}
+
+func (m *MockReminderRepository) GetTaskReminders(ctx context.Context, taskID int) ([]*models.Reminder, error) {
+	var reminders []*models.Reminder
+	for _, reminder := range m.reminders {
+		if reminder.TaskID != nil && *reminder.TaskID == taskID {
+			reminders = append(reminders, reminder)
+		}
+	}
+	return reminders, nil
+}
+
+func (m *MockReminderRepository) GetEventReminders(ctx context.Context, eventID int) ([]*models.Reminder, error) {
+	var reminders []*models.Reminder
+	for _, reminder := range m.reminders {
+		if reminder.EventID != nil && *reminder.EventID == eventID {
+			reminders = append(reminders, reminder)
+		}
+	}
+	return reminders, nil
+}
+
+func (m *MockReminderRepository) DeleteReminder(ctx context.Context, id int) error {
+	delete(m.reminders, id)
+	return nil
+}
+
+func (m *MockReminderRepository) GetDueReminders(ctx context.Context, now time.Time, limit int) ([]*models.Reminder, error) {
+	return nil, nil
+}
+
+func (m *MockReminderRepository) ClaimReminder(ctx context.Context, reminder *models.Reminder) (bool, error) {
+	return false, nil
+}
+
+func (m *MockReminderRepository) SaveReminderState(ctx context.Context, previous, reminder *models.Reminder) (bool, error) {
+	return false, nil
+}
+
+func (m *MockReminderRepository) ResetInterruptedReminders(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// BaseRepository methods (not used in tests but required for interface)
+func (m *MockReminderRepository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockReminderRepository) GetByID(ctx context.Context, dest interface{}, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockReminderRepository) Update(ctx context.Context, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockReminderRepository) Delete(ctx context.Context, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockReminderRepository) List(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockReminderRepository) Count(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockReminderRepository) Exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	return false, nil
+}
+
+func TestReminderService_TaskReminders(t *testing.T) {
+	taskRepo := NewMockTaskRepository()
+	reminderRepo := NewMockReminderRepository()
+	publisher := &recordingPublisher{}
+	service := NewReminderService(reminderRepo, taskRepo, new(MockEventRepository), MockTransactor{}, publisher)
+	ctx := context.Background()
+
+	due := time.Now().Add(48 * time.Hour)
+	task, err := taskRepo.CreateTask(ctx, &models.Task{Title: "File taxes", DueDate: &due})
+	require.NoError(t, err)
+	undated, err := taskRepo.CreateTask(ctx, &models.Task{Title: "Someday"})
+	require.NoError(t, err)
+	completed, err := taskRepo.CreateTask(ctx, &models.Task{Title: "Done", DueDate: &due, Status: models.TaskStatusCompleted})
+	require.NoError(t, err)
+
+	t.Run("fires the offset before the due date", func(t *testing.T) {
+		reminder, err := service.CreateTaskReminder(ctx, task.ID, CreateReminderRequest{OffsetMinutes: 24 * 60})
+		require.NoError(t, err)
+		assert.Equal(t, models.ReminderPending, reminder.Status)
+		assert.True(t, reminder.OccurrenceAt.Equal(due))
+		assert.True(t, reminder.RemindAt.Equal(due.Add(-24*time.Hour)))
+
+		require.Equal(t, []string{ChangeTaskUpdated}, publisher.types())
+		assert.Empty(t, publisher.changes[0].Previous.(*models.Task).Reminders)
+		assert.Len(t, publisher.changes[0].Data.(*models.Task).Reminders, 1)
+
+		reminders, err := service.GetTaskReminders(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Len(t, reminders, 1)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := service.CreateTaskReminder(ctx, task.ID, CreateReminderRequest{OffsetMinutes: -1})
+		assert.Equal(t, ErrInvalidReminderOffset, err)
+
+		_, err = service.CreateTaskReminder(ctx, task.ID, CreateReminderRequest{OffsetMinutes: maxReminderOffsetMinutes + 1})
+		assert.Equal(t, ErrInvalidReminderOffset, err)
+
+		_, err = service.CreateTaskReminder(ctx, undated.ID, CreateReminderRequest{OffsetMinutes: 10})
+		assert.Equal(t, ErrReminderRequiresDueDate, err)
+
+		_, err = service.CreateTaskReminder(ctx, completed.ID, CreateReminderRequest{OffsetMinutes: 10})
+		assert.Equal(t, ErrReminderTaskCompleted, err)
+
+		_, err = service.CreateTaskReminder(ctx, 999, CreateReminderRequest{OffsetMinutes: 10})
+		assert.Equal(t, ErrTaskNotFound, err)
+	})
+
+	t.Run("limits the number of reminders", func(t *testing.T) {
+		for i := len(reminderRepo.reminders); i < maxRemindersPerItem; i++ {
+			_, err := service.CreateTaskReminder(ctx, task.ID, CreateReminderRequest{OffsetMinutes: i})
+			require.NoError(t, err)
+		}
+
+		_, err := service.CreateTaskReminder(ctx, task.ID, CreateReminderRequest{OffsetMinutes: 5})
+		assert.Equal(t, ErrTooManyReminders, err)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		count := len(publisher.changes)
+		assert.NoError(t, service.DeleteReminder(ctx, 1))
+		require.Len(t, publisher.changes, count+1)
+		deleted := publisher.changes[count]
+		assert.Len(t, deleted.Previous.(*models.Task).Reminders, maxRemindersPerItem)
+		assert.Len(t, deleted.Data.(*models.Task).Reminders, maxRemindersPerItem-1)
+
+		assert.Equal(t, ErrReminderNotFound, service.DeleteReminder(ctx, 1))
+	})
+}
+
+func TestReminderService_EventReminders(t *testing.T) {
+	eventRepo := new(MockEventRepository)
+	reminderRepo := NewMockReminderRepository()
+	service := NewReminderService(reminderRepo, NewMockTaskRepository(), eventRepo, MockTransactor{})
+	ctx := context.Background()
+
+	start := time.Now().Add(-48 * time.Hour).Truncate(time.Hour)
+	past := &models.Event{ID: 1, Title: "Retro", StartTime: start, EndTime: start.Add(time.Hour)}
+	daily := &models.Event{ID: 2, Title: "Standup", StartTime: start, EndTime: start.Add(15 * time.Minute),
+		RecurrenceRule: "FREQ=DAILY"}
+
+	eventRepo.On("GetEventByID", ctx, 1).Return(past, nil)
+	eventRepo.On("GetEventByID", ctx, 2).Return(daily, nil)
+	eventRepo.On("GetEventByID", ctx, 3).Return(nil, sql.ErrNoRows)
+	eventRepo.On("GetEventOverrides", ctx, 2).Return([]*models.Event{}, nil)
+
+	t.Run("recurring events are reminded about the next occurrence", func(t *testing.T) {
+		reminder, err := service.CreateEventReminder(ctx, 2, CreateReminderRequest{OffsetMinutes: 15})
+		require.NoError(t, err)
+		assert.False(t, reminder.OccurrenceAt.Before(time.Now()))
+		assert.True(t, reminder.OccurrenceAt.Before(time.Now().Add(24*time.Hour)))
+		assert.Equal(t, reminder.OccurrenceAt.Add(-15*time.Minute), reminder.RemindAt)
+	})
+
+	t.Run("events that already started", func(t *testing.T) {
+		_, err := service.CreateEventReminder(ctx, 1, CreateReminderRequest{OffsetMinutes: 15})
+		assert.Equal(t, ErrNoUpcomingOccurrence, err)
+	})
+
+	t.Run("missing events", func(t *testing.T) {
+		_, err := service.CreateEventReminder(ctx, 3, CreateReminderRequest{OffsetMinutes: 15})
+		assert.Equal(t, ErrEventNotFound, err)
+
+		_, err = service.GetEventReminders(ctx, 3)
+		assert.Equal(t, ErrEventNotFound, err)
+	})
+
+	eventRepo.AssertNotCalled(t, "GetEventOverrides", mock.Anything, 1)
+}
+
+func TestNextEventOccurrence(t *testing.T) {
+	start := time.Date(2030, time.March, 4, 9, 0, 0, 0, time.UTC)
+	weekly := &models.Event{
+		ID:             1,
+		StartTime:      start,
+		EndTime:        start.Add(time.Hour),
+		RecurrenceRule: "FREQ=WEEKLY;COUNT=4",
+		ExDates:        models.TimeList{start.AddDate(0, 0, 7)},
+	}
+	movedFrom := start.AddDate(0, 0, 14)
+	movedTo := movedFrom.Add(-26 * time.Hour)
+	overrides := []*models.Event{{ID: 2, StartTime: movedTo, EndTime: movedTo.Add(time.Hour), RecurrenceID: &movedFrom}}
+
+	tests := []struct {
+		name  string
+		after time.Time
+		want  time.Time
+		ok    bool
+	}{
+		{"first occurrence", start.Add(-time.Hour), start, true},
+		{"occurrence starting at the bound", start, start, true},
+		{"excluded occurrences are skipped", start.Add(time.Minute), movedTo, true},
+		{"moved occurrences use the override", movedTo.Add(time.Minute), start.AddDate(0, 0, 21), true},
+		{"series ended", start.AddDate(0, 0, 22), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NextEventOccurrence(weekly, overrides, tt.after)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.True(t, got.Equal(tt.want), "expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	single := &models.Event{StartTime: start, EndTime: start.Add(time.Hour)}
+	_, ok := NextEventOccurrence(single, nil, start.Add(time.Second))
+	assert.False(t, ok)
+}
+
+func TestNextEventOccurrence_TimeZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// Daily at 8:00 in Berlin; clocks move forward on March 31, 2030
+	start := time.Date(2030, time.March, 30, 8, 0, 0, 0, berlin)
+	daily := &models.Event{
+		StartTime:      start.UTC(),
+		EndTime:        start.Add(time.Hour).UTC(),
+		TimeZone:       "Europe/Berlin",
+		RecurrenceRule: "FREQ=DAILY",
+	}
+
+	got, ok := NextEventOccurrence(daily, nil, start.Add(time.Minute))
+	require.True(t, ok)
+	assert.True(t, got.Equal(time.Date(2030, time.March, 31, 8, 0, 0, 0, berlin)), "got %v", got)
+	assert.Equal(t, 6, got.UTC().Hour())
+}
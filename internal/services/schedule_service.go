@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/recurrence"
+	"agenda/internal/timezone"
+)
+
+// ScheduleServiceInterface defines the contract for scheduling operations:
+// free/busy queries and meeting slot suggestions
+type ScheduleServiceInterface interface {
+	GetFreeBusy(ctx context.Context, req FreeBusyRequest) (*FreeBusy, error)
+	SuggestSlots(ctx context.Context, req SuggestSlotsRequest) ([]*SlotSuggestion, error)
+}
+
+// ScheduleService implements ScheduleServiceInterface on top of the
+// conflict checks of the event service
+type ScheduleService struct {
+	eventService EventServiceInterface
+	calendarRepo database.CalendarRepositoryInterface
+}
+
+// NewScheduleService creates a new schedule service instance
+func NewScheduleService(eventService EventServiceInterface, calendarRepo database.CalendarRepositoryInterface) ScheduleServiceInterface {
+	return &ScheduleService{
+		eventService: eventService,
+		calendarRepo: calendarRepo,
+	}
+}
+
+// FreeBusyRequest represents a free/busy query. Only the events of the
+// given attendees and calendars make the range busy; every event does when
+// neither is given.
+type FreeBusyRequest struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Attendees   []string  `json:"attendees"`
+	CalendarIDs []int     `json:"calendar_ids"`
+}
+
+// BusyInterval is a period taken by one or more events
+type BusyInterval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// FreeBusy lists the busy intervals of a range in chronological order.
+// Overlapping and adjacent events are merged into a single interval.
+type FreeBusy struct {
+	Start time.Time      `json:"start"`
+	End   time.Time      `json:"end"`
+	Busy  []BusyInterval `json:"busy"`
+}
+
+// WorkingHours are the times of day, on the given days of the week,
+// meetings can be suggested at. Times are HH:MM wall-clock times and days
+// are iCalendar weekday codes such as MO.
+type WorkingHours struct {
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	Days  []string `json:"days"`
+}
+
+// SuggestSlotsRequest represents a request for meeting slots of Duration
+// within [Start, End). Slots must be free for the attendees and calendars;
+// the optional attendees being free makes a slot rank higher. Working
+// hours are taken in TimeZone, or in the request time zone when empty.
+type SuggestSlotsRequest struct {
+	Duration          time.Duration `json:"duration"`
+	Start             time.Time     `json:"start"`
+	End               time.Time     `json:"end"`
+	WorkingHours      WorkingHours  `json:"working_hours"`
+	TimeZone          string        `json:"time_zone"`
+	Attendees         []string      `json:"attendees"`
+	OptionalAttendees []string      `json:"optional_attendees"`
+	CalendarIDs       []int         `json:"calendar_ids"`
+	Limit             int           `json:"limit"`
+}
+
+// SlotSuggestion is a candidate meeting slot. Unavailable lists the
+// optional attendees busy during the slot.
+type SlotSuggestion struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Unavailable []string  `json:"unavailable_attendees"`
+}
+
+// Scheduling limits
+const (
+	// maxScheduleRange bounds the ranges of free/busy queries and slot
+	// suggestions
+	maxScheduleRange = 31 * 24 * time.Hour
+	// slotStep is the spacing of suggested slot start times
+	slotStep            = 30 * time.Minute
+	maxSlotDuration     = 24 * time.Hour
+	defaultSlotLimit    = 10
+	maxSlotLimit        = 50
+	defaultWorkdayStart = "09:00"
+	defaultWorkdayEnd   = "17:00"
+)
+
+// defaultWorkingDays are the days slots are suggested on unless told
+// otherwise
+var defaultWorkingDays = []string{"MO", "TU", "WE", "TH", "FR"}
+
+// Schedule errors
+var (
+	ErrInvalidScheduleRange    = errors.New("end must be after start and at most 31 days later")
+	ErrInvalidSlotDuration     = errors.New("duration must be positive and at most 24 hours")
+	ErrInvalidWorkingHours     = errors.New("working hours must be HH:MM times with the start before the end")
+	ErrInvalidWorkingDay       = errors.New("working days must be iCalendar weekday codes such as MO")
+	ErrInvalidScheduleTimeZone = errors.New("time zone must be an IANA time zone name")
+)
+
+// GetFreeBusy returns the busy intervals of a range, clipped to the range
+func (ss *ScheduleService) GetFreeBusy(ctx context.Context, req FreeBusyRequest) (*FreeBusy, error) {
+	if err := validateScheduleRange(req.Start, req.End); err != nil {
+		return nil, err
+	}
+
+	events, err := ss.busyEvents(ctx, req.Start, req.End, req.CalendarIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FreeBusy{
+		Start: req.Start,
+		End:   req.End,
+		Busy:  mergeBusy(filterBusy(events, req.Attendees, req.CalendarIDs), req.Start, req.End),
+	}, nil
+}
+
+// SuggestSlots returns up to Limit free slots within the working hours,
+// starting on a 30 minute grid from the start of the working day. Slots
+// where fewer optional attendees are busy rank first, then earlier slots.
+func (ss *ScheduleService) SuggestSlots(ctx context.Context, req SuggestSlotsRequest) ([]*SlotSuggestion, error) {
+	if err := validateScheduleRange(req.Start, req.End); err != nil {
+		return nil, err
+	}
+	if req.Duration <= 0 || req.Duration > maxSlotDuration {
+		return nil, ErrInvalidSlotDuration
+	}
+
+	loc := timezone.FromContext(ctx)
+	if strings.TrimSpace(req.TimeZone) != "" {
+		var err error
+		if loc, err = timezone.Load(req.TimeZone); err != nil {
+			return nil, ErrInvalidScheduleTimeZone
+		}
+	}
+
+	dayStart, dayEnd, days, err := parseWorkingHours(req.WorkingHours)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSlotLimit
+	}
+	if limit > maxSlotLimit {
+		limit = maxSlotLimit
+	}
+
+	// Slots in the past are of no use
+	start := req.Start
+	if now := time.Now(); start.Before(now) {
+		start = now
+	}
+	if !start.Before(req.End) {
+		return []*SlotSuggestion{}, nil
+	}
+
+	events, err := ss.busyEvents(ctx, start, req.End, req.CalendarIDs)
+	if err != nil {
+		return nil, err
+	}
+	busy := mergeBusy(filterBusy(events, req.Attendees, req.CalendarIDs), start, req.End)
+	optionalBusy := make(map[string][]BusyInterval, len(req.OptionalAttendees))
+	for _, attendee := range req.OptionalAttendees {
+		optionalBusy[attendee] = mergeBusy(filterBusy(events, []string{attendee}, nil), start, req.End)
+	}
+
+	slots := []*SlotSuggestion{}
+	for day := timezone.StartOfDay(start, loc); day.Before(req.End); day = day.AddDate(0, 0, 1) {
+		if !days[day.Weekday()] {
+			continue
+		}
+
+		// Working hours keep their wall-clock time across DST changes
+		opening := time.Date(day.Year(), day.Month(), day.Day(), 0, dayStart, 0, 0, loc)
+		closing := time.Date(day.Year(), day.Month(), day.Day(), 0, dayEnd, 0, 0, loc)
+		if closing.After(req.End) {
+			closing = req.End
+		}
+
+		slotStart := opening
+		if opening.Before(start) {
+			slotStart = opening.Add((start.Sub(opening) + slotStep - 1) / slotStep * slotStep)
+		}
+		for ; !slotStart.Add(req.Duration).After(closing); slotStart = slotStart.Add(slotStep) {
+			slotEnd := slotStart.Add(req.Duration)
+			if overlapsBusy(busy, slotStart, slotEnd) {
+				continue
+			}
+
+			slot := &SlotSuggestion{Start: slotStart, End: slotEnd, Unavailable: []string{}}
+			for _, attendee := range req.OptionalAttendees {
+				if overlapsBusy(optionalBusy[attendee], slotStart, slotEnd) {
+					slot.Unavailable = append(slot.Unavailable, attendee)
+				}
+			}
+			slots = append(slots, slot)
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return len(slots[i].Unavailable) < len(slots[j].Unavailable)
+	})
+	if len(slots) > limit {
+		slots = slots[:limit]
+	}
+
+	return slots, nil
+}
+
+// busyEvents returns the events, and occurrences of recurring series,
+// overlapping [start, end), after checking that the calendars exist
+func (ss *ScheduleService) busyEvents(ctx context.Context, start, end time.Time, calendarIDs []int) ([]*models.Event, error) {
+	for _, id := range calendarIDs {
+		if _, err := ss.calendarRepo.GetCalendarByID(ctx, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrCalendarNotFound
+			}
+			return nil, fmt.Errorf("failed to get calendar: %w", err)
+		}
+	}
+
+	events, err := ss.eventService.CheckTimeConflicts(ctx, start, end, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get busy events: %w", err)
+	}
+
+	return events, nil
+}
+
+// filterBusy returns the events attended by one of the attendees without
+// having declined, or belonging to one of the calendars. Every event is
+// returned when neither is given.
+func filterBusy(events []*models.Event, attendees []string, calendarIDs []int) []*models.Event {
+	if len(attendees) == 0 && len(calendarIDs) == 0 {
+		return events
+	}
+
+	var busy []*models.Event
+	for _, event := range events {
+		if attendedByAny(event, attendees) || inCalendars(event, calendarIDs) {
+			busy = append(busy, event)
+		}
+	}
+	return busy
+}
+
+// inCalendars reports whether the event belongs to one of the calendars
+func inCalendars(event *models.Event, calendarIDs []int) bool {
+	if event.CalendarID == nil {
+		return false
+	}
+	for _, id := range calendarIDs {
+		if *event.CalendarID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeBusy returns the periods taken by the events within [start, end),
+// merging overlapping and adjacent events
+func mergeBusy(events []*models.Event, start, end time.Time) []BusyInterval {
+	intervals := make([]BusyInterval, 0, len(events))
+	for _, event := range events {
+		if !rangesOverlap(event.StartTime, event.EndTime, start, end) {
+			continue
+		}
+		interval := BusyInterval{Start: event.StartTime, End: event.EndTime}
+		if interval.Start.Before(start) {
+			interval.Start = start
+		}
+		if interval.End.After(end) {
+			interval.End = end
+		}
+		intervals = append(intervals, interval)
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+
+	merged := make([]BusyInterval, 0, len(intervals))
+	for _, interval := range intervals {
+		if last := len(merged) - 1; last >= 0 && !interval.Start.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// overlapsBusy reports whether [start, end) overlaps one of the busy
+// intervals
+func overlapsBusy(busy []BusyInterval, start, end time.Time) bool {
+	for _, interval := range busy {
+		if rangesOverlap(interval.Start, interval.End, start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateScheduleRange checks that a range is not empty nor too long
+func validateScheduleRange(start, end time.Time) error {
+	if !end.After(start) || end.Sub(start) > maxScheduleRange {
+		return ErrInvalidScheduleRange
+	}
+	return nil
+}
+
+// parseWorkingHours returns the start and end of the working day in
+// minutes after midnight, and the working days, applying the defaults
+func parseWorkingHours(hours WorkingHours) (int, int, map[time.Weekday]bool, error) {
+	if hours.Start == "" {
+		hours.Start = defaultWorkdayStart
+	}
+	if hours.End == "" {
+		hours.End = defaultWorkdayEnd
+	}
+	if len(hours.Days) == 0 {
+		hours.Days = defaultWorkingDays
+	}
+
+	start, err := parseClock(hours.Start)
+	if err != nil {
+		return 0, 0, nil, ErrInvalidWorkingHours
+	}
+	end, err := parseClock(hours.End)
+	if err != nil || end <= start {
+		return 0, 0, nil, ErrInvalidWorkingHours
+	}
+
+	days := make(map[time.Weekday]bool, len(hours.Days))
+	for _, code := range hours.Days {
+		day, ok := recurrence.ParseWeekday(code)
+		if !ok {
+			return 0, 0, nil, ErrInvalidWorkingDay
+		}
+		days[day] = true
+	}
+
+	return start, end, days, nil
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
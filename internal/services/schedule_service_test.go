@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleService_GetFreeBusy(t *testing.T) {
+	eventService, mockRepo := createTestEventService()
+	calendarRepo := NewMockCalendarRepository()
+	service := NewScheduleService(eventService, calendarRepo)
+	ctx := context.Background()
+
+	room, err := calendarRepo.CreateCalendar(ctx, &models.Calendar{Name: "Room"})
+	require.NoError(t, err)
+
+	// Monday 3 June 2030
+	day := time.Date(2030, 6, 3, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{
+		{ID: 1, StartTime: at(8, 0), EndTime: at(10, 0), Attendees: []models.Attendee{{Email: "ana@example.com", Status: models.RSVPAccepted}}},
+		{ID: 2, StartTime: at(9, 30), EndTime: at(11, 0), CalendarID: &room.ID},
+		{ID: 3, StartTime: at(11, 0), EndTime: at(12, 0)},
+		{ID: 4, StartTime: at(14, 0), EndTime: at(15, 0), Attendees: []models.Attendee{{Email: "ana@example.com", Status: models.RSVPDeclined}}},
+	}, nil)
+
+	t.Run("every event", func(t *testing.T) {
+		freeBusy, err := service.GetFreeBusy(ctx, FreeBusyRequest{Start: at(9, 0), End: at(18, 0)})
+		require.NoError(t, err)
+		assert.Equal(t, []BusyInterval{
+			{Start: at(9, 0), End: at(12, 0)},
+			{Start: at(14, 0), End: at(15, 0)},
+		}, freeBusy.Busy)
+	})
+
+	t.Run("attendees and calendars", func(t *testing.T) {
+		freeBusy, err := service.GetFreeBusy(ctx, FreeBusyRequest{Start: at(0, 0), End: at(24, 0), Attendees: []string{"ANA@example.com"}})
+		require.NoError(t, err)
+		assert.Equal(t, []BusyInterval{{Start: at(8, 0), End: at(10, 0)}}, freeBusy.Busy)
+
+		freeBusy, err = service.GetFreeBusy(ctx, FreeBusyRequest{Start: at(0, 0), End: at(24, 0), CalendarIDs: []int{room.ID}})
+		require.NoError(t, err)
+		assert.Equal(t, []BusyInterval{{Start: at(9, 30), End: at(11, 0)}}, freeBusy.Busy)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := service.GetFreeBusy(ctx, FreeBusyRequest{Start: at(9, 0), End: at(9, 0)})
+		assert.Equal(t, ErrInvalidScheduleRange, err)
+
+		_, err = service.GetFreeBusy(ctx, FreeBusyRequest{Start: day, End: day.AddDate(0, 0, 32)})
+		assert.Equal(t, ErrInvalidScheduleRange, err)
+
+		_, err = service.GetFreeBusy(ctx, FreeBusyRequest{Start: at(9, 0), End: at(10, 0), CalendarIDs: []int{999}})
+		assert.Equal(t, ErrCalendarNotFound, err)
+	})
+}
+
+func TestScheduleService_SuggestSlots(t *testing.T) {
+	eventService, mockRepo := createTestEventService()
+	service := NewScheduleService(eventService, NewMockCalendarRepository())
+	ctx := context.Background()
+
+	// Monday 3 June 2030
+	day := time.Date(2030, 6, 3, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	attending := func(email string) []models.Attendee {
+		return []models.Attendee{{Email: email, Status: models.RSVPAccepted}}
+	}
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{
+		{ID: 1, StartTime: at(9, 0), EndTime: at(10, 15), Attendees: attending("ana@example.com")},
+		{ID: 2, StartTime: at(10, 30), EndTime: at(11, 0), Attendees: attending("bo@example.com")},
+		{ID: 3, StartTime: at(12, 0), EndTime: at(17, 0), Attendees: attending("ana@example.com")},
+	}, nil)
+
+	t.Run("free slots within working hours", func(t *testing.T) {
+		slots, err := service.SuggestSlots(ctx, SuggestSlotsRequest{
+			Duration:  time.Hour,
+			Start:     day,
+			End:       day.AddDate(0, 0, 1),
+			TimeZone:  "UTC",
+			Attendees: []string{"ana@example.com"},
+		})
+		require.NoError(t, err)
+		require.Len(t, slots, 2)
+		assert.Equal(t, at(10, 30), slots[0].Start)
+		assert.Equal(t, at(11, 30), slots[0].End)
+		assert.Empty(t, slots[0].Unavailable)
+		assert.Equal(t, at(11, 0), slots[1].Start)
+	})
+
+	t.Run("optional attendees rank slots", func(t *testing.T) {
+		slots, err := service.SuggestSlots(ctx, SuggestSlotsRequest{
+			Duration:          30 * time.Minute,
+			Start:             day,
+			End:               day.AddDate(0, 0, 1),
+			WorkingHours:      WorkingHours{Start: "10:00", End: "12:00"},
+			TimeZone:          "UTC",
+			Attendees:         []string{"ana@example.com"},
+			OptionalAttendees: []string{"bo@example.com"},
+			Limit:             2,
+		})
+		require.NoError(t, err)
+		require.Len(t, slots, 2)
+		assert.Equal(t, at(11, 0), slots[0].Start)
+		assert.Equal(t, at(11, 30), slots[1].Start)
+	})
+
+	t.Run("working days", func(t *testing.T) {
+		// Saturday and Sunday only
+		slots, err := service.SuggestSlots(ctx, SuggestSlotsRequest{
+			Duration:     time.Hour,
+			Start:        day.AddDate(0, 0, -2),
+			End:          day.AddDate(0, 0, 1),
+			WorkingHours: WorkingHours{Days: []string{"sa", "SU"}},
+			TimeZone:     "UTC",
+			Limit:        50,
+		})
+		require.NoError(t, err)
+		require.Len(t, slots, 30)
+		assert.Equal(t, day.AddDate(0, 0, -2).Add(9*time.Hour), slots[0].Start)
+		for _, slot := range slots {
+			assert.True(t, slot.Start.Before(day))
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		valid := SuggestSlotsRequest{Duration: time.Hour, Start: day, End: day.AddDate(0, 0, 1)}
+
+		req := valid
+		req.Duration = 0
+		_, err := service.SuggestSlots(ctx, req)
+		assert.Equal(t, ErrInvalidSlotDuration, err)
+
+		req = valid
+		req.WorkingHours = WorkingHours{Start: "17:00", End: "09:00"}
+		_, err = service.SuggestSlots(ctx, req)
+		assert.Equal(t, ErrInvalidWorkingHours, err)
+
+		req = valid
+		req.WorkingHours = WorkingHours{Days: []string{"Monday"}}
+		_, err = service.SuggestSlots(ctx, req)
+		assert.Equal(t, ErrInvalidWorkingDay, err)
+
+		req = valid
+		req.TimeZone = "Mars/Olympus"
+		_, err = service.SuggestSlots(ctx, req)
+		assert.Equal(t, ErrInvalidScheduleTimeZone, err)
+	})
+}
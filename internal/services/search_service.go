@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+)
+
+// SearchServiceInterface defines the contract for full-text search across
+// tasks and events
+type SearchServiceInterface interface {
+	Search(ctx context.Context, req SearchRequest) ([]*models.SearchResult, error)
+}
+
+// SearchService implements SearchServiceInterface
+type SearchService struct {
+	searchRepo database.SearchRepositoryInterface
+	taskRepo   database.TaskRepositoryInterface
+	eventRepo  database.EventRepositoryInterface
+}
+
+// NewSearchService creates a new search service instance
+func NewSearchService(searchRepo database.SearchRepositoryInterface, taskRepo database.TaskRepositoryInterface, eventRepo database.EventRepositoryInterface) SearchServiceInterface {
+	return &SearchService{
+		searchRepo: searchRepo,
+		taskRepo:   taskRepo,
+		eventRepo:  eventRepo,
+	}
+}
+
+// SearchRequest represents a full-text search. Query supports "quoted
+// phrases", prefix* terms and the AND, OR and NOT operators.
+type SearchRequest struct {
+	Query string `json:"q"`
+	// Type restricts the results to models.SearchTypeTask or
+	// models.SearchTypeEvent; both are searched when empty
+	Type  string `json:"type"`
+	Limit int    `json:"limit"`
+}
+
+// Search limits
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchLength    = 255
+)
+
+// Search errors
+var (
+	ErrSearchQueryRequired = errors.New("search query is required")
+	ErrSearchQueryTooLong  = errors.New("search query cannot exceed 255 characters")
+	ErrInvalidSearchQuery  = errors.New("invalid search query")
+	ErrInvalidSearchType   = errors.New("search type must be task or event")
+)
+
+// Search returns the tasks and events matching req, best match first
+func (ss *SearchService) Search(ctx context.Context, req SearchRequest) ([]*models.SearchResult, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, ErrSearchQueryRequired
+	}
+	if len(query) > maxSearchLength {
+		return nil, ErrSearchQueryTooLong
+	}
+	if req.Type != "" && req.Type != models.SearchTypeTask && req.Type != models.SearchTypeEvent {
+		return nil, ErrInvalidSearchType
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	var results []*models.SearchResult
+	if req.Type != models.SearchTypeEvent {
+		tasks, err := ss.searchRepo.SearchTasks(ctx, query, limit)
+		if err != nil {
+			return nil, ss.searchError(err)
+		}
+		results = append(results, tasks...)
+	}
+	if req.Type != models.SearchTypeTask {
+		events, err := ss.searchRepo.SearchEvents(ctx, query, limit)
+		if err != nil {
+			return nil, ss.searchError(err)
+		}
+		results = append(results, events...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	for _, result := range results {
+		if err := ss.loadItem(ctx, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// loadItem attaches the task or event matched by result
+func (ss *SearchService) loadItem(ctx context.Context, result *models.SearchResult) error {
+	var err error
+	switch result.Type {
+	case models.SearchTypeTask:
+		result.Task, err = ss.taskRepo.GetTaskByID(ctx, result.ID)
+	case models.SearchTypeEvent:
+		result.Event, err = ss.eventRepo.GetEventByID(ctx, result.ID)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to load search result: %w", err)
+	}
+	return nil
+}
+
+// searchError maps repository search errors to service errors
+func (ss *SearchService) searchError(err error) error {
+	if errors.Is(err, database.ErrInvalidSearchQuery) {
+		return ErrInvalidSearchQuery
+	}
+	return fmt.Errorf("failed to search: %w", err)
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSearchRepository implements SearchRepositoryInterface for testing
+type MockSearchRepository struct {
+	tasks  []*models.SearchResult
+	events []*models.SearchResult
+	err    error
+	limits []int
+}
+
+func (m *MockSearchRepository) SearchTasks(ctx context.Context, query string, limit int) ([]*models.SearchResult, error) {
+	m.limits = append(m.limits, limit)
+	return m.tasks, m.err
+}
+
+func (m *MockSearchRepository) SearchEvents(ctx context.Context, query string, limit int) ([]*models.SearchResult, error) {
+	m.limits = append(m.limits, limit)
+	return m.events, m.err
+}
+
+func TestSearchService_Search(t *testing.T) {
+	taskRepo := NewMockTaskRepository()
+	task, err := taskRepo.CreateTask(context.Background(), &models.Task{Title: "Buy groceries"})
+	require.NoError(t, err)
+
+	eventRepo := new(MockEventRepository)
+	event := &models.Event{ID: 7, Title: "Groceries run"}
+	eventRepo.On("GetEventByID", context.Background(), 7).Return(event, nil)
+
+	searchRepo := &MockSearchRepository{
+		tasks: []*models.SearchResult{
+			{Type: models.SearchTypeTask, ID: task.ID, Score: 1.5},
+		},
+		events: []*models.SearchResult{
+			{Type: models.SearchTypeEvent, ID: 7, Score: 2.5},
+		},
+	}
+	service := NewSearchService(searchRepo, taskRepo, eventRepo)
+
+	t.Run("merges tasks and events by score", func(t *testing.T) {
+		results, err := service.Search(context.Background(), SearchRequest{Query: " groceries "})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, event, results[0].Event)
+		assert.Equal(t, task, results[1].Task)
+		assert.Equal(t, []int{defaultSearchLimit, defaultSearchLimit}, searchRepo.limits)
+	})
+
+	t.Run("filters by type and caps the limit", func(t *testing.T) {
+		searchRepo.limits = nil
+		results, err := service.Search(context.Background(), SearchRequest{Query: "groceries", Type: models.SearchTypeTask, Limit: 1000})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, models.SearchTypeTask, results[0].Type)
+		assert.Equal(t, []int{maxSearchLimit}, searchRepo.limits)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := service.Search(context.Background(), SearchRequest{Query: "  "})
+		assert.Equal(t, ErrSearchQueryRequired, err)
+
+		_, err = service.Search(context.Background(), SearchRequest{Query: "groceries", Type: "note"})
+		assert.Equal(t, ErrInvalidSearchType, err)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		searchRepo.err = database.ErrInvalidSearchQuery
+		_, err := service.Search(context.Background(), SearchRequest{Query: `"groceries`})
+		assert.Equal(t, ErrInvalidSearchQuery, err)
+	})
+}
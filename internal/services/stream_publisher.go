@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"agenda/internal/auth"
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/stream"
+)
+
+// StreamPublisher publishes the changes made to tasks and events to the
+// live streams of their owner
+type StreamPublisher struct {
+	hub *stream.Hub
+}
+
+// NewStreamPublisher creates a publisher sending changes to the streams of
+// the hub
+func NewStreamPublisher(hub *stream.Hub) ChangePublisher {
+	return &StreamPublisher{
+		hub: hub,
+	}
+}
+
+// Publish sends the change to the hub once the transaction making it
+// commits, so streams never see changes that were rolled back. The task or
+// event is encoded right away, as it may be modified before the commit.
+func (sp *StreamPublisher) Publish(ctx context.Context, change Change) error {
+	data, err := json.Marshal(change.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode stream message: %w", err)
+	}
+
+	msg := stream.Message{
+		Type:       change.Type,
+		OccurredAt: change.OccurredAt.UTC(),
+		Data:       data,
+		Periods:    append(changePeriods(change.Previous), changePeriods(change.Data)...),
+	}
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		msg.Owner = &userID
+	}
+
+	database.AfterCommit(ctx, func() {
+		sp.hub.Publish(msg)
+	})
+	return nil
+}
+
+// changePeriods returns the period a task or event takes place in. Tasks
+// take place on their due date, and recurring series from their first
+// occurrence on.
+func changePeriods(item interface{}) []stream.Period {
+	switch item := item.(type) {
+	case *models.Task:
+		if item == nil || item.DueDate == nil {
+			return nil
+		}
+		period := stream.Period{Start: *item.DueDate, End: *item.DueDate}
+		if item.IsRecurring() {
+			period.End = time.Time{}
+		}
+		return []stream.Period{period}
+	case *models.Event:
+		if item == nil {
+			return nil
+		}
+		period := stream.Period{Start: item.StartTime, End: item.EndTime}
+		if item.IsRecurring() {
+			period.End = time.Time{}
+		}
+		return []stream.Period{period}
+	}
+	return nil
+}
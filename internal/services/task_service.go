@@ -10,6 +10,8 @@ import (
 
 	"agenda/internal/database"
 	"agenda/internal/models"
+	"agenda/internal/recurrence"
+	"agenda/internal/timezone"
 )
 
 // TaskServiceInterface defines the contract for task business logic operations
@@ -25,21 +27,39 @@ type TaskServiceInterface interface {
 	ReopenTask(ctx context.Context, id int) (*models.Task, error)
 	
 	// Query operations
-	ListTasks(ctx context.Context, filters TaskListFilters) ([]*models.Task, int64, error)
+	ListTasks(ctx context.Context, filters TaskListFilters) ([]*models.Task, PageInfo, error)
 	GetOverdueTasks(ctx context.Context) ([]*models.Task, error)
 	GetTasksByStatus(ctx context.Context, status string) ([]*models.Task, error)
 	GetUpcomingTasks(ctx context.Context, days int) ([]*models.Task, error)
+
+	// Hierarchy and dependency operations
+	GetSubtasks(ctx context.Context, id int) ([]*models.Task, error)
+	GetDependencyTree(ctx context.Context, id int) (*TaskDependencyNode, error)
+
+	// Recurrence operations
+	GetCompletedInstances(ctx context.Context, id int) ([]*models.Task, error)
+
+	// Trash operations
+	RestoreTask(ctx context.Context, id int) (*models.Task, error)
+
+	// Bulk operations
+	BulkTasks(ctx context.Context, req BulkTaskRequest) ([]*BulkResult, error)
 }
 
 // TaskService implements TaskServiceInterface
 type TaskService struct {
-	taskRepo database.TaskRepositoryInterface
+	taskRepo   database.TaskRepositoryInterface
+	transactor database.Transactor
+	publishers changePublishers
 }
 
-// NewTaskService creates a new task service instance
-func NewTaskService(taskRepo database.TaskRepositoryInterface) TaskServiceInterface {
+// NewTaskService creates a new task service instance. Changes to tasks are
+// published to publishers in the transaction making them.
+func NewTaskService(taskRepo database.TaskRepositoryInterface, transactor database.Transactor, publishers ...ChangePublisher) TaskServiceInterface {
 	return &TaskService{
-		taskRepo: taskRepo,
+		taskRepo:   taskRepo,
+		transactor: transactor,
+		publishers: publishers,
 	}
 }
 
@@ -48,6 +68,15 @@ type CreateTaskRequest struct {
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	DueDate     *time.Time `json:"due_date"`
+	Priority    string     `json:"priority"`
+	Tags        []string   `json:"tags"`
+	ParentID    *int       `json:"parent_id"`
+	BlockedBy   []int      `json:"blocked_by"`
+
+	// Recurring tasks repeat either on a fixed schedule or a number of
+	// days after each completion
+	RecurrenceRule string `json:"recurrence_rule"`
+	RecurAfterDays int    `json:"recur_after_days"`
 }
 
 // UpdateTaskRequest represents the request to update an existing task
@@ -56,18 +85,96 @@ type UpdateTaskRequest struct {
 	Description *string    `json:"description"`
 	DueDate     *time.Time `json:"due_date"`
 	Status      *string    `json:"status"`
+	Priority    *string    `json:"priority"`
+	Tags        *[]string  `json:"tags"`
+	ParentID    *int       `json:"parent_id"` // 0 turns a subtask into a top-level task
+	BlockedBy   *[]int     `json:"blocked_by"`
+
+	// An empty rule and zero days stop the task from recurring
+	RecurrenceRule *string `json:"recurrence_rule"`
+	RecurAfterDays *int    `json:"recur_after_days"`
+
+	// ClearDueDate removes the due date of the task, ignoring DueDate
+	ClearDueDate bool `json:"-"`
+
+	// Version, when set, is the version of the task the update was made
+	// from; the update fails with ErrVersionMismatch when it is stale
+	Version *int `json:"-"`
+}
+
+// BulkTaskOperation is an operation of a bulk task request: create,
+// update, delete or complete. Create takes Create, update takes ID and
+// Update, and the others take ID.
+type BulkTaskOperation struct {
+	Op     string
+	ID     int
+	Create *CreateTaskRequest
+	Update *UpdateTaskRequest
+}
+
+// TaskSelection selects the tasks a bulk request applies to. Overdue
+// selects the pending tasks past their due date.
+type TaskSelection struct {
+	Status    string
+	Priority  string
+	DueAfter  *time.Time
+	DueBefore *time.Time
+	Overdue   bool
+	Search    string
+	Tags      []string
+	TagMatch  string // "any" (default) or "all"
+}
+
+// BulkTaskRequest represents a bulk request, made of either a list of
+// operations or a selection of tasks the operation Op, with Update for
+// updates, applies to. Mode defaults to BulkAtomic.
+type BulkTaskRequest struct {
+	Mode       string
+	Operations []BulkTaskOperation
+
+	Select *TaskSelection
+	Op     string
+	Update *UpdateTaskRequest
 }
 
 // TaskListFilters represents filtering options for listing tasks
 type TaskListFilters struct {
 	Status    string
+	Priority  string
 	DueAfter  *time.Time
 	DueBefore *time.Time
 	Search    string
+	Tags      []string
+	TagMatch  string // "any" (default) or "all"
+	SortBy    string // created_at (default), updated_at, due_date, priority or title
+	SortOrder string // "asc" or "desc"; defaults depend on SortBy
 	Page      int
 	PageSize  int
+	Cursor    string // cursor of a previous page; replaces Page
+	SkipTotal bool   // skips counting the matching tasks
 }
 
+// Tag matching modes for TaskListFilters
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// Sort orders for list filters
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// Tag limits
+const (
+	maxTaskTags      = 20
+	maxTaskTagLength = 50
+)
+
+// maxRecurAfterDays bounds the delay of tasks repeating after completion
+const maxRecurAfterDays = 3650
+
 // Validation errors
 var (
 	ErrTaskTitleRequired    = errors.New("task title is required")
@@ -78,12 +185,44 @@ var (
 	ErrDueDateInPast        = errors.New("due date cannot be in the past")
 	ErrTaskAlreadyCompleted = errors.New("task is already completed")
 	ErrTaskAlreadyPending   = errors.New("task is already pending")
+	ErrInvalidTaskPriority  = errors.New("invalid task priority")
+	ErrInvalidTaskTag       = errors.New("task tags must be between 1 and 50 characters")
+	ErrTooManyTaskTags      = errors.New("a task cannot have more than 20 tags")
+	ErrInvalidTagMatch      = errors.New("tag match must be 'any' or 'all'")
+	ErrInvalidTaskSort      = errors.New("invalid task sort field")
+	ErrInvalidSortOrder     = errors.New("sort order must be 'asc' or 'desc'")
+	ErrParentTaskNotFound   = errors.New("parent task not found")
+	ErrTaskHierarchyCycle   = errors.New("a task cannot be a subtask of itself or of its own subtasks")
+	ErrBlockingTaskNotFound = errors.New("blocking task not found")
+	ErrDependencyCycle      = errors.New("task dependencies cannot form a cycle")
+	ErrInvalidRecurAfterDays     = errors.New("recur_after_days must be between 0 and 3650")
+	ErrConflictingRecurrence     = errors.New("a task cannot repeat both on a schedule and after completion")
+	ErrRecurrenceRequiresDueDate = errors.New("tasks repeating on a schedule need a due date")
+	ErrTaskNotDeleted            = errors.New("task is not in the trash")
 )
 
+// TaskBlockedError is returned when completing a task that is blocked by
+// tasks which are still pending
+type TaskBlockedError struct {
+	TaskID    int
+	BlockedBy []int // IDs of the pending blockers
+}
+
+func (e *TaskBlockedError) Error() string {
+	return fmt.Sprintf("task %d is blocked by pending tasks %v", e.TaskID, e.BlockedBy)
+}
+
+// TaskDependencyNode is a task in a dependency tree, with the tasks blocking
+// it as children
+type TaskDependencyNode struct {
+	Task      *models.Task          `json:"task"`
+	BlockedBy []*TaskDependencyNode `json:"blocked_by"`
+}
+
 // CreateTask creates a new task with validation
 func (ts *TaskService) CreateTask(ctx context.Context, req CreateTaskRequest) (*models.Task, error) {
 	// Validate request
-	if err := ts.validateCreateTaskRequest(req); err != nil {
+	if err := ts.validateCreateTaskRequest(ctx, req); err != nil {
 		return nil, err
 	}
 
@@ -93,10 +232,31 @@ func (ts *TaskService) CreateTask(ctx context.Context, req CreateTaskRequest) (*
 		Description: strings.TrimSpace(req.Description),
 		DueDate:     req.DueDate,
 		Status:      models.TaskStatusPending,
+		Priority:    req.Priority,
+		Tags:        normalizeTags(req.Tags),
+		ParentID:    req.ParentID,
+		BlockedBy:   normalizeTaskIDs(req.BlockedBy),
+
+		RecurrenceRule: strings.TrimSpace(req.RecurrenceRule),
+		RecurAfterDays: req.RecurAfterDays,
+	}
+
+	if err := validateRecurrence(task); err != nil {
+		return nil, err
+	}
+
+	// Check the hierarchy and dependencies of the new task
+	if task.ParentID != nil {
+		if err := ts.checkParent(ctx, 0, *task.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	if err := ts.checkBlockers(ctx, 0, task.BlockedBy); err != nil {
+		return nil, err
 	}
 
 	// Create task in repository
-	createdTask, err := ts.taskRepo.CreateTask(ctx, task)
+	createdTask, err := ts.createTask(ctx, task)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
@@ -104,6 +264,36 @@ func (ts *TaskService) CreateTask(ctx context.Context, req CreateTaskRequest) (*
 	return createdTask, nil
 }
 
+// createTask stores a new task and publishes its creation in the same
+// transaction
+func (ts *TaskService) createTask(ctx context.Context, task *models.Task) (*models.Task, error) {
+	var createdTask *models.Task
+	err := ts.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		createdTask, err = ts.taskRepo.CreateTask(ctx, task)
+		if err != nil {
+			return err
+		}
+		return ts.publishers.publish(ctx, ChangeTaskCreated, createdTask)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createdTask, nil
+}
+
+// updateTask stores an updated task and publishes the change from previous
+// in the same transaction
+func (ts *TaskService) updateTask(ctx context.Context, changeType string, previous, task *models.Task) error {
+	return ts.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := ts.taskRepo.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		return ts.publishers.publishUpdate(ctx, changeType, previous, task)
+	})
+}
+
 // GetTaskByID retrieves a task by its ID
 func (ts *TaskService) GetTaskByID(ctx context.Context, id int) (*models.Task, error) {
 	if id <= 0 {
@@ -135,9 +325,12 @@ func (ts *TaskService) UpdateTask(ctx context.Context, id int, req UpdateTaskReq
 		}
 		return nil, fmt.Errorf("failed to get existing task: %w", err)
 	}
+	if err := checkVersion(req.Version, existingTask.Version); err != nil {
+		return nil, err
+	}
 
 	// Validate update request
-	if err := ts.validateUpdateTaskRequest(req); err != nil {
+	if err := ts.validateUpdateTaskRequest(ctx, req); err != nil {
 		return nil, err
 	}
 
@@ -149,15 +342,73 @@ func (ts *TaskService) UpdateTask(ctx context.Context, id int, req UpdateTaskReq
 	if req.Description != nil {
 		updatedTask.Description = strings.TrimSpace(*req.Description)
 	}
-	if req.DueDate != nil {
+	if req.ClearDueDate {
+		updatedTask.DueDate = nil
+	} else if req.DueDate != nil {
 		updatedTask.DueDate = req.DueDate
 	}
 	if req.Status != nil {
 		updatedTask.Status = *req.Status
 	}
+	if req.Priority != nil {
+		updatedTask.Priority = *req.Priority
+	}
+	if req.Tags != nil {
+		updatedTask.Tags = normalizeTags(*req.Tags)
+	}
+	if req.ParentID != nil {
+		updatedTask.ParentID = nil
+		if *req.ParentID != 0 {
+			if err := ts.checkParent(ctx, id, *req.ParentID); err != nil {
+				return nil, err
+			}
+			updatedTask.ParentID = req.ParentID
+		}
+	}
+	if req.BlockedBy != nil {
+		updatedTask.BlockedBy = normalizeTaskIDs(*req.BlockedBy)
+		if err := ts.checkBlockers(ctx, id, updatedTask.BlockedBy); err != nil {
+			return nil, err
+		}
+	}
+	if req.RecurrenceRule != nil {
+		updatedTask.RecurrenceRule = strings.TrimSpace(*req.RecurrenceRule)
+	}
+	if req.RecurAfterDays != nil {
+		updatedTask.RecurAfterDays = *req.RecurAfterDays
+	}
+	if err := validateRecurrence(&updatedTask); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case existingTask.Status != models.TaskStatusCompleted && updatedTask.Status == models.TaskStatusCompleted:
+		// Completing through an update is subject to the same dependencies
+		// and recurrence as CompleteTask
+		if err := ts.checkNotBlocked(ctx, &updatedTask); err != nil {
+			return nil, err
+		}
+		if err := ts.saveCompletion(ctx, existingTask, &updatedTask); err != nil {
+			if isVersionConflict(err) {
+				return nil, ErrVersionMismatch
+			}
+			return nil, fmt.Errorf("failed to update task: %w", err)
+		}
+		return &updatedTask, nil
+	case updatedTask.Status == models.TaskStatusPending:
+		updatedTask.CompletedAt = nil
+	}
+
+	changeType := ChangeTaskUpdated
+	if existingTask.Status == models.TaskStatusCompleted && updatedTask.Status == models.TaskStatusPending {
+		changeType = ChangeTaskReopened
+	}
 
 	// Update in repository
-	if err := ts.taskRepo.UpdateTask(ctx, &updatedTask); err != nil {
+	if err := ts.updateTask(ctx, changeType, existingTask, &updatedTask); err != nil {
+		if isVersionConflict(err) {
+			return nil, ErrVersionMismatch
+		}
 		return nil, fmt.Errorf("failed to update task: %w", err)
 	}
 
@@ -171,7 +422,7 @@ func (ts *TaskService) DeleteTask(ctx context.Context, id int) error {
 	}
 
 	// Check if task exists
-	_, err := ts.taskRepo.GetTaskByID(ctx, id)
+	task, err := ts.taskRepo.GetTaskByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrTaskNotFound
@@ -180,37 +431,234 @@ func (ts *TaskService) DeleteTask(ctx context.Context, id int) error {
 	}
 
 	// Delete task
-	if err := ts.taskRepo.DeleteTask(ctx, id); err != nil {
+	err = ts.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := ts.taskRepo.DeleteTask(ctx, id); err != nil {
+			return err
+		}
+		return ts.publishers.publish(ctx, ChangeTaskDeleted, task)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
 
 	return nil
 }
 
-// CompleteTask marks a task as completed
-func (ts *TaskService) CompleteTask(ctx context.Context, id int) (*models.Task, error) {
+// RestoreTask takes a deleted task out of the trash, together with its
+// subtasks and the dependencies on it.
+func (ts *TaskService) RestoreTask(ctx context.Context, id int) (*models.Task, error) {
 	if id <= 0 {
 		return nil, errors.New("invalid task ID")
 	}
 
-	// Get existing task
-	task, err := ts.taskRepo.GetTaskByID(ctx, id)
+	var task *models.Task
+	err := ts.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := ts.taskRepo.RestoreTask(ctx, id); err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrTaskNotFound
+			case errors.Is(err, database.ErrNotDeleted):
+				return ErrTaskNotDeleted
+			}
+			return fmt.Errorf("failed to restore task: %w", err)
+		}
+
+		var err error
+		task, err = ts.taskRepo.GetTaskByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get restored task: %w", err)
+		}
+		return ts.publishers.publish(ctx, ChangeTaskRestored, task)
+	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrTaskNotFound
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// BulkTasks applies the operations of a bulk request, or its operation to
+// every selected task, and returns the outcome of each
+func (ts *TaskService) BulkTasks(ctx context.Context, req BulkTaskRequest) ([]*BulkResult, error) {
+	if err := validateBulkMode(&req.Mode); err != nil {
+		return nil, err
+	}
+
+	operations := req.Operations
+	if req.Select != nil {
+		if len(operations) > 0 {
+			return nil, ErrInvalidBulkRequest
 		}
-		return nil, fmt.Errorf("failed to get task: %w", err)
+		if req.Op == BulkCreate || !isTaskBulkOp(req.Op) {
+			return nil, ErrInvalidBulkOperation
+		}
+
+		tasks, err := ts.selectTasks(ctx, *req.Select)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			operations = append(operations, BulkTaskOperation{Op: req.Op, ID: task.ID, Update: req.Update})
+		}
+	} else if len(operations) == 0 {
+		return nil, ErrInvalidBulkRequest
+	}
+	if len(operations) > maxBulkItems {
+		return nil, ErrBulkTooLarge
 	}
 
-	// Check if already completed
-	if task.Status == models.TaskStatusCompleted {
-		return nil, ErrTaskAlreadyCompleted
+	results := make([]*BulkResult, len(operations))
+	for i, op := range operations {
+		results[i] = &BulkResult{Index: i, Op: op.Op, ID: op.ID}
 	}
 
-	// Update status
-	task.Status = models.TaskStatusCompleted
-	if err := ts.taskRepo.UpdateTask(ctx, task); err != nil {
-		return nil, fmt.Errorf("failed to complete task: %w", err)
+	err := runBulk(ctx, ts.transactor, req.Mode, results, func(ctx context.Context, result *BulkResult) error {
+		op := operations[result.Index]
+		if !isTaskBulkOp(op.Op) {
+			return ErrInvalidBulkOperation
+		}
+		if op.Op != BulkCreate && op.ID <= 0 {
+			return ErrBulkIDRequired
+		}
+
+		var task *models.Task
+		var err error
+		switch op.Op {
+		case BulkCreate:
+			if op.Create == nil {
+				return ErrBulkDataRequired
+			}
+			task, err = ts.CreateTask(ctx, *op.Create)
+		case BulkUpdate:
+			if op.Update == nil {
+				return ErrBulkDataRequired
+			}
+			task, err = ts.UpdateTask(ctx, op.ID, *op.Update)
+		case BulkDelete:
+			err = ts.DeleteTask(ctx, op.ID)
+		case BulkComplete:
+			task, err = ts.CompleteTask(ctx, op.ID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if task != nil {
+			result.ID, result.Item = task.ID, task
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// selectTasks returns the tasks matching a bulk selection, oldest first
+func (ts *TaskService) selectTasks(ctx context.Context, selection TaskSelection) ([]*models.Task, error) {
+	if selection.Status == "" && selection.Priority == "" && selection.DueAfter == nil && selection.DueBefore == nil &&
+		!selection.Overdue && strings.TrimSpace(selection.Search) == "" && len(selection.Tags) == 0 {
+		return nil, ErrEmptyBulkSelection
+	}
+	if selection.Status != "" && selection.Status != models.TaskStatusPending && selection.Status != models.TaskStatusCompleted {
+		return nil, ErrInvalidTaskStatus
+	}
+
+	filters := TaskListFilters{
+		Status:    selection.Status,
+		Priority:  selection.Priority,
+		DueAfter:  selection.DueAfter,
+		DueBefore: selection.DueBefore,
+		Search:    selection.Search,
+		Tags:      selection.Tags,
+		TagMatch:  selection.TagMatch,
+		SortBy:    database.TaskSortCreatedAt,
+		SortOrder: SortAsc,
+	}
+	if selection.Overdue {
+		if filters.Status == models.TaskStatusCompleted {
+			return []*models.Task{}, nil
+		}
+		filters.Status = models.TaskStatusPending
+		if now := time.Now(); filters.DueBefore == nil || now.Before(*filters.DueBefore) {
+			filters.DueBefore = &now
+		}
+	}
+	if err := ts.validateTaskListFilters(&filters); err != nil {
+		return nil, err
+	}
+
+	// One more than the limit tells selections that are too large apart
+	tasks, err := ts.taskRepo.ListTasks(ctx, database.TaskFilters{
+		Status:       filters.Status,
+		Priority:     filters.Priority,
+		DueAfter:     filters.DueAfter,
+		DueBefore:    filters.DueBefore,
+		Search:       filters.Search,
+		Tags:         normalizeTags(filters.Tags),
+		MatchAllTags: filters.TagMatch == TagMatchAll,
+		SortBy:       filters.SortBy,
+		Limit:        maxBulkItems + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tasks: %w", err)
+	}
+	if len(tasks) > maxBulkItems {
+		return nil, ErrBulkTooLarge
+	}
+
+	return tasks, nil
+}
+
+// isTaskBulkOp reports whether op is an operation of bulk task requests
+func isTaskBulkOp(op string) bool {
+	switch op {
+	case BulkCreate, BulkUpdate, BulkDelete, BulkComplete:
+		return true
+	}
+	return false
+}
+
+// CompleteTask marks a task as completed. For recurring tasks, the next
+// instance is created in the same transaction.
+func (ts *TaskService) CompleteTask(ctx context.Context, id int) (*models.Task, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid task ID")
+	}
+
+	var task *models.Task
+	err := ts.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		// Get existing task
+		var err error
+		task, err = ts.taskRepo.GetTaskByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTaskNotFound
+			}
+			return fmt.Errorf("failed to get task: %w", err)
+		}
+
+		// Check if already completed
+		if task.Status == models.TaskStatusCompleted {
+			return ErrTaskAlreadyCompleted
+		}
+
+		// Blocked tasks cannot be completed before their blockers
+		if err := ts.checkNotBlocked(ctx, task); err != nil {
+			return err
+		}
+
+		// Update status
+		previous := *task
+		task.Status = models.TaskStatusCompleted
+		if err := ts.saveCompletion(ctx, &previous, task); err != nil {
+			return fmt.Errorf("failed to complete task: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return task, nil
@@ -237,16 +685,25 @@ func (ts *TaskService) ReopenTask(ctx context.Context, id int) (*models.Task, er
 	}
 
 	// Update status
+	previous := *task
 	task.Status = models.TaskStatusPending
-	if err := ts.taskRepo.UpdateTask(ctx, task); err != nil {
+	task.CompletedAt = nil
+	err = ts.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := ts.taskRepo.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		return ts.publishers.publishUpdate(ctx, ChangeTaskReopened, &previous, task)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to reopen task: %w", err)
 	}
 
 	return task, nil
 }
 
-// ListTasks retrieves tasks with filtering and pagination
-func (ts *TaskService) ListTasks(ctx context.Context, filters TaskListFilters) ([]*models.Task, int64, error) {
+// ListTasks retrieves tasks with filtering and pagination. Pages are
+// selected by Cursor when set, by Page otherwise.
+func (ts *TaskService) ListTasks(ctx context.Context, filters TaskListFilters) ([]*models.Task, PageInfo, error) {
 	// Set default pagination
 	if filters.PageSize <= 0 {
 		filters.PageSize = 20
@@ -258,28 +715,46 @@ func (ts *TaskService) ListTasks(ctx context.Context, filters TaskListFilters) (
 		filters.Page = 1
 	}
 
+	if err := ts.validateTaskListFilters(&filters); err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	cursor, err := decodeCursor(filters.Cursor, filters.SortBy, filters.SortOrder == SortDesc)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
 	// Convert to repository filters
 	repoFilters := database.TaskFilters{
-		Status:    filters.Status,
-		DueAfter:  filters.DueAfter,
-		DueBefore: filters.DueBefore,
-		Search:    filters.Search,
-		Limit:     filters.PageSize,
-		Offset:    (filters.Page - 1) * filters.PageSize,
+		Status:       filters.Status,
+		Priority:     filters.Priority,
+		DueAfter:     filters.DueAfter,
+		DueBefore:    filters.DueBefore,
+		Search:       filters.Search,
+		Tags:         normalizeTags(filters.Tags),
+		MatchAllTags: filters.TagMatch == TagMatchAll,
+		SortBy:       filters.SortBy,
+		SortDesc:     filters.SortOrder == SortDesc,
+		Limit:        filters.PageSize,
+		Offset:       (filters.Page - 1) * filters.PageSize,
+		Cursor:       cursor,
 	}
 
-	// Get tasks and total count
-	tasks, err := ts.taskRepo.ListTasks(ctx, repoFilters)
+	// Get the page of tasks and the total count
+	tasks, cursors, err := ts.taskRepo.ListTasksPage(ctx, repoFilters)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list tasks: %w", err)
+		return nil, PageInfo{}, fmt.Errorf("failed to list tasks: %w", err)
 	}
 
-	total, err := ts.taskRepo.CountTasks(ctx, repoFilters)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count tasks: %w", err)
+	var total int64
+	if !filters.SkipTotal {
+		total, err = ts.taskRepo.CountTasks(ctx, repoFilters)
+		if err != nil {
+			return nil, PageInfo{}, fmt.Errorf("failed to count tasks: %w", err)
+		}
 	}
 
-	return tasks, total, nil
+	return tasks, newPageInfo(total, cursors), nil
 }
 
 // GetOverdueTasks retrieves tasks that are overdue
@@ -324,8 +799,292 @@ func (ts *TaskService) GetUpcomingTasks(ctx context.Context, days int) ([]*model
 	return tasks, nil
 }
 
+// GetSubtasks retrieves the direct subtasks of a task
+func (ts *TaskService) GetSubtasks(ctx context.Context, id int) ([]*models.Task, error) {
+	if _, err := ts.GetTaskByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	tasks, err := ts.taskRepo.GetSubtasks(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// GetDependencyTree retrieves a task together with the tasks blocking it,
+// recursively
+func (ts *TaskService) GetDependencyTree(ctx context.Context, id int) (*TaskDependencyNode, error) {
+	task, err := ts.GetTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.buildDependencyTree(ctx, task, make(map[int]bool), make(map[int]*models.Task))
+}
+
+// buildDependencyTree builds the dependency tree below task. Tasks on the
+// current path are skipped so a cyclic graph cannot recurse forever, and
+// tasks blocking several others are only loaded once.
+func (ts *TaskService) buildDependencyTree(ctx context.Context, task *models.Task, path map[int]bool, loaded map[int]*models.Task) (*TaskDependencyNode, error) {
+	node := &TaskDependencyNode{Task: task, BlockedBy: []*TaskDependencyNode{}}
+
+	path[task.ID] = true
+	defer delete(path, task.ID)
+
+	for _, blockerID := range task.BlockedBy {
+		if path[blockerID] {
+			continue
+		}
+
+		blocker, ok := loaded[blockerID]
+		if !ok {
+			var err error
+			blocker, err = ts.taskRepo.GetTaskByID(ctx, blockerID)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get blocking task: %w", err)
+			}
+			loaded[blockerID] = blocker
+		}
+
+		child, err := ts.buildDependencyTree(ctx, blocker, path, loaded)
+		if err != nil {
+			return nil, err
+		}
+		node.BlockedBy = append(node.BlockedBy, child)
+	}
+
+	return node, nil
+}
+
+// GetCompletedInstances retrieves the completed instances of the recurring
+// task a task belongs to, most recently completed first
+func (ts *TaskService) GetCompletedInstances(ctx context.Context, id int) ([]*models.Task, error) {
+	task, err := ts.GetTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := ts.taskRepo.GetSeriesHistory(ctx, task.SeriesRootID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task history: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// saveCompletion stores a task that was just completed, previous being the
+// task before its completion. When the task recurs, its next instance is
+// created in the same transaction, so either both changes are saved or
+// neither is. The completion and the new instance are published in the
+// transaction as well.
+func (ts *TaskService) saveCompletion(ctx context.Context, previous, task *models.Task) error {
+	// The schedule is computed in the request time zone, so that the next
+	// instance keeps its wall-clock time across DST changes
+	now := timezone.Now(ctx)
+	task.CompletedAt = &now
+
+	next, err := nextTaskInstance(task, now)
+	if err != nil {
+		return err
+	}
+
+	return ts.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := ts.taskRepo.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		if err := ts.publishers.publishUpdate(ctx, ChangeTaskCompleted, previous, task); err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		if _, err := ts.taskRepo.CreateTask(ctx, next); err != nil {
+			return err
+		}
+		return ts.publishers.publish(ctx, ChangeTaskCreated, next)
+	})
+}
+
+// nextTaskInstance returns the instance following a recurring task that was
+// completed at completedAt, or nil when the task does not recur or its
+// schedule has ended. Days are counted in the location of completedAt.
+func nextTaskInstance(task *models.Task, completedAt time.Time) (*models.Task, error) {
+	if !task.IsRecurring() {
+		return nil, nil
+	}
+
+	seriesID := task.SeriesRootID()
+	next := &models.Task{
+		Title:          task.Title,
+		Description:    task.Description,
+		Status:         models.TaskStatusPending,
+		Priority:       task.Priority,
+		ParentID:       task.ParentID,
+		RecurrenceRule: task.RecurrenceRule,
+		RecurAfterDays: task.RecurAfterDays,
+		SeriesID:       &seriesID,
+		UserID:         task.UserID,
+		Tags:           append([]string(nil), task.Tags...),
+	}
+
+	if task.RecurAfterDays > 0 {
+		due := completedAt.AddDate(0, 0, task.RecurAfterDays)
+		next.DueDate = &due
+		return next, nil
+	}
+
+	// Fixed schedules keep to the rule, anchored at the current due date
+	rule, err := recurrence.Parse(task.RecurrenceRule)
+	if err != nil {
+		return nil, ErrInvalidRecurrenceRule
+	}
+	if task.DueDate == nil {
+		return nil, ErrRecurrenceRequiresDueDate
+	}
+
+	dtstart := task.DueDate.In(completedAt.Location())
+	due, ok := rule.After(dtstart, dtstart)
+	if !ok {
+		return nil, nil
+	}
+	next.DueDate = &due
+
+	// The next instance is anchored at its own due date, so COUNT only
+	// keeps the occurrences that are left
+	if rule.Count > 0 {
+		rule.Count -= rule.CountBefore(dtstart, due)
+		next.RecurrenceRule = rule.String()
+	}
+
+	return next, nil
+}
+
+// validateRecurrence validates the recurrence settings of a task
+func validateRecurrence(task *models.Task) error {
+	if task.RecurAfterDays < 0 || task.RecurAfterDays > maxRecurAfterDays {
+		return ErrInvalidRecurAfterDays
+	}
+	if task.RecurrenceRule == "" {
+		return nil
+	}
+
+	if task.RecurAfterDays > 0 {
+		return ErrConflictingRecurrence
+	}
+	if _, err := recurrence.Parse(task.RecurrenceRule); err != nil {
+		return ErrInvalidRecurrenceRule
+	}
+	if task.DueDate == nil {
+		return ErrRecurrenceRequiresDueDate
+	}
+
+	return nil
+}
+
+// checkParent verifies that parentID can become the parent of the task with
+// the given ID, which is 0 for new tasks
+func (ts *TaskService) checkParent(ctx context.Context, taskID, parentID int) error {
+	if parentID == taskID {
+		return ErrTaskHierarchyCycle
+	}
+
+	ancestor, err := ts.findTask(ctx, parentID, ErrParentTaskNotFound)
+	if err != nil {
+		return err
+	}
+
+	// The task cannot move below one of its own subtasks
+	seen := make(map[int]bool)
+	for ancestor.ParentID != nil && !seen[ancestor.ID] {
+		seen[ancestor.ID] = true
+		if *ancestor.ParentID == taskID {
+			return ErrTaskHierarchyCycle
+		}
+		if ancestor, err = ts.findTask(ctx, *ancestor.ParentID, ErrParentTaskNotFound); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkBlockers verifies that the task with the given ID, which is 0 for new
+// tasks, can be blocked by blockedBy without creating a cycle
+func (ts *TaskService) checkBlockers(ctx context.Context, taskID int, blockedBy []int) error {
+	// Walk the tasks the new blockers depend on; reaching the task itself
+	// means it would end up waiting for itself
+	pending := append([]int{}, blockedBy...)
+	seen := make(map[int]bool)
+	for len(pending) > 0 {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if id == taskID {
+			return ErrDependencyCycle
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		blocker, err := ts.findTask(ctx, id, ErrBlockingTaskNotFound)
+		if err != nil {
+			return err
+		}
+		pending = append(pending, blocker.BlockedBy...)
+	}
+
+	return nil
+}
+
+// checkNotBlocked returns a TaskBlockedError when some of the tasks blocking
+// task are still pending
+func (ts *TaskService) checkNotBlocked(ctx context.Context, task *models.Task) error {
+	var pending []int
+	for _, blockerID := range task.BlockedBy {
+		blocker, err := ts.taskRepo.GetTaskByID(ctx, blockerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get blocking task: %w", err)
+		}
+		if blocker.Status != models.TaskStatusCompleted {
+			pending = append(pending, blockerID)
+		}
+	}
+
+	if len(pending) > 0 {
+		return &TaskBlockedError{TaskID: task.ID, BlockedBy: pending}
+	}
+	return nil
+}
+
+// findTask retrieves a task referenced by another one, returning notFound
+// when it does not exist
+func (ts *TaskService) findTask(ctx context.Context, id int, notFound error) (*models.Task, error) {
+	if id <= 0 {
+		return nil, notFound
+	}
+
+	task, err := ts.taskRepo.GetTaskByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notFound
+		}
+		return nil, fmt.Errorf("failed to get task %d: %w", id, err)
+	}
+
+	return task, nil
+}
+
 // validateCreateTaskRequest validates the create task request
-func (ts *TaskService) validateCreateTaskRequest(req CreateTaskRequest) error {
+func (ts *TaskService) validateCreateTaskRequest(ctx context.Context, req CreateTaskRequest) error {
 	// Title validation
 	if strings.TrimSpace(req.Title) == "" {
 		return ErrTaskTitleRequired
@@ -339,16 +1098,22 @@ func (ts *TaskService) validateCreateTaskRequest(req CreateTaskRequest) error {
 		return ErrTaskDescriptionTooLong
 	}
 
-	// Due date validation
-	if req.DueDate != nil && req.DueDate.Before(time.Now().Truncate(24*time.Hour)) {
+	// Due date validation; tasks may be due any time today in the request
+	// time zone
+	if req.DueDate != nil && req.DueDate.Before(timezone.StartOfDay(time.Now(), timezone.FromContext(ctx))) {
 		return ErrDueDateInPast
 	}
 
-	return nil
+	// Priority validation (empty means the default priority)
+	if req.Priority != "" && !models.IsValidTaskPriority(req.Priority) {
+		return ErrInvalidTaskPriority
+	}
+
+	return validateTags(req.Tags)
 }
 
 // validateUpdateTaskRequest validates the update task request
-func (ts *TaskService) validateUpdateTaskRequest(req UpdateTaskRequest) error {
+func (ts *TaskService) validateUpdateTaskRequest(ctx context.Context, req UpdateTaskRequest) error {
 	// Title validation
 	if req.Title != nil {
 		if strings.TrimSpace(*req.Title) == "" {
@@ -371,10 +1136,119 @@ func (ts *TaskService) validateUpdateTaskRequest(req UpdateTaskRequest) error {
 		}
 	}
 
-	// Due date validation
-	if req.DueDate != nil && req.DueDate.Before(time.Now().Truncate(24*time.Hour)) {
+	// Due date validation; tasks may be due any time today in the request
+	// time zone
+	if req.DueDate != nil && !req.ClearDueDate && req.DueDate.Before(timezone.StartOfDay(time.Now(), timezone.FromContext(ctx))) {
 		return ErrDueDateInPast
 	}
 
+	// Priority validation
+	if req.Priority != nil && !models.IsValidTaskPriority(*req.Priority) {
+		return ErrInvalidTaskPriority
+	}
+
+	// Tags validation
+	if req.Tags != nil {
+		return validateTags(*req.Tags)
+	}
+
+	return nil
+}
+
+// validateTaskListFilters validates the list filters and fills in the
+// default tag match mode and sort order
+func (ts *TaskService) validateTaskListFilters(filters *TaskListFilters) error {
+	if filters.Priority != "" && !models.IsValidTaskPriority(filters.Priority) {
+		return ErrInvalidTaskPriority
+	}
+
+	switch filters.TagMatch {
+	case "":
+		filters.TagMatch = TagMatchAny
+	case TagMatchAny, TagMatchAll:
+	default:
+		return ErrInvalidTagMatch
+	}
+
+	if filters.SortBy == "" {
+		filters.SortBy = database.TaskSortCreatedAt
+	}
+	if !database.IsValidTaskSort(filters.SortBy) {
+		return ErrInvalidTaskSort
+	}
+
+	switch filters.SortOrder {
+	case "":
+		// Newest and most important first; alphabetical and soonest due first
+		filters.SortOrder = SortAsc
+		if filters.SortBy == database.TaskSortCreatedAt || filters.SortBy == database.TaskSortUpdatedAt ||
+			filters.SortBy == database.TaskSortPriority {
+			filters.SortOrder = SortDesc
+		}
+	case SortAsc, SortDesc:
+	default:
+		return ErrInvalidSortOrder
+	}
+
 	return nil
-}
\ No newline at end of file
+}
+
+// validateTags validates the tags of a task
+func validateTags(tags []string) error {
+	normalized := normalizeTags(tags)
+	if len(normalized) > maxTaskTags {
+		return ErrTooManyTaskTags
+	}
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || len(tag) > maxTaskTagLength {
+			return ErrInvalidTaskTag
+		}
+	}
+
+	return nil
+}
+
+// normalizeTags lowercases and trims tags and removes duplicates, keeping
+// the original order
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+// normalizeTaskIDs removes duplicate task IDs, keeping the original order
+func normalizeTaskIDs(ids []int) []int {
+	if ids == nil {
+		return nil
+	}
+
+	normalized := make([]int, 0, len(ids))
+	seen := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		normalized = append(normalized, id)
+	}
+
+	return normalized
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,18 +13,31 @@ import (
 	"agenda/internal/models"
 )
 
+// MockTransactor runs functions directly, without a transaction
+type MockTransactor struct{}
+
+func (MockTransactor) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 // MockTaskRepository implements TaskRepositoryInterface for testing
 type MockTaskRepository struct {
 	tasks       map[int]*models.Task
+	deleted     map[int]*models.Task
 	nextID      int
 	shouldError bool
 	errorMsg    string
+	lastFilters database.TaskFilters
+
+	// beforeUpdate, when set, runs before UpdateTask writes
+	beforeUpdate func()
 }
 
 func NewMockTaskRepository() *MockTaskRepository {
 	return &MockTaskRepository{
-		tasks:  make(map[int]*models.Task),
-		nextID: 1,
+		tasks:   make(map[int]*models.Task),
+		deleted: make(map[int]*models.Task),
+		nextID:  1,
 	}
 }
 
@@ -55,7 +71,7 @@ func (m *MockTaskRepository) GetTaskByID(ctx context.Context, id int) (*models.T
 
 	task, exists := m.tasks[id]
 	if !exists {
-		return nil, errors.New("task not found")
+		return nil, sql.ErrNoRows
 	}
 
 	// Return a copy to avoid modification issues
@@ -68,11 +84,20 @@ func (m *MockTaskRepository) UpdateTask(ctx context.Context, task *models.Task)
 		return errors.New(m.errorMsg)
 	}
 
-	if _, exists := m.tasks[task.ID]; !exists {
+	if m.beforeUpdate != nil {
+		m.beforeUpdate()
+	}
+
+	stored, exists := m.tasks[task.ID]
+	if !exists {
 		return errors.New("task not found")
 	}
+	if stored.Version != task.Version {
+		return database.ErrVersionConflict
+	}
 
 	task.UpdatedAt = time.Now()
+	task.Version++
 	m.tasks[task.ID] = task
 	return nil
 }
@@ -82,10 +107,14 @@ func (m *MockTaskRepository) DeleteTask(ctx context.Context, id int) error {
 		return errors.New(m.errorMsg)
 	}
 
-	if _, exists := m.tasks[id]; !exists {
+	task, exists := m.tasks[id]
+	if !exists {
 		return errors.New("task not found")
 	}
 
+	deletedAt := time.Now()
+	task.DeletedAt = &deletedAt
+	m.deleted[id] = task
 	delete(m.tasks, id)
 	return nil
 }
@@ -95,12 +124,17 @@ func (m *MockTaskRepository) ListTasks(ctx context.Context, filters database.Tas
 		return nil, errors.New(m.errorMsg)
 	}
 
+	m.lastFilters = filters
+
 	var result []*models.Task
 	for _, task := range m.tasks {
 		// Apply filters
 		if filters.Status != "" && task.Status != filters.Status {
 			continue
 		}
+		if filters.Priority != "" && task.Priority != filters.Priority {
+			continue
+		}
 		if filters.DueAfter != nil && (task.DueDate == nil || task.DueDate.Before(*filters.DueAfter)) {
 			continue
 		}
@@ -115,6 +149,11 @@ func (m *MockTaskRepository) ListTasks(ctx context.Context, filters database.Tas
 	return result, nil
 }
 
+func (m *MockTaskRepository) ListTasksPage(ctx context.Context, filters database.TaskFilters) ([]*models.Task, database.PageCursors, error) {
+	tasks, err := m.ListTasks(ctx, filters)
+	return tasks, database.PageCursors{}, err
+}
+
 func (m *MockTaskRepository) CountTasks(ctx context.Context, filters database.TaskFilters) (int64, error) {
 	if m.shouldError {
 		return 0, errors.New(m.errorMsg)
@@ -156,6 +195,98 @@ func (m *MockTaskRepository) GetOverdueTasks(ctx context.Context) ([]*models.Tas
 	return result, nil
 }
 
+func (m *MockTaskRepository) GetTaskByUID(ctx context.Context, uid string) (*models.Task, error) {
+	if m.shouldError {
+		return nil, errors.New(m.errorMsg)
+	}
+
+	for _, task := range m.tasks {
+		if task.UID == uid {
+			taskCopy := *task
+			return &taskCopy, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockTaskRepository) GetSubtasks(ctx context.Context, parentID int) ([]*models.Task, error) {
+	if m.shouldError {
+		return nil, errors.New(m.errorMsg)
+	}
+
+	var result []*models.Task
+	for _, task := range m.tasks {
+		if task.ParentID != nil && *task.ParentID == parentID {
+			taskCopy := *task
+			result = append(result, &taskCopy)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *MockTaskRepository) GetSeriesHistory(ctx context.Context, seriesID int) ([]*models.Task, error) {
+	if m.shouldError {
+		return nil, errors.New(m.errorMsg)
+	}
+
+	var result []*models.Task
+	for _, task := range m.tasks {
+		if task.SeriesRootID() == seriesID && task.Status == models.TaskStatusCompleted {
+			taskCopy := *task
+			result = append(result, &taskCopy)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *MockTaskRepository) ListDeletedTasks(ctx context.Context) ([]*models.Task, error) {
+	if m.shouldError {
+		return nil, errors.New(m.errorMsg)
+	}
+
+	var result []*models.Task
+	for _, task := range m.deleted {
+		taskCopy := *task
+		result = append(result, &taskCopy)
+	}
+
+	return result, nil
+}
+
+func (m *MockTaskRepository) RestoreTask(ctx context.Context, id int) error {
+	if m.shouldError {
+		return errors.New(m.errorMsg)
+	}
+
+	if _, exists := m.tasks[id]; exists {
+		return database.ErrNotDeleted
+	}
+	task, exists := m.deleted[id]
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	task.DeletedAt = nil
+	task.Version++
+	m.tasks[id] = task
+	delete(m.deleted, id)
+	return nil
+}
+
+func (m *MockTaskRepository) PurgeDeletedTasks(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	for id, task := range m.deleted {
+		if task.DeletedAt.Before(deletedBefore) {
+			delete(m.deleted, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
 // Implement BaseRepository interface methods (not used in tests but required)
 func (m *MockTaskRepository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
 	return 0, nil
@@ -181,7 +312,7 @@ func (m *MockTaskRepository) Exists(ctx context.Context, query string, args ...i
 
 func TestTaskService_CreateTask(t *testing.T) {
 	mockRepo := NewMockTaskRepository()
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, MockTransactor{})
 	ctx := context.Background()
 
 	t.Run("successful task creation", func(t *testing.T) {
@@ -292,7 +423,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 
 func TestTaskService_GetTaskByID(t *testing.T) {
 	mockRepo := NewMockTaskRepository()
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, MockTransactor{})
 	ctx := context.Background()
 
 	// Create a test task
@@ -334,7 +465,7 @@ func TestTaskService_GetTaskByID(t *testing.T) {
 
 func TestTaskService_UpdateTask(t *testing.T) {
 	mockRepo := NewMockTaskRepository()
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, MockTransactor{})
 	ctx := context.Background()
 
 	// Create a test task
@@ -388,11 +519,62 @@ func TestTaskService_UpdateTask(t *testing.T) {
 			t.Error("expected error for non-existent task")
 		}
 	})
+
+	t.Run("clear due date", func(t *testing.T) {
+		due := time.Now().Add(24 * time.Hour)
+		if _, err := service.UpdateTask(ctx, 1, UpdateTaskRequest{DueDate: &due}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		updatedTask, err := service.UpdateTask(ctx, 1, UpdateTaskRequest{ClearDueDate: true})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if updatedTask.DueDate != nil {
+			t.Errorf("expected the due date to be cleared, got %v", updatedTask.DueDate)
+		}
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		newTitle := "Stale Title"
+		stale := mockRepo.tasks[1].Version - 1
+		_, err := service.UpdateTask(ctx, 1, UpdateTaskRequest{Title: &newTitle, Version: &stale})
+		if err != ErrVersionMismatch {
+			t.Errorf("expected ErrVersionMismatch, got %v", err)
+		}
+		if mockRepo.tasks[1].Title == newTitle {
+			t.Error("expected the stale update not to apply")
+		}
+	})
+
+	t.Run("current version", func(t *testing.T) {
+		newTitle := "Versioned Title"
+		current := mockRepo.tasks[1].Version
+		updatedTask, err := service.UpdateTask(ctx, 1, UpdateTaskRequest{Title: &newTitle, Version: &current})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if updatedTask.Version != current+1 {
+			t.Errorf("expected version %d, got %d", current+1, updatedTask.Version)
+		}
+	})
+
+	t.Run("concurrent update", func(t *testing.T) {
+		// The task moves on between the read and the write
+		mockRepo.beforeUpdate = func() { mockRepo.tasks[1].Version++ }
+		defer func() { mockRepo.beforeUpdate = nil }()
+
+		newTitle := "Racing Title"
+		_, err := service.UpdateTask(ctx, 1, UpdateTaskRequest{Title: &newTitle})
+		if err != ErrVersionMismatch {
+			t.Errorf("expected ErrVersionMismatch, got %v", err)
+		}
+	})
 }
 
 func TestTaskService_CompleteTask(t *testing.T) {
 	mockRepo := NewMockTaskRepository()
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, MockTransactor{})
 	ctx := context.Background()
 
 	// Create a test task
@@ -427,7 +609,7 @@ func TestTaskService_CompleteTask(t *testing.T) {
 
 func TestTaskService_ReopenTask(t *testing.T) {
 	mockRepo := NewMockTaskRepository()
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, MockTransactor{})
 	ctx := context.Background()
 
 	// Create a completed test task
@@ -460,9 +642,42 @@ func TestTaskService_ReopenTask(t *testing.T) {
 	})
 }
 
+func TestTaskService_RestoreTask(t *testing.T) {
+	mockRepo := NewMockTaskRepository()
+	service := NewTaskService(mockRepo, MockTransactor{})
+	ctx := context.Background()
+
+	mockRepo.tasks[1] = &models.Task{ID: 1, Title: "Test Task", Status: models.TaskStatusPending, Version: 1}
+	if err := service.DeleteTask(ctx, 1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("successful restore", func(t *testing.T) {
+		restoredTask, err := service.RestoreTask(ctx, 1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if restoredTask.DeletedAt != nil {
+			t.Errorf("expected the task to leave the trash, got deleted_at %v", restoredTask.DeletedAt)
+		}
+	})
+
+	t.Run("task not in the trash", func(t *testing.T) {
+		if _, err := service.RestoreTask(ctx, 1); err != ErrTaskNotDeleted {
+			t.Errorf("expected ErrTaskNotDeleted, got %v", err)
+		}
+	})
+
+	t.Run("task not found", func(t *testing.T) {
+		if _, err := service.RestoreTask(ctx, 999); err != ErrTaskNotFound {
+			t.Errorf("expected ErrTaskNotFound, got %v", err)
+		}
+	})
+}
+
 func TestTaskService_ListTasks(t *testing.T) {
 	mockRepo := NewMockTaskRepository()
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, MockTransactor{})
 	ctx := context.Background()
 
 	// Create test tasks
@@ -473,7 +688,7 @@ func TestTaskService_ListTasks(t *testing.T) {
 
 	t.Run("list all tasks", func(t *testing.T) {
 		filters := TaskListFilters{}
-		tasks, total, err := service.ListTasks(ctx, filters)
+		tasks, page, err := service.ListTasks(ctx, filters)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -481,8 +696,8 @@ func TestTaskService_ListTasks(t *testing.T) {
 		if len(tasks) != 2 {
 			t.Errorf("expected 2 tasks, got %d", len(tasks))
 		}
-		if total != 2 {
-			t.Errorf("expected total 2, got %d", total)
+		if page.Total != 2 {
+			t.Errorf("expected total 2, got %d", page.Total)
 		}
 	})
 
@@ -490,7 +705,7 @@ func TestTaskService_ListTasks(t *testing.T) {
 		filters := TaskListFilters{
 			Status: models.TaskStatusPending,
 		}
-		tasks, total, err := service.ListTasks(ctx, filters)
+		tasks, page, err := service.ListTasks(ctx, filters)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -498,8 +713,8 @@ func TestTaskService_ListTasks(t *testing.T) {
 		if len(tasks) != 1 {
 			t.Errorf("expected 1 task, got %d", len(tasks))
 		}
-		if total != 1 {
-			t.Errorf("expected total 1, got %d", total)
+		if page.Total != 1 {
+			t.Errorf("expected total 1, got %d", page.Total)
 		}
 		if tasks[0].Status != models.TaskStatusPending {
 			t.Errorf("expected pending task, got %s", tasks[0].Status)
@@ -520,7 +735,7 @@ func TestTaskService_ListTasks(t *testing.T) {
 
 func TestTaskService_GetOverdueTasks(t *testing.T) {
 	mockRepo := NewMockTaskRepository()
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, MockTransactor{})
 	ctx := context.Background()
 
 	// Create test tasks
@@ -567,7 +782,7 @@ func TestTaskService_GetOverdueTasks(t *testing.T) {
 
 func TestTaskService_GetUpcomingTasks(t *testing.T) {
 	mockRepo := NewMockTaskRepository()
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, MockTransactor{})
 	ctx := context.Background()
 
 	// Create test tasks
@@ -614,4 +829,496 @@ func TestTaskService_GetUpcomingTasks(t *testing.T) {
 			t.Errorf("expected 1 upcoming task, got %d", len(tasks))
 		}
 	})
-}
\ No newline at end of file
+}
+func TestTaskService_PriorityAndTags(t *testing.T) {
+	repo := NewMockTaskRepository()
+	service := NewTaskService(repo, MockTransactor{})
+	ctx := context.Background()
+
+	task, err := service.CreateTask(ctx, CreateTaskRequest{
+		Title:    "Triage",
+		Priority: models.TaskPriorityHigh,
+		Tags:     []string{" Backend", "bugs", "backend"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if task.Priority != models.TaskPriorityHigh {
+		t.Errorf("Expected priority high, got %s", task.Priority)
+	}
+	if len(task.Tags) != 2 || task.Tags[0] != "backend" || task.Tags[1] != "bugs" {
+		t.Errorf("Expected normalized tags [backend bugs], got %v", task.Tags)
+	}
+
+	urgent := models.TaskPriorityUrgent
+	tags := []string{"ops"}
+	updated, err := service.UpdateTask(ctx, task.ID, UpdateTaskRequest{Priority: &urgent, Tags: &tags})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Priority != models.TaskPriorityUrgent || len(updated.Tags) != 1 || updated.Tags[0] != "ops" {
+		t.Errorf("Unexpected task after update: %+v", updated)
+	}
+
+	invalid := "critical"
+	if _, err := service.UpdateTask(ctx, task.ID, UpdateTaskRequest{Priority: &invalid}); err != ErrInvalidTaskPriority {
+		t.Errorf("Expected ErrInvalidTaskPriority, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		req     CreateTaskRequest
+		wantErr error
+	}{
+		{"invalid priority", CreateTaskRequest{Title: "Task", Priority: "critical"}, ErrInvalidTaskPriority},
+		{"blank tag", CreateTaskRequest{Title: "Task", Tags: []string{"  "}}, ErrInvalidTaskTag},
+		{"long tag", CreateTaskRequest{Title: "Task", Tags: []string{strings.Repeat("a", 51)}}, ErrInvalidTaskTag},
+		{"too many tags", CreateTaskRequest{Title: "Task", Tags: manyTags(21)}, ErrTooManyTaskTags},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreateTask(ctx, tt.req); err != tt.wantErr {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTaskService_ListTasks_SortAndTagFilters(t *testing.T) {
+	repo := NewMockTaskRepository()
+	service := NewTaskService(repo, MockTransactor{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		filters      TaskListFilters
+		wantErr      error
+		wantSortBy   string
+		wantSortDesc bool
+	}{
+		{name: "default sort", filters: TaskListFilters{}, wantSortBy: "created_at", wantSortDesc: true},
+		{name: "priority defaults to descending", filters: TaskListFilters{SortBy: "priority"}, wantSortBy: "priority", wantSortDesc: true},
+		{name: "due date defaults to ascending", filters: TaskListFilters{SortBy: "due_date"}, wantSortBy: "due_date"},
+		{name: "explicit order", filters: TaskListFilters{SortBy: "title", SortOrder: "desc"}, wantSortBy: "title", wantSortDesc: true},
+		{name: "invalid sort", filters: TaskListFilters{SortBy: "id"}, wantErr: ErrInvalidTaskSort},
+		{name: "invalid order", filters: TaskListFilters{SortOrder: "up"}, wantErr: ErrInvalidSortOrder},
+		{name: "invalid tag match", filters: TaskListFilters{TagMatch: "some"}, wantErr: ErrInvalidTagMatch},
+		{name: "invalid priority", filters: TaskListFilters{Priority: "critical"}, wantErr: ErrInvalidTaskPriority},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := service.ListTasks(ctx, tt.filters)
+			if err != tt.wantErr {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if repo.lastFilters.SortBy != tt.wantSortBy || repo.lastFilters.SortDesc != tt.wantSortDesc {
+				t.Errorf("Expected sort %s desc=%v, got %s desc=%v", tt.wantSortBy, tt.wantSortDesc,
+					repo.lastFilters.SortBy, repo.lastFilters.SortDesc)
+			}
+		})
+	}
+
+	if _, _, err := service.ListTasks(ctx, TaskListFilters{Tags: []string{"Ops", "ops", "home"}, TagMatch: "all"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !repo.lastFilters.MatchAllTags || len(repo.lastFilters.Tags) != 2 {
+		t.Errorf("Expected all of two normalized tags, got %+v", repo.lastFilters)
+	}
+}
+
+func manyTags(n int) []string {
+	tags := make([]string, n)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag-%d", i)
+	}
+	return tags
+}
+
+func TestTaskService_Subtasks(t *testing.T) {
+	repo := NewMockTaskRepository()
+	service := NewTaskService(repo, MockTransactor{})
+	ctx := context.Background()
+
+	parent, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Parent"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	child, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Child", ParentID: &parent.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	grandchild, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Grandchild", ParentID: &child.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	subtasks, err := service.GetSubtasks(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(subtasks) != 1 || subtasks[0].ID != child.ID {
+		t.Errorf("Expected subtasks [%d], got %v", child.ID, subtasks)
+	}
+
+	if _, err := service.GetSubtasks(ctx, 999); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+
+	missing := 999
+	if _, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Orphan", ParentID: &missing}); err != ErrParentTaskNotFound {
+		t.Errorf("Expected ErrParentTaskNotFound, got %v", err)
+	}
+
+	// A task cannot move below itself or its own subtasks
+	for _, parentID := range []int{parent.ID, grandchild.ID} {
+		parentID := parentID
+		if _, err := service.UpdateTask(ctx, parent.ID, UpdateTaskRequest{ParentID: &parentID}); err != ErrTaskHierarchyCycle {
+			t.Errorf("Moving the parent below task %d: expected ErrTaskHierarchyCycle, got %v", parentID, err)
+		}
+	}
+
+	// Zero turns a subtask into a top-level task
+	topLevel := 0
+	updated, err := service.UpdateTask(ctx, grandchild.ID, UpdateTaskRequest{ParentID: &topLevel})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.ParentID != nil {
+		t.Errorf("Expected no parent, got %d", *updated.ParentID)
+	}
+}
+
+func TestTaskService_Dependencies(t *testing.T) {
+	repo := NewMockTaskRepository()
+	service := NewTaskService(repo, MockTransactor{})
+	ctx := context.Background()
+
+	design, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Design"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	build, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Build", BlockedBy: []int{design.ID}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ship, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Ship", BlockedBy: []int{build.ID, design.ID, build.ID}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ship.BlockedBy) != 2 {
+		t.Errorf("Expected duplicate blockers to be removed, got %v", ship.BlockedBy)
+	}
+
+	if _, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Task", BlockedBy: []int{999}}); err != ErrBlockingTaskNotFound {
+		t.Errorf("Expected ErrBlockingTaskNotFound, got %v", err)
+	}
+
+	// Making the design wait for the release would close a cycle
+	for _, blockedBy := range [][]int{{design.ID}, {ship.ID}} {
+		blockedBy := blockedBy
+		if _, err := service.UpdateTask(ctx, design.ID, UpdateTaskRequest{BlockedBy: &blockedBy}); err != ErrDependencyCycle {
+			t.Errorf("Blocking design by %v: expected ErrDependencyCycle, got %v", blockedBy, err)
+		}
+	}
+
+	tree, err := service.GetDependencyTree(ctx, ship.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tree.BlockedBy) != 2 || tree.BlockedBy[0].Task.ID != build.ID || tree.BlockedBy[1].Task.ID != design.ID {
+		t.Fatalf("Unexpected dependency tree: %+v", tree)
+	}
+	if len(tree.BlockedBy[0].BlockedBy) != 1 || tree.BlockedBy[0].BlockedBy[0].Task.ID != design.ID {
+		t.Errorf("Expected build to be blocked by design, got %+v", tree.BlockedBy[0].BlockedBy)
+	}
+
+	// Blocked tasks cannot be completed, directly or through an update
+	_, err = service.CompleteTask(ctx, ship.ID)
+	var blocked *TaskBlockedError
+	if !errors.As(err, &blocked) {
+		t.Fatalf("Expected TaskBlockedError, got %v", err)
+	}
+	if len(blocked.BlockedBy) != 2 {
+		t.Errorf("Expected 2 pending blockers, got %v", blocked.BlockedBy)
+	}
+
+	completed := models.TaskStatusCompleted
+	if _, err := service.UpdateTask(ctx, build.ID, UpdateTaskRequest{Status: &completed}); !errors.As(err, &blocked) {
+		t.Errorf("Expected TaskBlockedError, got %v", err)
+	}
+
+	for _, id := range []int{design.ID, build.ID, ship.ID} {
+		if _, err := service.CompleteTask(ctx, id); err != nil {
+			t.Errorf("Completing task %d: expected no error, got %v", id, err)
+		}
+	}
+}
+
+func TestTaskService_RecurringTasks(t *testing.T) {
+	repo := NewMockTaskRepository()
+	service := NewTaskService(repo, MockTransactor{})
+	ctx := context.Background()
+
+	t.Run("fixed schedule", func(t *testing.T) {
+		due := time.Now().AddDate(0, 0, 1).Truncate(time.Hour)
+		task, err := service.CreateTask(ctx, CreateTaskRequest{
+			Title:          "Submit timesheet",
+			DueDate:        &due,
+			RecurrenceRule: "FREQ=WEEKLY;COUNT=3",
+			Tags:           []string{"admin"},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		// Each completion creates the next instance until COUNT runs out
+		current := task
+		for i := 1; i < 3; i++ {
+			completed, err := service.CompleteTask(ctx, current.ID)
+			if err != nil {
+				t.Fatalf("Completion %d: expected no error, got %v", i, err)
+			}
+			if completed.CompletedAt == nil {
+				t.Error("Expected completion time to be set")
+			}
+
+			next := repo.tasks[repo.nextID-1]
+			if next.ID == current.ID || next.Status != models.TaskStatusPending {
+				t.Fatalf("Completion %d: expected a new pending instance, got %+v", i, next)
+			}
+			if want := due.AddDate(0, 0, 7*i); next.DueDate == nil || !next.DueDate.Equal(want) {
+				t.Errorf("Completion %d: expected due date %v, got %v", i, want, next.DueDate)
+			}
+			if next.SeriesID == nil || *next.SeriesID != task.ID {
+				t.Errorf("Completion %d: expected series %d, got %v", i, task.ID, next.SeriesID)
+			}
+			if len(next.Tags) != 1 || next.Tags[0] != "admin" {
+				t.Errorf("Completion %d: expected tags to be copied, got %v", i, next.Tags)
+			}
+			current = next
+		}
+
+		last := repo.nextID
+		if _, err := service.CompleteTask(ctx, current.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if repo.nextID != last {
+			t.Error("Expected no instance after the last occurrence")
+		}
+
+		history, err := service.GetCompletedInstances(ctx, current.ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(history) != 3 {
+			t.Errorf("Expected 3 completed instances, got %d", len(history))
+		}
+	})
+
+	t.Run("after completion", func(t *testing.T) {
+		task, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Water plants", RecurAfterDays: 3})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		before := time.Now()
+		if _, err := service.CompleteTask(ctx, task.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		next := repo.tasks[repo.nextID-1]
+		if next.DueDate == nil || next.DueDate.Before(before.AddDate(0, 0, 3)) || next.DueDate.After(time.Now().AddDate(0, 0, 3)) {
+			t.Errorf("Expected the next instance to be due 3 days after completion, got %v", next.DueDate)
+		}
+		if next.RecurAfterDays != 3 {
+			t.Errorf("Expected the next instance to recur, got %d days", next.RecurAfterDays)
+		}
+	})
+
+	t.Run("reopening clears the completion time", func(t *testing.T) {
+		task, err := service.CreateTask(ctx, CreateTaskRequest{Title: "One-off"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := service.CompleteTask(ctx, task.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		reopened, err := service.ReopenTask(ctx, task.ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if reopened.CompletedAt != nil {
+			t.Errorf("Expected no completion time, got %v", reopened.CompletedAt)
+		}
+	})
+
+	due := time.Now().AddDate(0, 0, 1)
+	tests := []struct {
+		name    string
+		req     CreateTaskRequest
+		wantErr error
+	}{
+		{"invalid rule", CreateTaskRequest{Title: "Task", DueDate: &due, RecurrenceRule: "FREQ=HOURLY"}, ErrInvalidRecurrenceRule},
+		{"rule without due date", CreateTaskRequest{Title: "Task", RecurrenceRule: "FREQ=DAILY"}, ErrRecurrenceRequiresDueDate},
+		{"both kinds", CreateTaskRequest{Title: "Task", DueDate: &due, RecurrenceRule: "FREQ=DAILY", RecurAfterDays: 2}, ErrConflictingRecurrence},
+		{"negative days", CreateTaskRequest{Title: "Task", RecurAfterDays: -1}, ErrInvalidRecurAfterDays},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreateTask(ctx, tt.req); err != tt.wantErr {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNextTaskInstance_Count(t *testing.T) {
+	// The first due date is a Sunday that does not match BYDAY, so only the
+	// Mondays and Wednesdays count
+	due := time.Date(2030, time.January, 6, 9, 0, 0, 0, time.UTC)
+	task := &models.Task{ID: 1, Title: "Standup notes", DueDate: &due, RecurrenceRule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3"}
+
+	var dues []time.Time
+	for task != nil {
+		next, err := nextTaskInstance(task, due)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if next != nil {
+			dues = append(dues, *next.DueDate)
+		}
+		task = next
+	}
+
+	want := []time.Time{
+		time.Date(2030, time.January, 7, 9, 0, 0, 0, time.UTC),
+		time.Date(2030, time.January, 9, 9, 0, 0, 0, time.UTC),
+		time.Date(2030, time.January, 14, 9, 0, 0, 0, time.UTC),
+	}
+	if len(dues) != len(want) {
+		t.Fatalf("Expected due dates %v, got %v", want, dues)
+	}
+	for i := range want {
+		if !dues[i].Equal(want[i]) {
+			t.Errorf("Instance %d: expected %v, got %v", i, want[i], dues[i])
+		}
+	}
+}
+
+func TestTaskService_BulkTasks(t *testing.T) {
+	ctx := context.Background()
+	// Tasks are stored directly, as overdue tasks cannot be created
+	newTask := func(repo *MockTaskRepository, title string, due *time.Time, priority string) *models.Task {
+		task, _ := repo.CreateTask(ctx, &models.Task{Title: title, DueDate: due, Priority: priority, Version: 1})
+		return task
+	}
+
+	operations := func(keep, drop *models.Task) []BulkTaskOperation {
+		return []BulkTaskOperation{
+			{Op: BulkCreate, Create: &CreateTaskRequest{Title: "New task"}},
+			{Op: BulkComplete, ID: keep.ID},
+			{Op: BulkComplete, ID: 999},
+			{Op: BulkDelete, ID: drop.ID},
+		}
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		repo := NewMockTaskRepository()
+		service := NewTaskService(repo, MockTransactor{})
+		keep, drop := newTask(repo, "Keep", nil, "medium"), newTask(repo, "Drop", nil, "medium")
+
+		results, err := service.BulkTasks(ctx, BulkTaskRequest{Mode: BulkBestEffort, Operations: operations(keep, drop)})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(results) != 4 {
+			t.Fatalf("Expected 4 results, got %d", len(results))
+		}
+		if results[0].Err != nil || results[0].ID == 0 {
+			t.Errorf("Expected the task to be created, got %+v", results[0])
+		}
+		if task, ok := results[1].Item.(*models.Task); !ok || task.Status != models.TaskStatusCompleted {
+			t.Errorf("Expected the task to be completed, got %+v", results[1])
+		}
+		if results[2].Err != ErrTaskNotFound {
+			t.Errorf("Expected %v, got %v", ErrTaskNotFound, results[2].Err)
+		}
+		if results[3].Err != nil || results[3].Item != nil {
+			t.Errorf("Expected the task to be deleted, got %+v", results[3])
+		}
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		repo := NewMockTaskRepository()
+		service := NewTaskService(repo, MockTransactor{})
+		keep, drop := newTask(repo, "Keep", nil, "medium"), newTask(repo, "Drop", nil, "medium")
+
+		results, err := service.BulkTasks(ctx, BulkTaskRequest{Operations: operations(keep, drop)})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if results[2].Err != ErrTaskNotFound {
+			t.Errorf("Expected %v, got %v", ErrTaskNotFound, results[2].Err)
+		}
+		for _, i := range []int{0, 1, 3} {
+			if results[i].Err != ErrBulkAborted || results[i].Item != nil {
+				t.Errorf("Operation %d: expected %v, got %+v", i, ErrBulkAborted, results[i])
+			}
+		}
+		if results[0].ID != 0 {
+			t.Errorf("Expected no ID for the aborted creation, got %d", results[0].ID)
+		}
+	})
+
+	t.Run("selection", func(t *testing.T) {
+		repo := NewMockTaskRepository()
+		service := NewTaskService(repo, MockTransactor{})
+		yesterday, tomorrow := time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1)
+		late := newTask(repo, "Late", &yesterday, "high")
+		newTask(repo, "Late but low", &yesterday, "low")
+		newTask(repo, "Upcoming", &tomorrow, "high")
+
+		results, err := service.BulkTasks(ctx, BulkTaskRequest{
+			Select: &TaskSelection{Overdue: true, Priority: "high"},
+			Op:     BulkComplete,
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(results) != 1 || results[0].ID != late.ID || results[0].Err != nil {
+			t.Errorf("Expected the overdue high priority task to be completed, got %+v", results)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		req     BulkTaskRequest
+		wantErr error
+	}{
+		{"invalid mode", BulkTaskRequest{Mode: "some", Operations: []BulkTaskOperation{{Op: BulkDelete, ID: 1}}}, ErrInvalidBulkMode},
+		{"nothing to do", BulkTaskRequest{}, ErrInvalidBulkRequest},
+		{"operations and selection", BulkTaskRequest{
+			Operations: []BulkTaskOperation{{Op: BulkDelete, ID: 1}},
+			Select:     &TaskSelection{Status: "pending"},
+			Op:         BulkDelete,
+		}, ErrInvalidBulkRequest},
+		{"empty selection", BulkTaskRequest{Select: &TaskSelection{}, Op: BulkDelete}, ErrEmptyBulkSelection},
+		{"selection creating tasks", BulkTaskRequest{Select: &TaskSelection{Status: "pending"}, Op: BulkCreate}, ErrInvalidBulkOperation},
+		{"too many operations", BulkTaskRequest{Operations: make([]BulkTaskOperation, maxBulkItems+1)}, ErrBulkTooLarge},
+	}
+
+	service := NewTaskService(NewMockTaskRepository(), MockTransactor{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.BulkTasks(ctx, tt.req); err != tt.wantErr {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+)
+
+// TrashServiceInterface defines the contract for browsing the deleted tasks
+// and events. They are restored through the task and event services.
+type TrashServiceInterface interface {
+	ListTrash(ctx context.Context, itemType string) ([]*models.TrashItem, error)
+}
+
+// TrashService implements TrashServiceInterface
+type TrashService struct {
+	taskRepo  database.TaskRepositoryInterface
+	eventRepo database.EventRepositoryInterface
+}
+
+// NewTrashService creates a new trash service instance
+func NewTrashService(taskRepo database.TaskRepositoryInterface, eventRepo database.EventRepositoryInterface) TrashServiceInterface {
+	return &TrashService{
+		taskRepo:  taskRepo,
+		eventRepo: eventRepo,
+	}
+}
+
+// ErrInvalidTrashType is returned when listing the trash for an unknown
+// item type
+var ErrInvalidTrashType = errors.New("trash type must be task or event")
+
+// ListTrash returns the deleted tasks and events, most recently deleted
+// first. itemType restricts the list to models.TrashTypeTask or
+// models.TrashTypeEvent; both are listed when empty.
+func (ts *TrashService) ListTrash(ctx context.Context, itemType string) ([]*models.TrashItem, error) {
+	if itemType != "" && itemType != models.TrashTypeTask && itemType != models.TrashTypeEvent {
+		return nil, ErrInvalidTrashType
+	}
+
+	items := []*models.TrashItem{}
+	if itemType != models.TrashTypeEvent {
+		tasks, err := ts.taskRepo.ListDeletedTasks(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deleted tasks: %w", err)
+		}
+		for _, task := range tasks {
+			items = append(items, &models.TrashItem{
+				Type:      models.TrashTypeTask,
+				ID:        task.ID,
+				Title:     task.Title,
+				DeletedAt: *task.DeletedAt,
+				Task:      task,
+			})
+		}
+	}
+	if itemType != models.TrashTypeTask {
+		events, err := ts.eventRepo.ListDeletedEvents(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deleted events: %w", err)
+		}
+		for _, event := range events {
+			items = append(items, &models.TrashItem{
+				Type:      models.TrashTypeEvent,
+				ID:        event.ID,
+				Title:     event.Title,
+				DeletedAt: *event.DeletedAt,
+				Event:     event,
+			})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+
+	return items, nil
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+)
+
+// UndoServiceInterface defines the contract for undo operations. Undo
+// services record the changes published by the task and event services
+// under the token of the request making them, and revert them on demand.
+type UndoServiceInterface interface {
+	ChangePublisher
+
+	Undo(ctx context.Context, token string) ([]*models.UndoneChange, error)
+}
+
+// UndoService implements UndoServiceInterface
+type UndoService struct {
+	undoRepo   database.UndoRepositoryInterface
+	taskRepo   database.TaskRepositoryInterface
+	eventRepo  database.EventRepositoryInterface
+	transactor database.Transactor
+	window     time.Duration
+	publishers changePublishers
+}
+
+// NewUndoService creates a new undo service instance. Changes can be undone
+// for window after they are made; reverting them is published to
+// publishers like any other change.
+func NewUndoService(undoRepo database.UndoRepositoryInterface, taskRepo database.TaskRepositoryInterface,
+	eventRepo database.EventRepositoryInterface, transactor database.Transactor, window time.Duration,
+	publishers ...ChangePublisher) UndoServiceInterface {
+	if window <= 0 {
+		window = DefaultUndoWindow
+	}
+	return &UndoService{
+		undoRepo:   undoRepo,
+		taskRepo:   taskRepo,
+		eventRepo:  eventRepo,
+		transactor: transactor,
+		window:     window,
+		publishers: publishers,
+	}
+}
+
+// DefaultUndoWindow is how long changes can be undone by default
+const DefaultUndoWindow = 10 * time.Minute
+
+// UndoWindowFromEnv reads how long changes can be undone from the
+// UNDO_WINDOW environment variable, a duration such as "10m", falling back
+// to DefaultUndoWindow for unset or invalid values
+func UndoWindowFromEnv() time.Duration {
+	window, err := time.ParseDuration(os.Getenv("UNDO_WINDOW"))
+	if err != nil || window <= 0 {
+		return DefaultUndoWindow
+	}
+	return window
+}
+
+// Undo errors
+var (
+	ErrUndoTokenNotFound = errors.New("undo token not found")
+	ErrUndoExpired       = errors.New("undo token has expired")
+	ErrAlreadyUndone     = errors.New("changes were already undone")
+	ErrUndoConflict      = errors.New("item was modified since the change")
+)
+
+// undoOperation collects the changes made through a context returned by
+// WithUndo under a single token
+type undoOperation struct {
+	token string
+}
+
+type undoOperationKey struct{}
+
+// WithUndo returns a context recording the changes made through it for
+// undo, and a function returning the token undoing them. The token is
+// empty when no change was recorded.
+func WithUndo(ctx context.Context) (context.Context, func() string) {
+	operation := &undoOperation{}
+	return context.WithValue(ctx, undoOperationKey{}, operation), func() string {
+		return operation.token
+	}
+}
+
+// Publish records a change for undo, in the transaction making it. Changes
+// made through contexts not returned by WithUndo are not recorded.
+func (us *UndoService) Publish(ctx context.Context, change Change) error {
+	operation, ok := ctx.Value(undoOperationKey{}).(*undoOperation)
+	if !ok {
+		return nil
+	}
+
+	entityType, action, _ := strings.Cut(change.Type, ".")
+
+	record := &models.UndoRecord{
+		EntityType: entityType,
+		Action:     action,
+		CreatedAt:  change.OccurredAt,
+		ExpiresAt:  change.OccurredAt.Add(us.window),
+	}
+	switch item := change.Data.(type) {
+	case *models.Task:
+		record.EntityID, record.UserID = item.ID, item.UserID
+	case *models.Event:
+		record.EntityID, record.UserID = item.ID, item.UserID
+	default:
+		return fmt.Errorf("cannot record changes to %T for undo", change.Data)
+	}
+	if change.Previous != nil {
+		data, err := json.Marshal(change.Previous)
+		if err != nil {
+			return fmt.Errorf("failed to record previous %s: %w", entityType, err)
+		}
+		previous := string(data)
+		record.Previous = &previous
+	}
+
+	// The changed item is read back rather than taken from the change, as
+	// deletions publish the item as it was before
+	var err error
+	record.Version, err = us.undoRepo.GetItemVersion(ctx, entityType, record.EntityID)
+	if err != nil {
+		return fmt.Errorf("failed to get %s version: %w", entityType, err)
+	}
+
+	if operation.token == "" {
+		// Expired records are kept for another window, so that their token
+		// is refused as expired rather than unknown
+		if _, err := us.undoRepo.PurgeExpiredUndoRecords(ctx, change.OccurredAt.Add(-us.window)); err != nil {
+			return err
+		}
+		operation.token = newUndoToken()
+	}
+	record.Token = operation.token
+
+	if _, err := us.undoRepo.CreateUndoRecord(ctx, record); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Undo reverts the changes recorded under a token, most recent first, and
+// returns them. Deleted items are restored, created and restored ones are
+// moved to the trash and updated ones get their previous fields back. A
+// token undoes its changes only once, before it expires, and only while
+// every changed item is still at the version the changes left it in.
+func (us *UndoService) Undo(ctx context.Context, token string) ([]*models.UndoneChange, error) {
+	if token == "" {
+		return nil, ErrUndoTokenNotFound
+	}
+
+	var undone []*models.UndoneChange
+	err := us.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		records, err := us.undoRepo.GetUndoRecords(ctx, token)
+		if err != nil {
+			return fmt.Errorf("failed to get undo records: %w", err)
+		}
+		if len(records) == 0 {
+			return ErrUndoTokenNotFound
+		}
+		if records[0].UndoneAt != nil {
+			return ErrAlreadyUndone
+		}
+		now := time.Now()
+		if !now.Before(records[0].ExpiresAt) {
+			return ErrUndoExpired
+		}
+		if err := us.undoRepo.MarkUndone(ctx, token, now); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAlreadyUndone
+			}
+			return err
+		}
+
+		// Items changed several times are checked against their last change
+		latest := make(map[string]*models.UndoRecord)
+		for _, record := range records {
+			latest[undoItemKey(record)] = record
+		}
+		for _, record := range latest {
+			version, err := us.undoRepo.GetItemVersion(ctx, record.EntityType, record.EntityID)
+			if errors.Is(err, sql.ErrNoRows) || (err == nil && version != record.Version) {
+				return ErrUndoConflict
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get %s version: %w", record.EntityType, err)
+			}
+		}
+
+		for i := len(records) - 1; i >= 0; i-- {
+			if err := us.revert(ctx, records[i]); err != nil {
+				switch {
+				case errors.Is(err, sql.ErrNoRows), errors.Is(err, database.ErrNotDeleted), isVersionConflict(err):
+					return ErrUndoConflict
+				}
+				return fmt.Errorf("failed to undo %s %s: %w", records[i].EntityType, records[i].Action, err)
+			}
+		}
+
+		undone = make([]*models.UndoneChange, 0, len(records))
+		for i := len(records) - 1; i >= 0; i-- {
+			change, err := us.undoneChange(ctx, records[i])
+			if err != nil {
+				return err
+			}
+			undone = append(undone, change)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return undone, nil
+}
+
+// revert reverts a single recorded change and publishes the revert
+func (us *UndoService) revert(ctx context.Context, record *models.UndoRecord) error {
+	if record.EntityType == models.AuditEntityEvent {
+		return us.revertEvent(ctx, record)
+	}
+
+	switch record.Action {
+	case "created", "restored":
+		task, err := us.taskRepo.GetTaskByID(ctx, record.EntityID)
+		if err != nil {
+			return err
+		}
+		if err := us.taskRepo.DeleteTask(ctx, task.ID); err != nil {
+			return err
+		}
+		return us.publishers.publish(ctx, ChangeTaskDeleted, task)
+	case "deleted":
+		if err := us.taskRepo.RestoreTask(ctx, record.EntityID); err != nil {
+			return err
+		}
+		task, err := us.taskRepo.GetTaskByID(ctx, record.EntityID)
+		if err != nil {
+			return err
+		}
+		return us.publishers.publish(ctx, ChangeTaskRestored, task)
+	}
+
+	current, err := us.taskRepo.GetTaskByID(ctx, record.EntityID)
+	if err != nil {
+		return err
+	}
+	var task models.Task
+	if err := unmarshalPrevious(record, &task); err != nil {
+		return err
+	}
+	task.UserID, task.Version = current.UserID, current.Version
+	if err := us.taskRepo.UpdateTask(ctx, &task); err != nil {
+		return err
+	}
+
+	changeType := ChangeTaskUpdated
+	switch {
+	case current.Status == models.TaskStatusCompleted && task.Status == models.TaskStatusPending:
+		changeType = ChangeTaskReopened
+	case current.Status == models.TaskStatusPending && task.Status == models.TaskStatusCompleted:
+		changeType = ChangeTaskCompleted
+	}
+	return us.publishers.publishUpdate(ctx, changeType, current, &task)
+}
+
+// revertEvent reverts a single recorded event change and publishes the
+// revert
+func (us *UndoService) revertEvent(ctx context.Context, record *models.UndoRecord) error {
+	switch record.Action {
+	case "created", "restored":
+		event, err := us.eventRepo.GetEventByID(ctx, record.EntityID)
+		if err != nil {
+			return err
+		}
+		if err := us.eventRepo.DeleteEvent(ctx, event.ID); err != nil {
+			return err
+		}
+		return us.publishers.publish(ctx, ChangeEventDeleted, event)
+	case "deleted":
+		if err := us.eventRepo.RestoreEvent(ctx, record.EntityID); err != nil {
+			return err
+		}
+		event, err := us.eventRepo.GetEventByID(ctx, record.EntityID)
+		if err != nil {
+			return err
+		}
+		return us.publishers.publish(ctx, ChangeEventRestored, event)
+	}
+
+	current, err := us.eventRepo.GetEventByID(ctx, record.EntityID)
+	if err != nil {
+		return err
+	}
+	var event models.Event
+	if err := unmarshalPrevious(record, &event); err != nil {
+		return err
+	}
+	event.UserID, event.Version = current.UserID, current.Version
+	if err := us.eventRepo.UpdateEvent(ctx, &event); err != nil {
+		return err
+	}
+	return us.publishers.publishUpdate(ctx, ChangeEventUpdated, current, &event)
+}
+
+// undoneChange describes a reverted change, with its item as it is after
+// the undo
+func (us *UndoService) undoneChange(ctx context.Context, record *models.UndoRecord) (*models.UndoneChange, error) {
+	change := &models.UndoneChange{
+		EntityType: record.EntityType,
+		EntityID:   record.EntityID,
+		Action:     record.Action,
+	}
+
+	var err error
+	if record.EntityType == models.AuditEntityEvent {
+		change.Event, err = us.eventRepo.GetEventByID(ctx, record.EntityID)
+	} else {
+		change.Task, err = us.taskRepo.GetTaskByID(ctx, record.EntityID)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get %s: %w", record.EntityType, err)
+	}
+	return change, nil
+}
+
+// unmarshalPrevious decodes the item recorded before an update into item
+func unmarshalPrevious(record *models.UndoRecord, item interface{}) error {
+	if record.Previous == nil {
+		return fmt.Errorf("no previous %s recorded for %s", record.EntityType, record.Action)
+	}
+	return json.Unmarshal([]byte(*record.Previous), item)
+}
+
+// undoItemKey identifies the item changed by a record
+func undoItemKey(record *models.UndoRecord) string {
+	return fmt.Sprintf("%s:%d", record.EntityType, record.EntityID)
+}
+
+// newUndoToken returns a random undo token
+func newUndoToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
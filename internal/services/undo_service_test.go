@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockUndoRepository implements UndoRepositoryInterface for testing, reading
+// item versions from a MockTaskRepository
+type MockUndoRepository struct {
+	records  []*models.UndoRecord
+	taskRepo *MockTaskRepository
+}
+
+func (m *MockUndoRepository) CreateUndoRecord(ctx context.Context, record *models.UndoRecord) (*models.UndoRecord, error) {
+	record.ID = len(m.records) + 1
+	m.records = append(m.records, record)
+	return record, nil
+}
+
+func (m *MockUndoRepository) GetUndoRecords(ctx context.Context, token string) ([]*models.UndoRecord, error) {
+	var records []*models.UndoRecord
+	for _, record := range m.records {
+		if record.Token == token {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (m *MockUndoRepository) MarkUndone(ctx context.Context, token string, undoneAt time.Time) error {
+	marked := false
+	for _, record := range m.records {
+		if record.Token == token && record.UndoneAt == nil {
+			record.UndoneAt = &undoneAt
+			marked = true
+		}
+	}
+	if !marked {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (m *MockUndoRepository) GetItemVersion(ctx context.Context, entityType string, id int) (int, error) {
+	if task, ok := m.taskRepo.tasks[id]; ok {
+		return task.Version, nil
+	}
+	if task, ok := m.taskRepo.deleted[id]; ok {
+		return task.Version, nil
+	}
+	return 0, sql.ErrNoRows
+}
+
+func (m *MockUndoRepository) PurgeExpiredUndoRecords(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+// BaseRepository methods (not used in tests but required for interface)
+func (m *MockUndoRepository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockUndoRepository) GetByID(ctx context.Context, dest interface{}, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockUndoRepository) Update(ctx context.Context, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockUndoRepository) Delete(ctx context.Context, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockUndoRepository) List(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockUndoRepository) Count(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockUndoRepository) Exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	return false, nil
+}
+
+// setupUndoTest returns a task service recording its changes with an undo
+// service, which publishes its reverts to the returned publisher
+func setupUndoTest() (TaskServiceInterface, UndoServiceInterface, *MockTaskRepository, *MockUndoRepository, *recordingPublisher) {
+	taskRepo := NewMockTaskRepository()
+	undoRepo := &MockUndoRepository{taskRepo: taskRepo}
+	publisher := &recordingPublisher{}
+	undoService := NewUndoService(undoRepo, taskRepo, new(MockEventRepository), MockTransactor{}, time.Minute, publisher)
+	return NewTaskService(taskRepo, MockTransactor{}, undoService), undoService, taskRepo, undoRepo, publisher
+}
+
+func TestUndoService_UndoesRecurringCompletion(t *testing.T) {
+	taskService, undoService, taskRepo, undoRepo, publisher := setupUndoTest()
+
+	due := time.Now().Add(24 * time.Hour)
+	task, err := taskService.CreateTask(context.Background(), CreateTaskRequest{Title: "Water plants", DueDate: &due, RecurAfterDays: 3})
+	require.NoError(t, err)
+	assert.Empty(t, undoRepo.records, "changes made without an undo context are not recorded")
+
+	ctx, undoToken := WithUndo(context.Background())
+	_, err = taskService.CompleteTask(ctx, task.ID)
+	require.NoError(t, err)
+	token := undoToken()
+	require.NotEmpty(t, token)
+	require.Len(t, undoRepo.records, 2)
+	assert.Equal(t, "completed", undoRepo.records[0].Action)
+	assert.Equal(t, "created", undoRepo.records[1].Action)
+	next := undoRepo.records[1].EntityID
+
+	undone, err := undoService.Undo(context.Background(), token)
+	require.NoError(t, err)
+	require.Len(t, undone, 2)
+	assert.Equal(t, "created", undone[0].Action)
+	assert.Nil(t, undone[0].Task, "the next instance is in the trash")
+	assert.Equal(t, "completed", undone[1].Action)
+	require.NotNil(t, undone[1].Task)
+	assert.Equal(t, models.TaskStatusPending, undone[1].Task.Status)
+	assert.Nil(t, undone[1].Task.CompletedAt)
+
+	assert.Contains(t, taskRepo.deleted, next)
+	assert.Equal(t, []string{ChangeTaskDeleted, ChangeTaskReopened}, publisher.types())
+
+	_, err = undoService.Undo(context.Background(), token)
+	assert.Equal(t, ErrAlreadyUndone, err)
+}
+
+func TestUndoService_Errors(t *testing.T) {
+	taskService, undoService, _, undoRepo, _ := setupUndoTest()
+
+	task, err := taskService.CreateTask(context.Background(), CreateTaskRequest{Title: "Book flights"})
+	require.NoError(t, err)
+
+	update := func(title string) string {
+		ctx, undoToken := WithUndo(context.Background())
+		_, err := taskService.UpdateTask(ctx, task.ID, UpdateTaskRequest{Title: &title})
+		require.NoError(t, err)
+		return undoToken()
+	}
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := undoService.Undo(context.Background(), "missing")
+		assert.Equal(t, ErrUndoTokenNotFound, err)
+		_, err = undoService.Undo(context.Background(), "")
+		assert.Equal(t, ErrUndoTokenNotFound, err)
+	})
+
+	t.Run("modified since", func(t *testing.T) {
+		token := update("Book the flights")
+		update("Book flights to Lisbon")
+
+		_, err := undoService.Undo(context.Background(), token)
+		assert.Equal(t, ErrUndoConflict, err)
+		current, err := taskService.GetTaskByID(context.Background(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Book flights to Lisbon", current.Title)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		token := update("Book flights to Porto")
+		undoRepo.records[len(undoRepo.records)-1].ExpiresAt = time.Now().Add(-time.Second)
+
+		_, err := undoService.Undo(context.Background(), token)
+		assert.Equal(t, ErrUndoExpired, err)
+	})
+}
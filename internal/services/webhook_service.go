@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"agenda/internal/database"
+	"agenda/internal/models"
+	"agenda/internal/webhook"
+)
+
+// WebhookServiceInterface defines the contract for webhook business logic
+// operations. Webhook services publish task and event changes to the
+// webhooks subscribed to them.
+type WebhookServiceInterface interface {
+	ChangePublisher
+
+	CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*models.Webhook, error)
+	GetWebhookByID(ctx context.Context, id int) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, id int, req UpdateWebhookRequest) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+
+	// Delivery log operations
+	ListDeliveries(ctx context.Context, webhookID int, limit int) ([]*models.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, webhookID, id int) (*models.WebhookDelivery, error)
+}
+
+// WebhookService implements WebhookServiceInterface
+type WebhookService struct {
+	webhookRepo          database.WebhookRepositoryInterface
+	allowPrivateNetworks bool
+}
+
+// NewWebhookService creates a new webhook service instance. Unless
+// allowPrivateNetworks is set, webhook URLs must point to public addresses.
+func NewWebhookService(webhookRepo database.WebhookRepositoryInterface, allowPrivateNetworks bool) WebhookServiceInterface {
+	return &WebhookService{
+		webhookRepo:          webhookRepo,
+		allowPrivateNetworks: allowPrivateNetworks,
+	}
+}
+
+// CreateWebhookRequest represents the request to create a new webhook
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Description string   `json:"description"`
+	// Secret signs the deliveries; a random secret is generated when empty
+	Secret string `json:"secret"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+}
+
+// UpdateWebhookRequest represents the request to update an existing webhook
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url"`
+	EventTypes  *[]string `json:"event_types"`
+	Description *string   `json:"description"`
+	Active      *bool     `json:"active"`
+}
+
+// Webhook limits
+const (
+	maxWebhooks                  = 20
+	maxWebhookURLLength          = 2048
+	maxWebhookDescriptionLength  = 255
+	minWebhookSecretLength       = 16
+	defaultWebhookDeliveriesPage = 50
+	maxWebhookDeliveriesPage     = 200
+)
+
+// Webhook errors
+var (
+	ErrWebhookNotFound           = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound   = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL         = errors.New("webhook URL must be an absolute http or https URL of at most 2048 characters")
+	ErrWebhookURLNotPublic       = errors.New("webhook URL cannot point to a loopback, link-local or private network address")
+	ErrWebhookEventTypesRequired = errors.New("webhook must subscribe to at least one event type")
+	ErrInvalidWebhookEventType   = errors.New("invalid webhook event type")
+	ErrWebhookDescriptionTooLong = errors.New("webhook description cannot exceed 255 characters")
+	ErrWebhookSecretTooShort     = errors.New("webhook secret must be at least 16 characters")
+	ErrTooManyWebhooks           = errors.New("cannot have more than 20 webhooks")
+)
+
+// CreateWebhook creates a new webhook. The returned webhook carries its
+// secret, which is not returned again.
+func (ws *WebhookService) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*models.Webhook, error) {
+	hook := &models.Webhook{
+		URL:         strings.TrimSpace(req.URL),
+		Secret:      req.Secret,
+		Description: strings.TrimSpace(req.Description),
+		Active:      req.Active == nil || *req.Active,
+	}
+
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	hook.EventTypes = eventTypes
+
+	if err := ws.validateWebhook(ctx, hook); err != nil {
+		return nil, err
+	}
+	if hook.Secret == "" {
+		hook.Secret = webhook.NewSecret()
+	} else if len(hook.Secret) < minWebhookSecretLength {
+		return nil, ErrWebhookSecretTooShort
+	}
+
+	existing, err := ws.webhookRepo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxWebhooks {
+		return nil, ErrTooManyWebhooks
+	}
+
+	createdWebhook, err := ws.webhookRepo.CreateWebhook(ctx, hook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return createdWebhook, nil
+}
+
+// GetWebhookByID retrieves a webhook by its ID
+func (ws *WebhookService) GetWebhookByID(ctx context.Context, id int) (*models.Webhook, error) {
+	webhook, err := ws.webhookRepo.GetWebhookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooks retrieves every webhook
+func (ws *WebhookService) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	webhooks, err := ws.webhookRepo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook updates an existing webhook with validation
+func (ws *WebhookService) UpdateWebhook(ctx context.Context, id int, req UpdateWebhookRequest) (*models.Webhook, error) {
+	existingWebhook, err := ws.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedWebhook := *existingWebhook
+	if req.URL != nil {
+		updatedWebhook.URL = strings.TrimSpace(*req.URL)
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(*req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		updatedWebhook.EventTypes = eventTypes
+	}
+	if req.Description != nil {
+		updatedWebhook.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Active != nil {
+		updatedWebhook.Active = *req.Active
+	}
+
+	if err := ws.validateWebhook(ctx, &updatedWebhook); err != nil {
+		return nil, err
+	}
+
+	if err := ws.webhookRepo.UpdateWebhook(ctx, &updatedWebhook); err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return &updatedWebhook, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (ws *WebhookService) DeleteWebhook(ctx context.Context, id int) error {
+	if _, err := ws.GetWebhookByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := ws.webhookRepo.DeleteWebhook(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves the latest deliveries of a webhook, newest first
+func (ws *WebhookService) ListDeliveries(ctx context.Context, webhookID int, limit int) ([]*models.WebhookDelivery, error) {
+	if _, err := ws.GetWebhookByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultWebhookDeliveriesPage
+	}
+	if limit > maxWebhookDeliveriesPage {
+		limit = maxWebhookDeliveriesPage
+	}
+
+	deliveries, err := ws.webhookRepo.ListDeliveries(ctx, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// GetDelivery retrieves a delivery of a webhook along with its attempts
+func (ws *WebhookService) GetDelivery(ctx context.Context, webhookID, id int) (*models.WebhookDelivery, error) {
+	delivery, err := ws.webhookRepo.GetDeliveryByID(ctx, webhookID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	delivery.AttemptLog, err = ws.webhookRepo.GetDeliveryAttempts(ctx, delivery.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery attempts: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// Publish queues a delivery of the change for every active webhook of the
+// user subscribed to its type. Deliveries are written through ctx, so they
+// are only sent once the transaction making the change commits.
+func (ws *WebhookService) Publish(ctx context.Context, change Change) error {
+	webhooks, err := ws.webhookRepo.GetActiveWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range webhooks {
+		if !hook.Subscribes(change.Type) {
+			continue
+		}
+
+		id := webhook.NewDeliveryID()
+		payload, err := json.Marshal(webhook.Payload{
+			ID:         id,
+			Type:       change.Type,
+			OccurredAt: change.OccurredAt.UTC(),
+			Data:       change.Data,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook payload: %w", err)
+		}
+
+		delivery := &models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			UID:           id,
+			EventType:     change.Type,
+			Payload:       models.RawJSON(payload),
+			NextAttemptAt: change.OccurredAt,
+		}
+		if _, err := ws.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateWebhook validates the URL and description of a webhook
+func (ws *WebhookService) validateWebhook(ctx context.Context, hook *models.Webhook) error {
+	if len(hook.URL) > maxWebhookURLLength {
+		return ErrInvalidWebhookURL
+	}
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	if !ws.allowPrivateNetworks {
+		if err := webhook.CheckURL(ctx, hook.URL); err != nil {
+			return ErrWebhookURLNotPublic
+		}
+	}
+
+	if len(hook.Description) > maxWebhookDescriptionLength {
+		return ErrWebhookDescriptionTooLong
+	}
+
+	return nil
+}
+
+// normalizeEventTypes trims and deduplicates the event types of a webhook,
+// rejecting unknown ones
+func normalizeEventTypes(eventTypes []string) (models.StringList, error) {
+	var normalized models.StringList
+	for _, eventType := range eventTypes {
+		eventType = strings.ToLower(strings.TrimSpace(eventType))
+		if !IsChangeType(eventType) {
+			return nil, ErrInvalidWebhookEventType
+		}
+		if !normalized.Contains(eventType) {
+			normalized = append(normalized, eventType)
+		}
+	}
+
+	if len(normalized) == 0 {
+		return nil, ErrWebhookEventTypesRequired
+	}
+	return normalized, nil
+}
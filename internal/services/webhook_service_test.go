@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"agenda/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWebhookRepository implements WebhookRepositoryInterface for testing
+type MockWebhookRepository struct {
+	webhooks   map[int]*models.Webhook
+	deliveries []*models.WebhookDelivery
+	nextID     int
+}
+
+func NewMockWebhookRepository() *MockWebhookRepository {
+	return &MockWebhookRepository{
+		webhooks: make(map[int]*models.Webhook),
+		nextID:   1,
+	}
+}
+
+func (m *MockWebhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	webhook.ID = m.nextID
+	m.nextID++
+	stored := *webhook
+	m.webhooks[webhook.ID] = &stored
+	return webhook, nil
+}
+
+func (m *MockWebhookRepository) GetWebhookByID(ctx context.Context, id int) (*models.Webhook, error) {
+	webhook, exists := m.webhooks[id]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	copied := *webhook
+	return &copied, nil
+}
+
+func (m *MockWebhookRepository) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	for _, webhook := range m.webhooks {
+		copied := *webhook
+		webhooks = append(webhooks, &copied)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+func (m *MockWebhookRepository) GetActiveWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	all, _ := m.ListWebhooks(ctx)
+	var webhooks []*models.Webhook
+	for _, webhook := range all {
+		if webhook.Active {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (m *MockWebhookRepository) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	stored := *webhook
+	m.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(ctx context.Context, id int) error {
+	delete(m.webhooks, id)
+	return nil
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	delivery.ID = len(m.deliveries) + 1
+	if delivery.Status == "" {
+		delivery.Status = models.DeliveryPending
+	}
+	m.deliveries = append(m.deliveries, delivery)
+	return delivery, nil
+}
+
+func (m *MockWebhookRepository) GetDeliveryByID(ctx context.Context, webhookID, id int) (*models.WebhookDelivery, error) {
+	for _, delivery := range m.deliveries {
+		if delivery.ID == id && delivery.WebhookID == webhookID {
+			return delivery, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, webhookID int, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	for i := len(m.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if m.deliveries[i].WebhookID == webhookID {
+			deliveries = append(deliveries, m.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *MockWebhookRepository) GetDeliveryAttempts(ctx context.Context, deliveryID int) ([]*models.WebhookDeliveryAttempt, error) {
+	return nil, nil
+}
+
+func (m *MockWebhookRepository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (m *MockWebhookRepository) ClaimDelivery(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	return false, nil
+}
+
+func (m *MockWebhookRepository) SaveDeliveryState(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return nil
+}
+
+func (m *MockWebhookRepository) RecordDeliveryAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt) error {
+	return nil
+}
+
+func (m *MockWebhookRepository) ResetInterruptedDeliveries(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// BaseRepository methods (not used in tests but required for interface)
+func (m *MockWebhookRepository) Create(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, dest interface{}, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, query string, id interface{}) error {
+	return nil
+}
+
+func (m *MockWebhookRepository) List(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return nil
+}
+
+func (m *MockWebhookRepository) Count(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockWebhookRepository) Exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	return false, nil
+}
+
+// recordingPublisher records the published change types
+type recordingPublisher struct {
+	changes []Change
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, change Change) error {
+	p.changes = append(p.changes, change)
+	return nil
+}
+
+func (p *recordingPublisher) types() []string {
+	var types []string
+	for _, change := range p.changes {
+		types = append(types, change.Type)
+	}
+	return types
+}
+
+func TestWebhookService_CRUD(t *testing.T) {
+	service := NewWebhookService(NewMockWebhookRepository(), false)
+	ctx := context.Background()
+
+	webhook, err := service.CreateWebhook(ctx, CreateWebhookRequest{
+		URL:        " https://hooks.example.com/agenda ",
+		EventTypes: []string{"task.created", " Task.Created", "event.deleted"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/agenda", webhook.URL)
+	assert.Equal(t, models.StringList{"task.created", "event.deleted"}, webhook.EventTypes)
+	assert.True(t, webhook.Active)
+	assert.Contains(t, webhook.Secret, "whsec_", "expected a generated secret")
+
+	t.Run("validation", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  CreateWebhookRequest
+			want error
+		}{
+			{"relative URL", CreateWebhookRequest{URL: "/hooks", EventTypes: []string{"task.created"}}, ErrInvalidWebhookURL},
+			{"unsupported scheme", CreateWebhookRequest{URL: "ftp://example.com", EventTypes: []string{"task.created"}}, ErrInvalidWebhookURL},
+			{"loopback address", CreateWebhookRequest{URL: "http://127.0.0.1:8080/hooks", EventTypes: []string{"task.created"}}, ErrWebhookURLNotPublic},
+			{"metadata address", CreateWebhookRequest{URL: "http://169.254.169.254/latest", EventTypes: []string{"task.created"}}, ErrWebhookURLNotPublic},
+			{"private address", CreateWebhookRequest{URL: "https://10.0.0.5/hooks", EventTypes: []string{"task.created"}}, ErrWebhookURLNotPublic},
+			{"no event types", CreateWebhookRequest{URL: "https://example.com"}, ErrWebhookEventTypesRequired},
+			{"unknown event type", CreateWebhookRequest{URL: "https://example.com", EventTypes: []string{"task.exploded"}}, ErrInvalidWebhookEventType},
+			{"short secret", CreateWebhookRequest{URL: "https://example.com", EventTypes: []string{"task.created"}, Secret: "hunter2"}, ErrWebhookSecretTooShort},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.CreateWebhook(ctx, tt.req)
+				assert.Equal(t, tt.want, err)
+			})
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		inactive := false
+		updated, err := service.UpdateWebhook(ctx, webhook.ID, UpdateWebhookRequest{Active: &inactive})
+		require.NoError(t, err)
+		assert.False(t, updated.Active)
+		assert.Equal(t, webhook.Secret, updated.Secret)
+
+		_, err = service.UpdateWebhook(ctx, 999, UpdateWebhookRequest{Active: &inactive})
+		assert.Equal(t, ErrWebhookNotFound, err)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.NoError(t, service.DeleteWebhook(ctx, webhook.ID))
+		assert.Equal(t, ErrWebhookNotFound, service.DeleteWebhook(ctx, webhook.ID))
+	})
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	repo := NewMockWebhookRepository()
+	service := NewWebhookService(repo, false)
+	ctx := context.Background()
+
+	tasks, err := service.CreateWebhook(ctx, CreateWebhookRequest{URL: "https://example.com/tasks",
+		EventTypes: []string{ChangeTaskCreated}})
+	require.NoError(t, err)
+	inactive := false
+	_, err = service.CreateWebhook(ctx, CreateWebhookRequest{URL: "https://example.com/off",
+		EventTypes: []string{ChangeTaskCreated}, Active: &inactive})
+	require.NoError(t, err)
+
+	occurredAt := time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)
+	task := &models.Task{ID: 4, Title: "Renew passport"}
+	require.NoError(t, service.Publish(ctx, Change{Type: ChangeTaskCreated, Data: task, OccurredAt: occurredAt}))
+	require.NoError(t, service.Publish(ctx, Change{Type: ChangeTaskDeleted, Data: task, OccurredAt: occurredAt}))
+
+	// Only the active webhook subscribed to the change gets a delivery
+	require.Len(t, repo.deliveries, 1)
+	delivery := repo.deliveries[0]
+	assert.Equal(t, tasks.ID, delivery.WebhookID)
+	assert.Equal(t, ChangeTaskCreated, delivery.EventType)
+	assert.True(t, delivery.NextAttemptAt.Equal(occurredAt))
+
+	var payload struct {
+		ID         string      `json:"id"`
+		Type       string      `json:"type"`
+		OccurredAt time.Time   `json:"occurred_at"`
+		Data       models.Task `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(delivery.Payload), &payload))
+	assert.Equal(t, delivery.UID, payload.ID)
+	assert.Equal(t, ChangeTaskCreated, payload.Type)
+	assert.Equal(t, "Renew passport", payload.Data.Title)
+}
+
+func TestTaskService_PublishesChanges(t *testing.T) {
+	publisher := &recordingPublisher{}
+	service := NewTaskService(NewMockTaskRepository(), MockTransactor{}, publisher)
+	ctx := context.Background()
+
+	task, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Book flights"})
+	require.NoError(t, err)
+	title := "Book flights and hotel"
+	_, err = service.UpdateTask(ctx, task.ID, UpdateTaskRequest{Title: &title})
+	require.NoError(t, err)
+	_, err = service.CompleteTask(ctx, task.ID)
+	require.NoError(t, err)
+	_, err = service.ReopenTask(ctx, task.ID)
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTask(ctx, task.ID))
+
+	assert.Equal(t, []string{ChangeTaskCreated, ChangeTaskUpdated, ChangeTaskCompleted, ChangeTaskReopened,
+		ChangeTaskDeleted}, publisher.types())
+
+	t.Run("completing a recurring task publishes the next instance", func(t *testing.T) {
+		publisher.changes = nil
+		due := time.Now().Add(24 * time.Hour)
+		recurring, err := service.CreateTask(ctx, CreateTaskRequest{Title: "Standup notes", DueDate: &due,
+			RecurrenceRule: "FREQ=DAILY"})
+		require.NoError(t, err)
+
+		_, err = service.CompleteTask(ctx, recurring.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ChangeTaskCreated, ChangeTaskCompleted, ChangeTaskCreated}, publisher.types())
+	})
+}
+
+func TestEventService_PublishesChanges(t *testing.T) {
+	publisher := &recordingPublisher{}
+	_, mockRepo := createTestEventService()
+	service := NewEventService(mockRepo, NewMockCalendarRepository(), MockTransactor{}, publisher)
+	ctx := context.Background()
+
+	start := time.Now().Add(time.Hour)
+	event := &models.Event{ID: 1, Title: "Lunch", StartTime: start, EndTime: start.Add(time.Hour)}
+	mockRepo.On("ListEvents", ctx, mock.AnythingOfType("database.EventFilters")).Return([]*models.Event{}, nil)
+	mockRepo.On("CreateEvent", ctx, mock.AnythingOfType("*models.Event")).Return(event, nil)
+	mockRepo.On("GetEventByID", ctx, 1).Return(event, nil)
+	mockRepo.On("DeleteEvent", ctx, 1).Return(nil)
+
+	_, err := service.CreateEvent(ctx, CreateEventRequest{Title: "Lunch", StartTime: start, EndTime: start.Add(time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteEvent(ctx, 1))
+
+	assert.Equal(t, []string{ChangeEventCreated, ChangeEventDeleted}, publisher.types())
+	assert.Equal(t, event, publisher.changes[1].Data)
+}
@@ -0,0 +1,255 @@
+// Package stream fans task and event changes out to the live streams of
+// connected clients.
+//
+// Every message published to a Hub gets the next ID in sequence. The hub
+// keeps the most recent messages so a client reconnecting with the ID of the
+// last message it received can be sent the ones it missed. The sequence is
+// seeded from the clock, so the IDs of an earlier process are always older
+// than the messages kept by a new one and clients resuming with them learn
+// that they missed changes.
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReplaySize is the number of recent messages kept for clients
+// resuming a stream
+const DefaultReplaySize = 1000
+
+// subscriptionBuffer is the number of messages a stream can fall behind by
+// before it is dropped
+const subscriptionBuffer = 64
+
+// ErrClosed is returned when subscribing to a hub that was closed
+var ErrClosed = errors.New("stream hub is closed")
+
+// ReplaySizeFromEnv reads the number of messages kept for resuming streams
+// from the STREAM_REPLAY_SIZE environment variable, falling back to
+// DefaultReplaySize for unset or invalid values
+func ReplaySizeFromEnv() int {
+	size, err := strconv.Atoi(os.Getenv("STREAM_REPLAY_SIZE"))
+	if err != nil || size <= 0 {
+		return DefaultReplaySize
+	}
+	return size
+}
+
+// Period is the time range a task or event takes place in. A zero End
+// leaves it open, as for recurring series.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Message is a change sent to the streams
+type Message struct {
+	// ID is assigned by the hub when the message is published
+	ID uint64
+	// Type is the change type, such as task.created
+	Type       string
+	OccurredAt time.Time
+	// Data is the JSON encoding of the task or event
+	Data json.RawMessage
+	// Owner is the user owning the task or event, nil for rows created
+	// without authentication
+	Owner *int
+	// Periods are the time ranges the task or event takes place in before
+	// and after the change, empty when it has no date
+	Periods []Period
+}
+
+// Filter selects the messages sent to a stream
+type Filter struct {
+	// Owner only lets the messages of the user through, or the messages
+	// without owner when nil
+	Owner *int
+	// Types are entity types such as task, or change types such as
+	// event.deleted. Every type is let through when empty.
+	Types []string
+	// Start and End only let through the tasks and events taking place in
+	// the range. A zero time leaves that side of the range open.
+	Start time.Time
+	End   time.Time
+}
+
+// Matches reports whether the filter lets the message through
+func (f Filter) Matches(msg *Message) bool {
+	if (f.Owner == nil) != (msg.Owner == nil) || (f.Owner != nil && *f.Owner != *msg.Owner) {
+		return false
+	}
+
+	if len(f.Types) > 0 {
+		matched := false
+		for _, t := range f.Types {
+			if msg.Type == t || strings.HasPrefix(msg.Type, t+".") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if f.Start.IsZero() && f.End.IsZero() {
+		return true
+	}
+	for _, period := range msg.Periods {
+		if (f.End.IsZero() || period.Start.Before(f.End)) &&
+			(f.Start.IsZero() || period.End.IsZero() || !period.End.Before(f.Start)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription is a stream registered with a hub
+type Subscription struct {
+	// Replay holds the messages matching the filter published after the
+	// ID the stream resumes from
+	Replay []*Message
+	// Gap is true when some of the messages published after the ID the
+	// stream resumes from are no longer kept, so the client must reload
+	Gap bool
+	// LastID is the ID of the last message published before the
+	// subscription
+	LastID uint64
+
+	filter   Filter
+	messages chan *Message
+	hub      *Hub
+}
+
+// Messages returns the channel the matching messages are sent on. It is
+// closed when the subscription or the hub is closed, or when the stream
+// falls too far behind; the client should then resume from the last
+// message it received.
+func (s *Subscription) Messages() <-chan *Message {
+	return s.messages
+}
+
+// Close unregisters the subscription from its hub
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Hub is an in-process pub/sub hub sending published messages to the
+// matching subscriptions
+type Hub struct {
+	mu            sync.Mutex
+	lastID        uint64
+	replay        []*Message
+	replaySize    int
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+}
+
+// NewHub creates a hub keeping the last replaySize messages for resuming
+// streams
+func NewHub(replaySize int) *Hub {
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+
+	return &Hub{
+		lastID:        uint64(time.Now().UnixMicro()),
+		replaySize:    replaySize,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the message its ID, keeps it for resuming streams and
+// sends it to the matching subscriptions. Subscriptions too far behind to
+// take it are closed. Messages published to a closed hub are dropped.
+func (h *Hub) Publish(msg Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.lastID++
+	msg.ID = h.lastID
+	published := &msg
+
+	h.replay = append(h.replay, published)
+	if len(h.replay) > h.replaySize {
+		h.replay = h.replay[len(h.replay)-h.replaySize:]
+	}
+
+	for sub := range h.subscriptions {
+		if !sub.filter.Matches(published) {
+			continue
+		}
+		select {
+		case sub.messages <- published:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a stream receiving the messages matching filter.
+// Given the ID of the last message a client received, the matching
+// messages published after it are returned in Replay.
+func (h *Hub) Subscribe(filter Filter, lastID uint64) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrClosed
+	}
+
+	sub := &Subscription{
+		LastID:   h.lastID,
+		filter:   filter,
+		messages: make(chan *Message, subscriptionBuffer),
+		hub:      h,
+	}
+
+	if lastID != 0 {
+		// IDs are consecutive, so the kept messages start right after
+		// the last one dropped
+		firstKept := h.lastID + 1 - uint64(len(h.replay))
+		sub.Gap = lastID > h.lastID || lastID+1 < firstKept
+		for _, msg := range h.replay {
+			if msg.ID > lastID && filter.Matches(msg) {
+				sub.Replay = append(sub.Replay, msg)
+			}
+		}
+	}
+
+	h.subscriptions[sub] = struct{}{}
+	return sub, nil
+}
+
+// Close closes every subscription and stops accepting new ones
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subscriptions {
+		h.remove(sub)
+	}
+}
+
+// remove unregisters a subscription and closes its channel. The caller
+// must hold the lock.
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subscriptions[sub]; !ok {
+		return
+	}
+	delete(h.subscriptions, sub)
+	close(sub.messages)
+}
@@ -0,0 +1,179 @@
+package stream
+
+import (
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestFilter_Matches(t *testing.T) {
+	day := time.Date(2030, 6, 3, 0, 0, 0, 0, time.UTC)
+	msg := &Message{
+		Type:    "event.updated",
+		Owner:   intPtr(1),
+		Periods: []Period{{Start: day.Add(9 * time.Hour), End: day.Add(10 * time.Hour)}},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{name: "owner", filter: Filter{Owner: intPtr(1)}, want: true},
+		{name: "other owner", filter: Filter{Owner: intPtr(2)}},
+		{name: "no owner", filter: Filter{}},
+		{name: "entity type", filter: Filter{Owner: intPtr(1), Types: []string{"task", "event"}}, want: true},
+		{name: "change type", filter: Filter{Owner: intPtr(1), Types: []string{"event.updated"}}, want: true},
+		{name: "other type", filter: Filter{Owner: intPtr(1), Types: []string{"task", "event.deleted"}}},
+		{name: "range", filter: Filter{Owner: intPtr(1), Start: day, End: day.AddDate(0, 0, 1)}, want: true},
+		{name: "open range", filter: Filter{Owner: intPtr(1), Start: day.Add(9 * time.Hour)}, want: true},
+		{name: "range before", filter: Filter{Owner: intPtr(1), End: day.Add(9 * time.Hour)}},
+		{name: "range after", filter: Filter{Owner: intPtr(1), Start: day.Add(11 * time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(msg); got != tt.want {
+				t.Errorf("Expected Matches to return %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// Items without a date only match unbounded ranges
+	undated := &Message{Type: "task.created"}
+	if !(Filter{}).Matches(undated) {
+		t.Error("Expected an unbounded filter to match an undated item")
+	}
+	if (Filter{Start: day}).Matches(undated) {
+		t.Error("Expected a range filter not to match an undated item")
+	}
+
+	// Recurring series have open periods
+	series := &Message{Type: "event.created", Periods: []Period{{Start: day}}}
+	if !(Filter{Start: day.AddDate(0, 1, 0)}).Matches(series) {
+		t.Error("Expected an open period to match later ranges")
+	}
+}
+
+func TestHub_PublishAndSubscribe(t *testing.T) {
+	hub := NewHub(10)
+
+	tasks, err := hub.Subscribe(Filter{Types: []string{"task"}}, 0)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	other, err := hub.Subscribe(Filter{Owner: intPtr(1)}, 0)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	hub.Publish(Message{Type: "event.created"})
+	hub.Publish(Message{Type: "task.created"})
+
+	select {
+	case msg := <-tasks.Messages():
+		if msg.Type != "task.created" || msg.ID != tasks.LastID+2 {
+			t.Errorf("Unexpected message %+v", msg)
+		}
+	default:
+		t.Fatal("Expected the task change to be sent")
+	}
+	if len(tasks.Messages()) != 0 || len(other.Messages()) != 0 {
+		t.Error("Expected the other changes to be filtered out")
+	}
+
+	tasks.Close()
+	if _, ok := <-tasks.Messages(); ok {
+		t.Error("Expected the channel of a closed subscription to be closed")
+	}
+	tasks.Close()
+}
+
+func TestHub_Resume(t *testing.T) {
+	hub := NewHub(3)
+	for i := 0; i < 5; i++ {
+		hub.Publish(Message{Type: "task.updated"})
+	}
+	sub, err := hub.Subscribe(Filter{}, 0)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	sub.Close()
+	last := sub.LastID
+
+	tests := []struct {
+		name       string
+		lastID     uint64
+		wantReplay int
+		wantGap    bool
+	}{
+		{name: "up to date", lastID: last},
+		{name: "kept", lastID: last - 2, wantReplay: 2},
+		{name: "oldest kept", lastID: last - 3, wantReplay: 3},
+		{name: "dropped", lastID: last - 4, wantReplay: 3, wantGap: true},
+		{name: "earlier process", lastID: 42, wantReplay: 3, wantGap: true},
+		{name: "unknown", lastID: last + 1, wantGap: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := hub.Subscribe(Filter{}, tt.lastID)
+			if err != nil {
+				t.Fatalf("Subscribe failed: %v", err)
+			}
+			defer sub.Close()
+
+			if len(sub.Replay) != tt.wantReplay || sub.Gap != tt.wantGap {
+				t.Errorf("Expected %d replayed messages and gap %v, got %d and %v",
+					tt.wantReplay, tt.wantGap, len(sub.Replay), sub.Gap)
+			}
+			for i, msg := range sub.Replay {
+				if i > 0 && msg.ID != sub.Replay[i-1].ID+1 {
+					t.Errorf("Expected replayed messages in order, got %d after %d", msg.ID, sub.Replay[i-1].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestHub_SlowSubscriptionIsDropped(t *testing.T) {
+	hub := NewHub(0)
+	sub, err := hub.Subscribe(Filter{}, 0)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		hub.Publish(Message{Type: "task.updated"})
+	}
+
+	received := 0
+	for range sub.Messages() {
+		received++
+	}
+	if received != subscriptionBuffer {
+		t.Errorf("Expected %d messages before the subscription was dropped, got %d", subscriptionBuffer, received)
+	}
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(0)
+	sub, err := hub.Subscribe(Filter{}, 0)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	hub.Close()
+	if _, ok := <-sub.Messages(); ok {
+		t.Error("Expected closing the hub to close its subscriptions")
+	}
+	sub.Close()
+
+	if _, err := hub.Subscribe(Filter{}, 0); err != ErrClosed {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	hub.Publish(Message{Type: "task.updated"})
+}
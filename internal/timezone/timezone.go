@@ -0,0 +1,76 @@
+// Package timezone carries the time zone of a request through its context
+// and computes the calendar boundaries (days, months) in that zone.
+package timezone
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	// Embed the time zone database so zones resolve on hosts without one
+	_ "time/tzdata"
+)
+
+// ErrInvalidTimeZone is returned for names that are not IANA time zones
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+
+// contextKey is the key under which the request time zone is stored
+type contextKey struct{}
+
+// WithLocation returns a copy of ctx carrying the request time zone
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, contextKey{}, loc)
+}
+
+// FromContext returns the time zone carried by ctx, UTC when there is none
+func FromContext(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(contextKey{}).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// Load resolves an IANA time zone name such as "Europe/Paris". The empty
+// name resolves to UTC. Unlike time.LoadLocation, "Local" is rejected since
+// the server zone means nothing to clients.
+func Load(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	if name == "Local" {
+		return nil, ErrInvalidTimeZone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+	return loc, nil
+}
+
+// Now returns the current time in the time zone carried by ctx
+func Now(ctx context.Context) time.Time {
+	return time.Now().In(FromContext(ctx))
+}
+
+// StartOfDay returns midnight of the day of t in loc
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// DayBounds returns the start of the day of t in loc and the start of the
+// next day. Days are not always 24 hours long around DST changes.
+func DayBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	start := StartOfDay(t, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// MonthBounds returns the start of a month in loc and the start of the
+// next month
+func MonthBounds(year int, month time.Month, loc *time.Location) (time.Time, time.Time) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 1, 0)
+}
@@ -0,0 +1,56 @@
+package timezone
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationContext(t *testing.T) {
+	assert.Equal(t, time.UTC, FromContext(context.Background()))
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	assert.Equal(t, paris, FromContext(WithLocation(context.Background(), paris)))
+}
+
+func TestLoad(t *testing.T) {
+	loc, err := Load("America/New_York")
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", loc.String())
+
+	loc, err = Load("")
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	for _, name := range []string{"Local", "Mars/Olympus_Mons", "../etc/passwd"} {
+		_, err := Load(name)
+		assert.ErrorIs(t, err, ErrInvalidTimeZone, name)
+	}
+}
+
+func TestDayBounds(t *testing.T) {
+	ny, err := Load("America/New_York")
+	require.NoError(t, err)
+
+	// 02:30 UTC on the 10th is still the evening of the 9th in New York
+	start, end := DayBounds(time.Date(2030, time.January, 10, 2, 30, 0, 0, time.UTC), ny)
+	assert.True(t, start.Equal(time.Date(2030, time.January, 9, 5, 0, 0, 0, time.UTC)))
+	assert.True(t, end.Equal(time.Date(2030, time.January, 10, 5, 0, 0, 0, time.UTC)))
+
+	// The day clocks move forward lasts 23 hours
+	start, end = DayBounds(time.Date(2030, time.March, 10, 12, 0, 0, 0, ny), ny)
+	assert.Equal(t, 23*time.Hour, end.Sub(start))
+}
+
+func TestMonthBounds(t *testing.T) {
+	tokyo, err := Load("Asia/Tokyo")
+	require.NoError(t, err)
+
+	start, end := MonthBounds(2030, time.December, tokyo)
+	assert.True(t, start.Equal(time.Date(2030, time.November, 30, 15, 0, 0, 0, time.UTC)))
+	assert.True(t, end.Equal(time.Date(2030, time.December, 31, 15, 0, 0, 0, time.UTC)))
+}
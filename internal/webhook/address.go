@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// ErrForbiddenAddress is returned for receivers on loopback, link-local,
+// private or unspecified addresses while private networks are not allowed
+var ErrForbiddenAddress = errors.New("webhook receiver address is not allowed")
+
+// forbiddenPrefixes lists the ranges reserved for internal use that the
+// net.IP predicates do not cover
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+}
+
+// AllowPrivateNetworksFromEnv reports whether the
+// WEBHOOK_ALLOW_PRIVATE_NETWORKS environment variable allows webhooks to
+// target internal addresses. It is off by default, so that users cannot
+// make the server call its own network.
+func AllowPrivateNetworksFromEnv() bool {
+	allow, err := strconv.ParseBool(os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS"))
+	return err == nil && allow
+}
+
+// IsPublicIP reports whether ip is an address webhooks may be sent to: not a
+// loopback, link-local, private, multicast or unspecified address
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsPrivate() || ip.IsUnspecified() {
+		return false
+	}
+
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL rejects webhook URLs whose host is, or resolves to, an address
+// that is not public. Hosts that do not resolve yet are accepted; the
+// sender checks the address it connects to again.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// controlPublic is a net.Dialer Control function refusing connections to
+// addresses that are not public. It runs after name resolution, so a host
+// resolving to another address when the delivery is sent is still checked.
+func controlPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicIP(net.ParseIP(host)) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package webhook signs and sends webhook deliveries.
+//
+// Every delivery is a JSON POST carrying the headers below. The signature
+// is the hex-encoded HMAC-SHA256, keyed with the webhook secret, of the
+// timestamp header value, a dot and the raw request body, prefixed with
+// "sha256=". Receivers should recompute it, compare it in constant time and
+// reject old timestamps; Verify does all three.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery headers
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signaturePrefix names the signing algorithm in the signature header
+const signaturePrefix = "sha256="
+
+// maxResponseBody bounds the part of a response body kept for the delivery
+// log
+const maxResponseBody = 1024
+
+// Verification errors
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidTimestamp = errors.New("invalid or expired webhook timestamp")
+)
+
+// Payload is the body of a delivery
+type Payload struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// NewSecret generates a random signing secret
+func NewSecret() string {
+	return "whsec_" + randomHex(24)
+}
+
+// NewDeliveryID generates a unique delivery ID
+func NewDeliveryID() string {
+	return "dlv_" + randomHex(16)
+}
+
+// randomHex returns n random bytes, hex-encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Sign returns the signature header value of a body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a delivery received
+// at now. Timestamps further than tolerance from now are rejected.
+func Verify(secret, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidTimestamp
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) ||
+		!hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Request is a delivery to send
+type Request struct {
+	URL    string
+	Secret string
+	ID     string
+	Event  string
+	Body   []byte
+}
+
+// Result is the outcome of sending a delivery
+type Result struct {
+	// StatusCode is the HTTP status of the response, 0 when no response
+	// was received
+	StatusCode int
+	// Body is the start of the response body. It only comes from receivers
+	// the sender was allowed to connect to, so unless private networks are
+	// allowed it never exposes internal hosts.
+	Body     string
+	Duration time.Duration
+	// Err is set when the delivery was not accepted
+	Err error
+}
+
+// Sender posts signed deliveries
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a sender giving up on receivers after timeout. Unless
+// allowPrivateNetworks is set, it refuses to connect to addresses that are
+// not public and sends deliveries directly rather than through the proxy of
+// the environment, which would connect on its behalf. Redirects are never
+// followed, so a receiver answering with one fails the delivery.
+func NewSender(timeout time.Duration, allowPrivateNetworks bool) *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivateNetworks {
+		dialer := &net.Dialer{Timeout: timeout, Control: controlPublic}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+
+	return &Sender{client: &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Send posts a delivery signed at now. Deliveries are accepted by any 2xx
+// response.
+func (s *Sender) Send(ctx context.Context, req Request, now time.Time) Result {
+	start := time.Now()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return Result{Err: fmt.Errorf("failed to build request: %w", err)}
+	}
+
+	timestamp := now.Unix()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "agenda-webhooks/1.0")
+	httpReq.Header.Set(HeaderID, req.ID)
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return Result{Duration: time.Since(start), Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result := Result{StatusCode: resp.StatusCode, Body: string(body), Duration: time.Since(start)}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Err = fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return result
+}
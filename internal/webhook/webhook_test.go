@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"dlv_1","type":"task.created"}`)
+	now := time.Unix(1900000000, 0)
+	signature := Sign("whsec_test", now.Unix(), body)
+	timestamp := "1900000000"
+
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Fatalf("Unexpected signature format %q", signature)
+	}
+	if err := Verify("whsec_test", timestamp, signature, body, now, 5*time.Minute); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		want      error
+	}{
+		{"wrong secret", "whsec_other", timestamp, signature, body, ErrInvalidSignature},
+		{"tampered body", "whsec_test", timestamp, signature, []byte(`{}`), ErrInvalidSignature},
+		{"replayed with another timestamp", "whsec_test", "1900000001", signature, body, ErrInvalidSignature},
+		{"missing prefix", "whsec_test", timestamp, strings.TrimPrefix(signature, "sha256="), body, ErrInvalidSignature},
+		{"invalid timestamp", "whsec_test", "yesterday", signature, body, ErrInvalidTimestamp},
+		{"expired timestamp", "whsec_test", "1899999000", Sign("whsec_test", 1899999000, body), body, ErrInvalidTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.secret, tt.timestamp, tt.signature, tt.body, now, 5*time.Minute); err != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSender(t *testing.T) {
+	now := time.Now()
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify("whsec_test", r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, now, time.Minute); err != nil {
+			t.Errorf("Delivery failed verification: %v", err)
+		}
+		if r.Header.Get(HeaderID) != "dlv_1" || r.Header.Get(HeaderEvent) != "task.created" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(strings.Repeat("x", 2*maxResponseBody)))
+	}))
+	defer server.Close()
+
+	sender := NewSender(time.Second, true)
+	req := Request{URL: server.URL, Secret: "whsec_test", ID: "dlv_1", Event: "task.created", Body: []byte(`{}`)}
+
+	result := sender.Send(context.Background(), req, now)
+	if result.Err != nil || result.StatusCode != http.StatusOK {
+		t.Fatalf("Expected a successful delivery, got %d %v", result.StatusCode, result.Err)
+	}
+	if len(result.Body) != maxResponseBody {
+		t.Errorf("Expected the response body to be truncated, got %d bytes", len(result.Body))
+	}
+
+	status = http.StatusInternalServerError
+	result = sender.Send(context.Background(), req, now)
+	if result.Err == nil || result.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected a failed delivery, got %d %v", result.StatusCode, result.Err)
+	}
+
+	req.URL = "http://127.0.0.1:1"
+	result = sender.Send(context.Background(), req, now)
+	if result.Err == nil || result.StatusCode != 0 {
+		t.Errorf("Expected a connection error, got %d %v", result.StatusCode, result.Err)
+	}
+}
+
+func TestSenderAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	req := Request{URL: server.URL, Secret: "whsec_test", ID: "dlv_1", Event: "task.created", Body: []byte(`{}`)}
+
+	result := NewSender(time.Second, false).Send(context.Background(), req, time.Now())
+	if !errors.Is(result.Err, ErrForbiddenAddress) || result.StatusCode != 0 {
+		t.Errorf("Expected the loopback receiver to be refused, got %d %v", result.StatusCode, result.Err)
+	}
+
+	req.URL = server.URL + "/redirect"
+	result = NewSender(time.Second, true).Send(context.Background(), req, time.Now())
+	if result.Err == nil || result.StatusCode != http.StatusFound {
+		t.Errorf("Expected the redirect not to be followed, got %d %v", result.StatusCode, result.Err)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://93.184.216.34/hook", nil},
+		{"http://127.0.0.1:8080/hook", ErrForbiddenAddress},
+		{"http://[::1]/hook", ErrForbiddenAddress},
+		{"http://169.254.169.254/latest/meta-data", ErrForbiddenAddress},
+		{"http://localhost/hook", ErrForbiddenAddress},
+	}
+
+	for _, tt := range tests {
+		if err := CheckURL(context.Background(), tt.url); err != tt.want {
+			t.Errorf("CheckURL(%s) = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}